@@ -3,6 +3,9 @@ package danger
 import (
 	"encoding/json"
 	"fmt"
+	"log"
+	"math/rand/v2"
+	"time"
 
 	dangerJs "github.com/danger/golang/danger-js"
 )
@@ -11,70 +14,204 @@ import (
 // import the root danger package.
 type DSL = dangerJs.DSL
 
+// DSLOption configures how the DSL is built, see WithRepoPath.
+type DSLOption = dangerJs.DSLOption
+
+// WithRepoPath runs all git commands of the DSL in dir rather than the process
+// working directory, e.g. for a temporary clone.
+func WithRepoPath(dir string) DSLOption {
+	return dangerJs.WithRepoPath(dir)
+}
+
+// WithRefRemotes sets the remotes whose tracking branches refs such as the
+// base branch resolve to when no local branch exists, origin by default.
+func WithRefRemotes(remotes ...string) DSLOption {
+	return dangerJs.WithRefRemotes(remotes...)
+}
+
+// WithGitHubFetcher fetches PR commits and reviews missing from the DSL JSON
+// with f, e.g. a githubclient.Client.
+func WithGitHubFetcher(f dangerJs.GitHubFetcher) DSLOption {
+	return dangerJs.WithGitHubFetcher(f)
+}
+
+// WithGitLabFetcher fetches MR commits and approvals missing from the DSL
+// JSON with f, e.g. a gitlabclient.Client.
+func WithGitLabFetcher(f dangerJs.GitLabFetcher) DSLOption {
+	return dangerJs.WithGitLabFetcher(f)
+}
+
+// Impact reports the build targets affected by the changes, see
+// WithImpact.
+type Impact = dangerJs.Impact
+
+// WithImpact exposes a build graph impact analysis as DSL.Impact, e.g. a
+// dangerJs.ImpactFile computed by an earlier CI step.
+func WithImpact(i Impact) DSLOption {
+	return dangerJs.WithImpact(i)
+}
+
+// WithBazelImpact exposes the targets affected by the changed files, as
+// found by `bazel query rdeps`, as DSL.Impact.
+func WithBazelImpact() DSLOption {
+	return dangerJs.WithBazelImpact()
+}
+
 type T struct {
 	results Results
+	// rule is the name of the rule currently running, see RunRules.
+	rule string
+	// commentLimit configures FitComment.
+	commentLimit CommentLimit
+	// inlineBudget caps the inline comments of FitComment.
+	inlineBudget InlineBudget
+	// inlineThreads groups the inline comments of FitComment.
+	inlineThreads InlineThreads
+
+	reporters     []Reporter
+	logger        *log.Logger
+	failThreshold int
+	now           func() time.Time
+	rand          *rand.Rand
+	sorted        bool
+
+	changeMarkers   bool
+	previousComment string
+	// loadPrevious reads previousComment when first needed, see
+	// SetPreviousCommentLoader.
+	loadPrevious func() string
+	// checklist are the items added with Checklist.
+	checklist []ChecklistItem
+	// checked are the ticked checklist items of the previous comment.
+	checked map[string]bool
+	// failsAsWarnings records fails as warnings, see SetFailsAsWarnings.
+	failsAsWarnings bool
+	// strict is set while a Strict rule runs.
+	strict bool
+	// ruleRuns are the rules passed to RunRules, see RuleRuns.
+	ruleRuns []RuleRun
+	// fixes are the fixes added with AddFix.
+	fixes []Fix
+	// messages override the default message templates, see SetMessages.
+	messages Messages
+	// waivers are the waivers applied with ApplyWaivers.
+	waivers []Waiver
 }
 
-func New() *T {
-	return &T{
+// New creates an empty T configured with opts.
+func New(opts ...Option) *T {
+	t := &T{
 		results: Results{
 			Fails:     []Violation{},
 			Messages:  []Violation{},
 			Warnings:  []Violation{},
 			Markdowns: []Violation{},
 		},
+		logger:        log.Default(),
+		failThreshold: -1,
+		now:           time.Now,
+		rand:          rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+	for _, o := range opts {
+		o(t)
 	}
+	return t
 }
 
 // Results returns the JSON marshalled from the messages, warnings, failures,
 // and markdowns that was added so far.
 func (s *T) Results() (string, error) {
-	bb, err := json.Marshal(s.results)
+	bb, err := json.Marshal(s.Snapshot())
 	if err != nil {
 		return "", fmt.Errorf("marshalling results: %w", err)
 	}
 	return string(bb), nil
 }
 
+// Snapshot returns a copy of the messages, warnings, failures and markdowns
+// added so far.
+func (s *T) Snapshot() Results {
+	r := s.results
+	r.Fails = append([]Violation{}, s.results.Fails...)
+	r.Warnings = append([]Violation{}, s.results.Warnings...)
+	r.Messages = append([]Violation{}, s.results.Messages...)
+	r.Markdowns = append([]Violation{}, s.results.Markdowns...)
+	if v, ok := s.thresholdFail(); ok {
+		r.Fails = append(r.Fails, v)
+	}
+	if s.sorted {
+		r = r.Sorted()
+	}
+	return r
+}
+
 // Message adds the message to the Danger table. The only difference between
 // this and Warn is the emoji which shows in the table.
 func (s *T) Message(message string, file string, line int) {
-	s.results.Messages = append(s.results.Messages,
-		Violation{
-			Message: message,
-			File:    file,
-			Line:    line,
-		})
+	v := Violation{
+		Message: message,
+		File:    file,
+		Line:    line,
+		Rule:    s.rule,
+	}
+	s.results.Messages = append(s.results.Messages, v)
+	s.report(KindMessage, v)
 }
 
 // Warn adds the message to the Danger table. The message highlights
 // low-priority issues, but does not fail the build.
 func (s *T) Warn(message string, file string, line int) {
-	s.results.Warnings = append(s.results.Warnings,
-		Violation{
-			Message: message,
-			File:    file,
-			Line:    line,
-		})
+	v := Violation{
+		Message: message,
+		File:    file,
+		Line:    line,
+		Rule:    s.rule,
+	}
+	s.results.Warnings = append(s.results.Warnings, v)
+	s.report(KindWarning, v)
 }
 
 // Fail a build, outputting a specific reason for failing into an HTML table.
+// While SetFailsAsWarnings is on, the reason is added as a warning instead,
+// unless a Strict rule is running.
 func (s *T) Fail(message string, file string, line int) {
-	s.results.Fails = append(s.results.Fails,
-		Violation{
-			Message: message,
-			File:    file,
-			Line:    line,
-		})
+	if s.failsAsWarnings && !s.strict {
+		s.Warn(message, file, line)
+		return
+	}
+	v := Violation{
+		Message: message,
+		File:    file,
+		Line:    line,
+		Rule:    s.rule,
+	}
+	s.results.Fails = append(s.results.Fails, v)
+	s.report(KindFail, v)
+}
+
+// SetFailsAsWarnings relaxes the severity of the rules run afterwards: while
+// on, Fail adds warnings, e.g. for the first PR of a new contributor. Strict
+// rules opt out.
+func (s *T) SetFailsAsWarnings(on bool) {
+	s.failsAsWarnings = on
 }
 
 // Markdown adds the message as raw markdown into the Danger comment, under the
 // table.
 func (s *T) Markdown(message string, file string, line int) {
-	s.results.Markdowns = append(s.results.Markdowns,
-		Violation{
-			Message: message,
-			File:    file,
-			Line:    line,
-		})
+	v := Violation{
+		Message: message,
+		File:    file,
+		Line:    line,
+		Rule:    s.rule,
+	}
+	s.results.Markdowns = append(s.results.Markdowns, v)
+	s.report(KindMarkdown, v)
+}
+
+// SafeMode reports whether danger runs for a PR from a fork with a read-only
+// token. Write operations are skipped in safe mode and results are reported
+// as GitHub Actions annotations and in the job summary.
+func SafeMode() bool {
+	return dangerJs.SafeMode()
 }
@@ -19,3 +19,31 @@ func TestResults(t *testing.T) {
 	require.Nil(t, err)
 	require.Equal(t, `{"fails":[],"warnings":[],"messages":[{"message":"test"}],"markdowns":[]}`, r)
 }
+
+func TestSetFailsAsWarnings(t *testing.T) {
+	d := danger.New()
+	d.SetFailsAsWarnings(true)
+	d.Fail("relaxed", "main.go", 3)
+	d.RunRules(danger.DSL{}, danger.Rule{Name: "secrets", Strict: true, Run: func(d *danger.T, _ danger.DSL) {
+		d.Fail("leaked key", "", 0)
+	}})
+	d.SetFailsAsWarnings(false)
+	d.Fail("strict", "", 0)
+
+	r := d.Snapshot()
+	require.Equal(t, []danger.Violation{{Message: "relaxed", File: "main.go", Line: 3}}, r.Warnings)
+	require.Equal(t, []danger.Violation{{Message: "leaked key", Rule: "secrets"}, {Message: "strict"}}, r.Fails)
+}
+
+func TestFixes(t *testing.T) {
+	d := danger.New()
+	d.AddFix("Format main.go", "--- a/main.go\n+++ b/main.go\n")
+	d.RunRules(danger.DSL{}, danger.Rule{Name: "gofmt", Run: func(d *danger.T, _ danger.DSL) {
+		d.AddFix("Format util.go", "--- a/util.go\n+++ b/util.go\n")
+	}})
+
+	require.Equal(t, []danger.Fix{
+		{Description: "Format main.go", Patch: "--- a/main.go\n+++ b/main.go\n"},
+		{Rule: "gofmt", Description: "Format util.go", Patch: "--- a/util.go\n+++ b/util.go\n"},
+	}, d.Fixes())
+}
@@ -0,0 +1,119 @@
+// Package artifacts uploads reports too large for a PR comment, such as HTML
+// coverage or full lint output, and links them from the Danger comment.
+// Reports can be stored as a GitHub gist, a GitHub release asset or an S3
+// object.
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	danger "github.com/danger/golang"
+	"github.com/danger/golang/githubclient"
+)
+
+// DefaultInlineLimit is the size up to which Attach inlines text reports.
+const DefaultInlineLimit = 10000
+
+// Uploader stores a report and returns a URL to it.
+type Uploader interface {
+	Upload(ctx context.Context, name string, content []byte, contentType string) (string, error)
+}
+
+// Gist uploads reports as gists.
+type Gist struct {
+	Client      *githubclient.Client
+	Public      bool
+	Description string
+}
+
+// Upload creates a gist holding the report and returns its URL.
+func (g Gist) Upload(ctx context.Context, name string, content []byte, _ string) (string, error) {
+	if !utf8.Valid(content) {
+		return "", fmt.Errorf("gists only hold text, %s is binary", name)
+	}
+	gist, err := g.Client.CreateGist(ctx, g.Description, g.Public, map[string]string{name: string(content)})
+	if err != nil {
+		return "", fmt.Errorf("creating gist: %w", err)
+	}
+	return gist.HTMLURL, nil
+}
+
+// ReleaseAsset uploads reports as assets of an existing release, e.g. a
+// prerelease dedicated to CI reports. Asset names must be unique within the
+// release.
+type ReleaseAsset struct {
+	Client *githubclient.Client
+	Owner  string
+	Repo   string
+	Tag    string
+}
+
+// Upload attaches the report to the release and returns its download URL.
+func (r ReleaseAsset) Upload(ctx context.Context, name string, content []byte, contentType string) (string, error) {
+	release, err := r.Client.ReleaseByTag(ctx, r.Owner, r.Repo, r.Tag)
+	if err != nil {
+		return "", fmt.Errorf("finding release %s: %w", r.Tag, err)
+	}
+	asset, err := r.Client.UploadReleaseAsset(ctx, release, name, contentType, content)
+	if err != nil {
+		return "", fmt.Errorf("uploading release asset: %w", err)
+	}
+	return asset.BrowserDownloadURL, nil
+}
+
+// Report is a generated report to attach to the Danger comment.
+type Report struct {
+	// Name is the file name of the uploaded report, e.g. "coverage.html".
+	Name        string
+	Content     []byte
+	ContentType string
+	// Title is the link text, Name when empty.
+	Title string
+	// Summary is a short text shown next to the link, e.g. "82% covered".
+	Summary string
+	// InlineLimit is the size up to which a text report is shown in a
+	// collapsed block instead of being uploaded, DefaultInlineLimit when
+	// zero and never when negative.
+	InlineLimit int
+}
+
+// Attach adds the report to the Danger comment, inline when it is short text
+// and uploaded with u and linked otherwise.
+func Attach(d *danger.T, u Uploader, r Report) error {
+	title := r.Title
+	if title == "" {
+		title = r.Name
+	}
+	summary := ""
+	if r.Summary != "" {
+		summary = ": " + r.Summary
+	}
+
+	limit := r.InlineLimit
+	if limit == 0 {
+		limit = DefaultInlineLimit
+	}
+	if len(r.Content) <= limit && utf8.Valid(r.Content) && !strings.Contains(string(r.Content), "```") {
+		d.Markdown(fmt.Sprintf("<details>\n<summary>%s%s</summary>\n\n```\n%s\n```\n</details>",
+			title, summary, strings.TrimRight(string(r.Content), "\n")), "", 0)
+		return nil
+	}
+
+	contentType := r.ContentType
+	if contentType == "" {
+		contentType = "text/plain; charset=utf-8"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	link, err := u.Upload(ctx, r.Name, r.Content, contentType)
+	if err != nil {
+		d.Warn(fmt.Sprintf("Could not upload %s: %s", title, err), "", 0)
+		return err
+	}
+	d.Markdown(fmt.Sprintf("📎 [%s](%s)%s", title, link, summary), "", 0)
+	return nil
+}
@@ -0,0 +1,217 @@
+// Package autofix applies the fixes rules add with danger.T.AddFix to the
+// checkout and commits them to the PR branch, through the GitHub or GitLab
+// API or with git push.
+package autofix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// DefaultMessage is the message of fix commits.
+const DefaultMessage = "Apply fixes suggested by danger-go"
+
+// File is a file changed by the applied fixes.
+type File struct {
+	// Path is relative to the repository root.
+	Path    string
+	New     bool
+	Deleted bool
+}
+
+// Failure is a fix which could not be applied.
+type Failure struct {
+	Fix danger.Fix
+	Err error
+}
+
+// Result is the outcome of applying fixes.
+type Result struct {
+	Applied []danger.Fix
+	Failed  []Failure
+	// Files are the files changed by the applied fixes.
+	Files []File
+	// Commit is the SHA of the commit of the fixes, if committed.
+	Commit string
+}
+
+// Apply applies the patch of each fix to the working tree of the git
+// repository in dir. A fix which doesn't apply cleanly, e.g. because an
+// earlier fix changed the same lines, is left out and recorded in Failed.
+func Apply(ctx context.Context, dir string, fixes []danger.Fix) Result {
+	var r Result
+	seen := map[string]int{}
+	for _, f := range fixes {
+		files, err := patchFiles(f.Patch)
+		if err == nil {
+			if _, err = git(ctx, dir, f.Patch, "apply", "--check", "-"); err == nil {
+				_, err = git(ctx, dir, f.Patch, "apply", "-")
+			}
+		}
+		if err != nil {
+			r.Failed = append(r.Failed, Failure{Fix: f, Err: err})
+			continue
+		}
+		r.Applied = append(r.Applied, f)
+		for _, file := range files {
+			i, ok := seen[file.Path]
+			if !ok {
+				seen[file.Path] = len(r.Files)
+				r.Files = append(r.Files, file)
+				continue
+			}
+			// a file deleted and created again by later fixes is updated
+			prev := r.Files[i]
+			r.Files[i] = File{Path: file.Path, New: prev.New && !file.Deleted, Deleted: file.Deleted && !prev.New}
+		}
+	}
+	return r
+}
+
+// patchFiles returns the files a unified diff changes, from its ---/+++
+// headers. A rename is a deletion and a creation.
+func patchFiles(patch string) ([]File, error) {
+	var files []File
+	var from string
+	for _, line := range strings.Split(patch, "\n") {
+		switch {
+		case strings.HasPrefix(line, "--- "):
+			from = headerPath(line[4:])
+		case strings.HasPrefix(line, "+++ "):
+			to := headerPath(line[4:])
+			switch {
+			case from == "" && to == "":
+				return nil, errors.New("patch header names no file")
+			case from == "":
+				files = append(files, File{Path: to, New: true})
+			case to == "":
+				files = append(files, File{Path: from, Deleted: true})
+			case from != to:
+				files = append(files, File{Path: from, Deleted: true}, File{Path: to, New: true})
+			default:
+				files = append(files, File{Path: to})
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil, errors.New("patch changes no files")
+	}
+	return files, nil
+}
+
+// headerPath returns the path of a ---/+++ header without its a/ or b/
+// prefix, timestamp and git quoting, or "" for /dev/null.
+func headerPath(h string) string {
+	h, _, _ = strings.Cut(h, "\t")
+	h = dangerJs.UnquotePath(strings.TrimSpace(h))
+	if h == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(h, "a/") || strings.HasPrefix(h, "b/") {
+		return h[2:]
+	}
+	return h
+}
+
+func git(ctx context.Context, dir, stdin string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(stdin)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Committer commits the files changed in the working tree of dir to the PR
+// branch, returning the SHA of the commit.
+type Committer interface {
+	Commit(ctx context.Context, dir, message string, files []File) (string, error)
+}
+
+// Run applies fixes in dir and commits them with c. The fixes stay applied
+// in dir when the commit fails.
+func Run(ctx context.Context, dir string, fixes []danger.Fix, c Committer) (Result, error) {
+	r := Apply(ctx, dir, fixes)
+	if len(r.Applied) == 0 {
+		return r, nil
+	}
+	sha, err := c.Commit(ctx, dir, DefaultMessage, r.Files)
+	if err != nil {
+		return r, fmt.Errorf("committing fixes: %w", err)
+	}
+	r.Commit = sha
+	return r, nil
+}
+
+// Report reports the fixes committed as a message and those which failed to
+// apply as warnings.
+func Report(d *danger.T, r Result) {
+	if r.Commit != "" && len(r.Applied) > 0 {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "Committed %d %s in %s:\n", len(r.Applied), plural(len(r.Applied), "fix", "fixes"), r.Commit)
+		for _, f := range r.Applied {
+			sb.WriteString("\n- " + describe(f))
+		}
+		d.Message(sb.String(), "", 0)
+	}
+	for _, f := range r.Failed {
+		d.Warn(fmt.Sprintf("Could not apply the fix %s: %s", describe(f.Fix), f.Err.Error()), "", 0)
+	}
+}
+
+// Suggest lists fixes with their patches in a collapsed block, for runs
+// which don't commit them.
+func Suggest(d *danger.T, fixes []danger.Fix) {
+	if len(fixes) == 0 {
+		return
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<details>\n<summary>%d %s available</summary>\n", len(fixes), plural(len(fixes), "fix", "fixes"))
+	for _, f := range fixes {
+		fmt.Fprintf(&sb, "\n%s\n\n```diff\n%s\n```\n", describe(f), strings.TrimRight(f.Patch, "\n"))
+	}
+	sb.WriteString("\n</details>")
+	d.Markdown(sb.String(), "", 0)
+}
+
+func describe(f danger.Fix) string {
+	if f.Rule == "" {
+		return f.Description
+	}
+	return fmt.Sprintf("%s (%s)", f.Description, f.Rule)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// readFile returns the content of a changed file and whether it is
+// executable.
+func readFile(dir string, f File) (string, bool, error) {
+	if f.Deleted {
+		return "", false, nil
+	}
+	path := filepath.Join(dir, filepath.FromSlash(f.Path))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false, err
+	}
+	return string(data), info.Mode()&0o111 != 0, nil
+}
@@ -0,0 +1,218 @@
+package autofix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/githubclient"
+)
+
+const fixA = `--- a/a.go
++++ b/a.go
+@@ -1,2 +1,2 @@
+ package a
+-var x=1
++var x = 1
+`
+
+const fixNew = `--- /dev/null
++++ b/b.go
+@@ -0,0 +1 @@
++package a
+`
+
+func run(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.Nil(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+// newRepo creates a repository with a.go committed.
+func newRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	run(t, dir, "init", "-q", "-b", "feature")
+	run(t, dir, "config", "user.name", "test")
+	run(t, dir, "config", "user.email", "test@example.com")
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\nvar x=1\n"), 0o644))
+	run(t, dir, "add", "a.go")
+	run(t, dir, "commit", "-qm", "init")
+	return dir
+}
+
+func TestPatchFiles(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		want  []File
+		err   bool
+	}{
+		{name: "modified", patch: fixA, want: []File{{Path: "a.go"}}},
+		{name: "created", patch: fixNew, want: []File{{Path: "b.go", New: true}}},
+		{name: "deleted", patch: "--- a/a.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-package a\n", want: []File{{Path: "a.go", Deleted: true}}},
+		{name: "renamed", patch: "--- a/a.go\t2024-01-01\n+++ b/c.go\t2024-01-01\n", want: []File{{Path: "a.go", Deleted: true}, {Path: "c.go", New: true}}},
+		{name: "several files", patch: fixA + fixNew, want: []File{{Path: "a.go"}, {Path: "b.go", New: true}}},
+		{name: "quoted path", patch: "--- \"a/caf\\303\\251.go\"\n+++ \"b/caf\\303\\251.go\"\n", want: []File{{Path: "café.go"}}},
+		{name: "no header", patch: "@@ -1 +1 @@\n-a\n+b\n", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := patchFiles(tt.patch)
+			if tt.err {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, files)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	dir := newRepo(t)
+	r := Apply(context.Background(), dir, []danger.Fix{
+		{Rule: "gofmt", Description: "Format a.go", Patch: fixA},
+		{Description: "Conflicting", Patch: fixA},
+		{Description: "Add b.go", Patch: fixNew},
+	})
+
+	require.Len(t, r.Applied, 2)
+	require.Equal(t, "Format a.go", r.Applied[0].Description)
+	require.Equal(t, "Add b.go", r.Applied[1].Description)
+	require.Len(t, r.Failed, 1)
+	require.Equal(t, "Conflicting", r.Failed[0].Fix.Description)
+	require.Equal(t, []File{{Path: "a.go"}, {Path: "b.go", New: true}}, r.Files)
+
+	a, err := os.ReadFile(filepath.Join(dir, "a.go"))
+	require.Nil(t, err)
+	require.Equal(t, "package a\nvar x = 1\n", string(a))
+	_, err = os.Stat(filepath.Join(dir, "b.go"))
+	require.Nil(t, err)
+}
+
+func TestRunGitPush(t *testing.T) {
+	dir := newRepo(t)
+	remote := t.TempDir()
+	run(t, remote, "init", "-q", "--bare")
+	run(t, dir, "remote", "add", "origin", remote)
+	run(t, dir, "push", "-q", "origin", "feature")
+
+	r, err := Run(context.Background(), dir, []danger.Fix{{Description: "Format a.go", Patch: fixA}, {Description: "Add b.go", Patch: fixNew}},
+		GitPush{Branch: "feature", HeadSHA: run(t, dir, "rev-parse", "HEAD"), Name: "danger-go", Email: "danger-go@example.com"})
+	require.Nil(t, err)
+	require.NotEmpty(t, r.Commit)
+	require.Equal(t, r.Commit, run(t, remote, "rev-parse", "feature"))
+	require.Equal(t, "danger-go "+DefaultMessage, run(t, remote, "log", "-1", "--format=%an %s", "feature"))
+	require.Equal(t, "a.go\nb.go", run(t, remote, "show", "--format=", "--name-only", "feature"))
+}
+
+func TestRunNotPRHead(t *testing.T) {
+	dir := newRepo(t)
+	head := run(t, dir, "rev-parse", "HEAD")
+	// a merge commit checkout, as for pull_request events
+	run(t, dir, "commit", "-q", "--allow-empty", "-m", "Merge into base")
+
+	tests := []struct {
+		name string
+		c    Committer
+	}{
+		{name: "push", c: GitPush{Branch: "feature", HeadSHA: head}},
+		{name: "push without head", c: GitPush{Branch: "feature"}},
+		{name: "GitHub API", c: GitHubAPI{Branch: "feature", HeadSHA: head}},
+		{name: "GitLab API", c: GitLabAPI{Branch: "feature", HeadSHA: head}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.c.Commit(context.Background(), dir, DefaultMessage, []File{{Path: "a.go"}})
+			require.True(t, errors.Is(err, ErrNotPRHead), err)
+		})
+	}
+}
+
+func TestRunNothingApplied(t *testing.T) {
+	dir := newRepo(t)
+	r, err := Run(context.Background(), dir, []danger.Fix{{Description: "Stale", Patch: strings.ReplaceAll(fixA, "var x=1", "var y=1")}}, GitPush{Branch: "feature"})
+	require.Nil(t, err)
+	require.Empty(t, r.Commit)
+	require.Len(t, r.Failed, 1)
+}
+
+func TestGitHubAPI(t *testing.T) {
+	var tree struct {
+		Tree []map[string]any `json:"tree"`
+	}
+	dir := newRepo(t)
+	head := run(t, dir, "rev-parse", "HEAD")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/repos/o/r/git/ref/heads/feature":
+			_, _ = w.Write([]byte(`{"object":{"sha":"` + head + `"}}`))
+		case "/api/v3/repos/o/r/git/commits/" + head:
+			_, _ = w.Write([]byte(`{"tree":{"sha":"base"}}`))
+		case "/api/v3/repos/o/r/git/trees":
+			body, _ := io.ReadAll(r.Body)
+			require.Nil(t, json.Unmarshal(body, &tree))
+			_, _ = w.Write([]byte(`{"sha":"tree"}`))
+		default:
+			_, _ = w.Write([]byte(`{"sha":"fixed"}`))
+		}
+	}))
+	defer srv.Close()
+	c, err := githubclient.New(srv.URL, "")
+	require.Nil(t, err)
+
+	r, err := Run(context.Background(), dir, []danger.Fix{{Description: "Format a.go", Patch: fixA}}, GitHubAPI{Client: c, Owner: "o", Repo: "r", Branch: "feature", HeadSHA: head})
+	require.Nil(t, err)
+	require.Equal(t, "fixed", r.Commit)
+	require.Equal(t, []map[string]any{{"path": "a.go", "mode": "100644", "type": "blob", "content": "package a\nvar x = 1\n"}}, tree.Tree)
+}
+
+func TestReport(t *testing.T) {
+	d := danger.New()
+	Report(d, Result{
+		Applied: []danger.Fix{{Rule: "gofmt", Description: "Format a.go"}, {Description: "Add b.go"}},
+		Failed:  []Failure{{Fix: danger.Fix{Description: "Sort imports"}, Err: io.ErrUnexpectedEOF}},
+		Commit:  "abc123",
+	})
+	r := d.Snapshot()
+	require.Len(t, r.Messages, 1)
+	require.Equal(t, "Committed 2 fixes in abc123:\n\n- Format a.go (gofmt)\n- Add b.go", r.Messages[0].Message)
+	require.Len(t, r.Warnings, 1)
+	require.Equal(t, "Could not apply the fix Sort imports: unexpected EOF", r.Warnings[0].Message)
+}
+
+func TestSuggest(t *testing.T) {
+	d := danger.New()
+	Suggest(d, []danger.Fix{{Rule: "gofmt", Description: "Format a.go", Patch: fixA}})
+	r := d.Snapshot()
+	require.Len(t, r.Markdowns, 1)
+	require.Equal(t, "<details>\n<summary>1 fix available</summary>\n\nFormat a.go (gofmt)\n\n```diff\n"+strings.TrimSuffix(fixA, "\n")+"\n```\n\n</details>", r.Markdowns[0].Message)
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv(dangerJs.SafeModeEnv, "false")
+	t.Setenv("DANGER_AUTOFIX", "")
+	c, err := FromEnv(danger.DSL{})
+	require.Nil(t, err)
+	require.Nil(t, c)
+
+	t.Setenv("DANGER_AUTOFIX", "push")
+	_, err = FromEnv(danger.DSL{})
+	require.NotNil(t, err)
+}
@@ -0,0 +1,193 @@
+package autofix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	danger "github.com/danger/golang"
+	"github.com/danger/golang/githubclient"
+	"github.com/danger/golang/gitlabclient"
+)
+
+// ErrNotPRHead is returned by the committers when the checkout or the PR
+// branch is not at the PR head the fixes were made against, e.g. for the
+// merge commit checked out for pull_request events or after a push to the
+// branch. Committing would revert the new commits or copy changes of the base
+// branch into the PR branch.
+var ErrNotPRHead = errors.New("not at the PR head")
+
+// checkHead refuses to commit unless HEAD of dir is headSHA.
+func checkHead(ctx context.Context, dir, headSHA string) error {
+	if headSHA == "" {
+		return fmt.Errorf("%w: the PR head commit is unknown", ErrNotPRHead)
+	}
+	head, err := git(ctx, dir, "", "rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	if head != headSHA {
+		return fmt.Errorf("%w: the checkout is at %s, the PR head at %s", ErrNotPRHead, head, headSHA)
+	}
+	return nil
+}
+
+// GitPush commits with git and pushes the commit to Branch of Remote. The
+// remote, "origin" by default, may be a URL with credentials; the remote of
+// CI checkouts usually has them configured.
+type GitPush struct {
+	Remote string
+	Branch string
+	// HeadSHA is the PR head, which must be checked out.
+	HeadSHA string
+	// Name and Email are the identity of the commit when set, e.g. for CI
+	// runners without a configured git identity.
+	Name  string
+	Email string
+}
+
+// Commit implements Committer.
+func (g GitPush) Commit(ctx context.Context, dir, message string, files []File) (string, error) {
+	if err := checkHead(ctx, dir, g.HeadSHA); err != nil {
+		return "", err
+	}
+	args := []string{"add", "-A", "--"}
+	for _, f := range files {
+		args = append(args, f.Path)
+	}
+	if _, err := git(ctx, dir, "", args...); err != nil {
+		return "", err
+	}
+	commit := []string{"commit", "-m", message}
+	if g.Name != "" {
+		commit = append([]string{"-c", "user.name=" + g.Name, "-c", "user.email=" + g.Email}, commit...)
+	}
+	if _, err := git(ctx, dir, "", commit...); err != nil {
+		return "", err
+	}
+	sha, err := git(ctx, dir, "", "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	remote := g.Remote
+	if remote == "" {
+		remote = "origin"
+	}
+	if _, err := git(ctx, dir, "", "push", remote, "HEAD:refs/heads/"+g.Branch); err != nil {
+		return "", err
+	}
+	return sha, nil
+}
+
+// GitHubAPI commits with the git data API of GitHub, so no push access of
+// the checkout is needed. The commit is signed by GitHub when made with an
+// app token.
+type GitHubAPI struct {
+	Client      *githubclient.Client
+	Owner, Repo string
+	Branch      string
+	// HeadSHA is the PR head, which must be checked out and still be the
+	// head of Branch.
+	HeadSHA string
+}
+
+// Commit implements Committer.
+func (g GitHubAPI) Commit(ctx context.Context, dir, message string, files []File) (string, error) {
+	if err := checkHead(ctx, dir, g.HeadSHA); err != nil {
+		return "", err
+	}
+	changes := make([]githubclient.CommitFile, len(files))
+	for i, f := range files {
+		content, executable, err := readFile(dir, f)
+		if err != nil {
+			return "", fmt.Errorf("reading %s: %w", f.Path, err)
+		}
+		changes[i] = githubclient.CommitFile{Path: f.Path, Content: content, Executable: executable, Deleted: f.Deleted}
+	}
+	return g.Client.CommitFiles(ctx, g.Owner, g.Repo, g.Branch, g.HeadSHA, message, changes)
+}
+
+// GitLabAPI commits with the commits API of GitLab.
+type GitLabAPI struct {
+	Client  *gitlabclient.Client
+	Project string
+	Branch  string
+	// HeadSHA is the MR head, which must be checked out and still be the
+	// head of Branch.
+	HeadSHA string
+}
+
+// Commit implements Committer.
+func (g GitLabAPI) Commit(ctx context.Context, dir, message string, files []File) (string, error) {
+	if err := checkHead(ctx, dir, g.HeadSHA); err != nil {
+		return "", err
+	}
+	changes := make([]gitlabclient.CommitFile, len(files))
+	for i, f := range files {
+		content, executable, err := readFile(dir, f)
+		if err != nil {
+			return "", fmt.Errorf("reading %s: %w", f.Path, err)
+		}
+		changes[i] = gitlabclient.CommitFile{Path: f.Path, Content: content, Executable: executable, Deleted: f.Deleted, New: f.New}
+		if !f.New {
+			// GitLab refuses the action when the file changed since
+			if changes[i].LastCommitID, err = git(ctx, dir, "", "log", "-1", "--format=%H", g.HeadSHA, "--", f.Path); err != nil {
+				return "", err
+			}
+		}
+	}
+	return g.Client.CommitFiles(ctx, g.Project, g.Branch, g.HeadSHA, message, changes)
+}
+
+// ErrForkPR is returned by FromEnv for PRs from forks, whose branch the
+// token can't commit to.
+var ErrForkPR = errors.New("fixes can't be committed to the branch of a fork")
+
+// FromEnv returns the committer configured by DANGER_AUTOFIX: "api" commits
+// with the GitHub or GitLab API and "push" pushes to DANGER_AUTOFIX_REMOTE,
+// "origin" by default. It returns nil when autofix is off or in fork-PR safe
+// mode, where fixes are only suggested.
+func FromEnv(pr danger.DSL) (Committer, error) {
+	mode := os.Getenv("DANGER_AUTOFIX")
+	if mode == "" || mode == "false" || danger.SafeMode() {
+		return nil, nil
+	}
+	var branch, headSHA string
+	switch {
+	case pr.GitHub != nil && pr.GitHub.PR().Number != 0:
+		head := pr.GitHub.PR().Head
+		if head.Repo.FullName != pr.GitHub.PR().Base.Repo.FullName {
+			return nil, ErrForkPR
+		}
+		branch, headSHA = head.Ref, head.SHA
+	case pr.GitLab != nil && pr.GitLab.MR().IID != 0:
+		mr := pr.GitLab.MR()
+		if mr.SourceProjectID != mr.TargetProjectID {
+			return nil, ErrForkPR
+		}
+		branch, headSHA = mr.SourceBranch, mr.SHA
+	default:
+		return nil, errors.New("autofix requires a GitHub or GitLab PR")
+	}
+
+	switch mode {
+	case "push":
+		return GitPush{Remote: os.Getenv("DANGER_AUTOFIX_REMOTE"), Branch: branch, HeadSHA: headSHA, Name: "danger-go", Email: "danger-go@users.noreply.github.com"}, nil
+	case "api":
+		if pr.GitHub != nil && pr.GitHub.PR().Number != 0 {
+			c, err := githubclient.NewFromSettings(pr.Settings)
+			if err != nil {
+				return nil, err
+			}
+			this := pr.GitHub.ThisPR()
+			return GitHubAPI{Client: c, Owner: this.Owner, Repo: this.Repo, Branch: branch, HeadSHA: headSHA}, nil
+		}
+		c, err := gitlabclient.NewFromSettings(pr.Settings)
+		if err != nil {
+			return nil, err
+		}
+		return GitLabAPI{Client: c, Project: pr.GitLab.Metadata().RepoSlug, Branch: branch, HeadSHA: headSHA}, nil
+	}
+	return nil, fmt.Errorf("DANGER_AUTOFIX is %q, want api or push", mode)
+}
@@ -0,0 +1,154 @@
+package danger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CommentStateMarker starts the hidden marker recording the violations of a
+// run in the Danger comment, see MarkChanges.
+const CommentStateMarker = "<!-- danger-go:state"
+
+// newMarker flags violations which were not in the previous run.
+const newMarker = "🆕 "
+
+// WithChangeMarkers flags the violations which are new since the previous
+// run, see SetChangeMarkers.
+func WithChangeMarkers() Option {
+	return func(t *T) {
+		t.changeMarkers = true
+	}
+}
+
+// SetChangeMarkers enables flagging the violations which are new since the
+// previous run of Danger on the PR, and counting the resolved ones. The
+// runner looks up the previous Danger comment on GitHub, see MarkChanges.
+func (s *T) SetChangeMarkers(on bool) {
+	s.changeMarkers = on
+}
+
+// ChangeMarkers reports whether change markers are enabled.
+func (s *T) ChangeMarkers() bool {
+	return s.changeMarkers
+}
+
+// SetPreviousComment sets the body of the Danger comment of the previous run
+// the results are compared with when change markers are enabled, and the
+// checklist is read from.
+func (s *T) SetPreviousComment(body string) {
+	s.previousComment = body
+	s.loadPrevious = nil
+	s.checked = nil
+}
+
+// SetPreviousCommentLoader sets how the body of the previous Danger comment
+// is read the first time it is needed, e.g. from the API, so runs using
+// neither change markers nor checklists don't read it.
+func (s *T) SetPreviousCommentLoader(load func() string) {
+	s.loadPrevious = load
+	s.checked = nil
+}
+
+func (s *T) previous() string {
+	if s.loadPrevious != nil {
+		s.previousComment = s.loadPrevious()
+		s.loadPrevious = nil
+	}
+	return s.previousComment
+}
+
+// violationKey identifies a violation across runs. The line is left out, as
+// are the lines listed by grouped inline comments, so that a violation moved
+// by unrelated edits is not reported as new.
+func violationKey(kind Kind, v Violation) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{string(kind), v.Rule, v.File, threadKeyMessage(v)}, "\x00")))
+	return hex.EncodeToString(sum[:4])
+}
+
+func resultKeys(r Results) []string {
+	var keys []string
+	for _, g := range []struct {
+		kind Kind
+		vs   []Violation
+	}{{KindFail, r.Fails}, {KindWarning, r.Warnings}, {KindMessage, r.Messages}, {KindMarkdown, r.Markdowns}} {
+		for _, v := range g.vs {
+			keys = append(keys, violationKey(g.kind, v))
+		}
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// previousKeys reads the violation keys recorded in the comment body of the
+// previous run, if it has any.
+func previousKeys(body string) ([]string, bool) {
+	i := strings.Index(body, CommentStateMarker)
+	if i < 0 {
+		return nil, false
+	}
+	rest := body[i+len(CommentStateMarker):]
+	j := strings.Index(rest, "-->")
+	if j < 0 {
+		return nil, false
+	}
+	var keys []string
+	for _, k := range strings.Split(strings.TrimSpace(rest[:j]), ",") {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys, true
+}
+
+// MarkChanges compares r with the results recorded in previous, the body of
+// the Danger comment of the last run. Violations missing from it are flagged
+// with 🆕 and the resolved ones are counted in a markdown, which also records
+// the state of this run for the next one. It reports whether the results are
+// unchanged since the last run. Without a previous state only the state is
+// recorded.
+func MarkChanges(r Results, previous string) (Results, bool) {
+	keys := resultKeys(r)
+	state := fmt.Sprintf("%s %s -->", CommentStateMarker, strings.Join(keys, ","))
+	prev, ok := previousKeys(previous)
+	marked := r
+	marked.Markdowns = append(slices.Clone(r.Markdowns), Violation{Message: state})
+	if !ok {
+		return marked, false
+	}
+
+	seen := map[string]bool{}
+	for _, k := range prev {
+		seen[k] = true
+	}
+	flag := func(kind Kind, vs []Violation) []Violation {
+		out := slices.Clone(vs)
+		for i, v := range out {
+			if !seen[violationKey(kind, v)] {
+				out[i].Message = newMarker + v.Message
+			}
+		}
+		return out
+	}
+	marked.Fails = flag(KindFail, r.Fails)
+	marked.Warnings = flag(KindWarning, r.Warnings)
+	marked.Messages = flag(KindMessage, r.Messages)
+
+	current := map[string]bool{}
+	for _, k := range keys {
+		current[k] = true
+	}
+	resolved := 0
+	for _, k := range prev {
+		if !current[k] {
+			resolved++
+		}
+	}
+	if resolved > 0 {
+		marked.Markdowns[len(marked.Markdowns)-1].Message = fmt.Sprintf("✅ %d %s resolved since the last run.\n%s",
+			resolved, plural(resolved, "violation", "violations"), state)
+	}
+	return marked, slices.Equal(prev, keys)
+}
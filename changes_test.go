@@ -0,0 +1,69 @@
+package danger_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+)
+
+func TestMarkChanges(t *testing.T) {
+	first := danger.Results{
+		Fails:    []danger.Violation{{Message: "missing changelog"}},
+		Warnings: []danger.Violation{{Message: "big PR"}, {Message: "TODO left", File: "a.go", Line: 3}},
+	}
+	marked, unchanged := danger.MarkChanges(first, "")
+	require.False(t, unchanged)
+	require.Equal(t, first.Fails, marked.Fails, "nothing is flagged without a previous run")
+	require.Len(t, marked.Markdowns, 1)
+	state := marked.Markdowns[0].Message
+	require.True(t, strings.HasPrefix(state, danger.CommentStateMarker))
+
+	// the previous comment is rendered by danger-js around the markdowns
+	previous := "<table>…</table>\n\n" + state + "\n\n<p>Generated by Danger</p>"
+	again, unchanged := danger.MarkChanges(first, previous)
+	require.True(t, unchanged)
+	require.Equal(t, first.Warnings, again.Warnings)
+	require.Equal(t, state, again.Markdowns[0].Message)
+
+	// the TODO moved, the changelog was fixed and a new warning appeared
+	second := danger.Results{
+		Warnings: []danger.Violation{{Message: "big PR"}, {Message: "TODO left", File: "a.go", Line: 9}, {Message: "no tests"}},
+	}
+	marked, unchanged = danger.MarkChanges(second, previous)
+	require.False(t, unchanged)
+	require.Equal(t, []danger.Violation{
+		{Message: "big PR"},
+		{Message: "TODO left", File: "a.go", Line: 9},
+		{Message: "🆕 no tests"},
+	}, marked.Warnings)
+	require.True(t, strings.HasPrefix(marked.Markdowns[0].Message, "✅ 1 violation resolved since the last run.\n"+danger.CommentStateMarker))
+}
+
+func TestFitCommentKeepsState(t *testing.T) {
+	r, _ := danger.MarkChanges(danger.Results{Warnings: violations(100, 100)}, "")
+	got, _ := danger.FitComment(r, danger.CommentLimit{Max: 3000})
+	require.Contains(t, got.Markdowns[len(got.Markdowns)-1].Message, danger.CommentStateMarker)
+}
+
+func TestMarkChangesGroupedInlineComments(t *testing.T) {
+	thread := func(lines ...int) danger.Results {
+		var r danger.Results
+		for _, l := range lines {
+			r.Warnings = append(r.Warnings, danger.Violation{Message: "TODO left", File: "a.go", Line: l, Rule: "todo"})
+		}
+		return danger.GroupInlineComments(r, danger.InlineThreads{Min: 2})
+	}
+	first, _ := danger.MarkChanges(thread(3, 5), "")
+	previous := first.Markdowns[0].Message
+
+	moved, unchanged := danger.MarkChanges(thread(13, 15), previous)
+	require.True(t, unchanged, "the thread moved with unrelated edits")
+	require.False(t, strings.HasPrefix(moved.Warnings[0].Message, "🆕"))
+
+	grown, unchanged := danger.MarkChanges(thread(3, 5, 7), previous)
+	require.False(t, unchanged)
+	require.True(t, strings.HasPrefix(grown.Warnings[0].Message, "🆕"), "the thread has a new occurrence")
+}
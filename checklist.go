@@ -0,0 +1,85 @@
+package danger
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ChecklistMarker starts the hidden marker identifying each item of the
+// checklist in the Danger comment.
+const ChecklistMarker = "<!-- danger-go:check "
+
+// ChecklistItem is a box of the checklist of the Danger comment.
+type ChecklistItem struct {
+	ID   string
+	Text string
+	// Checked is whether the box was ticked in the previous Danger comment.
+	Checked bool
+}
+
+var checklistRe = regexp.MustCompile(`(?m)^\s*[-*]\s+\[([ xX])\].*` + regexp.QuoteMeta(ChecklistMarker) + `(\S+) -->`)
+
+// ParseChecklist returns whether each checklist item of a Danger comment is
+// ticked, by ID.
+func ParseChecklist(body string) map[string]bool {
+	checked := map[string]bool{}
+	for _, m := range checklistRe.FindAllStringSubmatch(body, -1) {
+		checked[m[2]] = m[1] != " "
+	}
+	return checked
+}
+
+// Checklist adds an item to the checklist of the Danger comment and returns
+// whether it is ticked. The box stays ticked once ticked in the comment, so
+// a rule can pass when the item was acknowledged:
+//
+//	if !d.Checklist("migration-notes", "Added migration notes") {
+//		d.Warn("Describe how to migrate in the PR description", "", 0)
+//	}
+//
+// Only users who can edit the comment, such as maintainers, can tick boxes:
+// the runner only reads back Danger comments posted with its own token.
+func (s *T) Checklist(id, text string) bool {
+	checked := s.Checked(id)
+	i := slices.IndexFunc(s.checklist, func(item ChecklistItem) bool { return item.ID == id })
+	if i < 0 {
+		s.checklist = append(s.checklist, ChecklistItem{ID: id, Text: text, Checked: checked})
+	}
+	return checked
+}
+
+// Checked reports whether the checklist item id was ticked in the previous
+// Danger comment.
+func (s *T) Checked(id string) bool {
+	if s.checked == nil {
+		s.checked = ParseChecklist(s.previous())
+	}
+	return s.checked[id]
+}
+
+// ChecklistItems returns the items added with Checklist, in order.
+func (s *T) ChecklistItems() []ChecklistItem {
+	return slices.Clone(s.checklist)
+}
+
+// AddChecklist adds the checklist as a markdown, titled by the
+// danger.checklist message. The runner calls it once the dangerfile has run.
+func (s *T) AddChecklist() {
+	if len(s.checklist) == 0 {
+		return
+	}
+	var sb strings.Builder
+	if title := s.Msg("danger.checklist", nil); strings.TrimSpace(title) != "" {
+		sb.WriteString(title + "\n\n")
+	}
+	for _, item := range s.checklist {
+		box := " "
+		if item.Checked {
+			box = "x"
+		}
+		fmt.Fprintf(&sb, "- [%s] %s %s%s -->\n", box, item.Text, ChecklistMarker, item.ID)
+	}
+	s.Markdown(strings.TrimSuffix(sb.String(), "\n"), "", 0)
+}
@@ -0,0 +1,64 @@
+package danger_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+)
+
+func TestParseChecklist(t *testing.T) {
+	body := "<table>…</table>\n\n#### Checklist\n\n" +
+		"- [x] Added migration notes <!-- danger-go:check migration-notes -->\n" +
+		"- [ ] Updated the docs <!-- danger-go:check docs -->\n" +
+		"* [X] Ran the load tests <!-- danger-go:check load-tests -->\n" +
+		"- [x] Not an item of Danger\n"
+	require.Equal(t, map[string]bool{"migration-notes": true, "docs": false, "load-tests": true}, danger.ParseChecklist(body))
+	require.Empty(t, danger.ParseChecklist(""))
+}
+
+func TestChecklist(t *testing.T) {
+	d := danger.New()
+	d.SetPreviousComment("- [x] Added migration notes <!-- danger-go:check migration-notes -->\n" +
+		"- [ ] Updated the docs <!-- danger-go:check docs -->")
+
+	require.True(t, d.Checklist("migration-notes", "Added migration notes"))
+	require.False(t, d.Checklist("docs", "Updated the docs"))
+	require.False(t, d.Checklist("changelog", "Added a changelog entry"))
+	require.False(t, d.Checklist("docs", "Updated the docs again"), "items are added once")
+	require.Equal(t, []danger.ChecklistItem{
+		{ID: "migration-notes", Text: "Added migration notes", Checked: true},
+		{ID: "docs", Text: "Updated the docs"},
+		{ID: "changelog", Text: "Added a changelog entry"},
+	}, d.ChecklistItems())
+
+	d.AddChecklist()
+	require.Equal(t, []danger.Violation{{Message: "#### Checklist\n\n" +
+		"- [x] Added migration notes <!-- danger-go:check migration-notes -->\n" +
+		"- [ ] Updated the docs <!-- danger-go:check docs -->\n" +
+		"- [ ] Added a changelog entry <!-- danger-go:check changelog -->"}}, d.Snapshot().Markdowns)
+
+	// the rendered checklist keeps the ticks on the next run
+	next := danger.New()
+	next.SetPreviousComment(d.Snapshot().Markdowns[0].Message)
+	require.True(t, next.Checked("migration-notes"))
+	require.False(t, next.Checked("changelog"))
+}
+
+func TestChecklistLoadsPreviousCommentOnce(t *testing.T) {
+	d := danger.New()
+	calls := 0
+	d.SetPreviousCommentLoader(func() string {
+		calls++
+		return "- [x] Reviewed <!-- danger-go:check reviewed -->"
+	})
+	require.Equal(t, 0, calls, "loaded when first needed")
+	require.True(t, d.Checked("reviewed"))
+	require.True(t, d.Checklist("reviewed", "Reviewed"))
+	require.Equal(t, 1, calls)
+
+	empty := danger.New()
+	empty.AddChecklist()
+	require.Empty(t, empty.Snapshot().Markdowns)
+}
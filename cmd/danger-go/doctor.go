@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danger/golang/cmd/danger-go/runner"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/githubclient"
+	"github.com/danger/golang/gitlabclient"
+	"github.com/danger/golang/secrets"
+)
+
+// checkStatus is the outcome of a doctor check.
+type checkStatus int
+
+const (
+	checkOK checkStatus = iota
+	checkWarn
+	checkFail
+)
+
+func (s checkStatus) String() string {
+	switch s {
+	case checkWarn:
+		return "!"
+	case checkFail:
+		return "✗"
+	}
+	return "✓"
+}
+
+// doctorCheck checks one prerequisite of danger-go, returning its status and
+// a line of detail.
+type doctorCheck struct {
+	name string
+	run  func(ctx context.Context) (checkStatus, string)
+}
+
+// runDoctor writes a diagnostic bundle to w: the versions, the result of each
+// check and the configuration from the environment, secrets redacted. It
+// returns false when a check failed.
+func runDoctor(ctx context.Context, w io.Writer, checks []doctorCheck) (bool, error) {
+	ok := true
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "danger-go %s (%s %s/%s)\n\nChecks:\n", version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	for _, c := range checks {
+		status, detail := c.run(ctx)
+		if status == checkFail {
+			ok = false
+		}
+		fmt.Fprintf(&sb, "  %s %s: %s\n", status, c.name, detail)
+	}
+	sb.WriteString("\nConfiguration:\n")
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return false, err
+	}
+	if err := dangerJs.DumpEnv(&indentWriter{w: w}); err != nil {
+		return false, err
+	}
+	return ok, nil
+}
+
+// indentWriter indents the lines written to w.
+type indentWriter struct {
+	w io.Writer
+}
+
+func (i *indentWriter) Write(p []byte) (int, error) {
+	lines := strings.SplitAfter(string(p), "\n")
+	for _, l := range lines {
+		if l == "" {
+			continue
+		}
+		if _, err := io.WriteString(i.w, "  "+l); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// doctorChecks are the checks of `danger-go doctor`. secretsErr is the
+// error of loading the secrets, if any.
+func doctorChecks(secretsErr error) []doctorCheck {
+	return []doctorCheck{
+		{name: "secrets", run: checkSecrets(secretsErr)},
+		{name: "git", run: checkGit},
+		{name: "go", run: checkGo},
+		{name: "repository", run: checkRepository},
+		{name: "base branch", run: checkBaseRef},
+		{name: "dangerfile", run: checkDangerfile},
+		{name: "danger-js", run: checkDangerJS},
+		{name: "GitHub token", run: checkGitHubToken},
+		{name: "GitLab token", run: checkGitLabToken},
+	}
+}
+
+// checkSecrets reports the error of loading the secrets from the provider of
+// DANGER_SECRETS_PROVIDER.
+func checkSecrets(err error) func(context.Context) (checkStatus, string) {
+	return func(context.Context) (checkStatus, string) {
+		if err != nil {
+			return checkFail, err.Error()
+		}
+		provider := os.Getenv(secrets.ProviderEnv)
+		if provider == "" {
+			provider = "env"
+		}
+		return checkOK, fmt.Sprintf("loaded with the %s provider", provider)
+	}
+}
+
+func command(ctx context.Context, name string, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+func checkGit(ctx context.Context) (checkStatus, string) {
+	out, err := command(ctx, "git", "--version")
+	if err != nil {
+		return checkFail, fmt.Sprintf("git is needed for the diff helpers: %s", err)
+	}
+	if major := gitMajorVersion(out); major < 2 {
+		return checkFail, fmt.Sprintf("%s, git 2 or later is needed", out)
+	}
+	return checkOK, out
+}
+
+// gitMajorVersion parses the major version of `git --version` output, e.g.
+// "git version 2.43.0", or returns 0.
+func gitMajorVersion(out string) int {
+	v := strings.TrimPrefix(out, "git version ")
+	major, _, _ := strings.Cut(v, ".")
+	n, _ := strconv.Atoi(major)
+	return n
+}
+
+func checkGo(ctx context.Context) (checkStatus, string) {
+	out, err := command(ctx, "go", "version")
+	if err != nil {
+		return checkFail, fmt.Sprintf("go is needed to build dangerfile.go: %s", err)
+	}
+	return checkOK, out
+}
+
+func checkRepository(ctx context.Context) (checkStatus, string) {
+	out, err := command(ctx, "git", "rev-parse", "--is-shallow-repository")
+	if err != nil {
+		return checkFail, "not a git repository, run danger-go from the checkout"
+	}
+	if out == "true" {
+		if os.Getenv(dangerJs.UnshallowEnv) != "" {
+			return checkOK, fmt.Sprintf("shallow clone, history is fetched on demand (%s)", dangerJs.UnshallowEnv)
+		}
+		return checkWarn, fmt.Sprintf("shallow clone, check out with fetch-depth: 0 or set %s=true", dangerJs.UnshallowEnv)
+	}
+	return checkOK, "full clone"
+}
+
+func checkBaseRef(ctx context.Context) (checkStatus, string) {
+	branch := os.Getenv("GITHUB_BASE_REF")
+	if branch == "" {
+		branch = os.Getenv("CI_MERGE_REQUEST_TARGET_BRANCH_NAME")
+	}
+	if branch == "" {
+		return checkWarn, "unknown outside of a PR job (GITHUB_BASE_REF or CI_MERGE_REQUEST_TARGET_BRANCH_NAME)"
+	}
+	ref := "origin/" + branch
+	if _, err := command(ctx, "git", "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return checkFail, fmt.Sprintf("%s is missing, run git fetch origin %s", ref, branch)
+	}
+	return checkOK, ref + " is present"
+}
+
+func checkDangerfile(context.Context) (checkStatus, string) {
+	_, err := os.Stat("dangerfile.go")
+	if err == nil {
+		return checkOK, "dangerfile.go"
+	}
+	config := os.Getenv("DANGER_CONFIG")
+	if config == "" {
+		config = "danger.yaml"
+	}
+	c, cerr := runner.LoadConfig(config)
+	switch {
+	case cerr != nil:
+		return checkFail, cerr.Error()
+	case len(c.Rules) > 0:
+		return checkOK, fmt.Sprintf("%d rule bundles in %s", len(c.Rules), config)
+	case errors.Is(err, fs.ErrNotExist):
+		wd, _ := os.Getwd()
+		return checkFail, fmt.Sprintf("no dangerfile.go or rule bundles in %s", wd)
+	}
+	return checkFail, err.Error()
+}
+
+func checkDangerJS(context.Context) (checkStatus, string) {
+	if lock, err := dangerJs.ReadLock(dangerJs.LockPath()); err == nil {
+		inst := dangerJs.NewInstaller()
+		if _, err := inst.Verify(lock); err != nil {
+			return checkWarn, fmt.Sprintf("danger-js %s pinned in %s is not installed: %s", lock.Version, dangerJs.LockPath(), err)
+		}
+		return checkOK, fmt.Sprintf("danger-js %s at %s", lock.Version, inst.Path(lock.Version))
+	}
+	bin, err := exec.LookPath("danger")
+	if err != nil {
+		return checkFail, "danger-js is not installed, run npm install -g danger or danger-go js install <version>"
+	}
+	return checkOK, filepath.Clean(bin)
+}
+
+func checkGitHubToken(ctx context.Context) (checkStatus, string) {
+	token := os.Getenv("DANGER_GITHUB_API_TOKEN")
+	if token == "" {
+		token = os.Getenv("GITHUB_TOKEN")
+	}
+	if token == "" {
+		return checkWarn, "not set, set DANGER_GITHUB_API_TOKEN or GITHUB_TOKEN for GitHub PRs"
+	}
+	c, err := githubclient.New(os.Getenv("DANGER_GITHUB_API_BASE_URL"), token)
+	if err != nil {
+		return checkFail, err.Error()
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	scopes, known, err := c.TokenScopes(ctx)
+	switch {
+	case err != nil:
+		return checkFail, err.Error()
+	case !known:
+		return checkOK, "valid, permissions are set on the fine-grained, app or Actions token"
+	case len(scopes) == 0:
+		return checkWarn, "valid but has no scopes"
+	}
+	return checkOK, "scopes " + strings.Join(scopes, ", ")
+}
+
+func checkGitLabToken(ctx context.Context) (checkStatus, string) {
+	if os.Getenv("DANGER_GITLAB_API_TOKEN") == "" && os.Getenv("DANGER_GITLAB_API_OAUTH_TOKEN") == "" {
+		return checkWarn, "not set, set DANGER_GITLAB_API_TOKEN for GitLab MRs"
+	}
+	c, err := gitlabclient.NewFromEnv()
+	if err != nil {
+		return checkFail, err.Error()
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if c.TokenType() != gitlabclient.PersonalToken {
+		if _, err := c.Version(ctx); err != nil {
+			return checkFail, err.Error()
+		}
+		return checkOK, fmt.Sprintf("valid %s token", c.TokenType())
+	}
+	scopes, err := c.TokenScopes(ctx)
+	if errors.Is(err, gitlabclient.ErrUnsupported) {
+		// the version was read with the token, so it is valid
+		return checkOK, "valid personal token, GitLab 15.5 lists its scopes"
+	}
+	if err != nil {
+		return checkFail, err.Error()
+	}
+	return checkOK, "scopes " + strings.Join(scopes, ", ")
+}
+
+// dumpDSLArgs removes `--dump-dsl=<path>` or `--dump-dsl <path>` from args,
+// returning the other arguments and the absolute path, which the runner
+// reads from runner.DumpDSLEnv.
+func dumpDSLArgs(args []string) ([]string, string, error) {
+	var path string
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		switch a := args[i]; {
+		case a == "--dump-dsl" && i+1 < len(args):
+			path = args[i+1]
+			i++
+		case strings.HasPrefix(a, "--dump-dsl="):
+			path = strings.TrimPrefix(a, "--dump-dsl=")
+		case a == "--dump-dsl":
+			return nil, "", fmt.Errorf("--dump-dsl requires a path")
+		default:
+			rest = append(rest, a)
+		}
+	}
+	if path == "" {
+		return rest, "", nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("resolving --dump-dsl path: %w", err)
+	}
+	return rest, abs, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"strings"
+	"time"
+
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// runJS runs `js install [version] [--sha256 <sum>]` and `js verify`, which
+// manage the danger-js binary pinned in the lock file.
+func runJS(args []string, inst dangerJs.Installer) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("js requires a sub-command: install or verify")
+	}
+	path := dangerJs.LockPath()
+	switch args[0] {
+	case "install":
+		version, sum, err := jsInstallArgs(args[1:])
+		if err != nil {
+			return "", err
+		}
+		lock, err := dangerJs.ReadLock(path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			if version == "" {
+				return "", fmt.Errorf("no %s yet, pass the danger-js version to install", path)
+			}
+		case err != nil:
+			return "", err
+		}
+		if version != "" && version != lock.Version {
+			// a new version is trusted on first use unless a checksum is given
+			lock = dangerJs.Lock{Version: version}
+		}
+		if sum != "" {
+			lock.SHA256 = maps.Clone(lock.SHA256)
+			if lock.SHA256 == nil {
+				lock.SHA256 = map[string]string{}
+			}
+			lock.SHA256[inst.Platform()] = sum
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		lock, bin, err := inst.Install(ctx, lock)
+		if err != nil {
+			return "", err
+		}
+		if err := dangerJs.WriteLock(path, lock); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Installed danger-js %s at %s (sha256 %s)", lock.Version, bin, lock.SHA256[inst.Platform()]), nil
+	case "verify":
+		lock, err := dangerJs.ReadLock(path)
+		if err != nil {
+			return "", err
+		}
+		if lock.SHA256[inst.Platform()] == "" {
+			return "", fmt.Errorf("%s pins no checksum for %s, run danger-go js install", path, inst.Platform())
+		}
+		if _, err := inst.Verify(lock); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("danger-js %s at %s matches %s", lock.Version, inst.Path(lock.Version), path), nil
+	default:
+		return "", fmt.Errorf("invalid js sub-command `%s`", args[0])
+	}
+}
+
+func jsInstallArgs(args []string) (version, sum string, err error) {
+	for i := 0; i < len(args); i++ {
+		switch a := args[i]; {
+		case a == "--sha256" && i+1 < len(args):
+			sum = args[i+1]
+			i++
+		case strings.HasPrefix(a, "--sha256="):
+			sum = strings.TrimPrefix(a, "--sha256=")
+		case strings.HasPrefix(a, "-"), version != "":
+			return "", "", fmt.Errorf("unexpected argument `%s`", a)
+		default:
+			version = a
+		}
+	}
+	return version, sum, nil
+}
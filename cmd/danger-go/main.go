@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/danger/golang/cmd/danger-go/runner"
 	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/secrets"
 )
 
 const version = "v0.3.0"
@@ -18,18 +23,95 @@ func main() {
 		return
 	}
 
+	args, dumpPath, err := dumpDSLArgs(os.Args)
+	if err != nil {
+		log.Fatalf("%s\n\n%s", err.Error(), usage)
+	}
+	if dumpPath != "" {
+		// the runner, started by danger-js, inherits the variable
+		os.Args = args
+		os.Setenv(runner.DumpDSLEnv, dumpPath)
+	}
+
 	command := os.Args[1]
+	var secretsErr error
+	if command != "version" {
+		// secrets mounted as files, e.g. DANGER_GITHUB_API_TOKEN_FILE
+		if err := dangerJs.ResolveFileEnv(); err != nil {
+			log.Fatal(err.Error())
+		}
+		secretsErr = loadSecrets(context.Background())
+		// doctor and config diagnose the configuration, so they report the
+		// secrets which can't be loaded rather than stop
+		if secretsErr != nil && command != "doctor" && command != "config" {
+			log.Fatal(secretsErr.Error())
+		}
+	}
 	switch command {
 	case "ci", "local", "pr":
+		if err := dangerJs.ValidateEnv(); err != nil {
+			log.Fatalf("invalid configuration: %s", err)
+		}
 		var rest []string
 		if len(os.Args) > 2 {
 			rest = os.Args[2:]
 		}
+		if command == "pr" {
+			var err error
+			if rest, err = prArgs(rest); err != nil {
+				log.Fatalf("%s\n\n%s", err.Error(), usage)
+			}
+		}
 		err := dangerJs.Process(command, rest)
+		var exitErr dangerJs.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	case "config":
+		if err := dangerJs.DumpEnv(os.Stdout); err != nil {
+			log.Fatal(err.Error())
+		}
+		if secretsErr != nil {
+			log.Fatal(secretsErr.Error())
+		}
+		if err := dangerJs.ValidateEnv(); err != nil {
+			log.Fatalf("invalid configuration: %s", err)
+		}
+	case "doctor":
+		ok, err := runDoctor(context.Background(), os.Stdout, doctorChecks(secretsErr))
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		if !ok {
+			os.Exit(1)
+		}
+	case "js":
+		out, err := runJS(os.Args[2:], dangerJs.NewInstaller())
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		fmt.Println(out)
+	case "publish":
+		from, rest, err := publishArgs(os.Args[2:])
+		if err != nil {
+			log.Fatalf("%s\n\n%s", err.Error(), usage)
+		}
+		err = runner.Publish(from, rest)
+		var exitErr dangerJs.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		if err != nil {
 			log.Fatal(err.Error())
 		}
 	case "runner":
+		if argsContain("--event") {
+			runner.RunEvent()
+			return
+		}
 		runner.Run()
 	case "version":
 		fmt.Printf("danger-go %s\n", version)
@@ -38,6 +120,60 @@ func main() {
 	}
 }
 
+// secretsTimeout bounds loading the secrets, so an unreachable secret store
+// fails the run rather than hangs it.
+const secretsTimeout = 30 * time.Second
+
+// loadSecrets sets the tokens which aren't in the environment from the
+// provider selected by DANGER_SECRETS_PROVIDER.
+func loadSecrets(ctx context.Context) error {
+	p, err := secrets.FromEnv()
+	if err != nil {
+		return fmt.Errorf("loading secrets: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, secretsTimeout)
+	defer cancel()
+	if err := secrets.Load(ctx, p, dangerJs.SecretEnvVars()...); err != nil {
+		return fmt.Errorf("loading secrets: %w", err)
+	}
+	return nil
+}
+
+// prArgs prepends the URL of the PR of the GitHub Actions event to the
+// arguments of `pr` when they don't start with one.
+func prArgs(args []string) ([]string, error) {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		return args, nil
+	}
+	url, err := dangerJs.EventPRURL()
+	if err != nil {
+		return nil, fmt.Errorf("pr requires the URL of a PR: %w", err)
+	}
+	return append([]string{url}, args...), nil
+}
+
+// publishArgs extracts the results file of `publish --from <file>` from args,
+// returning the other arguments, which are passed on to danger-js.
+func publishArgs(args []string) (string, []string, error) {
+	var from string
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		switch a := args[i]; {
+		case a == "--from" && i+1 < len(args):
+			from = args[i+1]
+			i++
+		case strings.HasPrefix(a, "--from="):
+			from = strings.TrimPrefix(a, "--from=")
+		default:
+			rest = append(rest, a)
+		}
+	}
+	if from == "" {
+		return "", nil, fmt.Errorf("publish requires --from <results.json>")
+	}
+	return from, rest, nil
+}
+
 // argsContain returns true if any of the provided `args` are in the list passed
 // in to the command
 func argsContain(args ...string) bool {
@@ -54,12 +190,19 @@ func argsContain(args ...string) bool {
 const usage = `Usage: danger-go [options] [command]
 
 Options:
-  -h, --help     Output usage information
+  -h, --help              Output usage information
+  --dump-dsl=<path>       Writes the DSL received by the runner to path, tokens and emails scrubbed, for bug reports
 
 Commands:
   ci             Runs DSL on CI
+  config         Prints the configuration from the environment, secrets redacted, and validates it
+  doctor         Checks the prerequisites (git, go, refs, danger-js, token scopes) and prints a diagnostic bundle
+  js             Manages the pinned danger-js binary: js install [version] [--sha256 <sum>], js verify
   local          Runs danger standalone on a repo, useful for git hooks
   pr             Runs your local Dangerfile against an existing GitHub DSL. Will not post on the DSL
+                 The PR defaults to the one of the GitHub Actions event
+  publish        Posts the results saved by an earlier run, e.g. publish --from results.json
   runner         Runs a dangerfile against a DSL passed in via STDIN [You probably don't need this]
+                 With --event, against the PR of the GitHub Actions event, without danger-js
   version        Show the version of the application
 `
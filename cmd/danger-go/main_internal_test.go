@@ -1,11 +1,21 @@
 package main
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	dangerJs "github.com/danger/golang/danger-js"
 )
 
 const cliPkg = "github.com/danger/golang/cmd/danger-go"
@@ -41,3 +51,171 @@ func TestShowsVersion(t *testing.T) {
 	require.Nil(t, err)
 	require.Equal(t, fmt.Sprintf("danger-go %s\n", version), res)
 }
+
+func TestPublishArgs(t *testing.T) {
+	from, rest, err := publishArgs([]string{"--from", "results.json", "--id", "go"})
+	require.Nil(t, err)
+	require.Equal(t, "results.json", from)
+	require.Equal(t, []string{"--id", "go"}, rest)
+
+	from, rest, err = publishArgs([]string{"--from=out/results.json"})
+	require.Nil(t, err)
+	require.Equal(t, "out/results.json", from)
+	require.Nil(t, rest)
+
+	_, _, err = publishArgs([]string{"--id", "go"})
+	require.EqualError(t, err, "publish requires --from <results.json>")
+}
+
+func TestDumpDSLArgs(t *testing.T) {
+	wd, err := os.Getwd()
+	require.Nil(t, err)
+
+	rest, path, err := dumpDSLArgs([]string{"danger-go", "ci", "--dump-dsl=dsl.json", "--id", "go"})
+	require.Nil(t, err)
+	require.Equal(t, []string{"danger-go", "ci", "--id", "go"}, rest)
+	require.Equal(t, filepath.Join(wd, "dsl.json"), path)
+
+	rest, path, err = dumpDSLArgs([]string{"danger-go", "pr", "--dump-dsl", "/tmp/dsl.json"})
+	require.Nil(t, err)
+	require.Equal(t, []string{"danger-go", "pr"}, rest)
+	require.Equal(t, filepath.FromSlash("/tmp/dsl.json"), path)
+
+	rest, path, err = dumpDSLArgs([]string{"danger-go", "ci"})
+	require.Nil(t, err)
+	require.Equal(t, []string{"danger-go", "ci"}, rest)
+	require.Empty(t, path)
+
+	_, _, err = dumpDSLArgs([]string{"danger-go", "ci", "--dump-dsl"})
+	require.EqualError(t, err, "--dump-dsl requires a path")
+}
+
+func TestRunDoctor(t *testing.T) {
+	t.Setenv("DANGER_GITHUB_API_TOKEN", "secret")
+	check := func(status checkStatus, detail string) func(context.Context) (checkStatus, string) {
+		return func(context.Context) (checkStatus, string) { return status, detail }
+	}
+
+	var out bytes.Buffer
+	ok, err := runDoctor(context.Background(), &out, []doctorCheck{
+		{name: "git", run: check(checkOK, "git version 2.43.0")},
+		{name: "repository", run: check(checkWarn, "shallow clone")},
+	})
+	require.Nil(t, err)
+	require.True(t, ok)
+	require.Contains(t, out.String(), "Checks:\n  ✓ git: git version 2.43.0\n  ! repository: shallow clone\n")
+	require.Contains(t, out.String(), "Configuration:\n")
+	require.Contains(t, out.String(), "  DANGER_GITHUB_API_TOKEN=<redacted, 6 characters>\n")
+	require.NotContains(t, out.String(), "secret")
+
+	ok, err = runDoctor(context.Background(), &out, []doctorCheck{
+		{name: "base branch", run: check(checkFail, "origin/main is missing")},
+	})
+	require.Nil(t, err)
+	require.False(t, ok)
+}
+
+func TestGitMajorVersion(t *testing.T) {
+	tests := []struct {
+		out  string
+		want int
+	}{
+		{out: "git version 2.43.0", want: 2},
+		{out: "git version 2.39.3 (Apple Git-145)", want: 2},
+		{out: "git version 1.8.3.1", want: 1},
+		{out: "unexpected", want: 0},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, gitMajorVersion(tt.out), tt.out)
+	}
+}
+
+func TestRunJS(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		f, _ := zw.Create("danger")
+		_, _ = f.Write([]byte("binary " + r.URL.Path))
+		_ = zw.Close()
+		_, _ = w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+	lock := filepath.Join(t.TempDir(), "lock.json")
+	t.Setenv("DANGER_JS_LOCK", lock)
+	inst := dangerJs.Installer{CacheDir: t.TempDir(), URL: srv.URL + "/{version}", GOOS: "linux", GOARCH: "amd64"}
+
+	_, err := runJS([]string{"install"}, inst)
+	require.ErrorContains(t, err, "pass the danger-js version")
+
+	_, err = runJS([]string{"install", "11.0.0"}, inst)
+	require.Nil(t, err)
+	l, err := dangerJs.ReadLock(lock)
+	require.Nil(t, err)
+	require.Equal(t, "11.0.0", l.Version)
+	require.NotEmpty(t, l.SHA256["linux/amd64"])
+
+	out, err := runJS([]string{"verify"}, inst)
+	require.Nil(t, err)
+	require.Contains(t, out, "danger-js 11.0.0")
+
+	// an explicit checksum must match
+	_, err = runJS([]string{"install", "11.1.0", "--sha256=00"}, inst)
+	require.ErrorIs(t, err, dangerJs.ErrChecksumMismatch)
+	l, err = dangerJs.ReadLock(lock)
+	require.Nil(t, err)
+	require.Equal(t, "11.0.0", l.Version)
+
+	_, err = runJS([]string{"install", "1", "2"}, inst)
+	require.Error(t, err)
+	_, err = runJS([]string{"remove"}, inst)
+	require.Error(t, err)
+}
+
+func TestPRArgs(t *testing.T) {
+	event := filepath.Join(t.TempDir(), "event.json")
+	require.Nil(t, os.WriteFile(event, []byte(`{"pull_request":{"html_url":"https://github.com/org/repo/pull/4"}}`), 0o600))
+
+	tests := []struct {
+		name    string
+		args    []string
+		event   string
+		want    []string
+		wantErr string
+	}{
+		{name: "explicit URL", args: []string{"https://github.com/org/repo/pull/1", "--json"}, event: event, want: []string{"https://github.com/org/repo/pull/1", "--json"}},
+		{name: "from event", args: []string{"--json"}, event: event, want: []string{"https://github.com/org/repo/pull/4", "--json"}},
+		{name: "no event", wantErr: "pr requires the URL of a PR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_EVENT_PATH", tt.event)
+			got, err := prArgs(tt.args)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCheckSecrets(t *testing.T) {
+	t.Setenv("DANGER_SECRETS_PROVIDER", "vault")
+	status, detail := checkSecrets(nil)(context.Background())
+	require.Equal(t, checkOK, status)
+	require.Equal(t, "loaded with the vault provider", detail)
+
+	status, detail = checkSecrets(errors.New("loading secrets: vault: 403 Forbidden"))(context.Background())
+	require.Equal(t, checkFail, status)
+	require.Equal(t, "loading secrets: vault: 403 Forbidden", detail)
+}
+
+func TestDoctorReportsSecrets(t *testing.T) {
+	cmd := exec.Command("go", "run", cliPkg, "doctor")
+	cmd.Env = append(os.Environ(), "DANGER_SECRETS_PROVIDER=file", "DANGER_SECRETS_DIR=")
+	out, err := cmd.CombinedOutput()
+	require.NotNil(t, err)
+	require.Contains(t, string(out), "✗ secrets: loading secrets: DANGER_SECRETS_DIR is required by the file secrets provider")
+	require.Contains(t, string(out), "Configuration:\n")
+}
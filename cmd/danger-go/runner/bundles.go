@@ -0,0 +1,303 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	danger "github.com/danger/golang"
+	"github.com/danger/golang/report"
+	"github.com/danger/golang/tracing"
+)
+
+const (
+	// configEnv overrides the path of the configuration file.
+	configEnv = "DANGER_CONFIG"
+	// defaultConfig is the configuration file read next to the dangerfile.
+	defaultConfig = "danger.yaml"
+)
+
+// Bundle is a shared rule bundle: a Go module whose root package exports a
+// dangerfile function, `func Run(d *danger.T, pr danger.DSL)`, pinned to a
+// version and optionally to the go.sum hash of that version.
+type Bundle struct {
+	Module  string
+	Version string
+	// Sum is the "h1:" hash of the module zip, as listed in go.sum.
+	Sum string
+}
+
+// String returns the module and version, e.g. "github.com/org/rules@v1.4.0".
+func (b Bundle) String() string {
+	return b.Module + "@" + b.Version
+}
+
+// ParseBundle parses a bundle reference, "<module>@<version>" optionally
+// followed by the go.sum hash of the version.
+func ParseBundle(ref string) (Bundle, error) {
+	fields := strings.Fields(ref)
+	if len(fields) == 0 || len(fields) > 2 {
+		return Bundle{}, fmt.Errorf("invalid rule bundle %q, want <module>@<version> [h1:<hash>]", ref)
+	}
+	if strings.HasPrefix(fields[0], "oci://") {
+		return Bundle{}, fmt.Errorf("rule bundle %s: OCI artifacts are not supported, publish the bundle as a Go module", fields[0])
+	}
+	module, version, ok := strings.Cut(fields[0], "@")
+	if !ok || module == "" {
+		return Bundle{}, fmt.Errorf("rule bundle %s must be pinned to a version, e.g. %s@v1.0.0", fields[0], fields[0])
+	}
+	if !strings.HasPrefix(version, "v") {
+		return Bundle{}, fmt.Errorf("rule bundle %s must be pinned to a version, not %q", module, version)
+	}
+	b := Bundle{Module: module, Version: version}
+	if len(fields) == 2 {
+		if !strings.HasPrefix(fields[1], "h1:") {
+			return Bundle{}, fmt.Errorf("rule bundle %s: invalid hash %q, want h1:<hash> as in go.sum", b, fields[1])
+		}
+		b.Sum = fields[1]
+	}
+	return b, nil
+}
+
+// FileConfig is the danger-go configuration file, danger.yaml. It lists the
+// rule bundles to run, either as a flow or a block sequence, and the message
+// templates overriding the defaults, see danger.SetMessages:
+//
+//	rules:
+//	  - github.com/org/danger-rules@v1.4.0
+//	  - github.com/org/security-rules@v0.3.1 h1:Xy7…=
+//	messages:
+//	  comment.footer: Questions? Ask in #dev-help.
+type FileConfig struct {
+	Rules    []Bundle
+	Messages danger.Messages
+}
+
+// configPath returns the path of the configuration file, DANGER_CONFIG or
+// danger.yaml.
+func configPath() string {
+	if path := os.Getenv(configEnv); path != "" {
+		return path
+	}
+	return defaultConfig
+}
+
+// LoadConfig reads the configuration file at path. A missing file is an
+// empty configuration.
+func LoadConfig(path string) (FileConfig, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return FileConfig{}, nil
+	}
+	if err != nil {
+		return FileConfig{}, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+	c, err := parseConfig(f)
+	if err != nil {
+		return FileConfig{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return c, nil
+}
+
+func parseConfig(r io.Reader) (FileConfig, error) {
+	var doc struct {
+		Rules    yaml.Node `yaml:"rules"`
+		Messages yaml.Node `yaml:"messages"`
+	}
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return FileConfig{}, err
+	}
+	var c FileConfig
+	switch doc.Rules.Kind {
+	case 0:
+	case yaml.SequenceNode:
+		var rules []string
+		if err := doc.Rules.Decode(&rules); err != nil {
+			return FileConfig{}, fmt.Errorf("rules: %w", err)
+		}
+		for _, rule := range rules {
+			b, err := ParseBundle(rule)
+			if err != nil {
+				return FileConfig{}, err
+			}
+			c.Rules = append(c.Rules, b)
+		}
+	default:
+		if doc.Rules.Tag != "!!null" {
+			return FileConfig{}, fmt.Errorf("line %d: rules must be a list", doc.Rules.Line)
+		}
+	}
+	if doc.Messages.Kind != 0 {
+		if err := doc.Messages.Decode(&c.Messages); err != nil {
+			return FileConfig{}, fmt.Errorf("messages: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// moduleInfo is the output of `go mod download -json`.
+type moduleInfo struct {
+	Path    string
+	Version string
+	Sum     string
+	// Zip is the path of the module zip in the module cache.
+	Zip   string
+	Error string
+}
+
+// downloadModule downloads a module version into the module cache. The go
+// command verifies it against go.sum and the checksum database.
+var downloadModule = func(ctx context.Context, b Bundle) (moduleInfo, error) {
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, "go", "mod", "download", "-json", b.String())
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	runErr := cmd.Run()
+	var info moduleInfo
+	if err := json.Unmarshal(stdout.Bytes(), &info); err != nil {
+		if runErr != nil {
+			return moduleInfo{}, runErr
+		}
+		return moduleInfo{}, fmt.Errorf("decoding go mod download output: %w", err)
+	}
+	if info.Error != "" {
+		return moduleInfo{}, errors.New(info.Error)
+	}
+	return info, runErr
+}
+
+// verifyBundle checks the downloaded module is the pinned version with the
+// pinned hash.
+func verifyBundle(b Bundle, info moduleInfo) error {
+	if info.Version != b.Version {
+		return fmt.Errorf("rule bundle %s resolved to version %s", b, info.Version)
+	}
+	if b.Sum != "" && info.Sum != b.Sum {
+		return fmt.Errorf("rule bundle %s: checksum mismatch, pinned %s but downloaded %s", b, b.Sum, info.Sum)
+	}
+	return nil
+}
+
+// bundleMain is the plugin wrapping the Run function of a bundle.
+const bundleMain = `package main
+
+import (
+	danger "github.com/danger/golang"
+	"github.com/danger/golang/report"
+	bundle "%s"
+)
+
+func Run(d *danger.T, pr danger.DSL) {
+	bundle.Run(d, pr)
+}
+`
+
+// buildBundle downloads, verifies and builds b as a plugin in dir, returning
+// the plugin path and the digest of the module zip.
+func buildBundle(ctx context.Context, b Bundle, dir string) (string, report.StatementItem, error) {
+	info, err := downloadModule(ctx, b)
+	if err != nil {
+		return "", report.StatementItem{}, fmt.Errorf("downloading rule bundle %s: %w", b, err)
+	}
+	if err := verifyBundle(b, info); err != nil {
+		return "", report.StatementItem{}, err
+	}
+	source, err := report.FileDigest(b.String(), info.Zip)
+	if err != nil {
+		return "", report.StatementItem{}, fmt.Errorf("rule bundle %s: %w", b, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module dangerbundle\n"), 0o644); err != nil {
+		return "", report.StatementItem{}, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(fmt.Sprintf(bundleMain, b.Module)), 0o644); err != nil {
+		return "", report.StatementItem{}, err
+	}
+	// plugins must share the versions of the packages of the runner
+	deps := []string{b.String()}
+	if bi, ok := debug.ReadBuildInfo(); ok && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		deps = append(deps, bi.Main.Path+"@"+bi.Main.Version)
+	}
+	output := filepath.Join(dir, "bundle.so")
+	for _, args := range [][]string{
+		append([]string{"get"}, deps...),
+		{"build", "-o", output, "-buildmode=plugin", "."},
+	} {
+		cmd := exec.CommandContext(ctx, "go", args...)
+		cmd.Dir = dir
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return "", report.StatementItem{}, fmt.Errorf("building rule bundle %s: go %s: %w", b, args[0], err)
+		}
+	}
+	return output, source, nil
+}
+
+// loadBundles builds and loads the rule bundles of the configuration file,
+// returning them as rules named after their module along with the digests of
+// their modules.
+func loadBundles(ctx context.Context) ([]danger.Rule, []report.StatementItem, func(), error) {
+	c, err := LoadConfig(configPath())
+	if err != nil || len(c.Rules) == 0 {
+		return nil, nil, func() {}, err
+	}
+	ctx, span := tracing.Start(ctx, "load rule bundles")
+	defer span.End()
+
+	tempDir, err := os.MkdirTemp("", "danger-go-bundles-")
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("creating temp directory: %w", err)
+	}
+	release := func() { _ = os.RemoveAll(tempDir) }
+	var rules []danger.Rule
+	var sources []report.StatementItem
+	for i, b := range c.Rules {
+		dir := filepath.Join(tempDir, fmt.Sprint(i))
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			release()
+			return nil, nil, nil, err
+		}
+		fmt.Printf("Building rule bundle %s\n", b)
+		lib, source, err := buildBundle(ctx, b, dir)
+		if err == nil {
+			var fn MainFunc
+			if fn, err = loadPlugin(lib); err == nil {
+				rules = append(rules, danger.Rule{Name: b.Module, Run: fn})
+				sources = append(sources, source)
+				continue
+			}
+			err = fmt.Errorf("loading rule bundle %s: %w", b, err)
+		}
+		span.SetError(err)
+		release()
+		return nil, nil, nil, err
+	}
+	return rules, sources, release, nil
+}
+
+// withBundles runs the rule bundles before the dangerfile fn, which may be
+// nil when the repository only uses bundles.
+func withBundles(bundles []danger.Rule, fn MainFunc) MainFunc {
+	if len(bundles) == 0 {
+		return fn
+	}
+	return func(d *danger.T, pr danger.DSL) {
+		d.RunRules(pr, bundles...)
+		if fn != nil {
+			fn(d, pr)
+		}
+	}
+}
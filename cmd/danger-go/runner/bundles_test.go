@@ -0,0 +1,141 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+)
+
+func TestParseBundle(t *testing.T) {
+	tests := []struct {
+		ref     string
+		want    Bundle
+		wantErr string
+	}{
+		{ref: "github.com/org/danger-rules@v1.4.0", want: Bundle{Module: "github.com/org/danger-rules", Version: "v1.4.0"}},
+		{
+			ref:  "github.com/org/danger-rules@v1.4.0 h1:abc=",
+			want: Bundle{Module: "github.com/org/danger-rules", Version: "v1.4.0", Sum: "h1:abc="},
+		},
+		{ref: "github.com/org/danger-rules", wantErr: "must be pinned to a version"},
+		{ref: "github.com/org/danger-rules@latest", wantErr: `must be pinned to a version, not "latest"`},
+		{ref: "github.com/org/danger-rules@v1.4.0 sha256:abc", wantErr: "invalid hash"},
+		{ref: "oci://ghcr.io/org/rules:1.4.0", wantErr: "OCI artifacts are not supported"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			b, err := ParseBundle(tt.ref)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, b)
+		})
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	a := Bundle{Module: "github.com/org/a", Version: "v1.0.0"}
+	b := Bundle{Module: "github.com/org/b", Version: "v0.2.0", Sum: "h1:Xy7+abc="}
+	tests := []struct {
+		name         string
+		config       string
+		want         []Bundle
+		wantMessages danger.Messages
+		wantErr      string
+	}{
+		{name: "flow", config: "rules: [github.com/org/a@v1.0.0, \"github.com/org/b@v0.2.0 h1:Xy7+abc=\"]\n", want: []Bundle{a, b}},
+		{
+			name:   "block",
+			config: "# shared policies\nrules:\n  - github.com/org/a@v1.0.0 # platform team\n  - github.com/org/b@v0.2.0 h1:Xy7+abc=\nother: value\n",
+			want:   []Bundle{a, b},
+		},
+		{name: "no rules", config: "other: value\n"},
+		{name: "not a list", config: "rules: github.com/org/a@v1.0.0\n", wantErr: "line 1: rules must be a list"},
+		{name: "unpinned", config: "rules:\n  - github.com/org/a\n", wantErr: "must be pinned"},
+		{
+			name:         "messages",
+			config:       "rules:\n- github.com/org/a@v1.0.0\nmessages:\n  comment.footer: |\n    Questions?\n    Ask in #dev-help.\n  rules.stale.behind: \"Please rebase.\" # tone\n",
+			want:         []Bundle{a},
+			wantMessages: danger.Messages{"comment.footer": "Questions?\nAsk in #dev-help.\n", "rules.stale.behind": "Please rebase."},
+		},
+		{name: "messages not a mapping", config: "messages:\n  - comment.footer\n", wantErr: "line 2: cannot unmarshal !!seq"},
+		{
+			name:         "flow messages",
+			config:       "messages: {comment.header: &h Hello, rules.stale.behind: *h}\n",
+			wantMessages: danger.Messages{"comment.header": "Hello", "rules.stale.behind": "Hello"},
+		},
+		{name: "rules not strings", config: "rules:\n  - {module: github.com/org/a}\n", wantErr: "rules: yaml: unmarshal errors"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := parseConfig(strings.NewReader(tt.config))
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, c.Rules)
+			require.Equal(t, tt.wantMessages, c.Messages)
+		})
+	}
+}
+
+func TestLoadConfigMissing(t *testing.T) {
+	c, err := LoadConfig(filepath.Join(t.TempDir(), "danger.yaml"))
+	require.Nil(t, err)
+	require.Empty(t, c.Rules)
+}
+
+func TestBuildBundleVerifies(t *testing.T) {
+	b := Bundle{Module: "github.com/org/a", Version: "v1.0.0", Sum: "h1:pinned="}
+	tests := []struct {
+		name    string
+		info    moduleInfo
+		err     error
+		wantErr string
+	}{
+		{name: "download fails", err: errors.New("unknown revision v1.0.0"), wantErr: "downloading rule bundle github.com/org/a@v1.0.0: unknown revision"},
+		{name: "other version", info: moduleInfo{Version: "v1.0.1", Sum: "h1:pinned="}, wantErr: "resolved to version v1.0.1"},
+		{name: "tampered", info: moduleInfo{Version: "v1.0.0", Sum: "h1:other="}, wantErr: "checksum mismatch, pinned h1:pinned= but downloaded h1:other="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev := downloadModule
+			downloadModule = func(context.Context, Bundle) (moduleInfo, error) { return tt.info, tt.err }
+			defer func() { downloadModule = prev }()
+
+			dir := t.TempDir()
+			_, _, err := buildBundle(context.Background(), b, dir)
+			require.ErrorContains(t, err, tt.wantErr)
+			_, statErr := os.Stat(filepath.Join(dir, "main.go"))
+			require.True(t, os.IsNotExist(statErr), "nothing is built from an unverified bundle")
+		})
+	}
+}
+
+func TestWithBundles(t *testing.T) {
+	bundle := danger.Rule{Name: "github.com/org/a", Run: func(d *danger.T, _ danger.DSL) { d.Warn("from bundle", "", 0) }}
+	dangerfile := func(d *danger.T, _ danger.DSL) { d.Warn("from dangerfile", "", 0) }
+
+	d := danger.New()
+	withBundles([]danger.Rule{bundle}, dangerfile)(d, danger.DSL{})
+	require.Equal(t, []danger.Violation{
+		{Message: "from bundle", Rule: "github.com/org/a"},
+		{Message: "from dangerfile"},
+	}, d.Snapshot().Warnings)
+
+	d = danger.New()
+	withBundles([]danger.Rule{bundle}, nil)(d, danger.DSL{})
+	require.Len(t, d.Snapshot().Warnings, 1)
+
+	require.Nil(t, withBundles(nil, nil))
+}
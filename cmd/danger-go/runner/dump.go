@@ -0,0 +1,41 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// DumpDSLEnv is the path the runner writes the received DSL to, scrubbed of
+// tokens and emails, for attaching to bug reports. `--dump-dsl=<path>` sets
+// it.
+const DumpDSLEnv = "DANGER_DUMP_DSL"
+
+// dumpDSL writes the scrubbed DSL document of req to the path of DumpDSLEnv.
+func dumpDSL(req request) error {
+	path := os.Getenv(DumpDSLEnv)
+	if path == "" {
+		return nil
+	}
+	var src io.Reader = bytes.NewReader(req.dsl)
+	if req.dslPath != "" {
+		f, err := os.Open(req.dslPath)
+		if err != nil {
+			return fmt.Errorf("reading DSL file: %w", err)
+		}
+		defer f.Close()
+		src = f
+	}
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("creating DSL dump: %w", err)
+	}
+	if err := dangerJs.ScrubDSL(src, out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
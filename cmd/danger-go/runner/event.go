@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/githubclient"
+	"github.com/danger/golang/tracing"
+)
+
+// RunEvent runs the dangerfile against the PR of the GitHub Actions event at
+// GITHUB_EVENT_PATH and writes the results JSON to stdout. Rather than
+// running danger-js, the DSL is built from the event payload with two API
+// requests, authenticated with DANGER_GITHUB_API_TOKEN or GITHUB_TOKEN. The
+// results can be posted with `danger-go publish --from`.
+func RunEvent() {
+	shutdownTracing := tracing.Init()
+	defer flushTraces(shutdownTracing)
+	ctx, span := tracing.Start(context.Background(), "danger-go run")
+	defer span.End()
+
+	in, err := eventRequest(ctx, os.Getenv("GITHUB_API_URL"), githubToken())
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	if err := serve(ctx, in, os.Stdout, loadDangerfile); err != nil {
+		log.Fatal(err.Error())
+	}
+}
+
+func githubToken() string {
+	if t := os.Getenv("DANGER_GITHUB_API_TOKEN"); t != "" {
+		return t
+	}
+	return os.Getenv("GITHUB_TOKEN")
+}
+
+// eventRequest returns the run request for the DSL built from the event
+// payload, as danger-js would send it without --passURLForDSL.
+func eventRequest(ctx context.Context, baseURL, token string) ([]byte, error) {
+	client, err := githubclient.New(baseURL, token)
+	if err != nil {
+		return nil, err
+	}
+	dsl, err := dangerJs.DSLFromEvent(ctx, "", client)
+	if err != nil {
+		return nil, fmt.Errorf("building DSL from the event: %w", err)
+	}
+	return json.Marshal(map[string]any{"danger": map[string]any{
+		"git":    dsl.Git,
+		"github": dsl.GitHub,
+		"settings": map[string]any{
+			"github": map[string]string{"accessToken": token, "baseURL": baseURL},
+		},
+	}})
+}
@@ -0,0 +1,54 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakeapi"
+)
+
+func TestEventRequest(t *testing.T) {
+	gh := fakeapi.NewGitHub(t, "org", "repo")
+	gh.SetFiles(4,
+		fakeapi.GitHubFile{Filename: "new.go", Status: "added"},
+		fakeapi.GitHubFile{Filename: "main.go", Status: "modified"},
+	)
+	event := filepath.Join(t.TempDir(), "event.json")
+	require.Nil(t, os.WriteFile(event, []byte(`{
+		"action": "opened",
+		"pull_request": {"number": 4, "title": "Add feature", "labels": [{"name": "go"}], "requested_teams": [{"slug": "backend"}]},
+		"repository": {"full_name": "org/repo"}
+	}`), 0o600))
+	t.Setenv("GITHUB_EVENT_PATH", event)
+	t.Setenv("DANGER_PROTECTED_PATHS", "")
+	t.Setenv(resultsFileEnv, filepath.Join(t.TempDir(), "results.json"))
+
+	in, err := eventRequest(context.Background(), gh.URL, fakeapi.Token)
+	require.Nil(t, err)
+
+	var out bytes.Buffer
+	err = Serve(context.Background(), in, &out, func(d *danger.T, pr danger.DSL) {
+		require.Equal(t, "Add feature", pr.GitHub.PR().Title)
+		require.Equal(t, dangerJs.GitHubAPIPR{Owner: "org", Repo: "repo", Number: 4}, pr.GitHub.ThisPR())
+		require.Equal(t, []dangerJs.GitHubIssueLabel{{Name: "go"}}, pr.GitHub.Issue().Labels)
+		require.True(t, pr.GitHub.RequestedReviewers().HasReviewerFromTeam("backend"))
+		require.Equal(t, []string{"new.go"}, pr.Git.CreatedFiles())
+		require.Equal(t, []string{"main.go"}, pr.Git.ModifiedFiles())
+		require.Equal(t, fakeapi.Token, pr.Settings.GitHubAccessToken())
+		d.Message("ran", "", 0)
+	})
+	require.Nil(t, err)
+	var results danger.Results
+	require.Nil(t, json.Unmarshal(out.Bytes(), &results))
+	require.Len(t, results.Messages, 1)
+	require.Len(t, gh.Find("GET", "/api/v3/repos/org/repo/pulls/4/files"), 1)
+	require.Len(t, gh.Find("GET", "/api/v3/repos/org/repo/pulls/4/reviews"), 1)
+}
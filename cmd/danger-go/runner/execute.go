@@ -0,0 +1,161 @@
+package runner
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"strings"
+
+	danger "github.com/danger/golang"
+	"github.com/danger/golang/autofix"
+	"github.com/danger/golang/metrics"
+	"github.com/danger/golang/runstate"
+)
+
+// Config configures a run embedded in another Go program with Execute.
+type Config struct {
+	// DSLSource provides the danger DSL of the run. It is required.
+	DSLSource DSLSource
+	// Dangerfunc is the dangerfile to run. When nil, dangerfile.go and the
+	// rule bundles of danger.yaml are built and loaded as by Run.
+	Dangerfunc MainFunc
+	// Reporters are notified of every violation as it is added.
+	Reporters []danger.Reporter
+
+	// The steps below are opt-in, where runs started by danger-js take them
+	// from the environment.
+
+	// ProtectedPaths are the globs of the paths skip requests can't skip,
+	// as DANGER_PROTECTED_PATHS.
+	ProtectedPaths []string
+	// Messages override the default message templates, see LoadConfig and
+	// danger.LoadMessages.
+	Messages danger.Messages
+	// DSLOptions complete the DSL, e.g. danger.WithImpact.
+	DSLOptions []danger.DSLOption
+	// PreviousComment finds the Danger comment of the previous run on
+	// GitHub, for the change markers of the dangerfile.
+	PreviousComment bool
+	// Waivers applies the `/danger accept <rule>` comments of
+	// WaiverReviewers, or of anyone with write access but the author.
+	Waivers         bool
+	WaiverReviewers []string
+	// Metrics export the metrics of the run, see metrics.FromEnv.
+	Metrics []metrics.Exporter
+	// ResultsFile is where the results are saved for `danger-go publish`.
+	ResultsFile string
+	// OutputsFile is the step outputs file of GitHub Actions, GITHUB_OUTPUT,
+	// which the counts and the results file are appended to.
+	OutputsFile string
+	// CodeQualityFile is where the GitLab Code Quality report is written.
+	CodeQualityFile string
+	// JobSummaryFile is the job summary of GitHub Actions,
+	// GITHUB_STEP_SUMMARY, which the results are appended to.
+	JobSummaryFile string
+	// SafeMode also reports the results as Actions annotations on stderr,
+	// and skips posting comment pages and saving the state.
+	SafeMode bool
+	// AttestationFile is where the policy attestation of the run is written,
+	// signed with AttestationKey when set.
+	AttestationFile string
+	AttestationKey  ed25519.PrivateKey
+	// State stores the state of the runs on a PR, see runstate.FromEnv.
+	State runstate.Store
+	// GitLabStatus names the commit status set on the MR head.
+	GitLabStatus string
+	// PostCommentPages posts the results which overflow the Danger comment
+	// as extra comments on GitHub.
+	PostCommentPages bool
+	// Autofix returns the committer of the fixes of the dangerfile, e.g.
+	// autofix.FromEnv. Without it the fixes are suggested.
+	Autofix func(pr danger.DSL) (autofix.Committer, error)
+}
+
+// DSLSource provides a run request in any of the forms danger-js sends on
+// stdin: the DSL document, its danger://dsl/ URL or a JSON-RPC request. The
+// results path of JSON-RPC requests is ignored by Execute.
+type DSLSource func(ctx context.Context) ([]byte, error)
+
+// DSLJSON is the source of a DSL document, which has the DSL under its
+// "danger" key as written by `danger-js pr --json`.
+func DSLJSON(doc []byte) DSLSource {
+	return func(context.Context) ([]byte, error) {
+		return doc, nil
+	}
+}
+
+// DSLFile is the source of the DSL document at a file path or a file:// or
+// http(s):// URL.
+func DSLFile(location string) DSLSource {
+	return func(context.Context) ([]byte, error) {
+		return []byte(dangerURLPrefix + location), nil
+	}
+}
+
+// DSLEvent builds the DSL from the PR of the GitHub Actions event at
+// GITHUB_EVENT_PATH, as RunEvent does, using the GitHub API at baseURL.
+func DSLEvent(baseURL, token string) DSLSource {
+	return func(ctx context.Context) ([]byte, error) {
+		return eventRequest(ctx, baseURL, token)
+	}
+}
+
+// Execute runs c.Dangerfunc against the DSL of c.DSLSource and returns the
+// results, fitted to the Danger comment. The run goes through the same steps
+// as one started by danger-js, but those configured by the environment there,
+// such as waivers, metrics, reports and the files it writes, only run when set
+// in c. Unlike Run it doesn't need danger-js nor a plugin build, so other Go
+// tools can embed Danger and post or inspect the results themselves.
+func Execute(ctx context.Context, c Config) (danger.Results, error) {
+	if c.DSLSource == nil {
+		return danger.Results{}, errors.New("no DSL source")
+	}
+	in, err := c.DSLSource(ctx)
+	if err != nil {
+		return danger.Results{}, fmt.Errorf("reading DSL: %w", err)
+	}
+	req, err := readRequest(ctx, in)
+	if err != nil {
+		return danger.Results{}, fmt.Errorf("reading DSL: %w", err)
+	}
+	dsl, closeDSL, err := req.decodeDSL()
+	if err != nil {
+		return danger.Results{}, fmt.Errorf("failed to read DSL JSON: %w", err)
+	}
+	defer closeDSL()
+
+	load := loadDangerfile
+	if c.Dangerfunc != nil {
+		load = staticLoader(c.Dangerfunc)
+	}
+	opts := make([]danger.Option, 0, len(c.Reporters))
+	for _, r := range c.Reporters {
+		opts = append(opts, danger.WithReporter(r))
+	}
+	reviewers := make([]string, 0, len(c.WaiverReviewers))
+	for _, r := range c.WaiverReviewers {
+		reviewers = append(reviewers, strings.ToLower(r))
+	}
+	fx := effects{
+		protected:       c.ProtectedPaths,
+		messages:        c.Messages,
+		dslOptions:      c.DSLOptions,
+		previousComment: c.PreviousComment,
+		waivers:         c.Waivers,
+		waiverReviewers: reviewers,
+		metrics:         c.Metrics,
+		codeQualityFile: c.CodeQualityFile,
+		jobSummaryFile:  c.JobSummaryFile,
+		safeMode:        c.SafeMode,
+		resultsFile:     c.ResultsFile,
+		outputsFile:     c.OutputsFile,
+		attestationFile: c.AttestationFile,
+		attestationKey:  attestationKey{key: c.AttestationKey},
+		state:           c.State,
+		gitLabStatus:    c.GitLabStatus,
+		commentPages:    c.PostCommentPages,
+		autofix:         c.Autofix,
+	}
+	return execute(ctx, dsl, load, fx, opts...)
+}
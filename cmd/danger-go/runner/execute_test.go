@@ -0,0 +1,150 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+func TestExecute(t *testing.T) {
+	t.Setenv("DANGER_PROTECTED_PATHS", "")
+
+	var reported []danger.Kind
+	results, err := Execute(context.Background(), Config{
+		DSLSource: DSLJSON([]byte(dslJSON)),
+		Dangerfunc: func(d *danger.T, pr danger.DSL) {
+			for _, f := range pr.Git.ModifiedFiles() {
+				d.Warn("Modified "+f, f, 0)
+			}
+		},
+		Reporters: []danger.Reporter{danger.ReporterFunc(func(kind danger.Kind, v danger.Violation) {
+			reported = append(reported, kind)
+		})},
+	})
+	require.Nil(t, err)
+	require.Len(t, results.Warnings, 1)
+	require.Equal(t, "Modified a.go", results.Warnings[0].Message)
+	require.Equal(t, []danger.Kind{danger.KindWarning}, reported)
+}
+
+func TestExecuteEffectsOptIn(t *testing.T) {
+	t.Setenv("DANGER_PROTECTED_PATHS", "")
+	dir := t.TempDir()
+	envResults := filepath.Join(dir, "env-results.json")
+	envOutputs := filepath.Join(dir, "env-outputs")
+	t.Setenv(resultsFileEnv, envResults)
+	t.Setenv("GITHUB_OUTPUT", envOutputs)
+	t.Setenv("DANGER_ATTESTATION_FILE", filepath.Join(dir, "env-attestation.json"))
+	warn := func(d *danger.T, _ danger.DSL) { d.Warn("warned", "", 0) }
+
+	_, err := Execute(context.Background(), Config{DSLSource: DSLJSON([]byte(dslJSON)), Dangerfunc: warn})
+	require.Nil(t, err)
+	entries, err := os.ReadDir(dir)
+	require.Nil(t, err)
+	require.Empty(t, entries, "the environment doesn't enable the effects of Execute")
+
+	results := filepath.Join(dir, "results.json")
+	outputs := filepath.Join(dir, "outputs")
+	_, err = Execute(context.Background(), Config{
+		DSLSource:   DSLJSON([]byte(dslJSON)),
+		Dangerfunc:  warn,
+		ResultsFile: results,
+		OutputsFile: outputs,
+	})
+	require.Nil(t, err)
+	saved, err := os.ReadFile(results)
+	require.Nil(t, err)
+	require.Contains(t, string(saved), "warned")
+	written, err := os.ReadFile(outputs)
+	require.Nil(t, err)
+	require.Contains(t, string(written), "warnings_count=1\nmessages_count=0\nreport_path="+results+"\n")
+	_, err = os.Stat(envResults)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestExecuteIgnoresEnvironment(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+	env := map[string]string{
+		"DANGER_PROTECTED_PATHS":         "a.go",
+		"DANGER_MESSAGES_FILE":           filepath.Join(dir, "missing.yaml"),
+		"DANGER_IMPACT_FILE":             filepath.Join(dir, "missing.json"),
+		"DANGER_BAZEL_IMPACT":            "1",
+		"DANGER_WAIVERS":                 "true",
+		"DANGER_METRICS_PUSHGATEWAY_URL": srv.URL,
+		"OTEL_EXPORTER_OTLP_ENDPOINT":    srv.URL,
+		"DANGER_ANALYTICS_FILE":          filepath.Join(dir, "analytics.jsonl"),
+		"DANGER_GITLAB_CODE_QUALITY":     filepath.Join(dir, "code-quality.json"),
+		"DANGER_JOB_SUMMARY":             "1",
+		"GITHUB_STEP_SUMMARY":            filepath.Join(dir, "summary.md"),
+		dangerJs.SafeModeEnv:             "true",
+		resultsFileEnv:                   filepath.Join(dir, "results.json"),
+		"GITHUB_OUTPUT":                  filepath.Join(dir, "outputs"),
+		"DANGER_ATTESTATION_FILE":        filepath.Join(dir, "attestation.json"),
+		"DANGER_GITLAB_STATUS":           "danger",
+	}
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+	dsl := `{"danger":{
+		"git":{"modified_files":["a.go"]},
+		"github":{"pr":{"number":1,"title":"Fix [skip danger]","user":{"login":"author"}},"thisPR":{"owner":"o","repo":"r","number":1}},
+		"settings":{"github":{"accessToken":"token","baseURL":"` + srv.URL + `"}}
+	}}`
+
+	fail := func(d *danger.T, _ danger.DSL) { d.Fail("failed", "", 0) }
+
+	results, err := Execute(context.Background(), Config{DSLSource: DSLJSON([]byte(dsl)), Dangerfunc: fail})
+	require.Nil(t, err)
+	require.Empty(t, results.Fails, "DANGER_PROTECTED_PATHS doesn't refuse the skip")
+	require.Len(t, results.Messages, 1)
+
+	results, err = Execute(context.Background(), Config{
+		DSLSource:      DSLJSON([]byte(strings.Replace(dsl, " [skip danger]", "", 1))),
+		Dangerfunc:     fail,
+		ProtectedPaths: []string{"a.go"},
+	})
+	require.Nil(t, err)
+	require.Len(t, results.Fails, 1, "DANGER_WAIVERS doesn't apply waivers")
+	require.Empty(t, calls)
+	entries, err := os.ReadDir(dir)
+	require.Nil(t, err)
+	require.Empty(t, entries)
+}
+
+func TestExecuteErrors(t *testing.T) {
+	noop := func(*danger.T, danger.DSL) {}
+	testCases := []struct {
+		name   string
+		c      Config
+		expErr string
+	}{
+		{name: "no source", c: Config{Dangerfunc: noop}, expErr: "no DSL source"},
+		{name: "not a DSL", c: Config{DSLSource: DSLJSON([]byte("nope")), Dangerfunc: noop}, expErr: "reading DSL: did not receive a DSL URL or JSON"},
+		{
+			name:   "missing file",
+			c:      Config{DSLSource: DSLFile(filepath.Join(t.TempDir(), "missing.json")), Dangerfunc: noop},
+			expErr: "failed to read DSL JSON",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Execute(context.Background(), tc.c)
+			require.ErrorContains(t, err, tc.expErr)
+		})
+	}
+}
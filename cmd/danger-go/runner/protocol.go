@@ -0,0 +1,223 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+const (
+	// resultsURLPrefix tells danger-js to read the results from a file
+	// rather than from stdout.
+	resultsURLPrefix = "danger-results:/"
+	// protocolVersion is the highest JSON-RPC protocol version understood.
+	protocolVersion = 2
+	rpcMethod       = "danger.run"
+)
+
+// request is a run request received from danger-js on stdin, in one of
+// three forms:
+//   - "danger://dsl/<path>", sent with --passURLForDSL. The path may also be a
+//     file:// or http(s):// URL.
+//   - the DSL JSON itself, sent without --passURLForDSL.
+//   - a JSON-RPC 2.0 "danger.run" request whose params carry the DSL or its
+//     URL, and optionally the path to write the results to.
+type request struct {
+	// dslPath is the local file holding the DSL document, which has the DSL
+	// under its "danger" key. When empty the document is in dsl.
+	dslPath string
+	dsl     []byte
+	// resultsPath is where the results are written, stdout when empty.
+	resultsPath string
+	// rpcID is the id of a JSON-RPC request, nil for the other forms.
+	rpcID json.RawMessage
+}
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Method  string          `json:"method"`
+	Params  struct {
+		Version     int             `json:"version"`
+		DSL         json.RawMessage `json:"dsl"`
+		DSLURL      string          `json:"dslURL"`
+		ResultsPath string          `json:"resultsPath"`
+	} `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// readRequest parses the stdin contents sent by danger-js and loads the DSL.
+func readRequest(ctx context.Context, in []byte) (request, error) {
+	in = bytes.TrimSpace(in)
+	switch {
+	case bytes.HasPrefix(in, []byte(dangerURLPrefix)):
+		var req request
+		var err error
+		req.dslPath, req.dsl, err = loadDSL(ctx, strings.TrimPrefix(string(in), dangerURLPrefix))
+		return req, err
+	case bytes.HasPrefix(in, []byte("{")):
+		var rpc rpcRequest
+		if err := json.Unmarshal(in, &rpc); err != nil {
+			return request{}, fmt.Errorf("decoding stdin JSON: %w", err)
+		}
+		if rpc.JSONRPC == "" {
+			return request{dsl: in}, nil
+		}
+		return rpcRun(ctx, rpc)
+	}
+	return request{}, errors.New("did not receive a DSL URL or JSON")
+}
+
+func rpcRun(ctx context.Context, rpc rpcRequest) (request, error) {
+	req := request{rpcID: rpc.ID, resultsPath: rpc.Params.ResultsPath}
+	if len(req.rpcID) == 0 {
+		req.rpcID = json.RawMessage("null")
+	}
+	if rpc.JSONRPC != "2.0" || rpc.Method != rpcMethod {
+		return req, fmt.Errorf("unsupported JSON-RPC request %q %q", rpc.JSONRPC, rpc.Method)
+	}
+	if rpc.Params.Version > protocolVersion {
+		return req, fmt.Errorf("unsupported protocol version %d, danger-go supports up to %d", rpc.Params.Version, protocolVersion)
+	}
+	switch {
+	case len(rpc.Params.DSL) > 0:
+		req.dsl = rpc.Params.DSL
+	case rpc.Params.DSLURL != "":
+		var err error
+		req.dslPath, req.dsl, err = loadDSL(ctx, strings.TrimPrefix(rpc.Params.DSLURL, dangerURLPrefix))
+		if err != nil {
+			return req, err
+		}
+	default:
+		return req, errors.New("JSON-RPC request has neither dsl nor dslURL")
+	}
+	return req, nil
+}
+
+// loadDSL locates the DSL document given as a file path or a file:// or
+// http(s):// URL. Local files are returned as a path so they can be decoded
+// without reading them into memory, downloads as their contents.
+func loadDSL(ctx context.Context, location string) (string, []byte, error) {
+	u, err := url.Parse(location)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "file") {
+		// a plain path, which may be a Windows path like C:\tmp\dsl.json
+		return location, nil, nil
+	}
+	if u.Scheme == "file" {
+		return fileURLPath(u), nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("creating DSL request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("fetching DSL: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("fetching DSL: %s", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, fmt.Errorf("reading DSL response: %w", err)
+	}
+	return "", data, nil
+}
+
+// decodeDSL streams the DSL out of the request's document. The returned
+// function releases the source, which must stay open while the DSL is used
+// as parts of it are decoded lazily.
+func (r request) decodeDSL() (dangerJs.DSLData, func(), error) {
+	if r.dslPath == "" {
+		d, err := dangerJs.DecodeDSLStream(bytes.NewReader(r.dsl), int64(len(r.dsl)))
+		return d, func() {}, err
+	}
+	f, err := os.Open(r.dslPath)
+	if err != nil {
+		return dangerJs.DSLData{}, nil, fmt.Errorf("reading DSL file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return dangerJs.DSLData{}, nil, fmt.Errorf("reading DSL file: %w", err)
+	}
+	d, err := dangerJs.DecodeDSLStream(f, info.Size())
+	if err != nil {
+		_ = f.Close()
+		return dangerJs.DSLData{}, nil, err
+	}
+	return d, func() { _ = f.Close() }, nil
+}
+
+// fileURLPath returns the local path of a file:// URL, including Windows
+// forms like file:///C:/dsl.json and file://C:/dsl.json.
+func fileURLPath(u *url.URL) string {
+	p := u.Host + u.Path
+	if len(p) > 2 && p[0] == '/' && p[2] == ':' {
+		p = p[1:]
+	}
+	return filepath.FromSlash(p)
+}
+
+// writeResults sends the results JSON to danger-js in the form matching the
+// request.
+func writeResults(w io.Writer, req request, results string) error {
+	if req.resultsPath != "" {
+		if err := os.WriteFile(req.resultsPath, []byte(results), 0o600); err != nil {
+			return fmt.Errorf("writing results: %w", err)
+		}
+	}
+	switch {
+	case req.rpcID != nil:
+		var result any = json.RawMessage(results)
+		if req.resultsPath != "" {
+			result = map[string]string{"resultsPath": req.resultsPath}
+		}
+		return json.NewEncoder(w).Encode(rpcResponse{JSONRPC: "2.0", ID: req.rpcID, Result: result})
+	case req.resultsPath != "":
+		_, err := fmt.Fprintln(w, resultsURLPrefix+req.resultsPath)
+		return err
+	default:
+		_, err := fmt.Fprint(w, results)
+		return err
+	}
+}
+
+// writeError reports a failed JSON-RPC request. Other requests have no way to
+// report errors besides the exit code and stderr.
+func writeError(w io.Writer, req request, err error) {
+	if req.rpcID == nil {
+		return
+	}
+	_ = json.NewEncoder(w).Encode(rpcResponse{
+		JSONRPC: "2.0",
+		ID:      req.rpcID,
+		Error:   &rpcError{Code: -32000, Message: err.Error()},
+	})
+}
@@ -0,0 +1,163 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const dslJSON = `{"danger":{"git":{"modified_files":["a.go"],"commits":[{"sha":"abc"}]}}}`
+
+func TestReadRequest(t *testing.T) {
+	dir := t.TempDir()
+	dslPath := filepath.Join(dir, "dsl.json")
+	require.Nil(t, os.WriteFile(dslPath, []byte(dslJSON), 0o600))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dsl.json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(dslJSON))
+	}))
+	defer srv.Close()
+
+	testCases := []struct {
+		name        string
+		in          string
+		resultsPath string
+		rpcID       string
+		expErr      string
+	}{
+		{name: "file URL", in: "danger://dsl/" + dslPath + "\n"},
+		{name: "file scheme", in: "danger://dsl/file://" + filepath.ToSlash(dslPath)},
+		{name: "http URL", in: "danger://dsl/" + srv.URL + "/dsl.json"},
+		{name: "http not found", in: "danger://dsl/" + srv.URL + "/missing", expErr: "fetching DSL: 404 Not Found"},
+		{name: "inline JSON", in: dslJSON},
+		{
+			name:        "JSON-RPC with DSL",
+			in:          `{"jsonrpc":"2.0","id":7,"method":"danger.run","params":{"version":2,"dsl":` + dslJSON + `,"resultsPath":"/tmp/r.json"}}`,
+			resultsPath: "/tmp/r.json",
+			rpcID:       "7",
+		},
+		{
+			name:  "JSON-RPC with URL",
+			in:    `{"jsonrpc":"2.0","id":"a","method":"danger.run","params":{"dslURL":"danger://dsl/` + srv.URL + `/dsl.json"}}`,
+			rpcID: `"a"`,
+		},
+		{
+			name:   "JSON-RPC newer version",
+			in:     `{"jsonrpc":"2.0","id":1,"method":"danger.run","params":{"version":3,"dsl":{}}}`,
+			rpcID:  "1",
+			expErr: "unsupported protocol version 3, danger-go supports up to 2",
+		},
+		{
+			name:   "JSON-RPC unknown method",
+			in:     `{"jsonrpc":"2.0","id":1,"method":"danger.stop"}`,
+			rpcID:  "1",
+			expErr: `unsupported JSON-RPC request "2.0" "danger.stop"`,
+		},
+		{name: "garbage", in: "hello", expErr: "did not receive a DSL URL or JSON"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := readRequest(context.Background(), []byte(tc.in))
+			if tc.rpcID != "" {
+				require.Equal(t, tc.rpcID, string(req.rpcID))
+			} else {
+				require.Nil(t, req.rpcID)
+			}
+			if tc.expErr != "" {
+				require.EqualError(t, err, tc.expErr)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tc.resultsPath, req.resultsPath)
+			dsl, closeDSL, err := req.decodeDSL()
+			require.Nil(t, err)
+			defer closeDSL()
+			pr := dsl.ToInterface()
+			require.Equal(t, []string{"a.go"}, pr.Git.ModifiedFiles())
+			require.Equal(t, "abc", pr.Git.Commits()[0].SHA)
+		})
+	}
+}
+
+func TestDumpDSL(t *testing.T) {
+	dir := t.TempDir()
+	dslPath := filepath.Join(dir, "dsl.json")
+	require.Nil(t, os.WriteFile(dslPath, []byte(`{"danger":{"settings":{"github":{"accessToken":"secret"}}}}`), 0o600))
+	dump := filepath.Join(dir, "dump.json")
+
+	require.Nil(t, dumpDSL(request{dslPath: dslPath}))
+	require.NoFileExists(t, dump)
+
+	t.Setenv(DumpDSLEnv, dump)
+	for _, req := range []request{
+		{dslPath: dslPath},
+		{dsl: []byte(`{"danger":{"settings":{"github":{"accessToken":"secret"}}}}`)},
+	} {
+		require.Nil(t, dumpDSL(req))
+		data, err := os.ReadFile(dump)
+		require.Nil(t, err)
+		require.JSONEq(t, `{"danger":{"settings":{"github":{"accessToken":"[scrubbed]"}}}}`, string(data))
+	}
+}
+
+func TestDecodeDSLMissingFile(t *testing.T) {
+	req, err := readRequest(context.Background(), []byte("danger://dsl/"+filepath.Join(t.TempDir(), "missing.json")))
+	require.Nil(t, err)
+	_, _, err = req.decodeDSL()
+	require.ErrorContains(t, err, "reading DSL file")
+}
+
+func TestWriteResults(t *testing.T) {
+	const results = `{"fails":[]}`
+	path := filepath.Join(t.TempDir(), "results.json")
+
+	var out bytes.Buffer
+	require.Nil(t, writeResults(&out, request{}, results))
+	require.Equal(t, results, out.String())
+
+	out.Reset()
+	require.Nil(t, writeResults(&out, request{resultsPath: path}, results))
+	require.Equal(t, "danger-results:/"+path+"\n", out.String())
+	written, err := os.ReadFile(path)
+	require.Nil(t, err)
+	require.Equal(t, results, string(written))
+
+	out.Reset()
+	require.Nil(t, writeResults(&out, request{rpcID: []byte("7")}, results))
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":7,"result":{"fails":[]}}`, out.String())
+
+	out.Reset()
+	require.Nil(t, writeResults(&out, request{rpcID: []byte("7"), resultsPath: path}, results))
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":7,"result":{"resultsPath":"`+path+`"}}`, out.String())
+
+	out.Reset()
+	writeError(&out, request{rpcID: []byte("7")}, errors.New("boom"))
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":7,"error":{"code":-32000,"message":"boom"}}`, out.String())
+
+	out.Reset()
+	writeError(&out, request{}, errors.New("boom"))
+	require.Empty(t, out.String())
+}
+
+func TestFileURLPath(t *testing.T) {
+	for in, want := range map[string]string{
+		"file:///tmp/dsl.json":    "/tmp/dsl.json",
+		"file:///C:/tmp/dsl.json": "C:/tmp/dsl.json",
+		"file://C:/tmp/dsl.json":  "C:/tmp/dsl.json",
+	} {
+		u, err := url.Parse(in)
+		require.Nil(t, err)
+		require.Equal(t, filepath.FromSlash(want), fileURLPath(u))
+	}
+}
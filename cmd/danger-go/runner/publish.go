@@ -0,0 +1,80 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+const (
+	// resultsFileEnv overrides where the runner persists the results before
+	// handing them to danger-js for publishing.
+	resultsFileEnv = "DANGER_RESULTS_FILE"
+	// publishFromEnv makes the runner skip the dangerfile and hand the
+	// results persisted by an earlier run to danger-js.
+	publishFromEnv = "DANGER_GO_PUBLISH_FROM"
+)
+
+// resultsFile returns where the results are persisted.
+func resultsFile() string {
+	if path := os.Getenv(resultsFileEnv); path != "" {
+		return path
+	}
+	return filepath.Join(os.TempDir(), "danger-go-results.json")
+}
+
+// saveResults writes the results JSON to path, replacing the file atomically
+// so an interrupted run doesn't leave a truncated file behind.
+func saveResults(path string, results []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".danger-results-*")
+	if err != nil {
+		return fmt.Errorf("creating results file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(results); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing results file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing results file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("writing results file: %w", err)
+	}
+	return nil
+}
+
+// loadResults reads results persisted by saveResults, checking they decode.
+func loadResults(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading results file: %w", err)
+	}
+	var r danger.Results
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("decoding results file %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// Publish runs danger-js to post the results persisted by an earlier run at
+// from, without running the dangerfile again, e.g. to retry after the GitHub
+// API failed while posting the comment. args are passed to `danger ci`.
+func Publish(from string, args []string) error {
+	if _, err := loadResults(from); err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(from)
+	if err != nil {
+		return fmt.Errorf("resolving results file: %w", err)
+	}
+	// danger-js passes its environment on to the runner it spawns
+	if err := os.Setenv(publishFromEnv, abs); err != nil {
+		return fmt.Errorf("setting %s: %w", publishFromEnv, err)
+	}
+	return dangerJs.Process("ci", args)
+}
@@ -0,0 +1,36 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveAndLoadResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	require.Nil(t, os.WriteFile(path, []byte("stale"), 0o600))
+
+	results := []byte(`{"fails":[{"message":"no changelog"}],"warnings":[],"messages":[],"markdowns":[]}`)
+	require.Nil(t, saveResults(path, results))
+	got, err := loadResults(path)
+	require.Nil(t, err)
+	require.Equal(t, results, got)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.Nil(t, err)
+	require.Len(t, entries, 1, "no temporary file left behind")
+}
+
+func TestLoadResultsInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	require.Nil(t, os.WriteFile(path, []byte("{"), 0o600))
+	_, err := loadResults(path)
+	require.ErrorContains(t, err, "decoding results file")
+}
+
+func TestResultsFile(t *testing.T) {
+	t.Setenv(resultsFileEnv, "out/results.json")
+	require.Equal(t, "out/results.json", resultsFile())
+}
@@ -1,73 +1,691 @@
 package runner
 
 import (
-	"bufio"
-	"bytes"
+	"context"
+	"crypto/ed25519"
 	_ "embed"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"plugin"
+	"slices"
 	"strings"
+	"time"
 
 	danger "github.com/danger/golang"
+	"github.com/danger/golang/autofix"
 	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/githubclient"
+	"github.com/danger/golang/gitlabclient"
+	"github.com/danger/golang/metrics"
+	"github.com/danger/golang/report"
+	"github.com/danger/golang/runstate"
+	"github.com/danger/golang/tracing"
 )
 
 const dangerURLPrefix = "danger://dsl/"
 
-// Run reads the danger DSL URL from stdin, invokes the Go dangerfile as a
-// plugin, and then writes the results JSON to stdout.
+// Run reads the danger DSL, or its URL, from stdin, invokes the Go dangerfile
+// as a plugin, and then writes the results JSON to stdout or to the path
+// requested by danger-js. See request for the supported protocols.
 func Run() {
-	in := readAll()
-	in = strings.TrimSpace(in)
+	shutdownTracing := tracing.Init()
+	defer flushTraces(shutdownTracing)
+	ctx, span := tracing.Start(context.Background(), "danger-go run")
+	defer span.End()
 
-	if !strings.HasPrefix(in, dangerURLPrefix) {
-		log.Fatalf("did not receive a DSL URL")
-	}
-
-	jsonPath := strings.Replace(in, dangerURLPrefix, "", 1)
-	jsonBytes, err := os.ReadFile(jsonPath)
+	in, err := io.ReadAll(os.Stdin)
 	if err != nil {
-		log.Fatalf("failed to read JSON file at %s", jsonPath)
+		log.Fatalf("reading stdin: %s", err.Error())
+	}
+	if err := serve(ctx, in, os.Stdout, loadDangerfile); err != nil {
+		log.Fatal(err.Error())
 	}
+}
+
+// Serve handles a single request of danger-js, as read from stdin by Run,
+// running fn as the dangerfile and writing the response to out. It lets tests
+// and other tools drive the runner without building a plugin.
+func Serve(ctx context.Context, in []byte, out io.Writer, fn MainFunc) error {
+	return serve(ctx, in, out, staticLoader(fn))
+}
 
-	var jsonData struct {
-		Danger dangerJs.DSLData `json:"danger"`
+// dangerfile is a loaded dangerfile.
+type dangerfile struct {
+	run MainFunc
+	// sources are the digests of the dangerfile and rule bundles run was
+	// built from, recorded in the attestation.
+	sources []report.StatementItem
+	// release frees what was loaded.
+	release func()
+}
+
+// dangerfileLoader provides the dangerfile to run. It is only called when the
+// run isn't skipped.
+type dangerfileLoader func(ctx context.Context) (dangerfile, error)
+
+// staticLoader provides fn, which needs no releasing.
+func staticLoader(fn MainFunc) dangerfileLoader {
+	return func(context.Context) (dangerfile, error) {
+		return dangerfile{run: fn, release: func() {}}, nil
 	}
-	err = json.Unmarshal(jsonBytes, &jsonData)
+}
+
+// loadDangerfile builds and loads dangerfile.go as a plugin, along with the
+// rule bundles of danger.yaml. The dangerfile is optional when bundles are
+// configured.
+func loadDangerfile(ctx context.Context) (dangerfile, error) {
+	bundles, sources, releaseBundles, err := loadBundles(ctx)
 	if err != nil {
-		fmt.Println("JSON\n", string(jsonBytes))
-		log.Fatalf("failed to unmarshal DSL JSON: %s", err.Error())
+		return dangerfile{}, err
 	}
 
 	dangerFile := "dangerfile.go"
 	// TODO: Find a way to build dangerfile.go that is in project's root... will
 	// have to copy along go.mod & go.sum or create new ones in temp directory.
 	// TODO: Take -d/--dangerfile arg into account
+	if _, err := os.Stat(dangerFile); os.IsNotExist(err) && len(bundles) > 0 {
+		return dangerfile{run: withBundles(bundles, nil), sources: sources, release: releaseBundles}, nil
+	}
+	source, err := report.FileDigest(dangerFile, dangerFile)
+	if err != nil {
+		releaseBundles()
+		return dangerfile{}, err
+	}
+	_, buildSpan := tracing.Start(ctx, "build dangerfile")
 	libPath, clearTempDir, err := buildPlugin(dangerFile)
+	buildSpan.SetError(err)
+	buildSpan.End()
 	if err != nil {
-		log.Fatalf("building plugin from dangerfile: %s", err.Error())
+		releaseBundles()
+		return dangerfile{}, fmt.Errorf("building plugin from dangerfile: %w", err)
 	}
-	defer func() { _ = clearTempDir() }()
 
+	_, loadSpan := tracing.Start(ctx, "load dangerfile")
 	fn, err := loadPlugin(libPath)
+	loadSpan.SetError(err)
+	loadSpan.End()
 	if err != nil {
-		log.Fatalf("loading dangerfile plugin: %s", err.Error())
+		_ = clearTempDir()
+		releaseBundles()
+		return dangerfile{}, fmt.Errorf("loading dangerfile plugin: %w", err)
 	}
+	return dangerfile{
+		run:     withBundles(bundles, fn),
+		sources: append([]report.StatementItem{source}, sources...),
+		release: func() {
+			_ = clearTempDir()
+			releaseBundles()
+		},
+	}, nil
+}
 
-	d := danger.New()
-	fn(d, jsonData.Danger.ToInterface())
-	respJSON, err := d.Results()
+func serve(ctx context.Context, in []byte, out io.Writer, load dangerfileLoader) error {
+	req, err := readRequest(ctx, in)
+	// fail also reports the error to danger-js for JSON-RPC requests
+	fail := func(format string, args ...any) error {
+		err := fmt.Errorf(format, args...)
+		writeError(out, req, err)
+		return err
+	}
 	if err != nil {
-		log.Fatalf("marshalling response: %s", err.Error())
+		return fail("reading DSL: %w", err)
+	}
+	if err := dumpDSL(req); err != nil {
+		log.Printf("dumping DSL: %s", err.Error())
+	}
+
+	dsl, closeDSL, err := req.decodeDSL()
+	if err != nil {
+		return fail("failed to read DSL JSON: %w", err)
+	}
+	defer closeDSL()
+
+	if from := os.Getenv(publishFromEnv); from != "" {
+		results, err := loadResults(from)
+		if err != nil {
+			return fail("publishing saved results: %w", err)
+		}
+		if err := writeResults(out, req, string(results)); err != nil {
+			return fmt.Errorf("sending results: %w", err)
+		}
+		return nil
+	}
+
+	results, err := execute(ctx, dsl, load, effectsFromEnv())
+	if err != nil {
+		return fail("%w", err)
+	}
+	resp, err := json.Marshal(results)
+	if err != nil {
+		return fail("marshalling response: %w", err)
+	}
+	if err := writeResults(out, req, string(resp)); err != nil {
+		return fmt.Errorf("sending results: %w", err)
+	}
+	return nil
+}
+
+// effects are what a run does beyond computing its results. Runs started by
+// danger-js take them from the environment, see effectsFromEnv, while Execute
+// only has those set in its Config.
+type effects struct {
+	// protected are the globs of the paths skip requests can't skip.
+	protected []string
+	// messages override the default message templates.
+	messages danger.Messages
+	// dslOptions complete the DSL, e.g. with the impact analysis.
+	dslOptions []danger.DSLOption
+	// previousComment finds the Danger comment of the previous run on
+	// GitHub.
+	previousComment bool
+	waivers         bool
+	// waiverReviewers are the lowercase logins who may accept failures,
+	// anyone with write access but the author when empty.
+	waiverReviewers []string
+	metrics         []metrics.Exporter
+	// resultsFile is where the results are saved for `danger-go publish`.
+	resultsFile string
+	// outputsFile is the step outputs file of GitHub Actions.
+	outputsFile     string
+	codeQualityFile string
+	jobSummaryFile  string
+	// safeMode reports the results as annotations and skips the writes of
+	// a read-only token.
+	safeMode        bool
+	attestationFile string
+	attestationKey  attestationKey
+	state           runstate.Store
+	// gitLabStatus names the commit status set on the MR head.
+	gitLabStatus string
+	commentPages bool
+	// autofix returns the committer of the fixes, which are suggested
+	// without one.
+	autofix func(pr danger.DSL) (autofix.Committer, error)
+}
+
+// effectsFromEnv returns the effects configured by the environment. The
+// attestation key is removed from the environment, so it must be called
+// before loading the dangerfile.
+func effectsFromEnv() effects {
+	state, err := runstate.FromEnv()
+	if err != nil {
+		log.Printf("loading run state: %s", err.Error())
+	}
+	safeMode := danger.SafeMode()
+	var jobSummaryFile string
+	if safeMode || os.Getenv("DANGER_JOB_SUMMARY") != "" {
+		jobSummaryFile = os.Getenv("GITHUB_STEP_SUMMARY")
+	}
+	return effects{
+		protected:       splitList(os.Getenv("DANGER_PROTECTED_PATHS")),
+		messages:        loadMessages(),
+		dslOptions:      impactOptions(),
+		previousComment: true,
+		waivers:         os.Getenv("DANGER_WAIVERS") == "true",
+		waiverReviewers: waiverReviewers(os.Getenv("DANGER_WAIVER_REVIEWERS")),
+		metrics:         metrics.FromEnv(),
+		resultsFile:     resultsFile(),
+		outputsFile:     os.Getenv("GITHUB_OUTPUT"),
+		codeQualityFile: os.Getenv("DANGER_GITLAB_CODE_QUALITY"),
+		jobSummaryFile:  jobSummaryFile,
+		safeMode:        safeMode,
+		attestationFile: os.Getenv("DANGER_ATTESTATION_FILE"),
+		attestationKey:  takeAttestationKey(),
+		state:           state,
+		gitLabStatus:    os.Getenv("DANGER_GITLAB_STATUS"),
+		commentPages:    true,
+		autofix:         autofix.FromEnv,
+	}
+}
+
+// execute runs the dangerfile provided by load against dsl, along with the
+// steps around it: waivers, fixes, checklist, metrics and reports, and the
+// effects fx. It returns the results to send back, fitted to the Danger
+// comment. opts configure the T passed to the dangerfile.
+func execute(ctx context.Context, dsl dangerJs.DSLData, load dangerfileLoader, fx effects, opts ...danger.Option) (danger.Results, error) {
+	skip, skipReason := danger.SkipCheck{Protected: fx.protected}.Check(dsl.ToInterface())
+	if skip {
+		// report the skip rather than an empty result, so it is visible why
+		// no checks ran
+		d := danger.New(opts...)
+		d.Message(skipReason, "", 0)
+		return d.Snapshot(), nil
+	}
+
+	loaded, err := load(ctx)
+	if err != nil {
+		return danger.Results{}, err
+	}
+	defer loaded.release()
+
+	d := danger.New(opts...)
+	if fx.messages != nil {
+		d.SetMessages(fx.messages)
+	}
+	pr := dsl.ToInterface()
+	for _, o := range append(apiFetchers(pr), fx.dslOptions...) {
+		o(&pr)
+	}
+	stateKey, prevState, hasPrevState := loadState(ctx, fx.state, pr)
+	d.SetPreviousCommentLoader(func() string {
+		var body string
+		if fx.previousComment {
+			body = previousComment(pr)
+		}
+		if body == "" && hasPrevState {
+			body = prevState.PreviousComment()
+		}
+		return body
+	})
+	_, runSpan := tracing.Start(ctx, "run dangerfile")
+	start := time.Now()
+	if !d.RunSafely(pr, loaded.run) {
+		runSpan.SetError(errors.New("dangerfile panicked"))
+	}
+	runSpan.End()
+	if skipReason != "" {
+		d.Warn(skipReason, "", 0)
+	}
+	if fx.waivers {
+		applyWaivers(d, pr, fx.waiverReviewers)
+	}
+	applyFixes(ctx, d, pr, fx.autofix)
+	d.AddChecklist()
+	d.AddCommentFrame()
+	exportMetrics(d, pr, time.Since(start), fx.metrics)
+	publishGitLabStatus(d, pr, fx.gitLabStatus)
+	writeCodeQuality(d, fx.codeQualityFile)
+	if fx.safeMode {
+		reportSafeMode(d)
+	}
+	if err := report.WriteJobSummaryFile(fx.jobSummaryFile, d.Snapshot()); err != nil {
+		log.Printf("writing job summary: %s", err.Error())
+	}
+
+	results, pages, limit := d.FitComment(pr)
+	if limit.Paginate && fx.commentPages && !fx.safeMode {
+		postCommentPages(pr, pages)
+	}
+	resp, err := json.Marshal(results)
+	if err != nil {
+		return danger.Results{}, fmt.Errorf("marshalling response: %w", err)
+	}
+	// persist the results first so a failed publish can be retried with
+	// `danger-go publish --from`
+	reportPath := fx.resultsFile
+	if reportPath != "" {
+		if err := saveResults(reportPath, resp); err != nil {
+			log.Printf("saving results: %s", err.Error())
+			reportPath = ""
+		}
+	}
+	if err := report.WriteOutputsFile(fx.outputsFile, d.Snapshot(), reportPath); err != nil {
+		log.Printf("writing step outputs: %s", err.Error())
+	}
+	writeAttestation(d, pr, fx.attestationFile, fx.attestationKey, loaded.sources)
+	if !fx.safeMode {
+		saveState(ctx, fx.state, stateKey, d, pr)
+	}
+	return results, nil
+}
+
+// splitList splits the comma-separated list s, dropping blank items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// apiFetchers returns options completing the DSL from the GitHub or GitLab
+// API when danger-js left parts of it out.
+func apiFetchers(pr danger.DSL) []danger.DSLOption {
+	var opts []danger.DSLOption
+	if pr.GitHub.ThisPR().Number != 0 && pr.Settings.GitHubAccessToken() != "" {
+		if c, err := githubclient.NewFromSettings(pr.Settings); err == nil {
+			opts = append(opts, danger.WithGitHubFetcher(c))
+		}
+	}
+	if pr.GitLab.Metadata().RepoSlug != "" {
+		if c, err := gitlabclient.NewFromSettings(pr.Settings); err == nil {
+			opts = append(opts, danger.WithGitLabFetcher(c))
+		}
+	}
+	return opts
+}
+
+// previousComment returns the body of the Danger comment of the previous run,
+// found by its change markers or checklist among the comments of the token's
+// user, if any. Failures are logged and
+// treated as a first run.
+func previousComment(pr danger.DSL) string {
+	if pr.GitHub.ThisPR().Number == 0 {
+		return ""
+	}
+	ops, err := danger.NewGitHubOps(pr)
+	if err != nil {
+		log.Printf("finding previous comment: %s", err.Error())
+		return ""
+	}
+	for _, marker := range []string{danger.CommentStateMarker, danger.ChecklistMarker} {
+		c, found, err := ops.FindOwnComment(marker)
+		if err != nil {
+			log.Printf("finding previous comment: %s", err.Error())
+			return ""
+		}
+		if found {
+			return c.Body
+		}
+	}
+	return ""
+}
+
+// impactOptions returns the option exposing the impact analysis configured
+// in the environment: the precomputed file at DANGER_IMPACT_FILE, or bazel
+// queries when DANGER_BAZEL_IMPACT is set.
+func impactOptions() []danger.DSLOption {
+	if path := os.Getenv("DANGER_IMPACT_FILE"); path != "" {
+		f, err := dangerJs.LoadImpactFile(path)
+		if err != nil {
+			log.Printf("loading impact analysis: %s", err.Error())
+			return nil
+		}
+		return []danger.DSLOption{danger.WithImpact(f)}
+	}
+	if os.Getenv("DANGER_BAZEL_IMPACT") != "" {
+		return []danger.DSLOption{danger.WithBazelImpact()}
+	}
+	return nil
+}
+
+// postCommentPages posts the results which did not fit in the Danger comment
+// as further PR comments. Failures are logged rather than failing the run.
+func postCommentPages(pr danger.DSL, pages []string) {
+	if pr.GitHub.ThisPR().Number == 0 {
+		if len(pages) > 0 {
+			log.Printf("posting %d comment pages: pagination is only supported on GitHub", len(pages))
+		}
+		return
+	}
+	ops, err := danger.NewGitHubOps(pr)
+	if err == nil {
+		err = ops.SetCommentPages("danger-go", pages)
+	}
+	if err != nil {
+		log.Printf("posting comment pages: %s", err.Error())
+	}
+}
+
+// publishGitLabStatus sets the commit status named name, if any, on the MR
+// head. Failures are logged rather than failing the run.
+func publishGitLabStatus(d *danger.T, pr danger.DSL, name string) {
+	if name == "" || pr.GitLab.Metadata().RepoSlug == "" {
+		return
+	}
+	c, err := gitlabclient.NewFromSettings(pr.Settings)
+	if err != nil {
+		log.Printf("publishing GitLab status: %s", err.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := (report.GitLabStatus{Client: c, Name: name}).Publish(ctx, pr, d.Snapshot()); err != nil {
+		log.Printf("publishing GitLab status: %s", err.Error())
+	}
+}
+
+// messagesFileEnv overrides the path of the messages file,
+// .danger/messages.yaml by default.
+const messagesFileEnv = "DANGER_MESSAGES_FILE"
+
+// loadMessages returns the messages of the messages section of the
+// configuration file, and of the messages file of the repository, if any, the
+// section taking precedence. Invalid files are logged and ignored.
+func loadMessages() danger.Messages {
+	c, err := LoadConfig(configPath())
+	if err != nil {
+		log.Printf("loading messages: %s", err.Error())
+		return nil
+	}
+	path := os.Getenv(messagesFileEnv)
+	if path == "" {
+		path = filepath.Join(".danger", "messages.yaml")
+	}
+	m, err := danger.LoadMessages(path)
+	if err != nil {
+		log.Printf("loading messages: %s", err.Error())
+		return nil
+	}
+	maps.Copy(m, c.Messages)
+	return m
+}
+
+// loadState returns the key of the PR in store, and the state saved by the
+// previous run on the PR, if any. Failures are logged and treated as a first
+// run.
+func loadState(ctx context.Context, store runstate.Store, pr danger.DSL) (string, runstate.State, bool) {
+	key := runstate.Key(pr)
+	if store == nil || key == "" {
+		return "", runstate.State{}, false
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	s, ok, err := store.Load(ctx, key)
+	if err != nil {
+		log.Printf("loading run state: %s", err.Error())
+	}
+	return key, s, ok
+}
+
+// saveState records the results of the run for the next runs on the PR,
+// keeping what the dangerfile recorded in the state.
+func saveState(ctx context.Context, store runstate.Store, key string, d *danger.T, pr danger.DSL) {
+	if store == nil || key == "" {
+		return
+	}
+	var head string
+	switch {
+	case pr.GitHub != nil && pr.GitHub.PR().Number != 0:
+		head = pr.GitHub.PR().Head.SHA
+	case pr.GitLab != nil:
+		head = pr.GitLab.MR().SHA
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	err := runstate.Update(ctx, store, key, func(s *runstate.State) {
+		s.Run = d.Now()
+		s.Head = head
+		s.Results = d.Snapshot()
+		for _, w := range d.AppliedWaivers() {
+			s.AddWaiver(w)
+		}
+	})
+	if err != nil {
+		log.Printf("saving run state: %s", err.Error())
+	}
+}
+
+// applyWaivers turns the failures of the rules reviewers accepted with
+// `/danger accept <rule>` comments into warnings. Only the lowercase logins of
+// reviewers may accept, when set; otherwise anyone with write access but the
+// PR author may.
+func applyWaivers(d *danger.T, pr danger.DSL, reviewers []string) {
+	if len(d.Snapshot().Fails) == 0 {
+		return
+	}
+	ops, err := danger.NewGitHubOps(pr)
+	if err != nil {
+		log.Printf("reading waivers: %s", err.Error())
+		return
+	}
+	author := pr.GitHub.PR().User.Login
+	var allowed func(string) bool
+	if len(reviewers) > 0 {
+		allowed = func(login string) bool {
+			return !strings.EqualFold(login, author) && slices.Contains(reviewers, strings.ToLower(login))
+		}
+	}
+	waivers, err := ops.Waivers(allowed)
+	if err != nil {
+		log.Printf("reading waivers: %s", err.Error())
+		return
+	}
+	if allowed == nil {
+		// write access was checked, the author can't waive their own PR
+		waivers = slices.DeleteFunc(waivers, func(w danger.Waiver) bool { return strings.EqualFold(w.By, author) })
+	}
+	d.ApplyWaivers(waivers)
+}
+
+// waiverReviewers parses the comma-separated logins of
+// DANGER_WAIVER_REVIEWERS, with or without their @.
+func waiverReviewers(s string) []string {
+	var reviewers []string
+	for _, r := range splitList(s) {
+		if r = strings.TrimSpace(strings.TrimPrefix(r, "@")); r != "" {
+			reviewers = append(reviewers, strings.ToLower(r))
+		}
+	}
+	return reviewers
+}
+
+// applyFixes commits the fixes added by the rules with the committer of
+// committer, e.g. autofix.FromEnv, and suggests them without one. Failures
+// are reported as warnings rather than failing the run.
+func applyFixes(ctx context.Context, d *danger.T, pr danger.DSL, committer func(danger.DSL) (autofix.Committer, error)) {
+	fixes := d.Fixes()
+	if len(fixes) == 0 {
+		return
+	}
+	var c autofix.Committer
+	if committer != nil {
+		var err error
+		if c, err = committer(pr); err != nil {
+			d.Warn(fmt.Sprintf("Not committing fixes: %s", err.Error()), "", 0)
+		}
+	}
+	if c == nil {
+		autofix.Suggest(d, fixes)
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	r, err := autofix.Run(ctx, ".", fixes, c)
+	autofix.Report(d, r)
+	if err != nil {
+		// the output of git push may hold the credentials of the remote URL,
+		// so it is only logged
+		log.Printf("%s", err.Error())
+		d.Warn("Could not commit the fixes, see the danger-go log for details", "", 0)
+	}
+}
+
+// writeCodeQuality writes the GitLab Code Quality report to path, if any.
+// Failures are logged rather than failing the run.
+func writeCodeQuality(d *danger.T, path string) {
+	if path == "" {
+		return
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		log.Printf("writing code quality report: %s", err.Error())
+		return
+	}
+	defer f.Close()
+	if err := report.WriteCodeQuality(f, d.Snapshot()); err != nil {
+		log.Printf("writing code quality report: %s", err.Error())
+	}
+}
+
+// attestationKey is the key signing the attestation, or why it couldn't be
+// read.
+type attestationKey struct {
+	key ed25519.PrivateKey
+	err error
+}
+
+// takeAttestationKey reads the PEM key of DANGER_ATTESTATION_KEY and removes
+// it from the environment.
+func takeAttestationKey() attestationKey {
+	pemKey, ok := os.LookupEnv("DANGER_ATTESTATION_KEY")
+	if !ok {
+		return attestationKey{}
+	}
+	_ = os.Unsetenv("DANGER_ATTESTATION_KEY")
+	if pemKey == "" {
+		return attestationKey{}
+	}
+	key, err := report.ParseSigningKey([]byte(pemKey))
+	return attestationKey{key: key, err: err}
+}
+
+// writeAttestation writes the policy attestation of the run to path, if any,
+// signed with key and recording the digests of the sources of the
+// dangerfile. Failures are logged rather than failing the run.
+func writeAttestation(d *danger.T, pr danger.DSL, path string, key attestationKey, sources []report.StatementItem) {
+	if path == "" {
+		return
+	}
+	if key.err != nil {
+		log.Printf("writing attestation: %s", key.err.Error())
+		return
+	}
+	if key.key == nil {
+		log.Printf("DANGER_ATTESTATION_KEY is not set, the attestation is unsigned")
+	}
+	statement := report.NewStatement(d.Snapshot(), d.RuleRuns(), report.SubjectOf(pr), d.Now(), sources...)
+	envelope, err := report.Sign(statement, key.key)
+	if err == nil {
+		var data []byte
+		if data, err = json.MarshalIndent(envelope, "", "  "); err == nil {
+			err = os.WriteFile(path, data, 0o644)
+		}
+	}
+	if err != nil {
+		log.Printf("writing attestation: %s", err.Error())
+	}
+}
+
+// reportSafeMode reports the results of a fork PR, which the read-only token
+// can't comment on, as Actions annotations, next to the job summary. The
+// annotations go to stderr as stdout carries the results to danger-js.
+func reportSafeMode(d *danger.T) {
+	if err := report.WriteAnnotations(os.Stderr, d.Snapshot()); err != nil {
+		log.Printf("writing annotations: %s", err.Error())
+	}
+}
+
+// exportMetrics pushes the run metrics to exporters. Failures are logged
+// rather than failing the run.
+func exportMetrics(d *danger.T, pr danger.DSL, duration time.Duration, exporters []metrics.Exporter) {
+	if len(exporters) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := metrics.Export(ctx, metrics.NewRun(d, pr, duration), exporters...); err != nil {
+		log.Printf("exporting metrics: %s", err.Error())
+	}
+}
+
+// flushTraces exports the spans recorded during the run. Failures are logged
+// rather than failing the run.
+func flushTraces(shutdown func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := shutdown(ctx); err != nil {
+		log.Printf("exporting traces: %s", err.Error())
 	}
-	fmt.Print(respJSON)
 }
 
 // buildPlugin builds the plugin and stores the artifacts in a temporary
@@ -127,19 +745,3 @@ func loadPlugin(libPath string) (MainFunc, error) {
 
 	return dangerFn, nil
 }
-
-// readAll reads everything on stdin until io.EOF and returns the result
-func readAll() string {
-	reader := bufio.NewReader(os.Stdin)
-	var bb bytes.Buffer
-	for {
-		line, _, err := reader.ReadLine()
-		if err == io.EOF {
-			break
-		} else if err != nil {
-			log.Fatalf("reading stdin: %s", err.Error())
-		}
-		bb.Write(line)
-	}
-	return bb.String()
-}
@@ -0,0 +1,17 @@
+package runner
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTakeAttestationKey(t *testing.T) {
+	t.Setenv("DANGER_ATTESTATION_KEY", "not a key")
+	key := takeAttestationKey()
+	require.NotNil(t, key.err)
+	_, set := os.LookupEnv("DANGER_ATTESTATION_KEY")
+	require.False(t, set, "the dangerfile can't read the key")
+	require.Equal(t, attestationKey{}, takeAttestationKey())
+}
@@ -0,0 +1,281 @@
+package danger
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Maximum lengths of a comment, in characters.
+const (
+	GitHubCommentLimit = 65536
+	GitLabCommentLimit = 1000000
+)
+
+const (
+	// commentOverhead reserves room for what danger-js adds around the
+	// violations: table headers, the footer and its hidden markers.
+	commentOverhead = 2000
+	// rowOverhead is the markup of a table row around its message.
+	rowOverhead = 80
+	// minTruncated is the shortest message worth keeping when truncating.
+	minTruncated = 200
+)
+
+// CommentLimit configures how results are shortened when the Danger comment
+// would exceed the platform's size limit.
+type CommentLimit struct {
+	// Max is the maximum length of the comment. Zero uses the limit of the
+	// platform the PR is on.
+	Max int
+	// ReportURL links the full report from the note summarizing what was
+	// left out, e.g. an upload made with the artifacts package.
+	ReportURL string
+	// Paginate posts what does not fit in additional managed comments
+	// instead of only counting it. Only supported on GitHub.
+	Paginate bool
+}
+
+// SetCommentLimit configures how the results are shortened to fit in the
+// Danger comment, see FitComment.
+func (s *T) SetCommentLimit(l CommentLimit) {
+	s.commentLimit = l
+}
+
+// FitComment returns the results fitted to the comment limit of the platform
+// of pr, along with the pages of overflow to post when pagination is
+// enabled. Repeated inline comments are grouped and the inline budget is
+// applied first, then with change markers enabled the results are compared
+// with the previous comment. See GroupInlineComments, ApplyInlineBudget,
+// MarkChanges and FitComment.
+func (s *T) FitComment(pr DSL) (Results, []string, CommentLimit) {
+	l := s.commentLimit
+	if l.Max == 0 {
+		l.Max = GitHubCommentLimit
+		if pr.GitLab != nil && pr.GitLab.Metadata().RepoSlug != "" {
+			l.Max = GitLabCommentLimit
+		}
+	}
+	r := ApplyInlineBudget(GroupInlineComments(s.Snapshot(), s.inlineThreads), s.inlineBudget)
+	if s.changeMarkers {
+		var unchanged bool
+		if r, unchanged = MarkChanges(r, s.previous()); unchanged {
+			s.logger.Print("Danger results unchanged since the last run")
+		}
+	}
+	r, pages := FitComment(r, l)
+	return r, pages, l
+}
+
+// EstimateCommentLength estimates the length of the comment danger-js renders
+// for r.
+func EstimateCommentLength(r Results) int {
+	n := commentOverhead
+	for _, vs := range [][]Violation{r.Fails, r.Warnings, r.Messages} {
+		for _, v := range vs {
+			n += rowLength(v)
+		}
+	}
+	for _, m := range r.Markdowns {
+		n += markdownLength(m)
+	}
+	return n
+}
+
+func rowLength(v Violation) int {
+	return rowOverhead + utf8.RuneCountInString(v.Message) + 2*utf8.RuneCountInString(v.File)
+}
+
+func markdownLength(v Violation) int {
+	return utf8.RuneCountInString(v.Message) + 2
+}
+
+// FitComment shortens r so that its comment fits in l.Max. Failures are kept
+// first, then as many warnings, messages and markdowns as fit, in that
+// order, truncating failures too long to fit. What is left out is counted in
+// a note linking l.ReportURL and, with l.Paginate, returned as pages of
+// Markdown to post as further comments. When no failure fits, a single one
+// counting them is kept so that the build still fails.
+func FitComment(r Results, l CommentLimit) (Results, []string) {
+	if l.Max <= 0 || EstimateCommentLength(r) <= l.Max {
+		return r, nil
+	}
+
+	// room for the note about what was left out
+	budget := l.Max - commentOverhead - minTruncated*2
+	fitted := r
+	fitted.Fails, fitted.Warnings, fitted.Messages, fitted.Markdowns = []Violation{}, []Violation{}, []Violation{}, []Violation{}
+	var overflow Results
+
+	keep := func(v Violation, length func(Violation) int, kept, left *[]Violation, truncate bool) {
+		n := length(v)
+		if n <= budget {
+			budget -= n
+			*kept = append(*kept, v)
+			return
+		}
+		markup := n - utf8.RuneCountInString(v.Message)
+		if truncate && budget-markup >= minTruncated {
+			v.Message = truncateRunes(v.Message, budget-markup-1) + "…"
+			budget -= length(v)
+			*kept = append(*kept, v)
+			return
+		}
+		*left = append(*left, v)
+	}
+	// the markdowns holding the state of the comment, its change markers and
+	// checklist, are always kept so the next run can read them back; their
+	// room is reserved first
+	for _, v := range r.Markdowns {
+		if keptMarkdown(v) {
+			budget -= markdownLength(v)
+		}
+	}
+	for _, v := range r.Fails {
+		keep(v, rowLength, &fitted.Fails, &overflow.Fails, true)
+	}
+	for _, v := range r.Warnings {
+		keep(v, rowLength, &fitted.Warnings, &overflow.Warnings, false)
+	}
+	for _, v := range r.Messages {
+		keep(v, rowLength, &fitted.Messages, &overflow.Messages, false)
+	}
+	for _, v := range r.Markdowns {
+		if keptMarkdown(v) {
+			fitted.Markdowns = append(fitted.Markdowns, v)
+			continue
+		}
+		keep(v, markdownLength, &fitted.Markdowns, &overflow.Markdowns, false)
+	}
+	if len(fitted.Fails) == 0 && len(overflow.Fails) > 0 {
+		fitted.Fails = append(fitted.Fails, Violation{
+			Message: fmt.Sprintf("%d %s did not fit in this comment.",
+				len(overflow.Fails), plural(len(overflow.Fails), "failure", "failures")),
+		})
+	}
+
+	var pages []string
+	if l.Paginate {
+		pages = renderPages(overflow, l.Max)
+	}
+	if note := overflowNote(overflow, len(pages), l.ReportURL); note != "" {
+		fitted.Markdowns = append([]Violation{{Message: note}}, fitted.Markdowns...)
+	}
+	return fitted, pages
+}
+
+// keptMarkdown reports whether v is never left out of the comment.
+func keptMarkdown(v Violation) bool {
+	return strings.Contains(v.Message, CommentStateMarker) || strings.Contains(v.Message, ChecklistMarker)
+}
+
+func truncateRunes(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+	return s
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// overflowNote summarizes what was left out of the comment.
+func overflowNote(overflow Results, pages int, reportURL string) string {
+	var counts []string
+	for _, c := range []struct {
+		n         int
+		one, many string
+	}{
+		{len(overflow.Fails), "failure", "failures"},
+		{len(overflow.Warnings), "warning", "warnings"},
+		{len(overflow.Messages), "message", "messages"},
+		{len(overflow.Markdowns), "markdown section", "markdown sections"},
+	} {
+		if c.n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", c.n, plural(c.n, c.one, c.many)))
+		}
+	}
+	if len(counts) == 0 {
+		return ""
+	}
+
+	list := strings.Join(counts, ", ")
+	if i := strings.LastIndex(list, ", "); i >= 0 {
+		list = list[:i] + " and " + list[i+2:]
+	}
+	note := fmt.Sprintf("> [!NOTE]\n> %s did not fit in this comment.", list)
+	if pages > 0 {
+		note += fmt.Sprintf(" They are listed in the next %d %s.", pages, plural(pages, "comment", "comments"))
+	}
+	if reportURL != "" {
+		note += fmt.Sprintf(" See the [full report](%s).", reportURL)
+	}
+	return note
+}
+
+// renderPages lists the overflow as Markdown split in pages of at most max
+// characters.
+func renderPages(overflow Results, max int) []string {
+	const header = "### Danger (continued)\n\n"
+	var blocks []string
+	for _, section := range []struct {
+		title string
+		icon  string
+		vs    []Violation
+	}{
+		{"Failures", "🚫", overflow.Fails},
+		{"Warnings", "⚠️", overflow.Warnings},
+		{"Messages", "📖", overflow.Messages},
+	} {
+		for i, v := range section.vs {
+			line := fmt.Sprintf("- %s %s", section.icon, v.Message)
+			if v.File != "" {
+				line += fmt.Sprintf(" (`%s", v.File)
+				if v.Line > 0 {
+					line += fmt.Sprintf(":%d", v.Line)
+				}
+				line += "`)"
+			}
+			if i == 0 {
+				line = "#### " + section.title + "\n\n" + line
+			}
+			blocks = append(blocks, line)
+		}
+	}
+	for _, m := range overflow.Markdowns {
+		blocks = append(blocks, m.Message)
+	}
+
+	room := max - utf8.RuneCountInString(header) - minTruncated
+	var pages []string
+	var page strings.Builder
+	size := 0
+	for _, b := range blocks {
+		n := utf8.RuneCountInString(b) + 2
+		if n > room {
+			b = truncateRunes(b, room-3) + "…"
+			n = room
+		}
+		if size+n > room && size > 0 {
+			pages = append(pages, header+strings.TrimRight(page.String(), "\n"))
+			page.Reset()
+			size = 0
+		}
+		page.WriteString(b + "\n\n")
+		size += n
+	}
+	if size > 0 {
+		pages = append(pages, header+strings.TrimRight(page.String(), "\n"))
+	}
+	return pages
+}
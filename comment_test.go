@@ -0,0 +1,87 @@
+package danger_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+)
+
+func violations(n, size int) []danger.Violation {
+	vs := make([]danger.Violation, n)
+	for i := range vs {
+		vs[i] = danger.Violation{Message: fmt.Sprintf("%03d", i) + strings.Repeat("x", size)}
+	}
+	return vs
+}
+
+func TestFitCommentUnderLimit(t *testing.T) {
+	r := danger.Results{Fails: violations(2, 10), Warnings: violations(3, 10)}
+	got, pages := danger.FitComment(r, danger.CommentLimit{Max: danger.GitHubCommentLimit})
+	require.Equal(t, r, got)
+	require.Nil(t, pages)
+}
+
+func TestFitCommentSummarizes(t *testing.T) {
+	r := danger.Results{
+		Fails:     violations(2, 100),
+		Warnings:  violations(100, 100),
+		Messages:  violations(10, 100),
+		Markdowns: violations(1, 100),
+	}
+	got, pages := danger.FitComment(r, danger.CommentLimit{Max: 5000, ReportURL: "https://example.com/report"})
+	require.Nil(t, pages)
+	require.Equal(t, r.Fails, got.Fails)
+	require.Less(t, len(got.Warnings), 100)
+	require.Empty(t, got.Messages)
+	require.LessOrEqual(t, danger.EstimateCommentLength(got), 5000)
+
+	left := 100 - len(got.Warnings)
+	require.Equal(t, fmt.Sprintf("> [!NOTE]\n> %d warnings, 10 messages and 1 markdown section did not fit in this comment. "+
+		"See the [full report](https://example.com/report).", left), got.Markdowns[0].Message)
+}
+
+func TestFitCommentKeepsChecklist(t *testing.T) {
+	checklist := danger.Violation{Message: "#### Checklist\n\n- [x] Reviewed " + danger.ChecklistMarker + "reviewed -->"}
+	r := danger.Results{
+		Warnings:  violations(100, 100),
+		Markdowns: append(violations(3, 1000), checklist),
+	}
+	got, _ := danger.FitComment(r, danger.CommentLimit{Max: 5000})
+	require.Equal(t, checklist, got.Markdowns[len(got.Markdowns)-1])
+	require.LessOrEqual(t, danger.EstimateCommentLength(got), 5000)
+}
+
+func TestFitCommentKeepsFailing(t *testing.T) {
+	r := danger.Results{Fails: violations(3, 5000)}
+	got, _ := danger.FitComment(r, danger.CommentLimit{Max: 2500})
+	require.Equal(t, []danger.Violation{{Message: "3 failures did not fit in this comment."}}, got.Fails)
+
+	// a single long failure is truncated instead
+	got, _ = danger.FitComment(r, danger.CommentLimit{Max: 6000})
+	require.Len(t, got.Fails, 1)
+	require.True(t, strings.HasPrefix(got.Fails[0].Message, "000xxx"))
+	require.True(t, strings.HasSuffix(got.Fails[0].Message, "…"))
+	require.LessOrEqual(t, danger.EstimateCommentLength(got), 6000)
+}
+
+func TestFitCommentPaginates(t *testing.T) {
+	r := danger.Results{Warnings: violations(100, 100)}
+	r.Warnings[99].File = "main.go"
+	r.Warnings[99].Line = 12
+	got, pages := danger.FitComment(r, danger.CommentLimit{Max: 5000, Paginate: true})
+	require.Len(t, pages, 2)
+	require.Contains(t, got.Markdowns[0].Message, "They are listed in the next 2 comments.")
+
+	listed := len(got.Warnings)
+	for _, p := range pages {
+		require.LessOrEqual(t, len([]rune(p)), 5000)
+		require.True(t, strings.HasPrefix(p, "### Danger (continued)\n\n"))
+		listed += strings.Count(p, "- ⚠️ ")
+	}
+	require.Equal(t, 100, listed)
+	require.True(t, strings.HasSuffix(pages[1], "(`main.go:12`)"))
+}
@@ -0,0 +1,105 @@
+package dangerJs
+
+import "strings"
+
+// KnownBots are the account names of common automation which opens PRs or
+// pushes commits, compared without a "[bot]" suffix.
+var KnownBots = []string{
+	"dependabot",
+	"dependabot-preview",
+	"renovate",
+	"renovate-bot",
+	"github-actions",
+	"greenkeeper",
+	"snyk-bot",
+	"mergify",
+	"pre-commit-ci",
+	"imgbot",
+}
+
+// IsBotName reports whether an account name or git author name belongs to a
+// bot: either a GitHub App ("name[bot]") or one of KnownBots.
+func IsBotName(name string) bool {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if strings.HasSuffix(name, "[bot]") {
+		return true
+	}
+	for _, b := range KnownBots {
+		if name == b {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBot reports whether the account is a bot.
+func (u GitHubUser) IsBot() bool {
+	return u.Type == "Bot" || IsBotName(u.Login)
+}
+
+// IsBot reports whether the account is a bot, such as a project access token
+// user or a known automation account.
+func (u GitLabUser) IsBot() bool {
+	return u.Bot || IsBotName(u.Username)
+}
+
+// IsBot reports whether the git identity belongs to a bot, judged by its name
+// or its GitHub noreply address, e.g. 49699333+dependabot[bot]@users.noreply.github.com.
+func (a GitCommitAuthor) IsBot() bool {
+	if IsBotName(a.Name) {
+		return true
+	}
+	local, _, _ := strings.Cut(strings.ToLower(a.Email), "@")
+	_, login, _ := strings.Cut(local, "+")
+	return strings.HasSuffix(login, "[bot]")
+}
+
+// CoAuthors returns the identities from the `Co-authored-by` trailers of a
+// commit message.
+func CoAuthors(message string) []GitCommitAuthor {
+	var authors []GitCommitAuthor
+	for _, v := range TrailerValues(message, "Co-authored-by") {
+		authors = append(authors, ParseIdentity(v))
+	}
+	return authors
+}
+
+// CoAuthors returns the identities from the commit's `Co-authored-by` trailers.
+func (c GitCommit) CoAuthors() []GitCommitAuthor {
+	return CoAuthors(c.Message)
+}
+
+// IsBot reports whether the commit was authored by a bot.
+func (c GitHubCommit) IsBot() bool {
+	return c.Author.IsBot() || c.Commit.Author.IsBot()
+}
+
+// CoAuthors returns the identities from the commit's `Co-authored-by` trailers.
+func (c GitLabMRCommit) CoAuthors() []GitCommitAuthor {
+	return CoAuthors(c.Message)
+}
+
+// IsBot reports whether the commit was authored by a bot.
+func (c GitLabMRCommit) IsBot() bool {
+	return c.Author().IsBot()
+}
+
+// IsBotPR reports whether the PR was opened by a bot. Without platform data
+// it reports whether every commit was authored by one.
+func (d DSL) IsBotPR() bool {
+	if d.GitHub != nil && d.GitHub.PR().User.Login != "" {
+		return d.GitHub.PR().User.IsBot()
+	}
+	if d.GitLab != nil && d.GitLab.MR().Author.Username != "" {
+		return d.GitLab.MR().Author.IsBot()
+	}
+	if d.Git == nil || len(d.Git.Commits()) == 0 {
+		return false
+	}
+	for _, c := range d.Git.Commits() {
+		if !c.Author.IsBot() {
+			return false
+		}
+	}
+	return true
+}
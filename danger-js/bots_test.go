@@ -0,0 +1,81 @@
+package dangerJs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBotName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"dependabot[bot]":     true,
+		"renovate[bot]":       true,
+		"Renovate":            true,
+		"github-actions[bot]": true,
+		"my-app[bot]":         true,
+		"octocat":             false,
+		"robot":               false,
+	} {
+		require.Equal(t, want, IsBotName(name), name)
+	}
+}
+
+func TestGitCommitAuthorIsBot(t *testing.T) {
+	require.True(t, GitCommitAuthor{Name: "x", Email: "49699333+dependabot[bot]@users.noreply.github.com"}.IsBot())
+	require.True(t, GitCommitAuthor{Name: "renovate[bot]", Email: "bot@renovateapp.com"}.IsBot())
+	require.False(t, GitCommitAuthor{Name: "Mona", Email: "583231+octocat@users.noreply.github.com"}.IsBot())
+}
+
+func TestCoAuthors(t *testing.T) {
+	c := GitCommit{Message: "Pair on fix\n\nCo-authored-by: Bob <bob@example.com>\nco-authored-by: Carol <carol@example.com>"}
+	require.Equal(t, []GitCommitAuthor{
+		{Name: "Bob", Email: "bob@example.com"},
+		{Name: "Carol", Email: "carol@example.com"},
+	}, c.CoAuthors())
+	require.Empty(t, GitCommit{Message: "Solo"}.CoAuthors())
+}
+
+func TestIsBotPR(t *testing.T) {
+	tests := []struct {
+		name string
+		dsl  DSL
+		want bool
+	}{
+		{
+			name: "github bot account",
+			dsl:  DSL{GitHub: gitHubImpl{PRData: GitHubPR{User: GitHubUser{Login: "renovate[bot]", Type: "Bot"}}}},
+			want: true,
+		},
+		{
+			name: "github human",
+			dsl: DSL{
+				GitHub: gitHubImpl{PRData: GitHubPR{User: GitHubUser{Login: "octocat", Type: "User"}}},
+				Git:    gitImpl{CommitsList: []GitCommit{{Author: GitCommitAuthor{Name: "dependabot[bot]"}}}},
+			},
+			want: false,
+		},
+		{
+			name: "gitlab bot",
+			dsl: DSL{
+				GitHub: gitHubImpl{},
+				GitLab: gitLabImpl{MRData: GitLabMR{GitLabMRBase: GitLabMRBase{Author: GitLabUser{Username: "project_1_bot", Bot: true}}}},
+			},
+			want: true,
+		},
+		{
+			name: "local commits all from bots",
+			dsl:  DSL{Git: gitImpl{CommitsList: []GitCommit{{Author: GitCommitAuthor{Name: "renovate[bot]"}}}}},
+			want: true,
+		},
+		{
+			name: "no data",
+			dsl:  DSL{Git: gitImpl{}},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.dsl.IsBotPR())
+		})
+	}
+}
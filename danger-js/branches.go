@@ -0,0 +1,75 @@
+package dangerJs
+
+import (
+	"regexp"
+	"strings"
+)
+
+// HeadBranch returns the branch the PR or MR merges from, empty outside of
+// GitHub and GitLab.
+func (d DSL) HeadBranch() string {
+	if d.GitHub != nil && d.GitHub.PR().Head.Ref != "" {
+		return d.GitHub.PR().Head.Ref
+	}
+	if d.GitLab != nil {
+		return d.GitLab.MR().SourceBranch
+	}
+	return ""
+}
+
+// BaseBranch returns the branch the PR or MR merges into, empty outside of
+// GitHub and GitLab.
+func (d DSL) BaseBranch() string {
+	if d.GitHub != nil && d.GitHub.PR().Base.Ref != "" {
+		return d.GitHub.PR().Base.Ref
+	}
+	if d.GitLab != nil {
+		return d.GitLab.MR().TargetBranch
+	}
+	return ""
+}
+
+// BranchName is a branch name split into its conventional components, e.g.
+// "feature/ABC-123-login" has the type "feature", the ticket "ABC-123" and
+// the description "login".
+type BranchName struct {
+	Name string
+	// Type is the segment before the first slash, lower-cased.
+	Type string
+	// Ticket is an issue key such as "ABC-123", upper-cased, or an issue
+	// number such as "123" leading the rest of the name.
+	Ticket      string
+	Description string
+}
+
+var (
+	ticketKeyRe    = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9]*-[0-9]+)(?:[-_/]+|$)`)
+	ticketNumberRe = regexp.MustCompile(`^#?([0-9]+)(?:[-_/]+|$)`)
+)
+
+// ParseBranchName splits a branch name into its type, ticket and
+// description. A leading issue key without a type, e.g. "ABC-123-login",
+// is parsed as the ticket.
+func ParseBranchName(name string) BranchName {
+	name = strings.TrimPrefix(name, "refs/heads/")
+	b := BranchName{Name: name}
+	rest := name
+	if typ, after, ok := strings.Cut(name, "/"); ok && !ticketKeyRe.MatchString(name) {
+		b.Type = strings.ToLower(typ)
+		rest = after
+	}
+	for _, re := range []*regexp.Regexp{ticketKeyRe, ticketNumberRe} {
+		if m := re.FindStringSubmatch(rest); m != nil {
+			b.Ticket = strings.ToUpper(m[1])
+			rest = rest[len(m[0]):]
+			break
+		}
+	}
+	b.Description = rest
+	return b
+}
+
+// HeadBranchName returns the parsed head branch of the PR or MR.
+func (d DSL) HeadBranchName() BranchName {
+	return ParseBranchName(d.HeadBranch())
+}
@@ -0,0 +1,28 @@
+package dangerJs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBranchName(t *testing.T) {
+	tests := []struct {
+		name string
+		want BranchName
+	}{
+		{"feature/ABC-123-login-form", BranchName{Name: "feature/ABC-123-login-form", Type: "feature", Ticket: "ABC-123", Description: "login-form"}},
+		{"Fix/abc-7_crash", BranchName{Name: "Fix/abc-7_crash", Type: "fix", Ticket: "ABC-7", Description: "crash"}},
+		{"fix/42-null-pointer", BranchName{Name: "fix/42-null-pointer", Type: "fix", Ticket: "42", Description: "null-pointer"}},
+		{"ABC-123-login", BranchName{Name: "ABC-123-login", Ticket: "ABC-123", Description: "login"}},
+		{"ABC-123/login", BranchName{Name: "ABC-123/login", Ticket: "ABC-123", Description: "login"}},
+		{"refs/heads/chore/deps", BranchName{Name: "chore/deps", Type: "chore", Description: "deps"}},
+		{"release/1.2", BranchName{Name: "release/1.2", Type: "release", Description: "1.2"}},
+		{"main", BranchName{Name: "main", Description: "main"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, ParseBranchName(tt.name))
+		})
+	}
+}
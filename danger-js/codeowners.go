@@ -0,0 +1,102 @@
+package dangerJs
+
+import (
+	"strings"
+)
+
+// CodeOwnersPaths are the locations GitHub and GitLab read CODEOWNERS from,
+// in order of precedence.
+var CodeOwnersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+// CodeOwners maps paths to their owners as declared in a CODEOWNERS file.
+type CodeOwners struct {
+	rules []codeOwnersRule
+}
+
+type codeOwnersRule struct {
+	pattern string
+	owners  []string
+}
+
+// ParseCodeOwners parses the contents of a CODEOWNERS file. GitLab section
+// headers are ignored, so sections are treated as a single list of rules.
+func ParseCodeOwners(content string) CodeOwners {
+	var c CodeOwners
+	for _, line := range strings.Split(normalizeLineEndings(content), "\n") {
+		if i := strings.Index(line, "#"); i >= 0 && (i == 0 || line[i-1] != '\\') {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "[") || strings.HasPrefix(fields[0], "^[") {
+			continue
+		}
+		rule := codeOwnersRule{pattern: strings.ReplaceAll(fields[0], `\#`, "#")}
+		if len(fields) > 1 {
+			rule.owners = fields[1:]
+		}
+		c.rules = append(c.rules, rule)
+	}
+	return c
+}
+
+// LoadCodeOwners reads the CODEOWNERS file at ref from the first of
+// CodeOwnersPaths which exists. ok is false when there is none.
+func LoadCodeOwners(git Git, ref string) (owners CodeOwners, ok bool) {
+	for _, p := range CodeOwnersPaths {
+		content, err := FileAtRef(git, p, ref)
+		if err == nil && content != "" {
+			return ParseCodeOwners(content), true
+		}
+	}
+	return CodeOwners{}, false
+}
+
+// Owners returns the owners of file. The last matching rule wins, as on
+// GitHub, and a rule without owners unsets the ownership.
+func (c CodeOwners) Owners(file string) []string {
+	file = strings.TrimPrefix(NormalizePath(file), "/")
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if codeOwnersMatch(c.rules[i].pattern, file) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// codeOwnersMatch applies the gitignore style matching of CODEOWNERS: a
+// pattern containing a slash other than a trailing one is relative to the
+// root, other patterns match at any depth, and a pattern matching a
+// directory matches everything below it, except that "dir/*" only matches
+// the direct children of dir.
+func codeOwnersMatch(pattern, file string) bool {
+	if pattern == "*" {
+		return true
+	}
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	if strings.HasSuffix(pattern, "/*") {
+		return MatchPath(pattern, file)
+	}
+	if !dirOnly && MatchPath(pattern, file) {
+		return true
+	}
+	for dir := parentDir(file); dir != ""; dir = parentDir(dir) {
+		if MatchPath(pattern, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func parentDir(p string) string {
+	i := strings.LastIndex(p, "/")
+	if i < 0 {
+		return ""
+	}
+	return p[:i]
+}
@@ -0,0 +1,50 @@
+package dangerJs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeOwners(t *testing.T) {
+	owners := ParseCodeOwners(`# Default owners
+*       @org/core
+
+*.js    @js-owner # inline comment
+/build/logs/ @doctocat
+docs/*  docs@example.com
+apps/   @octocat
+/scripts/**/deploy.sh @org/ops
+
+[Docs][2]
+/docs/internal/ @org/docs
+/vendor/
+`)
+
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"main.go", []string{"@org/core"}},
+		{"web/app.js", []string{"@js-owner"}},
+		{"build/logs/a/b.log", []string{"@doctocat"}},
+		{"src/build/logs/x.log", []string{"@org/core"}},
+		{"docs/getting-started.md", []string{"docs@example.com"}},
+		{"docs/build-app/troubleshooting.md", []string{"@org/core"}},
+		{"docs/internal/x.md", []string{"@org/docs"}},
+		{"apps/web/main.go", []string{"@octocat"}},
+		{"src/apps/main.go", []string{"@octocat"}},
+		{"scripts/a/b/deploy.sh", []string{"@org/ops"}},
+		{`apps\win\main.go`, []string{"@octocat"}},
+		{"vendor/lib/lib.go", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			require.Equal(t, tt.want, owners.Owners(tt.file))
+		})
+	}
+}
+
+func TestCodeOwnersEmpty(t *testing.T) {
+	require.Nil(t, ParseCodeOwners("").Owners("main.go"))
+}
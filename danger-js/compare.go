@@ -0,0 +1,127 @@
+package dangerJs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// GitHubComparison is the comparison of two commits by the GitHub compare
+// API, from their merge base to the head.
+type GitHubComparison struct {
+	// Status is "ahead", "behind", "diverged" or "identical".
+	Status          string         `json:"status"`
+	AheadBy         int            `json:"ahead_by"`
+	BehindBy        int            `json:"behind_by"`
+	TotalCommits    int            `json:"total_commits"`
+	MergeBaseCommit GitHubCommit   `json:"merge_base_commit"`
+	Commits         []GitHubCommit `json:"commits"`
+	// Files lists at most 300 files.
+	Files []GitHubPRFile `json:"files"`
+}
+
+// DiffForFile returns the diff of a file of the comparison, parsed as by
+// Git.DiffForFile.
+func (c GitHubComparison) DiffForFile(filePath string) (FileDiff, error) {
+	for _, f := range c.Files {
+		if f.Filename != filePath {
+			continue
+		}
+		if f.Patch == "" && f.Changes > 0 {
+			return FileDiff{}, fmt.Errorf("GitHub has no patch for %s, it is binary or too large", filePath)
+		}
+		return f.Diff(), nil
+	}
+	return FileDiff{}, nil
+}
+
+// Diff parses the patch of the file.
+func (f GitHubPRFile) Diff() FileDiff {
+	return parsePatch(f.Patch)
+}
+
+// ParsePatch parses the diff of a single file with context lines, such as
+// the diffs of the GitHub and GitLab APIs.
+func ParsePatch(patch string) FileDiff {
+	return parsePatch(patch)
+}
+
+// GitHubComparer compares commits with the GitHub API. githubclient.Client
+// implements it.
+type GitHubComparer interface {
+	Compare(ctx context.Context, owner, repo, base, head string) (GitHubComparison, error)
+}
+
+// ErrNoComparer is returned by Compare when no GitHubComparer is
+// configured, see WithGitHubFetcher.
+var ErrNoComparer = errors.New("comparing commits requires a GitHub API client")
+
+// Compare compares base and head, branch names or commit SHAs, in the
+// repository of the PR with the GitHub API. It needs a GitHubFetcher which
+// is also a GitHubComparer.
+func (g gitHubImpl) Compare(ctx context.Context, base, head string) (GitHubComparison, error) {
+	c, ok := g.fetcher.(GitHubComparer)
+	if !ok {
+		return GitHubComparison{}, ErrNoComparer
+	}
+	return c.Compare(ctx, g.ThisPRData.Owner, g.ThisPRData.Repo, base, head)
+}
+
+// historyUnavailable reports whether err means the local clone lacks the
+// history, so the API should be asked instead.
+func historyUnavailable(err error) bool {
+	return errors.Is(err, ErrRefNotFound) || errors.Is(err, ErrNoGitRepo)
+}
+
+// apiRef returns the ref GitHub knows for a local ref: HEAD is the PR head
+// and origin/<branch> the branch.
+func (d DSL) apiRef(ref string) string {
+	if ref == "HEAD" && d.GitHub != nil && d.GitHub.PR().Head.SHA != "" {
+		return d.GitHub.PR().Head.SHA
+	}
+	return strings.TrimPrefix(ref, "origin/")
+}
+
+// DiffForFileWithRefs returns the diff of a file between baseRef and
+// headRef from git or, when the clone lacks their history as in shallow CI
+// checkouts, from the GitHub compare API. The API diffs from the merge base,
+// as GitHub shows the PR.
+func (d DSL) DiffForFileWithRefs(ctx context.Context, filePath, baseRef, headRef string) (FileDiff, error) {
+	var err error
+	if d.Git != nil {
+		var diff FileDiff
+		if diff, err = d.Git.DiffForFileWithRefs(filePath, baseRef, headRef); err == nil || !historyUnavailable(err) {
+			return diff, err
+		}
+	}
+	if d.GitHub == nil {
+		return FileDiff{}, err
+	}
+	c, cerr := Compare(ctx, d.GitHub, d.apiRef(baseRef), d.apiRef(headRef))
+	if cerr != nil {
+		return FileDiff{}, errors.Join(err, cerr)
+	}
+	return c.DiffForFile(filePath)
+}
+
+// CommitsBehind returns how many commits baseRef has advanced since headRef
+// branched off it, counted by git or, without the history, by the GitHub
+// compare API.
+func (d DSL) CommitsBehind(ctx context.Context, baseRef, headRef string) (int, error) {
+	var err error
+	if d.Git != nil {
+		var n int
+		if n, err = CommitsBehind(d.Git, baseRef, headRef); err == nil || !historyUnavailable(err) {
+			return n, err
+		}
+	}
+	if d.GitHub == nil {
+		return 0, err
+	}
+	c, cerr := Compare(ctx, d.GitHub, d.apiRef(baseRef), d.apiRef(headRef))
+	if cerr != nil {
+		return 0, errors.Join(err, cerr)
+	}
+	return c.BehindBy, nil
+}
@@ -0,0 +1,111 @@
+package dangerJs
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const comparePatch = `@@ -1,3 +1,4 @@
+ package main
+ 
++import "fmt"
+ func main() {
+@@ -10,6 +11,7 @@ func main() {
+ 	if true {
+-		fmt.Println("old")
++		fmt.Println("new")
++		fmt.Println("extra")
+ 	}
+ }`
+
+type fakeComparer struct {
+	fakeFetcher
+	base, head string
+}
+
+func (f *fakeComparer) Compare(_ context.Context, owner, repo, base, head string) (GitHubComparison, error) {
+	f.base, f.head = base, head
+	return GitHubComparison{
+		Status:   "diverged",
+		AheadBy:  2,
+		BehindBy: 7,
+		Files:    []GitHubPRFile{{Filename: "main.go", Status: "modified", Changes: 4, Patch: comparePatch}, {Filename: "logo.png", Changes: 1}},
+	}, nil
+}
+
+// historyGit is a Git whose diffs fail with err.
+type historyGit struct {
+	Git
+	err error
+}
+
+func (g historyGit) DiffForFileWithRefs(string, string, string) (FileDiff, error) {
+	return FileDiff{AddedLines: []DiffLine{{Content: "local", Line: 1}}}, g.err
+}
+
+func (g historyGit) CommitsBehind(string, string) (int, error) {
+	return 3, g.err
+}
+
+func TestGitHubPRFileDiff(t *testing.T) {
+	require.Equal(t, FileDiff{
+		AddedLines: []DiffLine{
+			{Content: `import "fmt"`, Line: 3, Hunk: 1, Position: 3},
+			{Content: "\t\tfmt.Println(\"new\")", Line: 12, Hunk: 2, Position: 8},
+			{Content: "\t\tfmt.Println(\"extra\")", Line: 13, Hunk: 2, Position: 9},
+		},
+		RemovedLines: []DiffLine{{Content: "\t\tfmt.Println(\"old\")", Line: 11, Hunk: 2, Position: 7}},
+	}, GitHubPRFile{Patch: comparePatch}.Diff())
+}
+
+func TestDiffForFileWithRefs(t *testing.T) {
+	shallow := fmt.Errorf("%w: %w", ErrShallowClone, ErrRefNotFound)
+	tests := []struct {
+		name     string
+		git      Git
+		file     string
+		want     FileDiff
+		behind   int
+		compared bool
+		wantErr  string
+	}{
+		{name: "local history", git: historyGit{}, file: "main.go", want: FileDiff{AddedLines: []DiffLine{{Content: "local", Line: 1}}}, behind: 3},
+		{name: "shallow clone", git: historyGit{err: shallow}, file: "main.go", want: GitHubPRFile{Patch: comparePatch}.Diff(), behind: 7, compared: true},
+		{name: "no git", file: "main.go", want: GitHubPRFile{Patch: comparePatch}.Diff(), behind: 7, compared: true},
+		{name: "file not changed", git: historyGit{err: shallow}, behind: 7, compared: true, file: "other.go"},
+		{name: "binary file", git: historyGit{err: shallow}, behind: 7, compared: true, file: "logo.png", wantErr: "GitHub has no patch for logo.png, it is binary or too large"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &fakeComparer{}
+			var pr GitHubPR
+			pr.Head.SHA = "abc123"
+			d := DSL{Git: tt.git, GitHub: gitHubImpl{PRData: pr, ThisPRData: GitHubAPIPR{Owner: "o", Repo: "r", Number: 1}, fetcher: c}}
+
+			diff, err := d.DiffForFileWithRefs(context.Background(), tt.file, "origin/main", "HEAD")
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.want, diff)
+			}
+			behind, err := d.CommitsBehind(context.Background(), "origin/main", "HEAD")
+			require.NoError(t, err)
+			require.Equal(t, tt.behind, behind)
+			if tt.compared {
+				require.Equal(t, "main", c.base)
+				require.Equal(t, "abc123", c.head)
+			}
+		})
+	}
+}
+
+func TestCompareWithoutComparer(t *testing.T) {
+	d := DSL{Git: historyGit{err: ErrRefNotFound}, GitHub: gitHubImpl{fetcher: &fakeFetcher{}}}
+	_, err := d.CommitsBehind(context.Background(), "origin/main", "HEAD")
+	require.ErrorIs(t, err, ErrRefNotFound)
+	require.ErrorIs(t, err, ErrNoComparer)
+}
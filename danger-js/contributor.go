@@ -0,0 +1,22 @@
+package dangerJs
+
+// FirstTimeContributor reports whether the PR is the first contribution of
+// its author to the repository, from the GitHub author association or the
+// GitLab first_contribution flag. known is false when the platform doesn't
+// tell, e.g. for a GitHub author association of NONE, and outside of GitHub
+// and GitLab.
+func (d DSL) FirstTimeContributor() (first, known bool) {
+	if d.GitHub != nil && d.GitHub.PR().Number != 0 {
+		switch d.GitHub.PR().AuthorAssociation {
+		case "FIRST_TIMER", "FIRST_TIME_CONTRIBUTOR":
+			return true, true
+		case "OWNER", "MEMBER", "COLLABORATOR", "CONTRIBUTOR":
+			return false, true
+		}
+		return false, false
+	}
+	if d.GitLab != nil && d.GitLab.MR().IID != 0 {
+		return d.GitLab.MR().FirstContribution, true
+	}
+	return false, false
+}
@@ -0,0 +1,35 @@
+package dangerJs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirstTimeContributor(t *testing.T) {
+	gitLab := func(first bool) gitLabImpl {
+		var mr GitLabMR
+		mr.IID = 1
+		mr.FirstContribution = first
+		return gitLabImpl{MRData: mr}
+	}
+	tests := []struct {
+		name         string
+		data         DSLData
+		first, known bool
+	}{
+		{name: "GitHub first timer", data: DSLData{GitHub: gitHubImpl{PRData: GitHubPR{Number: 1, AuthorAssociation: "FIRST_TIME_CONTRIBUTOR"}}}, first: true, known: true},
+		{name: "GitHub member", data: DSLData{GitHub: gitHubImpl{PRData: GitHubPR{Number: 1, AuthorAssociation: "MEMBER"}}}, known: true},
+		{name: "GitHub none", data: DSLData{GitHub: gitHubImpl{PRData: GitHubPR{Number: 1, AuthorAssociation: "NONE"}}}},
+		{name: "GitLab first contribution", data: DSLData{GitLab: gitLab(true)}, first: true, known: true},
+		{name: "GitLab", data: DSLData{GitLab: gitLab(false)}, known: true},
+		{name: "no platform"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, known := tt.data.ToInterface().FirstTimeContributor()
+			require.Equal(t, tt.first, first)
+			require.Equal(t, tt.known, known)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package dangerJs
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ConventionalCommit is a message following https://www.conventionalcommits.org,
+// e.g. "feat(api)!: remove v1 endpoints".
+type ConventionalCommit struct {
+	Type        string
+	Scope       string
+	Breaking    bool
+	Description string
+	Body        string
+}
+
+var conventionalRe = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^)]*)\))?(!)?:\s+(.+)$`)
+
+// ParseConventionalCommit parses a commit message or PR title. The second
+// return value is false when the subject line isn't a conventional commit. A
+// `BREAKING CHANGE` trailer marks the commit as breaking.
+func ParseConventionalCommit(message string) (ConventionalCommit, bool) {
+	message = strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n"))
+	subject, body, _ := strings.Cut(message, "\n")
+	m := conventionalRe.FindStringSubmatch(strings.TrimSpace(subject))
+	if m == nil {
+		return ConventionalCommit{}, false
+	}
+	c := ConventionalCommit{
+		Type:        strings.ToLower(m[1]),
+		Scope:       m[2],
+		Breaking:    m[3] == "!",
+		Description: strings.TrimSpace(m[4]),
+		Body:        strings.TrimSpace(body),
+	}
+	for _, t := range ParseTrailers(message) {
+		if t.Key == "BREAKING-CHANGE" {
+			c.Breaking = true
+		}
+	}
+	if strings.HasPrefix(c.Body, "BREAKING CHANGE:") || strings.Contains(c.Body, "\nBREAKING CHANGE:") {
+		c.Breaking = true
+	}
+	return c, true
+}
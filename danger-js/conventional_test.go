@@ -0,0 +1,37 @@
+package dangerJs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConventionalCommit(t *testing.T) {
+	tests := []struct {
+		message string
+		want    ConventionalCommit
+		ok      bool
+	}{
+		{"feat: add dark mode", ConventionalCommit{Type: "feat", Description: "add dark mode"}, true},
+		{"Fix(api)!: drop v1", ConventionalCommit{Type: "fix", Scope: "api", Breaking: true, Description: "drop v1"}, true},
+		{
+			"refactor(db): rename tables\n\nBREAKING CHANGE: tables are renamed",
+			ConventionalCommit{Type: "refactor", Scope: "db", Breaking: true, Description: "rename tables", Body: "BREAKING CHANGE: tables are renamed"},
+			true,
+		},
+		{
+			"chore: tidy\n\nBREAKING-CHANGE: nothing really",
+			ConventionalCommit{Type: "chore", Breaking: true, Description: "tidy", Body: "BREAKING-CHANGE: nothing really"},
+			true,
+		},
+		{"Update README", ConventionalCommit{}, false},
+		{"feat:missing space", ConventionalCommit{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			got, ok := ParseConventionalCommit(tt.message)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -1,11 +1,12 @@
 package dangerJs
 
 import (
-	"encoding/json"
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
-	"strings"
+	"slices"
 )
 
 const (
@@ -13,39 +14,45 @@ const (
 	dangerGoBinary = "danger-go"
 )
 
+// findBinary looks up name in PATH. Unlike `which`, exec.LookPath also works
+// on Windows, where it resolves extensions from PATHEXT such as danger.cmd.
 func findBinary(name string) (string, error) {
-	cmd := exec.Command("which", name)
-	dangerBin, err := cmd.CombinedOutput()
+	bin, err := exec.LookPath(name)
 	if err != nil {
 		return "", fmt.Errorf("could not find `%s` binary: %w", name, err)
 	}
-	return strings.TrimSpace(string(dangerBin)), nil
+	return bin, nil
 }
 
-func GetPR(url string, dangerBin string) (DSL, error) {
+// GetPR returns the DSL of the PR at url, as written by `danger pr --json`.
+func GetPR(url string, dangerBin string, opts ...DSLOption) (DSL, error) {
 	var err error
 	if dangerBin == "" {
-		dangerBin, err = findBinary(dangerJsBinary)
+		dangerBin, err = dangerJsBin()
 		if err != nil {
 			return DSL{}, err
 		}
 	}
 
 	cmd := exec.Command(dangerBin, "pr", url, "--json")
-	prJSON, err := cmd.CombinedOutput()
+	// only stdout holds the JSON, danger-js logs to stderr
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	prJSON, err := cmd.Output()
 	if err != nil {
-		return DSL{}, fmt.Errorf("could not download DSL JSON with danger-js: %w", err)
+		return DSL{}, fmt.Errorf("could not download DSL JSON with danger-js: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
 	}
 
-	var prData DSLData
-	if err = json.Unmarshal(prJSON, &prData); err != nil {
+	// the DSL is under the "danger" key of the document
+	prData, err := DecodeDSLStream(bytes.NewReader(prJSON), int64(len(prJSON)))
+	if err != nil {
 		return DSL{}, err
 	}
-	return prData.ToInterface(), nil
+	return prData.ToInterface(opts...), nil
 }
 
 func Process(command string, args []string) error {
-	dangerBin, err := findBinary(dangerJsBinary)
+	dangerBin, err := dangerJsBin()
 	if err != nil {
 		return err
 	}
@@ -57,9 +64,24 @@ func Process(command string, args []string) error {
 	// i.e. `danger-go`, with the first argument of `runner` followed by the
 	// arguments it received.
 	cmdArgs := append([]string{command, "--process", dangerGoBin, "--passURLForDSL"}, args...)
+	safeMode := command == "ci" && SafeMode()
+	if safeMode {
+		// the token can't post the comment, report through the runner only
+		fmt.Println("Fork PR with a read-only token: running in safe mode, results are reported as annotations and in the job summary")
+		if !slices.Contains(args, "--text-only") {
+			cmdArgs = append(cmdArgs, "--text-only")
+		}
+	}
 	cmd := exec.Command(dangerBin, cmdArgs...)
 	fmt.Printf("Running: %s\n", cmd)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if safeMode && errors.As(err, &exitErr) {
+		if code, ok := safeModeExitCode(); ok {
+			return ExitError{Code: code}
+		}
+	}
+	return err
 }
@@ -0,0 +1,31 @@
+package dangerJs
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPR(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fakes danger-js with a shell script")
+	}
+	bin := filepath.Join(t.TempDir(), "danger")
+	script := "#!/bin/sh\n" +
+		"echo 'Starting Danger PR on danger/golang#4' >&2\n" +
+		`echo '{"danger":{"git":{"modified_files":["a.go"]},"github":{"pr":{"number":4}}}}'` + "\n"
+	require.Nil(t, os.WriteFile(bin, []byte(script), 0o755))
+
+	pr, err := GetPR("https://github.com/danger/golang/pull/4", bin)
+	require.Nil(t, err)
+	require.Equal(t, []string{"a.go"}, pr.Git.ModifiedFiles())
+	require.Equal(t, 4, pr.GitHub.PR().Number)
+
+	failing := filepath.Join(t.TempDir(), "danger")
+	require.Nil(t, os.WriteFile(failing, []byte("#!/bin/sh\necho 'PR not found' >&2\nexit 1\n"), 0o755))
+	_, err = GetPR("https://github.com/danger/golang/pull/5", failing)
+	require.ErrorContains(t, err, "PR not found")
+}
@@ -0,0 +1,168 @@
+package dangerJs
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// VersionBump is the size of a version change following semantic versioning.
+type VersionBump int
+
+const (
+	// BumpUnknown means a version could not be parsed, e.g. a digest pin.
+	BumpUnknown VersionBump = iota
+	BumpPatch
+	BumpMinor
+	BumpMajor
+)
+
+func (b VersionBump) String() string {
+	switch b {
+	case BumpPatch:
+		return "patch"
+	case BumpMinor:
+		return "minor"
+	case BumpMajor:
+		return "major"
+	default:
+		return "unknown"
+	}
+}
+
+// DependencyChange is a single dependency changed by an update PR. From is
+// empty when the PR doesn't mention the previous version.
+type DependencyChange struct {
+	Name string
+	From string
+	To   string
+}
+
+// Bump returns the size of the version change.
+func (c DependencyChange) Bump() VersionBump {
+	from, ok := parseVersion(c.From)
+	if !ok {
+		return BumpUnknown
+	}
+	to, ok := parseVersion(c.To)
+	if !ok {
+		return BumpUnknown
+	}
+	switch {
+	case from[0] != to[0]:
+		return BumpMajor
+	case from[1] != to[1]:
+		return BumpMinor
+	default:
+		return BumpPatch
+	}
+}
+
+// DependencyUpdate describes a PR opened by a dependency update bot.
+type DependencyUpdate struct {
+	Tool    string // "dependabot" | "renovate"
+	Changes []DependencyChange
+}
+
+// Bump returns the largest version change of the update, or BumpUnknown when
+// any change has an unknown size.
+func (u DependencyUpdate) Bump() VersionBump {
+	if len(u.Changes) == 0 {
+		return BumpUnknown
+	}
+	biggest := BumpPatch
+	for _, c := range u.Changes {
+		b := c.Bump()
+		if b == BumpUnknown {
+			return BumpUnknown
+		}
+		if b > biggest {
+			biggest = b
+		}
+	}
+	return biggest
+}
+
+var (
+	// Bump github.com/foo/bar from 1.2.3 to 1.3.0 (in /dir)
+	dependabotTitleRe = regexp.MustCompile(`(?i)\bbump (\S+) from (\S+) to (\S+)`)
+	// Updates `foo` from 1.2.3 to 1.3.0, in grouped dependabot PR bodies
+	dependabotBodyRe = regexp.MustCompile("(?im)^\\s*updates? `([^`]+)` from (\\S+) to (\\S+?)\\.?\\s*$")
+	// | [github.com/foo/bar](https://...) | `v1.2.3` -> `v1.3.0` | in renovate PR bodies
+	renovateTableRe = regexp.MustCompile("(?m)^\\|\\s*(?:\\[([^\\]]+)\\]\\([^)]*\\)|([^|\\s]+))\\s*\\|.*?`([^`]+)`\\s*(?:->|→)\\s*`([^`]+)`")
+	// Update module github.com/foo/bar to v1.3.0
+	renovateTitleRe = regexp.MustCompile(`(?i)\bupdate (?:module |dependency )?(\S+) to (\S+)`)
+)
+
+// DependencyUpdate reports the dependencies updated by a Dependabot or
+// Renovate PR, parsed from its branch, title and body. The second return
+// value is false for other PRs.
+func (d DSL) DependencyUpdate() (DependencyUpdate, bool) {
+	var title, body, branch string
+	switch {
+	case d.GitHub != nil && d.GitHub.PR().Title != "":
+		pr := d.GitHub.PR()
+		title, body, branch = pr.Title, pr.Body, pr.Head.Ref
+	case d.GitLab != nil && d.GitLab.MR().Title != "":
+		mr := d.GitLab.MR()
+		title, body, branch = mr.Title, mr.Description, mr.SourceBranch
+	default:
+		return DependencyUpdate{}, false
+	}
+	return parseDependencyUpdate(title, body, branch)
+}
+
+func parseDependencyUpdate(title, body, branch string) (DependencyUpdate, bool) {
+	var u DependencyUpdate
+	switch {
+	case strings.HasPrefix(branch, "dependabot/"):
+		u.Tool = "dependabot"
+		for _, m := range dependabotBodyRe.FindAllStringSubmatch(body, -1) {
+			u.Changes = append(u.Changes, DependencyChange{Name: m[1], From: m[2], To: m[3]})
+		}
+		if len(u.Changes) == 0 {
+			if m := dependabotTitleRe.FindStringSubmatch(title); m != nil {
+				u.Changes = append(u.Changes, DependencyChange{Name: m[1], From: m[2], To: m[3]})
+			}
+		}
+	case strings.HasPrefix(branch, "renovate/"):
+		u.Tool = "renovate"
+		for _, m := range renovateTableRe.FindAllStringSubmatch(body, -1) {
+			name := m[1]
+			if name == "" {
+				name = m[2]
+			}
+			u.Changes = append(u.Changes, DependencyChange{Name: name, From: m[3], To: m[4]})
+		}
+		if len(u.Changes) == 0 {
+			if m := renovateTitleRe.FindStringSubmatch(title); m != nil {
+				u.Changes = append(u.Changes, DependencyChange{Name: m[1], To: m[2]})
+			}
+		}
+	default:
+		return DependencyUpdate{}, false
+	}
+	return u, true
+}
+
+// parseVersion extracts major, minor and patch from versions such as
+// "v1.2.3", "^1.2" or "1.2.3-rc.1".
+func parseVersion(v string) ([3]int, bool) {
+	var out [3]int
+	v = strings.TrimLeft(strings.TrimSpace(v), "v^~=<>")
+	if i := strings.IndexAny(v, "-+ "); i >= 0 {
+		v = v[:i]
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return out, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return out, false
+		}
+		out[i] = n
+	}
+	return out, true
+}
@@ -0,0 +1,103 @@
+package dangerJs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDependencyUpdate(t *testing.T) {
+	tests := []struct {
+		name   string
+		title  string
+		body   string
+		branch string
+		want   DependencyUpdate
+		ok     bool
+	}{
+		{
+			name:   "dependabot single",
+			title:  "Bump github.com/stretchr/testify from 1.8.4 to 1.9.0",
+			branch: "dependabot/go_modules/github.com/stretchr/testify-1.9.0",
+			want: DependencyUpdate{Tool: "dependabot", Changes: []DependencyChange{
+				{Name: "github.com/stretchr/testify", From: "1.8.4", To: "1.9.0"},
+			}},
+			ok: true,
+		},
+		{
+			name:  "dependabot group",
+			title: "Bump the npm group across 1 directory with 2 updates",
+			body: "Bumps the npm group with 2 updates:\n\n" +
+				"Updates `lodash` from 4.17.20 to 4.17.21\n" +
+				"- [Release notes](https://github.com/lodash/lodash/releases)\n\n" +
+				"Updates `react` from 17.0.2 to 18.2.0\n",
+			branch: "dependabot/npm_and_yarn/npm-abc123",
+			want: DependencyUpdate{Tool: "dependabot", Changes: []DependencyChange{
+				{Name: "lodash", From: "4.17.20", To: "4.17.21"},
+				{Name: "react", From: "17.0.2", To: "18.2.0"},
+			}},
+			ok: true,
+		},
+		{
+			name:  "renovate table",
+			title: "fix(deps): update module github.com/foo/bar to v1.3.0",
+			body: "| Package | Change | Age |\n|---|---|---|\n" +
+				"| [github.com/foo/bar](https://togithub.com/foo/bar) | `v1.2.3` -> `v1.3.0` | [![age](x)](y) |\n",
+			branch: "renovate/github.com-foo-bar-1.x",
+			want: DependencyUpdate{Tool: "renovate", Changes: []DependencyChange{
+				{Name: "github.com/foo/bar", From: "v1.2.3", To: "v1.3.0"},
+			}},
+			ok: true,
+		},
+		{
+			name:   "renovate title only",
+			title:  "Update dependency eslint to v9",
+			branch: "renovate/eslint-9.x",
+			want: DependencyUpdate{Tool: "renovate", Changes: []DependencyChange{
+				{Name: "eslint", To: "v9"},
+			}},
+			ok: true,
+		},
+		{
+			name:   "not an update",
+			title:  "Bump version from 1 to 2",
+			branch: "feature/bump",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseDependencyUpdate(tt.title, tt.body, tt.branch)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDependencyBump(t *testing.T) {
+	require.Equal(t, BumpPatch, DependencyChange{From: "v1.2.3", To: "v1.2.4"}.Bump())
+	require.Equal(t, BumpMinor, DependencyChange{From: "^1.2", To: "^1.3"}.Bump())
+	require.Equal(t, BumpMajor, DependencyChange{From: "1.9.0", To: "2.0.0-rc.1"}.Bump())
+	require.Equal(t, BumpUnknown, DependencyChange{To: "v9"}.Bump())
+	require.Equal(t, BumpUnknown, DependencyChange{From: "abc123", To: "def456"}.Bump())
+
+	u := DependencyUpdate{Changes: []DependencyChange{
+		{From: "1.0.0", To: "1.0.1"},
+		{From: "1.0.0", To: "1.1.0"},
+	}}
+	require.Equal(t, BumpMinor, u.Bump())
+	require.Equal(t, "minor", u.Bump().String())
+	require.Equal(t, BumpUnknown, DependencyUpdate{}.Bump())
+}
+
+func TestDSLDependencyUpdate(t *testing.T) {
+	dsl := DSL{GitHub: gitHubImpl{PRData: GitHubPR{
+		Title: "Bump golang.org/x/net from 0.20.0 to 0.21.0",
+		Head:  GitHubMergeRef{Ref: "dependabot/go_modules/golang.org/x/net-0.21.0"},
+	}}}
+	u, ok := dsl.DependencyUpdate()
+	require.True(t, ok)
+	require.Equal(t, BumpMinor, u.Bump())
+
+	_, ok = DSL{GitHub: gitHubImpl{}, GitLab: gitLabImpl{}}.DependencyUpdate()
+	require.False(t, ok)
+}
@@ -0,0 +1,205 @@
+package dangerJs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// FileEnvSuffix marks a variable holding the path of a file with the value
+// of the variable without the suffix, e.g. DANGER_GITHUB_API_TOKEN_FILE for a
+// token mounted from a Kubernetes secret.
+const FileEnvSuffix = "_FILE"
+
+// EnvKind is the type of value of a variable, used to validate it.
+type EnvKind int
+
+const (
+	EnvText EnvKind = iota
+	EnvURL
+	EnvBool
+	EnvInt
+)
+
+// EnvVar is a configuration variable read by danger-go or danger-js.
+type EnvVar struct {
+	Name string
+	Kind EnvKind
+	// Secret values are redacted from DumpEnv.
+	Secret bool
+	// Bootstrap variables configure the secrets provider, so they can't be
+	// looked up in it.
+	Bootstrap bool
+}
+
+// EnvVars are the variables configuring danger-go and the danger-js process
+// it runs.
+var EnvVars = []EnvVar{
+	{Name: "DANGER_GITHUB_API_TOKEN", Secret: true},
+	{Name: "DANGER_GITHUB_API_BASE_URL", Kind: EnvURL},
+	{Name: "GITHUB_TOKEN", Secret: true},
+	{Name: "DANGER_GITLAB_API_TOKEN", Secret: true},
+	{Name: "DANGER_GITLAB_API_OAUTH_TOKEN", Secret: true},
+	{Name: "DANGER_GITLAB_HOST"},
+	{Name: "DANGER_GITLAB_STATUS"},
+	{Name: "DANGER_GITLAB_CODE_QUALITY"},
+	{Name: "DANGER_BITBUCKETCLOUD_USERNAME"},
+	{Name: "DANGER_BITBUCKETCLOUD_PASSWORD", Secret: true},
+	{Name: "DANGER_BITBUCKETCLOUD_OAUTH_KEY"},
+	{Name: "DANGER_BITBUCKETCLOUD_OAUTH_SECRET", Secret: true},
+	{Name: "DANGER_BITBUCKETCLOUD_REPO_ACCESSTOKEN", Secret: true},
+	{Name: "DANGER_BITBUCKETSERVER_HOST", Kind: EnvURL},
+	{Name: "DANGER_BITBUCKETSERVER_USERNAME"},
+	{Name: "DANGER_BITBUCKETSERVER_PASSWORD", Secret: true},
+	{Name: "DANGER_BITBUCKETSERVER_TOKEN", Secret: true},
+	{Name: SafeModeEnv, Kind: EnvBool},
+	{Name: SafeModeExitCodeEnv, Kind: EnvInt},
+	{Name: UnshallowEnv, Kind: EnvBool},
+	{Name: ManagedEnv, Kind: EnvBool},
+	{Name: LockFileEnv},
+	{Name: DownloadURLEnv},
+	{Name: "DANGER_RESULTS_FILE"},
+	{Name: "DANGER_DUMP_DSL"},
+	{Name: "DANGER_CONFIG"},
+	{Name: "DANGER_JOB_SUMMARY"},
+	{Name: "DANGER_PROTECTED_PATHS"},
+	{Name: "DANGER_IMPACT_FILE"},
+	{Name: "DANGER_BAZEL_IMPACT"},
+	{Name: "DANGER_ANALYTICS_FILE"},
+	{Name: "DANGER_ANALYTICS_DRIVER"},
+	{Name: "DANGER_ANALYTICS_DSN", Secret: true},
+	{Name: "DANGER_ATTESTATION_FILE"},
+	{Name: "DANGER_ATTESTATION_KEY", Secret: true},
+	{Name: "DANGER_AUTOFIX"},
+	{Name: "DANGER_AUTOFIX_REMOTE", Secret: true},
+	{Name: "DANGER_MESSAGES_FILE"},
+	{Name: "DANGER_STATE"},
+	{Name: "DANGER_STATE_REMOTE", Secret: true},
+	{Name: "DANGER_WAIVERS", Kind: EnvBool},
+	{Name: "DANGER_WAIVER_REVIEWERS"},
+	{Name: "DANGER_METRICS_PUSHGATEWAY_URL", Kind: EnvURL},
+	{Name: "DANGER_METRICS_JOB"},
+	{Name: "OTEL_EXPORTER_OTLP_ENDPOINT", Kind: EnvURL},
+	{Name: "OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", Kind: EnvURL},
+	{Name: "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", Kind: EnvURL},
+	{Name: "OTEL_EXPORTER_OTLP_HEADERS", Secret: true},
+	{Name: "OTEL_SERVICE_NAME"},
+	{Name: "ANTHROPIC_API_KEY", Secret: true},
+	{Name: "OPENAI_API_KEY", Secret: true},
+	{Name: "OPENAI_BASE_URL", Kind: EnvURL},
+	{Name: "DANGER_SECRETS_PROVIDER", Bootstrap: true},
+	{Name: "DANGER_SECRETS_DIR", Bootstrap: true},
+	{Name: "VAULT_ADDR", Kind: EnvURL, Bootstrap: true},
+	{Name: "VAULT_TOKEN", Secret: true, Bootstrap: true},
+	{Name: "DANGER_SECRETS_VAULT_MOUNT", Bootstrap: true},
+	{Name: "DANGER_SECRETS_VAULT_PATH", Bootstrap: true},
+	{Name: "DANGER_SECRETS_AWS_SECRET_ID", Bootstrap: true},
+	{Name: "AWS_REGION", Bootstrap: true},
+	{Name: "AWS_ACCESS_KEY_ID", Bootstrap: true},
+	{Name: "AWS_SECRET_ACCESS_KEY", Secret: true, Bootstrap: true},
+	{Name: "AWS_SESSION_TOKEN", Secret: true, Bootstrap: true},
+	{Name: "DANGER_SECRETS_GCP_PROJECT", Bootstrap: true},
+	{Name: "GOOGLE_OAUTH_ACCESS_TOKEN", Secret: true, Bootstrap: true},
+}
+
+// SecretEnvVars returns the names of the secret variables which can be
+// looked up in a secrets provider.
+func SecretEnvVars() []string {
+	var names []string
+	for _, v := range EnvVars {
+		if v.Secret && !v.Bootstrap {
+			names = append(names, v.Name)
+		}
+	}
+	return names
+}
+
+// ResolveFileEnv sets each variable of EnvVars given as <NAME>_FILE to the
+// content of the file, without surrounding whitespace, so both danger-go and
+// the danger-js process it starts see the value.
+func ResolveFileEnv() error {
+	var errs []error
+	for _, v := range EnvVars {
+		path := os.Getenv(v.Name + FileEnvSuffix)
+		if path == "" {
+			continue
+		}
+		if os.Getenv(v.Name) != "" {
+			errs = append(errs, fmt.Errorf("both %s and %s%s are set", v.Name, v.Name, FileEnvSuffix))
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("reading %s%s: %w", v.Name, FileEnvSuffix, err))
+			continue
+		}
+		if err := os.Setenv(v.Name, strings.TrimSpace(string(data))); err != nil {
+			errs = append(errs, fmt.Errorf("setting %s: %w", v.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateEnv checks the values of the set variables of EnvVars.
+func ValidateEnv() error {
+	var errs []error
+	for _, v := range EnvVars {
+		value := os.Getenv(v.Name)
+		if value == "" {
+			continue
+		}
+		if err := v.validate(value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", v.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (v EnvVar) validate(value string) error {
+	switch v.Kind {
+	case EnvURL:
+		u, err := url.Parse(value)
+		if err != nil {
+			return err
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%q is not an absolute URL", value)
+		}
+	case EnvBool:
+		switch strings.ToLower(value) {
+		case "1", "true", "yes", "0", "false", "no":
+		default:
+			return fmt.Errorf("%q is not one of true, false, yes, no, 1 or 0", value)
+		}
+	case EnvInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+	}
+	return nil
+}
+
+// DumpEnv writes the set variables of EnvVars to w, one per line, with the
+// values of secrets redacted.
+func DumpEnv(w io.Writer) error {
+	for _, v := range EnvVars {
+		value := os.Getenv(v.Name)
+		if value == "" {
+			continue
+		}
+		if v.Secret {
+			value = fmt.Sprintf("<redacted, %d characters>", len(value))
+		}
+		if path := os.Getenv(v.Name + FileEnvSuffix); path != "" {
+			value += " (from " + path + ")"
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", v.Name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
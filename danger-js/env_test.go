@@ -0,0 +1,121 @@
+package dangerJs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// clearEnv unsets the variables of EnvVars for the test.
+func clearEnv(t *testing.T) {
+	for _, v := range EnvVars {
+		t.Setenv(v.Name, "")
+		t.Setenv(v.Name+FileEnvSuffix, "")
+	}
+}
+
+func TestResolveFileEnv(t *testing.T) {
+	dir := t.TempDir()
+	token := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(token, []byte("s3cret\n"), 0o600))
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    string
+		wantErr string
+	}{
+		{name: "from file", env: map[string]string{"DANGER_GITHUB_API_TOKEN_FILE": token}, want: "s3cret"},
+		{name: "literal", env: map[string]string{"DANGER_GITHUB_API_TOKEN": "literal"}, want: "literal"},
+		{
+			name:    "both",
+			env:     map[string]string{"DANGER_GITHUB_API_TOKEN": "literal", "DANGER_GITHUB_API_TOKEN_FILE": token},
+			want:    "literal",
+			wantErr: "both DANGER_GITHUB_API_TOKEN and DANGER_GITHUB_API_TOKEN_FILE are set",
+		},
+		{
+			name:    "missing file",
+			env:     map[string]string{"DANGER_GITHUB_API_TOKEN_FILE": filepath.Join(dir, "missing")},
+			wantErr: "reading DANGER_GITHUB_API_TOKEN_FILE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			err := ResolveFileEnv()
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.want, os.Getenv("DANGER_GITHUB_API_TOKEN"))
+		})
+	}
+}
+
+func TestValidateEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr []string
+	}{
+		{name: "empty"},
+		{
+			name: "valid",
+			env: map[string]string{
+				"DANGER_GITHUB_API_BASE_URL": "https://github.example.com/api/v3",
+				SafeModeEnv:                  "yes",
+				SafeModeExitCodeEnv:          "0",
+			},
+		},
+		{
+			name: "invalid",
+			env: map[string]string{
+				"DANGER_METRICS_PUSHGATEWAY_URL": "pushgateway:9091",
+				ManagedEnv:                       "sometimes",
+				SafeModeExitCodeEnv:              "none",
+			},
+			wantErr: []string{
+				"DANGER_METRICS_PUSHGATEWAY_URL: \"pushgateway:9091\" is not an absolute URL",
+				ManagedEnv + ": \"sometimes\" is not one of",
+				SafeModeExitCodeEnv + ": \"none\" is not an integer",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			err := ValidateEnv()
+			if len(tt.wantErr) == 0 {
+				require.NoError(t, err)
+			}
+			for _, want := range tt.wantErr {
+				require.ErrorContains(t, err, want)
+			}
+		})
+	}
+}
+
+func TestDumpEnv(t *testing.T) {
+	clearEnv(t)
+	token := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(token, []byte("s3cret"), 0o600))
+	t.Setenv("DANGER_GITHUB_API_TOKEN_FILE", token)
+	t.Setenv("DANGER_GITHUB_API_BASE_URL", "https://github.example.com/api/v3")
+	require.NoError(t, ResolveFileEnv())
+
+	var out strings.Builder
+	require.NoError(t, DumpEnv(&out))
+	require.Equal(t, "DANGER_GITHUB_API_TOKEN=<redacted, 6 characters> (from "+token+")\n"+
+		"DANGER_GITHUB_API_BASE_URL=https://github.example.com/api/v3\n", out.String())
+	require.NotContains(t, out.String(), "s3cret")
+}
@@ -0,0 +1,38 @@
+package dangerJs
+
+import (
+	"errors"
+	"strings"
+)
+
+// Errors returned by the DSL so dangerfiles can branch on the cause of a
+// failure with errors.Is instead of matching error text.
+var (
+	// ErrNoGitRepo is returned when git commands run outside a repository.
+	ErrNoGitRepo = errors.New("not a git repository")
+	// ErrRefNotFound is returned when a ref or commit is not present in the
+	// local clone, e.g. the base branch in a shallow checkout.
+	ErrRefNotFound = errors.New("git ref not found")
+	// ErrAPIUnauthorized is returned when GitHub or GitLab rejects the API
+	// token as missing, invalid or expired.
+	ErrAPIUnauthorized = errors.New("API token unauthorized")
+	// ErrDSLFieldMissing is returned when the DSL JSON received from danger-js
+	// lacks a required section.
+	ErrDSLFieldMissing = errors.New("DSL field missing")
+)
+
+// gitErrorCause maps git's stderr to one of the sentinel errors, or nil when
+// the failure has no specific cause.
+func gitErrorCause(stderr string) error {
+	switch {
+	case strings.Contains(stderr, "not a git repository"):
+		return ErrNoGitRepo
+	case strings.Contains(stderr, "unknown revision"),
+		strings.Contains(stderr, "bad revision"),
+		strings.Contains(stderr, "bad object"),
+		strings.Contains(stderr, "invalid object name"),
+		strings.Contains(stderr, "Needed a single revision"):
+		return ErrRefNotFound
+	}
+	return nil
+}
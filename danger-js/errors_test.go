@@ -0,0 +1,117 @@
+package dangerJs
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitErrorCause(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		want   error
+	}{
+		{"outside repo", "fatal: not a git repository (or any of the parent directories): .git", ErrNoGitRepo},
+		{"unknown base", "fatal: ambiguous argument 'origin/main': unknown revision or path not in the working tree.", ErrRefNotFound},
+		{"bad revision", "fatal: bad revision 'deadbeef'", ErrRefNotFound},
+		{"show missing ref", "fatal: invalid object name 'nope'.", ErrRefNotFound},
+		{"other", "fatal: unable to read tree", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, gitErrorCause(tt.stderr))
+		})
+	}
+}
+
+func TestRunGitNoRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+
+	_, err := gitImpl{dir: dir}.runGit("rev-parse", "HEAD")
+	require.ErrorIs(t, err, ErrNoGitRepo)
+}
+
+func TestDecodeDSL(t *testing.T) {
+	d, err := DecodeDSL([]byte(`{"git":{"modified_files":["a.go"]},"settings":{}}`))
+	require.Nil(t, err)
+	require.Equal(t, []FilePath{"a.go"}, d.Git.ModifiedFiles())
+	_, ok := d.ToInterface().Extra("peril")
+	require.False(t, ok)
+
+	d, err = DecodeDSL([]byte(`{"git":{},"peril":{"env":{"A":"1"}},"settings":{}}`))
+	require.Nil(t, err)
+	raw, ok := d.ToInterface().Extra("peril")
+	require.True(t, ok)
+	require.JSONEq(t, `{"env":{"A":"1"}}`, string(raw))
+
+	_, err = DecodeDSL([]byte(`{"github":{}}`))
+	require.ErrorIs(t, err, ErrDSLFieldMissing)
+	require.EqualError(t, err, "DSL field missing: git")
+
+	_, err = DecodeDSL([]byte(`[]`))
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrDSLFieldMissing)
+}
+
+func TestSettingsCredentials(t *testing.T) {
+	t.Setenv("DANGER_GITLAB_API_TOKEN", "env-token")
+	t.Setenv("DANGER_GITLAB_HOST", "")
+	t.Setenv("CI_SERVER_URL", "https://gitlab.ci")
+	t.Setenv("DANGER_BITBUCKETCLOUD_USERNAME", "env-user")
+	t.Setenv("DANGER_BITBUCKETCLOUD_PASSWORD", "env-pass")
+	t.Setenv("DANGER_BITBUCKETSERVER_HOST", "https://bitbucket.example.com")
+	t.Setenv("DANGER_BITBUCKETSERVER_TOKEN", "env-bb-token")
+
+	d, err := DecodeDSL([]byte(`{"git":{},"settings":{}}`))
+	require.Nil(t, err)
+	s := d.ToInterface().Settings
+	require.Equal(t, "env-token", GitLabAccessToken(s))
+	require.Equal(t, "https://gitlab.ci", GitLabBaseURL(s))
+	require.Equal(t, BitbucketCloudCredentials{Username: "env-user", Password: "env-pass"}, BitbucketCloud(s))
+	require.Equal(t, BitbucketServerCredentials{BaseURL: "https://bitbucket.example.com", Token: "env-bb-token"}, BitbucketServer(s))
+
+	d, err = DecodeDSL([]byte(`{"git":{},"settings":{
+		"gitlab":{"accessToken":"dsl-token","baseURL":"https://gitlab.dsl"},
+		"bitbucketCloud":{"username":"dsl-user"}
+	}}`))
+	require.Nil(t, err)
+	s = d.ToInterface().Settings
+	require.Equal(t, "dsl-token", GitLabAccessToken(s))
+	require.Equal(t, "https://gitlab.dsl", GitLabBaseURL(s))
+	require.Equal(t, BitbucketCloudCredentials{Username: "dsl-user", Password: "env-pass"}, BitbucketCloud(s))
+}
+
+func TestCLIArgsFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    string
+		verbose bool
+		text    bool
+	}{
+		{name: "booleans", args: `{"verbose":true,"textOnly":false}`, verbose: true},
+		{name: "strings", args: `{"verbose":"true","textOnly":"true"}`, verbose: true, text: true},
+		{name: "false strings", args: `{"verbose":"false","textOnly":""}`},
+		{name: "numbers", args: `{"verbose":1,"textOnly":0}`, verbose: true},
+		{name: "null", args: `{"verbose":null}`},
+		{name: "missing", args: `{}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := DecodeDSL([]byte(`{"git":{},"settings":{"cliArgs":` + tt.args + `}}`))
+			require.Nil(t, err)
+			args := d.ToInterface().Settings.CLIArgs()
+			require.Equal(t, tt.verbose, args.Verbose())
+			require.Equal(t, tt.text, args.TextOnly())
+		})
+	}
+
+	_, err := DecodeDSL([]byte(`{"git":{},"settings":{"cliArgs":{"verbose":[]}}}`))
+	require.Error(t, err)
+}
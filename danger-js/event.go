@@ -0,0 +1,136 @@
+package dangerJs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrNoEventPR is returned when the GitHub Actions event isn't of a pull
+// request.
+var ErrNoEventPR = errors.New("the GitHub Actions event is not of a pull request")
+
+// EventFetcher fetches the data of a PR missing from the Actions event
+// payload. githubclient.Client implements it.
+type EventFetcher interface {
+	PullRequestFiles(ctx context.Context, owner, repo string, number int) ([]GitHubPRFile, error)
+	PullRequestReviews(ctx context.Context, owner, repo string, number int) ([]GitHubReview, error)
+}
+
+// actionsEvent is the part of an Actions event payload describing a PR.
+type actionsEvent struct {
+	PullRequest *struct {
+		GitHubPR
+		Labels             []GitHubIssueLabel `json:"labels"`
+		RequestedReviewers []GitHubUser       `json:"requested_reviewers"`
+		RequestedTeams     []GitHubTeam       `json:"requested_teams"`
+	} `json:"pull_request"`
+	Issue struct {
+		PullRequest struct {
+			HTMLURL string `json:"html_url"`
+		} `json:"pull_request"`
+	} `json:"issue"`
+	Repository GitHubRepo `json:"repository"`
+}
+
+// readEvent decodes the event payload at path, GITHUB_EVENT_PATH when empty.
+func readEvent(path string) (actionsEvent, error) {
+	if path == "" {
+		path = os.Getenv("GITHUB_EVENT_PATH")
+	}
+	if path == "" {
+		return actionsEvent{}, fmt.Errorf("GITHUB_EVENT_PATH is not set: %w", ErrNoEventPR)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return actionsEvent{}, fmt.Errorf("reading event payload: %w", err)
+	}
+	var event actionsEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return actionsEvent{}, fmt.Errorf("decoding event payload: %w", err)
+	}
+	return event, nil
+}
+
+// EventPRURL returns the URL of the pull request of the GitHub Actions event
+// payload at GITHUB_EVENT_PATH, for pull_request, pull_request_target,
+// pull_request_review and issue_comment events.
+func EventPRURL() (string, error) {
+	event, err := readEvent("")
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case event.PullRequest != nil && event.PullRequest.HTMLURL != "":
+		return event.PullRequest.HTMLURL, nil
+	case event.Issue.PullRequest.HTMLURL != "":
+		return event.Issue.PullRequest.HTMLURL, nil
+	}
+	return "", ErrNoEventPR
+}
+
+// DSLFromEvent builds the DSL of the PR of a pull_request or
+// pull_request_target event payload at path, GITHUB_EVENT_PATH when empty,
+// instead of having danger-js fetch it. The payload holds the PR, its labels
+// and requested reviewers; only the files and reviews are fetched with f.
+// The commits are left to CommitsContext, see WithGitHubFetcher.
+func DSLFromEvent(ctx context.Context, path string, f EventFetcher) (DSLData, error) {
+	event, err := readEvent(path)
+	if err != nil {
+		return DSLData{}, err
+	}
+	if event.PullRequest == nil {
+		return DSLData{}, ErrNoEventPR
+	}
+	owner, repo, ok := strings.Cut(event.Repository.FullName, "/")
+	if !ok {
+		owner, repo, ok = strings.Cut(event.PullRequest.Base.Repo.FullName, "/")
+	}
+	if !ok {
+		return DSLData{}, errors.New("the event payload names no repository")
+	}
+	pr := event.PullRequest.GitHubPR
+
+	var d DSLData
+	d.GitHub.PRData = pr
+	d.GitHub.ThisPRData = GitHubAPIPR{Owner: owner, Repo: repo, Number: pr.Number}
+	d.GitHub.IssueData = GitHubIssue{
+		Number:  pr.Number,
+		Title:   pr.Title,
+		Body:    pr.Body,
+		State:   pr.State,
+		HTMLURL: pr.HTMLURL,
+		Labels:  event.PullRequest.Labels,
+	}
+	d.GitHub.RequestedReviewersData = GitHubReviewers{
+		Users: event.PullRequest.RequestedReviewers,
+		Teams: event.PullRequest.RequestedTeams,
+	}
+
+	files, err := f.PullRequestFiles(ctx, owner, repo, pr.Number)
+	if err != nil {
+		return DSLData{}, fmt.Errorf("fetching PR files: %w", err)
+	}
+	// the lists are empty rather than nil, as in the DSL of danger-js
+	d.Git.CreatedFilesList = []FilePath{}
+	d.Git.ModifiedFilesList = []FilePath{}
+	d.Git.DeletedFilesList = []FilePath{}
+	for _, file := range files {
+		switch file.Status {
+		case "added":
+			d.Git.CreatedFilesList = append(d.Git.CreatedFilesList, file.Filename)
+		case "removed":
+			d.Git.DeletedFilesList = append(d.Git.DeletedFilesList, file.Filename)
+		default:
+			d.Git.ModifiedFilesList = append(d.Git.ModifiedFilesList, file.Filename)
+		}
+	}
+
+	if d.GitHub.ReviewsList, err = f.PullRequestReviews(ctx, owner, repo, pr.Number); err != nil {
+		return DSLData{}, fmt.Errorf("fetching PR reviews: %w", err)
+	}
+	return d, nil
+}
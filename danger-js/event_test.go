@@ -0,0 +1,102 @@
+package dangerJs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventPRURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "pull_request",
+			payload: `{"action":"opened","pull_request":{"number":4,"html_url":"https://github.com/org/repo/pull/4"}}`,
+			want:    "https://github.com/org/repo/pull/4",
+		},
+		{
+			name:    "issue_comment on a PR",
+			payload: `{"issue":{"number":5,"pull_request":{"html_url":"https://github.com/org/repo/pull/5"}}}`,
+			want:    "https://github.com/org/repo/pull/5",
+		},
+		{name: "push", payload: `{"ref":"refs/heads/main"}`, wantErr: ErrNoEventPR},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "event.json")
+			require.NoError(t, os.WriteFile(path, []byte(tt.payload), 0o600))
+			t.Setenv("GITHUB_EVENT_PATH", path)
+			got, err := EventPRURL()
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Setenv("GITHUB_EVENT_PATH", "")
+	_, err := EventPRURL()
+	require.ErrorIs(t, err, ErrNoEventPR)
+}
+
+type fakeEventFetcher struct {
+	files   []GitHubPRFile
+	reviews []GitHubReview
+}
+
+func (f fakeEventFetcher) PullRequestFiles(_ context.Context, owner, repo string, number int) ([]GitHubPRFile, error) {
+	if owner != "org" || repo != "repo" || number != 7 {
+		return nil, errors.New("unexpected PR")
+	}
+	return f.files, nil
+}
+
+func (f fakeEventFetcher) PullRequestReviews(context.Context, string, string, int) ([]GitHubReview, error) {
+	return f.reviews, nil
+}
+
+func TestDSLFromEvent(t *testing.T) {
+	dir := t.TempDir()
+	prEvent := filepath.Join(dir, "pr.json")
+	require.NoError(t, os.WriteFile(prEvent, []byte(`{
+		"action": "synchronize",
+		"pull_request": {
+			"number": 7, "title": "Fix", "state": "open", "html_url": "https://github.com/org/repo/pull/7",
+			"labels": [{"name": "bug"}],
+			"requested_reviewers": [{"login": "alice"}],
+			"base": {"repo": {"full_name": "org/repo"}}
+		}
+	}`), 0o600))
+	pushEvent := filepath.Join(dir, "push.json")
+	require.NoError(t, os.WriteFile(pushEvent, []byte(`{"ref":"refs/heads/main","repository":{"full_name":"org/repo"}}`), 0o600))
+
+	f := fakeEventFetcher{
+		files: []GitHubPRFile{
+			{Filename: "a.go", Status: "added"},
+			{Filename: "b.go", Status: "modified"},
+			{Filename: "c.go", Status: "removed"},
+			{Filename: "d.go", Status: "renamed", PreviousFilename: "old.go"},
+		},
+		reviews: []GitHubReview{{State: "APPROVED", User: GitHubUser{Login: "bob"}}},
+	}
+	d, err := DSLFromEvent(context.Background(), prEvent, f)
+	require.NoError(t, err)
+	dsl := d.ToInterface()
+	require.Equal(t, GitHubAPIPR{Owner: "org", Repo: "repo", Number: 7}, dsl.GitHub.ThisPR())
+	require.Equal(t, "Fix", dsl.GitHub.Issue().Title)
+	require.Equal(t, "bug", dsl.GitHub.Issue().Labels[0].Name)
+	require.True(t, dsl.GitHub.RequestedReviewers().HasReviewer("alice"))
+	require.Equal(t, []FilePath{"a.go"}, dsl.Git.CreatedFiles())
+	require.Equal(t, []FilePath{"b.go", "d.go"}, dsl.Git.ModifiedFiles())
+	require.Equal(t, []FilePath{"c.go"}, dsl.Git.DeletedFiles())
+	require.Equal(t, f.reviews, dsl.GitHub.Reviews())
+
+	_, err = DSLFromEvent(context.Background(), pushEvent, f)
+	require.ErrorIs(t, err, ErrNoEventPR)
+}
@@ -0,0 +1,140 @@
+package dangerJs
+
+import (
+	"context"
+	"strconv"
+	"sync"
+)
+
+// GitHubFetcher fetches the GitHub data of a PR missing from the DSL JSON,
+// e.g. in standalone mode or with partial payloads. githubclient.Client
+// implements it.
+type GitHubFetcher interface {
+	PullRequestCommits(ctx context.Context, owner, repo string, number int) ([]GitHubCommit, error)
+	PullRequestReviews(ctx context.Context, owner, repo string, number int) ([]GitHubReview, error)
+}
+
+// GitLabFetcher fetches the GitLab data of a MR missing from the DSL JSON.
+// gitlabclient.Client implements it.
+type GitLabFetcher interface {
+	MRCommits(ctx context.Context, project string, mrIID int64) ([]GitLabMRCommit, error)
+	MRApprovals(ctx context.Context, project string, mrIID int64) (GitLabApproval, error)
+}
+
+// WithGitHubFetcher fetches the commits and reviews of the PR with f when the
+// DSL JSON lacks them.
+func WithGitHubFetcher(f GitHubFetcher) DSLOption {
+	return func(d *DSL) {
+		if g, ok := d.GitHub.(gitHubImpl); ok {
+			g.fetcher = f
+			g.fetched = &gitHubFetched{}
+			d.GitHub = g
+		}
+	}
+}
+
+// WithGitLabFetcher fetches the commits and approvals of the MR with f when
+// the DSL JSON lacks them.
+func WithGitLabFetcher(f GitLabFetcher) DSLOption {
+	return func(d *DSL) {
+		if g, ok := d.GitLab.(gitLabImpl); ok {
+			g.fetcher = f
+			g.fetched = &gitLabFetched{}
+			d.GitLab = g
+		}
+	}
+}
+
+// memo holds a value fetched once. Failed fetches are retried on the next
+// call.
+type memo[T any] struct {
+	mu   sync.Mutex
+	done bool
+	val  T
+}
+
+func (m *memo[T]) load(fetch func() (T, error)) (T, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.done {
+		return m.val, nil
+	}
+	v, err := fetch()
+	if err != nil {
+		return v, err
+	}
+	m.done, m.val = true, v
+	return v, nil
+}
+
+type gitHubFetched struct {
+	commits  memo[[]GitHubCommit]
+	reviews  memo[[]GitHubReview]
+	timeline memo[GitHubTimeline]
+}
+
+type gitLabFetched struct {
+	commits   memo[[]GitLabMRCommit]
+	approvals memo[GitLabApproval]
+}
+
+// CommitsContext returns the commits of the PR, fetching them when the DSL
+// JSON has none and a GitHubFetcher is configured. The error wraps
+// ErrDSLFieldMissing when the streamed commits can't be decoded.
+func (g gitHubImpl) CommitsContext(ctx context.Context) ([]GitHubCommit, error) {
+	if g.commits != nil {
+		return g.commits.load()
+	}
+	if g.CommitsList != nil || g.fetcher == nil {
+		return g.CommitsList, nil
+	}
+	return g.fetched.commits.load(func() ([]GitHubCommit, error) {
+		return g.fetcher.PullRequestCommits(ctx, g.ThisPRData.Owner, g.ThisPRData.Repo, g.ThisPRData.Number)
+	})
+}
+
+// ReviewsContext returns the reviews of the PR, fetching them when the DSL
+// JSON has none and a GitHubFetcher is configured.
+func (g gitHubImpl) ReviewsContext(ctx context.Context) ([]GitHubReview, error) {
+	if g.ReviewsList != nil || g.fetcher == nil {
+		return g.ReviewsList, nil
+	}
+	return g.fetched.reviews.load(func() ([]GitHubReview, error) {
+		return g.fetcher.PullRequestReviews(ctx, g.ThisPRData.Owner, g.ThisPRData.Repo, g.ThisPRData.Number)
+	})
+}
+
+// mrIID returns the IID of the MR, from the MR data or the metadata.
+func (g gitLabImpl) mrIID() int64 {
+	if g.MRData.IID != 0 {
+		return g.MRData.IID
+	}
+	iid, _ := strconv.ParseInt(g.MetadataData.PullRequestID, 10, 64)
+	return iid
+}
+
+// CommitsContext returns the commits of the MR, fetching them when the DSL
+// JSON has none and a GitLabFetcher is configured. The error wraps
+// ErrDSLFieldMissing when the streamed commits can't be decoded.
+func (g gitLabImpl) CommitsContext(ctx context.Context) ([]GitLabMRCommit, error) {
+	if g.commits != nil {
+		return g.commits.load()
+	}
+	if g.CommitsList != nil || g.fetcher == nil {
+		return g.CommitsList, nil
+	}
+	return g.fetched.commits.load(func() ([]GitLabMRCommit, error) {
+		return g.fetcher.MRCommits(ctx, g.MetadataData.RepoSlug, g.mrIID())
+	})
+}
+
+// ApprovalsContext returns the approvals of the MR, fetching them when the
+// DSL JSON has none and a GitLabFetcher is configured.
+func (g gitLabImpl) ApprovalsContext(ctx context.Context) (GitLabApproval, error) {
+	if g.ApprovalsData.ID != 0 || g.fetcher == nil {
+		return g.ApprovalsData, nil
+	}
+	return g.fetched.approvals.load(func() (GitLabApproval, error) {
+		return g.fetcher.MRApprovals(ctx, g.MetadataData.RepoSlug, g.mrIID())
+	})
+}
@@ -0,0 +1,84 @@
+package dangerJs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFetcher struct {
+	calls int
+	err   error
+}
+
+func (f *fakeFetcher) PullRequestCommits(_ context.Context, owner, repo string, number int) ([]GitHubCommit, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []GitHubCommit{{SHA: owner + "/" + repo}}, nil
+}
+
+func (f *fakeFetcher) PullRequestReviews(_ context.Context, _, _ string, number int) ([]GitHubReview, error) {
+	f.calls++
+	return []GitHubReview{{State: "APPROVED"}}, nil
+}
+
+func (f *fakeFetcher) MRCommits(_ context.Context, project string, mrIID int64) ([]GitLabMRCommit, error) {
+	f.calls++
+	return []GitLabMRCommit{{ID: project}}, nil
+}
+
+func (f *fakeFetcher) MRApprovals(_ context.Context, _ string, mrIID int64) (GitLabApproval, error) {
+	f.calls++
+	return GitLabApproval{ID: 1, IID: mrIID}, nil
+}
+
+func TestGitHubFetcher(t *testing.T) {
+	data := DSLData{GitHub: gitHubImpl{ThisPRData: GitHubAPIPR{Owner: "o", Repo: "r", Number: 3}}}
+
+	f := &fakeFetcher{}
+	pr := data.ToInterface(WithGitHubFetcher(f))
+	commits, err := GitHubCommits(context.Background(), pr.GitHub)
+	require.NoError(t, err)
+	require.Equal(t, []GitHubCommit{{SHA: "o/r"}}, commits)
+	require.Equal(t, commits, pr.GitHub.Commits())
+	require.Equal(t, "APPROVED", pr.GitHub.Reviews()[0].State)
+	require.Equal(t, 2, f.calls, "results are memoized")
+
+	// data present in the JSON is used as is, even when empty
+	data.GitHub.CommitsList = []GitHubCommit{}
+	f = &fakeFetcher{}
+	pr = data.ToInterface(WithGitHubFetcher(f))
+	require.Empty(t, pr.GitHub.Commits())
+	require.Zero(t, f.calls)
+
+	// failures are returned and retried
+	data.GitHub.CommitsList = nil
+	f = &fakeFetcher{err: errors.New("boom")}
+	pr = data.ToInterface(WithGitHubFetcher(f))
+	_, err = GitHubCommits(context.Background(), pr.GitHub)
+	require.EqualError(t, err, "boom")
+	require.Nil(t, pr.GitHub.Commits())
+	require.Equal(t, 2, f.calls)
+
+	// without a fetcher nothing is fetched
+	commits, err = GitHubCommits(context.Background(), data.ToInterface().GitHub)
+	require.NoError(t, err)
+	require.Nil(t, commits)
+}
+
+func TestGitLabFetcher(t *testing.T) {
+	data := DSLData{GitLab: gitLabImpl{MetadataData: RepoMetaData{RepoSlug: "group/project", PullRequestID: "9"}}}
+
+	f := &fakeFetcher{}
+	pr := data.ToInterface(WithGitLabFetcher(f))
+	require.Equal(t, []GitLabMRCommit{{ID: "group/project"}}, pr.GitLab.Commits())
+	approvals, err := GitLabApprovals(context.Background(), pr.GitLab)
+	require.NoError(t, err)
+	require.Equal(t, int64(9), approvals.IID)
+	require.Equal(t, approvals, pr.GitLab.Approvals())
+	require.Equal(t, 2, f.calls)
+}
@@ -0,0 +1,121 @@
+package dangerJs
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Git file modes as they appear in diffs.
+const (
+	ModeAbsent     = "000000"
+	ModeRegular    = "100644"
+	ModeExecutable = "100755"
+	ModeSymlink    = "120000"
+	ModeSubmodule  = "160000"
+)
+
+// FileModeChange describes a path whose git file mode differs between two
+// refs, including created and deleted files.
+type FileModeChange struct {
+	Path    FilePath
+	OldMode string
+	NewMode string
+	// LinkTarget is the path a symlink points to, set when NewMode is
+	// ModeSymlink.
+	LinkTarget string
+
+	newBlob string
+}
+
+// BecameExecutable reports whether the file is executable at the head ref but
+// was not at the base ref, including newly created executables.
+func (c FileModeChange) BecameExecutable() bool {
+	return c.NewMode == ModeExecutable && c.OldMode != ModeExecutable
+}
+
+// IsNewSymlink reports whether the path is a symlink at the head ref but was
+// not at the base ref.
+func (c FileModeChange) IsNewSymlink() bool {
+	return c.NewMode == ModeSymlink && c.OldMode != ModeSymlink
+}
+
+// LinkEscapesRepo reports whether the symlink points at an absolute path or
+// outside of the repository root.
+func (c FileModeChange) LinkEscapesRepo() bool {
+	if c.NewMode != ModeSymlink || c.LinkTarget == "" {
+		return false
+	}
+	if strings.HasPrefix(c.LinkTarget, "/") || strings.HasPrefix(c.LinkTarget, `\`) ||
+		(len(c.LinkTarget) > 1 && c.LinkTarget[1] == ':') {
+		return true
+	}
+	resolved := path.Clean(path.Join(path.Dir(c.Path), c.LinkTarget))
+	return resolved == ".." || strings.HasPrefix(resolved, "../")
+}
+
+// FileModeChangesWithRefs returns the paths whose file mode differs between
+// baseRef and headRef. Symlink targets are resolved from the head ref.
+func (g gitImpl) FileModeChangesWithRefs(baseRef, headRef string) ([]FileModeChange, error) {
+	if !validateGitRef(baseRef) {
+		return nil, fmt.Errorf("invalid base ref: %s", baseRef)
+	}
+	if !validateGitRef(headRef) {
+		return nil, fmt.Errorf("invalid head ref: %s", headRef)
+	}
+
+	out, err := g.runGitWithRefs([]string{baseRef, headRef}, func(r []string) []string {
+		return []string{"diff", "--raw", "--no-abbrev", "--no-renames", "-z", r[0], r[1]}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := parseRawDiff(out)
+	if err != nil {
+		return nil, err
+	}
+	for i, c := range changes {
+		if c.NewMode != ModeSymlink {
+			continue
+		}
+		target, err := g.runGit("cat-file", "blob", c.newBlob)
+		if err != nil {
+			return nil, fmt.Errorf("reading symlink target of %s: %w", c.Path, err)
+		}
+		changes[i].LinkTarget = target
+	}
+	return changes, nil
+}
+
+// parseRawDiff parses `git diff --raw -z` output and returns the entries whose
+// mode changed.
+func parseRawDiff(out string) ([]FileModeChange, error) {
+	var changes []FileModeChange
+	fields := strings.Split(out, "\x00")
+	for i := 0; i < len(fields); i++ {
+		meta := fields[i]
+		if meta == "" {
+			continue
+		}
+		if !strings.HasPrefix(meta, ":") || i+1 >= len(fields) {
+			return nil, fmt.Errorf("unexpected raw diff entry: %q", meta)
+		}
+		// :<old mode> <new mode> <old sha> <new sha> <status>
+		parts := strings.Fields(strings.TrimPrefix(meta, ":"))
+		if len(parts) < 5 {
+			return nil, fmt.Errorf("unexpected raw diff entry: %q", meta)
+		}
+		i++
+		if parts[0] == parts[1] {
+			continue
+		}
+		changes = append(changes, FileModeChange{
+			Path:    fields[i],
+			OldMode: parts[0],
+			NewMode: parts[1],
+			newBlob: parts[3],
+		})
+	}
+	return changes, nil
+}
@@ -0,0 +1,105 @@
+package dangerJs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRawDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		want    []FileModeChange
+		wantErr bool
+	}{
+		{
+			name: "empty output",
+			out:  "",
+			want: nil,
+		},
+		{
+			name: "content change only",
+			out:  ":100644 100644 aaa bbb M\x00main.go\x00",
+			want: nil,
+		},
+		{
+			name: "mode changes",
+			out: ":100644 100755 aaa bbb M\x00scripts/run.sh\x00" +
+				":000000 120000 000 ccc A\x00link\x00" +
+				":100644 000000 ddd 000 D\x00old.go\x00",
+			want: []FileModeChange{
+				{Path: "scripts/run.sh", OldMode: ModeRegular, NewMode: ModeExecutable, newBlob: "bbb"},
+				{Path: "link", OldMode: ModeAbsent, NewMode: ModeSymlink, newBlob: "ccc"},
+				{Path: "old.go", OldMode: ModeRegular, NewMode: ModeAbsent, newBlob: "000"},
+			},
+		},
+		{
+			name:    "malformed entry",
+			out:     "garbage\x00",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRawDiff(tt.out)
+			if tt.wantErr {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFileModeChange(t *testing.T) {
+	tests := []struct {
+		name            string
+		change          FileModeChange
+		wantExecutable  bool
+		wantNewSymlink  bool
+		wantEscapesRepo bool
+	}{
+		{
+			name:           "regular to executable",
+			change:         FileModeChange{Path: "run.sh", OldMode: ModeRegular, NewMode: ModeExecutable},
+			wantExecutable: true,
+		},
+		{
+			name:           "new executable",
+			change:         FileModeChange{Path: "run.sh", OldMode: ModeAbsent, NewMode: ModeExecutable},
+			wantExecutable: true,
+		},
+		{
+			name:   "executable to regular",
+			change: FileModeChange{Path: "run.sh", OldMode: ModeExecutable, NewMode: ModeRegular},
+		},
+		{
+			name:           "symlink inside repo",
+			change:         FileModeChange{Path: "a/b/link", OldMode: ModeAbsent, NewMode: ModeSymlink, LinkTarget: "../c"},
+			wantNewSymlink: true,
+		},
+		{
+			name:            "symlink escaping repo",
+			change:          FileModeChange{Path: "a/link", OldMode: ModeAbsent, NewMode: ModeSymlink, LinkTarget: "../../etc"},
+			wantNewSymlink:  true,
+			wantEscapesRepo: true,
+		},
+		{
+			name:            "absolute symlink",
+			change:          FileModeChange{Path: "link", OldMode: ModeRegular, NewMode: ModeSymlink, LinkTarget: "/etc/passwd"},
+			wantNewSymlink:  true,
+			wantEscapesRepo: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.wantExecutable, tt.change.BecameExecutable())
+			require.Equal(t, tt.wantNewSymlink, tt.change.IsNewSymlink())
+			require.Equal(t, tt.wantEscapesRepo, tt.change.LinkEscapesRepo())
+		})
+	}
+}
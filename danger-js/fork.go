@@ -0,0 +1,73 @@
+package dangerJs
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SafeModeEnv forces fork-PR safe mode on ("true") or off ("false"). When
+// unset, safe mode is detected from the GitHub Actions event.
+const SafeModeEnv = "DANGER_SAFE_MODE"
+
+// SafeModeExitCodeEnv sets the exit code of `danger-go ci` when danger fails
+// in safe mode, e.g. 0 to keep fork PRs green and rely on the annotations.
+const SafeModeExitCodeEnv = "DANGER_SAFE_MODE_EXIT_CODE"
+
+// ErrReadOnly is returned by write operations skipped in safe mode.
+var ErrReadOnly = errors.New("running in fork-PR safe mode with a read-only token, write operations are skipped")
+
+// SafeMode reports whether danger runs for a PR from a fork with a read-only
+// token, as GitHub Actions grants to `pull_request` workflows of forks. In
+// safe mode nothing is written to the PR: results are reported as Actions
+// annotations and in the job summary instead.
+func SafeMode() bool {
+	switch strings.ToLower(os.Getenv(SafeModeEnv)) {
+	case "1", "true", "yes":
+		return true
+	case "0", "false", "no":
+		return false
+	}
+	if os.Getenv("GITHUB_EVENT_NAME") != "pull_request" {
+		return false
+	}
+	fork, err := isForkEvent(os.Getenv("GITHUB_EVENT_PATH"))
+	return err == nil && fork
+}
+
+// isForkEvent reports whether the event payload at path is of a PR whose head
+// repository differs from its base repository.
+func isForkEvent(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	var event struct {
+		PullRequest struct {
+			Head GitHubMergeRef `json:"head"`
+			Base GitHubMergeRef `json:"base"`
+		} `json:"pull_request"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return false, err
+	}
+	head, base := event.PullRequest.Head.Repo.FullName, event.PullRequest.Base.Repo.FullName
+	return head != "" && base != "" && !strings.EqualFold(head, base), nil
+}
+
+// safeModeExitCode returns the exit code configured by SafeModeExitCodeEnv.
+func safeModeExitCode() (int, bool) {
+	code, err := strconv.Atoi(os.Getenv(SafeModeExitCodeEnv))
+	return code, err == nil
+}
+
+// ExitError is returned by Process when danger should exit with Code.
+type ExitError struct {
+	Code int
+}
+
+func (e ExitError) Error() string {
+	return "danger exited with code " + strconv.Itoa(e.Code)
+}
@@ -0,0 +1,39 @@
+package dangerJs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSafeMode(t *testing.T) {
+	event := func(head string) string {
+		return `{"pull_request":{"head":{"repo":{"full_name":"` + head + `"}},"base":{"repo":{"full_name":"org/repo"}}}}`
+	}
+	tests := []struct {
+		name      string
+		env       string
+		eventName string
+		payload   string
+		want      bool
+	}{
+		{name: "fork", eventName: "pull_request", payload: event("someone/repo"), want: true},
+		{name: "same repository", eventName: "pull_request", payload: event("org/repo"), want: false},
+		{name: "pull_request_target has a write token", eventName: "pull_request_target", payload: event("someone/repo"), want: false},
+		{name: "not on Actions", want: false},
+		{name: "disabled", env: "false", eventName: "pull_request", payload: event("someone/repo"), want: false},
+		{name: "forced", env: "true", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "event.json")
+			require.NoError(t, os.WriteFile(path, []byte(tt.payload), 0o600))
+			t.Setenv(SafeModeEnv, tt.env)
+			t.Setenv("GITHUB_EVENT_NAME", tt.eventName)
+			t.Setenv("GITHUB_EVENT_PATH", path)
+			require.Equal(t, tt.want, SafeMode())
+		})
+	}
+}
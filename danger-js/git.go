@@ -0,0 +1,62 @@
+package dangerJs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+
+	"github.com/danger/golang/tracing"
+)
+
+// redactArgs returns args with the credentials of the URLs among them, such
+// as the remotes fetched, replaced so they don't leak into spans.
+func redactArgs(args []string) []string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		out[i] = arg
+		if !strings.Contains(arg, "://") {
+			continue
+		}
+		if u, err := url.Parse(arg); err == nil && u.User != nil {
+			u.User = url.User("REDACTED")
+			out[i] = u.String()
+		}
+	}
+	return out
+}
+
+// runGit runs git with the given arguments in the repository directory and
+// returns its stdout.
+func (g gitImpl) runGit(args ...string) (string, error) {
+	_, span := tracing.Start(context.Background(), "git "+args[0])
+	span.SetAttr("git.args", strings.Join(redactArgs(args), " "))
+	if g.dir != "" {
+		span.SetAttr("git.dir", g.dir)
+	}
+	defer span.End()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = g.dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		switch cause := gitErrorCause(msg); {
+		case cause != nil:
+			err = fmt.Errorf("git %s: %w: %w: %s", args[0], cause, err, msg)
+		case msg != "":
+			err = fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		default:
+			err = fmt.Errorf("git %s: %w", args[0], err)
+		}
+		span.SetError(err)
+		// stdout is returned too, as some commands report results through
+		// their exit code, e.g. merge-tree
+		return stdout.String(), err
+	}
+	return stdout.String(), nil
+}
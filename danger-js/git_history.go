@@ -0,0 +1,139 @@
+package dangerJs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BlameLine attributes a line of a file to the commit which last changed it.
+type BlameLine struct {
+	Line        int
+	SHA         string
+	AuthorName  string
+	AuthorEmail string
+	AuthorTime  time.Time
+	Content     string
+}
+
+// Blame returns the authorship of every line of a file at ref.
+func (g gitImpl) Blame(filePath, ref string) ([]BlameLine, error) {
+	filePath = NormalizePath(filePath)
+	if !validateFilePath(filePath) {
+		return nil, fmt.Errorf("invalid file path: %s", filePath)
+	}
+	if !validateGitRef(ref) {
+		return nil, fmt.Errorf("invalid ref: %s", ref)
+	}
+	out, err := g.runGitWithRefs([]string{ref}, func(r []string) []string {
+		return []string{"blame", "--line-porcelain", r[0], "--", filePath}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseBlamePorcelain(out)
+}
+
+// parseBlamePorcelain parses `git blame --line-porcelain` output, in which
+// every line is preceded by its full commit header.
+func parseBlamePorcelain(out string) ([]BlameLine, error) {
+	var lines []BlameLine
+	var cur BlameLine
+	for _, l := range strings.Split(normalizeLineEndings(out), "\n") {
+		switch {
+		case l == "":
+			continue
+		case strings.HasPrefix(l, "\t"):
+			cur.Content = l[1:]
+			lines = append(lines, cur)
+			cur = BlameLine{}
+		case strings.HasPrefix(l, "author "):
+			cur.AuthorName = strings.TrimPrefix(l, "author ")
+		case strings.HasPrefix(l, "author-mail "):
+			cur.AuthorEmail = strings.Trim(strings.TrimPrefix(l, "author-mail "), "<>")
+		case strings.HasPrefix(l, "author-time "):
+			sec, err := strconv.ParseInt(strings.TrimPrefix(l, "author-time "), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("parsing blame author time: %w", err)
+			}
+			cur.AuthorTime = time.Unix(sec, 0).UTC()
+		case cur.SHA == "":
+			// <sha> <original line> <final line> [<group size>]
+			fields := strings.Fields(l)
+			if len(fields) < 3 || len(fields[0]) < 40 {
+				continue
+			}
+			n, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("parsing blame line number: %w", err)
+			}
+			cur.SHA = fields[0]
+			cur.Line = n
+		}
+	}
+	return lines, nil
+}
+
+// logFieldSep separates fields in the custom log format used below.
+const logFieldSep = "\x1f"
+
+// CommitsForFile returns up to limit commits reachable from HEAD which
+// touched the file, newest first. A limit of zero returns every commit.
+func (g gitImpl) CommitsForFile(filePath string, limit int) ([]GitCommit, error) {
+	filePath = NormalizePath(filePath)
+	if !validateFilePath(filePath) {
+		return nil, fmt.Errorf("invalid file path: %s", filePath)
+	}
+	args := []string{"log", "--format=%H%x1f%an%x1f%ae%x1f%aI%x1f%B%x1e"}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+	out, err := g.runGit(append(args, "--", filePath)...)
+	if err != nil {
+		return nil, err
+	}
+	return parseLog(out), nil
+}
+
+// CommitsForFileAtRef returns up to limit commits reachable from ref which
+// touched the file, newest first, e.g. the history before the PR with its
+// base ref. A limit of zero returns every commit.
+func (g gitImpl) CommitsForFileAtRef(filePath, ref string, limit int) ([]GitCommit, error) {
+	filePath = NormalizePath(filePath)
+	if !validateFilePath(filePath) {
+		return nil, fmt.Errorf("invalid file path: %s", filePath)
+	}
+	if !validateGitRef(ref) {
+		return nil, fmt.Errorf("invalid ref: %s", ref)
+	}
+	out, err := g.runGitWithRefs([]string{ref}, func(r []string) []string {
+		args := []string{"log", "--format=%H%x1f%an%x1f%ae%x1f%aI%x1f%B%x1e"}
+		if limit > 0 {
+			args = append(args, "-n", strconv.Itoa(limit))
+		}
+		return append(args, r[0], "--", filePath)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseLog(out), nil
+}
+
+// parseLog parses records written with the format used by CommitsForFile.
+func parseLog(out string) []GitCommit {
+	var commits []GitCommit
+	for _, rec := range strings.Split(out, "\x1e") {
+		rec = strings.TrimLeft(rec, "\n")
+		fields := strings.SplitN(rec, logFieldSep, 5)
+		if len(fields) < 5 {
+			continue
+		}
+		commits = append(commits, GitCommit{
+			SHA:     fields[0],
+			Author:  GitCommitAuthor{Name: fields[1], Email: fields[2], Date: fields[3]},
+			Message: strings.TrimSpace(fields[4]),
+		})
+	}
+	return commits
+}
@@ -0,0 +1,106 @@
+package dangerJs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBlamePorcelain(t *testing.T) {
+	out := `4bf306fbe778bc75e29e7e32b912e195c83831f6 1 1 1
+author Ada
+author-mail <ada@example.com>
+author-time 1700000000
+author-tz +0000
+committer Ada
+committer-mail <ada@example.com>
+committer-time 1700000000
+committer-tz +0000
+summary change timeout
+previous 502b655ba4039fb22d83b996af136b0fe1e7bc1d c.txt
+filename c.txt
+	timeout = 60
+502b655ba4039fb22d83b996af136b0fe1e7bc1d 2 2 1
+author Bob
+author-mail <bob@example.com>
+author-time 1600000000
+author-tz +0000
+committer Bob
+committer-mail <bob@example.com>
+committer-time 1600000000
+committer-tz +0000
+summary initial
+filename c.txt
+	name = foo
+`
+
+	lines, err := parseBlamePorcelain(out)
+	require.Nil(t, err)
+	require.Equal(t, []BlameLine{
+		{
+			Line:        1,
+			SHA:         "4bf306fbe778bc75e29e7e32b912e195c83831f6",
+			AuthorName:  "Ada",
+			AuthorEmail: "ada@example.com",
+			AuthorTime:  time.Unix(1700000000, 0).UTC(),
+			Content:     "timeout = 60",
+		},
+		{
+			Line:        2,
+			SHA:         "502b655ba4039fb22d83b996af136b0fe1e7bc1d",
+			AuthorName:  "Bob",
+			AuthorEmail: "bob@example.com",
+			AuthorTime:  time.Unix(1600000000, 0).UTC(),
+			Content:     "name = foo",
+		},
+	}, lines)
+}
+
+func TestParseLog(t *testing.T) {
+	out := "aaa\x1fAda\x1fada@example.com\x1f2024-01-02T03:04:05+00:00\x1ffix: crash\n\nDetails\n\x1e\n" +
+		"bbb\x1fBob\x1fbob@example.com\x1f2023-01-02T03:04:05+00:00\x1ffeat: thing\n\x1e\n"
+
+	require.Equal(t, []GitCommit{
+		{
+			SHA:     "aaa",
+			Author:  GitCommitAuthor{Name: "Ada", Email: "ada@example.com", Date: "2024-01-02T03:04:05+00:00"},
+			Message: "fix: crash\n\nDetails",
+		},
+		{
+			SHA:     "bbb",
+			Author:  GitCommitAuthor{Name: "Bob", Email: "bob@example.com", Date: "2023-01-02T03:04:05+00:00"},
+			Message: "feat: thing",
+		},
+	}, parseLog(out))
+}
+
+func TestCommitsForFileAtRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git(t, dir, "init", "-q")
+	for _, msg := range []string{"feat: add a", "fix: a on the base", "fix: a in the PR"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte(msg), 0o644))
+		git(t, dir, "add", "a.txt")
+		git(t, dir, "commit", "-q", "-m", msg)
+	}
+	g := gitImpl{dir: dir}
+
+	commits, err := g.CommitsForFileAtRef("a.txt", "HEAD^", 0)
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	require.Equal(t, "fix: a on the base", commits[0].Message)
+
+	commits, err = g.CommitsForFileAtRef("a.txt", "HEAD", 1)
+	require.NoError(t, err)
+	require.Len(t, commits, 1)
+	require.Equal(t, "fix: a in the PR", commits[0].Message)
+
+	_, err = g.CommitsForFileAtRef("a.txt", "HEAD;ls", 0)
+	require.ErrorContains(t, err, "invalid ref")
+}
@@ -0,0 +1,236 @@
+package dangerJs
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// ChangedFunction is a Go function or method containing changed lines.
+type ChangedFunction struct {
+	File FilePath
+	Name string
+	// Receiver is the receiver type name for methods, without any pointer,
+	// e.g. "T" for `func (t *T) Foo()`.
+	Receiver string
+	Exported bool
+	// StartLine and EndLine span the declaration at the head ref, excluding
+	// its doc comment.
+	StartLine int
+	EndLine   int
+	// HasDoc reports whether the function has a doc comment at the head ref.
+	HasDoc bool
+	// DocChanged reports whether any line of the doc comment changed.
+	DocChanged bool
+	// BodyChanged reports whether any line of the declaration changed.
+	BodyChanged bool
+}
+
+// QualifiedName returns Receiver.Name for methods and Name for functions.
+func (f ChangedFunction) QualifiedName() string {
+	if f.Receiver == "" {
+		return f.Name
+	}
+	return f.Receiver + "." + f.Name
+}
+
+// FileAtRef returns the content of a file at the given ref.
+func (g gitImpl) FileAtRef(filePath, ref string) (string, error) {
+	filePath = NormalizePath(filePath)
+	if !validateFilePath(filePath) {
+		return "", fmt.Errorf("invalid file path: %s", filePath)
+	}
+	if !validateGitRef(ref) {
+		return "", fmt.Errorf("invalid ref: %s", ref)
+	}
+	return g.runGitWithRefs([]string{ref}, func(r []string) []string {
+		return []string{"show", r[0] + ":" + filePath}
+	})
+}
+
+// ChangedGoFunctionsWithRefs returns the functions and methods in created and
+// modified Go files which contain added lines, or which had lines removed,
+// between baseRef and headRef.
+func (g gitImpl) ChangedGoFunctionsWithRefs(baseRef, headRef string) ([]ChangedFunction, error) {
+	var changed []ChangedFunction
+	files := append(append([]FilePath{}, g.ModifiedFiles()...), g.CreatedFiles()...)
+	for _, file := range files {
+		if !strings.HasSuffix(file, ".go") {
+			continue
+		}
+		diff, err := g.DiffForFileWithRefs(file, baseRef, headRef)
+		if err != nil {
+			return nil, err
+		}
+		head, err := g.FileAtRef(file, headRef)
+		if err != nil {
+			return nil, err
+		}
+		// The base version is missing for created files
+		base := ""
+		if len(diff.RemovedLines) > 0 {
+			if base, err = g.FileAtRef(file, baseRef); err != nil {
+				return nil, err
+			}
+		}
+		fns, err := changedGoFunctions(file, base, head, diff)
+		if err != nil {
+			return nil, err
+		}
+		changed = append(changed, fns...)
+	}
+	return changed, nil
+}
+
+// goFunc is a function declaration and the lines it spans.
+type goFunc struct {
+	name, receiver     string
+	docStart, docEnd   int
+	declStart, declEnd int
+	// nth counts the previous declarations of the same name and receiver in
+	// the file, as init and _ may be declared several times.
+	nth int
+}
+
+// key identifies the declaration in both versions of the file. Declarations
+// sharing a name are told apart by their position among them, rather than by
+// their lines which the diff shifts.
+func (f goFunc) key() string {
+	if f.nth == 0 {
+		return f.receiver + "." + f.name
+	}
+	return fmt.Sprintf("%s.%s#%d", f.receiver, f.name, f.nth)
+}
+
+// parseGoFuncs returns the function declarations in src.
+func parseGoFuncs(filename, src string) ([]goFunc, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filename, err)
+	}
+	var fns []goFunc
+	seen := map[string]int{}
+	for _, decl := range f.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		fn := goFunc{
+			name:      fd.Name.Name,
+			receiver:  receiverName(fd),
+			declStart: fset.Position(fd.Pos()).Line,
+			declEnd:   fset.Position(fd.End()).Line,
+		}
+		fn.nth = seen[fn.key()]
+		seen[fn.key()]++
+		if fd.Doc != nil {
+			fn.docStart = fset.Position(fd.Doc.Pos()).Line
+			fn.docEnd = fset.Position(fd.Doc.End()).Line
+		}
+		fns = append(fns, fn)
+	}
+	return fns, nil
+}
+
+// receiverName returns the base type name of a method receiver.
+func receiverName(fd *ast.FuncDecl) string {
+	if fd.Recv == nil || len(fd.Recv.List) == 0 {
+		return ""
+	}
+	expr := fd.Recv.List[0].Type
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.ParenExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// changedGoFunctions maps added lines onto the functions of the head version
+// and removed lines onto the functions of the base version. Functions which
+// only exist at the base ref are not reported.
+func changedGoFunctions(file FilePath, base, head string, diff FileDiff) ([]ChangedFunction, error) {
+	headFns, err := parseGoFuncs(file, head)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := map[string]*ChangedFunction{}
+	var order []string
+	touch := func(fn goFunc) *ChangedFunction {
+		if c, ok := byKey[fn.key()]; ok {
+			return c
+		}
+		c := &ChangedFunction{
+			File:      file,
+			Name:      fn.name,
+			Receiver:  fn.receiver,
+			Exported:  ast.IsExported(fn.name),
+			StartLine: fn.declStart,
+			EndLine:   fn.declEnd,
+			HasDoc:    fn.docStart > 0,
+		}
+		byKey[fn.key()] = c
+		order = append(order, fn.key())
+		return c
+	}
+
+	for _, l := range diff.AddedLines {
+		for _, fn := range headFns {
+			switch {
+			case fn.docStart > 0 && l.Line >= fn.docStart && l.Line <= fn.docEnd:
+				touch(fn).DocChanged = true
+			case l.Line >= fn.declStart && l.Line <= fn.declEnd:
+				touch(fn).BodyChanged = true
+			}
+		}
+	}
+
+	if len(diff.RemovedLines) > 0 && base != "" {
+		baseFns, err := parseGoFuncs(file, base)
+		if err != nil {
+			return nil, err
+		}
+		headByKey := map[string]goFunc{}
+		for _, fn := range headFns {
+			headByKey[fn.key()] = fn
+		}
+		for _, l := range diff.RemovedLines {
+			for _, fn := range baseFns {
+				headFn, exists := headByKey[fn.key()]
+				if !exists {
+					continue
+				}
+				switch {
+				case fn.docStart > 0 && l.Line >= fn.docStart && l.Line <= fn.docEnd:
+					touch(headFn).DocChanged = true
+				case l.Line >= fn.declStart && l.Line <= fn.declEnd:
+					touch(headFn).BodyChanged = true
+				}
+			}
+		}
+	}
+
+	changed := make([]ChangedFunction, 0, len(order))
+	for _, k := range order {
+		changed = append(changed, *byKey[k])
+	}
+	sort.SliceStable(changed, func(i, j int) bool {
+		return changed[i].StartLine < changed[j].StartLine
+	})
+	return changed, nil
+}
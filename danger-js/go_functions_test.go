@@ -0,0 +1,103 @@
+package dangerJs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const goFunctionsBase = `package demo
+
+// Add adds.
+func Add(a, b int) int {
+	return a + b
+}
+
+func (s *Server) stop() {
+	s.done = true
+}
+`
+
+const goFunctionsHead = `package demo
+
+// Add returns the sum of a and b.
+func Add(a, b int) int {
+	return a + b
+}
+
+func (s *Server) stop() {
+}
+
+func New[T any]() *List[T] {
+	return &List[T]{}
+}
+
+func (l *List[T]) Len() int { return 0 }
+`
+
+func TestChangedGoFunctions(t *testing.T) {
+	diff := FileDiff{
+		AddedLines: []DiffLine{
+			{Content: "// Add returns the sum of a and b.", Line: 3},
+			{Content: "", Line: 10},
+			{Content: "func New[T any]() *List[T] {", Line: 11},
+			{Content: "\treturn &List[T]{}", Line: 12},
+			{Content: "}", Line: 13},
+			{Content: "", Line: 14},
+			{Content: "func (l *List[T]) Len() int { return 0 }", Line: 15},
+		},
+		RemovedLines: []DiffLine{
+			{Content: "// Add adds.", Line: 3},
+			{Content: "\ts.done = true", Line: 9},
+		},
+	}
+
+	got, err := changedGoFunctions("demo.go", goFunctionsBase, goFunctionsHead, diff)
+	require.Nil(t, err)
+	require.Equal(t, []ChangedFunction{
+		{File: "demo.go", Name: "Add", Exported: true, StartLine: 4, EndLine: 6, HasDoc: true, DocChanged: true},
+		{File: "demo.go", Name: "stop", Receiver: "Server", StartLine: 8, EndLine: 9, BodyChanged: true},
+		{File: "demo.go", Name: "New", Exported: true, StartLine: 11, EndLine: 13, BodyChanged: true},
+		{File: "demo.go", Name: "Len", Receiver: "List", Exported: true, StartLine: 15, EndLine: 15, BodyChanged: true},
+	}, got)
+	require.Equal(t, "List.Len", got[3].QualifiedName())
+	require.Equal(t, "Add", got[0].QualifiedName())
+}
+
+func TestChangedGoFunctionsParseError(t *testing.T) {
+	_, err := changedGoFunctions("bad.go", "", "package", FileDiff{})
+	require.NotNil(t, err)
+}
+
+func TestChangedGoFunctionsRepeatedNames(t *testing.T) {
+	base := `package demo
+
+func init() {
+	a = 1
+}
+
+func init() {
+	b = 1
+}
+`
+	head := `package demo
+
+func init() {
+	a = 2
+}
+
+func init() {
+}
+`
+	diff := FileDiff{
+		AddedLines:   []DiffLine{{Content: "\ta = 2", Line: 4}},
+		RemovedLines: []DiffLine{{Content: "\ta = 1", Line: 4}, {Content: "\tb = 1", Line: 8}},
+	}
+
+	got, err := changedGoFunctions("demo.go", base, head, diff)
+	require.Nil(t, err)
+	require.Equal(t, []ChangedFunction{
+		{File: "demo.go", Name: "init", StartLine: 3, EndLine: 5, BodyChanged: true},
+		{File: "demo.go", Name: "init", StartLine: 7, EndLine: 8, BodyChanged: true},
+	}, got)
+}
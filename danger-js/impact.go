@@ -0,0 +1,179 @@
+package dangerJs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Impact reports the build targets affected by the changes of the PR, e.g.
+// to require reviews from the owners of affected targets or to summarize the
+// blast radius of a change in a monorepo.
+type Impact interface {
+	// AffectedTargets returns the targets depending on any changed file.
+	AffectedTargets() ([]string, error)
+	// TargetsOf returns the targets depending on file.
+	TargetsOf(file string) ([]string, error)
+}
+
+// ImpactFile is a precomputed impact analysis, e.g. produced by a previous
+// CI step with a build graph tool.
+type ImpactFile struct {
+	Targets []string            `json:"targets"`
+	Files   map[string][]string `json:"files,omitempty"`
+}
+
+// AffectedTargets returns the targets of the file.
+func (f ImpactFile) AffectedTargets() ([]string, error) {
+	return f.Targets, nil
+}
+
+// TargetsOf returns the targets the file lists for file.
+func (f ImpactFile) TargetsOf(file string) ([]string, error) {
+	return f.Files[NormalizePath(file)], nil
+}
+
+// LoadImpactFile reads an ImpactFile from its JSON encoding at path.
+func LoadImpactFile(path string) (ImpactFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ImpactFile{}, fmt.Errorf("reading impact file: %w", err)
+	}
+	var f ImpactFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return ImpactFile{}, fmt.Errorf("decoding impact file %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// BazelImpact finds the affected targets with `bazel query rdeps`. Queries
+// run lazily and their results are cached.
+type BazelImpact struct {
+	// Files are the changed files, relative to the workspace root.
+	Files []string
+	// Dir is the workspace root, the working directory when empty.
+	Dir string
+	// Universe bounds the query, "//..." when empty.
+	Universe string
+	// Bazel is the bazel binary, "bazel" when empty.
+	Bazel string
+
+	// run runs bazel, stubbed in tests.
+	run func(dir, bazel string, args ...string) ([]byte, error)
+
+	mu    sync.Mutex
+	cache map[string][]string
+}
+
+// NewBazelImpact creates a BazelImpact for the changed files run in dir.
+func NewBazelImpact(dir string, files []string) *BazelImpact {
+	return &BazelImpact{Dir: dir, Files: files}
+}
+
+// AffectedTargets returns the targets depending on any of Files.
+func (b *BazelImpact) AffectedTargets() ([]string, error) {
+	return b.query(b.Files)
+}
+
+// TargetsOf returns the targets depending on file.
+func (b *BazelImpact) TargetsOf(file string) ([]string, error) {
+	return b.query([]string{file})
+}
+
+func (b *BazelImpact) query(files []string) ([]string, error) {
+	var quoted []string
+	for _, f := range files {
+		f = NormalizePath(f)
+		if !validateFilePath(f) || strings.ContainsAny(f, `"'`) {
+			return nil, fmt.Errorf("invalid file path: %s", f)
+		}
+		quoted = append(quoted, `"`+f+`"`)
+	}
+	if len(quoted) == 0 {
+		return nil, nil
+	}
+	slices.Sort(quoted)
+	universe := b.Universe
+	if universe == "" {
+		universe = "//..."
+	}
+	expr := fmt.Sprintf("rdeps(%s, set(%s))", universe, strings.Join(quoted, " "))
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if targets, ok := b.cache[expr]; ok {
+		return targets, nil
+	}
+	bazel, run := b.Bazel, b.run
+	if bazel == "" {
+		bazel = "bazel"
+	}
+	if run == nil {
+		run = runBazel
+	}
+	out, err := run(b.Dir, bazel, "query", "--output=label", "--keep_going", expr)
+	if err != nil {
+		return nil, err
+	}
+	var targets []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			targets = append(targets, line)
+		}
+	}
+	slices.Sort(targets)
+	if b.cache == nil {
+		b.cache = map[string][]string{}
+	}
+	b.cache[expr] = targets
+	return targets, nil
+}
+
+// bazelPartialResults is the exit code of a query run with --keep_going which
+// skipped some targets, e.g. files deleted by the PR.
+const bazelPartialResults = 3
+
+func runBazel(dir, bazel string, args ...string) ([]byte, error) {
+	cmd := exec.Command(bazel, args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == bazelPartialResults {
+		return out, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bazel %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// WithImpact exposes the impact analysis i as DSL.Impact.
+func WithImpact(i Impact) DSLOption {
+	return func(d *DSL) {
+		d.Impact = i
+	}
+}
+
+// WithBazelImpact exposes the targets affected by the created and modified
+// files as DSL.Impact, queried with bazel in the repository of the DSL.
+func WithBazelImpact() DSLOption {
+	return func(d *DSL) {
+		if d.Git == nil {
+			return
+		}
+		dir := ""
+		if g, ok := d.Git.(gitImpl); ok {
+			dir = g.dir
+		}
+		files := append(slices.Clone(d.Git.CreatedFiles()), d.Git.ModifiedFiles()...)
+		d.Impact = NewBazelImpact(dir, files)
+	}
+}
@@ -0,0 +1,66 @@
+package dangerJs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBazelImpact(t *testing.T) {
+	var queries []string
+	b := NewBazelImpact("/repo", []string{"pkg/b.go", `pkg\a.go`})
+	b.run = func(dir, bazel string, args ...string) ([]byte, error) {
+		require.Equal(t, "/repo", dir)
+		require.Equal(t, "bazel", bazel)
+		require.Equal(t, []string{"query", "--output=label", "--keep_going"}, args[:3])
+		queries = append(queries, args[3])
+		return []byte("//pkg:pkg_test\n//pkg:pkg\n\n"), nil
+	}
+
+	targets, err := b.AffectedTargets()
+	require.NoError(t, err)
+	require.Equal(t, []string{"//pkg:pkg", "//pkg:pkg_test"}, targets)
+
+	_, err = b.AffectedTargets()
+	require.NoError(t, err)
+	_, err = b.TargetsOf("pkg/a.go")
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`rdeps(//..., set("pkg/a.go" "pkg/b.go"))`,
+		`rdeps(//..., set("pkg/a.go"))`,
+	}, queries)
+
+	_, err = b.TargetsOf(`pkg/"x") + //secret:all + set("y`)
+	require.Error(t, err)
+	_, err = b.TargetsOf("../outside.go")
+	require.Error(t, err)
+}
+
+func TestImpactFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "impact.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"targets":["//api:api"],"files":{"api/a.go":["//api:api"]}}`), 0o600))
+
+	f, err := LoadImpactFile(path)
+	require.NoError(t, err)
+	targets, err := f.AffectedTargets()
+	require.NoError(t, err)
+	require.Equal(t, []string{"//api:api"}, targets)
+	targets, err = f.TargetsOf(`api\a.go`)
+	require.NoError(t, err)
+	require.Equal(t, []string{"//api:api"}, targets)
+
+	dsl := DSLData{}.ToInterface(WithImpact(f))
+	require.Equal(t, f, dsl.Impact)
+}
+
+func TestWithBazelImpact(t *testing.T) {
+	data := DSLData{}
+	data.Git = gitImpl{CreatedFilesList: []FilePath{"new.go"}, ModifiedFilesList: []FilePath{"old.go"}}
+	dsl := data.ToInterface(WithRepoPath("/repo"), WithBazelImpact())
+	b, ok := dsl.Impact.(*BazelImpact)
+	require.True(t, ok)
+	require.Equal(t, "/repo", b.Dir)
+	require.Equal(t, []string{"new.go", "old.go"}, b.Files)
+}
@@ -0,0 +1,33 @@
+package dangerJs
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// closingPattern matches GitHub's closing keywords followed by an issue of
+// the same repository, e.g. "Fixes #12".
+var closingPattern = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?)\s*:?\s+#(\d+)\b`)
+
+// LinkedIssues returns the issues of the same repository the PR description
+// closes with a keyword like "Fixes #12", once each and in order.
+func (d DSL) LinkedIssues() []int {
+	var body string
+	switch {
+	case d.GitHub != nil && d.GitHub.PR().Number != 0:
+		body = d.GitHub.PR().Body
+	case d.GitLab != nil && d.GitLab.MR().IID != 0:
+		body = d.GitLab.MR().Description
+	}
+	var issues []int
+	seen := map[int]bool{}
+	for _, m := range closingPattern.FindAllStringSubmatch(body, -1) {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || seen[n] {
+			continue
+		}
+		seen[n] = true
+		issues = append(issues, n)
+	}
+	return issues
+}
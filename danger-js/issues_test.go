@@ -0,0 +1,29 @@
+package dangerJs_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+func TestLinkedIssues(t *testing.T) {
+	tests := []struct {
+		body string
+		want []int
+	}{
+		{body: "", want: nil},
+		{body: "See #3", want: nil},
+		{body: "Fixes #12", want: []int{12}},
+		{body: "closes: #4, resolved #5 and fixes #4.\nClose #6", want: []int{4, 5, 6}},
+		{body: "prefixes #7", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			pr := dangerJs.DSL{GitHub: fakedsl.GitHub{PRData: dangerJs.GitHubPR{Number: 1, Body: tt.body}}}
+			require.Equal(t, tt.want, pr.LinkedIssues())
+		})
+	}
+}
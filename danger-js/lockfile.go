@@ -0,0 +1,215 @@
+package dangerJs
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// LockfileManifests maps the names of the lockfiles ParseLockfile reads to
+// the manifest next to them which they lock.
+var LockfileManifests = map[string]string{
+	"go.sum":            "go.mod",
+	"package-lock.json": "package.json",
+	"yarn.lock":         "package.json",
+}
+
+// IsLockfile reports whether ParseLockfile reads the file at path.
+func IsLockfile(filePath string) bool {
+	_, ok := LockfileManifests[path.Base(filePath)]
+	return ok
+}
+
+// ParseLockfile returns the versions of each package locked by a go.sum,
+// package-lock.json or yarn.lock file, by package name. A package may be
+// locked at several versions, e.g. nested node modules.
+func ParseLockfile(filePath, content string) (map[string][]string, error) {
+	var pkgs map[string][]string
+	var err error
+	switch path.Base(filePath) {
+	case "go.sum":
+		pkgs = parseGoSum(content)
+	case "package-lock.json":
+		pkgs, err = parsePackageLock(content)
+	case "yarn.lock":
+		pkgs = parseYarnLock(content)
+	default:
+		return nil, fmt.Errorf("%s is not a supported lockfile", filePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filePath, err)
+	}
+	for name, versions := range pkgs {
+		slices.Sort(versions)
+		pkgs[name] = slices.Compact(versions)
+	}
+	return pkgs, nil
+}
+
+// parseGoSum reads "module version[/go.mod] hash" lines.
+func parseGoSum(content string) map[string][]string {
+	pkgs := map[string][]string{}
+	sc := bufio.NewScanner(strings.NewReader(content))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		pkgs[fields[0]] = append(pkgs[fields[0]], strings.TrimSuffix(fields[1], "/go.mod"))
+	}
+	return pkgs
+}
+
+type packageLockDep struct {
+	Version      string                    `json:"version"`
+	Link         bool                      `json:"link"`
+	Dependencies map[string]packageLockDep `json:"dependencies"`
+}
+
+// parsePackageLock reads the "packages" of lockfile versions 2 and 3, or the
+// nested "dependencies" of version 1.
+func parsePackageLock(content string) (map[string][]string, error) {
+	var lock struct {
+		Packages     map[string]packageLockDep `json:"packages"`
+		Dependencies map[string]packageLockDep `json:"dependencies"`
+	}
+	if err := json.Unmarshal([]byte(content), &lock); err != nil {
+		return nil, err
+	}
+	pkgs := map[string][]string{}
+	if lock.Packages != nil {
+		for key, p := range lock.Packages {
+			i := strings.LastIndex(key, "node_modules/")
+			if i < 0 || p.Link || p.Version == "" {
+				// the root package or a workspace
+				continue
+			}
+			name := key[i+len("node_modules/"):]
+			pkgs[name] = append(pkgs[name], p.Version)
+		}
+		return pkgs, nil
+	}
+	var walk func(deps map[string]packageLockDep)
+	walk = func(deps map[string]packageLockDep) {
+		for name, d := range deps {
+			if d.Version != "" {
+				pkgs[name] = append(pkgs[name], d.Version)
+			}
+			walk(d.Dependencies)
+		}
+	}
+	walk(lock.Dependencies)
+	return pkgs, nil
+}
+
+// parseYarnLock reads the entries of yarn.lock files of Yarn 1, e.g.
+//
+//	"lodash@^4.17.20", lodash@^4.17.21:
+//	  version "4.17.21"
+//
+// and of later versions, which quote differently and use "version: 4.17.21".
+func parseYarnLock(content string) map[string][]string {
+	pkgs := map[string][]string{}
+	var names []string
+	sc := bufio.NewScanner(strings.NewReader(content))
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), " \r")
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case line[0] != ' ' && strings.HasSuffix(line, ":"):
+			names = nil
+			for _, spec := range strings.Split(strings.TrimSuffix(line, ":"), ",") {
+				spec = strings.Trim(strings.TrimSpace(spec), `"`)
+				// the name ends at the @ of the range, past the one of a scope
+				if i := strings.Index(spec[min(1, len(spec)):], "@"); i >= 0 {
+					spec = spec[:i+1]
+				}
+				if spec != "" && spec != "__metadata" && !slices.Contains(names, spec) {
+					names = append(names, spec)
+				}
+			}
+		case strings.HasPrefix(line, "  version") && len(names) > 0:
+			version := strings.TrimSpace(strings.TrimPrefix(line, "  version"))
+			version = strings.Trim(strings.TrimSpace(strings.TrimPrefix(version, ":")), `"`)
+			for _, name := range names {
+				pkgs[name] = append(pkgs[name], version)
+			}
+			names = nil
+		}
+	}
+	return pkgs
+}
+
+// LockfileDiff summarizes the packages a change of a lockfile adds, removes
+// and updates, sorted by name. The From and To of the changes list every
+// locked version, separated by commas.
+type LockfileDiff struct {
+	File    string
+	Added   []DependencyChange
+	Removed []DependencyChange
+	Updated []DependencyChange
+}
+
+// Empty reports whether no package changed, e.g. when only hashes did.
+func (l LockfileDiff) Empty() bool {
+	return len(l.Added) == 0 && len(l.Removed) == 0 && len(l.Updated) == 0
+}
+
+// Summary counts the changes, e.g. "3 added, 1 updated".
+func (l LockfileDiff) Summary() string {
+	var parts []string
+	for _, c := range []struct {
+		n    int
+		verb string
+	}{{len(l.Added), "added"}, {len(l.Removed), "removed"}, {len(l.Updated), "updated"}} {
+		if c.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c.n, c.verb))
+		}
+	}
+	if len(parts) == 0 {
+		return "no package changes"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// DiffLockfiles compares the base and head content of a lockfile. Either may
+// be empty for created and deleted lockfiles.
+func DiffLockfiles(filePath, base, head string) (LockfileDiff, error) {
+	from, err := ParseLockfile(filePath, base)
+	if err != nil && strings.TrimSpace(base) != "" {
+		return LockfileDiff{}, err
+	}
+	to, err := ParseLockfile(filePath, head)
+	if err != nil && strings.TrimSpace(head) != "" {
+		return LockfileDiff{}, err
+	}
+
+	names := make([]string, 0, len(from)+len(to))
+	for name := range from {
+		names = append(names, name)
+	}
+	for name := range to {
+		if _, ok := from[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	diff := LockfileDiff{File: filePath}
+	for _, name := range names {
+		before, after := from[name], to[name]
+		change := DependencyChange{Name: name, From: strings.Join(before, ", "), To: strings.Join(after, ", ")}
+		switch {
+		case len(before) == 0:
+			diff.Added = append(diff.Added, change)
+		case len(after) == 0:
+			diff.Removed = append(diff.Removed, change)
+		case !slices.Equal(before, after):
+			diff.Updated = append(diff.Updated, change)
+		}
+	}
+	return diff, nil
+}
@@ -0,0 +1,122 @@
+package dangerJs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLockfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    map[string][]string
+	}{
+		{
+			name: "go.sum",
+			file: "go.sum",
+			content: "github.com/stretchr/testify v1.8.4 h1:abc=\n" +
+				"github.com/stretchr/testify v1.8.4/go.mod h1:def=\n" +
+				"github.com/stretchr/testify v1.9.0/go.mod h1:ghi=\n" +
+				"gopkg.in/yaml.v3 v3.0.1 h1:jkl=\n",
+			want: map[string][]string{
+				"github.com/stretchr/testify": {"v1.8.4", "v1.9.0"},
+				"gopkg.in/yaml.v3":            {"v3.0.1"},
+			},
+		},
+		{
+			name: "package-lock v3",
+			file: "web/package-lock.json",
+			content: `{"lockfileVersion": 3, "packages": {
+				"": {"name": "web", "version": "1.0.0"},
+				"node_modules/lodash": {"version": "4.17.21"},
+				"node_modules/@babel/core": {"version": "7.24.0"},
+				"node_modules/@babel/core/node_modules/semver": {"version": "6.3.1"},
+				"node_modules/semver": {"version": "7.6.0"},
+				"node_modules/shared": {"link": true, "resolved": "packages/shared"}
+			}}`,
+			want: map[string][]string{
+				"lodash":      {"4.17.21"},
+				"@babel/core": {"7.24.0"},
+				"semver":      {"6.3.1", "7.6.0"},
+			},
+		},
+		{
+			name: "package-lock v1",
+			file: "package-lock.json",
+			content: `{"lockfileVersion": 1, "dependencies": {
+				"lodash": {"version": "4.17.20"},
+				"debug": {"version": "4.3.4", "dependencies": {"ms": {"version": "2.1.2"}}}
+			}}`,
+			want: map[string][]string{
+				"lodash": {"4.17.20"},
+				"debug":  {"4.3.4"},
+				"ms":     {"2.1.2"},
+			},
+		},
+		{
+			name: "yarn v1",
+			file: "yarn.lock",
+			content: "# THIS IS AN AUTOGENERATED FILE.\n\n" +
+				"\"@babel/code-frame@^7.0.0\", \"@babel/code-frame@^7.10.4\":\n" +
+				"  version \"7.12.13\"\n" +
+				"  resolved \"https://registry.yarnpkg.com/@babel/code-frame/-/code-frame-7.12.13.tgz\"\n" +
+				"  dependencies:\n" +
+				"    \"@babel/highlight\" \"^7.10.4\"\n\n" +
+				"lodash@^4.17.20, lodash@^4.17.21:\n" +
+				"  version \"4.17.21\"\n",
+			want: map[string][]string{
+				"@babel/code-frame": {"7.12.13"},
+				"lodash":            {"4.17.21"},
+			},
+		},
+		{
+			name: "yarn berry",
+			file: "yarn.lock",
+			content: "__metadata:\n  version: 8\n\n" +
+				"\"lodash@npm:^4.17.21\":\n" +
+				"  version: 4.17.21\n" +
+				"  resolution: \"lodash@npm:4.17.21\"\n",
+			want: map[string][]string{"lodash": {"4.17.21"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLockfile(tt.file, tt.content)
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseLockfileErrors(t *testing.T) {
+	_, err := ParseLockfile("Cargo.lock", "")
+	require.NotNil(t, err)
+	_, err = ParseLockfile("package-lock.json", "{")
+	require.NotNil(t, err)
+}
+
+func TestDiffLockfiles(t *testing.T) {
+	base := "a.example/x v1.0.0 h1:=\nb.example/y v0.1.0 h1:=\nc.example/z v2.0.0 h1:=\n"
+	head := "a.example/x v1.1.0 h1:=\nc.example/z v2.0.0 h1:changed=\nd.example/w v0.3.0 h1:=\n"
+
+	diff, err := DiffLockfiles("go.sum", base, head)
+	require.Nil(t, err)
+	require.Equal(t, LockfileDiff{
+		File:    "go.sum",
+		Added:   []DependencyChange{{Name: "d.example/w", To: "v0.3.0"}},
+		Removed: []DependencyChange{{Name: "b.example/y", From: "v0.1.0"}},
+		Updated: []DependencyChange{{Name: "a.example/x", From: "v1.0.0", To: "v1.1.0"}},
+	}, diff)
+	require.Equal(t, "1 added, 1 removed, 1 updated", diff.Summary())
+
+	diff, err = DiffLockfiles("package-lock.json", "", `{"packages": {"node_modules/ms": {"version": "2.1.3"}}}`)
+	require.Nil(t, err)
+	require.Equal(t, []DependencyChange{{Name: "ms", To: "2.1.3"}}, diff.Added)
+
+	diff, err = DiffLockfiles("go.sum", base, base)
+	require.Nil(t, err)
+	require.True(t, diff.Empty())
+	require.Equal(t, "no package changes", diff.Summary())
+}
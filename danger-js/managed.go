@@ -0,0 +1,310 @@
+package dangerJs
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	// ManagedEnv makes danger-go run the danger-js binary pinned in the lock
+	// file, installing it on first use, rather than the one in PATH.
+	ManagedEnv = "DANGER_JS_MANAGED"
+	// LockFileEnv overrides the lock file path, LockFile by default.
+	LockFileEnv = "DANGER_JS_LOCK"
+	// DownloadURLEnv overrides DefaultDownloadURL, e.g. for a mirror.
+	DownloadURLEnv = "DANGER_JS_DOWNLOAD_URL"
+
+	// LockFile pins the danger-js version and the checksums of its binaries.
+	LockFile = ".danger-js.lock"
+	// DefaultDownloadURL is the standalone binary of a danger-js release.
+	// {version} and {os} (linux, macos or win) are replaced.
+	DefaultDownloadURL = "https://github.com/danger/danger-js/releases/download/{version}/danger-{os}.zip"
+)
+
+// ErrChecksumMismatch is returned when a danger-js binary doesn't match the
+// checksum pinned in the lock file.
+var ErrChecksumMismatch = errors.New("danger-js checksum mismatch")
+
+// Lock is the content of the lock file.
+type Lock struct {
+	Version string `json:"version"`
+	// SHA256 maps each platform, as os/arch, to the hex checksum of its
+	// binary. The checksum of a platform is recorded on its first install.
+	SHA256 map[string]string `json:"sha256,omitempty"`
+}
+
+// ReadLock reads a lock file.
+func ReadLock(path string) (Lock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Lock{}, fmt.Errorf("reading lock file: %w", err)
+	}
+	var l Lock
+	if err := json.Unmarshal(data, &l); err != nil {
+		return Lock{}, fmt.Errorf("decoding lock file %s: %w", path, err)
+	}
+	if l.Version == "" {
+		return Lock{}, fmt.Errorf("lock file %s has no version", path)
+	}
+	return l, nil
+}
+
+// WriteLock writes a lock file.
+func WriteLock(path string, l Lock) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding lock file: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing lock file: %w", err)
+	}
+	return nil
+}
+
+// LockPath returns the lock file in use, $DANGER_JS_LOCK or LockFile.
+func LockPath() string {
+	if p := os.Getenv(LockFileEnv); p != "" {
+		return p
+	}
+	return LockFile
+}
+
+// Installer downloads danger-js releases into a cache following the tool
+// cache layout of GitHub Actions: <dir>/danger-js/<version>/<arch>, with an
+// <arch>.complete marker once installed.
+type Installer struct {
+	// CacheDir is the cache root, $RUNNER_TOOL_CACHE on Actions and the user
+	// cache directory otherwise.
+	CacheDir string
+	// URL is the download URL template, DefaultDownloadURL when empty.
+	URL    string
+	Client *http.Client
+	GOOS   string
+	GOARCH string
+}
+
+// NewInstaller returns an Installer for the current platform.
+func NewInstaller() Installer {
+	dir := os.Getenv("RUNNER_TOOL_CACHE")
+	if dir == "" {
+		if cache, err := os.UserCacheDir(); err == nil {
+			dir = filepath.Join(cache, "danger-go")
+		}
+	}
+	return Installer{
+		CacheDir: dir,
+		URL:      os.Getenv(DownloadURLEnv),
+		Client:   http.DefaultClient,
+		GOOS:     runtime.GOOS,
+		GOARCH:   runtime.GOARCH,
+	}
+}
+
+// dir is the cache directory of version.
+func (i Installer) dir(version string) string {
+	return filepath.Join(i.CacheDir, "danger-js", version, i.arch())
+}
+
+// Platform returns the os/arch key of the installer's checksum in a Lock.
+func (i Installer) Platform() string {
+	return i.GOOS + "/" + i.GOARCH
+}
+
+// arch names the architecture like the Actions tool cache.
+func (i Installer) arch() string {
+	if i.GOARCH == "amd64" {
+		return "x64"
+	}
+	return i.GOARCH
+}
+
+// Path returns where the binary of version is installed.
+func (i Installer) Path(version string) string {
+	name := dangerJsBinary
+	if i.GOOS == "windows" {
+		name += ".exe"
+	}
+	return filepath.Join(i.dir(version), name)
+}
+
+func (i Installer) downloadURL(version string) string {
+	u := i.URL
+	if u == "" {
+		u = DefaultDownloadURL
+	}
+	platform := map[string]string{"darwin": "macos", "windows": "win"}[i.GOOS]
+	if platform == "" {
+		platform = i.GOOS
+	}
+	return strings.NewReplacer("{version}", version, "{os}", platform).Replace(u)
+}
+
+// Installed reports whether version is in the cache.
+func (i Installer) Installed(version string) bool {
+	_, err := os.Stat(i.dir(version) + ".complete")
+	return err == nil
+}
+
+// Install makes sure the binary of l.Version is in the cache, downloading it
+// when missing, and checks it against the checksum of its platform in
+// l.SHA256. When l has none, the returned lock has the one of the binary so
+// the caller can pin it.
+func (i Installer) Install(ctx context.Context, l Lock) (Lock, string, error) {
+	fresh := !i.Installed(l.Version)
+	if fresh {
+		if err := i.download(ctx, l.Version); err != nil {
+			return l, "", err
+		}
+	}
+	sum, err := i.Verify(l)
+	if err != nil {
+		if fresh {
+			// don't keep a binary which doesn't match the pin
+			_ = os.RemoveAll(i.dir(l.Version))
+			_ = os.Remove(i.dir(l.Version) + ".complete")
+		}
+		return l, "", err
+	}
+	l.SHA256 = maps.Clone(l.SHA256)
+	if l.SHA256 == nil {
+		l.SHA256 = map[string]string{}
+	}
+	l.SHA256[i.Platform()] = sum
+	return l, i.Path(l.Version), nil
+}
+
+// Verify checks the installed binary of l.Version against the checksum of
+// its platform in l.SHA256 and returns its checksum.
+func (i Installer) Verify(l Lock) (string, error) {
+	if !i.Installed(l.Version) {
+		return "", fmt.Errorf("danger-js %s is not installed, run danger-go js install", l.Version)
+	}
+	sum, err := fileSHA256(i.Path(l.Version))
+	if err != nil {
+		return "", err
+	}
+	if want := l.SHA256[i.Platform()]; want != "" && !strings.EqualFold(sum, want) {
+		return "", fmt.Errorf("%w: %s has %s, the lock file pins %s for %s", ErrChecksumMismatch, i.Path(l.Version), sum, want, i.Platform())
+	}
+	return sum, nil
+}
+
+func (i Installer) download(ctx context.Context, version string) error {
+	u := i.downloadURL(version)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	client := i.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("downloading danger-js: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("downloading danger-js from %s: %s", u, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("downloading danger-js: %w", err)
+	}
+
+	if err := os.MkdirAll(i.dir(version), 0o755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	if err := extractBinary(data, i.Path(version)); err != nil {
+		return err
+	}
+	if err := os.WriteFile(i.dir(version)+".complete", nil, 0o644); err != nil {
+		return fmt.Errorf("marking danger-js installed: %w", err)
+	}
+	return nil
+}
+
+// extractBinary writes the danger executable of a release archive to dst.
+func extractBinary(archive []byte, dst string) error {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return fmt.Errorf("opening danger-js archive: %w", err)
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !strings.HasPrefix(filepath.Base(f.Name), dangerJsBinary) {
+			continue
+		}
+		src, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("extracting %s: %w", f.Name, err)
+		}
+		defer src.Close()
+		tmp := dst + ".tmp"
+		out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o755)
+		if err != nil {
+			return fmt.Errorf("extracting %s: %w", f.Name, err)
+		}
+		if _, err := io.Copy(out, src); err != nil {
+			out.Close()
+			return fmt.Errorf("extracting %s: %w", f.Name, err)
+		}
+		if err := out.Close(); err != nil {
+			return fmt.Errorf("extracting %s: %w", f.Name, err)
+		}
+		return os.Rename(tmp, dst)
+	}
+	return fmt.Errorf("danger-js archive has no danger binary")
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("hashing danger-js: %w", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("hashing danger-js: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// managed reports whether DANGER_JS_MANAGED is enabled.
+func managed() bool {
+	switch strings.ToLower(os.Getenv(ManagedEnv)) {
+	case "", "0", "false", "no":
+		return false
+	}
+	return true
+}
+
+// dangerJsBin returns the danger-js binary to run: the managed one pinned by
+// the lock file when DANGER_JS_MANAGED is set, otherwise the one in PATH.
+func dangerJsBin() (string, error) {
+	if !managed() {
+		return findBinary(dangerJsBinary)
+	}
+	l, err := ReadLock(LockPath())
+	if err != nil {
+		return "", err
+	}
+	inst := NewInstaller()
+	if l.SHA256[inst.Platform()] == "" {
+		return "", fmt.Errorf("lock file %s pins no checksum for %s, run danger-go js install", LockPath(), inst.Platform())
+	}
+	_, bin, err := inst.Install(context.Background(), l)
+	return bin, err
+}
@@ -0,0 +1,86 @@
+package dangerJs
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func releaseZip(t *testing.T, binary string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("danger-linux/danger")
+	require.NoError(t, err)
+	_, err = w.Write([]byte(binary))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestInstaller(t *testing.T) {
+	archive := releaseZip(t, "#!/bin/sh\necho danger\n")
+	downloads := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/11.3.1/danger-linux.zip", r.URL.Path)
+		downloads++
+		_, _ = w.Write(archive)
+	}))
+	defer srv.Close()
+
+	inst := Installer{CacheDir: t.TempDir(), URL: srv.URL + "/{version}/danger-{os}.zip", GOOS: "linux", GOARCH: "amd64"}
+	require.Equal(t, filepath.Join(inst.CacheDir, "danger-js", "11.3.1", "x64", "danger"), inst.Path("11.3.1"))
+
+	// trusted on first use
+	lock, bin, err := inst.Install(context.Background(), Lock{Version: "11.3.1"})
+	require.NoError(t, err)
+	require.Equal(t, inst.Path("11.3.1"), bin)
+	require.Len(t, lock.SHA256["linux/amd64"], 64)
+	require.FileExists(t, filepath.Join(inst.CacheDir, "danger-js", "11.3.1", "x64.complete"))
+
+	// cached installs are verified without downloading
+	_, _, err = inst.Install(context.Background(), lock)
+	require.NoError(t, err)
+	require.Equal(t, 1, downloads)
+
+	require.NoError(t, os.WriteFile(bin, []byte("tampered"), 0o755))
+	_, err = inst.Verify(lock)
+	require.ErrorIs(t, err, ErrChecksumMismatch)
+}
+
+func TestInstallerChecksumMismatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(releaseZip(t, "other binary"))
+	}))
+	defer srv.Close()
+
+	inst := Installer{CacheDir: t.TempDir(), URL: srv.URL + "/{version}", GOOS: "linux", GOARCH: "arm64"}
+	_, _, err := inst.Install(context.Background(), Lock{Version: "11.3.1", SHA256: map[string]string{"linux/arm64": "00ff"}})
+	require.ErrorIs(t, err, ErrChecksumMismatch)
+	require.False(t, inst.Installed("11.3.1"))
+
+	// the checksums of other platforms don't apply
+	lock, _, err := inst.Install(context.Background(), Lock{Version: "11.3.1", SHA256: map[string]string{"linux/amd64": "00ff"}})
+	require.NoError(t, err)
+	require.Equal(t, "00ff", lock.SHA256["linux/amd64"])
+	require.Len(t, lock.SHA256["linux/arm64"], 64)
+}
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockFile)
+	lock := Lock{Version: "11.3.1", SHA256: map[string]string{"linux/amd64": "abc", "darwin/arm64": "def"}}
+	require.NoError(t, WriteLock(path, lock))
+	l, err := ReadLock(path)
+	require.NoError(t, err)
+	require.Equal(t, lock, l)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{}`), 0o644))
+	_, err = ReadLock(path)
+	require.Error(t, err)
+}
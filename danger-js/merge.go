@@ -0,0 +1,115 @@
+package dangerJs
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// MergeConflicts returns the files conflicting when merging headRef into
+// baseRef, nil when they merge cleanly. The merge is done by `git merge-tree`,
+// which needs git 2.38 or later, without touching the working tree.
+func (g gitImpl) MergeConflicts(baseRef, headRef string) ([]FilePath, error) {
+	if !validateGitRef(baseRef) {
+		return nil, fmt.Errorf("invalid base ref: %s", baseRef)
+	}
+	if !validateGitRef(headRef) {
+		return nil, fmt.Errorf("invalid head ref: %s", headRef)
+	}
+
+	out, err := g.runGitWithRefs([]string{baseRef, headRef}, func(r []string) []string {
+		return []string{"merge-tree", "--write-tree", "--name-only", "--no-messages", "-z", r[0], r[1]}
+	})
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return nil, nil
+	case !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 || out == "":
+		// git also exits with 1 on unknown refs, without output
+		return nil, err
+	}
+	// conflicts are reported by the tree ID followed by the conflicting
+	// paths, each NUL terminated
+	var files []FilePath
+	entries := strings.Split(out, "\x00")
+	for _, f := range entries[1:] {
+		if f == "" {
+			break
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// CommitsBehind returns how many commits baseRef has advanced since headRef
+// branched off it, counted with `git rev-list --count`. It needs the history
+// of both refs back to their merge base.
+func (g gitImpl) CommitsBehind(baseRef, headRef string) (int, error) {
+	if !validateGitRef(baseRef) {
+		return 0, fmt.Errorf("invalid base ref: %s", baseRef)
+	}
+	if !validateGitRef(headRef) {
+		return 0, fmt.Errorf("invalid head ref: %s", headRef)
+	}
+	out, err := g.runGitWithRefs([]string{baseRef, headRef}, func(r []string) []string {
+		return []string{"rev-list", "--count", r[1] + ".." + r[0]}
+	})
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(out))
+	if err != nil {
+		return 0, fmt.Errorf("parsing commit count %q: %w", out, err)
+	}
+	return n, nil
+}
+
+// Mergeability is whether the PR or MR can be merged, as reported by GitHub
+// or GitLab.
+type Mergeability struct {
+	// Known is false while the platform computes the state, and outside of
+	// GitHub and GitLab.
+	Known bool
+	// Conflicting reports merge conflicts with the base branch.
+	Conflicting bool
+	// NeedsRebase reports the head must be rebased onto or updated with the
+	// base branch before merging, e.g. when branch protection requires it.
+	NeedsRebase bool
+	// State is the state of the platform, the GitHub mergeable_state or the
+	// GitLab detailed_merge_status.
+	State string
+}
+
+// Mergeability returns whether the PR or MR can be merged.
+func (d DSL) Mergeability() Mergeability {
+	if d.GitHub != nil && d.GitHub.PR().Number != 0 {
+		pr := d.GitHub.PR()
+		m := Mergeability{State: pr.MergeableState}
+		if pr.Mergeable == nil {
+			return m
+		}
+		m.Known = true
+		m.Conflicting = !*pr.Mergeable || pr.MergeableState == "dirty"
+		m.NeedsRebase = pr.MergeableState == "behind"
+		return m
+	}
+	if d.GitLab != nil && d.GitLab.MR().IID != 0 {
+		mr := d.GitLab.MR()
+		state := mr.DetailedMergeStatus
+		if state == "" {
+			state = mr.MergeStatus
+		}
+		m := Mergeability{State: state}
+		switch state {
+		case "", "checking", "unchecked", "preparing", "approvals_syncing":
+			return m
+		}
+		m.Known = true
+		m.Conflicting = mr.HasConflicts || state == "conflict" || state == "cannot_be_merged"
+		m.NeedsRebase = state == "need_rebase"
+		return m
+	}
+	return Mergeability{}
+}
@@ -0,0 +1,139 @@
+package dangerJs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeConflicts(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	git(t, dir, "init", "-q", "-b", "main")
+	write("a.txt", "a\n")
+	write("b.txt", "b\n")
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "base")
+	git(t, dir, "checkout", "-q", "-b", "feature")
+	write("a.txt", "feature\n")
+	write("c.txt", "c\n")
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "feature")
+	git(t, dir, "checkout", "-q", "-b", "clean", "main")
+	write("b.txt", "clean\n")
+	git(t, dir, "commit", "-q", "-am", "clean")
+	git(t, dir, "checkout", "-q", "main")
+	write("a.txt", "main\n")
+	git(t, dir, "commit", "-q", "-am", "main")
+
+	var data DSLData
+	pr := data.ToInterface(WithRepoPath(dir))
+	if _, err := MergeConflicts(pr.Git, "main", "clean"); err != nil {
+		t.Skipf("git merge-tree --write-tree unsupported: %s", err)
+	}
+
+	files, err := MergeConflicts(pr.Git, "main", "feature")
+	require.NoError(t, err)
+	require.Equal(t, []FilePath{"a.txt"}, files)
+
+	files, err = MergeConflicts(pr.Git, "main", "clean")
+	require.NoError(t, err)
+	require.Nil(t, files)
+
+	_, err = MergeConflicts(pr.Git, "main", "missing")
+	require.Error(t, err)
+}
+
+func TestCommitsBehind(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	commit := func(msg string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "log.txt"), []byte(msg), 0o644))
+		git(t, dir, "add", ".")
+		git(t, dir, "commit", "-q", "-m", msg)
+	}
+	git(t, dir, "init", "-q", "-b", "main")
+	commit("base")
+	git(t, dir, "checkout", "-q", "-b", "feature")
+	commit("feature")
+	git(t, dir, "checkout", "-q", "main")
+	commit("main 1")
+	commit("main 2")
+
+	var data DSLData
+	pr := data.ToInterface(WithRepoPath(dir))
+	n, err := CommitsBehind(pr.Git, "main", "feature")
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	n, err = CommitsBehind(pr.Git, "feature", "main")
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	_, err = CommitsBehind(pr.Git, "main", "missing")
+	require.Error(t, err)
+}
+
+func TestMergeability(t *testing.T) {
+	yes, no := true, false
+	mr := func(detailed, legacy string, conflicts bool) gitLabImpl {
+		var m GitLabMR
+		m.IID = 2
+		m.DetailedMergeStatus = detailed
+		m.MergeStatus = legacy
+		m.HasConflicts = conflicts
+		return gitLabImpl{MRData: m}
+	}
+	tests := []struct {
+		name string
+		data DSLData
+		want Mergeability
+	}{
+		{name: "no platform", want: Mergeability{}},
+		{
+			name: "GitHub computing",
+			data: DSLData{GitHub: gitHubImpl{PRData: GitHubPR{Number: 1, MergeableState: "unknown"}}},
+			want: Mergeability{State: "unknown"},
+		},
+		{
+			name: "GitHub conflicts",
+			data: DSLData{GitHub: gitHubImpl{PRData: GitHubPR{Number: 1, Mergeable: &no, MergeableState: "dirty"}}},
+			want: Mergeability{Known: true, Conflicting: true, State: "dirty"},
+		},
+		{
+			name: "GitHub behind",
+			data: DSLData{GitHub: gitHubImpl{PRData: GitHubPR{Number: 1, Mergeable: &yes, MergeableState: "behind"}}},
+			want: Mergeability{Known: true, NeedsRebase: true, State: "behind"},
+		},
+		{
+			name: "GitLab conflicts",
+			data: DSLData{GitLab: mr("conflict", "cannot_be_merged", true)},
+			want: Mergeability{Known: true, Conflicting: true, State: "conflict"},
+		},
+		{
+			name: "GitLab needs rebase",
+			data: DSLData{GitLab: mr("need_rebase", "can_be_merged", false)},
+			want: Mergeability{Known: true, NeedsRebase: true, State: "need_rebase"},
+		},
+		{
+			name: "GitLab legacy status",
+			data: DSLData{GitLab: mr("", "can_be_merged", false)},
+			want: Mergeability{Known: true, State: "can_be_merged"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.data.ToInterface().Mergeability())
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package dangerJs
+
+import (
+	"strings"
+)
+
+// DefaultMovedBlockLines is the minimum number of consecutive lines which
+// must match for a block to be considered moved.
+const DefaultMovedBlockLines = 3
+
+// MovedCode records the added and removed lines which belong to a block of
+// code that was moved, within the same file or between files, rather than
+// written or deleted.
+type MovedCode struct {
+	added   map[FilePath]map[int]bool
+	removed map[FilePath]map[int]bool
+}
+
+// IsMovedAdded reports whether the added line at the head ref was moved from
+// elsewhere.
+func (m MovedCode) IsMovedAdded(file FilePath, line int) bool {
+	return m.added[file][line]
+}
+
+// IsMovedRemoved reports whether the removed line at the base ref was moved
+// somewhere else.
+func (m MovedCode) IsMovedRemoved(file FilePath, line int) bool {
+	return m.removed[file][line]
+}
+
+// NewAddedLines returns the added lines of a file's diff which were not moved,
+// i.e. the code which is genuinely new.
+func (m MovedCode) NewAddedLines(file FilePath, diff FileDiff) []DiffLine {
+	var lines []DiffLine
+	for _, l := range diff.AddedLines {
+		if !m.IsMovedAdded(file, l.Line) {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+type lineRef struct {
+	file FilePath
+	line int
+}
+
+// DetectMovedCode matches blocks of at least minLines consecutive removed
+// lines against identical blocks of added lines in any of the given diffs.
+// Leading and trailing whitespace is ignored so that re-indented code still
+// matches. A minLines below one uses DefaultMovedBlockLines.
+func DetectMovedCode(diffs map[FilePath]FileDiff, minLines int) MovedCode {
+	if minLines < 1 {
+		minLines = DefaultMovedBlockLines
+	}
+	m := MovedCode{
+		added:   map[FilePath]map[int]bool{},
+		removed: map[FilePath]map[int]bool{},
+	}
+
+	// Index every window of removed lines by its content
+	removedWindows := map[string][][]lineRef{}
+	for file, diff := range diffs {
+		for _, block := range consecutiveBlocks(diff.RemovedLines) {
+			for _, w := range windows(file, block, minLines) {
+				removedWindows[w.key] = append(removedWindows[w.key], w.refs)
+			}
+		}
+	}
+	if len(removedWindows) == 0 {
+		return m
+	}
+
+	for file, diff := range diffs {
+		for _, block := range consecutiveBlocks(diff.AddedLines) {
+			for _, w := range windows(file, block, minLines) {
+				matches, ok := removedWindows[w.key]
+				if !ok {
+					continue
+				}
+				mark(m.added, w.refs)
+				for _, refs := range matches {
+					mark(m.removed, refs)
+				}
+			}
+		}
+	}
+	return m
+}
+
+func mark(set map[FilePath]map[int]bool, refs []lineRef) {
+	for _, r := range refs {
+		if set[r.file] == nil {
+			set[r.file] = map[int]bool{}
+		}
+		set[r.file][r.line] = true
+	}
+}
+
+// consecutiveBlocks splits lines into runs with consecutive line numbers.
+func consecutiveBlocks(lines []DiffLine) [][]DiffLine {
+	var blocks [][]DiffLine
+	start := 0
+	for i := 1; i <= len(lines); i++ {
+		if i == len(lines) || lines[i].Line != lines[i-1].Line+1 {
+			blocks = append(blocks, lines[start:i])
+			start = i
+		}
+	}
+	return blocks
+}
+
+type window struct {
+	key  string
+	refs []lineRef
+}
+
+// windows returns every run of size lines in block, skipping runs made only of
+// blank lines since they match trivially.
+func windows(file FilePath, block []DiffLine, size int) []window {
+	var ww []window
+	for i := 0; i+size <= len(block); i++ {
+		parts := make([]string, size)
+		refs := make([]lineRef, size)
+		blank := true
+		for j := 0; j < size; j++ {
+			parts[j] = strings.TrimSpace(block[i+j].Content)
+			refs[j] = lineRef{file: file, line: block[i+j].Line}
+			blank = blank && parts[j] == ""
+		}
+		if blank {
+			continue
+		}
+		ww = append(ww, window{key: strings.Join(parts, "\n"), refs: refs})
+	}
+	return ww
+}
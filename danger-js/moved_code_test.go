@@ -0,0 +1,69 @@
+package dangerJs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectMovedCode(t *testing.T) {
+	diffs := map[FilePath]FileDiff{
+		"old.go": {
+			RemovedLines: []DiffLine{
+				{Content: "func helper() {", Line: 10},
+				{Content: "\tsecret := load()", Line: 11},
+				{Content: "\treturn secret", Line: 12},
+				{Content: "}", Line: 13},
+				{Content: "// unrelated", Line: 20},
+			},
+		},
+		"new.go": {
+			AddedLines: []DiffLine{
+				{Content: "// brand new", Line: 1},
+				{Content: "func helper() {", Line: 5},
+				{Content: "    secret := load()", Line: 6},
+				{Content: "    return secret", Line: 7},
+				{Content: "}", Line: 8},
+			},
+		},
+	}
+
+	m := DetectMovedCode(diffs, 3)
+
+	for _, line := range []int{5, 6, 7, 8} {
+		require.True(t, m.IsMovedAdded("new.go", line), "line %d", line)
+	}
+	require.False(t, m.IsMovedAdded("new.go", 1))
+
+	for _, line := range []int{10, 11, 12, 13} {
+		require.True(t, m.IsMovedRemoved("old.go", line), "line %d", line)
+	}
+	require.False(t, m.IsMovedRemoved("old.go", 20))
+
+	require.Equal(t,
+		[]DiffLine{{Content: "// brand new", Line: 1}},
+		m.NewAddedLines("new.go", diffs["new.go"]))
+}
+
+func TestDetectMovedCodeShortBlocks(t *testing.T) {
+	diffs := map[FilePath]FileDiff{
+		"a.go": {
+			RemovedLines: []DiffLine{{Content: "}", Line: 3}, {Content: "", Line: 4}},
+			AddedLines:   []DiffLine{{Content: "}", Line: 8}, {Content: "", Line: 9}},
+		},
+	}
+
+	m := DetectMovedCode(diffs, 3)
+	require.False(t, m.IsMovedAdded("a.go", 8))
+	require.False(t, m.IsMovedRemoved("a.go", 3))
+}
+
+func TestDetectMovedCodeIgnoresBlankBlocks(t *testing.T) {
+	blank := []DiffLine{{Line: 1}, {Line: 2}, {Line: 3}}
+	diffs := map[FilePath]FileDiff{
+		"a.go": {RemovedLines: blank, AddedLines: blank},
+	}
+
+	m := DetectMovedCode(diffs, 0)
+	require.False(t, m.IsMovedAdded("a.go", 1))
+}
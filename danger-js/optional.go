@@ -0,0 +1,301 @@
+package dangerJs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// The Git, GitHub, GitLab and Settings interfaces only have the methods every
+// DSL has. The functions below reach the other capabilities of the DSL built
+// from danger-js through optional methods, so other implementations, such as
+// the fakes of fakedsl, only need the methods they use. They fail with an
+// error wrapping errors.ErrUnsupported, or fall back to the data of the
+// interface, when the optional method is missing.
+
+// unsupported is the error of calling method on v, which lacks it.
+func unsupported(v any, method string) error {
+	return fmt.Errorf("%T has no %s method: %w", v, method, errors.ErrUnsupported)
+}
+
+// LastCommit returns the latest commit of the PR, and whether it has commits.
+// Unlike Commits, it doesn't decode every commit of a streamed DSL.
+func LastCommit(g Git) (GitCommit, bool) {
+	if m, ok := g.(interface{ LastCommit() (GitCommit, bool) }); ok {
+		return m.LastCommit()
+	}
+	commits := g.Commits()
+	if len(commits) == 0 {
+		return GitCommit{}, false
+	}
+	return commits[len(commits)-1], true
+}
+
+// FileModeChanges returns the mode changes between HEAD^ and HEAD.
+func FileModeChanges(g Git) ([]FileModeChange, error) {
+	return FileModeChangesWithRefs(g, "HEAD^", "HEAD")
+}
+
+// FileModeChangesWithRefs returns the paths whose mode changed between
+// baseRef and headRef.
+func FileModeChangesWithRefs(g Git, baseRef, headRef string) ([]FileModeChange, error) {
+	m, ok := g.(interface {
+		FileModeChangesWithRefs(baseRef, headRef string) ([]FileModeChange, error)
+	})
+	if !ok {
+		return nil, unsupported(g, "FileModeChangesWithRefs")
+	}
+	return m.FileModeChangesWithRefs(baseRef, headRef)
+}
+
+// WordDiffForFile returns the word diff of a file between HEAD^ and HEAD.
+func WordDiffForFile(g Git, filePath string) (WordDiff, error) {
+	return WordDiffForFileWithRefs(g, filePath, "HEAD^", "HEAD")
+}
+
+// WordDiffForFileWithRefs returns the word diff of a file between baseRef
+// and headRef.
+func WordDiffForFileWithRefs(g Git, filePath, baseRef, headRef string) (WordDiff, error) {
+	m, ok := g.(interface {
+		WordDiffForFileWithRefs(filePath, baseRef, headRef string) (WordDiff, error)
+	})
+	if !ok {
+		return WordDiff{}, unsupported(g, "WordDiffForFileWithRefs")
+	}
+	return m.WordDiffForFileWithRefs(filePath, baseRef, headRef)
+}
+
+// FileAtRef returns the content of a file at ref.
+func FileAtRef(g Git, filePath, ref string) (string, error) {
+	m, ok := g.(interface {
+		FileAtRef(filePath, ref string) (string, error)
+	})
+	if !ok {
+		return "", unsupported(g, "FileAtRef")
+	}
+	return m.FileAtRef(filePath, ref)
+}
+
+// ChangedGoFunctions returns the Go functions changed between HEAD^ and HEAD.
+func ChangedGoFunctions(g Git) ([]ChangedFunction, error) {
+	return ChangedGoFunctionsWithRefs(g, "HEAD^", "HEAD")
+}
+
+// ChangedGoFunctionsWithRefs returns the Go functions changed between
+// baseRef and headRef.
+func ChangedGoFunctionsWithRefs(g Git, baseRef, headRef string) ([]ChangedFunction, error) {
+	m, ok := g.(interface {
+		ChangedGoFunctionsWithRefs(baseRef, headRef string) ([]ChangedFunction, error)
+	})
+	if !ok {
+		return nil, unsupported(g, "ChangedGoFunctionsWithRefs")
+	}
+	return m.ChangedGoFunctionsWithRefs(baseRef, headRef)
+}
+
+// Blame returns the authorship of every line of a file at ref.
+func Blame(g Git, filePath, ref string) ([]BlameLine, error) {
+	m, ok := g.(interface {
+		Blame(filePath, ref string) ([]BlameLine, error)
+	})
+	if !ok {
+		return nil, unsupported(g, "Blame")
+	}
+	return m.Blame(filePath, ref)
+}
+
+// CommitsForFile returns up to limit of the latest commits changing a file.
+func CommitsForFile(g Git, filePath string, limit int) ([]GitCommit, error) {
+	m, ok := g.(interface {
+		CommitsForFile(filePath string, limit int) ([]GitCommit, error)
+	})
+	if !ok {
+		return nil, unsupported(g, "CommitsForFile")
+	}
+	return m.CommitsForFile(filePath, limit)
+}
+
+// CommitsForFileAtRef returns up to limit of the latest commits reachable
+// from ref changing a file.
+func CommitsForFileAtRef(g Git, filePath, ref string, limit int) ([]GitCommit, error) {
+	m, ok := g.(interface {
+		CommitsForFileAtRef(filePath, ref string, limit int) ([]GitCommit, error)
+	})
+	if !ok {
+		return nil, unsupported(g, "CommitsForFileAtRef")
+	}
+	return m.CommitsForFileAtRef(filePath, ref, limit)
+}
+
+// IsShallowClone reports whether the repository is a shallow clone.
+func IsShallowClone(g Git) (bool, error) {
+	m, ok := g.(interface {
+		IsShallowClone() (bool, error)
+	})
+	if !ok {
+		return false, unsupported(g, "IsShallowClone")
+	}
+	return m.IsShallowClone()
+}
+
+// ResolveRef returns the ref which ref names in the repository, e.g.
+// origin/main for main.
+func ResolveRef(g Git, ref string) (string, error) {
+	m, ok := g.(interface {
+		ResolveRef(ref string) (string, error)
+	})
+	if !ok {
+		return "", unsupported(g, "ResolveRef")
+	}
+	return m.ResolveRef(ref)
+}
+
+// RenamedFiles returns the files renamed between HEAD^ and HEAD.
+func RenamedFiles(g Git) ([]RenamedFile, error) {
+	return RenamedFilesWithRefs(g, "HEAD^", "HEAD")
+}
+
+// RenamedFilesWithRefs returns the files renamed between baseRef and
+// headRef.
+func RenamedFilesWithRefs(g Git, baseRef, headRef string) ([]RenamedFile, error) {
+	m, ok := g.(interface {
+		RenamedFilesWithRefs(baseRef, headRef string) ([]RenamedFile, error)
+	})
+	if !ok {
+		return nil, unsupported(g, "RenamedFilesWithRefs")
+	}
+	return m.RenamedFilesWithRefs(baseRef, headRef)
+}
+
+// MergeConflicts returns the files which conflict when merging headRef into
+// baseRef.
+func MergeConflicts(g Git, baseRef, headRef string) ([]FilePath, error) {
+	m, ok := g.(interface {
+		MergeConflicts(baseRef, headRef string) ([]FilePath, error)
+	})
+	if !ok {
+		return nil, unsupported(g, "MergeConflicts")
+	}
+	return m.MergeConflicts(baseRef, headRef)
+}
+
+// CommitsBehind returns how many commits baseRef has advanced since headRef
+// branched off it. DSL.CommitsBehind falls back to the GitHub API.
+func CommitsBehind(g Git, baseRef, headRef string) (int, error) {
+	m, ok := g.(interface {
+		CommitsBehind(baseRef, headRef string) (int, error)
+	})
+	if !ok {
+		return 0, unsupported(g, "CommitsBehind")
+	}
+	return m.CommitsBehind(baseRef, headRef)
+}
+
+// GitHubCommits returns the commits of the PR, fetched from the API when the
+// DSL JSON lacks them, see WithGitHubFetcher.
+func GitHubCommits(ctx context.Context, gh GitHub) ([]GitHubCommit, error) {
+	if m, ok := gh.(interface {
+		CommitsContext(ctx context.Context) ([]GitHubCommit, error)
+	}); ok {
+		return m.CommitsContext(ctx)
+	}
+	return gh.Commits(), nil
+}
+
+// GitHubReviews returns the reviews of the PR, fetched from the API when the
+// DSL JSON lacks them, see WithGitHubFetcher.
+func GitHubReviews(ctx context.Context, gh GitHub) ([]GitHubReview, error) {
+	if m, ok := gh.(interface {
+		ReviewsContext(ctx context.Context) ([]GitHubReview, error)
+	}); ok {
+		return m.ReviewsContext(ctx)
+	}
+	return gh.Reviews(), nil
+}
+
+// Compare compares base and head, branch names or commit SHAs, in the
+// repository of the PR with the GitHub API. It fails with ErrNoComparer
+// when gh can't compare.
+func Compare(ctx context.Context, gh GitHub, base, head string) (GitHubComparison, error) {
+	m, ok := gh.(interface {
+		Compare(ctx context.Context, base, head string) (GitHubComparison, error)
+	})
+	if !ok {
+		return GitHubComparison{}, ErrNoComparer
+	}
+	return m.Compare(ctx, base, head)
+}
+
+// Timeline fetches the timeline events of the PR with the GitHub API. It
+// fails with ErrNoTimelineFetcher when gh can't fetch them.
+func Timeline(ctx context.Context, gh GitHub) (GitHubTimeline, error) {
+	m, ok := gh.(interface {
+		Timeline(ctx context.Context) (GitHubTimeline, error)
+	})
+	if !ok {
+		return nil, ErrNoTimelineFetcher
+	}
+	return m.Timeline(ctx)
+}
+
+// GitLabCommits returns the commits of the MR, fetched from the API when the
+// DSL JSON lacks them, see WithGitLabFetcher.
+func GitLabCommits(ctx context.Context, gl GitLab) ([]GitLabMRCommit, error) {
+	if m, ok := gl.(interface {
+		CommitsContext(ctx context.Context) ([]GitLabMRCommit, error)
+	}); ok {
+		return m.CommitsContext(ctx)
+	}
+	return gl.Commits(), nil
+}
+
+// GitLabApprovals returns the approvals of the MR, fetched from the API when
+// the DSL JSON lacks them, see WithGitLabFetcher.
+func GitLabApprovals(ctx context.Context, gl GitLab) (GitLabApproval, error) {
+	if m, ok := gl.(interface {
+		ApprovalsContext(ctx context.Context) (GitLabApproval, error)
+	}); ok {
+		return m.ApprovalsContext(ctx)
+	}
+	return gl.Approvals(), nil
+}
+
+// GitLabAccessToken returns the GitLab token of the settings,
+// DANGER_GITLAB_API_TOKEN by default.
+func GitLabAccessToken(s Settings) string {
+	if m, ok := s.(interface{ GitLabAccessToken() string }); ok {
+		return m.GitLabAccessToken()
+	}
+	return settingsImpl{}.GitLabAccessToken()
+}
+
+// GitLabBaseURL returns the GitLab instance of the settings, DANGER_GITLAB_HOST
+// or the CI_SERVER_URL of GitLab CI by default. Empty means gitlab.com.
+func GitLabBaseURL(s Settings) string {
+	if m, ok := s.(interface{ GitLabBaseURL() string }); ok {
+		return m.GitLabBaseURL()
+	}
+	return settingsImpl{}.GitLabBaseURL()
+}
+
+// BitbucketCloud returns the Bitbucket Cloud credentials of the settings,
+// each taken from the DANGER_BITBUCKETCLOUD_* variables by default.
+func BitbucketCloud(s Settings) BitbucketCloudCredentials {
+	if m, ok := s.(interface {
+		BitbucketCloud() BitbucketCloudCredentials
+	}); ok {
+		return m.BitbucketCloud()
+	}
+	return settingsImpl{}.BitbucketCloud()
+}
+
+// BitbucketServer returns the Bitbucket Server credentials of the settings,
+// each taken from the DANGER_BITBUCKETSERVER_* variables by default.
+func BitbucketServer(s Settings) BitbucketServerCredentials {
+	if m, ok := s.(interface {
+		BitbucketServer() BitbucketServerCredentials
+	}); ok {
+		return m.BitbucketServer()
+	}
+	return settingsImpl{}.BitbucketServer()
+}
@@ -0,0 +1,85 @@
+package dangerJs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+// bareGit, bareGitHub and bareGitLab only have the methods of the
+// interfaces, hiding the optional ones of what they wrap.
+type bareGit struct{ dangerJs.Git }
+
+type bareGitHub struct{ dangerJs.GitHub }
+
+type bareGitLab struct{ dangerJs.GitLab }
+
+func (bareGitLab) Commits() []dangerJs.GitLabMRCommit { return []dangerJs.GitLabMRCommit{{ID: "abc"}} }
+func (bareGitLab) Approvals() dangerJs.GitLabApproval { return dangerJs.GitLabApproval{ID: 2} }
+
+func TestOptionalGit(t *testing.T) {
+	g := fakedsl.Git{
+		Files:     map[string]string{"a.go": "package a\n"},
+		Conflicts: []string{"go.mod"},
+		Errs:      map[string]error{"CommitsBehind": dangerJs.ErrShallowClone},
+	}
+	content, err := dangerJs.FileAtRef(g, "a.go", "HEAD")
+	require.NoError(t, err)
+	require.Equal(t, "package a\n", content)
+	conflicts, err := dangerJs.MergeConflicts(g, "main", "HEAD")
+	require.NoError(t, err)
+	require.Equal(t, []string{"go.mod"}, conflicts)
+	_, err = dangerJs.CommitsBehind(g, "main", "HEAD")
+	require.ErrorIs(t, err, dangerJs.ErrShallowClone)
+
+	_, err = dangerJs.FileAtRef(bareGit{}, "a.go", "HEAD")
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+	require.ErrorContains(t, err, "dangerJs_test.bareGit has no FileAtRef method")
+	_, err = dangerJs.RenamedFiles(bareGit{})
+	require.ErrorIs(t, err, errors.ErrUnsupported)
+}
+
+func TestOptionalGitHub(t *testing.T) {
+	ctx := context.Background()
+	fake := fakedsl.GitHub{
+		CommitsList: []dangerJs.GitHubCommit{{SHA: "abc"}},
+		Comparison:  &dangerJs.GitHubComparison{BehindBy: 3},
+	}
+	gh := bareGitHub{fake}
+	commits, err := dangerJs.GitHubCommits(ctx, gh)
+	require.NoError(t, err)
+	require.Equal(t, fake.CommitsList, commits)
+	_, err = dangerJs.Compare(ctx, gh, "main", "HEAD")
+	require.ErrorIs(t, err, dangerJs.ErrNoComparer)
+	_, err = dangerJs.Timeline(ctx, gh)
+	require.ErrorIs(t, err, dangerJs.ErrNoTimelineFetcher)
+
+	c, err := dangerJs.Compare(ctx, fake, "main", "HEAD")
+	require.NoError(t, err)
+	require.Equal(t, 3, c.BehindBy)
+}
+
+func TestOptionalGitLab(t *testing.T) {
+	ctx := context.Background()
+	commits, err := dangerJs.GitLabCommits(ctx, bareGitLab{})
+	require.NoError(t, err)
+	require.Equal(t, []dangerJs.GitLabMRCommit{{ID: "abc"}}, commits)
+	approvals, err := dangerJs.GitLabApprovals(ctx, bareGitLab{})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), approvals.ID)
+}
+
+func TestOptionalSettings(t *testing.T) {
+	t.Setenv("DANGER_GITLAB_API_TOKEN", "env-token")
+	t.Setenv("DANGER_BITBUCKETSERVER_TOKEN", "env-bb-token")
+	require.Equal(t, "dsl-token", dangerJs.GitLabAccessToken(fakedsl.Settings{GitLabToken: "dsl-token"}))
+
+	type bareSettings struct{ dangerJs.Settings }
+	require.Equal(t, "env-token", dangerJs.GitLabAccessToken(bareSettings{}))
+	require.Equal(t, "env-bb-token", dangerJs.BitbucketServer(bareSettings{}).Token)
+}
@@ -0,0 +1,79 @@
+package dangerJs
+
+import (
+	"path"
+	"strconv"
+	"strings"
+)
+
+// NormalizePath converts Windows path separators to the forward slashes git
+// uses, so paths built on any platform compare equal to the DSL's file lists.
+func NormalizePath(p string) string {
+	return strings.ReplaceAll(p, `\`, "/")
+}
+
+// UnquotePath decodes a path quoted by git, as in the headers of diffs of
+// files with special or non-ASCII characters when core.quotepath is set, e.g.
+// "caf\303\251.txt" for café.txt. Unquoted paths are returned unchanged.
+func UnquotePath(p string) string {
+	if len(p) < 2 || p[0] != '"' || p[len(p)-1] != '"' {
+		return p
+	}
+	// git escapes like Go: \t, \", \\ and octal bytes
+	if unquoted, err := strconv.Unquote(p); err == nil {
+		return unquoted
+	}
+	return p
+}
+
+// MatchPath reports whether name matches the glob pattern. Both are
+// normalized with NormalizePath first. `*` matches within a single path
+// segment and `**` matches any number of segments, e.g. "cmd/**/*.go".
+func MatchPath(pattern, name string) bool {
+	pattern = strings.Trim(NormalizePath(pattern), "/")
+	name = strings.Trim(NormalizePath(name), "/")
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// isWindowsAbs reports whether p starts with a drive letter or is a UNC path,
+// which filepath.IsAbs does not detect when running on other platforms.
+func isWindowsAbs(p string) bool {
+	if strings.HasPrefix(p, "//") {
+		return true
+	}
+	return len(p) >= 2 && p[1] == ':' &&
+		(p[0] >= 'a' && p[0] <= 'z' || p[0] >= 'A' && p[0] <= 'Z')
+}
+
+// normalizeLineEndings converts git output which was translated to CRLF as a
+// whole, e.g. by a Windows pipe, back to LF. CRLF line endings that are part
+// of the file content are left alone, as git's own header lines always end
+// in LF.
+func normalizeLineEndings(out string) string {
+	if n := strings.Count(out, "\n"); n > 0 && strings.Count(out, "\r\n") == n {
+		return strings.ReplaceAll(out, "\r\n", "\n")
+	}
+	return out
+}
@@ -0,0 +1,48 @@
+package dangerJs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePath(t *testing.T) {
+	require.Equal(t, "src/pkg/main.go", NormalizePath(`src\pkg\main.go`))
+	require.Equal(t, "src/pkg/main.go", NormalizePath("src/pkg/main.go"))
+}
+
+func TestUnquotePath(t *testing.T) {
+	require.Equal(t, "café.txt", UnquotePath(`"caf\303\251.txt"`))
+	require.Equal(t, "tab\there.txt", UnquotePath(`"tab\there.txt"`))
+	require.Equal(t, `say "hi".txt`, UnquotePath(`"say \"hi\".txt"`))
+	require.Equal(t, "plain.txt", UnquotePath("plain.txt"))
+	require.Equal(t, `"broken\q"`, UnquotePath(`"broken\q"`))
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false},
+		{"cmd/*.go", `cmd\main.go`, true},
+		{`cmd\*.go`, "cmd/main.go", true},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "cmd/danger-go/main.go", true},
+		{"cmd/**", "cmd/danger-go/runner/runner.go", true},
+		{"cmd/**", "api.go", false},
+		{"cmd/**/runner.go", "cmd/runner.go", true},
+		{"cmd/**/runner.go", `cmd\danger-go\runner\runner.go`, true},
+		{"cmd/**/runner.go", "cmd/danger-go/runner/main.go", false},
+		{"**/testdata/**", "a/b/testdata/c/d.json", true},
+		{"migrations", "migrations", true},
+		{"migrations", "migrations/001.sql", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, MatchPath(tt.pattern, tt.name))
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package dangerJs
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// RefNotFoundError is returned when none of the refs a ref resolves to
+// exists in the local clone. It matches ErrRefNotFound with errors.Is.
+type RefNotFoundError struct {
+	Ref string
+	// Tried are the refs looked up, in order.
+	Tried []string
+}
+
+func (e *RefNotFoundError) Error() string {
+	return fmt.Sprintf("%s: %s (tried %s)", ErrRefNotFound, e.Ref, strings.Join(e.Tried, ", "))
+}
+
+func (e *RefNotFoundError) Is(target error) bool {
+	return target == ErrRefNotFound
+}
+
+// defaultRefRemotes are the remotes whose tracking branches refs resolve to,
+// see WithRefRemotes.
+var defaultRefRemotes = []string{"origin"}
+
+var commitIDRe = regexp.MustCompile(`^[0-9a-fA-F]{7,64}$`)
+
+// symbolicRefs are the refs git itself maintains, never a branch or tag.
+var symbolicRefs = []string{"HEAD", "FETCH_HEAD", "ORIG_HEAD", "MERGE_HEAD"}
+
+// refCandidates returns the refs ref may name, in the order they are tried:
+// ref itself, the tracking branch of each remote, e.g. origin/main for main,
+// and the tag of that name. Full refs, commit IDs, HEAD and revision
+// expressions such as HEAD^ are only tried as they are.
+func refCandidates(ref string, remotes []string) []string {
+	candidates := []string{ref}
+	if slices.Contains(symbolicRefs, ref) || strings.HasPrefix(ref, "refs/") || strings.ContainsAny(ref, "^~@:") || commitIDRe.MatchString(ref) {
+		return candidates
+	}
+	for _, remote := range remotes {
+		if strings.HasPrefix(ref, remote+"/") {
+			// already a tracking branch
+			return candidates
+		}
+	}
+	for _, remote := range remotes {
+		candidates = append(candidates, remote+"/"+ref)
+	}
+	return append(candidates, "refs/tags/"+ref)
+}
+
+// ResolveRef returns the ref which ref names in the local clone, e.g.
+// origin/main when only the tracking branch of main was fetched, as is usual
+// on CI, or refs/tags/v1.2.0 for v1.2.0. It returns a *RefNotFoundError
+// listing the refs it tried when none exists.
+func (g gitImpl) ResolveRef(ref string) (string, error) {
+	if !validateGitRef(ref) {
+		return "", fmt.Errorf("invalid ref: %s", ref)
+	}
+	candidates := refCandidates(ref, g.refRemotes())
+	for _, c := range candidates {
+		_, err := g.runGit("rev-parse", "--verify", "--quiet", c+"^{commit}")
+		if err == nil {
+			return c, nil
+		}
+		if errors.Is(err, ErrNoGitRepo) {
+			return "", err
+		}
+	}
+	return "", &RefNotFoundError{Ref: ref, Tried: candidates}
+}
+
+// refRemotes returns the remotes set with WithRefRemotes, origin by default.
+func (g gitImpl) refRemotes() []string {
+	if g.remotes == nil {
+		return defaultRefRemotes
+	}
+	return g.remotes
+}
+
+// resolveRefs resolves each of refs with ResolveRef.
+func (g gitImpl) resolveRefs(refs []string) ([]string, error) {
+	resolved := make([]string, len(refs))
+	for i, ref := range refs {
+		r, err := g.ResolveRef(ref)
+		if err != nil {
+			return nil, err
+		}
+		resolved[i] = r
+	}
+	return resolved, nil
+}
+
+// WithRefRemotes sets the remotes whose tracking branches refs given to the
+// DSL resolve to when no local branch exists, origin by default. With no
+// remotes, refs only resolve to local branches, tags and commits.
+func WithRefRemotes(remotes ...string) DSLOption {
+	return func(d *DSL) {
+		if g, ok := d.Git.(gitImpl); ok {
+			g.remotes = append([]string{}, remotes...)
+			d.Git = g
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package dangerJs
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefCandidates(t *testing.T) {
+	tests := []struct {
+		ref     string
+		remotes []string
+		want    []string
+	}{
+		{"main", []string{"origin"}, []string{"main", "origin/main", "refs/tags/main"}},
+		{"v1.2.0", []string{"origin", "upstream"}, []string{"v1.2.0", "origin/v1.2.0", "upstream/v1.2.0", "refs/tags/v1.2.0"}},
+		{"main", nil, []string{"main", "refs/tags/main"}},
+		{"origin/main", []string{"origin"}, []string{"origin/main"}},
+		{"refs/heads/main", []string{"origin"}, []string{"refs/heads/main"}},
+		{"HEAD", []string{"origin"}, []string{"HEAD"}},
+		{"HEAD^", []string{"origin"}, []string{"HEAD^"}},
+		{"main~2", []string{"origin"}, []string{"main~2"}},
+		{"0123abcd", []string{"origin"}, []string{"0123abcd"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			require.Equal(t, tt.want, refCandidates(tt.ref, tt.remotes))
+		})
+	}
+}
+
+// cloneWithTag creates a repository with a tagged commit followed by a
+// feature branch commit, and returns a clone of it with only the feature
+// branch checked out, as CI checkouts of PRs are.
+func cloneWithTag(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	origin := t.TempDir()
+	git(t, origin, "init", "-q", "-b", "main")
+	require.NoError(t, os.WriteFile(filepath.Join(origin, "a.txt"), []byte("one\n"), 0o644))
+	git(t, origin, "add", "a.txt")
+	git(t, origin, "commit", "-q", "-m", "add a")
+	git(t, origin, "tag", "-a", "v1.0.0", "-m", "v1.0.0")
+	git(t, origin, "checkout", "-q", "-b", "feature")
+	require.NoError(t, os.WriteFile(filepath.Join(origin, "a.txt"), []byte("two\n"), 0o644))
+	git(t, origin, "commit", "-q", "-am", "change a")
+
+	clone := filepath.Join(t.TempDir(), "clone")
+	git(t, origin, "clone", "-q", "-b", "feature", "file://"+filepath.ToSlash(origin), clone)
+	return clone
+}
+
+func TestResolveRef(t *testing.T) {
+	g := gitImpl{dir: cloneWithTag(t)}
+
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"feature", "feature"},
+		{"main", "origin/main"},
+		{"origin/main", "origin/main"},
+		{"v1.0.0", "v1.0.0"},
+		{"HEAD^", "HEAD^"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := g.ResolveRef(tt.ref)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := g.ResolveRef("release")
+	require.ErrorIs(t, err, ErrRefNotFound)
+	var notFound *RefNotFoundError
+	require.True(t, errors.As(err, &notFound))
+	require.Equal(t, []string{"release", "origin/release", "refs/tags/release"}, notFound.Tried)
+	require.ErrorContains(t, err, "tried release, origin/release, refs/tags/release")
+}
+
+func TestDiffWithResolvedRefs(t *testing.T) {
+	var data DSLData
+	pr := data.ToInterface(WithRepoPath(cloneWithTag(t)))
+
+	for _, base := range []string{"main", "v1.0.0"} {
+		diff, err := pr.Git.DiffForFileWithRefs("a.txt", base, "HEAD")
+		require.NoError(t, err)
+		require.Equal(t, []DiffLine{{Content: "two", Line: 1, Hunk: 1, Position: 2}}, diff.AddedLines)
+	}
+
+	content, err := FileAtRef(pr.Git, "a.txt", "main")
+	require.NoError(t, err)
+	require.Equal(t, "one\n", content)
+
+	_, err = pr.Git.DiffForFileWithRefs("a.txt", "release", "HEAD")
+	require.ErrorIs(t, err, ErrRefNotFound)
+}
+
+func TestWithRefRemotes(t *testing.T) {
+	var data DSLData
+	pr := data.ToInterface(WithRepoPath(cloneWithTag(t)), WithRefRemotes())
+
+	_, err := ResolveRef(pr.Git, "main")
+	var notFound *RefNotFoundError
+	require.True(t, errors.As(err, &notFound))
+	require.Equal(t, []string{"main", "refs/tags/main"}, notFound.Tried)
+}
@@ -0,0 +1,148 @@
+package dangerJs
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RenamedFile is a file git detected as moved between two refs.
+type RenamedFile struct {
+	From FilePath
+	To   FilePath
+	// Similarity is the percentage of the content shared by both versions,
+	// 100 for pure renames.
+	Similarity int
+}
+
+// RenamedFilesWithRefs returns the files renamed between baseRef and headRef,
+// using git's default rename detection threshold of 50% similarity.
+func (g gitImpl) RenamedFilesWithRefs(baseRef, headRef string) ([]RenamedFile, error) {
+	if !validateGitRef(baseRef) {
+		return nil, fmt.Errorf("invalid base ref: %s", baseRef)
+	}
+	if !validateGitRef(headRef) {
+		return nil, fmt.Errorf("invalid head ref: %s", headRef)
+	}
+
+	out, err := g.runGitWithRefs([]string{baseRef, headRef}, func(r []string) []string {
+		return []string{"diff", "--name-status", "-M", "-z", r[0], r[1]}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseNameStatus(out)
+}
+
+// parseNameStatus parses `git diff --name-status -M -z` output and returns the
+// renames.
+func parseNameStatus(out string) ([]RenamedFile, error) {
+	var renames []RenamedFile
+	fields := strings.Split(out, "\x00")
+	for i := 0; i < len(fields); i++ {
+		status := fields[i]
+		if status == "" {
+			continue
+		}
+		// renames and copies are followed by two paths, the rest by one
+		if status[0] != 'R' && status[0] != 'C' {
+			i++
+			continue
+		}
+		if i+2 >= len(fields) || fields[i+2] == "" {
+			return nil, fmt.Errorf("unexpected name-status entry: %q", status)
+		}
+		from, to := fields[i+1], fields[i+2]
+		i += 2
+		if status[0] == 'C' {
+			continue
+		}
+		similarity, err := strconv.Atoi(status[1:])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected name-status entry: %q", status)
+		}
+		renames = append(renames, RenamedFile{From: from, To: to, Similarity: similarity})
+	}
+	return renames, nil
+}
+
+// PackageMove is a Go package whose files moved to another directory, which
+// changes its import path.
+type PackageMove struct {
+	FromDir string
+	ToDir   string
+	// FromImport and ToImport are the import paths of both directories,
+	// empty when no module path was given.
+	FromImport string
+	ToImport   string
+	Files      []RenamedFile
+}
+
+// GoPackageMoves groups the renamed .go files by source and destination
+// directory. modulePath, e.g. from the go.mod module directive, is used to
+// compute the import paths and may be empty. Test files alone don't make a
+// move.
+func GoPackageMoves(renames []RenamedFile, modulePath string) []PackageMove {
+	type key struct{ from, to string }
+	moves := map[key]*PackageMove{}
+	for _, r := range renames {
+		if !strings.HasSuffix(r.To, ".go") || !strings.HasSuffix(r.From, ".go") {
+			continue
+		}
+		k := key{path.Dir(NormalizePath(r.From)), path.Dir(NormalizePath(r.To))}
+		if k.from == k.to {
+			continue
+		}
+		m, ok := moves[k]
+		if !ok {
+			m = &PackageMove{FromDir: k.from, ToDir: k.to}
+			if modulePath != "" {
+				m.FromImport = importPath(modulePath, k.from)
+				m.ToImport = importPath(modulePath, k.to)
+			}
+			moves[k] = m
+		}
+		m.Files = append(m.Files, r)
+	}
+
+	var out []PackageMove
+	for _, m := range moves {
+		for _, f := range m.Files {
+			if !strings.HasSuffix(f.To, "_test.go") {
+				out = append(out, *m)
+				break
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].FromDir != out[j].FromDir {
+			return out[i].FromDir < out[j].FromDir
+		}
+		return out[i].ToDir < out[j].ToDir
+	})
+	return out
+}
+
+func importPath(modulePath, dir string) string {
+	if dir == "." {
+		return modulePath
+	}
+	return modulePath + "/" + dir
+}
+
+// GoModulePath returns the module path declared in the contents of a go.mod
+// file, or "" when there is none.
+func GoModulePath(gomod string) string {
+	for _, line := range strings.Split(normalizeLineEndings(gomod), "\n") {
+		line = strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(line, "module"); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			if i := strings.Index(rest, "//"); i >= 0 {
+				rest = rest[:i]
+			}
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
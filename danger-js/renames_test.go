@@ -0,0 +1,77 @@
+package dangerJs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNameStatus(t *testing.T) {
+	renames, err := parseNameStatus("M\x00main.go\x00R100\x00old/a.go\x00new/a.go\x00" +
+		"C075\x00x.go\x00y.go\x00A\x00b.go\x00R087\x00README\x00README.md\x00")
+	require.NoError(t, err)
+	require.Equal(t, []RenamedFile{
+		{From: "old/a.go", To: "new/a.go", Similarity: 100},
+		{From: "README", To: "README.md", Similarity: 87},
+	}, renames)
+
+	_, err = parseNameStatus("R100\x00only-one\x00")
+	require.Error(t, err)
+}
+
+func TestRenamedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git(t, dir, "init", "-q")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "old"), 0o755))
+	content := "package old\n\nfunc A() int {\n\treturn 1\n}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "old", "a.go"), []byte(content), 0o644))
+	git(t, dir, "add", ".")
+	git(t, dir, "commit", "-q", "-m", "add")
+	git(t, dir, "mv", "old", "pkg")
+	git(t, dir, "commit", "-q", "-m", "move")
+
+	var data DSLData
+	pr := data.ToInterface(WithRepoPath(dir))
+	renames, err := RenamedFiles(pr.Git)
+	require.NoError(t, err)
+	require.Equal(t, []RenamedFile{{From: "old/a.go", To: "pkg/a.go", Similarity: 100}}, renames)
+}
+
+func TestGoPackageMoves(t *testing.T) {
+	renames := []RenamedFile{
+		{From: "internal/util/strings.go", To: "pkg/text/strings.go", Similarity: 100},
+		{From: "internal/util/strings_test.go", To: "pkg/text/strings_test.go", Similarity: 96},
+		{From: "a/a_test.go", To: "b/a_test.go", Similarity: 100},
+		{From: "api/api.go", To: "api/v1.go", Similarity: 100},
+		{From: "docs/a.md", To: "guide/a.md", Similarity: 100},
+		{From: "tool.go", To: "cmd/tool/main.go", Similarity: 80},
+	}
+	require.Equal(t, []PackageMove{
+		{
+			FromDir: ".", ToDir: "cmd/tool",
+			FromImport: "example.com/m", ToImport: "example.com/m/cmd/tool",
+			Files: renames[5:6],
+		},
+		{
+			FromDir: "internal/util", ToDir: "pkg/text",
+			FromImport: "example.com/m/internal/util", ToImport: "example.com/m/pkg/text",
+			Files: renames[0:2],
+		},
+	}, GoPackageMoves(renames, "example.com/m"))
+
+	moves := GoPackageMoves(renames[:1], "")
+	require.Len(t, moves, 1)
+	require.Empty(t, moves[0].FromImport)
+}
+
+func TestGoModulePath(t *testing.T) {
+	require.Equal(t, "github.com/danger/golang", GoModulePath("// comment\r\nmodule github.com/danger/golang // x\r\n\r\ngo 1.22\r\n"))
+	require.Equal(t, "example.com/m", GoModulePath(`module "example.com/m"`))
+	require.Empty(t, GoModulePath("go 1.22\nmodules x\n"))
+}
@@ -0,0 +1,169 @@
+package dangerJs
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Review states as reported by the GitHub API.
+const (
+	ReviewApproved         = "APPROVED"
+	ReviewChangesRequested = "CHANGES_REQUESTED"
+	ReviewCommented        = "COMMENTED"
+	ReviewDismissed        = "DISMISSED"
+	ReviewPending          = "PENDING"
+)
+
+// GitHubHelpers computes review decisions from the raw GitHub data of a PR.
+type GitHubHelpers struct {
+	reviews []GitHubReview
+	author  string
+	headSHA string
+}
+
+// GitHubHelpers returns the review helpers of the PR. Without GitHub data
+// they report no reviews.
+func (d DSL) GitHubHelpers() GitHubHelpers {
+	if d.GitHub == nil {
+		return GitHubHelpers{}
+	}
+	pr := d.GitHub.PR()
+	return GitHubHelpers{reviews: d.GitHub.Reviews(), author: pr.User.Login, headSHA: pr.Head.SHA}
+}
+
+// LatestReviewPerUser returns the review deciding each reviewer's stance,
+// sorted by login. Comments and pending reviews don't change a stance, so a
+// comment after an approval leaves the approval in place, while a dismissal
+// withdraws it and the reviewer is left out. Reviews by the PR author are
+// ignored.
+func (h GitHubHelpers) LatestReviewPerUser() []GitHubReview {
+	latest := map[string]GitHubReview{}
+	// the API lists reviews chronologically
+	for _, r := range h.reviews {
+		login := strings.ToLower(r.User.Login)
+		if login == "" || strings.EqualFold(r.User.Login, h.author) {
+			continue
+		}
+		switch r.State {
+		case ReviewApproved, ReviewChangesRequested:
+			latest[login] = r
+		case ReviewDismissed:
+			delete(latest, login)
+		}
+	}
+
+	out := make([]GitHubReview, 0, len(latest))
+	for _, r := range latest {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return strings.ToLower(out[i].User.Login) < strings.ToLower(out[j].User.Login)
+	})
+	return out
+}
+
+func (h GitHubHelpers) loginsWith(state string) []string {
+	var logins []string
+	for _, r := range h.LatestReviewPerUser() {
+		if r.State == state {
+			logins = append(logins, r.User.Login)
+		}
+	}
+	return logins
+}
+
+// ApprovedBy returns the reviewers currently approving the PR.
+func (h GitHubHelpers) ApprovedBy() []string {
+	return h.loginsWith(ReviewApproved)
+}
+
+// ChangesRequestedBy returns the reviewers currently requesting changes.
+func (h GitHubHelpers) ChangesRequestedBy() []string {
+	return h.loginsWith(ReviewChangesRequested)
+}
+
+// StaleApprovals returns the reviewers whose approval was given on an older
+// commit than the head of the PR. GitHub keeps these unless the branch
+// protection dismisses stale approvals.
+func (h GitHubHelpers) StaleApprovals() []string {
+	var logins []string
+	for _, r := range h.LatestReviewPerUser() {
+		if r.State == ReviewApproved && h.headSHA != "" && r.CommitID != "" && r.CommitID != h.headSHA {
+			logins = append(logins, r.User.Login)
+		}
+	}
+	return logins
+}
+
+// IsApproved reports whether at least minApprovals reviewers approve the PR
+// and nobody requests changes.
+func (h GitHubHelpers) IsApproved(minApprovals int) bool {
+	return len(h.ApprovedBy()) >= minApprovals && len(h.ChangesRequestedBy()) == 0
+}
+
+// GitLabHelpers computes the approval decision from the raw GitLab data of
+// an MR.
+type GitLabHelpers struct {
+	approvals GitLabApproval
+	author    string
+}
+
+// GitLabHelpers returns the approval helpers of the MR. Without GitLab data
+// they report no approvals.
+func (d DSL) GitLabHelpers() GitLabHelpers {
+	if d.GitLab == nil || d.GitLab.MR().IID == 0 {
+		return GitLabHelpers{}
+	}
+	return GitLabHelpers{approvals: d.GitLab.Approvals(), author: d.GitLab.MR().Author.Username}
+}
+
+// ApprovedBy returns the usernames of the users approving the MR, sorted.
+// Approvals by the MR author are ignored.
+func (h GitLabHelpers) ApprovedBy() []string {
+	var usernames []string
+	for _, u := range approvers(h.approvals.ApprovedBy) {
+		if u.Username != "" && !strings.EqualFold(u.Username, h.author) {
+			usernames = append(usernames, u.Username)
+		}
+	}
+	sort.Strings(usernames)
+	return usernames
+}
+
+// IsApproved reports whether at least minApprovals users approve the MR and
+// none of the approvals its rules require are left.
+func (h GitLabHelpers) IsApproved(minApprovals int) bool {
+	return len(h.ApprovedBy()) >= minApprovals && h.approvals.ApprovalsLeft <= 0
+}
+
+// approvers decodes the approved_by field of the GitLab approvals, a list of
+// {"user": user} entries, a single one, or a list of users.
+func approvers(v any) []GitLabUser {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		entries = []json.RawMessage{raw}
+	}
+	var users []GitLabUser
+	for _, e := range entries {
+		var wrapped struct {
+			User *GitLabUser `json:"user"`
+		}
+		if err := json.Unmarshal(e, &wrapped); err != nil {
+			continue
+		}
+		if wrapped.User != nil {
+			users = append(users, *wrapped.User)
+			continue
+		}
+		var u GitLabUser
+		if err := json.Unmarshal(e, &u); err == nil {
+			users = append(users, u)
+		}
+	}
+	return users
+}
@@ -0,0 +1,153 @@
+package dangerJs_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+func review(login, state, commit string) dangerJs.GitHubReview {
+	return dangerJs.GitHubReview{User: dangerJs.GitHubUser{Login: login}, State: state, CommitID: commit}
+}
+
+func TestGitHubHelpers(t *testing.T) {
+	tests := []struct {
+		name             string
+		reviews          []dangerJs.GitHubReview
+		approvedBy       []string
+		changesRequested []string
+		stale            []string
+		approved         bool
+	}{
+		{
+			name: "no reviews",
+		},
+		{
+			name: "comment after approval keeps it",
+			reviews: []dangerJs.GitHubReview{
+				review("ann", dangerJs.ReviewApproved, "head"),
+				review("ann", dangerJs.ReviewCommented, "head"),
+				review("bob", dangerJs.ReviewCommented, "head"),
+			},
+			approvedBy: []string{"ann"},
+			approved:   true,
+		},
+		{
+			name: "changes requested after approval",
+			reviews: []dangerJs.GitHubReview{
+				review("ann", dangerJs.ReviewApproved, "head"),
+				review("bob", dangerJs.ReviewApproved, "old"),
+				review("Ann", dangerJs.ReviewChangesRequested, "head"),
+			},
+			approvedBy:       []string{"bob"},
+			changesRequested: []string{"Ann"},
+			stale:            []string{"bob"},
+		},
+		{
+			name: "dismissed review is withdrawn",
+			reviews: []dangerJs.GitHubReview{
+				review("ann", dangerJs.ReviewChangesRequested, "old"),
+				review("ann", dangerJs.ReviewDismissed, "old"),
+				review("bob", dangerJs.ReviewApproved, "head"),
+				review("bob", dangerJs.ReviewPending, ""),
+			},
+			approvedBy: []string{"bob"},
+			approved:   true,
+		},
+		{
+			name: "author reviews are ignored",
+			reviews: []dangerJs.GitHubReview{
+				review("author", dangerJs.ReviewApproved, "head"),
+				review("ann", dangerJs.ReviewApproved, "head"),
+				review("bob", dangerJs.ReviewApproved, "head"),
+			},
+			approvedBy: []string{"ann", "bob"},
+			approved:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := dangerJs.GitHubPR{User: dangerJs.GitHubUser{Login: "Author"}}
+			pr.Head.SHA = "head"
+			h := dangerJs.DSL{GitHub: fakedsl.GitHub{PRData: pr, ReviewsList: tt.reviews}}.GitHubHelpers()
+			require.Equal(t, tt.approvedBy, h.ApprovedBy())
+			require.Equal(t, tt.changesRequested, h.ChangesRequestedBy())
+			require.Equal(t, tt.stale, h.StaleApprovals())
+			require.Equal(t, tt.approved, h.IsApproved(1))
+		})
+	}
+}
+
+func TestGitHubHelpersMinApprovals(t *testing.T) {
+	h := dangerJs.DSL{GitHub: fakedsl.GitHub{ReviewsList: []dangerJs.GitHubReview{
+		review("ann", dangerJs.ReviewApproved, ""),
+		review("ann", dangerJs.ReviewApproved, ""),
+	}}}.GitHubHelpers()
+	require.Len(t, h.LatestReviewPerUser(), 1)
+	require.True(t, h.IsApproved(1))
+	require.False(t, h.IsApproved(2))
+	require.True(t, dangerJs.DSL{}.GitHubHelpers().IsApproved(0))
+}
+
+func TestGitLabHelpers(t *testing.T) {
+	mr := dangerJs.GitLabMR{}
+	mr.IID = 4
+	mr.Author.Username = "ann"
+
+	tests := []struct {
+		name       string
+		approvedBy any
+		left       int
+		want       []string
+		approved   bool
+	}{
+		{
+			name: "no approvals",
+		},
+		{
+			name: "user entries",
+			approvedBy: []any{
+				map[string]any{"user": map[string]any{"username": "cat"}},
+				map[string]any{"user": map[string]any{"username": "bob"}},
+			},
+			want:     []string{"bob", "cat"},
+			approved: true,
+		},
+		{
+			name:       "single entry",
+			approvedBy: map[string]any{"user": map[string]any{"username": "bob"}},
+			want:       []string{"bob"},
+		},
+		{
+			name:       "users",
+			approvedBy: []any{map[string]any{"username": "bob"}, map[string]any{"username": "cat"}},
+			want:       []string{"bob", "cat"},
+			approved:   true,
+		},
+		{
+			name:       "author approval ignored",
+			approvedBy: []any{map[string]any{"username": "ann"}, map[string]any{"username": "bob"}},
+			want:       []string{"bob"},
+		},
+		{
+			name:       "approvals left",
+			approvedBy: []any{map[string]any{"username": "bob"}, map[string]any{"username": "cat"}},
+			left:       1,
+			want:       []string{"bob", "cat"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := dangerJs.DSL{GitLab: fakedsl.GitLab{
+				MRData:        mr,
+				ApprovalsData: dangerJs.GitLabApproval{ApprovedBy: tt.approvedBy, ApprovalsLeft: tt.left},
+			}}.GitLabHelpers()
+			require.Equal(t, tt.want, h.ApprovedBy())
+			require.Equal(t, tt.approved, h.IsApproved(2))
+		})
+	}
+	require.Empty(t, dangerJs.DSL{GitLab: fakedsl.GitLab{}}.GitLabHelpers().ApprovedBy())
+}
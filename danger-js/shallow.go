@@ -0,0 +1,105 @@
+package dangerJs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// UnshallowEnv opts in to fetching the full history when a ref needed by a
+// git operation is missing from a shallow clone.
+const UnshallowEnv = "DANGER_GO_UNSHALLOW"
+
+// ErrShallowClone is returned, along with ErrRefNotFound, when a ref is
+// missing because the repository is a shallow clone.
+var ErrShallowClone = errors.New("shallow clone is missing the history needed for the diff, " +
+	"fetch the full history (e.g. actions/checkout with `fetch-depth: 0`) or set " + UnshallowEnv + "=true")
+
+// IsShallowClone reports whether the repository is a shallow clone, as made
+// by `git clone --depth=1` and the default actions/checkout configuration.
+func (g gitImpl) IsShallowClone() (bool, error) {
+	out, err := g.runGit("rev-parse", "--is-shallow-repository")
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) == "true", nil
+}
+
+func autoUnshallow() bool {
+	switch strings.ToLower(os.Getenv(UnshallowEnv)) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// runGitWithRefs resolves refs with ResolveRef and runs the git command
+// args returns for the resolved refs. When one of them is missing from a
+// shallow clone, the clone is deepened and the refs resolved again if
+// UnshallowEnv is set, otherwise an error wrapping ErrShallowClone explains
+// how to fix the checkout.
+func (g gitImpl) runGitWithRefs(refs []string, args func(resolved []string) []string) (string, error) {
+	resolved, err := g.resolveRefs(refs)
+	if err != nil {
+		if !errors.Is(err, ErrRefNotFound) {
+			return "", err
+		}
+		shallow, shallowErr := g.IsShallowClone()
+		if shallowErr != nil || !shallow {
+			return "", err
+		}
+		if !autoUnshallow() {
+			return "", fmt.Errorf("%w: %w", ErrShallowClone, err)
+		}
+		fetches := unshallowFetches(refs, g.refRemotes())
+		if _, fetchErr := g.runGit(fetches[0]...); fetchErr != nil {
+			return "", fmt.Errorf("unshallowing clone: %w", fetchErr)
+		}
+		for _, fetch := range fetches[1:] {
+			// the ref may not exist on every remote, the refs are resolved
+			// again below
+			_, _ = g.runGit(fetch...)
+		}
+		if resolved, err = g.resolveRefs(refs); err != nil {
+			return "", err
+		}
+	}
+	return g.runGit(args(resolved)...)
+}
+
+// unshallowFetches returns the fetch commands run to unshallow the clone:
+// the first converts it to a full one from the first remote, the next fetch
+// each remote branch and tag the refs may resolve to, see refCandidates, as
+// CI checkouts often only include the PR ref. The refs needn't exist on
+// every remote, so only the first command must succeed.
+func unshallowFetches(refs, remotes []string) [][]string {
+	first := "origin"
+	if len(remotes) > 0 {
+		first = remotes[0]
+	}
+	fetches := [][]string{{"fetch", "--no-tags", "--unshallow", first}}
+	seen := map[string]bool{}
+	add := func(remote, refspec string) {
+		if !seen[remote+" "+refspec] {
+			seen[remote+" "+refspec] = true
+			fetches = append(fetches, []string{"fetch", "--no-tags", remote, refspec})
+		}
+	}
+	for _, ref := range refs {
+		for _, c := range refCandidates(ref, remotes) {
+			if tag, ok := strings.CutPrefix(c, "refs/tags/"); ok {
+				for _, remote := range remotes {
+					add(remote, fmt.Sprintf("+refs/tags/%s:refs/tags/%s", tag, tag))
+				}
+				continue
+			}
+			for _, remote := range remotes {
+				if branch, ok := strings.CutPrefix(c, remote+"/"); ok {
+					add(remote, fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", branch, remote, branch))
+				}
+			}
+		}
+	}
+	return fetches
+}
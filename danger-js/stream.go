@@ -0,0 +1,272 @@
+package dangerJs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+)
+
+// lazy is a JSON value of the DSL decoded from its source on first use. It is
+// shared by pointer so copies of the DSL types decode it only once.
+type lazy[T any] struct {
+	once sync.Once
+	// name is the path of the value in the DSL, e.g. git.commits.
+	name string
+	src  io.ReaderAt
+	off  int64
+	n    int64
+	// last is the last item of a list, kept when skipping it, see lastItem.
+	last json.RawMessage
+	val  T
+	err  error
+}
+
+// load decodes the value on first use. When the source can't be read or
+// decoded, the value stays empty and the error wraps ErrDSLFieldMissing.
+func (l *lazy[T]) load() (T, error) {
+	l.once.Do(func() {
+		defer func() { l.src = nil }()
+		raw := make([]byte, l.n)
+		if _, err := l.src.ReadAt(raw, l.off); err != nil && !errors.Is(err, io.EOF) {
+			l.err = fmt.Errorf("%w: reading %s: %w", ErrDSLFieldMissing, l.name, err)
+			return
+		}
+		// the section starts right after the key, before the colon
+		raw = bytes.TrimLeft(raw, " \t\r\n:")
+		if err := json.Unmarshal(raw, &l.val); err != nil {
+			l.err = fmt.Errorf("%w: decoding %s: %w", ErrDSLFieldMissing, l.name, err)
+		}
+	})
+	return l.val, l.err
+}
+
+// lastItem returns the last item of the list l without decoding the others,
+// and whether the list has items.
+func lastItem[T any](l *lazy[[]T]) (T, bool) {
+	var item T
+	if l.last == nil {
+		return item, false
+	}
+	if err := json.Unmarshal(l.last, &item); err != nil {
+		log.Printf("reading the DSL: %s", fmt.Errorf("%w: decoding %s: %w", ErrDSLFieldMissing, l.name, err).Error())
+		return item, false
+	}
+	return item, true
+}
+
+// get is load for the getters without an error, which log it instead.
+func (l *lazy[T]) get() T {
+	v, err := l.load()
+	if err != nil {
+		log.Printf("reading the DSL: %s", err.Error())
+	}
+	return v
+}
+
+// DecodeDSLStream decodes the document danger-js passes to processes, with
+// the DSL under its "danger" key, from the size bytes of r without reading
+// it into memory at once. The commit lists of the git, github and gitlab
+// sections, the bulk of the DSL on PRs with many commits, are skipped and
+// only decoded from r when first accessed, so r must stay readable while the
+// DSL is in use. Errors wrap ErrDSLFieldMissing when the danger or git
+// section is absent.
+func DecodeDSLStream(r io.ReaderAt, size int64) (DSLData, error) {
+	dec := json.NewDecoder(io.NewSectionReader(r, 0, size))
+	var d DSLData
+	found := false
+	err := decodeObject(dec, func(key string) error {
+		if key != "danger" {
+			return skipValue(dec)
+		}
+		found = true
+		return decodeDanger(dec, r, &d)
+	})
+	if err != nil {
+		return DSLData{}, fmt.Errorf("decoding DSL JSON: %w", err)
+	}
+	if !found {
+		return DSLData{}, fmt.Errorf("%w: danger", ErrDSLFieldMissing)
+	}
+	return d, nil
+}
+
+func decodeDanger(dec *json.Decoder, r io.ReaderAt, d *DSLData) error {
+	hasGit := false
+	err := decodeObject(dec, func(key string) error {
+		switch key {
+		case "git":
+			hasGit = true
+			commits, err := decodeSection(dec, r, &d.Git)
+			d.Git.commits = lazyOf[[]GitCommit]("git.commits", commits, r)
+			return err
+		case "github":
+			commits, err := decodeSection(dec, r, &d.GitHub)
+			d.GitHub.commits = lazyOf[[]GitHubCommit]("github.commits", commits, r)
+			return err
+		case "gitlab":
+			commits, err := decodeSection(dec, r, &d.GitLab)
+			d.GitLab.commits = lazyOf[[]GitLabMRCommit]("gitlab.commits", commits, r)
+			return err
+		case "settings":
+			return dec.Decode(&d.Settings)
+		default:
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return err
+			}
+			d.addExtra(key, raw)
+			return nil
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if !hasGit {
+		return fmt.Errorf("%w: git", ErrDSLFieldMissing)
+	}
+	return nil
+}
+
+// span is the location of a JSON value in the source, and the last item of
+// the list it holds.
+type span struct {
+	off, n int64
+	last   json.RawMessage
+}
+
+func lazyOf[T any](name string, s *span, r io.ReaderAt) *lazy[T] {
+	if s == nil {
+		return nil
+	}
+	return &lazy[T]{name: name, src: r, off: s.off, n: s.n, last: s.last}
+}
+
+// decodeSection decodes a JSON object into out, except for its commits key
+// whose location is returned instead.
+func decodeSection(dec *json.Decoder, r io.ReaderAt, out any) (*span, error) {
+	tok, err := dec.Token()
+	if err != nil || tok == nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("expected object, got %v", tok)
+	}
+	var commits *span
+	fields := map[string]json.RawMessage{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		if key == "commits" {
+			start := dec.InputOffset()
+			last, err := skipList(dec)
+			if err != nil {
+				return nil, err
+			}
+			commits = &span{off: start, n: dec.InputOffset() - start, last: last}
+			continue
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		fields[key] = raw
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	return commits, json.Unmarshal(data, out)
+}
+
+// decodeObject calls fn for every key of the JSON object at the decoder,
+// which must consume the value. A null object has no keys.
+func decodeObject(dec *json.Decoder, fn func(key string) error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("expected object, got %v", tok)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if err := fn(tok.(string)); err != nil {
+			return err
+		}
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// skipList consumes the next JSON value, keeping only the last item when it
+// is a list.
+func skipList(dec *json.Decoder) (json.RawMessage, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return nil, nil
+	}
+	if delim == '{' {
+		// not a list, skip the rest of the object
+		for dec.More() {
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			if err := skipValue(dec); err != nil {
+				return nil, err
+			}
+		}
+		_, err := dec.Token()
+		return nil, err
+	}
+	var last json.RawMessage
+	for dec.More() {
+		var item json.RawMessage
+		if err := dec.Decode(&item); err != nil {
+			return nil, err
+		}
+		last = item
+	}
+	_, err = dec.Token()
+	return last, err
+}
+
+// skipValue consumes the next JSON value without keeping it in memory.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if delim, ok := tok.(json.Delim); ok {
+			if delim == '{' || delim == '[' {
+				depth++
+			} else {
+				depth--
+			}
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package dangerJs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const streamDSL = `{
+	"danger": {
+		"git": {
+			"modified_files": ["a.go"],
+			"commits" :
+				[{"sha": "abc", "message": "first"}, {"sha": "def", "message": "second"}],
+			"created_files": ["b.go"]
+		},
+		"github": {
+			"pr": {"number": 12, "title": "Stream"},
+			"commits": [{"sha": "abc", "commit": {"message": "first"}}],
+			"reviews": [{"state": "APPROVED"}]
+		},
+		"gitlab": null,
+		"settings": {"github": {"baseURL": "https://api.github.com"}},
+		"unknown": {"ignored": [1, 2, {"x": null}]}
+	},
+	"other": true
+}`
+
+// countingReader records how many bytes were read at each offset.
+type countingReader struct {
+	*bytes.Reader
+	reads int
+}
+
+func (r *countingReader) ReadAt(p []byte, off int64) (int, error) {
+	r.reads++
+	return r.Reader.ReadAt(p, off)
+}
+
+func TestDecodeDSLStream(t *testing.T) {
+	src := &countingReader{Reader: bytes.NewReader([]byte(streamDSL))}
+	d, err := DecodeDSLStream(src, src.Size())
+	require.NoError(t, err)
+
+	decoded := src.reads
+	pr := d.ToInterface()
+	require.Equal(t, []string{"a.go"}, pr.Git.ModifiedFiles())
+	require.Equal(t, []string{"b.go"}, pr.Git.CreatedFiles())
+	require.Equal(t, 12, pr.GitHub.PR().Number)
+	require.Len(t, pr.GitHub.Reviews(), 1)
+	require.Equal(t, "https://api.github.com", pr.Settings.GitHubBaseURL())
+	raw, ok := pr.Extra("unknown")
+	require.True(t, ok)
+	require.Equal(t, `{"ignored": [1, 2, {"x": null}]}`, string(raw))
+	require.Equal(t, decoded, src.reads, "commits must not be read until accessed")
+	last, ok := LastCommit(pr.Git)
+	require.True(t, ok)
+	require.Equal(t, GitCommit{SHA: "def", Message: "second"}, last)
+	require.Equal(t, decoded, src.reads, "the last commit is kept while decoding")
+
+	require.Equal(t, []GitCommit{{SHA: "abc", Message: "first"}, {SHA: "def", Message: "second"}}, pr.Git.Commits())
+	require.Equal(t, "first", pr.GitHub.Commits()[0].Commit.Message)
+	require.Nil(t, pr.GitLab.Commits())
+	reads := src.reads
+	require.Len(t, pr.Git.Commits(), 2)
+	require.Equal(t, reads, src.reads, "commits must be decoded once")
+}
+
+func TestDecodeDSLStreamMatchesDecodeDSL(t *testing.T) {
+	start := strings.Index(streamDSL, `{`+"\n\t\t\"git\"")
+	end := strings.LastIndex(streamDSL, `"other"`)
+	inner := strings.TrimRight(streamDSL[start:end], ",\n\t ")
+
+	want, err := DecodeDSL([]byte(inner))
+	require.NoError(t, err)
+	got, err := DecodeDSLStream(strings.NewReader(streamDSL), int64(len(streamDSL)))
+	require.NoError(t, err)
+
+	require.Equal(t, want.Git.CommitsList, got.ToInterface().Git.Commits())
+	require.Equal(t, want.GitHub.CommitsList, got.ToInterface().GitHub.Commits())
+	got.Git.commits, got.GitHub.commits = nil, nil
+	want.Git.CommitsList, want.GitHub.CommitsList = nil, nil
+	require.Equal(t, want, got)
+}
+
+func TestDecodeDSLStreamLastCommit(t *testing.T) {
+	tests := []struct {
+		name    string
+		commits string
+		exp     GitCommit
+		expOK   bool
+	}{
+		{name: "one", commits: `[{"sha": "abc"}]`, exp: GitCommit{SHA: "abc"}, expOK: true},
+		{name: "empty", commits: `[]`},
+		{name: "null", commits: `null`},
+		{name: "not a list", commits: `{"sha": {"x": [1]}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := `{"danger": {"git": {"commits": ` + tt.commits + `, "modified_files": ["a.go"]}}}`
+			d, err := DecodeDSLStream(strings.NewReader(doc), int64(len(doc)))
+			require.NoError(t, err)
+			last, ok := LastCommit(d.ToInterface().Git)
+			require.Equal(t, tt.expOK, ok)
+			require.Equal(t, tt.exp, last)
+			require.Equal(t, []string{"a.go"}, d.ToInterface().Git.ModifiedFiles())
+		})
+	}
+}
+
+func TestDecodeDSLStreamErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		missing bool
+	}{
+		{name: "no danger", doc: `{"other": {}}`, missing: true},
+		{name: "no git", doc: `{"danger": {"github": {}}}`, missing: true},
+		{name: "truncated", doc: `{"danger": {"git": {"commits": [`},
+		{name: "not an object", doc: `[]`},
+		{name: "section not an object", doc: `{"danger": {"git": []}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeDSLStream(strings.NewReader(tt.doc), int64(len(tt.doc)))
+			require.Error(t, err)
+			require.Equal(t, tt.missing, errors.Is(err, ErrDSLFieldMissing))
+		})
+	}
+}
+
+// closedReader fails once closed, like a DSL file removed while in use.
+type closedReader struct {
+	*bytes.Reader
+	closed bool
+}
+
+func (r *closedReader) ReadAt(p []byte, off int64) (int, error) {
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+	return r.Reader.ReadAt(p, off)
+}
+
+func TestDecodeDSLStreamLazyErrors(t *testing.T) {
+	doc := `{"danger": {"git": {"commits": [{"sha": "abc"}]}, "github": {"commits": [{"sha": 1}]}}}`
+	src := &closedReader{Reader: bytes.NewReader([]byte(doc))}
+	d, err := DecodeDSLStream(src, src.Size())
+	require.NoError(t, err)
+	pr := d.ToInterface()
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	_, err = GitHubCommits(context.Background(), pr.GitHub)
+	require.ErrorIs(t, err, ErrDSLFieldMissing)
+	require.ErrorContains(t, err, "decoding github.commits")
+
+	src.closed = true
+	require.Empty(t, pr.Git.Commits())
+	require.Contains(t, logs.String(), "reading git.commits: "+os.ErrClosed.Error())
+}
@@ -0,0 +1,136 @@
+package dangerJs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Kinds of GitHubTimelineEvent, as named by the GitHub timeline API.
+const (
+	TimelineLabeled              = "labeled"
+	TimelineUnlabeled            = "unlabeled"
+	TimelineReviewRequested      = "review_requested"
+	TimelineReviewRequestRemoved = "review_request_removed"
+	TimelineForcePushed          = "head_ref_force_pushed"
+	TimelineReviewed             = "reviewed"
+	TimelineReadyForReview       = "ready_for_review"
+	TimelineConvertedToDraft     = "convert_to_draft"
+)
+
+// GitHubTimelineEvent is an event of the timeline of a PR. Which fields are
+// set depends on the Event.
+type GitHubTimelineEvent struct {
+	ID    int64  `json:"id"`
+	Event string `json:"event"`
+	// Actor performed the event. Reviewed events have the User instead.
+	Actor     GitHubUser `json:"actor"`
+	User      GitHubUser `json:"user"`
+	CreatedAt time.Time  `json:"created_at"`
+	// Label is set for labeled and unlabeled events.
+	Label *GitHubIssueLabel `json:"label,omitempty"`
+	// RequestedReviewer or RequestedTeam is set for review request events.
+	RequestedReviewer *GitHubUser `json:"requested_reviewer,omitempty"`
+	RequestedTeam     *GitHubTeam `json:"requested_team,omitempty"`
+	// CommitID is the new head of force pushes.
+	CommitID string `json:"commit_id,omitempty"`
+	// State and SubmittedAt are set for reviewed events.
+	State       string    `json:"state,omitempty"`
+	SubmittedAt time.Time `json:"submitted_at,omitempty"`
+}
+
+// Login returns the login of who performed the event.
+func (e GitHubTimelineEvent) Login() string {
+	if e.Actor.Login != "" {
+		return e.Actor.Login
+	}
+	return e.User.Login
+}
+
+// GitHubTimeline is the timeline of a PR, oldest event first. Its filters
+// combine, e.g. to find whether the author ever removed a label:
+//
+//	tl.Label("do-not-merge").Events(dangerJs.TimelineUnlabeled).By(pr.GitHub.PR().User.Login)
+type GitHubTimeline []GitHubTimelineEvent
+
+func (t GitHubTimeline) filter(keep func(GitHubTimelineEvent) bool) GitHubTimeline {
+	var out GitHubTimeline
+	for _, e := range t {
+		if keep(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Events returns the events of the given kinds, e.g. TimelineForcePushed.
+func (t GitHubTimeline) Events(kinds ...string) GitHubTimeline {
+	return t.filter(func(e GitHubTimelineEvent) bool {
+		for _, k := range kinds {
+			if e.Event == k {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Label returns the labeled and unlabeled events of label, compared case
+// insensitively as GitHub does.
+func (t GitHubTimeline) Label(label string) GitHubTimeline {
+	return t.filter(func(e GitHubTimelineEvent) bool {
+		return e.Label != nil && strings.EqualFold(e.Label.Name, label)
+	})
+}
+
+// By returns the events performed by the user login.
+func (t GitHubTimeline) By(login string) GitHubTimeline {
+	return t.filter(func(e GitHubTimelineEvent) bool {
+		return strings.EqualFold(e.Login(), login)
+	})
+}
+
+// Since returns the events at or after since.
+func (t GitHubTimeline) Since(since time.Time) GitHubTimeline {
+	return t.filter(func(e GitHubTimelineEvent) bool {
+		at := e.CreatedAt
+		if at.IsZero() {
+			at = e.SubmittedAt
+		}
+		return !at.Before(since)
+	})
+}
+
+// HasLabelEver reports whether label was applied at some point, even if it
+// was removed since.
+func (t GitHubTimeline) HasLabelEver(label string) bool {
+	return len(t.Label(label).Events(TimelineLabeled)) > 0
+}
+
+// GitHubTimelineFetcher fetches the timeline of a PR. githubclient.Client
+// implements it.
+type GitHubTimelineFetcher interface {
+	IssueTimeline(ctx context.Context, owner, repo string, number int) ([]GitHubTimelineEvent, error)
+}
+
+// ErrNoTimelineFetcher is returned by Timeline when no
+// GitHubTimelineFetcher is configured, see WithGitHubFetcher.
+var ErrNoTimelineFetcher = errors.New("reading the PR timeline requires a GitHub API client")
+
+// Timeline fetches the timeline of the PR from the GitHub API, once per run.
+// It needs a GitHubFetcher which is also a GitHubTimelineFetcher.
+func (g gitHubImpl) Timeline(ctx context.Context) (GitHubTimeline, error) {
+	f, ok := g.fetcher.(GitHubTimelineFetcher)
+	if !ok {
+		return nil, ErrNoTimelineFetcher
+	}
+	fetch := func() (GitHubTimeline, error) {
+		events, err := f.IssueTimeline(ctx, g.ThisPRData.Owner, g.ThisPRData.Repo, g.ThisPRData.Number)
+		return GitHubTimeline(events), err
+	}
+	if g.fetched == nil {
+		return fetch()
+	}
+	return g.fetched.timeline.load(fetch)
+}
@@ -0,0 +1,78 @@
+package dangerJs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTimelineFetcher struct {
+	fakeFetcher
+	events []GitHubTimelineEvent
+}
+
+func (f *fakeTimelineFetcher) IssueTimeline(_ context.Context, owner, repo string, number int) ([]GitHubTimelineEvent, error) {
+	f.calls++
+	return f.events, nil
+}
+
+func at(day int) time.Time {
+	return time.Date(2026, 1, day, 0, 0, 0, 0, time.UTC)
+}
+
+var timeline = GitHubTimeline{
+	{ID: 1, Event: TimelineLabeled, Actor: GitHubUser{Login: "maintainer"}, CreatedAt: at(1), Label: &GitHubIssueLabel{Name: "do-not-merge"}},
+	{ID: 2, Event: TimelineReviewRequested, Actor: GitHubUser{Login: "author"}, CreatedAt: at(2), RequestedReviewer: &GitHubUser{Login: "bob"}},
+	{ID: 3, Event: TimelineUnlabeled, Actor: GitHubUser{Login: "Author"}, CreatedAt: at(3), Label: &GitHubIssueLabel{Name: "Do-Not-Merge"}},
+	{ID: 4, Event: TimelineReviewed, User: GitHubUser{Login: "bob"}, SubmittedAt: at(4), State: "approved"},
+	{ID: 5, Event: TimelineForcePushed, Actor: GitHubUser{Login: "author"}, CreatedAt: at(5), CommitID: "abc"},
+}
+
+func ids(t GitHubTimeline) []int64 {
+	out := []int64{}
+	for _, e := range t {
+		out = append(out, e.ID)
+	}
+	return out
+}
+
+func TestGitHubTimelineFilters(t *testing.T) {
+	tests := []struct {
+		name string
+		got  GitHubTimeline
+		want []int64
+	}{
+		{name: "events", got: timeline.Events(TimelineForcePushed, TimelineReviewed), want: []int64{4, 5}},
+		{name: "label", got: timeline.Label("do-not-merge"), want: []int64{1, 3}},
+		{name: "by", got: timeline.By("author"), want: []int64{2, 3, 5}},
+		{name: "by reviewer", got: timeline.By("bob"), want: []int64{4}},
+		{name: "since", got: timeline.Since(at(4)), want: []int64{4, 5}},
+		{name: "label removed by author", got: timeline.Label("do-not-merge").Events(TimelineUnlabeled).By("author"), want: []int64{3}},
+		{name: "none", got: timeline.Events("closed"), want: []int64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, ids(tt.got))
+		})
+	}
+	require.True(t, timeline.HasLabelEver("DO-NOT-MERGE"))
+	require.False(t, timeline.HasLabelEver("wip"))
+}
+
+func TestGitHubTimeline(t *testing.T) {
+	f := &fakeTimelineFetcher{events: timeline}
+	d := DSL{GitHub: gitHubImpl{ThisPRData: GitHubAPIPR{Owner: "o", Repo: "r", Number: 1}}}
+	WithGitHubFetcher(f)(&d)
+
+	got, err := Timeline(context.Background(), d.GitHub)
+	require.NoError(t, err)
+	require.Equal(t, timeline, got)
+	_, err = Timeline(context.Background(), d.GitHub)
+	require.NoError(t, err)
+	require.Equal(t, 1, f.calls, "the timeline is fetched once")
+
+	_, err = gitHubImpl{}.Timeline(context.Background())
+	require.ErrorIs(t, err, ErrNoTimelineFetcher)
+}
@@ -0,0 +1,63 @@
+package dangerJs
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Trailer is a `Key: value` line in the trailer block at the end of a commit
+// message, e.g. `Signed-off-by: Alice <alice@example.com>`.
+type Trailer struct {
+	Key   string
+	Value string
+}
+
+var trailerRe = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*):\s*(.*)$`)
+
+// ParseTrailers returns the trailers of a commit message, which are the lines
+// of its last paragraph when every line of it is a trailer. The subject line
+// is never treated as a trailer.
+func ParseTrailers(message string) []Trailer {
+	message = strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n"))
+	paragraphs := strings.Split(message, "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+	var trailers []Trailer
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		m := trailerRe.FindStringSubmatch(line)
+		if m == nil {
+			return nil
+		}
+		trailers = append(trailers, Trailer{Key: m[1], Value: strings.TrimSpace(m[2])})
+	}
+	return trailers
+}
+
+// TrailerValues returns the values of the trailers with the given key,
+// compared case-insensitively.
+func TrailerValues(message, key string) []string {
+	var values []string
+	for _, t := range ParseTrailers(message) {
+		if strings.EqualFold(t.Key, key) {
+			values = append(values, t.Value)
+		}
+	}
+	return values
+}
+
+// ParseIdentity splits an identity of the form `Name <email>`.
+func ParseIdentity(s string) GitCommitAuthor {
+	s = strings.TrimSpace(s)
+	start, end := strings.LastIndex(s, "<"), strings.LastIndex(s, ">")
+	if start < 0 || end < start {
+		return GitCommitAuthor{Name: s}
+	}
+	return GitCommitAuthor{
+		Name:  strings.TrimSpace(s[:start]),
+		Email: strings.TrimSpace(s[start+1 : end]),
+	}
+}
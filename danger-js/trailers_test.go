@@ -0,0 +1,57 @@
+package dangerJs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrailers(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []Trailer
+	}{
+		{
+			name:    "subject only",
+			message: "Signed-off-by: Alice <alice@example.com>",
+		},
+		{
+			name:    "trailer block",
+			message: "Fix bug\n\nLonger description.\n\nSigned-off-by: Alice <alice@example.com>\nCo-authored-by: Bob <bob@example.com>\n",
+			want: []Trailer{
+				{Key: "Signed-off-by", Value: "Alice <alice@example.com>"},
+				{Key: "Co-authored-by", Value: "Bob <bob@example.com>"},
+			},
+		},
+		{
+			name:    "last paragraph is prose",
+			message: "Fix bug\n\nSigned-off-by: Alice <alice@example.com>\n\nNote: the trailer above is not last.",
+			want:    []Trailer{{Key: "Note", Value: "the trailer above is not last."}},
+		},
+		{
+			name:    "mixed paragraph is not a trailer block",
+			message: "Fix bug\n\nThis fixes it.\nSigned-off-by: Alice <alice@example.com>",
+		},
+		{
+			name:    "CRLF",
+			message: "Fix bug\r\n\r\nSigned-off-by: Alice <alice@example.com>\r\n",
+			want:    []Trailer{{Key: "Signed-off-by", Value: "Alice <alice@example.com>"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, ParseTrailers(tt.message))
+		})
+	}
+}
+
+func TestTrailerValues(t *testing.T) {
+	msg := "Fix\n\nsigned-off-by: A <a@example.com>\nSigned-off-by: B <b@example.com>"
+	require.Equal(t, []string{"A <a@example.com>", "B <b@example.com>"}, TrailerValues(msg, "Signed-off-by"))
+}
+
+func TestParseIdentity(t *testing.T) {
+	require.Equal(t, GitCommitAuthor{Name: "Alice Smith", Email: "alice@example.com"}, ParseIdentity(" Alice Smith <alice@example.com> "))
+	require.Equal(t, GitCommitAuthor{Name: "alice"}, ParseIdentity("alice"))
+}
@@ -8,6 +8,8 @@ type GitCommit struct {
 	Tree      any             `json:"tree"`
 	Parents   []string        `json:"parents,omitempty"`
 	URL       string          `json:"url"`
+	// Verification is only set for commits of GitHub PRs.
+	Verification *GitHubCommitVerification `json:"verification,omitempty"`
 }
 
 type GitCommitAuthor struct {
@@ -1,13 +1,15 @@
 package dangerJs
 
 import (
-	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
-	"os/exec"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 var (
@@ -46,6 +48,25 @@ type Settings interface {
 	CLIArgs() CLIArgs
 }
 
+// BitbucketCloudCredentials authenticate with Bitbucket Cloud using either a
+// username and app password, an OAuth consumer or a repository access token.
+type BitbucketCloudCredentials struct {
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	OAuthKey    string `json:"oauthKey"`
+	OAuthSecret string `json:"oauthSecret"`
+	AccessToken string `json:"accessToken"`
+}
+
+// BitbucketServerCredentials authenticate with a Bitbucket Server instance
+// using either a username and password or a personal access token.
+type BitbucketServerCredentials struct {
+	BaseURL  string `json:"baseURL"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Token    string `json:"token"`
+}
+
 type Git interface {
 	ModifiedFiles() []FilePath
 	CreatedFiles() []FilePath
@@ -61,6 +82,19 @@ type DSL struct {
 	GitHub   GitHub   `json:"github,omitempty"`
 	GitLab   GitLab   `json:"gitlab,omitempty"`
 	Settings Settings `json:"settings"`
+	// Impact is the build graph impact analysis of the changes, nil unless
+	// configured with WithImpact or WithBazelImpact.
+	Impact Impact `json:"-"`
+
+	// extras are the top-level DSL fields not decoded into the fields above.
+	extras map[string]json.RawMessage
+}
+
+// Extra returns the raw JSON of a top-level DSL field unknown to danger-go,
+// such as data injected by Peril or danger-js plugins.
+func (d DSL) Extra(key string) (json.RawMessage, bool) {
+	raw, ok := d.extras[key]
+	return raw, ok
 }
 
 type FilePath = string
@@ -71,6 +105,14 @@ type gitImpl struct {
 	CreatedFilesList  []FilePath  `json:"created_files"`
 	DeletedFilesList  []FilePath  `json:"deleted_files"`
 	CommitsList       []GitCommit `json:"commits"`
+
+	// dir is the repository git commands run in, the working directory when
+	// empty. See WithRepoPath.
+	dir string
+	// remotes are the remotes refs resolve to, see WithRefRemotes.
+	remotes []string
+	// commits replaces CommitsList when decoded with DecodeDSLStream.
+	commits *lazy[[]GitCommit]
 }
 
 func (g gitImpl) ModifiedFiles() []FilePath {
@@ -86,9 +128,24 @@ func (g gitImpl) DeletedFiles() []FilePath {
 }
 
 func (g gitImpl) Commits() []GitCommit {
+	if g.commits != nil {
+		return g.commits.get()
+	}
 	return g.CommitsList
 }
 
+// LastCommit returns the latest commit of the PR, without decoding the others
+// when the DSL was streamed, and whether the PR has commits.
+func (g gitImpl) LastCommit() (GitCommit, bool) {
+	if g.commits != nil {
+		return lastItem(g.commits)
+	}
+	if len(g.CommitsList) == 0 {
+		return GitCommit{}, false
+	}
+	return g.CommitsList[len(g.CommitsList)-1], true
+}
+
 // FileDiff represents the changes in a file.
 type FileDiff struct {
 	AddedLines   []DiffLine
@@ -99,6 +156,20 @@ type FileDiff struct {
 type DiffLine struct {
 	Content string
 	Line    int
+	// NoNewlineAtEOF is set when the line is the last in the file and is not
+	// followed by a newline.
+	NoNewlineAtEOF bool
+	// InvalidUTF8 is set when the line was not valid UTF-8, e.g. in a Latin-1
+	// file, and Content has U+FFFD in place of the invalid bytes.
+	InvalidUTF8 bool
+	// Hunk is the hunk of the diff the line is in, 1 for the first.
+	Hunk int
+	// Position is the line's offset in the diff of its file as GitHub review
+	// comments count it: 1 for the line below the first hunk header, counting
+	// every line after it, later hunk headers included. It matches GitHub's
+	// only for diffs with the same context lines, such as the patches of
+	// GitHubPRFile.
+	Position int
 }
 
 // DiffForFile executes a git diff command for a specific file and parses its output.
@@ -123,7 +194,7 @@ func validateFilePath(path string) bool {
 	}
 
 	// Reject absolute paths as they could access files outside the repository
-	if filepath.IsAbs(cleaned) {
+	if filepath.IsAbs(cleaned) || isWindowsAbs(NormalizePath(path)) {
 		return false
 	}
 
@@ -181,6 +252,7 @@ func validateGitRef(ref string) bool {
 
 // DiffForFileWithRefs executes a git diff command for a specific file with configurable references.
 func (g gitImpl) DiffForFileWithRefs(filePath, baseRef, headRef string) (FileDiff, error) {
+	filePath = NormalizePath(filePath)
 	// Validate file path to prevent command injection
 	if !validateFilePath(filePath) {
 		return FileDiff{}, fmt.Errorf("invalid file path: %s", filePath)
@@ -193,15 +265,14 @@ func (g gitImpl) DiffForFileWithRefs(filePath, baseRef, headRef string) (FileDif
 		return FileDiff{}, fmt.Errorf("invalid head ref: %s", headRef)
 	}
 
-	cmd := exec.Command("git", "diff", "--unified=0", baseRef, headRef, filePath)
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	err := cmd.Run()
+	out, err := g.runGitWithRefs([]string{baseRef, headRef}, func(r []string) []string {
+		return []string{"diff", "--unified=0", r[0], r[1], "--", filePath}
+	})
 	if err != nil {
 		return FileDiff{}, err
 	}
 
-	return parseDiffContent(out.String()), nil
+	return parseDiffContent(out), nil
 }
 
 // parseHunkHeader extracts line number information from a hunk header
@@ -239,42 +310,125 @@ func parseRemovedLine(line string) (content string, isRemoved bool) {
 
 // parseDiffContent parses git diff output and extracts added and removed lines with line numbers
 func parseDiffContent(diffContent string) FileDiff {
+	return parseDiff(diffContent, false)
+}
+
+// parsePatch parses a diff with context lines, such as the patches of the
+// GitHub API, which advance the line numbers of both sides.
+func parsePatch(patch string) FileDiff {
+	return parseDiff(patch, true)
+}
+
+func parseDiff(diffContent string, countContext bool) FileDiff {
 	var fileDiff FileDiff
 
-	lines := strings.Split(diffContent, "\n")
+	lines := strings.Split(normalizeLineEndings(diffContent), "\n")
 	// Initialize line numbers to -1 to indicate no hunk header has been found yet
 	currentRemovedLine := -1
 	currentAddedLine := -1
+	// removedLeft and addedLeft count the lines of the current hunk not seen
+	// yet. Within a hunk, lines starting with --- or +++ are content, e.g. a
+	// removed SQL comment, rather than file headers.
+	removedLeft, addedLeft := 0, 0
+	// lastLine points at the most recently parsed line so that a following
+	// "\ No newline at end of file" marker can be attached to it
+	var lastLine *[]DiffLine
+	// position is -1 until the first hunk header of a file
+	hunk, position := 0, -1
 
 	for _, line := range lines {
+		inHunk := removedLeft > 0 || addedLeft > 0
+		if !inHunk && strings.HasPrefix(line, "diff ") {
+			// the next file of a multi-file diff
+			currentRemovedLine, currentAddedLine = -1, -1
+			hunk, position = 0, -1
+			lastLine = nil
+			continue
+		}
 		// Check for hunk header to track line numbers
 		if removedStart, addedStart, isHunk := parseHunkHeader(line); isHunk {
 			currentRemovedLine = removedStart
 			currentAddedLine = addedStart
-		} else if content, isAdded := parseAddedLine(line); isAdded {
+			removedLeft, addedLeft = parseHunkCounts(line)
+			hunk++
+			position = max(position+1, 0)
+			lastLine = nil
+			continue
+		}
+		if position >= 0 {
+			position++
+		}
+		content, isAdded := parseAddedLine(line)
+		if inHunk && strings.HasPrefix(line, "+") {
+			content, isAdded = line[1:], true
+		}
+		if isAdded {
 			// Only add line if we have a valid line number from a hunk header
 			if currentAddedLine >= 0 {
-				fileDiff.AddedLines = append(fileDiff.AddedLines, DiffLine{
-					Content: content,
-					Line:    currentAddedLine,
-				})
+				fileDiff.AddedLines = append(fileDiff.AddedLines, newDiffLine(content, currentAddedLine, hunk, position))
+				lastLine = &fileDiff.AddedLines
 				currentAddedLine++
+				addedLeft--
 			}
-		} else if content, isRemoved := parseRemovedLine(line); isRemoved {
+			continue
+		}
+		content, isRemoved := parseRemovedLine(line)
+		if inHunk && strings.HasPrefix(line, "-") {
+			content, isRemoved = line[1:], true
+		}
+		switch {
+		case isRemoved:
 			// Only add line if we have a valid line number from a hunk header
 			if currentRemovedLine >= 0 {
-				fileDiff.RemovedLines = append(fileDiff.RemovedLines, DiffLine{
-					Content: content,
-					Line:    currentRemovedLine,
-				})
+				fileDiff.RemovedLines = append(fileDiff.RemovedLines, newDiffLine(content, currentRemovedLine, hunk, position))
+				lastLine = &fileDiff.RemovedLines
+				currentRemovedLine++
+				removedLeft--
+			}
+		case strings.HasPrefix(line, " ") && currentAddedLine >= 0:
+			if countContext {
+				currentAddedLine++
 				currentRemovedLine++
 			}
+			removedLeft--
+			addedLeft--
+			lastLine = nil
+		case strings.HasPrefix(line, "\\ ") && lastLine != nil:
+			(*lastLine)[len(*lastLine)-1].NoNewlineAtEOF = true
+			lastLine = nil
+		default:
+			lastLine = nil
 		}
 	}
 
 	return fileDiff
 }
 
+// parseHunkCounts returns the number of removed and added side lines of a
+// hunk header, 1 when omitted as in "@@ -3 +3 @@".
+func parseHunkCounts(line string) (removed, added int) {
+	matches := hunkHeaderRe.FindStringSubmatch(line)
+	count := func(s string) int {
+		if s == "" {
+			return 1
+		}
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+	return count(matches[2]), count(matches[4])
+}
+
+// newDiffLine returns the line with content, replacing bytes which are not
+// valid UTF-8, e.g. of Latin-1 files, with U+FFFD.
+func newDiffLine(content string, line, hunk, position int) DiffLine {
+	l := DiffLine{Content: content, Line: line, Hunk: hunk, Position: position}
+	if !utf8.ValidString(content) {
+		l.Content = strings.ToValidUTF8(content, "\uFFFD")
+		l.InvalidUTF8 = true
+	}
+	return l
+}
+
 // settingsImpl is the internal implementation of the Settings interface
 type settingsImpl struct {
 	GitHub struct {
@@ -282,7 +436,13 @@ type settingsImpl struct {
 		BaseURL           string `json:"baseURL"`
 		AdditionalHeaders any    `json:"additionalHeaders"`
 	} `json:"github"`
-	CLIArgsData CLIArgs `json:"cliArgs"`
+	GitLab struct {
+		AccessToken string `json:"accessToken"`
+		BaseURL     string `json:"baseURL"`
+	} `json:"gitlab"`
+	BitbucketCloudData  BitbucketCloudCredentials  `json:"bitbucketCloud"`
+	BitbucketServerData BitbucketServerCredentials `json:"bitbucketServer"`
+	CLIArgsData         CLIArgs                    `json:"cliArgs"`
 }
 
 // GitHubAccessToken returns the GitHub access token
@@ -298,10 +458,56 @@ func (s settingsImpl) GitHubAdditionalHeaders() any {
 	return s.GitHub.AdditionalHeaders
 }
 
+// GitLabAccessToken returns the GitLab token, DANGER_GITLAB_API_TOKEN by
+// default.
+func (s settingsImpl) GitLabAccessToken() string {
+	return firstNonEmpty(s.GitLab.AccessToken, os.Getenv("DANGER_GITLAB_API_TOKEN"))
+}
+
+// GitLabBaseURL returns the GitLab instance, DANGER_GITLAB_HOST or the
+// CI_SERVER_URL of GitLab CI by default. Empty means gitlab.com.
+func (s settingsImpl) GitLabBaseURL() string {
+	return firstNonEmpty(s.GitLab.BaseURL, os.Getenv("DANGER_GITLAB_HOST"), os.Getenv("CI_SERVER_URL"))
+}
+
+// BitbucketCloud returns the Bitbucket Cloud credentials, each taken from the
+// DANGER_BITBUCKETCLOUD_* variables when not in the DSL.
+func (s settingsImpl) BitbucketCloud() BitbucketCloudCredentials {
+	c := s.BitbucketCloudData
+	return BitbucketCloudCredentials{
+		Username:    firstNonEmpty(c.Username, os.Getenv("DANGER_BITBUCKETCLOUD_USERNAME")),
+		Password:    firstNonEmpty(c.Password, os.Getenv("DANGER_BITBUCKETCLOUD_PASSWORD")),
+		OAuthKey:    firstNonEmpty(c.OAuthKey, os.Getenv("DANGER_BITBUCKETCLOUD_OAUTH_KEY")),
+		OAuthSecret: firstNonEmpty(c.OAuthSecret, os.Getenv("DANGER_BITBUCKETCLOUD_OAUTH_SECRET")),
+		AccessToken: firstNonEmpty(c.AccessToken, os.Getenv("DANGER_BITBUCKETCLOUD_REPO_ACCESSTOKEN")),
+	}
+}
+
+// BitbucketServer returns the Bitbucket Server credentials, each taken from
+// the DANGER_BITBUCKETSERVER_* variables when not in the DSL.
+func (s settingsImpl) BitbucketServer() BitbucketServerCredentials {
+	c := s.BitbucketServerData
+	return BitbucketServerCredentials{
+		BaseURL:  firstNonEmpty(c.BaseURL, os.Getenv("DANGER_BITBUCKETSERVER_HOST")),
+		Username: firstNonEmpty(c.Username, os.Getenv("DANGER_BITBUCKETSERVER_USERNAME")),
+		Password: firstNonEmpty(c.Password, os.Getenv("DANGER_BITBUCKETSERVER_PASSWORD")),
+		Token:    firstNonEmpty(c.Token, os.Getenv("DANGER_BITBUCKETSERVER_TOKEN")),
+	}
+}
+
 func (s settingsImpl) CLIArgs() CLIArgs {
 	return s.CLIArgsData
 }
 
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
 // gitHubImpl is the internal implementation of the GitHub interface
 type gitHubImpl struct {
 	IssueData              GitHubIssue     `json:"issue"`
@@ -310,6 +516,11 @@ type gitHubImpl struct {
 	CommitsList            []GitHubCommit  `json:"commits"`
 	ReviewsList            []GitHubReview  `json:"reviews"`
 	RequestedReviewersData GitHubReviewers `json:"requested_reviewers"`
+
+	// commits replaces CommitsList when decoded with DecodeDSLStream.
+	commits *lazy[[]GitHubCommit]
+	fetcher GitHubFetcher
+	fetched *gitHubFetched
 }
 
 func (g gitHubImpl) Issue() GitHubIssue {
@@ -325,10 +536,21 @@ func (g gitHubImpl) ThisPR() GitHubAPIPR {
 }
 
 func (g gitHubImpl) Commits() []GitHubCommit {
+	if g.commits != nil {
+		return g.commits.get()
+	}
+	if g.CommitsList == nil && g.fetcher != nil {
+		commits, _ := g.CommitsContext(context.Background())
+		return commits
+	}
 	return g.CommitsList
 }
 
 func (g gitHubImpl) Reviews() []GitHubReview {
+	if g.ReviewsList == nil && g.fetcher != nil {
+		reviews, _ := g.ReviewsContext(context.Background())
+		return reviews
+	}
 	return g.ReviewsList
 }
 
@@ -342,6 +564,11 @@ type gitLabImpl struct {
 	MRData        GitLabMR         `json:"mr"`
 	CommitsList   []GitLabMRCommit `json:"commits"`
 	ApprovalsData GitLabApproval   `json:"approvals"`
+
+	// commits replaces CommitsList when decoded with DecodeDSLStream.
+	commits *lazy[[]GitLabMRCommit]
+	fetcher GitLabFetcher
+	fetched *gitLabFetched
 }
 
 func (g gitLabImpl) Metadata() RepoMetaData {
@@ -353,10 +580,21 @@ func (g gitLabImpl) MR() GitLabMR {
 }
 
 func (g gitLabImpl) Commits() []GitLabMRCommit {
+	if g.commits != nil {
+		return g.commits.get()
+	}
+	if g.CommitsList == nil && g.fetcher != nil {
+		commits, _ := g.CommitsContext(context.Background())
+		return commits
+	}
 	return g.CommitsList
 }
 
 func (g gitLabImpl) Approvals() GitLabApproval {
+	if g.ApprovalsData.ID == 0 && g.fetcher != nil {
+		approvals, _ := g.ApprovalsContext(context.Background())
+		return approvals
+	}
 	return g.ApprovalsData
 }
 
@@ -366,24 +604,127 @@ type DSLData struct {
 	GitHub   gitHubImpl   `json:"github,omitempty"`
 	GitLab   gitLabImpl   `json:"gitlab,omitempty"`
 	Settings settingsImpl `json:"settings"`
+	// Extras holds the top-level fields not decoded into the sections above,
+	// nil when there are none.
+	Extras map[string]json.RawMessage `json:"-"`
+}
+
+// dslSections are the top-level DSL fields decoded into DSLData.
+var dslSections = map[string]bool{"git": true, "github": true, "gitlab": true, "settings": true}
+
+// addExtra records an unknown top-level field.
+func (d *DSLData) addExtra(key string, raw json.RawMessage) {
+	if d.Extras == nil {
+		d.Extras = map[string]json.RawMessage{}
+	}
+	d.Extras[key] = raw
+}
+
+// DecodeDSL decodes the DSL JSON produced by danger-js. The error wraps
+// ErrDSLFieldMissing when the git section is absent.
+func DecodeDSL(data []byte) (DSLData, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return DSLData{}, fmt.Errorf("unmarshalling DSL JSON: %w", err)
+	}
+	if _, ok := fields["git"]; !ok {
+		return DSLData{}, fmt.Errorf("%w: git", ErrDSLFieldMissing)
+	}
+	var d DSLData
+	if err := json.Unmarshal(data, &d); err != nil {
+		return DSLData{}, fmt.Errorf("unmarshalling DSL JSON: %w", err)
+	}
+	for key, raw := range fields {
+		if !dslSections[key] {
+			d.addExtra(key, raw)
+		}
+	}
+	return d, nil
+}
+
+// DSLOption configures a DSL built from the danger-js JSON.
+type DSLOption func(*DSL)
+
+// WithRepoPath runs all git commands in dir rather than the process working
+// directory.
+func WithRepoPath(dir string) DSLOption {
+	return func(d *DSL) {
+		if g, ok := d.Git.(gitImpl); ok {
+			g.dir = dir
+			d.Git = g
+		}
+	}
 }
 
 // ToInterface converts DSLData to DSL with interfaces
-func (d DSLData) ToInterface() DSL {
-	return DSL{
+func (d DSLData) ToInterface(opts ...DSLOption) DSL {
+	dsl := DSL{
 		Git:      d.Git,
 		GitHub:   d.GitHub,
 		GitLab:   d.GitLab,
 		Settings: d.Settings,
+		extras:   d.Extras,
+	}
+	for _, o := range opts {
+		o(&dsl)
 	}
+	return dsl
 }
 
+// CLIArgs are the arguments danger-js was run with. The flags are decoded
+// with Flag since danger-js versions disagree on their JSON types; read them
+// through the Verbose, TextOnly and Staging methods.
 type CLIArgs struct {
 	Base               string `json:"base"`
-	Verbose            string `json:"verbose"`
+	VerboseFlag        Flag   `json:"verbose"`
 	ExternalCIProvider string `json:"externalCiProvider"`
-	TextOnly           bool   `json:"textOnly"` // JS has this as string
+	TextOnlyFlag       Flag   `json:"textOnly"`
 	Dangerfile         string `json:"dangerfile"`
 	ID                 string `json:"id"`
-	Staging            bool   `json:"staging"`
+	StagingFlag        Flag   `json:"staging"`
+}
+
+// Verbose reports whether danger-js was run with --verbose.
+func (a CLIArgs) Verbose() bool {
+	return bool(a.VerboseFlag)
+}
+
+// TextOnly reports whether danger-js was run with --text-only.
+func (a CLIArgs) TextOnly() bool {
+	return bool(a.TextOnlyFlag)
+}
+
+// Staging reports whether danger-js was run with --staging.
+func (a CLIArgs) Staging() bool {
+	return bool(a.StagingFlag)
+}
+
+// Flag is a boolean CLI argument decoded from a JSON bool, number or string.
+// Empty strings, "false", "0", "no" and "off" are false, other strings true.
+type Flag bool
+
+// UnmarshalJSON decodes any of the representations danger-js uses.
+func (f *Flag) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("decoding flag: %w", err)
+	}
+	switch v := v.(type) {
+	case nil:
+		*f = false
+	case bool:
+		*f = Flag(v)
+	case float64:
+		*f = v != 0
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "", "false", "0", "no", "off":
+			*f = false
+		default:
+			*f = true
+		}
+	default:
+		return fmt.Errorf("decoding flag: unexpected value %s", data)
+	}
+	return nil
 }
@@ -28,12 +28,12 @@ index 123..456 100644
 -	fmt.Println("removed line")`,
 			wantFileDiff: FileDiff{
 				AddedLines: []DiffLine{
-					{Content: "func newFunction() {", Line: 1},
-					{Content: "\treturn \"added line\"", Line: 5},
+					{Content: "func newFunction() {", Line: 1, Hunk: 1, Position: 2},
+					{Content: "\treturn \"added line\"", Line: 5, Hunk: 2, Position: 4},
 				},
 				RemovedLines: []DiffLine{
-					{Content: "func oldFunction() {", Line: 1},
-					{Content: "\tfmt.Println(\"removed line\")", Line: 5},
+					{Content: "func oldFunction() {", Line: 1, Hunk: 1, Position: 1},
+					{Content: "\tfmt.Println(\"removed line\")", Line: 5, Hunk: 2, Position: 5},
 				},
 			},
 		},
@@ -49,9 +49,9 @@ index 123..456 100644
 +func main() {}`,
 			wantFileDiff: FileDiff{
 				AddedLines: []DiffLine{
-					{Content: "package main", Line: 1},
-					{Content: "", Line: 2},
-					{Content: "func main() {}", Line: 3},
+					{Content: "package main", Line: 1, Hunk: 1, Position: 1},
+					{Content: "", Line: 2, Hunk: 1, Position: 2},
+					{Content: "func main() {}", Line: 3, Hunk: 1, Position: 3},
 				},
 				RemovedLines: nil,
 			},
@@ -69,9 +69,9 @@ index 123..456 100644
 			wantFileDiff: FileDiff{
 				AddedLines: nil,
 				RemovedLines: []DiffLine{
-					{Content: "package main", Line: 1},
-					{Content: "", Line: 2},
-					{Content: "func old() {}", Line: 3},
+					{Content: "package main", Line: 1, Hunk: 1, Position: 1},
+					{Content: "", Line: 2, Hunk: 1, Position: 2},
+					{Content: "func old() {}", Line: 3, Hunk: 1, Position: 3},
 				},
 			},
 		},
@@ -98,11 +98,11 @@ index 123..456 100644
  	unchanged line 3`,
 			wantFileDiff: FileDiff{
 				AddedLines: []DiffLine{
-					{Content: "\tnew implementation", Line: 10},
-					{Content: "\tadditional line", Line: 11},
+					{Content: "\tnew implementation", Line: 10, Hunk: 1, Position: 4},
+					{Content: "\tadditional line", Line: 11, Hunk: 1, Position: 5},
 				},
 				RemovedLines: []DiffLine{
-					{Content: "\told implementation", Line: 10},
+					{Content: "\told implementation", Line: 10, Hunk: 1, Position: 3},
 				},
 			},
 		},
@@ -117,10 +117,10 @@ index 123..456 100644
 +	fmt.Printf("Hi %s!\n", name)`,
 			wantFileDiff: FileDiff{
 				AddedLines: []DiffLine{
-					{Content: "\tfmt.Printf(\"Hi %s!\\n\", name)", Line: 1},
+					{Content: "\tfmt.Printf(\"Hi %s!\\n\", name)", Line: 1, Hunk: 1, Position: 2},
 				},
 				RemovedLines: []DiffLine{
-					{Content: "\tfmt.Printf(\"Hello %s\\n\", name)", Line: 1},
+					{Content: "\tfmt.Printf(\"Hello %s\\n\", name)", Line: 1, Hunk: 1, Position: 1},
 				},
 			},
 		},
@@ -135,10 +135,10 @@ index 123..456 100644
 +},`,
 			wantFileDiff: FileDiff{
 				AddedLines: []DiffLine{
-					{Content: "},", Line: 1},
+					{Content: "},", Line: 1, Hunk: 1, Position: 2},
 				},
 				RemovedLines: []DiffLine{
-					{Content: "}", Line: 1},
+					{Content: "}", Line: 1, Hunk: 1, Position: 1},
 				},
 			},
 		},
@@ -155,12 +155,12 @@ index 123..456 100644
 + `,
 			wantFileDiff: FileDiff{
 				AddedLines: []DiffLine{
-					{Content: "", Line: 1},
-					{Content: " ", Line: 2},
+					{Content: "", Line: 1, Hunk: 1, Position: 2},
+					{Content: " ", Line: 2, Hunk: 1, Position: 4},
 				},
 				RemovedLines: []DiffLine{
-					{Content: "", Line: 1},
-					{Content: " ", Line: 2},
+					{Content: "", Line: 1, Hunk: 1, Position: 1},
+					{Content: " ", Line: 2, Hunk: 1, Position: 3},
 				},
 			},
 		},
@@ -195,15 +195,64 @@ index 123..456 100644
  }`,
 			wantFileDiff: FileDiff{
 				AddedLines: []DiffLine{
-					{Content: "import \"fmt\"", Line: 1},
-					{Content: "\t\tfmt.Println(\"new\")", Line: 11},
-					{Content: "\t\tfmt.Println(\"extra\")", Line: 12},
+					{Content: "import \"fmt\"", Line: 1, Hunk: 1, Position: 3},
+					{Content: "\t\tfmt.Println(\"new\")", Line: 11, Hunk: 2, Position: 8},
+					{Content: "\t\tfmt.Println(\"extra\")", Line: 12, Hunk: 2, Position: 9},
 				},
 				RemovedLines: []DiffLine{
-					{Content: "\t\tfmt.Println(\"old\")", Line: 10},
+					{Content: "\t\tfmt.Println(\"old\")", Line: 10, Hunk: 2, Position: 7},
 				},
 			},
 		},
+		{
+			name: "no newline at end of file markers",
+			gitDiffOutput: `diff --git a/eof.txt b/eof.txt
+index 123..456 100644
+--- a/eof.txt
++++ b/eof.txt
+@@ -3 +3,2 @@
+-last
+\ No newline at end of file
++last
++appended
+\ No newline at end of file`,
+			wantFileDiff: FileDiff{
+				AddedLines: []DiffLine{
+					{Content: "last", Line: 3, Hunk: 1, Position: 3},
+					{Content: "appended", Line: 4, NoNewlineAtEOF: true, Hunk: 1, Position: 4},
+				},
+				RemovedLines: []DiffLine{
+					{Content: "last", Line: 3, NoNewlineAtEOF: true, Hunk: 1, Position: 1},
+				},
+			},
+		},
+		{
+			name: "CRLF file content is preserved",
+			gitDiffOutput: "diff --git a/win.txt b/win.txt\n" +
+				"--- a/win.txt\n" +
+				"+++ b/win.txt\n" +
+				"@@ -1 +1 @@\n" +
+				"-old\r\n" +
+				"+new\r\n",
+			wantFileDiff: FileDiff{
+				AddedLines:   []DiffLine{{Content: "new\r", Line: 1, Hunk: 1, Position: 2}},
+				RemovedLines: []DiffLine{{Content: "old\r", Line: 1, Hunk: 1, Position: 1}},
+			},
+		},
+		{
+			name: "output translated to CRLF",
+			gitDiffOutput: "diff --git a/win.txt b/win.txt\r\n" +
+				"--- a/win.txt\r\n" +
+				"+++ b/win.txt\r\n" +
+				"@@ -1 +1,2 @@\r\n" +
+				"-old\r\n" +
+				"+new\r\n" +
+				"+more\r\n",
+			wantFileDiff: FileDiff{
+				AddedLines:   []DiffLine{{Content: "new", Line: 1, Hunk: 1, Position: 2}, {Content: "more", Line: 2, Hunk: 1, Position: 3}},
+				RemovedLines: []DiffLine{{Content: "old", Line: 1, Hunk: 1, Position: 1}},
+			},
+		},
 		{
 			name: "malformed diff without hunk headers",
 			gitDiffOutput: `diff --git a/bad.go b/bad.go
@@ -231,6 +280,66 @@ index 123..456 100644
 				RemovedLines: nil, // Should be empty since hunk header is invalid
 			},
 		},
+		{
+			name: "content starting with the header markers",
+			gitDiffOutput: `diff --git a/schema.sql b/schema.sql
+--- a/schema.sql
++++ b/schema.sql
+@@ -1,2 +1,2 @@
+--- drop the old table
+-DROP TABLE users;
++++i;
++-- keep the table
+`,
+			wantFileDiff: FileDiff{
+				AddedLines:   []DiffLine{{Content: "++i;", Line: 1, Hunk: 1, Position: 3}, {Content: "-- keep the table", Line: 2, Hunk: 1, Position: 4}},
+				RemovedLines: []DiffLine{{Content: "-- drop the old table", Line: 1, Hunk: 1, Position: 1}, {Content: "DROP TABLE users;", Line: 2, Hunk: 1, Position: 2}},
+			},
+		},
+		{
+			name: "quoted paths",
+			gitDiffOutput: `diff --git "a/caf\303\251.txt" "b/caf\303\251.txt"
+--- "a/caf\303\251.txt"
++++ "b/caf\303\251.txt"
+@@ -1 +1 @@
+-old
++new`,
+			wantFileDiff: FileDiff{
+				AddedLines:   []DiffLine{{Content: "new", Line: 1, Hunk: 1, Position: 2}},
+				RemovedLines: []DiffLine{{Content: "old", Line: 1, Hunk: 1, Position: 1}},
+			},
+		},
+		{
+			name: "several files",
+			gitDiffOutput: `diff --git a/a.txt b/a.txt
+--- a/a.txt
++++ b/a.txt
+@@ -1 +1 @@
+-a
++b
+diff --git a/b.txt b/b.txt
+--- a/b.txt
++++ b/b.txt
+@@ -3 +3 @@
+-c
++d`,
+			wantFileDiff: FileDiff{
+				AddedLines:   []DiffLine{{Content: "b", Line: 1, Hunk: 1, Position: 2}, {Content: "d", Line: 3, Hunk: 1, Position: 2}},
+				RemovedLines: []DiffLine{{Content: "a", Line: 1, Hunk: 1, Position: 1}, {Content: "c", Line: 3, Hunk: 1, Position: 1}},
+			},
+		},
+		{
+			name: "content which is not UTF-8",
+			gitDiffOutput: "--- a/latin1.txt\n" +
+				"+++ b/latin1.txt\n" +
+				"@@ -1 +1 @@\n" +
+				"-caf\xe9\n" +
+				"+café\n",
+			wantFileDiff: FileDiff{
+				AddedLines:   []DiffLine{{Content: "café", Line: 1, Hunk: 1, Position: 2}},
+				RemovedLines: []DiffLine{{Content: "caf\uFFFD", Line: 1, InvalidUTF8: true, Hunk: 1, Position: 1}},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -292,6 +401,26 @@ func TestValidateFilePath(t *testing.T) {
 			path:      "src/pkg/utils/helper.go",
 			wantValid: true,
 		},
+		{
+			name:      "windows relative path",
+			path:      `src\pkg\helper.go`,
+			wantValid: true,
+		},
+		{
+			name:      "windows drive path",
+			path:      `C:\Windows\win.ini`,
+			wantValid: false,
+		},
+		{
+			name:      "windows UNC path",
+			path:      `\\server\share\file.go`,
+			wantValid: false,
+		},
+		{
+			name:      "windows path traversal",
+			path:      `..\..\secret`,
+			wantValid: false,
+		},
 	}
 
 	for _, tt := range tests {
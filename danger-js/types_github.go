@@ -1,9 +1,55 @@
 package dangerJs
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type GitHubIssue struct {
-	Labels []GitHubIssueLabel `json:"labels"`
+	Number  int                `json:"number,omitempty"`
+	Title   string             `json:"title,omitempty"`
+	Body    string             `json:"body,omitempty"`
+	State   string             `json:"state,omitempty"` // "open" | "closed"
+	HTMLURL string             `json:"html_url,omitempty"`
+	Labels  []GitHubIssueLabel `json:"labels"`
+	// PullRequest is set when the issue is a pull request.
+	PullRequest *struct {
+		URL string `json:"url"`
+	} `json:"pull_request,omitempty"`
+}
+
+type GitHubIssueComment struct {
+	ID        int64      `json:"id"`
+	Body      string     `json:"body"`
+	User      GitHubUser `json:"user"`
+	HTMLURL   string     `json:"html_url"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	// Reactions counts the reactions to the comment by content.
+	Reactions GitHubReactionRollup `json:"reactions"`
+}
+
+// GitHubReactionRollup counts the reactions to a comment or issue.
+type GitHubReactionRollup struct {
+	TotalCount int `json:"total_count"`
+	PlusOne    int `json:"+1"`
+	MinusOne   int `json:"-1"`
+	Laugh      int `json:"laugh"`
+	Hooray     int `json:"hooray"`
+	Confused   int `json:"confused"`
+	Heart      int `json:"heart"`
+	Rocket     int `json:"rocket"`
+	Eyes       int `json:"eyes"`
+}
+
+// GitHubReaction is a reaction of a user to a comment or issue. Content is
+// one of "+1", "-1", "laugh", "confused", "heart", "hooray", "rocket" and
+// "eyes".
+type GitHubReaction struct {
+	ID        int64      `json:"id"`
+	User      GitHubUser `json:"user"`
+	Content   string     `json:"content"`
+	CreatedAt time.Time  `json:"created_at"`
 }
 
 type GitHubIssueLabel struct {
@@ -46,6 +92,10 @@ type GitHubPR struct {
 	ChangedFiles      int            `json:"changed_files"`
 	HTMLURL           string         `json:"html_url"`
 	AuthorAssociation string         `json:"author_association"` // "COLLABORATOR", "CONTRIBUTOR", "FIRST_TIMER", "FIRST_TIME_CONTRIBUTOR", "MEMBER", "NONE", "OWNER"
+	// Mergeable and Rebaseable are nil while GitHub computes them.
+	Mergeable      *bool  `json:"mergeable,omitempty"`
+	Rebaseable     *bool  `json:"rebaseable,omitempty"`
+	MergeableState string `json:"mergeable_state,omitempty"` // "clean" | "dirty" | "behind" | "blocked" | "unstable" | "has_hooks" | "draft" | "unknown"
 }
 type GitHubMergeRef struct {
 	Label string     `json:"label"`
@@ -65,13 +115,74 @@ type GitHubRepo struct {
 	HTMLURL     string `json:"html_url"`
 }
 
+// GitHubCommit is a commit of the PR. Author and Committer are the GitHub
+// accounts linked to the git identities in Commit, and are empty when the
+// commit email doesn't belong to any account.
 type GitHubCommit struct {
-	Commit    GitCommit  `json:"commit"`
-	SHA       string     `json:"sha"`
-	URL       string     `json:"url"`
-	Author    GitHubUser `json:"author"`
-	Committer GitHubUser `json:"committer"`
-	Parents   []any      `json:"parents"`
+	Commit      GitCommit            `json:"commit"`
+	SHA         string               `json:"sha"`
+	NodeID      string               `json:"node_id,omitempty"`
+	URL         string               `json:"url"`
+	HTMLURL     string               `json:"html_url,omitempty"`
+	CommentsURL string               `json:"comments_url,omitempty"`
+	Author      GitHubUser           `json:"author"`
+	Committer   GitHubUser           `json:"committer"`
+	Parents     []GitHubCommitParent `json:"parents"`
+}
+
+// Verified reports whether GitHub verified the commit signature.
+func (c GitHubCommit) Verified() bool {
+	return c.Commit.Verification != nil && c.Commit.Verification.Verified
+}
+
+// IsMerge reports whether the commit has more than one parent.
+func (c GitHubCommit) IsMerge() bool {
+	return len(c.Parents) > 1
+}
+
+type GitHubCommitParent struct {
+	SHA     string `json:"sha"`
+	URL     string `json:"url"`
+	HTMLURL string `json:"html_url,omitempty"`
+}
+
+type GitHubCommitVerification struct {
+	Verified   bool      `json:"verified"`
+	Reason     string    `json:"reason"` // "valid" | "unsigned" | "unknown_key" | "bad_email" | "unverified_email" | "expired_key" | ...
+	Signature  string    `json:"signature,omitempty"`
+	Payload    string    `json:"payload,omitempty"`
+	VerifiedAt time.Time `json:"verified_at,omitempty"`
+}
+
+type GitHubCommitStatus struct {
+	ID          int64      `json:"id"`
+	State       string     `json:"state"` // "error" | "failure" | "pending" | "success"
+	Description string     `json:"description,omitempty"`
+	TargetURL   string     `json:"target_url,omitempty"`
+	Context     string     `json:"context"`
+	Creator     GitHubUser `json:"creator"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type GitHubCombinedStatus struct {
+	State      string               `json:"state"` // "failure" | "pending" | "success"
+	SHA        string               `json:"sha"`
+	TotalCount int                  `json:"total_count"`
+	Statuses   []GitHubCommitStatus `json:"statuses"`
+}
+
+// GitHubPRFile is a file changed by a PR, as listed by the pulls files
+// endpoint.
+type GitHubPRFile struct {
+	Filename         string `json:"filename"`
+	Status           string `json:"status"` // "added" | "removed" | "modified" | "renamed" | "copied" | "changed" | "unchanged"
+	PreviousFilename string `json:"previous_filename,omitempty"`
+	Additions        int    `json:"additions"`
+	Deletions        int    `json:"deletions"`
+	Changes          int    `json:"changes"`
+	// Patch is the diff of the file, absent for binary and very large files.
+	Patch string `json:"patch,omitempty"`
 }
 
 type GitHubReview struct {
@@ -80,11 +191,62 @@ type GitHubReview struct {
 	Body     string     `json:"body,omitempty"`
 	CommitID string     `json:"commit_id,omitempty"`
 	State    string     `json:"state,omitempty"` // APPROVED | CHANGES_REQUESTED | COMMENTED | PENDING | DISMISSED
+	// SubmittedAt is zero for pending reviews.
+	SubmittedAt time.Time `json:"submitted_at,omitempty"`
 }
 
+// GitHubReviewers are the users and teams whose review was requested.
 type GitHubReviewers struct {
 	Users []GitHubUser `json:"users"`
-	Teams []any        `json:"teams"`
+	Teams []GitHubTeam `json:"teams"`
+}
+
+// HasReviewer reports whether a review was requested from the user login.
+func (r GitHubReviewers) HasReviewer(login string) bool {
+	for _, u := range r.Users {
+		if strings.EqualFold(u.Login, login) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasReviewerFromTeam reports whether a review was requested from the team
+// with the given slug, with or without the organization, e.g. "backend" or
+// "my-org/backend".
+func (r GitHubReviewers) HasReviewerFromTeam(slug string) bool {
+	if i := strings.LastIndex(slug, "/"); i >= 0 {
+		slug = slug[i+1:]
+	}
+	for _, t := range r.Teams {
+		if strings.EqualFold(t.Slug, slug) {
+			return true
+		}
+	}
+	return false
+}
+
+// TeamSlugs returns the slugs of the requested teams.
+func (r GitHubReviewers) TeamSlugs() []string {
+	slugs := make([]string, 0, len(r.Teams))
+	for _, t := range r.Teams {
+		slugs = append(slugs, t.Slug)
+	}
+	return slugs
+}
+
+type GitHubTeam struct {
+	ID          int64  `json:"id"`
+	NodeID      string `json:"node_id,omitempty"`
+	URL         string `json:"url"`
+	HTMLURL     string `json:"html_url"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Description string `json:"description,omitempty"`
+	Privacy     string `json:"privacy"`    // "closed" | "secret"
+	Permission  string `json:"permission"` // "pull" | "triage" | "push" | "maintain" | "admin"
+	// Parent is the parent team of a nested team.
+	Parent *GitHubTeam `json:"parent,omitempty"`
 }
 
 type GitHubMilestone struct {
@@ -0,0 +1,78 @@
+package dangerJs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubCommitJSON(t *testing.T) {
+	payload := `{
+		"sha": "6dcb09b",
+		"node_id": "MDY6Q29tbWl0",
+		"url": "https://api.github.com/repos/o/r/commits/6dcb09b",
+		"html_url": "https://github.com/o/r/commit/6dcb09b",
+		"commit": {
+			"author": {"name": "Mona", "email": "mona@example.com", "date": "2024-01-01T00:00:00Z"},
+			"committer": {"name": "GitHub", "email": "noreply@github.com", "date": "2024-01-01T00:00:00Z"},
+			"message": "Fix all the bugs",
+			"verification": {"verified": true, "reason": "valid", "signature": "-----BEGIN PGP SIGNATURE-----"}
+		},
+		"author": {"login": "octocat", "id": 1, "type": "User"},
+		"committer": {"login": "web-flow", "id": 2, "type": "User"},
+		"parents": [
+			{"sha": "a1", "url": "https://api.github.com/repos/o/r/commits/a1"},
+			{"sha": "b2", "url": "https://api.github.com/repos/o/r/commits/b2"}
+		]
+	}`
+
+	var c GitHubCommit
+	require.NoError(t, json.Unmarshal([]byte(payload), &c))
+	require.True(t, c.Verified())
+	require.Equal(t, "valid", c.Commit.Verification.Reason)
+	require.True(t, c.IsMerge())
+	require.Equal(t, "octocat", c.Author.Login)
+	require.Equal(t, "mona@example.com", c.Commit.Author.Email)
+	require.Equal(t, "b2", c.Parents[1].SHA)
+
+	var unsigned GitHubCommit
+	require.NoError(t, json.Unmarshal([]byte(`{"sha":"1","commit":{"message":"x"},"author":null,"parents":[]}`), &unsigned))
+	require.False(t, unsigned.Verified())
+	require.False(t, unsigned.IsMerge())
+	require.Empty(t, unsigned.Author.Login)
+}
+
+func TestGitLabMRCommitIdentities(t *testing.T) {
+	c := GitLabMRCommit{
+		AuthorName: "Alice", AuthorEmail: "alice@example.com", AuthoredDate: "2024-01-01",
+		CommitterName: "Bob", CommitterEmail: "bob@example.com", CommittedDate: "2024-01-02",
+		ParentIDs: []string{"a"},
+	}
+	require.Equal(t, GitCommitAuthor{Name: "Alice", Email: "alice@example.com", Date: "2024-01-01"}, c.Author())
+	require.Equal(t, GitCommitAuthor{Name: "Bob", Email: "bob@example.com", Date: "2024-01-02"}, c.Committer())
+	require.False(t, c.IsMerge())
+}
+
+func TestGitHubReviewers(t *testing.T) {
+	payload := `{
+		"users": [{"login": "octocat", "id": 1, "type": "User"}],
+		"teams": [{
+			"id": 7, "name": "Backend", "slug": "backend", "privacy": "closed", "permission": "push",
+			"html_url": "https://github.com/orgs/my-org/teams/backend",
+			"parent": {"id": 6, "name": "Engineering", "slug": "engineering"}
+		}]
+	}`
+
+	var r GitHubReviewers
+	require.NoError(t, json.Unmarshal([]byte(payload), &r))
+	require.Equal(t, "Engineering", r.Teams[0].Parent.Name)
+	require.Equal(t, []string{"backend"}, r.TeamSlugs())
+
+	require.True(t, r.HasReviewer("OctoCat"))
+	require.False(t, r.HasReviewer("hubot"))
+	require.True(t, r.HasReviewerFromTeam("backend"))
+	require.True(t, r.HasReviewerFromTeam("my-org/Backend"))
+	require.False(t, r.HasReviewerFromTeam("engineering"))
+	require.Empty(t, GitHubReviewers{}.TeamSlugs())
+}
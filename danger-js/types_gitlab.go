@@ -33,7 +33,10 @@ type GitLabMRBase struct {
 	WorkInProgress            bool            `json:"work_in_progress"`
 	Milestone                 GitLabMileStone `json:"milestone"`
 	MergeWhenPipelineSucceeds bool            `json:"merge_when_pipeline_succeeds"`
-	MergeStatus               string          `json:"merge_status"` // "can_be_merged"
+	MergeStatus               string          `json:"merge_status"`                    // "can_be_merged"
+	DetailedMergeStatus       string          `json:"detailed_merge_status,omitempty"` // "mergeable" | "conflict" | "need_rebase" | "checking" | "unchecked" | ...
+	HasConflicts              bool            `json:"has_conflicts"`
+	FirstContribution         bool            `json:"first_contribution"`
 	MergeError                any             `json:"merge_error"`
 	SHA                       string          `json:"sha"`
 	MergeCommitSHA            string          `json:"merge_commit_sha,omitempty"`
@@ -54,6 +57,7 @@ type GitLabUser struct {
 	State     string `json:"state"` // "active" | "blocked"
 	AvatarURL string `json:"avatar_url,omitempty"`
 	WebURL    string `json:"web_url"`
+	Bot       bool   `json:"bot,omitempty"`
 }
 
 type GitLabMileStone struct {
@@ -112,18 +116,35 @@ type gitLabMRDiffRefs struct {
 }
 
 type GitLabMRCommit struct {
-	ID             string   `json:"id"`
-	ShortID        string   `json:"short_id"`
-	CreatedAt      string   `json:"created_at"`
-	ParentIDs      []string `json:"parent_ids"`
-	Title          string   `json:"title"`
-	Message        string   `json:"message"`
-	AuthorName     string   `json:"author_name"`
-	AuthorEmail    string   `json:"author_email"`
-	AuthoredDate   string   `json:"authored_date"`
-	CommitterName  string   `json:"committer_name"`
-	CommitterEmail string   `json:"committer_email"`
-	CommittedDate  string   `json:"committed_date"`
+	ID             string            `json:"id"`
+	ShortID        string            `json:"short_id"`
+	CreatedAt      string            `json:"created_at"`
+	ParentIDs      []string          `json:"parent_ids"`
+	Title          string            `json:"title"`
+	Message        string            `json:"message"`
+	AuthorName     string            `json:"author_name"`
+	AuthorEmail    string            `json:"author_email"`
+	AuthoredDate   string            `json:"authored_date"`
+	CommitterName  string            `json:"committer_name"`
+	CommitterEmail string            `json:"committer_email"`
+	CommittedDate  string            `json:"committed_date"`
+	WebURL         string            `json:"web_url,omitempty"`
+	Trailers       map[string]string `json:"trailers,omitempty"`
+}
+
+// Author returns the git identity which authored the commit.
+func (c GitLabMRCommit) Author() GitCommitAuthor {
+	return GitCommitAuthor{Name: c.AuthorName, Email: c.AuthorEmail, Date: c.AuthoredDate}
+}
+
+// Committer returns the git identity which committed the commit.
+func (c GitLabMRCommit) Committer() GitCommitAuthor {
+	return GitCommitAuthor{Name: c.CommitterName, Email: c.CommitterEmail, Date: c.CommittedDate}
+}
+
+// IsMerge reports whether the commit has more than one parent.
+func (c GitLabMRCommit) IsMerge() bool {
+	return len(c.ParentIDs) > 1
 }
 
 type GitLabApproval struct {
@@ -0,0 +1,185 @@
+package dangerJs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WordOp describes how a token changed within a line.
+type WordOp int
+
+const (
+	WordEqual WordOp = iota
+	WordAdded
+	WordRemoved
+)
+
+// WordToken is a run of text within a line with the same change type.
+type WordToken struct {
+	Op   WordOp
+	Text string
+}
+
+// WordDiffLine is a changed line broken down into the tokens that stayed the
+// same, were added and were removed.
+type WordDiffLine struct {
+	// OldLine is the line number at the base ref, 0 for lines which only
+	// exist at the head ref.
+	OldLine int
+	// NewLine is the line number at the head ref, 0 for lines which were
+	// removed entirely.
+	NewLine int
+	Tokens  []WordToken
+}
+
+// Old returns the line as it was at the base ref.
+func (l WordDiffLine) Old() string {
+	return l.join(WordRemoved)
+}
+
+// New returns the line as it is at the head ref.
+func (l WordDiffLine) New() string {
+	return l.join(WordAdded)
+}
+
+func (l WordDiffLine) join(include WordOp) string {
+	var sb strings.Builder
+	for _, t := range l.Tokens {
+		if t.Op == WordEqual || t.Op == include {
+			sb.WriteString(t.Text)
+		}
+	}
+	return sb.String()
+}
+
+// Changes returns only the added and removed tokens.
+func (l WordDiffLine) Changes() []WordToken {
+	var changes []WordToken
+	for _, t := range l.Tokens {
+		if t.Op != WordEqual {
+			changes = append(changes, t)
+		}
+	}
+	return changes
+}
+
+// WordDiff holds the intra-line changes of a file.
+type WordDiff struct {
+	Lines []WordDiffLine
+}
+
+// ForLine returns the word diff of the line numbered n at the head ref.
+func (w WordDiff) ForLine(n int) (WordDiffLine, bool) {
+	for _, l := range w.Lines {
+		if l.NewLine == n {
+			return l, true
+		}
+	}
+	return WordDiffLine{}, false
+}
+
+// WordDiffForFileWithRefs returns the word diff of a file between two refs,
+// using git's whitespace-delimited word splitting.
+func (g gitImpl) WordDiffForFileWithRefs(filePath, baseRef, headRef string) (WordDiff, error) {
+	filePath = NormalizePath(filePath)
+	if !validateFilePath(filePath) {
+		return WordDiff{}, fmt.Errorf("invalid file path: %s", filePath)
+	}
+	if !validateGitRef(baseRef) {
+		return WordDiff{}, fmt.Errorf("invalid base ref: %s", baseRef)
+	}
+	if !validateGitRef(headRef) {
+		return WordDiff{}, fmt.Errorf("invalid head ref: %s", headRef)
+	}
+
+	out, err := g.runGitWithRefs([]string{baseRef, headRef}, func(r []string) []string {
+		return []string{"diff", "--unified=0", "--word-diff=porcelain", r[0], r[1], "--", filePath}
+	})
+	if err != nil {
+		return WordDiff{}, err
+	}
+	return parseWordDiff(out), nil
+}
+
+// parseWordDiff parses `git diff --word-diff=porcelain` output. Each token is
+// on its own line prefixed by ' ', '+' or '-', and '~' ends a line of the
+// file.
+func parseWordDiff(diffContent string) WordDiff {
+	var wd WordDiff
+	oldLine, newLine := -1, -1
+	// oldLeft and newLeft are the lines of the hunk not yet seen, which
+	// tell what the empty lines are
+	var oldLeft, newLeft int
+	var tokens []WordToken
+
+	for _, line := range strings.Split(normalizeLineEndings(diffContent), "\n") {
+		if removedStart, addedStart, isHunk := parseHunkHeader(line); isHunk {
+			oldLine, newLine = removedStart, addedStart
+			oldLeft, newLeft = hunkCounts(line)
+			tokens = nil
+			continue
+		}
+		if oldLine < 0 || line == "" {
+			// Skip the file headers before the first hunk
+			continue
+		}
+
+		switch line[0] {
+		case ' ':
+			tokens = append(tokens, WordToken{Op: WordEqual, Text: line[1:]})
+		case '+':
+			tokens = append(tokens, WordToken{Op: WordAdded, Text: line[1:]})
+		case '-':
+			tokens = append(tokens, WordToken{Op: WordRemoved, Text: line[1:]})
+		case '~':
+			if len(tokens) == 0 {
+				// an empty line, added when the hunk has more new lines
+				// left, removed when it has more old ones, else unchanged
+				switch {
+				case newLeft > oldLeft:
+					newLine, newLeft = newLine+1, newLeft-1
+				case oldLeft > newLeft:
+					oldLine, oldLeft = oldLine+1, oldLeft-1
+				default:
+					oldLine, oldLeft = oldLine+1, oldLeft-1
+					newLine, newLeft = newLine+1, newLeft-1
+				}
+				continue
+			}
+			var hasOld, hasNew bool
+			for _, t := range tokens {
+				hasOld = hasOld || t.Op != WordAdded
+				hasNew = hasNew || t.Op != WordRemoved
+			}
+			l := WordDiffLine{Tokens: tokens}
+			if hasOld {
+				l.OldLine = oldLine
+				oldLine++
+				oldLeft--
+			}
+			if hasNew {
+				l.NewLine = newLine
+				newLine++
+				newLeft--
+			}
+			wd.Lines = append(wd.Lines, l)
+			tokens = nil
+		}
+	}
+	return wd
+}
+
+// hunkCounts returns the old and new line counts of a hunk header, which
+// default to 1 when left out.
+func hunkCounts(header string) (oldCount, newCount int) {
+	m := hunkHeaderRe.FindStringSubmatch(header)
+	count := func(s string) int {
+		if s == "" {
+			return 1
+		}
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+	return count(m[2]), count(m[4])
+}
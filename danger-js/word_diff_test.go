@@ -0,0 +1,91 @@
+package dangerJs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWordDiff(t *testing.T) {
+	out := `diff --git a/c.txt b/c.txt
+index 1fa7205..4ddbe20 100644
+--- a/c.txt
++++ b/c.txt
+@@ -1 +1 @@
+ timeout = ` + `
+-30
++60
+~
+@@ -2,0 +3 @@ name = foo
++new line here
+~
+@@ -7 +7,0 @@
+-removed line
+~`
+
+	wd := parseWordDiff(out)
+	require.Equal(t, WordDiff{Lines: []WordDiffLine{
+		{
+			OldLine: 1,
+			NewLine: 1,
+			Tokens: []WordToken{
+				{Op: WordEqual, Text: "timeout = "},
+				{Op: WordRemoved, Text: "30"},
+				{Op: WordAdded, Text: "60"},
+			},
+		},
+		{
+			NewLine: 3,
+			Tokens:  []WordToken{{Op: WordAdded, Text: "new line here"}},
+		},
+		{
+			OldLine: 7,
+			Tokens:  []WordToken{{Op: WordRemoved, Text: "removed line"}},
+		},
+	}}, wd)
+
+	l, ok := wd.ForLine(1)
+	require.True(t, ok)
+	require.Equal(t, "timeout = 30", l.Old())
+	require.Equal(t, "timeout = 60", l.New())
+	require.Equal(t, []WordToken{
+		{Op: WordRemoved, Text: "30"},
+		{Op: WordAdded, Text: "60"},
+	}, l.Changes())
+
+	_, ok = wd.ForLine(2)
+	require.False(t, ok)
+}
+
+func TestParseWordDiffEmptyLines(t *testing.T) {
+	testCases := []struct {
+		name string
+		out  string
+		exp  WordDiffLine
+	}{
+		{
+			name: "added",
+			out:  "@@ -2 +2,2 @@ a = 1\n~\n b = \n-5\n+6\n~",
+			exp:  WordDiffLine{OldLine: 2, NewLine: 3, Tokens: []WordToken{{Op: WordEqual, Text: "b = "}, {Op: WordRemoved, Text: "5"}, {Op: WordAdded, Text: "6"}}},
+		},
+		{
+			name: "removed",
+			out:  "@@ -2,2 +2 @@ a = 1\n~\n b = \n-5\n+6\n~",
+			exp:  WordDiffLine{OldLine: 3, NewLine: 2, Tokens: []WordToken{{Op: WordEqual, Text: "b = "}, {Op: WordRemoved, Text: "5"}, {Op: WordAdded, Text: "6"}}},
+		},
+		{
+			name: "context",
+			out:  "@@ -2,2 +2,2 @@ a = 1\n~\n b = \n-5\n+6\n~",
+			exp:  WordDiffLine{OldLine: 3, NewLine: 3, Tokens: []WordToken{{Op: WordEqual, Text: "b = "}, {Op: WordRemoved, Text: "5"}, {Op: WordAdded, Text: "6"}}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, WordDiff{Lines: []WordDiffLine{tc.exp}}, parseWordDiff(tc.out))
+		})
+	}
+}
+
+func TestParseWordDiffEmpty(t *testing.T) {
+	require.Equal(t, WordDiff{}, parseWordDiff(""))
+}
@@ -0,0 +1,210 @@
+// Package e2e runs dangerfiles through the danger-go runner the way danger-js
+// does: the harness plays the danger-js process, sends a DSL payload with one
+// of the process protocols and decodes the results the runner replies with.
+// It catches protocol changes that unit tests of the dangerfile would miss,
+// and is usable by plugin authors to test their plugins end to end.
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	danger "github.com/danger/golang"
+	"github.com/danger/golang/cmd/danger-go/runner"
+)
+
+// Protocol is the way the DSL is handed to the runner.
+type Protocol int
+
+const (
+	// ProtocolURL sends a danger://dsl/ URL to a file holding the DSL, as
+	// danger-js does with --passURLForDSL.
+	ProtocolURL Protocol = iota
+	// ProtocolJSON sends the DSL document itself.
+	ProtocolJSON
+	// ProtocolRPC sends a JSON-RPC danger.run request carrying the DSL.
+	ProtocolRPC
+	// ProtocolRPCFile sends a JSON-RPC request asking for the results to be
+	// written to a file.
+	ProtocolRPCFile
+)
+
+func (p Protocol) String() string {
+	switch p {
+	case ProtocolJSON:
+		return "json"
+	case ProtocolRPC:
+		return "rpc"
+	case ProtocolRPCFile:
+		return "rpc-file"
+	default:
+		return "url"
+	}
+}
+
+// Protocols lists all protocols, to run a test over each of them.
+var Protocols = []Protocol{ProtocolURL, ProtocolJSON, ProtocolRPC, ProtocolRPCFile}
+
+// Harness runs a dangerfile through the runner.
+type Harness struct {
+	// Dangerfile is the function a dangerfile plugin exports as Run.
+	Dangerfile runner.MainFunc
+	Protocol   Protocol
+	// Dir holds the files the harness writes, a new temporary directory
+	// when empty.
+	Dir string
+}
+
+// Output is what the runner replied.
+type Output struct {
+	// Stdout is the raw reply on stdout.
+	Stdout string
+	// Results are the decoded results.
+	Results danger.Results
+}
+
+// Run sends dsl, the JSON danger-js puts under the "danger" key of the
+// document, to the runner and decodes its reply.
+func (h Harness) Run(ctx context.Context, dsl []byte) (Output, error) {
+	dir := h.Dir
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "danger-go-e2e-")
+		if err != nil {
+			return Output{}, fmt.Errorf("creating temp directory: %w", err)
+		}
+		defer os.RemoveAll(tmp)
+		dir = tmp
+	}
+	doc, err := json.Marshal(map[string]json.RawMessage{"danger": dsl})
+	if err != nil {
+		return Output{}, fmt.Errorf("encoding DSL document: %w", err)
+	}
+
+	in, resultsPath, err := h.request(dir, doc)
+	if err != nil {
+		return Output{}, err
+	}
+	var out bytes.Buffer
+	err = runner.Serve(ctx, in, &out, h.Dangerfile)
+	o := Output{Stdout: out.String()}
+	if err != nil {
+		return o, err
+	}
+	raw, err := h.results(o.Stdout, resultsPath)
+	if err != nil {
+		return o, err
+	}
+	if err := json.Unmarshal(raw, &o.Results); err != nil {
+		return o, fmt.Errorf("decoding results: %w", err)
+	}
+	return o, nil
+}
+
+// request builds the stdin of the runner for the protocol.
+func (h Harness) request(dir string, doc []byte) ([]byte, string, error) {
+	switch h.Protocol {
+	case ProtocolJSON:
+		return doc, "", nil
+	case ProtocolRPC, ProtocolRPCFile:
+		params := map[string]any{"version": 2, "dsl": json.RawMessage(doc)}
+		var resultsPath string
+		if h.Protocol == ProtocolRPCFile {
+			resultsPath = filepath.Join(dir, "results.json")
+			params["resultsPath"] = resultsPath
+		}
+		in, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": 1, "method": "danger.run", "params": params})
+		if err != nil {
+			return nil, "", fmt.Errorf("encoding request: %w", err)
+		}
+		return in, resultsPath, nil
+	default:
+		path := filepath.Join(dir, "dsl.json")
+		if err := os.WriteFile(path, doc, 0o600); err != nil {
+			return nil, "", fmt.Errorf("writing DSL: %w", err)
+		}
+		return []byte("danger://dsl/" + path), "", nil
+	}
+}
+
+// results extracts the results JSON from the reply.
+func (h Harness) results(stdout, resultsPath string) ([]byte, error) {
+	if h.Protocol != ProtocolRPC && h.Protocol != ProtocolRPCFile {
+		return []byte(stdout), nil
+	}
+	var resp struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Result  json.RawMessage `json:"result"`
+		Error   *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &resp); err != nil {
+		return nil, fmt.Errorf("decoding JSON-RPC response: %w", err)
+	}
+	switch {
+	case resp.JSONRPC != "2.0" || string(resp.ID) != "1":
+		return nil, fmt.Errorf("invalid JSON-RPC response: %s", strings.TrimSpace(stdout))
+	case resp.Error != nil:
+		return nil, fmt.Errorf("JSON-RPC error %d: %s", resp.Error.Code, resp.Error.Message)
+	case resultsPath == "":
+		return resp.Result, nil
+	}
+	var ref struct {
+		ResultsPath string `json:"resultsPath"`
+	}
+	if err := json.Unmarshal(resp.Result, &ref); err != nil || ref.ResultsPath != resultsPath {
+		return nil, fmt.Errorf("JSON-RPC result doesn't point to %s: %s", resultsPath, resp.Result)
+	}
+	raw, err := os.ReadFile(resultsPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading results: %w", err)
+	}
+	return raw, nil
+}
+
+// Transcript is a recorded exchange with the runner: the stdin danger-js
+// sent and the stdout it got back.
+type Transcript struct {
+	Stdin  string `json:"stdin"`
+	Stdout string `json:"stdout"`
+}
+
+// ErrTranscriptMismatch is returned by Replay when the runner's reply
+// differs from the recorded one.
+var ErrTranscriptMismatch = errors.New("reply differs from the transcript")
+
+// ReadTranscript reads a transcript saved as JSON.
+func ReadTranscript(path string) (Transcript, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("reading transcript: %w", err)
+	}
+	var t Transcript
+	if err := json.Unmarshal(data, &t); err != nil {
+		return Transcript{}, fmt.Errorf("decoding transcript %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// Replay sends the recorded stdin to the runner running fn and compares the
+// reply with the recorded stdout, ignoring surrounding whitespace. It
+// returns the reply, which can be saved as the new transcript when the
+// change is intended.
+func Replay(ctx context.Context, t Transcript, fn runner.MainFunc) (string, error) {
+	var out bytes.Buffer
+	if err := runner.Serve(ctx, []byte(t.Stdin), &out, fn); err != nil {
+		return out.String(), err
+	}
+	if strings.TrimSpace(out.String()) != strings.TrimSpace(t.Stdout) {
+		return out.String(), fmt.Errorf("%w:\nwant %s\n got %s", ErrTranscriptMismatch, strings.TrimSpace(t.Stdout), strings.TrimSpace(out.String()))
+	}
+	return out.String(), nil
+}
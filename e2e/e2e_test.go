@@ -0,0 +1,60 @@
+package e2e_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	"github.com/danger/golang/e2e"
+)
+
+func dangerfile(d *danger.T, pr danger.DSL) {
+	for _, f := range pr.Git.ModifiedFiles() {
+		d.Warn(fmt.Sprintf("`%s` was modified", f), f, 0)
+	}
+	if pr.GitHub.PR().Body == "" {
+		d.Fail("Please add a description", "", 0)
+	}
+}
+
+const dsl = `{
+	"git": {"modified_files": ["go.mod"], "created_files": [], "deleted_files": [], "commits": []},
+	"github": {"pr": {"number": 1, "title": "Bump deps", "body": ""}},
+	"settings": {"github": {}, "cliArgs": {}}
+}`
+
+func TestHarness(t *testing.T) {
+	t.Setenv("DANGER_RESULTS_FILE", filepath.Join(t.TempDir(), "results.json"))
+	for _, p := range e2e.Protocols {
+		t.Run(p.String(), func(t *testing.T) {
+			h := e2e.Harness{Dangerfile: dangerfile, Protocol: p, Dir: t.TempDir()}
+			out, err := h.Run(context.Background(), []byte(dsl))
+			require.Nil(t, err)
+			require.Equal(t, []danger.Violation{{Message: "Please add a description"}}, out.Results.Fails)
+			require.Equal(t, []danger.Violation{{Message: "`go.mod` was modified", File: "go.mod"}}, out.Results.Warnings)
+		})
+	}
+}
+
+func TestHarnessMissingGit(t *testing.T) {
+	h := e2e.Harness{Dangerfile: dangerfile, Protocol: e2e.ProtocolRPC}
+	out, err := h.Run(context.Background(), []byte(`{"settings": {}}`))
+	require.ErrorContains(t, err, "DSL field missing: git")
+	require.Contains(t, out.Stdout, `"error":{"code":-32000`)
+}
+
+func TestReplay(t *testing.T) {
+	t.Setenv("DANGER_RESULTS_FILE", filepath.Join(t.TempDir(), "results.json"))
+	tr, err := e2e.ReadTranscript(filepath.Join("testdata", "rpc.json"))
+	require.Nil(t, err)
+	_, err = e2e.Replay(context.Background(), tr, dangerfile)
+	require.Nil(t, err)
+
+	tr.Stdout = `{"jsonrpc":"2.0","id":7,"result":{}}`
+	_, err = e2e.Replay(context.Background(), tr, dangerfile)
+	require.ErrorIs(t, err, e2e.ErrTranscriptMismatch)
+}
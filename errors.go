@@ -0,0 +1,14 @@
+package danger
+
+import dangerJs "github.com/danger/golang/danger-js"
+
+// Errors returned by the DSL, re-exported so dangerfiles can use errors.Is
+// without importing the danger-js package.
+var (
+	ErrNoGitRepo       = dangerJs.ErrNoGitRepo
+	ErrRefNotFound     = dangerJs.ErrRefNotFound
+	ErrAPIUnauthorized = dangerJs.ErrAPIUnauthorized
+	ErrDSLFieldMissing = dangerJs.ErrDSLFieldMissing
+	ErrShallowClone    = dangerJs.ErrShallowClone
+	ErrReadOnly        = dangerJs.ErrReadOnly
+)
@@ -0,0 +1,89 @@
+package fakeapi_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakeapi"
+	"github.com/danger/golang/githubclient"
+	"github.com/danger/golang/gitlabclient"
+)
+
+func TestGitHub(t *testing.T) {
+	gh := fakeapi.NewGitHub(t, "o", "r")
+	gh.SetPR(dangerJs.GitHubPR{Number: 5, Title: "Add cache", Body: "Author text"})
+	gh.AddComment(5, "alice", "LGTM")
+
+	ops, err := danger.NewGitHubOps(gh.DSL(t, 5))
+	require.Nil(t, err)
+	require.Nil(t, ops.UpdatePRSection("coverage", "80%"))
+	require.Equal(t, "Author text\n\n<!-- danger:begin coverage -->\n80%\n<!-- danger:end coverage -->", gh.PR(5).Body)
+	require.Nil(t, ops.CommentOnIssue(5, "note", "hi"))
+
+	comments := gh.Comments(5)
+	require.Len(t, comments, 2)
+	require.Equal(t, "alice", comments[0].User.Login)
+	require.Equal(t, "hi\n\n<!-- danger:issue note -->", comments[1].Body)
+	req := gh.RequireRequest(t, http.MethodPost, "/api/v3/repos/o/r/issues/5/comments")
+	var body map[string]string
+	require.Nil(t, req.JSON(&body))
+	require.Equal(t, "hi\n\n<!-- danger:issue note -->", body["body"])
+
+	client, err := githubclient.New(gh.URL, fakeapi.Token)
+	require.Nil(t, err)
+	ctx := context.Background()
+	require.Nil(t, client.AddLabels(ctx, "o", "r", 5, "cache", "perf"))
+	require.Nil(t, client.RemoveLabel(ctx, "o", "r", 5, "perf"))
+	require.Equal(t, []string{"cache"}, gh.Labels(5))
+
+	_, err = client.PullRequest(ctx, "o", "other", 5)
+	require.Error(t, err)
+}
+
+func TestGitHubOverride(t *testing.T) {
+	gh := fakeapi.NewGitHub(t, "o", "r")
+	gh.Handle("GET /api/v3/repos/o/r/pulls/{number}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	client, err := githubclient.New(gh.URL, fakeapi.Token)
+	require.Nil(t, err)
+	_, err = client.PullRequest(context.Background(), "o", "r", 1)
+	require.ErrorIs(t, err, dangerJs.ErrAPIUnauthorized)
+	gh.RequireNoWrites(t)
+}
+
+func TestGitLab(t *testing.T) {
+	gl := fakeapi.NewGitLab(t, "group/project")
+	gl.SetMR(dangerJs.GitLabMR{GitLabMRBase: dangerJs.GitLabMRBase{IID: 3, Title: "Fix", Labels: []string{"bug"}}})
+
+	pr := gl.DSL(t, 3)
+	require.Equal(t, "group/project", pr.GitLab.Metadata().RepoSlug)
+	client, err := gitlabclient.NewFromSettings(pr.Settings)
+	require.Nil(t, err)
+	ctx := context.Background()
+
+	mr, err := client.MR(ctx, "group/project", 3)
+	require.Nil(t, err)
+	require.Equal(t, "Fix", mr.Title)
+
+	note, err := client.CreateMRNote(ctx, "group/project", 3, "first")
+	require.Nil(t, err)
+	_, err = client.UpdateMRNote(ctx, "group/project", 3, note.ID, "edited")
+	require.Nil(t, err)
+	require.Equal(t, []gitlabclient.Note{{ID: note.ID, Body: "edited"}}, gl.Notes(3))
+
+	require.Nil(t, client.UpdateMRLabels(ctx, "group/project", 3, []string{"danger"}, []string{"bug"}))
+	require.Equal(t, []string{"danger"}, gl.MR(3).Labels)
+
+	_, err = client.SetCommitStatus(ctx, "group/project", "abc", gitlabclient.CommitStatus{Name: "danger", State: "success"})
+	require.Nil(t, err)
+	statuses := gl.Statuses("abc")
+	require.Len(t, statuses, 1)
+	require.Equal(t, "success", statuses[0].State)
+	gl.RequireRequest(t, http.MethodPost, "/api/v4/projects/group%2Fproject/statuses/abc")
+}
@@ -0,0 +1,358 @@
+package fakeapi
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// GitHubFile is a file of the PR as listed by the pulls files endpoint.
+type GitHubFile struct {
+	Filename  string `json:"filename"`
+	Status    string `json:"status"` // "added" | "modified" | "removed" | "renamed"
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Patch     string `json:"patch,omitempty"`
+}
+
+// GitHub is a fake GitHub REST API serving a single repository, with the
+// endpoints for pull requests, their files, commits and reviews, issue
+// comments, labels and commit statuses. The token's user, who posts the
+// comments created through the API, is danger-bot. The API root is URL + "/api/v3", as
+// for GitHub Enterprise Server, which githubclient.New derives from URL.
+type GitHub struct {
+	*server
+	Owner string
+	Repo  string
+
+	prs      map[int]dangerJs.GitHubPR
+	files    map[int][]GitHubFile
+	labels   map[int][]string
+	comments []githubComment
+	statuses map[string][]dangerJs.GitHubCommitStatus
+	nextID   int64
+}
+
+type githubComment struct {
+	number  int
+	comment dangerJs.GitHubIssueComment
+}
+
+// NewGitHub starts a fake GitHub for owner/repo, closed when the test ends.
+func NewGitHub(t testing.TB, owner, repo string) *GitHub {
+	g := &GitHub{
+		Owner:    owner,
+		Repo:     repo,
+		prs:      map[int]dangerJs.GitHubPR{},
+		files:    map[int][]GitHubFile{},
+		labels:   map[int][]string{},
+		statuses: map[string][]dangerJs.GitHubCommitStatus{},
+	}
+	g.server = newServer(t, g.routes)
+	return g
+}
+
+// DSL returns a DSL for PR number whose settings point GitHub clients, e.g.
+// danger.NewGitHubOps, at the server.
+func (g *GitHub) DSL(t testing.TB, number int) danger.DSL {
+	t.Helper()
+	g.mu.Lock()
+	pr := g.prs[number]
+	g.mu.Unlock()
+	pr.Number = number
+	data, err := dangerJs.DecodeDSL(mustJSON(t, map[string]any{
+		"git": map[string]any{},
+		"github": map[string]any{
+			"pr":     pr,
+			"issue":  map[string]any{"labels": issueLabels(g.Labels(number))},
+			"thisPR": map[string]any{"owner": g.Owner, "repo": g.Repo, "number": number},
+		},
+		"settings": map[string]any{"github": map[string]any{"accessToken": Token, "baseURL": g.URL}},
+	}))
+	if err != nil {
+		t.Fatalf("decoding DSL: %s", err)
+	}
+	return data.ToInterface()
+}
+
+// SetPR adds or replaces a pull request.
+func (g *GitHub) SetPR(pr dangerJs.GitHubPR) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.prs[pr.Number] = pr
+}
+
+// PR returns the current state of a pull request.
+func (g *GitHub) PR(number int) dangerJs.GitHubPR {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.prs[number]
+}
+
+// SetFiles sets the files of a pull request.
+func (g *GitHub) SetFiles(number int, files ...GitHubFile) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.files[number] = files
+}
+
+// Labels returns the labels of an issue or pull request.
+func (g *GitHub) Labels(number int) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return slices.Clone(g.labels[number])
+}
+
+// AddComment adds a comment to an issue or pull request as if posted by
+// user, returning its ID.
+func (g *GitHub) AddComment(number int, user, body string) int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.addComment(number, user, body).ID
+}
+
+// Comments returns the comments of an issue or pull request.
+func (g *GitHub) Comments(number int) []dangerJs.GitHubIssueComment {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var out []dangerJs.GitHubIssueComment
+	for _, c := range g.comments {
+		if c.number == number {
+			out = append(out, c.comment)
+		}
+	}
+	return out
+}
+
+// Statuses returns the statuses set on a commit.
+func (g *GitHub) Statuses(sha string) []dangerJs.GitHubCommitStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return slices.Clone(g.statuses[sha])
+}
+
+func (g *GitHub) addComment(number int, user, body string) dangerJs.GitHubIssueComment {
+	g.nextID++
+	now := time.Now().UTC()
+	c := dangerJs.GitHubIssueComment{
+		ID:        g.nextID,
+		Body:      body,
+		User:      dangerJs.GitHubUser{Login: user},
+		HTMLURL:   fmt.Sprintf("%s/%s/%s/issues/%d#issuecomment-%d", g.URL, g.Owner, g.Repo, number, g.nextID),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	g.comments = append(g.comments, githubComment{number: number, comment: c})
+	return c
+}
+
+func (g *GitHub) routes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v3/user", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, dangerJs.GitHubUser{Login: "danger-bot", Type: "Bot"})
+	})
+	repo := "/api/v3/repos/{owner}/{repo}/"
+	handle := func(pattern string, h func(w http.ResponseWriter, r *http.Request)) {
+		method, path, _ := strings.Cut(pattern, " ")
+		mux.HandleFunc(method+" "+repo+path, func(w http.ResponseWriter, r *http.Request) {
+			if r.PathValue("owner") != g.Owner || r.PathValue("repo") != g.Repo {
+				notFound(w)
+				return
+			}
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			h(w, r)
+		})
+	}
+
+	handle("GET pulls/{number}", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := pathInt(r, "number")
+		pr, ok := g.prs[int(n)]
+		if !ok {
+			notFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, pr)
+	})
+	handle("PATCH pulls/{number}", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := pathInt(r, "number")
+		pr, ok := g.prs[int(n)]
+		if !ok {
+			notFound(w)
+			return
+		}
+		var fields struct {
+			Title *string `json:"title"`
+			Body  *string `json:"body"`
+			State *string `json:"state"`
+		}
+		if err := decodeBody(r, &fields); err != nil {
+			badRequest(w, err)
+			return
+		}
+		if fields.Title != nil {
+			pr.Title = *fields.Title
+		}
+		if fields.Body != nil {
+			pr.Body = *fields.Body
+		}
+		if fields.State != nil {
+			pr.State = *fields.State
+		}
+		g.prs[int(n)] = pr
+		writeJSON(w, http.StatusOK, pr)
+	})
+	handle("GET pulls/{number}/files", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := pathInt(r, "number")
+		writeJSON(w, http.StatusOK, page(r, g.files[int(n)]))
+	})
+	handle("GET pulls/{number}/commits", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, []any{})
+	})
+	handle("GET pulls/{number}/reviews", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, []any{})
+	})
+
+	handle("GET issues/{number}/comments", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := pathInt(r, "number")
+		var out []dangerJs.GitHubIssueComment
+		for _, c := range g.comments {
+			if c.number == int(n) {
+				out = append(out, c.comment)
+			}
+		}
+		writeJSON(w, http.StatusOK, page(r, out))
+	})
+	handle("POST issues/{number}/comments", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := pathInt(r, "number")
+		var fields struct {
+			Body string `json:"body"`
+		}
+		if err := decodeBody(r, &fields); err != nil {
+			badRequest(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, g.addComment(int(n), "danger-bot", fields.Body))
+	})
+	handle("PATCH issues/comments/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := pathInt(r, "id")
+		var fields struct {
+			Body string `json:"body"`
+		}
+		if err := decodeBody(r, &fields); err != nil {
+			badRequest(w, err)
+			return
+		}
+		for i, c := range g.comments {
+			if c.comment.ID == id {
+				g.comments[i].comment.Body = fields.Body
+				g.comments[i].comment.UpdatedAt = time.Now().UTC()
+				writeJSON(w, http.StatusOK, g.comments[i].comment)
+				return
+			}
+		}
+		notFound(w)
+	})
+	handle("DELETE issues/comments/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := pathInt(r, "id")
+		for i, c := range g.comments {
+			if c.comment.ID == id {
+				g.comments = slices.Delete(g.comments, i, i+1)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		notFound(w)
+	})
+
+	handle("POST issues/{number}/labels", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := pathInt(r, "number")
+		var fields struct {
+			Labels []string `json:"labels"`
+		}
+		if err := decodeBody(r, &fields); err != nil {
+			badRequest(w, err)
+			return
+		}
+		for _, l := range fields.Labels {
+			if !slices.Contains(g.labels[int(n)], l) {
+				g.labels[int(n)] = append(g.labels[int(n)], l)
+			}
+		}
+		writeJSON(w, http.StatusOK, issueLabels(g.labels[int(n)]))
+	})
+	handle("DELETE issues/{number}/labels/{label}", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := pathInt(r, "number")
+		i := slices.Index(g.labels[int(n)], r.PathValue("label"))
+		if i < 0 {
+			notFound(w)
+			return
+		}
+		g.labels[int(n)] = slices.Delete(g.labels[int(n)], i, i+1)
+		writeJSON(w, http.StatusOK, issueLabels(g.labels[int(n)]))
+	})
+
+	handle("POST statuses/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		var s dangerJs.GitHubCommitStatus
+		if err := decodeBody(r, &s); err != nil {
+			badRequest(w, err)
+			return
+		}
+		g.nextID++
+		s.ID = g.nextID
+		s.CreatedAt = time.Now().UTC()
+		s.UpdatedAt = s.CreatedAt
+		sha := r.PathValue("sha")
+		g.statuses[sha] = append(g.statuses[sha], s)
+		writeJSON(w, http.StatusCreated, s)
+	})
+	handle("GET commits/{ref}/status", func(w http.ResponseWriter, r *http.Request) {
+		sha := r.PathValue("ref")
+		// the latest status of each context, newest first
+		var latest []dangerJs.GitHubCommitStatus
+		seen := map[string]bool{}
+		all := g.statuses[sha]
+		for i := len(all) - 1; i >= 0; i-- {
+			if !seen[all[i].Context] {
+				seen[all[i].Context] = true
+				latest = append(latest, all[i])
+			}
+		}
+		writeJSON(w, http.StatusOK, dangerJs.GitHubCombinedStatus{
+			State:      combinedState(latest),
+			SHA:        sha,
+			TotalCount: len(latest),
+			Statuses:   latest,
+		})
+	})
+}
+
+// combinedState combines statuses the way GitHub does.
+func combinedState(statuses []dangerJs.GitHubCommitStatus) string {
+	state := "success"
+	if len(statuses) == 0 {
+		return "pending"
+	}
+	for _, s := range statuses {
+		switch s.State {
+		case "error", "failure":
+			return "failure"
+		case "pending":
+			state = "pending"
+		}
+	}
+	return state
+}
+
+func issueLabels(names []string) []dangerJs.GitHubIssueLabel {
+	labels := []dangerJs.GitHubIssueLabel{}
+	for _, n := range names {
+		labels = append(labels, dangerJs.GitHubIssueLabel{Name: n})
+	}
+	return labels
+}
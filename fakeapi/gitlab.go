@@ -0,0 +1,309 @@
+package fakeapi
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/gitlabclient"
+)
+
+// GitLabChange is a file of the MR as listed by the changes endpoint.
+type GitLabChange struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	NewFile     bool   `json:"new_file"`
+	RenamedFile bool   `json:"renamed_file"`
+	DeletedFile bool   `json:"deleted_file"`
+	Diff        string `json:"diff"`
+}
+
+// GitLab is a fake GitLab v4 API serving a single project, with the
+// endpoints for merge requests, their changes, commits and approvals, notes,
+// labels and commit statuses. The API root is URL + "/api/v4".
+type GitLab struct {
+	*server
+	// Project is the path of the project, e.g. "group/project".
+	Project string
+
+	mrs      map[int64]dangerJs.GitLabMR
+	changes  map[int64][]GitLabChange
+	notes    []gitlabNote
+	statuses map[string][]gitlabclient.CommitStatus
+	nextID   int64
+}
+
+type gitlabNote struct {
+	iid  int64
+	note gitlabclient.Note
+}
+
+// NewGitLab starts a fake GitLab for project, closed when the test ends.
+func NewGitLab(t testing.TB, project string) *GitLab {
+	g := &GitLab{
+		Project:  project,
+		mrs:      map[int64]dangerJs.GitLabMR{},
+		changes:  map[int64][]GitLabChange{},
+		statuses: map[string][]gitlabclient.CommitStatus{},
+	}
+	g.server = newServer(t, g.routes)
+	return g
+}
+
+// DSL returns a DSL for MR iid whose settings point GitLab clients, e.g.
+// gitlabclient.NewFromSettings, at the server.
+func (g *GitLab) DSL(t testing.TB, iid int64) danger.DSL {
+	t.Helper()
+	mr := g.MR(iid)
+	mr.IID = iid
+	data, err := dangerJs.DecodeDSL(mustJSON(t, map[string]any{
+		"git": map[string]any{},
+		"gitlab": map[string]any{
+			"mr":       mr,
+			"Metadata": dangerJs.RepoMetaData{RepoSlug: g.Project, PullRequestID: fmt.Sprint(iid)},
+		},
+		"settings": map[string]any{"gitlab": map[string]any{"accessToken": Token, "baseURL": g.URL}},
+	}))
+	if err != nil {
+		t.Fatalf("decoding DSL: %s", err)
+	}
+	return data.ToInterface()
+}
+
+// SetMR adds or replaces a merge request.
+func (g *GitLab) SetMR(mr dangerJs.GitLabMR) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.mrs[mr.IID] = mr
+}
+
+// MR returns the current state of a merge request.
+func (g *GitLab) MR(iid int64) dangerJs.GitLabMR {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.mrs[iid]
+}
+
+// SetChanges sets the changed files of a merge request.
+func (g *GitLab) SetChanges(iid int64, changes ...GitLabChange) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.changes[iid] = changes
+}
+
+// AddNote adds a note to a merge request, returning its ID.
+func (g *GitLab) AddNote(iid int64, body string) int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.addNote(iid, body).ID
+}
+
+// Notes returns the notes of a merge request.
+func (g *GitLab) Notes(iid int64) []gitlabclient.Note {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var out []gitlabclient.Note
+	for _, n := range g.notes {
+		if n.iid == iid {
+			out = append(out, n.note)
+		}
+	}
+	return out
+}
+
+// Statuses returns the statuses set on a commit.
+func (g *GitLab) Statuses(sha string) []gitlabclient.CommitStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return slices.Clone(g.statuses[sha])
+}
+
+func (g *GitLab) addNote(iid int64, body string) gitlabclient.Note {
+	g.nextID++
+	n := gitlabclient.Note{ID: g.nextID, Body: body}
+	g.notes = append(g.notes, gitlabNote{iid: iid, note: n})
+	return n
+}
+
+func (g *GitLab) routes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v4/version", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"version": "17.0.0", "revision": "fake"})
+	})
+
+	project := "/api/v4/projects/{project}/"
+	handle := func(pattern string, h func(w http.ResponseWriter, r *http.Request)) {
+		method, path, _ := strings.Cut(pattern, " ")
+		mux.HandleFunc(method+" "+project+path, func(w http.ResponseWriter, r *http.Request) {
+			if r.PathValue("project") != g.Project {
+				notFound(w)
+				return
+			}
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			h(w, r)
+		})
+	}
+	// mr returns the merge request of the path, writing a 404 when missing.
+	mr := func(w http.ResponseWriter, r *http.Request) (dangerJs.GitLabMR, bool) {
+		iid, _ := pathInt(r, "iid")
+		m, ok := g.mrs[iid]
+		if !ok {
+			notFound(w)
+		}
+		return m, ok
+	}
+
+	handle("GET merge_requests/{iid}", func(w http.ResponseWriter, r *http.Request) {
+		if m, ok := mr(w, r); ok {
+			writeJSON(w, http.StatusOK, m)
+		}
+	})
+	handle("PUT merge_requests/{iid}", func(w http.ResponseWriter, r *http.Request) {
+		m, ok := mr(w, r)
+		if !ok {
+			return
+		}
+		var fields struct {
+			Title        *string `json:"title"`
+			Description  *string `json:"description"`
+			AddLabels    string  `json:"add_labels"`
+			RemoveLabels string  `json:"remove_labels"`
+		}
+		if err := decodeBody(r, &fields); err != nil {
+			badRequest(w, err)
+			return
+		}
+		if fields.Title != nil {
+			m.Title = *fields.Title
+		}
+		if fields.Description != nil {
+			m.Description = *fields.Description
+		}
+		for _, l := range splitLabels(fields.AddLabels) {
+			if !slices.Contains(m.Labels, l) {
+				m.Labels = append(m.Labels, l)
+			}
+		}
+		for _, l := range splitLabels(fields.RemoveLabels) {
+			m.Labels = slices.DeleteFunc(m.Labels, func(x string) bool { return x == l })
+		}
+		g.mrs[m.IID] = m
+		writeJSON(w, http.StatusOK, m)
+	})
+	handle("GET merge_requests/{iid}/changes", func(w http.ResponseWriter, r *http.Request) {
+		m, ok := mr(w, r)
+		if !ok {
+			return
+		}
+		changes := g.changes[m.IID]
+		if changes == nil {
+			changes = []GitLabChange{}
+		}
+		writeJSON(w, http.StatusOK, struct {
+			dangerJs.GitLabMR
+			Changes []GitLabChange `json:"changes"`
+		}{m, changes})
+	})
+	handle("GET merge_requests/{iid}/diffs", func(w http.ResponseWriter, r *http.Request) {
+		if m, ok := mr(w, r); ok {
+			writeJSON(w, http.StatusOK, page(r, g.changes[m.IID]))
+		}
+	})
+	handle("GET merge_requests/{iid}/commits", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, []any{})
+	})
+	handle("GET merge_requests/{iid}/approvals", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, dangerJs.GitLabApproval{})
+	})
+
+	handle("GET merge_requests/{iid}/notes", func(w http.ResponseWriter, r *http.Request) {
+		iid, _ := pathInt(r, "iid")
+		out := []gitlabclient.Note{}
+		for _, n := range g.notes {
+			if n.iid == iid {
+				out = append(out, n.note)
+			}
+		}
+		writeJSON(w, http.StatusOK, page(r, out))
+	})
+	handle("POST merge_requests/{iid}/notes", func(w http.ResponseWriter, r *http.Request) {
+		iid, _ := pathInt(r, "iid")
+		var fields struct {
+			Body string `json:"body"`
+		}
+		if err := decodeBody(r, &fields); err != nil {
+			badRequest(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, g.addNote(iid, fields.Body))
+	})
+	handle("PUT merge_requests/{iid}/notes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := pathInt(r, "id")
+		var fields struct {
+			Body string `json:"body"`
+		}
+		if err := decodeBody(r, &fields); err != nil {
+			badRequest(w, err)
+			return
+		}
+		for i, n := range g.notes {
+			if n.note.ID == id {
+				g.notes[i].note.Body = fields.Body
+				writeJSON(w, http.StatusOK, g.notes[i].note)
+				return
+			}
+		}
+		notFound(w)
+	})
+	handle("DELETE merge_requests/{iid}/notes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := pathInt(r, "id")
+		for i, n := range g.notes {
+			if n.note.ID == id {
+				g.notes = slices.Delete(g.notes, i, i+1)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		notFound(w)
+	})
+
+	handle("POST statuses/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		// the request names the state "state", the response "status"
+		var fields struct {
+			gitlabclient.CommitStatus
+			RequestState string `json:"state"`
+		}
+		if err := decodeBody(r, &fields); err != nil {
+			badRequest(w, err)
+			return
+		}
+		s := fields.CommitStatus
+		s.State = fields.RequestState
+		g.nextID++
+		s.ID = g.nextID
+		s.SHA = r.PathValue("sha")
+		g.statuses[s.SHA] = append(g.statuses[s.SHA], s)
+		writeJSON(w, http.StatusCreated, s)
+	})
+	handle("GET repository/commits/{sha}/statuses", func(w http.ResponseWriter, r *http.Request) {
+		statuses := g.statuses[r.PathValue("sha")]
+		if statuses == nil {
+			statuses = []gitlabclient.CommitStatus{}
+		}
+		writeJSON(w, http.StatusOK, page(r, statuses))
+	})
+}
+
+func splitLabels(s string) []string {
+	var out []string
+	for _, l := range strings.Split(s, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			out = append(out, l)
+		}
+	}
+	return out
+}
@@ -0,0 +1,176 @@
+// Package fakeapi provides in-memory GitHub and GitLab API servers built on
+// httptest, so features writing to the platform can be tested hermetically.
+// Point a client at a server through its base URL, or use the DSL helpers
+// which configure the settings danger-go builds its clients from, then
+// assert on the recorded requests and the resulting state.
+package fakeapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// Token is the access token the DSL helpers configure.
+const Token = "fake-token"
+
+// Request is a request received by a server.
+type Request struct {
+	Method string
+	// Path is the escaped path, e.g. /api/v3/repos/o/r/pulls/1.
+	Path  string
+	Query url.Values
+	Body  []byte
+}
+
+// JSON decodes the request body into v.
+func (r Request) JSON(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// server holds what the GitHub and GitLab servers share: request recording,
+// handler overrides and the lock over their state.
+type server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	requests  []Request
+	mux       *http.ServeMux
+	overrides *http.ServeMux
+}
+
+func newServer(t testing.TB, routes func(mux *http.ServeMux)) *server {
+	s := &server{mux: http.NewServeMux(), overrides: http.NewServeMux()}
+	routes(s.mux)
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *server) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.EscapedPath(), Query: r.URL.Query(), Body: body})
+	s.mu.Unlock()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if h, pattern := s.overrides.Handler(r); pattern != "" {
+		h.ServeHTTP(w, r)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// Handle registers a handler taking precedence over the canned endpoints,
+// e.g. to fail a request: "GET /api/v3/repos/{owner}/{repo}/pulls/{number}".
+// Patterns use the http.ServeMux syntax.
+func (s *server) Handle(pattern string, h http.HandlerFunc) {
+	s.overrides.HandleFunc(pattern, h)
+}
+
+// Requests returns the requests received so far.
+func (s *server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Find returns the requests with the method and escaped path.
+func (s *server) Find(method, path string) []Request {
+	var found []Request
+	for _, r := range s.Requests() {
+		if r.Method == method && r.Path == path {
+			found = append(found, r)
+		}
+	}
+	return found
+}
+
+// RequireRequest fails the test unless a request with the method and path
+// was received, and returns the last one.
+func (s *server) RequireRequest(t testing.TB, method, path string) Request {
+	t.Helper()
+	found := s.Find(method, path)
+	if len(found) == 0 {
+		t.Fatalf("no %s %s request, got %v", method, path, s.summary())
+	}
+	return found[len(found)-1]
+}
+
+// RequireNoWrites fails the test if any request other than a GET was
+// received.
+func (s *server) RequireNoWrites(t testing.TB) {
+	t.Helper()
+	for _, r := range s.Requests() {
+		if r.Method != http.MethodGet {
+			t.Fatalf("unexpected %s %s request", r.Method, r.Path)
+		}
+	}
+}
+
+func (s *server) summary() []string {
+	var out []string
+	for _, r := range s.Requests() {
+		out = append(out, r.Method+" "+r.Path)
+	}
+	return out
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func notFound(w http.ResponseWriter) {
+	writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+}
+
+func badRequest(w http.ResponseWriter, err error) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+}
+
+// page returns the items of the requested page, 100 per page by default.
+func page[T any](r *http.Request, items []T) []T {
+	n, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	per, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if n < 1 {
+		n = 1
+	}
+	if per < 1 {
+		per = 100
+	}
+	start := (n - 1) * per
+	if start >= len(items) {
+		return []T{}
+	}
+	return items[start:min(start+per, len(items))]
+}
+
+func decodeBody(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding request body: %w", err)
+	}
+	return nil
+}
+
+func mustJSON(t testing.TB, v any) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("encoding JSON: %s", err)
+	}
+	return data
+}
+
+func pathInt(r *http.Request, name string) (int64, bool) {
+	v, err := strconv.ParseInt(r.PathValue(name), 10, 64)
+	return v, err == nil
+}
@@ -0,0 +1,129 @@
+// Package fakedsl provides in-memory implementations of the Git, GitHub,
+// GitLab and Settings interfaces of the danger DSL, so rules and plugins can
+// be tested without a repository nor danger-js. Each fake serves its fields,
+// including through the optional methods the functions of dangerJs reach,
+// such as dangerJs.FileAtRef.
+package fakedsl
+
+import (
+	"fmt"
+	"io/fs"
+
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// Git is a dangerJs.Git serving its fields.
+type Git struct {
+	ModifiedFilesList []dangerJs.FilePath
+	CreatedFilesList  []dangerJs.FilePath
+	DeletedFilesList  []dangerJs.FilePath
+	CommitsList       []dangerJs.GitCommit
+
+	// Diffs and WordDiffs are the diffs of the files between any refs.
+	Diffs     map[dangerJs.FilePath]dangerJs.FileDiff
+	WordDiffs map[dangerJs.FilePath]dangerJs.WordDiff
+	// Files maps "ref:path" to the content of path at ref, and path to its
+	// content at the other refs. FileAtRef fails with fs.ErrNotExist for the
+	// files missing.
+	Files       map[string]string
+	ModeChanges []dangerJs.FileModeChange
+	Functions   []dangerJs.ChangedFunction
+	Renames     []dangerJs.RenamedFile
+	// Blames and History map files to their blame and their latest commits.
+	Blames  map[dangerJs.FilePath][]dangerJs.BlameLine
+	History map[dangerJs.FilePath][]dangerJs.GitCommit
+	Shallow bool
+	// Refs maps the refs ResolveRef knows to the ref they resolve to.
+	Refs      map[string]string
+	Conflicts []dangerJs.FilePath
+	Behind    int
+
+	// Errs maps method names, e.g. "MergeConflicts", to the error they
+	// return.
+	Errs map[string]error
+}
+
+func (g Git) ModifiedFiles() []dangerJs.FilePath { return g.ModifiedFilesList }
+func (g Git) CreatedFiles() []dangerJs.FilePath  { return g.CreatedFilesList }
+func (g Git) DeletedFiles() []dangerJs.FilePath  { return g.DeletedFilesList }
+func (g Git) Commits() []dangerJs.GitCommit      { return g.CommitsList }
+
+func (g Git) DiffForFile(filePath string) (dangerJs.FileDiff, error) {
+	return g.Diffs[filePath], g.Errs["DiffForFile"]
+}
+
+func (g Git) DiffForFileWithRefs(filePath, _, _ string) (dangerJs.FileDiff, error) {
+	return g.Diffs[filePath], g.Errs["DiffForFileWithRefs"]
+}
+
+func (g Git) WordDiffForFileWithRefs(filePath, _, _ string) (dangerJs.WordDiff, error) {
+	return g.WordDiffs[filePath], g.Errs["WordDiffForFileWithRefs"]
+}
+
+func (g Git) FileAtRef(filePath, ref string) (string, error) {
+	if err := g.Errs["FileAtRef"]; err != nil {
+		return "", err
+	}
+	if content, ok := g.Files[ref+":"+filePath]; ok {
+		return content, nil
+	}
+	if content, ok := g.Files[filePath]; ok {
+		return content, nil
+	}
+	return "", fmt.Errorf("%s at %s: %w", filePath, ref, fs.ErrNotExist)
+}
+
+func (g Git) FileModeChangesWithRefs(_, _ string) ([]dangerJs.FileModeChange, error) {
+	return g.ModeChanges, g.Errs["FileModeChangesWithRefs"]
+}
+
+func (g Git) ChangedGoFunctionsWithRefs(_, _ string) ([]dangerJs.ChangedFunction, error) {
+	return g.Functions, g.Errs["ChangedGoFunctionsWithRefs"]
+}
+
+func (g Git) RenamedFilesWithRefs(_, _ string) ([]dangerJs.RenamedFile, error) {
+	return g.Renames, g.Errs["RenamedFilesWithRefs"]
+}
+
+func (g Git) Blame(filePath, _ string) ([]dangerJs.BlameLine, error) {
+	return g.Blames[filePath], g.Errs["Blame"]
+}
+
+func (g Git) CommitsForFile(filePath string, limit int) ([]dangerJs.GitCommit, error) {
+	commits := g.History[filePath]
+	if limit > 0 && len(commits) > limit {
+		commits = commits[:limit]
+	}
+	return commits, g.Errs["CommitsForFile"]
+}
+
+func (g Git) CommitsForFileAtRef(filePath, _ string, limit int) ([]dangerJs.GitCommit, error) {
+	commits := g.History[filePath]
+	if limit > 0 && len(commits) > limit {
+		commits = commits[:limit]
+	}
+	return commits, g.Errs["CommitsForFileAtRef"]
+}
+
+func (g Git) IsShallowClone() (bool, error) {
+	return g.Shallow, g.Errs["IsShallowClone"]
+}
+
+func (g Git) ResolveRef(ref string) (string, error) {
+	if err := g.Errs["ResolveRef"]; err != nil {
+		return "", err
+	}
+	resolved, ok := g.Refs[ref]
+	if !ok {
+		return "", &dangerJs.RefNotFoundError{Ref: ref, Tried: []string{ref}}
+	}
+	return resolved, nil
+}
+
+func (g Git) MergeConflicts(_, _ string) ([]dangerJs.FilePath, error) {
+	return g.Conflicts, g.Errs["MergeConflicts"]
+}
+
+func (g Git) CommitsBehind(_, _ string) (int, error) {
+	return g.Behind, g.Errs["CommitsBehind"]
+}
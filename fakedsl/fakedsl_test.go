@@ -0,0 +1,51 @@
+package fakedsl_test
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+func TestGit(t *testing.T) {
+	g := fakedsl.Git{
+		Files: map[string]string{"HEAD^:a.go": "old", "a.go": "new"},
+		History: map[string][]dangerJs.GitCommit{
+			"a.go": {{SHA: "3"}, {SHA: "2"}, {SHA: "1"}},
+		},
+		Refs: map[string]string{"main": "origin/main"},
+	}
+	pr := danger.DSL{Git: g}
+
+	content, err := dangerJs.FileAtRef(pr.Git, "a.go", "HEAD^")
+	require.Nil(t, err)
+	require.Equal(t, "old", content)
+	content, err = dangerJs.FileAtRef(pr.Git, "a.go", "HEAD")
+	require.Nil(t, err)
+	require.Equal(t, "new", content)
+	_, err = dangerJs.FileAtRef(pr.Git, "b.go", "HEAD")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	commits, err := dangerJs.CommitsForFile(pr.Git, "a.go", 2)
+	require.Nil(t, err)
+	require.Equal(t, []dangerJs.GitCommit{{SHA: "3"}, {SHA: "2"}}, commits)
+
+	ref, err := dangerJs.ResolveRef(pr.Git, "main")
+	require.Nil(t, err)
+	require.Equal(t, "origin/main", ref)
+	_, err = dangerJs.ResolveRef(pr.Git, "dev")
+	require.ErrorIs(t, err, dangerJs.ErrRefNotFound)
+}
+
+func TestGitHub(t *testing.T) {
+	gh := fakedsl.GitHub{
+		IssueData:              fakedsl.Labeled("bug"),
+		RequestedReviewersData: fakedsl.Reviewers("ann"),
+	}
+	require.Equal(t, "bug", gh.Issue().Labels[0].Name)
+	require.Equal(t, "ann", gh.RequestedReviewers().Users[0].Login)
+}
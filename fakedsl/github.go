@@ -0,0 +1,63 @@
+package fakedsl
+
+import (
+	"context"
+
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// GitHub is a dangerJs.GitHub serving its fields.
+type GitHub struct {
+	IssueData              dangerJs.GitHubIssue
+	PRData                 dangerJs.GitHubPR
+	ThisPRData             dangerJs.GitHubAPIPR
+	CommitsList            []dangerJs.GitHubCommit
+	ReviewsList            []dangerJs.GitHubReview
+	RequestedReviewersData dangerJs.GitHubReviewers
+
+	// Comparison is returned by Compare, which fails with
+	// dangerJs.ErrNoComparer when it is nil.
+	Comparison *dangerJs.GitHubComparison
+	// TimelineEvents are returned by Timeline, which fails with
+	// dangerJs.ErrNoTimelineFetcher when they are nil.
+	TimelineEvents dangerJs.GitHubTimeline
+}
+
+func (g GitHub) Issue() dangerJs.GitHubIssue                  { return g.IssueData }
+func (g GitHub) PR() dangerJs.GitHubPR                        { return g.PRData }
+func (g GitHub) ThisPR() dangerJs.GitHubAPIPR                 { return g.ThisPRData }
+func (g GitHub) Commits() []dangerJs.GitHubCommit             { return g.CommitsList }
+func (g GitHub) Reviews() []dangerJs.GitHubReview             { return g.ReviewsList }
+func (g GitHub) RequestedReviewers() dangerJs.GitHubReviewers { return g.RequestedReviewersData }
+
+func (g GitHub) Compare(context.Context, string, string) (dangerJs.GitHubComparison, error) {
+	if g.Comparison == nil {
+		return dangerJs.GitHubComparison{}, dangerJs.ErrNoComparer
+	}
+	return *g.Comparison, nil
+}
+
+func (g GitHub) Timeline(context.Context) (dangerJs.GitHubTimeline, error) {
+	if g.TimelineEvents == nil {
+		return nil, dangerJs.ErrNoTimelineFetcher
+	}
+	return g.TimelineEvents, nil
+}
+
+// Labeled returns an issue with the labels names.
+func Labeled(names ...string) dangerJs.GitHubIssue {
+	var issue dangerJs.GitHubIssue
+	for _, name := range names {
+		issue.Labels = append(issue.Labels, dangerJs.GitHubIssueLabel{Name: name})
+	}
+	return issue
+}
+
+// Reviewers returns the requested reviewers of the logins.
+func Reviewers(logins ...string) dangerJs.GitHubReviewers {
+	var r dangerJs.GitHubReviewers
+	for _, login := range logins {
+		r.Users = append(r.Users, dangerJs.GitHubUser{Login: login})
+	}
+	return r
+}
@@ -0,0 +1,16 @@
+package fakedsl
+
+import dangerJs "github.com/danger/golang/danger-js"
+
+// GitLab is a dangerJs.GitLab serving its fields.
+type GitLab struct {
+	MetadataData  dangerJs.RepoMetaData
+	MRData        dangerJs.GitLabMR
+	CommitsList   []dangerJs.GitLabMRCommit
+	ApprovalsData dangerJs.GitLabApproval
+}
+
+func (g GitLab) Metadata() dangerJs.RepoMetaData    { return g.MetadataData }
+func (g GitLab) MR() dangerJs.GitLabMR              { return g.MRData }
+func (g GitLab) Commits() []dangerJs.GitLabMRCommit { return g.CommitsList }
+func (g GitLab) Approvals() dangerJs.GitLabApproval { return g.ApprovalsData }
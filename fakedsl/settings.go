@@ -0,0 +1,31 @@
+package fakedsl
+
+import dangerJs "github.com/danger/golang/danger-js"
+
+// Settings is a dangerJs.Settings serving its fields. Unlike the settings of
+// danger-js, it doesn't fall back to the environment.
+type Settings struct {
+	GitHubToken         string
+	GitHubURL           string
+	GitHubHeaders       any
+	GitLabToken         string
+	GitLabURL           string
+	BitbucketCloudData  dangerJs.BitbucketCloudCredentials
+	BitbucketServerData dangerJs.BitbucketServerCredentials
+	CLIArgsData         dangerJs.CLIArgs
+}
+
+func (s Settings) GitHubAccessToken() string    { return s.GitHubToken }
+func (s Settings) GitHubBaseURL() string        { return s.GitHubURL }
+func (s Settings) GitHubAdditionalHeaders() any { return s.GitHubHeaders }
+func (s Settings) GitLabAccessToken() string    { return s.GitLabToken }
+func (s Settings) GitLabBaseURL() string        { return s.GitLabURL }
+func (s Settings) CLIArgs() dangerJs.CLIArgs    { return s.CLIArgsData }
+
+func (s Settings) BitbucketCloud() dangerJs.BitbucketCloudCredentials {
+	return s.BitbucketCloudData
+}
+
+func (s Settings) BitbucketServer() dangerJs.BitbucketServerCredentials {
+	return s.BitbucketServerData
+}
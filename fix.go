@@ -0,0 +1,22 @@
+package danger
+
+// Fix is a change proposed by a rule to correct what it reported, as a
+// unified diff relative to the repository root, e.g. the output of
+// `git diff`. The runner applies and commits fixes when autofix is enabled,
+// see the autofix package.
+type Fix struct {
+	// Rule is the rule which added the fix, if any.
+	Rule        string
+	Description string
+	Patch       string
+}
+
+// AddFix registers a fix described by description, e.g. "Format main.go".
+func (s *T) AddFix(description, patch string) {
+	s.fixes = append(s.fixes, Fix{Rule: s.rule, Description: description, Patch: patch})
+}
+
+// Fixes returns the fixes added so far.
+func (s *T) Fixes() []Fix {
+	return append([]Fix{}, s.fixes...)
+}
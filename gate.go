@@ -0,0 +1,95 @@
+package danger
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Condition decides whether a rule runs against the PR. When it doesn't, the
+// reason is shown in the Danger comment.
+type Condition func(pr DSL) (run bool, reason string)
+
+// SkipIfLabel skips the rule when the PR has any of labels, compared case
+// insensitively, e.g. SkipIfLabel("skip-changelog").
+func SkipIfLabel(labels ...string) Condition {
+	return func(pr DSL) (bool, string) {
+		if l, ok := findLabel(pr, labels); ok {
+			return false, fmt.Sprintf("labeled `%s`", l)
+		}
+		return true, ""
+	}
+}
+
+// OnlyIfLabel runs the rule only when the PR has any of labels, compared case
+// insensitively, e.g. OnlyIfLabel("release").
+func OnlyIfLabel(labels ...string) Condition {
+	return func(pr DSL) (bool, string) {
+		if _, ok := findLabel(pr, labels); ok {
+			return true, ""
+		}
+		return false, "not labeled " + quoteAll(labels, " or ")
+	}
+}
+
+func findLabel(pr DSL, labels []string) (string, bool) {
+	for _, l := range PRLabels(pr) {
+		if slices.ContainsFunc(labels, func(want string) bool { return strings.EqualFold(l, want) }) {
+			return l, true
+		}
+	}
+	return "", false
+}
+
+func quoteAll(ss []string, sep string) string {
+	quoted := make([]string, len(ss))
+	for i, s := range ss {
+		quoted[i] = "`" + s + "`"
+	}
+	return strings.Join(quoted, sep)
+}
+
+// PRLabels returns the labels of the GitHub PR or GitLab MR.
+func PRLabels(pr DSL) []string {
+	if pr.GitHub != nil && pr.GitHub.PR().Number != 0 {
+		var labels []string
+		for _, l := range pr.GitHub.Issue().Labels {
+			labels = append(labels, l.Name)
+		}
+		return labels
+	}
+	if pr.GitLab != nil {
+		return pr.GitLab.MR().Labels
+	}
+	return nil
+}
+
+// gated is a rule skipped by its conditions.
+type gated struct {
+	rule   string
+	reason string
+}
+
+// gate evaluates the conditions of r, returning the reason of the first one
+// which skips it.
+func gate(pr DSL, r Rule) (string, bool) {
+	for _, c := range r.When {
+		if run, reason := c(pr); !run {
+			return reason, true
+		}
+	}
+	return "", false
+}
+
+func (s *T) reportGated(skipped []gated) {
+	if len(skipped) == 0 {
+		return
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<details>\n<summary>%s</summary>\n\n", s.Msg("danger.skipped_rules", map[string]any{"count": len(skipped)}))
+	for _, g := range skipped {
+		fmt.Fprintf(&sb, "- `%s`: %s\n", g.rule, g.reason)
+	}
+	sb.WriteString("</details>")
+	s.Markdown(sb.String(), "", 0)
+}
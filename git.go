@@ -0,0 +1,20 @@
+package danger
+
+import dangerJs "github.com/danger/golang/danger-js"
+
+// ChangedFunction is a Go function or method changed by the PR.
+type ChangedFunction = dangerJs.ChangedFunction
+
+// ChangedGoFunctions returns the Go functions and methods changed between
+// HEAD^ and HEAD, e.g. danger.ChangedGoFunctions(pr.Git). It fails with an
+// error wrapping errors.ErrUnsupported when g lacks the
+// ChangedGoFunctionsWithRefs method of the DSL built from danger-js.
+func ChangedGoFunctions(g dangerJs.Git) ([]ChangedFunction, error) {
+	return dangerJs.ChangedGoFunctions(g)
+}
+
+// ChangedGoFunctionsWithRefs returns the Go functions and methods changed
+// between baseRef and headRef, see ChangedGoFunctions.
+func ChangedGoFunctionsWithRefs(g dangerJs.Git, baseRef, headRef string) ([]ChangedFunction, error) {
+	return dangerJs.ChangedGoFunctionsWithRefs(g, baseRef, headRef)
+}
@@ -0,0 +1,24 @@
+package danger_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+func TestChangedGoFunctions(t *testing.T) {
+	functions := []danger.ChangedFunction{{File: "a.go", Name: "Run", Exported: true}}
+	pr := danger.DSL{Git: fakedsl.Git{Functions: functions}}
+	changed, err := danger.ChangedGoFunctions(pr.Git)
+	require.Nil(t, err)
+	require.Equal(t, functions, changed)
+
+	type bareGit struct{ dangerJs.Git }
+	_, err = danger.ChangedGoFunctionsWithRefs(bareGit{}, "main", "HEAD")
+	require.True(t, errors.Is(err, errors.ErrUnsupported))
+}
@@ -0,0 +1,295 @@
+package danger
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/githubclient"
+)
+
+// opsTimeout bounds each GitHubOps call.
+const opsTimeout = 30 * time.Second
+
+// GitHubOps performs write operations on the GitHub PR being checked.
+type GitHubOps struct {
+	client *githubclient.Client
+	owner  string
+	repo   string
+	number int
+	// readOnly skips writes in fork-PR safe mode.
+	readOnly bool
+}
+
+// NewGitHubOps creates GitHubOps for the PR of pr, authenticating with the
+// GitHub settings danger-js passes in the DSL. In fork-PR safe mode, see
+// SafeMode, its write operations return ErrReadOnly without calling GitHub.
+func NewGitHubOps(pr DSL) (*GitHubOps, error) {
+	if pr.GitHub == nil || pr.GitHub.ThisPR().Number == 0 {
+		return nil, fmt.Errorf("not running on a GitHub PR")
+	}
+	client, err := githubclient.NewFromSettings(pr.Settings)
+	if err != nil {
+		return nil, fmt.Errorf("creating GitHub client: %w", err)
+	}
+	this := pr.GitHub.ThisPR()
+	ops := NewGitHubOpsWithClient(client, this.Owner, this.Repo, this.Number)
+	ops.readOnly = dangerJs.SafeMode()
+	return ops, nil
+}
+
+// NewGitHubOpsWithClient creates GitHubOps for PR number of owner/repo using
+// client.
+func NewGitHubOpsWithClient(client *githubclient.Client, owner, repo string, number int) *GitHubOps {
+	return &GitHubOps{client: client, owner: owner, repo: repo, number: number}
+}
+
+// UpdatePRBody replaces the PR description with the result of fn, which is
+// called with the current description as fetched from GitHub. Nothing is
+// sent when fn returns it unchanged.
+func (o *GitHubOps) UpdatePRBody(fn func(old string) string) error {
+	if o.readOnly {
+		return ErrReadOnly
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), opsTimeout)
+	defer cancel()
+	pr, err := o.client.PullRequest(ctx, o.owner, o.repo, o.number)
+	if err != nil {
+		return fmt.Errorf("fetching PR: %w", err)
+	}
+	body := fn(pr.Body)
+	if body == pr.Body {
+		return nil
+	}
+	if _, err := o.client.UpdatePullRequest(ctx, o.owner, o.repo, o.number, map[string]any{"body": body}); err != nil {
+		return fmt.Errorf("updating PR body: %w", err)
+	}
+	return nil
+}
+
+// UpdatePRSection sets the content of the managed section name of the PR
+// description, see ReplaceSection.
+func (o *GitHubOps) UpdatePRSection(name, content string) error {
+	return o.UpdatePRBody(func(old string) string {
+		return ReplaceSection(old, name, content)
+	})
+}
+
+func sectionMarkers(name string) (string, string) {
+	return fmt.Sprintf("<!-- danger:begin %s -->", name), fmt.Sprintf("<!-- danger:end %s -->", name)
+}
+
+// ReplaceSection sets the content between the markers of the managed section
+// name in body, appending the section when body has none, and leaves the
+// rest of body untouched. An empty content removes the section.
+func ReplaceSection(body, name, content string) string {
+	begin, end := sectionMarkers(name)
+	section := ""
+	if content != "" {
+		section = begin + "\n" + strings.TrimRight(content, "\n") + "\n" + end
+	}
+
+	i := strings.Index(body, begin)
+	j := strings.Index(body, end)
+	if i < 0 || j < i {
+		if section == "" {
+			return body
+		}
+		if strings.TrimSpace(body) == "" {
+			return section
+		}
+		return strings.TrimRight(body, "\n") + "\n\n" + section
+	}
+	before, after := body[:i], body[j+len(end):]
+	if section == "" {
+		return strings.TrimRight(before, "\n") + after
+	}
+	return before + section + after
+}
+
+// Section returns the content of the managed section name in body and
+// whether it exists.
+func Section(body, name string) (string, bool) {
+	begin, end := sectionMarkers(name)
+	i := strings.Index(body, begin)
+	j := strings.Index(body, end)
+	if i < 0 || j < i {
+		return "", false
+	}
+	return strings.Trim(body[i+len(begin):j], "\n"), true
+}
+
+// issueMarker tags issues and comments created with a dedup key.
+func issueMarker(key string) string {
+	return fmt.Sprintf("<!-- danger:issue %s -->", key)
+}
+
+// CreateIssue opens an issue in the repository of the PR, unless an open
+// issue with the same title and labels exists, in which case that issue is
+// returned instead.
+func (o *GitHubOps) CreateIssue(title, body string, labels []string) (dangerJs.GitHubIssue, error) {
+	return o.createIssue(title, body, labels, func(issue dangerJs.GitHubIssue) bool {
+		return strings.EqualFold(strings.TrimSpace(issue.Title), strings.TrimSpace(title))
+	})
+}
+
+// CreateIssueWithKey is like CreateIssue but deduplicates by key, which is
+// embedded in the body as a hidden marker, so the title may change between
+// runs.
+func (o *GitHubOps) CreateIssueWithKey(key, title, body string, labels []string) (dangerJs.GitHubIssue, error) {
+	marker := issueMarker(key)
+	return o.createIssue(title, body+"\n\n"+marker, labels, func(issue dangerJs.GitHubIssue) bool {
+		return strings.Contains(issue.Body, marker)
+	})
+}
+
+func (o *GitHubOps) createIssue(title, body string, labels []string, same func(dangerJs.GitHubIssue) bool) (dangerJs.GitHubIssue, error) {
+	if o.readOnly {
+		return dangerJs.GitHubIssue{}, ErrReadOnly
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), opsTimeout)
+	defer cancel()
+	query := url.Values{"state": {"open"}}
+	if len(labels) > 0 {
+		query.Set("labels", strings.Join(labels, ","))
+	}
+	issues, err := o.client.ListIssues(ctx, o.owner, o.repo, query)
+	if err != nil {
+		return dangerJs.GitHubIssue{}, fmt.Errorf("listing issues: %w", err)
+	}
+	for _, issue := range issues {
+		if issue.PullRequest == nil && same(issue) {
+			return issue, nil
+		}
+	}
+	issue, err := o.client.CreateIssue(ctx, o.owner, o.repo, title, body, labels)
+	if err != nil {
+		return dangerJs.GitHubIssue{}, fmt.Errorf("creating issue: %w", err)
+	}
+	return issue, nil
+}
+
+// CommentOnIssue comments on an issue or PR of the repository, e.g. one the
+// PR links to. With a non-empty key the comment is only added once: it is
+// skipped when a comment carrying the key's marker exists.
+func (o *GitHubOps) CommentOnIssue(number int, key, body string) error {
+	if o.readOnly {
+		return ErrReadOnly
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), opsTimeout)
+	defer cancel()
+	if key != "" {
+		marker := issueMarker(key)
+		comments, err := o.client.IssueComments(ctx, o.owner, o.repo, number)
+		if err != nil {
+			return fmt.Errorf("listing comments: %w", err)
+		}
+		for _, c := range comments {
+			if strings.Contains(c.Body, marker) {
+				return nil
+			}
+		}
+		body += "\n\n" + marker
+	}
+	if _, err := o.client.CreateIssueComment(ctx, o.owner, o.repo, number, body); err != nil {
+		return fmt.Errorf("commenting on #%d: %w", number, err)
+	}
+	return nil
+}
+
+// pageMarker tags page i of the managed comments key.
+func pageMarker(key string, i int) string {
+	return fmt.Sprintf("<!-- danger:page %s %d -->", key, i)
+}
+
+// SetCommentPages makes the PR carry one managed comment per page, updating
+// the comments posted by earlier runs and deleting the ones no longer
+// needed, e.g. the overflow pages of FitComment.
+func (o *GitHubOps) SetCommentPages(key string, pages []string) error {
+	if o.readOnly {
+		return ErrReadOnly
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), opsTimeout)
+	defer cancel()
+	comments, err := o.client.IssueComments(ctx, o.owner, o.repo, o.number)
+	if err != nil {
+		return fmt.Errorf("listing comments: %w", err)
+	}
+	prefix := fmt.Sprintf("<!-- danger:page %s ", key)
+	existing := map[int]dangerJs.GitHubIssueComment{}
+	for _, c := range comments {
+		i := strings.Index(c.Body, prefix)
+		if i < 0 {
+			continue
+		}
+		var n int
+		if _, err := fmt.Sscanf(c.Body[i+len(prefix):], "%d -->", &n); err == nil {
+			existing[n] = c
+		}
+	}
+
+	for i, page := range pages {
+		body := page + "\n\n" + pageMarker(key, i)
+		c, ok := existing[i]
+		delete(existing, i)
+		switch {
+		case !ok:
+			if _, err := o.client.CreateIssueComment(ctx, o.owner, o.repo, o.number, body); err != nil {
+				return fmt.Errorf("creating comment page %d: %w", i+1, err)
+			}
+		case c.Body != body:
+			if _, err := o.client.UpdateIssueComment(ctx, o.owner, o.repo, c.ID, body); err != nil {
+				return fmt.Errorf("updating comment page %d: %w", i+1, err)
+			}
+		}
+	}
+	stale := make([]int, 0, len(existing))
+	for i := range existing {
+		stale = append(stale, i)
+	}
+	sort.Ints(stale)
+	for _, i := range stale {
+		if err := o.client.DeleteIssueComment(ctx, o.owner, o.repo, existing[i].ID); err != nil {
+			return fmt.Errorf("deleting stale comment page: %w", err)
+		}
+	}
+	return nil
+}
+
+// FindComment returns the most recent comment of the PR containing substr.
+func (o *GitHubOps) FindComment(substr string) (dangerJs.GitHubIssueComment, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opsTimeout)
+	defer cancel()
+	return o.findComment(ctx, substr, func(dangerJs.GitHubUser) bool { return true })
+}
+
+// FindOwnComment returns the most recent comment of the PR containing substr
+// posted by the user of the token, so PR authors can't forge the comments
+// Danger reads back. GitHub App and Actions tokens have no user to compare
+// with, so comments of bots are accepted for them.
+func (o *GitHubOps) FindOwnComment(substr string) (dangerJs.GitHubIssueComment, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opsTimeout)
+	defer cancel()
+	own := func(u dangerJs.GitHubUser) bool { return u.Type == "Bot" }
+	if user, err := o.client.AuthenticatedUser(ctx); err == nil {
+		own = func(u dangerJs.GitHubUser) bool { return u.Login == user.Login }
+	}
+	return o.findComment(ctx, substr, own)
+}
+
+func (o *GitHubOps) findComment(ctx context.Context, substr string, author func(dangerJs.GitHubUser) bool) (dangerJs.GitHubIssueComment, bool, error) {
+	comments, err := o.client.IssueComments(ctx, o.owner, o.repo, o.number)
+	if err != nil {
+		return dangerJs.GitHubIssueComment{}, false, fmt.Errorf("listing comments: %w", err)
+	}
+	for i := len(comments) - 1; i >= 0; i-- {
+		if strings.Contains(comments[i].Body, substr) && author(comments[i].User) {
+			return comments[i], true, nil
+		}
+	}
+	return dangerJs.GitHubIssueComment{}, false, nil
+}
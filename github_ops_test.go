@@ -0,0 +1,252 @@
+package danger_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/githubclient"
+)
+
+func TestReplaceSection(t *testing.T) {
+	const managed = "<!-- danger:begin checklist -->\n- [ ] docs\n<!-- danger:end checklist -->"
+	tests := []struct {
+		name    string
+		body    string
+		content string
+		want    string
+	}{
+		{name: "empty body", body: "", content: "- [ ] docs", want: managed},
+		{name: "append", body: "My change.\n", content: "- [ ] docs\n", want: "My change.\n\n" + managed},
+		{
+			name:    "replace keeps author text",
+			body:    "Intro\n\n<!-- danger:begin checklist -->\nold\n<!-- danger:end checklist -->\n\nOutro",
+			content: "- [ ] docs",
+			want:    "Intro\n\n" + managed + "\n\nOutro",
+		},
+		{name: "remove", body: "Intro\n\n" + managed + "\nOutro", content: "", want: "Intro\nOutro"},
+		{name: "remove missing", body: "Intro", content: "", want: "Intro"},
+		{
+			name:    "other sections untouched",
+			body:    "<!-- danger:begin coverage -->\n80%\n<!-- danger:end coverage -->",
+			content: "- [ ] docs",
+			want:    "<!-- danger:begin coverage -->\n80%\n<!-- danger:end coverage -->\n\n" + managed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := danger.ReplaceSection(tt.body, "checklist", tt.content)
+			require.Equal(t, tt.want, got)
+			if tt.content != "" {
+				content, ok := danger.Section(got, "checklist")
+				require.True(t, ok)
+				require.Equal(t, "- [ ] docs", content)
+			}
+		})
+	}
+}
+
+func TestUpdatePRBody(t *testing.T) {
+	body := "Author text"
+	patches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v3/repos/o/r/pulls/5", r.URL.Path)
+		if r.Method == http.MethodPatch {
+			patches++
+			raw, _ := io.ReadAll(r.Body)
+			var fields map[string]string
+			require.Nil(t, json.Unmarshal(raw, &fields))
+			body = fields["body"]
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"number": 5, "body": body})
+	}))
+	defer srv.Close()
+
+	client, err := githubclient.New(srv.URL, "token")
+	require.Nil(t, err)
+	ops := danger.NewGitHubOpsWithClient(client, "o", "r", 5)
+
+	require.Nil(t, ops.UpdatePRSection("coverage", "Coverage: 80%"))
+	require.Equal(t, "Author text\n\n<!-- danger:begin coverage -->\nCoverage: 80%\n<!-- danger:end coverage -->", body)
+	require.Equal(t, 1, patches)
+
+	// unchanged bodies are not sent
+	require.Nil(t, ops.UpdatePRSection("coverage", "Coverage: 80%"))
+	require.Equal(t, 1, patches)
+}
+
+func TestNewGitHubOpsWithoutPR(t *testing.T) {
+	_, err := danger.NewGitHubOps(danger.DSL{})
+	require.EqualError(t, err, "not running on a GitHub PR")
+}
+
+func TestCreateIssue(t *testing.T) {
+	var created []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v3/repos/o/r/issues", r.URL.Path)
+		if r.Method == http.MethodPost {
+			var fields map[string]any
+			require.Nil(t, json.NewDecoder(r.Body).Decode(&fields))
+			created = append(created, fields)
+			_, _ = w.Write([]byte(`{"number":20,"title":"new"}`))
+			return
+		}
+		require.Equal(t, "open", r.URL.Query().Get("state"))
+		require.Equal(t, "tech-debt", r.URL.Query().Get("labels"))
+		_, _ = w.Write([]byte(`[
+			{"number":1,"title":"Remove legacy API","pull_request":{"url":"x"}},
+			{"number":2,"title":"Remove legacy API ","body":"tracking"},
+			{"number":3,"title":"Other","body":"x\n\n<!-- danger:issue legacy-cache -->"}
+		]`))
+	}))
+	defer srv.Close()
+
+	client, err := githubclient.New(srv.URL, "token")
+	require.Nil(t, err)
+	ops := danger.NewGitHubOpsWithClient(client, "o", "r", 5)
+
+	issue, err := ops.CreateIssue("remove legacy API", "body", []string{"tech-debt"})
+	require.Nil(t, err)
+	require.Equal(t, 2, issue.Number)
+
+	issue, err = ops.CreateIssueWithKey("legacy-cache", "Drop the legacy cache", "body", []string{"tech-debt"})
+	require.Nil(t, err)
+	require.Equal(t, 3, issue.Number)
+	require.Empty(t, created)
+
+	issue, err = ops.CreateIssue("New debt", "body", []string{"tech-debt"})
+	require.Nil(t, err)
+	require.Equal(t, 20, issue.Number)
+	require.Equal(t, []map[string]any{{"title": "New debt", "body": "body", "labels": []any{"tech-debt"}}}, created)
+}
+
+func TestCommentOnIssue(t *testing.T) {
+	var posted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v3/repos/o/r/issues/9/comments", r.URL.Path)
+		if r.Method == http.MethodPost {
+			var fields map[string]string
+			require.Nil(t, json.NewDecoder(r.Body).Decode(&fields))
+			posted = append(posted, fields["body"])
+			_, _ = w.Write([]byte(`{"id":1}`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id":1,"body":"hi\n\n<!-- danger:issue seen -->"}]`))
+	}))
+	defer srv.Close()
+
+	client, err := githubclient.New(srv.URL, "token")
+	require.Nil(t, err)
+	ops := danger.NewGitHubOpsWithClient(client, "o", "r", 5)
+
+	require.Nil(t, ops.CommentOnIssue(9, "seen", "hi"))
+	require.Nil(t, ops.CommentOnIssue(9, "new", "Referenced by #5"))
+	require.Nil(t, ops.CommentOnIssue(9, "", "plain"))
+	require.Equal(t, []string{"Referenced by #5\n\n<!-- danger:issue new -->", "plain"}, posted)
+}
+
+func TestFindOwnComment(t *testing.T) {
+	tests := []struct {
+		name   string
+		user   string
+		wantID int64
+	}{
+		{name: "token user", user: `{"login":"danger-bot","type":"User"}`, wantID: 2},
+		{name: "app token", wantID: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/v3/user" {
+					if tt.user == "" {
+						w.WriteHeader(http.StatusForbidden)
+						_, _ = w.Write([]byte(`{"message":"Resource not accessible by integration"}`))
+						return
+					}
+					_, _ = w.Write([]byte(tt.user))
+					return
+				}
+				require.Equal(t, "/api/v3/repos/o/r/issues/5/comments", r.URL.Path)
+				_, _ = w.Write([]byte(`[
+					{"id":1,"body":"<!-- danger-go:check a -->","user":{"login":"other-bot","type":"Bot"}},
+					{"id":2,"body":"<!-- danger-go:check a -->","user":{"login":"danger-bot","type":"User"}},
+					{"id":3,"body":"<!-- danger-go:check a -->","user":{"login":"github-actions[bot]","type":"Bot"}},
+					{"id":4,"body":"- [x] <!-- danger-go:check a -->","user":{"login":"author","type":"User"}}
+				]`))
+			}))
+			defer srv.Close()
+
+			client, err := githubclient.New(srv.URL, "token")
+			require.Nil(t, err)
+			ops := danger.NewGitHubOpsWithClient(client, "o", "r", 5)
+
+			c, found, err := ops.FindOwnComment(danger.ChecklistMarker)
+			require.Nil(t, err)
+			require.True(t, found)
+			require.Equal(t, tt.wantID, c.ID)
+
+			c, _, err = ops.FindComment(danger.ChecklistMarker)
+			require.Nil(t, err)
+			require.Equal(t, int64(4), c.ID)
+		})
+	}
+}
+
+func TestSetCommentPages(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			require.Equal(t, "/api/v3/repos/o/r/issues/5/comments", r.URL.Path)
+			_, _ = w.Write([]byte(`[
+				{"id":10,"body":"human comment"},
+				{"id":11,"body":"same\n\n<!-- danger:page danger-go 0 -->"},
+				{"id":12,"body":"old\n\n<!-- danger:page danger-go 1 -->"},
+				{"id":13,"body":"stale\n\n<!-- danger:page danger-go 2 -->"}
+			]`))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		calls = append(calls, r.Method+" "+r.URL.Path+" "+string(body))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	client, err := githubclient.New(srv.URL, "token")
+	require.Nil(t, err)
+	ops := danger.NewGitHubOpsWithClient(client, "o", "r", 5)
+
+	require.Nil(t, ops.SetCommentPages("danger-go", []string{"same", "new"}))
+	require.Equal(t, []string{
+		`PATCH /api/v3/repos/o/r/issues/comments/12 {"body":"new\n\n\u003c!-- danger:page danger-go 1 --\u003e"}`,
+		`DELETE /api/v3/repos/o/r/issues/comments/13 `,
+	}, calls)
+}
+
+func TestGitHubOpsSafeMode(t *testing.T) {
+	t.Setenv("DANGER_SAFE_MODE", "true")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	data, err := dangerJs.DecodeDSL([]byte(`{
+		"git": {},
+		"github": {"thisPR": {"owner": "o", "repo": "r", "number": 5}},
+		"settings": {"github": {"accessToken": "t", "baseURL": "` + srv.URL + `"}}
+	}`))
+	require.Nil(t, err)
+	ops, err := danger.NewGitHubOps(data.ToInterface())
+	require.Nil(t, err)
+
+	require.ErrorIs(t, ops.UpdatePRSection("coverage", "80%"), danger.ErrReadOnly)
+	require.ErrorIs(t, ops.CommentOnIssue(1, "", "hi"), danger.ErrReadOnly)
+	require.ErrorIs(t, ops.SetCommentPages("danger-go", []string{"page"}), danger.ErrReadOnly)
+	_, err = ops.CreateIssue("title", "body", nil)
+	require.ErrorIs(t, err, danger.ErrReadOnly)
+}
@@ -0,0 +1,849 @@
+// Package githubclient is a small GitHub REST and GraphQL client which honors
+// the base URL danger-js was configured with, so GitHub Enterprise Server
+// installations behave the same way as github.com.
+package githubclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/tracing"
+)
+
+const (
+	// DefaultBaseURL is the REST API root used when no base URL is configured.
+	DefaultBaseURL = "https://api.github.com"
+
+	// APIVersion is the REST API version requested from servers which support
+	// version negotiation.
+	APIVersion = "2022-11-28"
+
+	apiVersionHeader        = "X-GitHub-Api-Version"
+	enterpriseVersionHeader = "X-GitHub-Enterprise-Version"
+)
+
+// Client talks to the GitHub REST and GraphQL APIs.
+type Client struct {
+	restURL    *url.URL
+	graphqlURL *url.URL
+	token      string
+	headers    http.Header
+	httpClient *http.Client
+
+	mu                sync.Mutex
+	sendAPIVersion    bool
+	enterpriseVersion string
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithHeaders adds headers which are sent with every request.
+func WithHeaders(h http.Header) Option {
+	return func(c *Client) {
+		for k, vv := range h {
+			for _, v := range vv {
+				c.headers.Add(k, v)
+			}
+		}
+	}
+}
+
+// New creates a client for the API at baseURL. An empty baseURL means
+// github.com. GitHub Enterprise Server URLs may be given with or without the
+// /api/v3 suffix.
+func New(baseURL, token string, opts ...Option) (*Client, error) {
+	restURL, graphqlURL, err := endpoints(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		restURL:        restURL,
+		graphqlURL:     graphqlURL,
+		token:          token,
+		headers:        http.Header{},
+		httpClient:     http.DefaultClient,
+		sendAPIVersion: true,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c, nil
+}
+
+// NewFromSettings creates a client from the GitHub settings danger-js passes
+// in the DSL, including any additional headers.
+func NewFromSettings(s dangerJs.Settings, opts ...Option) (*Client, error) {
+	headers := http.Header{}
+	switch hh := s.GitHubAdditionalHeaders().(type) {
+	case map[string]any:
+		for k, v := range hh {
+			headers.Set(k, fmt.Sprint(v))
+		}
+	case map[string]string:
+		for k, v := range hh {
+			headers.Set(k, v)
+		}
+	}
+	opts = append([]Option{WithHeaders(headers)}, opts...)
+	return New(s.GitHubBaseURL(), s.GitHubAccessToken(), opts...)
+}
+
+// endpoints works out the REST and GraphQL roots for a configured base URL.
+func endpoints(baseURL string) (*url.URL, *url.URL, error) {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing base URL: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, nil, fmt.Errorf("invalid base URL: %s", baseURL)
+	}
+
+	if u.Host == "github.com" || u.Host == "www.github.com" {
+		u.Host = "api.github.com"
+		u.Path = ""
+	}
+
+	rest := *u
+	graphql := *u
+	switch {
+	case u.Host == "api.github.com":
+		graphql.Path = u.Path + "/graphql"
+	case strings.HasSuffix(u.Path, "/api/v3"):
+		graphql.Path = strings.TrimSuffix(u.Path, "/v3") + "/graphql"
+	case strings.HasSuffix(u.Path, "/api/graphql"):
+		rest.Path = strings.TrimSuffix(u.Path, "/graphql") + "/v3"
+	case strings.HasSuffix(u.Path, "/api"):
+		rest.Path = u.Path + "/v3"
+		graphql.Path = u.Path + "/graphql"
+	default:
+		// A bare GitHub Enterprise Server host, e.g. https://github.example.com
+		rest.Path = u.Path + "/api/v3"
+		graphql.Path = u.Path + "/api/graphql"
+	}
+	return &rest, &graphql, nil
+}
+
+// RESTURL returns the root of the REST API.
+func (c *Client) RESTURL() string {
+	return c.restURL.String()
+}
+
+// GraphQLURL returns the GraphQL endpoint.
+func (c *Client) GraphQLURL() string {
+	return c.graphqlURL.String()
+}
+
+// EnterpriseVersion returns the GitHub Enterprise Server version reported by
+// the last response, or an empty string for github.com.
+func (c *Client) EnterpriseVersion() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.enterpriseVersion
+}
+
+// Error is returned when the API responds with a non-2xx status.
+type Error struct {
+	StatusCode       int
+	Method           string
+	URL              string
+	Message          string
+	DocumentationURL string
+	// AcceptedPermissions and AcceptedScopes are what the endpoint requires
+	// of app and fine-grained tokens, e.g. "pull_requests=write", and of
+	// classic tokens, as listed by GitHub on 403 and 404 responses.
+	AcceptedPermissions string
+	AcceptedScopes      string
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	msg = fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, msg)
+	switch {
+	case e.AcceptedPermissions != "":
+		msg += fmt.Sprintf(" (the token needs the %s permission)", e.AcceptedPermissions)
+	case e.AcceptedScopes != "":
+		msg += fmt.Sprintf(" (the token needs one of the scopes %s)", e.AcceptedScopes)
+	}
+	return msg
+}
+
+// Is reports a 401 response as dangerJs.ErrAPIUnauthorized.
+func (e *Error) Is(target error) bool {
+	return target == dangerJs.ErrAPIUnauthorized && e.StatusCode == http.StatusUnauthorized
+}
+
+// Do sends a request to the REST API. path is relative to the API root and
+// may include a query string. body, when not nil, is encoded as JSON and the
+// response is decoded into out when out is not nil.
+func (c *Client) Do(ctx context.Context, method, path string, body, out any) (*http.Response, error) {
+	u, err := c.restURL.Parse(c.restURL.Path + "/" + strings.TrimPrefix(path, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("building request URL: %w", err)
+	}
+	return c.do(ctx, method, u.String(), body, out)
+}
+
+// GraphQL runs a query against the GraphQL API and decodes the `data` field of
+// the response into out.
+func (c *Client) GraphQL(ctx context.Context, query string, variables map[string]any, out any) error {
+	req := map[string]any{"query": query}
+	if len(variables) > 0 {
+		req["variables"] = variables
+	}
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if _, err := c.do(ctx, http.MethodPost, c.graphqlURL.String(), req, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		msgs := make([]string, 0, len(resp.Errors))
+		for _, e := range resp.Errors {
+			msgs = append(msgs, e.Message)
+		}
+		return fmt.Errorf("graphql: %s", strings.Join(msgs, "; "))
+	}
+	if out == nil || len(resp.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(resp.Data, out); err != nil {
+		return fmt.Errorf("decoding graphql data: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method, rawURL string, body, out any) (*http.Response, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling request body: %w", err)
+		}
+	}
+	return c.doRaw(ctx, method, rawURL, payload, "application/json", out)
+}
+
+// doRaw sends payload as is with the given content type.
+func (c *Client) doRaw(ctx context.Context, method, rawURL string, payload []byte, contentType string, out any) (*http.Response, error) {
+	c.mu.Lock()
+	sendVersion := c.sendAPIVersion
+	c.mu.Unlock()
+
+	resp, respBody, err := c.send(ctx, method, rawURL, payload, contentType, sendVersion)
+	if err != nil {
+		return nil, err
+	}
+	if sendVersion && rejectsAPIVersion(resp, respBody) {
+		// Older GitHub Enterprise Server releases reject the version header
+		// rather than ignoring it, so fall back to the server's default.
+		c.mu.Lock()
+		c.sendAPIVersion = false
+		c.mu.Unlock()
+		resp, respBody, err = c.send(ctx, method, rawURL, payload, contentType, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &Error{StatusCode: resp.StatusCode, Method: method, URL: rawURL}
+		if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound {
+			apiErr.AcceptedPermissions = resp.Header.Get("X-Accepted-GitHub-Permissions")
+			apiErr.AcceptedScopes = resp.Header.Get("X-Accepted-OAuth-Scopes")
+		}
+		var e struct {
+			Message          string `json:"message"`
+			DocumentationURL string `json:"documentation_url"`
+		}
+		if json.Unmarshal(respBody, &e) == nil {
+			apiErr.Message = e.Message
+			apiErr.DocumentationURL = e.DocumentationURL
+		}
+		return resp, apiErr
+	}
+
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return resp, fmt.Errorf("decoding response from %s: %w", rawURL, err)
+		}
+	}
+	return resp, nil
+}
+
+func (c *Client) send(ctx context.Context, method, rawURL string, payload []byte, contentType string, sendVersion bool) (_ *http.Response, _ []byte, err error) {
+	ctx, span := tracing.Start(ctx, "github "+method)
+	span.SetAttr("http.url", rawURL)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating request: %w", err)
+	}
+	for k, vv := range c.headers {
+		req.Header[k] = vv
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if payload != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if sendVersion {
+		req.Header.Set(apiVersionHeader, APIVersion)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s %s: %w", method, rawURL, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading response from %s: %w", rawURL, err)
+	}
+
+	span.SetAttr("http.status_code", strconv.Itoa(resp.StatusCode))
+	if v := resp.Header.Get(enterpriseVersionHeader); v != "" {
+		c.mu.Lock()
+		c.enterpriseVersion = v
+		c.mu.Unlock()
+	}
+	return resp, respBody, nil
+}
+
+// rejectsAPIVersion reports whether the server refused the request because it
+// does not support the requested API version.
+func rejectsAPIVersion(resp *http.Response, body []byte) bool {
+	if resp.StatusCode != http.StatusBadRequest {
+		return false
+	}
+	return bytes.Contains(bytes.ToLower(body), []byte(strings.ToLower(apiVersionHeader)))
+}
+
+// PullRequest fetches a single pull request.
+func (c *Client) PullRequest(ctx context.Context, owner, repo string, number int) (dangerJs.GitHubPR, error) {
+	var pr dangerJs.GitHubPR
+	path := fmt.Sprintf("repos/%s/%s/pulls/%d", url.PathEscape(owner), url.PathEscape(repo), number)
+	if _, err := c.Do(ctx, http.MethodGet, path, nil, &pr); err != nil {
+		return dangerJs.GitHubPR{}, err
+	}
+	return pr, nil
+}
+
+// CombinedStatus fetches the combined commit status of ref.
+func (c *Client) CombinedStatus(ctx context.Context, owner, repo, ref string) (dangerJs.GitHubCombinedStatus, error) {
+	var status dangerJs.GitHubCombinedStatus
+	path := fmt.Sprintf("repos/%s/%s/commits/%s/status", url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(ref))
+	if _, err := c.Do(ctx, http.MethodGet, path, nil, &status); err != nil {
+		return dangerJs.GitHubCombinedStatus{}, err
+	}
+	return status, nil
+}
+
+// AddLabels adds labels to an issue or pull request.
+func (c *Client) AddLabels(ctx context.Context, owner, repo string, number int, labels ...string) error {
+	path := fmt.Sprintf("repos/%s/%s/issues/%d/labels", url.PathEscape(owner), url.PathEscape(repo), number)
+	_, err := c.Do(ctx, http.MethodPost, path, map[string][]string{"labels": labels}, nil)
+	return err
+}
+
+// RemoveLabel removes a label from an issue or pull request.
+func (c *Client) RemoveLabel(ctx context.Context, owner, repo string, number int, label string) error {
+	path := fmt.Sprintf("repos/%s/%s/issues/%d/labels/%s", url.PathEscape(owner), url.PathEscape(repo), number, url.PathEscape(label))
+	_, err := c.Do(ctx, http.MethodDelete, path, nil, nil)
+	return err
+}
+
+// ListPullRequests lists the pull requests of a repository. query holds the
+// filters of the list endpoint, e.g. state, head and base.
+func (c *Client) ListPullRequests(ctx context.Context, owner, repo string, query url.Values) ([]dangerJs.GitHubPR, error) {
+	var prs []dangerJs.GitHubPR
+	path := fmt.Sprintf("repos/%s/%s/pulls", url.PathEscape(owner), url.PathEscape(repo))
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	if _, err := c.Do(ctx, http.MethodGet, path, nil, &prs); err != nil {
+		return nil, err
+	}
+	return prs, nil
+}
+
+// SearchIssuesCount returns the number of issues and pull requests matching
+// a search query, e.g. "is:pr is:open review-requested:octocat".
+func (c *Client) SearchIssuesCount(ctx context.Context, query string) (int, error) {
+	var out struct {
+		TotalCount int `json:"total_count"`
+	}
+	path := "search/issues?" + url.Values{"q": {query}, "per_page": {"1"}}.Encode()
+	if _, err := c.Do(ctx, http.MethodGet, path, nil, &out); err != nil {
+		return 0, err
+	}
+	return out.TotalCount, nil
+}
+
+// RequestReviewers requests reviews of a pull request from users.
+func (c *Client) RequestReviewers(ctx context.Context, owner, repo string, number int, reviewers ...string) error {
+	path := fmt.Sprintf("repos/%s/%s/pulls/%d/requested_reviewers", url.PathEscape(owner), url.PathEscape(repo), number)
+	_, err := c.Do(ctx, http.MethodPost, path, map[string][]string{"reviewers": reviewers}, nil)
+	return err
+}
+
+// Compare compares two commits, branches or tags of a repository, from their
+// merge base to head. GitHub lists at most 250 commits and 300 files.
+func (c *Client) Compare(ctx context.Context, owner, repo, base, head string) (dangerJs.GitHubComparison, error) {
+	var out dangerJs.GitHubComparison
+	path := fmt.Sprintf("repos/%s/%s/compare/%s...%s", url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(base), url.PathEscape(head))
+	_, err := c.Do(ctx, http.MethodGet, path, nil, &out)
+	return out, err
+}
+
+// UpdateBranch merges the base branch into the head branch of a pull
+// request. With a non-empty expectedHeadSHA GitHub rejects the update when
+// the head moved.
+func (c *Client) UpdateBranch(ctx context.Context, owner, repo string, number int, expectedHeadSHA string) error {
+	path := fmt.Sprintf("repos/%s/%s/pulls/%d/update-branch", url.PathEscape(owner), url.PathEscape(repo), number)
+	body := map[string]string{}
+	if expectedHeadSHA != "" {
+		body["expected_head_sha"] = expectedHeadSHA
+	}
+	_, err := c.Do(ctx, http.MethodPut, path, body, nil)
+	return err
+}
+
+// TokenScopes returns the OAuth scopes of the token, as listed in the
+// X-OAuth-Scopes header. known is false for tokens without scopes, such as
+// fine-grained, GitHub App and Actions tokens, whose permissions are set
+// instead.
+func (c *Client) TokenScopes(ctx context.Context) (scopes []string, known bool, err error) {
+	resp, err := c.Do(ctx, http.MethodGet, "rate_limit", nil, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	header, known := resp.Header[http.CanonicalHeaderKey("X-OAuth-Scopes")]
+	if !known {
+		return nil, false, nil
+	}
+	for _, h := range header {
+		for _, s := range strings.Split(h, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				scopes = append(scopes, s)
+			}
+		}
+	}
+	return scopes, true, nil
+}
+
+// CommitFile is a file changed by CommitFiles.
+type CommitFile struct {
+	Path    string
+	Content string
+	// Executable sets the file mode to 100755.
+	Executable bool
+	// Deleted removes the file, ignoring Content.
+	Deleted bool
+}
+
+// CommitFiles commits files on top of headSHA as a single commit with the
+// git data API and moves branch to it, returning the SHA of the commit. It
+// fails when branch is no longer at headSHA, rather than committing files
+// based on a stale head over newer commits, and the update fails rather than
+// forcing when the branch moved meanwhile.
+func (c *Client) CommitFiles(ctx context.Context, owner, repo, branch, headSHA, message string, files []CommitFile) (string, error) {
+	base := fmt.Sprintf("repos/%s/%s/git/", url.PathEscape(owner), url.PathEscape(repo))
+	var ref struct {
+		Object struct {
+			SHA string `json:"sha"`
+		} `json:"object"`
+	}
+	if _, err := c.Do(ctx, http.MethodGet, base+"ref/heads/"+branch, nil, &ref); err != nil {
+		return "", fmt.Errorf("fetching branch %s: %w", branch, err)
+	}
+	if ref.Object.SHA != headSHA {
+		return "", fmt.Errorf("branch %s is at %s, not %s", branch, ref.Object.SHA, headSHA)
+	}
+	var parent struct {
+		Tree struct {
+			SHA string `json:"sha"`
+		} `json:"tree"`
+	}
+	if _, err := c.Do(ctx, http.MethodGet, base+"commits/"+headSHA, nil, &parent); err != nil {
+		return "", fmt.Errorf("fetching commit %s: %w", headSHA, err)
+	}
+
+	entries := make([]map[string]any, len(files))
+	for i, f := range files {
+		mode := "100644"
+		if f.Executable {
+			mode = "100755"
+		}
+		e := map[string]any{"path": f.Path, "mode": mode, "type": "blob"}
+		if f.Deleted {
+			e["sha"] = nil
+		} else {
+			e["content"] = f.Content
+		}
+		entries[i] = e
+	}
+	var tree, commit struct {
+		SHA string `json:"sha"`
+	}
+	if _, err := c.Do(ctx, http.MethodPost, base+"trees", map[string]any{"base_tree": parent.Tree.SHA, "tree": entries}, &tree); err != nil {
+		return "", fmt.Errorf("creating tree: %w", err)
+	}
+	body := map[string]any{"message": message, "tree": tree.SHA, "parents": []string{headSHA}}
+	if _, err := c.Do(ctx, http.MethodPost, base+"commits", body, &commit); err != nil {
+		return "", fmt.Errorf("creating commit: %w", err)
+	}
+	if _, err := c.Do(ctx, http.MethodPatch, base+"refs/heads/"+branch, map[string]any{"sha": commit.SHA}, nil); err != nil {
+		return "", fmt.Errorf("updating branch %s: %w", branch, err)
+	}
+	return commit.SHA, nil
+}
+
+// IssueTimeline lists the timeline events of an issue or pull request,
+// oldest first.
+func (c *Client) IssueTimeline(ctx context.Context, owner, repo string, number int) ([]dangerJs.GitHubTimelineEvent, error) {
+	path := fmt.Sprintf("repos/%s/%s/issues/%d/timeline", url.PathEscape(owner), url.PathEscape(repo), number)
+	return listPages[dangerJs.GitHubTimelineEvent](ctx, c, path, nil)
+}
+
+var (
+	_ dangerJs.GitHubFetcher         = (*Client)(nil)
+	_ dangerJs.GitHubComparer        = (*Client)(nil)
+	_ dangerJs.GitHubTimelineFetcher = (*Client)(nil)
+)
+
+// perPage is the page size used by the paginated list methods.
+const perPage = 100
+
+// listPages fetches every page of a list endpoint until a page has fewer
+// than perPage items. query holds the filters of the endpoint.
+func listPages[T any](ctx context.Context, c *Client, path string, query url.Values) ([]T, error) {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	q.Set("per_page", strconv.Itoa(perPage))
+	var all []T
+	for page := 1; ; page++ {
+		q.Set("page", strconv.Itoa(page))
+		var items []T
+		if _, err := c.Do(ctx, http.MethodGet, path+"?"+q.Encode(), nil, &items); err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) < perPage {
+			return all, nil
+		}
+	}
+}
+
+// PullRequestCommits fetches the commits of a pull request. GitHub lists at
+// most 250 commits.
+func (c *Client) PullRequestCommits(ctx context.Context, owner, repo string, number int) ([]dangerJs.GitHubCommit, error) {
+	path := fmt.Sprintf("repos/%s/%s/pulls/%d/commits", url.PathEscape(owner), url.PathEscape(repo), number)
+	return listPages[dangerJs.GitHubCommit](ctx, c, path, nil)
+}
+
+// PullRequestFiles fetches the files changed by a pull request. GitHub lists
+// at most 3000 files.
+func (c *Client) PullRequestFiles(ctx context.Context, owner, repo string, number int) ([]dangerJs.GitHubPRFile, error) {
+	path := fmt.Sprintf("repos/%s/%s/pulls/%d/files", url.PathEscape(owner), url.PathEscape(repo), number)
+	return listPages[dangerJs.GitHubPRFile](ctx, c, path, nil)
+}
+
+// PullRequestReviews fetches the reviews of a pull request.
+func (c *Client) PullRequestReviews(ctx context.Context, owner, repo string, number int) ([]dangerJs.GitHubReview, error) {
+	path := fmt.Sprintf("repos/%s/%s/pulls/%d/reviews", url.PathEscape(owner), url.PathEscape(repo), number)
+	return listPages[dangerJs.GitHubReview](ctx, c, path, nil)
+}
+
+// UpdatePullRequest edits a pull request. fields holds the attributes to
+// change, e.g. title, body or state.
+func (c *Client) UpdatePullRequest(ctx context.Context, owner, repo string, number int, fields map[string]any) (dangerJs.GitHubPR, error) {
+	var pr dangerJs.GitHubPR
+	path := fmt.Sprintf("repos/%s/%s/pulls/%d", url.PathEscape(owner), url.PathEscape(repo), number)
+	if _, err := c.Do(ctx, http.MethodPatch, path, fields, &pr); err != nil {
+		return dangerJs.GitHubPR{}, err
+	}
+	return pr, nil
+}
+
+// ListIssues lists the issues of a repository, including pull requests.
+// query holds the filters of the list endpoint, e.g. state and labels.
+func (c *Client) ListIssues(ctx context.Context, owner, repo string, query url.Values) ([]dangerJs.GitHubIssue, error) {
+	path := fmt.Sprintf("repos/%s/%s/issues", url.PathEscape(owner), url.PathEscape(repo))
+	return listPages[dangerJs.GitHubIssue](ctx, c, path, query)
+}
+
+// CreateIssue opens an issue.
+func (c *Client) CreateIssue(ctx context.Context, owner, repo, title, body string, labels []string) (dangerJs.GitHubIssue, error) {
+	var issue dangerJs.GitHubIssue
+	path := fmt.Sprintf("repos/%s/%s/issues", url.PathEscape(owner), url.PathEscape(repo))
+	fields := map[string]any{"title": title, "body": body}
+	if len(labels) > 0 {
+		fields["labels"] = labels
+	}
+	if _, err := c.Do(ctx, http.MethodPost, path, fields, &issue); err != nil {
+		return dangerJs.GitHubIssue{}, err
+	}
+	return issue, nil
+}
+
+// AuthenticatedUser fetches the user of the token. GitHub App installation
+// and Actions tokens have no user and get a 403.
+func (c *Client) AuthenticatedUser(ctx context.Context) (dangerJs.GitHubUser, error) {
+	var user dangerJs.GitHubUser
+	if _, err := c.Do(ctx, http.MethodGet, "user", nil, &user); err != nil {
+		return dangerJs.GitHubUser{}, err
+	}
+	return user, nil
+}
+
+// IssueComments fetches the comments of an issue or pull request.
+func (c *Client) IssueComments(ctx context.Context, owner, repo string, number int) ([]dangerJs.GitHubIssueComment, error) {
+	path := fmt.Sprintf("repos/%s/%s/issues/%d/comments", url.PathEscape(owner), url.PathEscape(repo), number)
+	return listPages[dangerJs.GitHubIssueComment](ctx, c, path, nil)
+}
+
+// CreateIssueComment comments on an issue or pull request.
+func (c *Client) CreateIssueComment(ctx context.Context, owner, repo string, number int, body string) (dangerJs.GitHubIssueComment, error) {
+	var comment dangerJs.GitHubIssueComment
+	path := fmt.Sprintf("repos/%s/%s/issues/%d/comments", url.PathEscape(owner), url.PathEscape(repo), number)
+	if _, err := c.Do(ctx, http.MethodPost, path, map[string]string{"body": body}, &comment); err != nil {
+		return dangerJs.GitHubIssueComment{}, err
+	}
+	return comment, nil
+}
+
+// UpdateIssueComment edits a comment of an issue or pull request.
+func (c *Client) UpdateIssueComment(ctx context.Context, owner, repo string, id int64, body string) (dangerJs.GitHubIssueComment, error) {
+	var comment dangerJs.GitHubIssueComment
+	path := fmt.Sprintf("repos/%s/%s/issues/comments/%d", url.PathEscape(owner), url.PathEscape(repo), id)
+	if _, err := c.Do(ctx, http.MethodPatch, path, map[string]string{"body": body}, &comment); err != nil {
+		return dangerJs.GitHubIssueComment{}, err
+	}
+	return comment, nil
+}
+
+// DeleteIssueComment deletes a comment of an issue or pull request.
+func (c *Client) DeleteIssueComment(ctx context.Context, owner, repo string, id int64) error {
+	path := fmt.Sprintf("repos/%s/%s/issues/comments/%d", url.PathEscape(owner), url.PathEscape(repo), id)
+	_, err := c.Do(ctx, http.MethodDelete, path, nil, nil)
+	return err
+}
+
+// IssueCommentReactions fetches the reactions to a comment of an issue or
+// pull request. A non-empty content, e.g. "+1", only lists those reactions.
+func (c *Client) IssueCommentReactions(ctx context.Context, owner, repo string, id int64, content string) ([]dangerJs.GitHubReaction, error) {
+	path := fmt.Sprintf("repos/%s/%s/issues/comments/%d/reactions", url.PathEscape(owner), url.PathEscape(repo), id)
+	var query url.Values
+	if content != "" {
+		query = url.Values{"content": {content}}
+	}
+	return listPages[dangerJs.GitHubReaction](ctx, c, path, query)
+}
+
+// CreateIssueCommentReaction reacts to a comment of an issue or pull request.
+// Reacting again with the same content is not an error.
+func (c *Client) CreateIssueCommentReaction(ctx context.Context, owner, repo string, id int64, content string) (dangerJs.GitHubReaction, error) {
+	var reaction dangerJs.GitHubReaction
+	path := fmt.Sprintf("repos/%s/%s/issues/comments/%d/reactions", url.PathEscape(owner), url.PathEscape(repo), id)
+	if _, err := c.Do(ctx, http.MethodPost, path, map[string]string{"content": content}, &reaction); err != nil {
+		return dangerJs.GitHubReaction{}, err
+	}
+	return reaction, nil
+}
+
+// CollaboratorPermission fetches the permission of user on the repository:
+// "admin", "write", "read" or "none". Maintain and triage roles are reported
+// as "write" and "read".
+func (c *Client) CollaboratorPermission(ctx context.Context, owner, repo, user string) (string, error) {
+	var out struct {
+		Permission string `json:"permission"`
+	}
+	path := fmt.Sprintf("repos/%s/%s/collaborators/%s/permission", url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(user))
+	if _, err := c.Do(ctx, http.MethodGet, path, nil, &out); err != nil {
+		return "", err
+	}
+	return out.Permission, nil
+}
+
+// Release is a GitHub release.
+type Release struct {
+	ID        int64  `json:"id"`
+	TagName   string `json:"tag_name"`
+	HTMLURL   string `json:"html_url"`
+	UploadURL string `json:"upload_url"`
+}
+
+// ReleaseAsset is a file attached to a release.
+type ReleaseAsset struct {
+	ID                 int64  `json:"id"`
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// ReleaseByTag fetches the release of a tag.
+func (c *Client) ReleaseByTag(ctx context.Context, owner, repo, tag string) (Release, error) {
+	var release Release
+	path := fmt.Sprintf("repos/%s/%s/releases/tags/%s", url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(tag))
+	if _, err := c.Do(ctx, http.MethodGet, path, nil, &release); err != nil {
+		return Release{}, err
+	}
+	return release, nil
+}
+
+// UploadReleaseAsset uploads data as an asset named name to a release.
+func (c *Client) UploadReleaseAsset(ctx context.Context, release Release, name, contentType string, data []byte) (ReleaseAsset, error) {
+	// the upload URL is a template like ".../assets{?name,label}"
+	rawURL := release.UploadURL
+	if i := strings.Index(rawURL, "{"); i >= 0 {
+		rawURL = rawURL[:i]
+	}
+	rawURL += "?" + url.Values{"name": {name}}.Encode()
+	var asset ReleaseAsset
+	if _, err := c.doRaw(ctx, http.MethodPost, rawURL, data, contentType, &asset); err != nil {
+		return ReleaseAsset{}, err
+	}
+	return asset, nil
+}
+
+// Gist is a GitHub gist.
+type Gist struct {
+	ID      string `json:"id"`
+	HTMLURL string `json:"html_url"`
+	Files   map[string]struct {
+		RawURL string `json:"raw_url"`
+	} `json:"files"`
+}
+
+// CreateGist creates a gist holding files, which maps file names to their
+// content.
+func (c *Client) CreateGist(ctx context.Context, description string, public bool, files map[string]string) (Gist, error) {
+	body := map[string]any{"description": description, "public": public}
+	contents := map[string]map[string]string{}
+	for name, content := range files {
+		contents[name] = map[string]string{"content": content}
+	}
+	body["files"] = contents
+	var gist Gist
+	if _, err := c.Do(ctx, http.MethodPost, "gists", body, &gist); err != nil {
+		return Gist{}, err
+	}
+	return gist, nil
+}
+
+// WorkflowRun is a GitHub Actions workflow run.
+type WorkflowRun struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	WorkflowID int64     `json:"workflow_id"`
+	HeadBranch string    `json:"head_branch"`
+	HeadSHA    string    `json:"head_sha"`
+	Status     string    `json:"status"`
+	Conclusion string    `json:"conclusion"`
+	HTMLURL    string    `json:"html_url"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WorkflowJob is a job of a workflow run.
+type WorkflowJob struct {
+	ID          int64     `json:"id"`
+	RunID       int64     `json:"run_id"`
+	Name        string    `json:"name"`
+	Status      string    `json:"status"`
+	Conclusion  string    `json:"conclusion"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// Duration returns how long the job ran, zero unless it completed.
+func (j WorkflowJob) Duration() time.Duration {
+	if j.StartedAt.IsZero() || j.CompletedAt.IsZero() {
+		return 0
+	}
+	return j.CompletedAt.Sub(j.StartedAt)
+}
+
+// WorkflowRun fetches a workflow run.
+func (c *Client) WorkflowRun(ctx context.Context, owner, repo string, id int64) (WorkflowRun, error) {
+	var run WorkflowRun
+	path := fmt.Sprintf("repos/%s/%s/actions/runs/%d", url.PathEscape(owner), url.PathEscape(repo), id)
+	if _, err := c.Do(ctx, http.MethodGet, path, nil, &run); err != nil {
+		return WorkflowRun{}, err
+	}
+	return run, nil
+}
+
+// ListWorkflowRuns lists the most recent runs of a workflow, at most
+// perPage. query holds the filters of the endpoint, e.g. branch and status.
+func (c *Client) ListWorkflowRuns(ctx context.Context, owner, repo string, workflowID int64, query url.Values) ([]WorkflowRun, error) {
+	q := url.Values{"per_page": {strconv.Itoa(perPage)}}
+	for k, v := range query {
+		q[k] = v
+	}
+	var resp struct {
+		WorkflowRuns []WorkflowRun `json:"workflow_runs"`
+	}
+	path := fmt.Sprintf("repos/%s/%s/actions/workflows/%d/runs?%s", url.PathEscape(owner), url.PathEscape(repo), workflowID, q.Encode())
+	if _, err := c.Do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.WorkflowRuns, nil
+}
+
+// WorkflowRunJobs fetches the jobs of the latest attempt of a workflow run.
+func (c *Client) WorkflowRunJobs(ctx context.Context, owner, repo string, runID int64) ([]WorkflowJob, error) {
+	var all []WorkflowJob
+	for page := 1; ; page++ {
+		var resp struct {
+			Jobs []WorkflowJob `json:"jobs"`
+		}
+		path := fmt.Sprintf("repos/%s/%s/actions/runs/%d/jobs?per_page=%d&page=%d",
+			url.PathEscape(owner), url.PathEscape(repo), runID, perPage, page)
+		if _, err := c.Do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+			return nil, err
+		}
+		all = append(all, resp.Jobs...)
+		if len(resp.Jobs) < perPage {
+			return all, nil
+		}
+	}
+}
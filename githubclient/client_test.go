@@ -0,0 +1,479 @@
+package githubclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+func TestEndpoints(t *testing.T) {
+	tests := []struct {
+		name        string
+		baseURL     string
+		wantREST    string
+		wantGraphQL string
+	}{
+		{
+			name:        "empty base URL",
+			baseURL:     "",
+			wantREST:    "https://api.github.com",
+			wantGraphQL: "https://api.github.com/graphql",
+		},
+		{
+			name:        "github.com web URL",
+			baseURL:     "https://github.com",
+			wantREST:    "https://api.github.com",
+			wantGraphQL: "https://api.github.com/graphql",
+		},
+		{
+			name:        "enterprise host",
+			baseURL:     "https://github.example.com",
+			wantREST:    "https://github.example.com/api/v3",
+			wantGraphQL: "https://github.example.com/api/graphql",
+		},
+		{
+			name:        "enterprise REST root",
+			baseURL:     "https://github.example.com/api/v3/",
+			wantREST:    "https://github.example.com/api/v3",
+			wantGraphQL: "https://github.example.com/api/graphql",
+		},
+		{
+			name:        "enterprise GraphQL endpoint",
+			baseURL:     "https://github.example.com/api/graphql",
+			wantREST:    "https://github.example.com/api/v3",
+			wantGraphQL: "https://github.example.com/api/graphql",
+		},
+		{
+			name:        "enterprise api prefix",
+			baseURL:     "https://github.example.com/api",
+			wantREST:    "https://github.example.com/api/v3",
+			wantGraphQL: "https://github.example.com/api/graphql",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(tt.baseURL, "")
+			require.Nil(t, err)
+			require.Equal(t, tt.wantREST, c.RESTURL())
+			require.Equal(t, tt.wantGraphQL, c.GraphQLURL())
+		})
+	}
+}
+
+func TestNewInvalidBaseURL(t *testing.T) {
+	_, err := New("not a url", "")
+	require.NotNil(t, err)
+}
+
+func TestPullRequestEnterprisePayload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v3/repos/danger/golang/pulls/7", r.URL.Path)
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.Header().Set("X-GitHub-Enterprise-Version", "3.6.0")
+		// GHES 3.x omits draft, author_association and sends null dates
+		_, _ = w.Write([]byte(`{"number":7,"state":"open","title":"Add feature","merged_at":null,"closed_at":null}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, "token")
+	require.Nil(t, err)
+
+	pr, err := c.PullRequest(context.Background(), "danger", "golang", 7)
+	require.Nil(t, err)
+	require.Equal(t, 7, pr.Number)
+	require.Equal(t, "Add feature", pr.Title)
+	require.False(t, pr.Draft)
+	require.True(t, pr.MergedAt.IsZero())
+	require.Equal(t, "3.6.0", c.EnterpriseVersion())
+}
+
+func TestAPIVersionFallback(t *testing.T) {
+	var versions []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v := r.Header.Get("X-GitHub-Api-Version")
+		versions = append(versions, v)
+		if v != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"message":"Unsupported 'X-GitHub-Api-Version' header"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"number":1}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL+"/api/v3", "")
+	require.Nil(t, err)
+
+	_, err = c.PullRequest(context.Background(), "o", "r", 1)
+	require.Nil(t, err)
+	_, err = c.PullRequest(context.Background(), "o", "r", 1)
+	require.Nil(t, err)
+	require.Equal(t, []string{APIVersion, "", ""}, versions)
+}
+
+func TestGraphQL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/graphql", r.URL.Path)
+		_, _ = w.Write([]byte(`{"data":{"viewer":{"login":"octocat"}}}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, "")
+	require.Nil(t, err)
+
+	var out struct {
+		Viewer struct {
+			Login string `json:"login"`
+		} `json:"viewer"`
+	}
+	err = c.GraphQL(context.Background(), "{ viewer { login } }", nil, &out)
+	require.Nil(t, err)
+	require.Equal(t, "octocat", out.Viewer.Login)
+}
+
+func TestErrorResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, "")
+	require.Nil(t, err)
+
+	_, err = c.PullRequest(context.Background(), "o", "r", 1)
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	require.Equal(t, "Not Found", apiErr.Message)
+	require.NotErrorIs(t, err, dangerJs.ErrAPIUnauthorized)
+}
+
+func TestErrorPermissions(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		want   string
+	}{
+		{
+			name:   "app token",
+			header: http.Header{"X-Accepted-Github-Permissions": {"pull_requests=write"}},
+			want:   "403 Resource not accessible by integration (the token needs the pull_requests=write permission)",
+		},
+		{
+			name:   "classic token",
+			header: http.Header{"X-Accepted-Oauth-Scopes": {"repo, public_repo"}},
+			want:   "403 Resource not accessible by integration (the token needs one of the scopes repo, public_repo)",
+		},
+		{name: "no header", header: http.Header{}, want: "403 Resource not accessible by integration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.header {
+					w.Header()[k] = v
+				}
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"message":"Resource not accessible by integration"}`))
+			}))
+			defer srv.Close()
+
+			c, err := New(srv.URL, "t")
+			require.Nil(t, err)
+			err = c.AddLabels(context.Background(), "o", "r", 1, "bug")
+			require.NotNil(t, err)
+			require.True(t, strings.HasSuffix(err.Error(), tt.want), err.Error())
+		})
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message":"Bad credentials"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, "expired")
+	require.Nil(t, err)
+
+	_, err = c.PullRequest(context.Background(), "o", "r", 1)
+	require.ErrorIs(t, err, dangerJs.ErrAPIUnauthorized)
+}
+
+func TestCombinedStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v3/repos/o/r/commits/abc/status", r.URL.Path)
+		_, _ = w.Write([]byte(`{"state":"failure","sha":"abc","total_count":1,
+			"statuses":[{"id":1,"state":"failure","context":"ci/build","target_url":"https://ci.example.com/1"}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, "")
+	require.Nil(t, err)
+
+	status, err := c.CombinedStatus(context.Background(), "o", "r", "abc")
+	require.Nil(t, err)
+	require.Equal(t, "failure", status.State)
+	require.Equal(t, []dangerJs.GitHubCommitStatus{
+		{ID: 1, State: "failure", Context: "ci/build", TargetURL: "https://ci.example.com/1"},
+	}, status.Statuses)
+}
+
+func TestLabels(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		calls = append(calls, r.Method+" "+r.URL.EscapedPath()+" "+string(body))
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, "")
+	require.Nil(t, err)
+
+	require.Nil(t, c.AddLabels(context.Background(), "o", "r", 7, "semver/minor"))
+	require.Nil(t, c.RemoveLabel(context.Background(), "o", "r", 7, "semver/patch"))
+	require.Equal(t, []string{
+		`POST /api/v3/repos/o/r/issues/7/labels {"labels":["semver/minor"]}`,
+		`DELETE /api/v3/repos/o/r/issues/7/labels/semver%2Fpatch `,
+	}, calls)
+}
+
+func TestListPullRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v3/repos/o/r/pulls", r.URL.Path)
+		require.Equal(t, "o:feature", r.URL.Query().Get("head"))
+		require.Equal(t, "open", r.URL.Query().Get("state"))
+		_, _ = w.Write([]byte(`[{"number":4,"title":"Base","head":{"ref":"feature"},"base":{"ref":"main"}}]`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, "")
+	require.Nil(t, err)
+
+	prs, err := c.ListPullRequests(context.Background(), "o", "r", url.Values{"head": {"o:feature"}, "state": {"open"}})
+	require.Nil(t, err)
+	require.Len(t, prs, 1)
+	require.Equal(t, 4, prs[0].Number)
+	require.Equal(t, "main", prs[0].Base.Ref)
+}
+
+func TestSearchIssuesCount(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v3/search/issues", r.URL.Path)
+		require.Equal(t, "is:pr is:open review-requested:alice repo:o/r", r.URL.Query().Get("q"))
+		_, _ = w.Write([]byte(`{"total_count":3,"items":[{"number":1}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, "")
+	require.Nil(t, err)
+	n, err := c.SearchIssuesCount(context.Background(), "is:pr is:open review-requested:alice repo:o/r")
+	require.Nil(t, err)
+	require.Equal(t, 3, n)
+}
+
+func TestRequestReviewers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/api/v3/repos/o/r/pulls/7/requested_reviewers", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		require.JSONEq(t, `{"reviewers":["alice","bob"]}`, string(body))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, "")
+	require.Nil(t, err)
+	require.Nil(t, c.RequestReviewers(context.Background(), "o", "r", 7, "alice", "bob"))
+}
+
+func TestCompare(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v3/repos/o/r/compare/main...feature%2Flogin", r.URL.EscapedPath())
+		_, _ = w.Write([]byte(`{"status":"diverged","ahead_by":2,"behind_by":5,"total_commits":2,
+			"files":[{"filename":"a.go","status":"modified","changes":1,"patch":"@@ -1 +1 @@\n-a\n+b"}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, "")
+	require.Nil(t, err)
+	cmp, err := c.Compare(context.Background(), "o", "r", "main", "feature/login")
+	require.Nil(t, err)
+	require.Equal(t, "diverged", cmp.Status)
+	require.Equal(t, 2, cmp.AheadBy)
+	require.Equal(t, 5, cmp.BehindBy)
+	require.Equal(t, 2, cmp.TotalCommits)
+	diff, err := cmp.DiffForFile("a.go")
+	require.Nil(t, err)
+	require.Equal(t, dangerJs.FileDiff{
+		AddedLines:   []dangerJs.DiffLine{{Content: "b", Line: 1, Hunk: 1, Position: 2}},
+		RemovedLines: []dangerJs.DiffLine{{Content: "a", Line: 1, Hunk: 1, Position: 1}},
+	}, diff)
+}
+
+func TestUpdateBranch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		require.Equal(t, "/api/v3/repos/o/r/pulls/7/update-branch", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		require.JSONEq(t, `{"expected_head_sha":"abc"}`, string(body))
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"message":"Updating pull request branch."}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, "")
+	require.Nil(t, err)
+	require.Nil(t, c.UpdateBranch(context.Background(), "o", "r", 7, "abc"))
+}
+
+func TestCommitFiles(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/api/v3/repos/o/r/git/ref/heads/feature/x":
+			_, _ = w.Write([]byte(`{"object":{"sha":"head"}}`))
+		case "/api/v3/repos/o/r/git/commits/head":
+			_, _ = w.Write([]byte(`{"sha":"head","tree":{"sha":"basetree"}}`))
+		case "/api/v3/repos/o/r/git/trees":
+			require.JSONEq(t, `{"base_tree":"basetree","tree":[
+				{"path":"a.go","mode":"100644","type":"blob","content":"package a\n"},
+				{"path":"old.go","mode":"100644","type":"blob","sha":null}]}`, string(body))
+			_, _ = w.Write([]byte(`{"sha":"tree"}`))
+		case "/api/v3/repos/o/r/git/commits":
+			require.JSONEq(t, `{"message":"Apply fixes","tree":"tree","parents":["head"]}`, string(body))
+			_, _ = w.Write([]byte(`{"sha":"fixed"}`))
+		case "/api/v3/repos/o/r/git/refs/heads/feature/x":
+			require.JSONEq(t, `{"sha":"fixed"}`, string(body))
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, "")
+	require.Nil(t, err)
+	files := []CommitFile{
+		{Path: "a.go", Content: "package a\n"},
+		{Path: "old.go", Deleted: true},
+	}
+	sha, err := c.CommitFiles(context.Background(), "o", "r", "feature/x", "head", "Apply fixes", files)
+	require.Nil(t, err)
+	require.Equal(t, "fixed", sha)
+	require.Equal(t, []string{
+		"GET /api/v3/repos/o/r/git/ref/heads/feature/x",
+		"GET /api/v3/repos/o/r/git/commits/head",
+		"POST /api/v3/repos/o/r/git/trees",
+		"POST /api/v3/repos/o/r/git/commits",
+		"PATCH /api/v3/repos/o/r/git/refs/heads/feature/x",
+	}, requests)
+
+	// a push since the checkout moved the branch
+	requests = nil
+	_, err = c.CommitFiles(context.Background(), "o", "r", "feature/x", "checkout", "Apply fixes", files)
+	require.EqualError(t, err, "branch feature/x is at head, not checkout")
+	require.Equal(t, []string{"GET /api/v3/repos/o/r/git/ref/heads/feature/x"}, requests)
+}
+
+func TestTokenScopes(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []string
+		want   []string
+		known  bool
+	}{
+		{name: "classic token", header: []string{"repo, read:org"}, want: []string{"repo", "read:org"}, known: true},
+		{name: "no scopes", header: []string{""}, known: true},
+		{name: "app token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "/api/v3/rate_limit", r.URL.Path)
+				if tt.header != nil {
+					w.Header()["X-Oauth-Scopes"] = tt.header
+				}
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer srv.Close()
+
+			c, err := New(srv.URL, "t")
+			require.Nil(t, err)
+			scopes, known, err := c.TokenScopes(context.Background())
+			require.Nil(t, err)
+			require.Equal(t, tt.known, known)
+			require.Equal(t, tt.want, scopes)
+		})
+	}
+}
+
+func TestPullRequestCommitsPagination(t *testing.T) {
+	var pages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v3/repos/o/r/pulls/3/commits", r.URL.Path)
+		pages = append(pages, r.URL.Query().Get("page"))
+		n := 100
+		if r.URL.Query().Get("page") == "2" {
+			n = 1
+		}
+		_, _ = w.Write([]byte("[" + strings.TrimSuffix(strings.Repeat(`{"sha":"abc"},`, n), ",") + "]"))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, "")
+	require.Nil(t, err)
+
+	commits, err := c.PullRequestCommits(context.Background(), "o", "r", 3)
+	require.Nil(t, err)
+	require.Len(t, commits, 101)
+	require.Equal(t, []string{"1", "2"}, pages)
+}
+
+func TestIssueTimeline(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v3/repos/o/r/issues/3/timeline", r.URL.Path)
+		_, _ = w.Write([]byte(`[
+			{"id":1,"event":"labeled","actor":{"login":"alice"},"created_at":"2026-01-02T03:04:05Z","label":{"name":"do-not-merge"}},
+			{"id":2,"event":"reviewed","user":{"login":"bob"},"state":"approved","submitted_at":"2026-01-03T00:00:00Z"},
+			{"id":3,"event":"head_ref_force_pushed","actor":{"login":"alice"},"commit_id":"abc"}]`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, "")
+	require.Nil(t, err)
+	events, err := c.IssueTimeline(context.Background(), "o", "r", 3)
+	require.Nil(t, err)
+	require.Len(t, events, 3)
+	require.Equal(t, "do-not-merge", events[0].Label.Name)
+	require.Equal(t, "bob", events[1].Login())
+	require.Equal(t, "abc", events[2].CommitID)
+}
+
+func TestPullRequestFiles(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v3/repos/o/r/pulls/3/files", r.URL.Path)
+		_, _ = w.Write([]byte(`[{"filename":"b.go","status":"renamed","previous_filename":"a.go","additions":1,"changes":1,"patch":"@@ -1 +1 @@"}]`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, "")
+	require.Nil(t, err)
+
+	files, err := c.PullRequestFiles(context.Background(), "o", "r", 3)
+	require.Nil(t, err)
+	require.Equal(t, []dangerJs.GitHubPRFile{{Filename: "b.go", Status: "renamed", PreviousFilename: "a.go", Additions: 1, Changes: 1, Patch: "@@ -1 +1 @@"}}, files)
+}
@@ -0,0 +1,564 @@
+// Package gitlabclient is a small GitLab REST client used by all GitLab write
+// operations. It works with gitlab.com and self-managed instances, and with
+// personal, project, group, OAuth and CI job tokens.
+package gitlabclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/tracing"
+)
+
+// DefaultBaseURL is the instance used when no base URL is configured.
+const DefaultBaseURL = "https://gitlab.com"
+
+// TokenType describes how a token authenticates against the API.
+type TokenType int
+
+const (
+	// PersonalToken is a personal access token.
+	PersonalToken TokenType = iota
+	// ProjectToken is a project or group access token.
+	ProjectToken
+	// JobToken is the CI_JOB_TOKEN of a running pipeline job.
+	JobToken
+	// OAuthToken is an OAuth2 bearer token.
+	OAuthToken
+)
+
+func (t TokenType) String() string {
+	switch t {
+	case PersonalToken:
+		return "personal"
+	case ProjectToken:
+		return "project"
+	case JobToken:
+		return "job"
+	case OAuthToken:
+		return "oauth"
+	default:
+		return "unknown"
+	}
+}
+
+// Token is a credential along with how it must be presented to GitLab.
+type Token struct {
+	Type  TokenType
+	Value string
+}
+
+// header returns the header name and value used to authenticate with t.
+func (t Token) header() (string, string) {
+	switch t.Type {
+	case JobToken:
+		return "JOB-TOKEN", t.Value
+	case OAuthToken:
+		return "Authorization", "Bearer " + t.Value
+	default:
+		return "PRIVATE-TOKEN", t.Value
+	}
+}
+
+// Version is the GitLab release of the instance.
+type Version struct {
+	Major    int
+	Minor    int
+	Revision string
+}
+
+// AtLeast reports whether v is at least major.minor.
+func (v Version) AtLeast(major, minor int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	return v.Minor >= minor
+}
+
+// Client talks to the GitLab v4 REST API.
+type Client struct {
+	apiURL     *url.URL
+	token      Token
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	version *Version
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// New creates a client for the instance at baseURL. An empty baseURL means
+// gitlab.com. The URL may be given with or without the /api/v4 suffix.
+func New(baseURL string, token Token, opts ...Option) (*Client, error) {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if !strings.Contains(baseURL, "://") {
+		// danger-js accepts DANGER_GITLAB_HOST without a scheme
+		baseURL = "https://" + baseURL
+	}
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing base URL: %w", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL: %s", baseURL)
+	}
+	if !strings.HasSuffix(u.Path, "/api/v4") {
+		u.Path += "/api/v4"
+	}
+
+	c := &Client{
+		apiURL:     u,
+		token:      token,
+		httpClient: http.DefaultClient,
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c, nil
+}
+
+// NewFromEnv creates a client using the same environment variables as
+// danger-js, falling back to the CI job token when running in GitLab CI.
+func NewFromEnv(opts ...Option) (*Client, error) {
+	baseURL := firstEnv("DANGER_GITLAB_HOST", "CI_SERVER_URL")
+
+	var token Token
+	switch {
+	case os.Getenv("DANGER_GITLAB_API_TOKEN") != "":
+		token = Token{Type: PersonalToken, Value: os.Getenv("DANGER_GITLAB_API_TOKEN")}
+	case os.Getenv("DANGER_GITLAB_API_OAUTH_TOKEN") != "":
+		token = Token{Type: OAuthToken, Value: os.Getenv("DANGER_GITLAB_API_OAUTH_TOKEN")}
+	case os.Getenv("CI_JOB_TOKEN") != "":
+		token = Token{Type: JobToken, Value: os.Getenv("CI_JOB_TOKEN")}
+	default:
+		return nil, fmt.Errorf("no GitLab token found, set DANGER_GITLAB_API_TOKEN")
+	}
+	return New(baseURL, token, opts...)
+}
+
+// NewFromSettings creates a client from the GitLab settings of the DSL,
+// falling back to NewFromEnv for OAuth and CI job tokens when the settings
+// hold no token.
+func NewFromSettings(s dangerJs.Settings, opts ...Option) (*Client, error) {
+	token := dangerJs.GitLabAccessToken(s)
+	if token == "" {
+		return NewFromEnv(opts...)
+	}
+	return New(dangerJs.GitLabBaseURL(s), Token{Type: PersonalToken, Value: token}, opts...)
+}
+
+func firstEnv(keys ...string) string {
+	for _, k := range keys {
+		if v := os.Getenv(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// APIURL returns the root of the v4 API.
+func (c *Client) APIURL() string {
+	return c.apiURL.String()
+}
+
+// TokenType returns the type of token the client authenticates with.
+func (c *Client) TokenType() TokenType {
+	return c.token.Type
+}
+
+// Error is returned when the API responds with a non-2xx status.
+type Error struct {
+	StatusCode int
+	Method     string
+	URL        string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, msg)
+}
+
+// Is reports a 401 response as dangerJs.ErrAPIUnauthorized.
+func (e *Error) Is(target error) bool {
+	return target == dangerJs.ErrAPIUnauthorized && e.StatusCode == http.StatusUnauthorized
+}
+
+// ProjectPath returns the URL path segment for a project given either its
+// numeric ID or its full path, e.g. "group/sub/project".
+func ProjectPath(project string) string {
+	return "projects/" + url.PathEscape(project)
+}
+
+// Do sends a request to the API. path is relative to /api/v4 and may include
+// a query string. body, when not nil, is encoded as JSON and the response is
+// decoded into out when out is not nil.
+func (c *Client) Do(ctx context.Context, method, path string, body, out any) (_ *http.Response, err error) {
+	rawURL := c.apiURL.String() + "/" + strings.TrimPrefix(path, "/")
+	ctx, span := tracing.Start(ctx, "gitlab "+method)
+	span.SetAttr("http.url", rawURL)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling request body: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, reader)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token.Value != "" {
+		k, v := c.token.header()
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, rawURL, err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response from %s: %w", rawURL, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &Error{StatusCode: resp.StatusCode, Method: method, URL: rawURL}
+		// GitLab uses either `message` or `error` depending on the endpoint
+		var e struct {
+			Message any    `json:"message"`
+			Error   string `json:"error"`
+		}
+		if json.Unmarshal(respBody, &e) == nil {
+			if e.Message != nil {
+				apiErr.Message = fmt.Sprint(e.Message)
+			} else {
+				apiErr.Message = e.Error
+			}
+		}
+		return resp, apiErr
+	}
+
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return resp, fmt.Errorf("decoding response from %s: %w", rawURL, err)
+		}
+	}
+	return resp, nil
+}
+
+// Version returns the GitLab version of the instance. The result is cached.
+// Job tokens cannot read the version endpoint, in which case an error is
+// returned and callers should assume a recent release.
+func (c *Client) Version(ctx context.Context) (Version, error) {
+	c.mu.Lock()
+	if c.version != nil {
+		defer c.mu.Unlock()
+		return *c.version, nil
+	}
+	c.mu.Unlock()
+
+	var resp struct {
+		Version  string `json:"version"`
+		Revision string `json:"revision"`
+	}
+	if _, err := c.Do(ctx, http.MethodGet, "version", nil, &resp); err != nil {
+		return Version{}, err
+	}
+	v, err := parseVersion(resp.Version)
+	if err != nil {
+		return Version{}, err
+	}
+	v.Revision = resp.Revision
+
+	c.mu.Lock()
+	c.version = &v
+	c.mu.Unlock()
+	return v, nil
+}
+
+// parseVersion parses versions like "16.2.1-ee".
+func parseVersion(s string) (Version, error) {
+	parts := strings.SplitN(s, ".", 3)
+	if len(parts) < 2 {
+		return Version{}, fmt.Errorf("invalid GitLab version: %q", s)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return Version{}, fmt.Errorf("invalid GitLab version: %q", s)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return Version{}, fmt.Errorf("invalid GitLab version: %q", s)
+	}
+	return Version{Major: major, Minor: minor}, nil
+}
+
+// Note is a comment on a merge request.
+type Note struct {
+	ID     int64  `json:"id"`
+	Body   string `json:"body"`
+	System bool   `json:"system"`
+}
+
+// CreateMRNote adds a comment to a merge request.
+func (c *Client) CreateMRNote(ctx context.Context, project string, mrIID int64, body string) (Note, error) {
+	var note Note
+	path := fmt.Sprintf("%s/merge_requests/%d/notes", ProjectPath(project), mrIID)
+	if _, err := c.Do(ctx, http.MethodPost, path, map[string]string{"body": body}, &note); err != nil {
+		return Note{}, err
+	}
+	return note, nil
+}
+
+// UpdateMRNote replaces the body of an existing merge request comment.
+func (c *Client) UpdateMRNote(ctx context.Context, project string, mrIID, noteID int64, body string) (Note, error) {
+	var note Note
+	path := fmt.Sprintf("%s/merge_requests/%d/notes/%d", ProjectPath(project), mrIID, noteID)
+	if _, err := c.Do(ctx, http.MethodPut, path, map[string]string{"body": body}, &note); err != nil {
+		return Note{}, err
+	}
+	return note, nil
+}
+
+// UpdateMRLabels adds and removes merge request labels, leaving the others.
+func (c *Client) UpdateMRLabels(ctx context.Context, project string, mrIID int64, add, remove []string) error {
+	body := map[string]string{}
+	if len(add) > 0 {
+		body["add_labels"] = strings.Join(add, ",")
+	}
+	if len(remove) > 0 {
+		body["remove_labels"] = strings.Join(remove, ",")
+	}
+	path := fmt.Sprintf("%s/merge_requests/%d", ProjectPath(project), mrIID)
+	_, err := c.Do(ctx, http.MethodPut, path, body, nil)
+	return err
+}
+
+// UpdateMR edits a merge request. fields holds the attributes to change,
+// e.g. "title" or "description".
+func (c *Client) UpdateMR(ctx context.Context, project string, mrIID int64, fields map[string]any) (dangerJs.GitLabMR, error) {
+	var mr dangerJs.GitLabMR
+	path := fmt.Sprintf("%s/merge_requests/%d", ProjectPath(project), mrIID)
+	if _, err := c.Do(ctx, http.MethodPut, path, fields, &mr); err != nil {
+		return dangerJs.GitLabMR{}, err
+	}
+	return mr, nil
+}
+
+// RebaseMR rebases the source branch of a merge request onto its target
+// branch. GitLab rebases asynchronously.
+func (c *Client) RebaseMR(ctx context.Context, project string, mrIID int64) error {
+	path := fmt.Sprintf("%s/merge_requests/%d/rebase", ProjectPath(project), mrIID)
+	_, err := c.Do(ctx, http.MethodPut, path, nil, nil)
+	return err
+}
+
+// ErrUnsupported is returned by the methods needing a newer GitLab release
+// than the instance runs.
+var ErrUnsupported = errors.New("not supported by this GitLab version")
+
+// TokenScopes returns the scopes of the personal, project or group access
+// token of the client, e.g. "api" or "read_api". It fails for OAuth and CI
+// job tokens, and with ErrUnsupported before GitLab 15.5.
+func (c *Client) TokenScopes(ctx context.Context) ([]string, error) {
+	if v, err := c.Version(ctx); err == nil && !v.AtLeast(15, 5) {
+		return nil, fmt.Errorf("listing token scopes on GitLab %d.%d: %w", v.Major, v.Minor, ErrUnsupported)
+	}
+	var out struct {
+		Scopes []string `json:"scopes"`
+	}
+	if _, err := c.Do(ctx, http.MethodGet, "personal_access_tokens/self", nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Scopes, nil
+}
+
+// CommitFile is a file changed by CommitFiles.
+type CommitFile struct {
+	Path    string
+	Content string
+	// Executable sets the executable bit of the file.
+	Executable bool
+	// Deleted removes the file, ignoring Content.
+	Deleted bool
+	// New creates the file rather than updating it.
+	New bool
+	// LastCommitID is the last commit changing the file, for updates and
+	// deletions. GitLab refuses the commit when the file changed since.
+	LastCommitID string
+}
+
+// CommitFiles commits files to branch as a single commit on top of
+// startSHA, returning its SHA.
+func (c *Client) CommitFiles(ctx context.Context, project, branch, startSHA, message string, files []CommitFile) (string, error) {
+	actions := make([]map[string]any, len(files))
+	for i, f := range files {
+		a := map[string]any{"file_path": f.Path}
+		switch {
+		case f.Deleted:
+			a["action"] = "delete"
+		case f.New:
+			a["action"] = "create"
+		default:
+			a["action"] = "update"
+		}
+		if !f.Deleted {
+			a["content"] = f.Content
+			a["execute_filemode"] = f.Executable
+		}
+		if f.LastCommitID != "" {
+			a["last_commit_id"] = f.LastCommitID
+		}
+		actions[i] = a
+	}
+	var out struct {
+		ID string `json:"id"`
+	}
+	body := map[string]any{"branch": branch, "commit_message": message, "actions": actions}
+	if startSHA != "" {
+		body["start_sha"] = startSHA
+	}
+	if _, err := c.Do(ctx, http.MethodPost, ProjectPath(project)+"/repository/commits", body, &out); err != nil {
+		return "", err
+	}
+	return out.ID, nil
+}
+
+// ListMRs lists the merge requests of a project. query holds the filters of
+// the list endpoint, e.g. state, source_branch and target_branch.
+func (c *Client) ListMRs(ctx context.Context, project string, query url.Values) ([]dangerJs.GitLabMR, error) {
+	var mrs []dangerJs.GitLabMR
+	path := ProjectPath(project) + "/merge_requests"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	if _, err := c.Do(ctx, http.MethodGet, path, nil, &mrs); err != nil {
+		return nil, err
+	}
+	return mrs, nil
+}
+
+// MR fetches a single merge request.
+func (c *Client) MR(ctx context.Context, project string, mrIID int64) (dangerJs.GitLabMR, error) {
+	var mr dangerJs.GitLabMR
+	path := fmt.Sprintf("%s/merge_requests/%d", ProjectPath(project), mrIID)
+	if _, err := c.Do(ctx, http.MethodGet, path, nil, &mr); err != nil {
+		return dangerJs.GitLabMR{}, err
+	}
+	return mr, nil
+}
+
+var _ dangerJs.GitLabFetcher = (*Client)(nil)
+
+// perPage is the page size used by the paginated list methods.
+const perPage = 100
+
+// listPages fetches every page of a list endpoint until a page has fewer
+// than perPage items. query holds the filters of the endpoint.
+func listPages[T any](ctx context.Context, c *Client, path string, query url.Values) ([]T, error) {
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	q.Set("per_page", strconv.Itoa(perPage))
+	var all []T
+	for page := 1; ; page++ {
+		q.Set("page", strconv.Itoa(page))
+		var items []T
+		if _, err := c.Do(ctx, http.MethodGet, path+"?"+q.Encode(), nil, &items); err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) < perPage {
+			return all, nil
+		}
+	}
+}
+
+// MRCommits fetches the commits of a merge request.
+func (c *Client) MRCommits(ctx context.Context, project string, mrIID int64) ([]dangerJs.GitLabMRCommit, error) {
+	path := fmt.Sprintf("%s/merge_requests/%d/commits", ProjectPath(project), mrIID)
+	return listPages[dangerJs.GitLabMRCommit](ctx, c, path, nil)
+}
+
+// MRApprovals fetches the approval state of a merge request.
+func (c *Client) MRApprovals(ctx context.Context, project string, mrIID int64) (dangerJs.GitLabApproval, error) {
+	var approvals dangerJs.GitLabApproval
+	path := fmt.Sprintf("%s/merge_requests/%d/approvals", ProjectPath(project), mrIID)
+	if _, err := c.Do(ctx, http.MethodGet, path, nil, &approvals); err != nil {
+		return dangerJs.GitLabApproval{}, err
+	}
+	return approvals, nil
+}
+
+// CommitStatus is the status of an external job on a commit, shown in the
+// pipeline of merge requests.
+type CommitStatus struct {
+	ID          int64  `json:"id,omitempty"`
+	SHA         string `json:"sha,omitempty"`
+	Ref         string `json:"ref,omitempty"`
+	Name        string `json:"name"`
+	State       string `json:"status"` // "pending" | "running" | "success" | "failed" | "canceled"
+	TargetURL   string `json:"target_url,omitempty"`
+	Description string `json:"description,omitempty"`
+	PipelineID  int64  `json:"pipeline_id,omitempty"`
+}
+
+// SetCommitStatus creates or updates the status s.Name of commit sha.
+func (c *Client) SetCommitStatus(ctx context.Context, project, sha string, s CommitStatus) (CommitStatus, error) {
+	body := map[string]any{"state": s.State, "name": s.Name}
+	if s.Ref != "" {
+		body["ref"] = s.Ref
+	}
+	if s.TargetURL != "" {
+		body["target_url"] = s.TargetURL
+	}
+	if s.Description != "" {
+		body["description"] = s.Description
+	}
+	if s.PipelineID != 0 {
+		body["pipeline_id"] = s.PipelineID
+	}
+	var status CommitStatus
+	path := fmt.Sprintf("%s/statuses/%s", ProjectPath(project), url.PathEscape(sha))
+	if _, err := c.Do(ctx, http.MethodPost, path, body, &status); err != nil {
+		return CommitStatus{}, err
+	}
+	return status, nil
+}
@@ -0,0 +1,353 @@
+package gitlabclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+func TestNewAPIURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		want    string
+	}{
+		{name: "empty", baseURL: "", want: "https://gitlab.com/api/v4"},
+		{name: "self-managed", baseURL: "https://gitlab.example.com/", want: "https://gitlab.example.com/api/v4"},
+		{name: "with api suffix", baseURL: "https://gitlab.example.com/api/v4", want: "https://gitlab.example.com/api/v4"},
+		{name: "host only", baseURL: "gitlab.example.com", want: "https://gitlab.example.com/api/v4"},
+		{name: "relative path install", baseURL: "https://example.com/gitlab", want: "https://example.com/gitlab/api/v4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(tt.baseURL, Token{})
+			require.Nil(t, err)
+			require.Equal(t, tt.want, c.APIURL())
+		})
+	}
+}
+
+func TestTokenHeaders(t *testing.T) {
+	tests := []struct {
+		name      string
+		token     Token
+		wantKey   string
+		wantValue string
+	}{
+		{name: "personal", token: Token{Type: PersonalToken, Value: "p"}, wantKey: "PRIVATE-TOKEN", wantValue: "p"},
+		{name: "project", token: Token{Type: ProjectToken, Value: "pr"}, wantKey: "PRIVATE-TOKEN", wantValue: "pr"},
+		{name: "job", token: Token{Type: JobToken, Value: "j"}, wantKey: "JOB-TOKEN", wantValue: "j"},
+		{name: "oauth", token: Token{Type: OAuthToken, Value: "o"}, wantKey: "Authorization", wantValue: "Bearer o"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, tt.wantValue, r.Header.Get(tt.wantKey))
+				_, _ = w.Write([]byte(`{"version":"14.10.5-ee"}`))
+			}))
+			defer srv.Close()
+
+			c, err := New(srv.URL, tt.token)
+			require.Nil(t, err)
+			_, err = c.Version(context.Background())
+			require.Nil(t, err)
+		})
+	}
+}
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv("DANGER_GITLAB_HOST", "")
+	t.Setenv("CI_SERVER_URL", "https://gitlab.example.com")
+	t.Setenv("DANGER_GITLAB_API_TOKEN", "")
+	t.Setenv("DANGER_GITLAB_API_OAUTH_TOKEN", "")
+	t.Setenv("CI_JOB_TOKEN", "job")
+
+	c, err := NewFromEnv()
+	require.Nil(t, err)
+	require.Equal(t, JobToken, c.TokenType())
+	require.Equal(t, "https://gitlab.example.com/api/v4", c.APIURL())
+
+	t.Setenv("DANGER_GITLAB_API_TOKEN", "personal")
+	c, err = NewFromEnv()
+	require.Nil(t, err)
+	require.Equal(t, PersonalToken, c.TokenType())
+}
+
+func TestNewFromSettings(t *testing.T) {
+	t.Setenv("DANGER_GITLAB_HOST", "")
+	t.Setenv("CI_SERVER_URL", "")
+	t.Setenv("DANGER_GITLAB_API_TOKEN", "")
+	t.Setenv("DANGER_GITLAB_API_OAUTH_TOKEN", "oauth")
+
+	d, err := dangerJs.DecodeDSL([]byte(`{"git":{},"settings":{"gitlab":{"accessToken":"t","baseURL":"gitlab.internal"}}}`))
+	require.Nil(t, err)
+	c, err := NewFromSettings(d.ToInterface().Settings)
+	require.Nil(t, err)
+	require.Equal(t, PersonalToken, c.TokenType())
+	require.Equal(t, "https://gitlab.internal/api/v4", c.APIURL())
+
+	// without a token in the DSL the environment is used
+	d, err = dangerJs.DecodeDSL([]byte(`{"git":{},"settings":{}}`))
+	require.Nil(t, err)
+	c, err = NewFromSettings(d.ToInterface().Settings)
+	require.Nil(t, err)
+	require.Equal(t, OAuthToken, c.TokenType())
+	require.Equal(t, "https://gitlab.com/api/v4", c.APIURL())
+}
+
+func TestVersion(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		require.Equal(t, "/api/v4/version", r.URL.Path)
+		_, _ = w.Write([]byte(`{"version":"14.0.1-ee","revision":"abc"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, Token{Value: "t"})
+	require.Nil(t, err)
+
+	v, err := c.Version(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, Version{Major: 14, Minor: 0, Revision: "abc"}, v)
+	require.True(t, v.AtLeast(14, 0))
+	require.True(t, v.AtLeast(13, 12))
+	require.False(t, v.AtLeast(14, 1))
+
+	_, err = c.Version(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, 1, calls)
+}
+
+func TestCreateMRNote(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/api/v4/projects/group%2Fproject/merge_requests/3/notes", r.URL.EscapedPath())
+		_, _ = w.Write([]byte(`{"id":10,"body":"hello"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, Token{Value: "t"})
+	require.Nil(t, err)
+
+	note, err := c.CreateMRNote(context.Background(), "group/project", 3, "hello")
+	require.Nil(t, err)
+	require.Equal(t, Note{ID: 10, Body: "hello"}, note)
+}
+
+func TestErrorResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message":"401 Unauthorized"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, Token{Value: "t"})
+	require.Nil(t, err)
+
+	_, err = c.Version(context.Background())
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	require.Equal(t, "401 Unauthorized", apiErr.Message)
+}
+
+func TestUnauthorized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message":"401 Unauthorized"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, Token{Value: "expired"})
+	require.Nil(t, err)
+
+	_, err = c.CreateMRNote(context.Background(), "group/project", 3, "hello")
+	require.ErrorIs(t, err, dangerJs.ErrAPIUnauthorized)
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, "401 Unauthorized", apiErr.Message)
+}
+
+func TestUpdateMRLabels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		require.Equal(t, "/api/v4/projects/group%2Fproject/merge_requests/3", r.URL.EscapedPath())
+		body, _ := io.ReadAll(r.Body)
+		require.JSONEq(t, `{"add_labels":"semver/minor","remove_labels":"semver/patch,semver/major"}`, string(body))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, Token{Value: "t"})
+	require.Nil(t, err)
+	require.Nil(t, c.UpdateMRLabels(context.Background(), "group/project", 3,
+		[]string{"semver/minor"}, []string{"semver/patch", "semver/major"}))
+}
+
+func TestUpdateMR(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		require.Equal(t, "/api/v4/projects/group%2Fproject/merge_requests/3", r.URL.EscapedPath())
+		body, _ := io.ReadAll(r.Body)
+		require.JSONEq(t, `{"title":"feat: add login"}`, string(body))
+		_, _ = w.Write([]byte(`{"iid":3,"title":"feat: add login"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, Token{Value: "t"})
+	require.Nil(t, err)
+	mr, err := c.UpdateMR(context.Background(), "group/project", 3, map[string]any{"title": "feat: add login"})
+	require.Nil(t, err)
+	require.Equal(t, "feat: add login", mr.Title)
+}
+
+func TestRebaseMR(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		require.Equal(t, "/api/v4/projects/group%2Fproject/merge_requests/3/rebase", r.URL.EscapedPath())
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"rebase_in_progress":true}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, Token{Value: "t"})
+	require.Nil(t, err)
+	require.Nil(t, c.RebaseMR(context.Background(), "group/project", 3))
+}
+
+func TestTokenScopes(t *testing.T) {
+	testCases := []struct {
+		name      string
+		version   int // status of the version endpoint
+		body      string
+		expScopes []string
+		expCalls  []string
+		expErr    error
+	}{
+		{
+			name:      "current",
+			version:   http.StatusOK,
+			body:      `{"version":"16.2.1-ee"}`,
+			expScopes: []string{"api", "read_repository"},
+			expCalls:  []string{"/api/v4/version", "/api/v4/personal_access_tokens/self"},
+		},
+		{
+			name:     "before 15.5",
+			version:  http.StatusOK,
+			body:     `{"version":"14.10.0"}`,
+			expCalls: []string{"/api/v4/version"},
+			expErr:   ErrUnsupported,
+		},
+		{
+			name:      "unknown version",
+			version:   http.StatusForbidden,
+			body:      `{"message":"403 Forbidden"}`,
+			expScopes: []string{"api", "read_repository"},
+			expCalls:  []string{"/api/v4/version", "/api/v4/personal_access_tokens/self"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, r.URL.Path)
+				if r.URL.Path == "/api/v4/version" {
+					w.WriteHeader(tc.version)
+					_, _ = w.Write([]byte(tc.body))
+					return
+				}
+				_, _ = w.Write([]byte(`{"id":1,"name":"danger","scopes":["api","read_repository"]}`))
+			}))
+			defer srv.Close()
+
+			c, err := New(srv.URL, Token{Value: "t"})
+			require.Nil(t, err)
+			scopes, err := c.TokenScopes(context.Background())
+			require.ErrorIs(t, err, tc.expErr)
+			require.Equal(t, tc.expScopes, scopes)
+			require.Equal(t, tc.expCalls, calls)
+		})
+	}
+}
+
+func TestCommitFiles(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/api/v4/projects/group%2Fproject/repository/commits", r.URL.EscapedPath())
+		body, _ := io.ReadAll(r.Body)
+		require.JSONEq(t, `{"branch":"feature","start_sha":"head","commit_message":"Apply fixes","actions":[
+			{"action":"update","file_path":"a.go","content":"package a\n","execute_filemode":false,"last_commit_id":"c1"},
+			{"action":"create","file_path":"run.sh","content":"#!/bin/sh\n","execute_filemode":true},
+			{"action":"delete","file_path":"old.go","last_commit_id":"c2"}]}`, string(body))
+		_, _ = w.Write([]byte(`{"id":"fixed"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, Token{Value: "t"})
+	require.Nil(t, err)
+	sha, err := c.CommitFiles(context.Background(), "group/project", "feature", "head", "Apply fixes", []CommitFile{
+		{Path: "a.go", Content: "package a\n", LastCommitID: "c1"},
+		{Path: "run.sh", Content: "#!/bin/sh\n", Executable: true, New: true},
+		{Path: "old.go", Deleted: true, LastCommitID: "c2"},
+	})
+	require.Nil(t, err)
+	require.Equal(t, "fixed", sha)
+}
+
+func TestListMRs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v4/projects/group%2Fproject/merge_requests", r.URL.EscapedPath())
+		require.Equal(t, "feature", r.URL.Query().Get("target_branch"))
+		_, _ = w.Write([]byte(`[{"iid":5,"title":"Child","source_branch":"child","target_branch":"feature"}]`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, Token{Value: "t"})
+	require.Nil(t, err)
+
+	mrs, err := c.ListMRs(context.Background(), "group/project", url.Values{"target_branch": {"feature"}})
+	require.Nil(t, err)
+	require.Len(t, mrs, 1)
+	require.Equal(t, int64(5), mrs[0].IID)
+	require.Equal(t, "child", mrs[0].SourceBranch)
+}
+
+func TestMR(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v4/projects/group%2Fproject/merge_requests/12", r.URL.EscapedPath())
+		_, _ = w.Write([]byte(`{"iid":12,"title":"API","state":"merged"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, Token{Value: "t"})
+	require.Nil(t, err)
+
+	mr, err := c.MR(context.Background(), "group/project", 12)
+	require.Nil(t, err)
+	require.Equal(t, "merged", mr.State)
+}
+
+func TestMRApprovals(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v4/projects/group%2Fproject/merge_requests/3/approvals", r.URL.EscapedPath())
+		_, _ = w.Write([]byte(`{"id":1,"iid":3,"approvals_required":2,"approvals_left":1}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, Token{Value: "t"})
+	require.Nil(t, err)
+
+	approvals, err := c.MRApprovals(context.Background(), "group/project", 3)
+	require.Nil(t, err)
+	require.Equal(t, 1, approvals.ApprovalsLeft)
+}
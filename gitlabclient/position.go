@@ -0,0 +1,229 @@
+package gitlabclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// DiffRefs are the commits a merge request diff is computed from, as in the
+// diff_refs of the merge request.
+type DiffRefs struct {
+	BaseSHA  string `json:"base_sha"`
+	StartSHA string `json:"start_sha"`
+	HeadSHA  string `json:"head_sha"`
+}
+
+// Position anchors a discussion to a line of a merge request diff. Added
+// lines have only a NewLine, removed lines only an OldLine, and unchanged
+// lines both, which must agree with each other.
+type Position struct {
+	BaseSHA      string `json:"base_sha"`
+	StartSHA     string `json:"start_sha"`
+	HeadSHA      string `json:"head_sha"`
+	PositionType string `json:"position_type"`
+	OldPath      string `json:"old_path"`
+	NewPath      string `json:"new_path"`
+	OldLine      int    `json:"old_line,omitempty"`
+	NewLine      int    `json:"new_line,omitempty"`
+}
+
+// LinePosition returns the position of line of the new version of a file,
+// from the diff of the file between refs.BaseSHA and refs.HeadSHA. oldPath
+// differs from newPath for renamed files.
+func LinePosition(refs DiffRefs, oldPath, newPath string, diff dangerJs.FileDiff, line int) Position {
+	p := newPosition(refs, oldPath, newPath)
+	p.NewLine = line
+	if !containsLine(diff.AddedLines, line) {
+		p.OldLine = oldLineOf(diff, line)
+	}
+	return p
+}
+
+// RemovedLinePosition returns the position of line of the old version of a
+// file, e.g. to comment on a removed line.
+func RemovedLinePosition(refs DiffRefs, oldPath, newPath string, diff dangerJs.FileDiff, line int) Position {
+	p := newPosition(refs, oldPath, newPath)
+	p.OldLine = line
+	if !containsLine(diff.RemovedLines, line) {
+		p.NewLine = newLineOf(diff, line)
+	}
+	return p
+}
+
+func newPosition(refs DiffRefs, oldPath, newPath string) Position {
+	if oldPath == "" {
+		oldPath = newPath
+	}
+	return Position{
+		BaseSHA:      refs.BaseSHA,
+		StartSHA:     refs.StartSHA,
+		HeadSHA:      refs.HeadSHA,
+		PositionType: "text",
+		OldPath:      oldPath,
+		NewPath:      newPath,
+	}
+}
+
+func containsLine(lines []dangerJs.DiffLine, line int) bool {
+	return slices.ContainsFunc(lines, func(l dangerJs.DiffLine) bool { return l.Line == line })
+}
+
+// oldLineOf returns the line of the old file an unchanged line of the new
+// file was at. Unchanged lines keep their order, so walking both files past
+// the added and removed lines pairs them up.
+func oldLineOf(diff dangerJs.FileDiff, newLine int) int {
+	if newLine < 1 {
+		return 0
+	}
+	oldLine, n := 1, 1
+	for {
+		switch {
+		case containsLine(diff.AddedLines, n):
+			n++
+		case containsLine(diff.RemovedLines, oldLine):
+			oldLine++
+		case n == newLine:
+			return oldLine
+		default:
+			n++
+			oldLine++
+		}
+	}
+}
+
+// newLineOf is the inverse of oldLineOf.
+func newLineOf(diff dangerJs.FileDiff, oldLine int) int {
+	if oldLine < 1 {
+		return 0
+	}
+	o, newLine := 1, 1
+	for {
+		switch {
+		case containsLine(diff.RemovedLines, o):
+			o++
+		case containsLine(diff.AddedLines, newLine):
+			newLine++
+		case o == oldLine:
+			return newLine
+		default:
+			o++
+			newLine++
+		}
+	}
+}
+
+// MRVersion is a version of the diff of a merge request, created by each
+// push to it.
+type MRVersion struct {
+	ID             int64  `json:"id"`
+	HeadCommitSHA  string `json:"head_commit_sha"`
+	BaseCommitSHA  string `json:"base_commit_sha"`
+	StartCommitSHA string `json:"start_commit_sha"`
+	State          string `json:"state"`
+	// Diffs are only returned by MRVersion, not MRVersions.
+	Diffs []MRDiff `json:"diffs,omitempty"`
+}
+
+// DiffRefs returns the commits of the version, for LinePosition.
+func (v MRVersion) DiffRefs() DiffRefs {
+	return DiffRefs{BaseSHA: v.BaseCommitSHA, StartSHA: v.StartCommitSHA, HeadSHA: v.HeadCommitSHA}
+}
+
+// MRDiff is the diff of a file in a merge request version.
+type MRDiff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	Diff        string `json:"diff"`
+	NewFile     bool   `json:"new_file"`
+	RenamedFile bool   `json:"renamed_file"`
+	DeletedFile bool   `json:"deleted_file"`
+}
+
+// MRVersions lists the diff versions of a merge request, latest first.
+func (c *Client) MRVersions(ctx context.Context, project string, mrIID int64) ([]MRVersion, error) {
+	path := fmt.Sprintf("%s/merge_requests/%d/versions", ProjectPath(project), mrIID)
+	return listPages[MRVersion](ctx, c, path, nil)
+}
+
+// MRVersion fetches a diff version of a merge request with its diffs.
+func (c *Client) MRVersion(ctx context.Context, project string, mrIID, versionID int64) (MRVersion, error) {
+	var version MRVersion
+	path := fmt.Sprintf("%s/merge_requests/%d/versions/%d", ProjectPath(project), mrIID, versionID)
+	if _, err := c.Do(ctx, http.MethodGet, path, nil, &version); err != nil {
+		return MRVersion{}, err
+	}
+	return version, nil
+}
+
+// ErrInvalidPosition is returned by ValidatePosition when GitLab would
+// reject the position or anchor it to the wrong line.
+var ErrInvalidPosition = errors.New("invalid diff position")
+
+// ValidatePosition checks p against the latest diff version of a merge
+// request: its commits must be those of the version, its file must be in the
+// diff, and its lines must be added, removed or unchanged as p claims.
+func (c *Client) ValidatePosition(ctx context.Context, project string, mrIID int64, p Position) error {
+	versions, err := c.MRVersions(ctx, project, mrIID)
+	if err != nil {
+		return fmt.Errorf("listing versions: %w", err)
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("%w: the merge request has no diff versions", ErrInvalidPosition)
+	}
+	latest, err := c.MRVersion(ctx, project, mrIID, versions[0].ID)
+	if err != nil {
+		return fmt.Errorf("fetching version %d: %w", versions[0].ID, err)
+	}
+	return checkPosition(latest, p)
+}
+
+func checkPosition(v MRVersion, p Position) error {
+	if refs := v.DiffRefs(); refs != (DiffRefs{BaseSHA: p.BaseSHA, StartSHA: p.StartSHA, HeadSHA: p.HeadSHA}) {
+		return fmt.Errorf("%w: %s is not the head of the latest version, %s", ErrInvalidPosition, p.HeadSHA, refs.HeadSHA)
+	}
+	i := slices.IndexFunc(v.Diffs, func(d MRDiff) bool { return d.NewPath == p.NewPath })
+	if i < 0 {
+		return fmt.Errorf("%w: %s is not changed by the merge request", ErrInvalidPosition, p.NewPath)
+	}
+	file := v.Diffs[i]
+	if file.OldPath != p.OldPath {
+		return fmt.Errorf("%w: %s was %s before, not %s", ErrInvalidPosition, p.NewPath, file.OldPath, p.OldPath)
+	}
+	diff := dangerJs.ParsePatch(file.Diff)
+	var want Position
+	switch {
+	case p.NewLine != 0:
+		want = LinePosition(v.DiffRefs(), p.OldPath, p.NewPath, diff, p.NewLine)
+	case p.OldLine != 0:
+		want = RemovedLinePosition(v.DiffRefs(), p.OldPath, p.NewPath, diff, p.OldLine)
+	default:
+		return fmt.Errorf("%w: neither old_line nor new_line is set", ErrInvalidPosition)
+	}
+	if want.OldLine != p.OldLine || want.NewLine != p.NewLine {
+		return fmt.Errorf("%w: %s has old_line %d and new_line %d, not %d and %d",
+			ErrInvalidPosition, p.NewPath, want.OldLine, want.NewLine, p.OldLine, p.NewLine)
+	}
+	return nil
+}
+
+// Discussion is a thread of notes on a merge request.
+type Discussion struct {
+	ID    string `json:"id"`
+	Notes []Note `json:"notes"`
+}
+
+// CreateMRDiscussion starts a discussion on the line of the merge request
+// diff at p, e.g. computed with LinePosition.
+func (c *Client) CreateMRDiscussion(ctx context.Context, project string, mrIID int64, body string, p Position) (Discussion, error) {
+	var discussion Discussion
+	path := fmt.Sprintf("%s/merge_requests/%d/discussions", ProjectPath(project), mrIID)
+	if _, err := c.Do(ctx, http.MethodPost, path, map[string]any{"body": body, "position": p}, &discussion); err != nil {
+		return Discussion{}, err
+	}
+	return discussion, nil
+}
@@ -0,0 +1,132 @@
+package gitlabclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// positionPatch changes main.go: line 3 is added, old line 10 is removed and
+// replaced by new lines 11 and 12.
+const positionPatch = `@@ -1,3 +1,4 @@
+ package main
+ 
++import "fmt"
+ func main() {
+@@ -9,3 +10,4 @@ func main() {
+ 	if true {
+-		println("old")
++		fmt.Println("new")
++		fmt.Println("extra")
+ 	}`
+
+var positionRefs = DiffRefs{BaseSHA: "base", StartSHA: "start", HeadSHA: "head"}
+
+func TestLinePosition(t *testing.T) {
+	diff := dangerJs.ParsePatch(positionPatch)
+	tests := []struct {
+		name    string
+		line    int
+		removed bool
+		oldLine int
+		newLine int
+	}{
+		{name: "added", line: 3, newLine: 3},
+		{name: "unchanged before changes", line: 2, oldLine: 2, newLine: 2},
+		{name: "unchanged after an addition", line: 4, oldLine: 3, newLine: 4},
+		{name: "unchanged after a replacement", line: 13, oldLine: 11, newLine: 13},
+		{name: "unchanged outside hunks", line: 20, oldLine: 18, newLine: 20},
+		{name: "removed", line: 10, removed: true, oldLine: 10},
+		{name: "unchanged old line", line: 9, removed: true, oldLine: 9, newLine: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := LinePosition(positionRefs, "", "main.go", diff, tt.line)
+			if tt.removed {
+				p = RemovedLinePosition(positionRefs, "", "main.go", diff, tt.line)
+			}
+			require.Equal(t, Position{
+				BaseSHA: "base", StartSHA: "start", HeadSHA: "head", PositionType: "text",
+				OldPath: "main.go", NewPath: "main.go", OldLine: tt.oldLine, NewLine: tt.newLine,
+			}, p)
+		})
+	}
+}
+
+func TestValidatePosition(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/group%2Fproject/merge_requests/3/versions":
+			_, _ = w.Write([]byte(`[{"id":2,"head_commit_sha":"head","base_commit_sha":"base","start_commit_sha":"start"},{"id":1}]`))
+		case "/api/v4/projects/group%2Fproject/merge_requests/3/versions/2":
+			version := MRVersion{ID: 2, HeadCommitSHA: "head", BaseCommitSHA: "base", StartCommitSHA: "start",
+				Diffs: []MRDiff{{OldPath: "old.go", NewPath: "main.go", Diff: positionPatch, RenamedFile: true}}}
+			require.Nil(t, json.NewEncoder(w).Encode(version))
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, Token{Value: "t"})
+	require.Nil(t, err)
+
+	diff := dangerJs.ParsePatch(positionPatch)
+	stale := positionRefs
+	stale.HeadSHA = "previous"
+	tests := []struct {
+		name string
+		p    Position
+		err  bool
+	}{
+		{name: "added line", p: LinePosition(positionRefs, "old.go", "main.go", diff, 11)},
+		{name: "unchanged line", p: LinePosition(positionRefs, "old.go", "main.go", diff, 13)},
+		{name: "removed line", p: RemovedLinePosition(positionRefs, "old.go", "main.go", diff, 10)},
+		{name: "stale head", p: LinePosition(stale, "old.go", "main.go", diff, 11), err: true},
+		{name: "rename ignored", p: LinePosition(positionRefs, "", "main.go", diff, 11), err: true},
+		{name: "file not in diff", p: LinePosition(positionRefs, "", "other.go", diff, 1), err: true},
+		{name: "unchanged line as added", p: Position{BaseSHA: "base", StartSHA: "start", HeadSHA: "head", OldPath: "old.go", NewPath: "main.go", NewLine: 13}, err: true},
+		{name: "no line", p: Position{BaseSHA: "base", StartSHA: "start", HeadSHA: "head", OldPath: "old.go", NewPath: "main.go"}, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.ValidatePosition(context.Background(), "group/project", 3, tt.p)
+			if tt.err {
+				require.True(t, errors.Is(err, ErrInvalidPosition), err)
+				return
+			}
+			require.Nil(t, err)
+		})
+	}
+}
+
+func TestCreateMRDiscussion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/api/v4/projects/group%2Fproject/merge_requests/3/discussions", r.URL.EscapedPath())
+		var body struct {
+			Body     string   `json:"body"`
+			Position Position `json:"position"`
+		}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, "Use fmt", body.Body)
+		require.Equal(t, 11, body.Position.NewLine)
+		_, _ = w.Write([]byte(`{"id":"abc","notes":[{"id":7,"body":"Use fmt"}]}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, Token{Value: "t"})
+	require.Nil(t, err)
+
+	p := LinePosition(positionRefs, "", "main.go", dangerJs.ParsePatch(positionPatch), 11)
+	discussion, err := c.CreateMRDiscussion(context.Background(), "group/project", 3, "Use fmt", p)
+	require.Nil(t, err)
+	require.Equal(t, Discussion{ID: "abc", Notes: []Note{{ID: 7, Body: "Use fmt"}}}, discussion)
+}
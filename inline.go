@@ -0,0 +1,211 @@
+package danger
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DefaultInlineBudget is a cap on inline comments which keeps clear of the
+// throttling code hosts apply to runs creating many review comments.
+const DefaultInlineBudget = 25
+
+// InlineBudget caps the violations posted as inline comments, i.e. those
+// with a file and line.
+type InlineBudget struct {
+	// Max is the number of inline comments kept, unlimited when zero.
+	Max int
+	// Weights ranks the rules within a severity, higher first. Rules
+	// missing from it weigh zero.
+	Weights map[string]int
+}
+
+// WithInlineBudget caps the inline comments, see SetInlineBudget.
+func WithInlineBudget(b InlineBudget) Option {
+	return func(t *T) {
+		t.inlineBudget = b
+	}
+}
+
+// SetInlineBudget caps the inline comments of the Danger comment, see
+// ApplyInlineBudget. FitComment applies it.
+func (s *T) SetInlineBudget(b InlineBudget) {
+	s.inlineBudget = b
+}
+
+// ApplyInlineBudget keeps at most b.Max violations inline, picking fails
+// over warnings over messages over markdowns, then by rule weight, then in
+// the order they were added. Fails over the budget move to the main comment
+// with their location in the message, so they still fail the build; the
+// other violations over the budget are listed in a collapsed markdown.
+func ApplyInlineBudget(r Results, b InlineBudget) Results {
+	if b.Max <= 0 {
+		return r
+	}
+	type inline struct {
+		kind  int
+		index int
+		v     Violation
+	}
+	groups := []*[]Violation{&r.Fails, &r.Warnings, &r.Messages, &r.Markdowns}
+	var all []inline
+	for kind, g := range groups {
+		for i, v := range *g {
+			if v.File != "" && v.Line > 0 {
+				all = append(all, inline{kind, i, v})
+			}
+		}
+	}
+	if len(all) <= b.Max {
+		return r
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].kind != all[j].kind {
+			return all[i].kind < all[j].kind
+		}
+		return b.Weights[all[i].v.Rule] > b.Weights[all[j].v.Rule]
+	})
+
+	// dropped holds the indexes of the violations over budget, by kind
+	dropped := make([]map[int]bool, len(groups))
+	for kind := range dropped {
+		dropped[kind] = map[int]bool{}
+	}
+	var moved, summarized []Violation
+	for _, in := range all[b.Max:] {
+		dropped[in.kind][in.index] = true
+		if in.kind == 0 {
+			moved = append(moved, in.v)
+		} else {
+			summarized = append(summarized, in.v)
+		}
+	}
+
+	out := r
+	outGroups := []*[]Violation{&out.Fails, &out.Warnings, &out.Messages, &out.Markdowns}
+	for kind, g := range groups {
+		kept := []Violation{}
+		for i, v := range *g {
+			switch {
+			case !dropped[kind][i]:
+				kept = append(kept, v)
+			case kind == 0:
+				v.Message = fmt.Sprintf("%s: %s", location(v), v.Message)
+				v.File, v.Line = "", 0
+				kept = append(kept, v)
+			}
+		}
+		*outGroups[kind] = kept
+	}
+	if len(summarized) > 0 {
+		out.Markdowns = append(out.Markdowns, Violation{Message: inlineOverflow(summarized, len(moved))})
+	}
+	return out
+}
+
+// InlineThreads groups the inline comments a rule repeats in a file, see
+// GroupInlineComments.
+type InlineThreads struct {
+	// Min is the number of comments of a rule in a file from which they are
+	// grouped. Comments are not grouped when it is below 2.
+	Min int
+}
+
+// WithInlineThreads groups repeated inline comments, see SetInlineThreads.
+func WithInlineThreads(t InlineThreads) Option {
+	return func(s *T) {
+		s.inlineThreads = t
+	}
+}
+
+// SetInlineThreads groups the inline comments a rule repeats in a file, see
+// GroupInlineComments. FitComment applies it before the inline budget.
+func (s *T) SetInlineThreads(t InlineThreads) {
+	s.inlineThreads = t
+}
+
+// GroupInlineComments replaces the inline comments of a rule in a file, when
+// there are at least t.Min of them of the same severity, with a single one
+// on the first of their lines. It shows the first message, followed by a
+// collapsed list of every occurrence.
+func GroupInlineComments(r Results, t InlineThreads) Results {
+	if t.Min < 2 {
+		return r
+	}
+	type key struct{ rule, file string }
+	out := r
+	for _, g := range []*[]Violation{&out.Fails, &out.Warnings, &out.Messages, &out.Markdowns} {
+		threads := map[key][]int{}
+		for i, v := range *g {
+			if v.Rule != "" && v.File != "" && v.Line > 0 {
+				k := key{v.Rule, v.File}
+				threads[k] = append(threads[k], i)
+			}
+		}
+		kept := []Violation{}
+		for i, v := range *g {
+			thread := threads[key{v.Rule, v.File}]
+			switch {
+			case v.Rule == "" || v.File == "" || v.Line <= 0 || len(thread) < t.Min:
+				kept = append(kept, v)
+			case thread[0] == i:
+				vs := make([]Violation, len(thread))
+				for j, index := range thread {
+					vs[j] = (*g)[index]
+				}
+				kept = append(kept, threadComment(vs))
+			}
+		}
+		*g = kept
+	}
+	return out
+}
+
+func threadComment(thread []Violation) Violation {
+	slices.SortStableFunc(thread, func(a, b Violation) int { return a.Line - b.Line })
+	v := thread[0]
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s\n\n<details>\n<summary>`%s` found %d times in this file</summary>\n\n", v.Message, v.Rule, len(thread))
+	for _, o := range thread {
+		msg, _, _ := strings.Cut(strings.TrimSpace(o.Message), "\n")
+		fmt.Fprintf(&sb, "- Line %d: %s\n", o.Line, msg)
+	}
+	sb.WriteString("</details>")
+	v.Message = sb.String()
+	return v
+}
+
+// threadLineRe matches the line numbers of the occurrences listed by a
+// grouped inline comment.
+var threadLineRe = regexp.MustCompile(`(?m)^- Line \d+: `)
+
+// threadKeyMessage is the message of v without the line numbers of the
+// occurrences it lists when it is a grouped inline comment, so that the
+// thread keeps its key when unrelated edits move them.
+func threadKeyMessage(v Violation) string {
+	return threadLineRe.ReplaceAllString(v.Message, "- ")
+}
+
+func location(v Violation) string {
+	return fmt.Sprintf("`%s:%d`", v.File, v.Line)
+}
+
+func inlineOverflow(vs []Violation, moved int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<details>\n<summary>%d more inline comments were left out", len(vs))
+	switch {
+	case moved == 1:
+		sb.WriteString(", 1 failure is listed above")
+	case moved > 1:
+		fmt.Fprintf(&sb, ", %d failures are listed above", moved)
+	}
+	sb.WriteString("</summary>\n\n")
+	for _, v := range vs {
+		msg, _, _ := strings.Cut(strings.TrimSpace(v.Message), "\n")
+		fmt.Fprintf(&sb, "- %s: %s\n", location(v), msg)
+	}
+	sb.WriteString("</details>")
+	return sb.String()
+}
@@ -0,0 +1,88 @@
+package danger_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+)
+
+func TestApplyInlineBudget(t *testing.T) {
+	r := danger.Results{
+		Fails: []danger.Violation{
+			{Message: "vet", File: "a.go", Line: 1, Rule: "vet"},
+			{Message: "no changelog"},
+			{Message: "license", File: "b.go", Line: 1, Rule: "license"},
+		},
+		Warnings: []danger.Violation{
+			{Message: "todo", File: "a.go", Line: 2, Rule: "todo"},
+			{Message: "naming\ndetails", File: "c.go", Line: 3, Rule: "naming"},
+		},
+		Messages:  []danger.Violation{{Message: "nice", File: "d.go", Line: 4}},
+		Markdowns: []danger.Violation{},
+	}
+	got := danger.ApplyInlineBudget(r, danger.InlineBudget{Max: 1, Weights: map[string]int{"license": 2, "naming": 1}})
+
+	require.Equal(t, []danger.Violation{
+		{Message: "`a.go:1`: vet", Rule: "vet"},
+		{Message: "no changelog"},
+		{Message: "license", File: "b.go", Line: 1, Rule: "license"},
+	}, got.Fails, "fails over budget keep failing from the main comment")
+	require.Empty(t, got.Warnings)
+	require.Empty(t, got.Messages)
+	require.Equal(t, []danger.Violation{{Message: "<details>\n<summary>3 more inline comments were left out, 1 failure is listed above</summary>\n\n" +
+		"- `c.go:3`: naming\n- `a.go:2`: todo\n- `d.go:4`: nice\n</details>"}}, got.Markdowns)
+
+	require.Equal(t, r, danger.ApplyInlineBudget(r, danger.InlineBudget{}), "unlimited")
+	require.Equal(t, r, danger.ApplyInlineBudget(r, danger.InlineBudget{Max: 5}), "within budget")
+}
+
+func TestFitCommentAppliesInlineBudget(t *testing.T) {
+	d := danger.New(danger.WithInlineBudget(danger.InlineBudget{Max: 1}))
+	d.Warn("first", "a.go", 1)
+	d.Warn("second", "a.go", 2)
+	got, _, _ := d.FitComment(danger.DSL{})
+	require.Len(t, got.Warnings, 1)
+	require.Len(t, got.Markdowns, 1)
+}
+
+func TestGroupInlineComments(t *testing.T) {
+	r := danger.Results{
+		Fails: []danger.Violation{{Message: "vet", File: "a.go", Line: 1, Rule: "vet"}},
+		Warnings: []danger.Violation{
+			{Message: "TODO left\nin code", File: "a.go", Line: 9, Rule: "todo"},
+			{Message: "no changelog", Rule: "changelog"},
+			{Message: "TODO left", File: "a.go", Line: 3, Rule: "todo"},
+			{Message: "TODO left", File: "b.go", Line: 2, Rule: "todo"},
+			{Message: "TODO left", File: "a.go", Line: 5, Rule: "todo"},
+			{Message: "debug print", File: "a.go", Line: 4},
+			{Message: "debug print", File: "a.go", Line: 6},
+		},
+	}
+	got := danger.GroupInlineComments(r, danger.InlineThreads{Min: 2})
+
+	require.Equal(t, r.Fails, got.Fails)
+	require.Equal(t, []danger.Violation{
+		{Message: "TODO left\n\n<details>\n<summary>`todo` found 3 times in this file</summary>\n\n" +
+			"- Line 3: TODO left\n- Line 5: TODO left\n- Line 9: TODO left\n</details>", File: "a.go", Line: 3, Rule: "todo"},
+		{Message: "no changelog", Rule: "changelog"},
+		{Message: "TODO left", File: "b.go", Line: 2, Rule: "todo"},
+		{Message: "debug print", File: "a.go", Line: 4},
+		{Message: "debug print", File: "a.go", Line: 6},
+	}, got.Warnings, "comments without a rule are not grouped")
+
+	require.Equal(t, r, danger.GroupInlineComments(r, danger.InlineThreads{}), "disabled")
+	require.Len(t, danger.GroupInlineComments(r, danger.InlineThreads{Min: 4}).Warnings, len(r.Warnings), "below the minimum")
+}
+
+func TestFitCommentGroupsInlineComments(t *testing.T) {
+	d := danger.New(danger.WithInlineThreads(danger.InlineThreads{Min: 2}), danger.WithInlineBudget(danger.InlineBudget{Max: 1}))
+	d.RunRules(danger.DSL{}, danger.Rule{Name: "todo", Run: func(d *danger.T, _ danger.DSL) {
+		d.Warn("first", "a.go", 1)
+		d.Warn("second", "a.go", 2)
+	}})
+	got, _, _ := d.FitComment(danger.DSL{})
+	require.Len(t, got.Warnings, 1)
+	require.Empty(t, got.Markdowns, "the thread fits in the inline budget")
+}
@@ -0,0 +1,83 @@
+// Package otlp holds the OTLP over HTTP code shared by the metrics and
+// tracing exporters.
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Attribute is an OTLP key value pair.
+type Attribute struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+// String is a string attribute.
+func String(k, v string) Attribute {
+	return Attribute{Key: k, Value: map[string]any{"stringValue": v}}
+}
+
+// Attributes returns the string attributes of attrs, sorted by key.
+func Attributes(attrs map[string]string) []Attribute {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]Attribute, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, String(k, attrs[k]))
+	}
+	return out
+}
+
+// Post sends payload, JSON encoded, to endpoint with the headers. what names
+// the payload in errors, e.g. "spans".
+func Post(ctx context.Context, client *http.Client, endpoint string, headers map[string]string, what string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshalling OTLP %s: %w", what, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating OTLP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("exporting OTLP %s: %w", what, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("exporting OTLP %s: %s: %s", what, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// ParseHeaders parses the OTEL_EXPORTER_OTLP_HEADERS format of comma
+// separated key=value pairs.
+func ParseHeaders(s string) map[string]string {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return headers
+}
@@ -0,0 +1,154 @@
+package danger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"strings"
+	"sync"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Messages maps message keys to text/template templates, e.g.
+// "rules.hygiene.trailing_whitespace" to "Trailing whitespace, see
+// https://wiki.example.com/style". Templates refer to the data of the
+// message by name, e.g. {{.file}}.
+type Messages map[string]string
+
+// Keys of the messages framing the Danger comment. Both are empty by
+// default; when set, they are added as the first and the last markdown.
+const (
+	MsgCommentHeader = "comment.header"
+	MsgCommentFooter = "comment.footer"
+)
+
+var (
+	defaultsMu      sync.RWMutex
+	defaultMessages = Messages{
+		MsgCommentHeader:       "",
+		MsgCommentFooter:       "",
+		"danger.skipped_rules": "Skipped rules ({{.count}})",
+		"danger.waived":        "{{.message}} (waived by @{{.by}}{{if .reason}}: {{.reason}}{{end}})",
+		"danger.checklist":     "#### Checklist",
+	}
+)
+
+// DefineMessages registers the default templates of messages, e.g. from the
+// init function of a package of rules. Existing defaults are replaced.
+func DefineMessages(m Messages) {
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+	maps.Copy(defaultMessages, m)
+}
+
+// DefaultMessages returns a copy of the default templates, e.g. to document
+// the keys which can be overridden.
+func DefaultMessages() Messages {
+	defaultsMu.RLock()
+	defer defaultsMu.RUnlock()
+	return maps.Clone(defaultMessages)
+}
+
+// WithMessages overrides the default templates of messages, see
+// SetMessages.
+func WithMessages(m Messages) Option {
+	return func(t *T) {
+		t.SetMessages(m)
+	}
+}
+
+// SetMessages overrides the default templates of messages with those of m,
+// e.g. loaded with LoadMessages. Keys missing from m keep their default.
+func (s *T) SetMessages(m Messages) {
+	s.messages = maps.Clone(m)
+}
+
+// Msg renders the message key with data. The template set with SetMessages
+// is used when there is one, the default otherwise; an override which fails
+// to render is logged and the default is used instead. Unknown keys render
+// as the key itself.
+func (s *T) Msg(key string, data map[string]any) string {
+	if tmpl, ok := s.messages[key]; ok {
+		msg, err := renderMessage(key, tmpl, data)
+		if err == nil {
+			return msg
+		}
+		s.logger.Printf("rendering message %s: %s", key, err.Error())
+	}
+	defaultsMu.RLock()
+	tmpl, ok := defaultMessages[key]
+	defaultsMu.RUnlock()
+	if !ok {
+		return key
+	}
+	msg, err := renderMessage(key, tmpl, data)
+	if err != nil {
+		s.logger.Printf("rendering message %s: %s", key, err.Error())
+		return key
+	}
+	return msg
+}
+
+func renderMessage(key, tmpl string, data map[string]any) (string, error) {
+	if !strings.Contains(tmpl, "{{") {
+		return tmpl, nil
+	}
+	t, err := template.New(key).Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// AddCommentFrame adds the comment.header message as the first markdown and
+// the comment.footer message as the last one, when they aren't empty. The
+// runner calls it once the dangerfile has run.
+func (s *T) AddCommentFrame() {
+	if header := s.Msg(MsgCommentHeader, nil); strings.TrimSpace(header) != "" {
+		s.results.Markdowns = append([]Violation{{Message: header}}, s.results.Markdowns...)
+		s.report(KindMarkdown, Violation{Message: header})
+	}
+	if footer := s.Msg(MsgCommentFooter, nil); strings.TrimSpace(footer) != "" {
+		s.Markdown(footer, "", 0)
+	}
+}
+
+// LoadMessages reads a messages file, a YAML mapping of message keys to
+// templates. A missing file has no messages.
+func LoadMessages(path string) (Messages, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Messages{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening messages: %w", err)
+	}
+	defer f.Close()
+	m, err := ParseMessages(f)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// ParseMessages parses a flat YAML mapping of message keys to templates.
+// Multi-line templates are literal (|) or folded (>) block scalars.
+func ParseMessages(r io.Reader) (Messages, error) {
+	var m Messages
+	if err := yaml.NewDecoder(r).Decode(&m); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if m == nil {
+		m = Messages{}
+	}
+	return m, nil
+}
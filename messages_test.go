@@ -0,0 +1,121 @@
+package danger_test
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+)
+
+func TestParseMessages(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want danger.Messages
+	}{
+		{
+			name: "plain and quoted",
+			in: `# tone of the rules
+rules.hygiene.crlf: Use LF line endings  # see the style guide
+rules.hygiene.bom: "No BOM, see \"style\""
+rules.hygiene.final_newline: 'End files with a newline: it''s required'
+`,
+			want: danger.Messages{
+				"rules.hygiene.crlf":          "Use LF line endings",
+				"rules.hygiene.bom":           `No BOM, see "style"`,
+				"rules.hygiene.final_newline": "End files with a newline: it's required",
+			},
+		},
+		{
+			name: "literal block",
+			in: `comment.footer: |
+  Questions? Ask in #dev-help.
+
+  [Review guide](https://wiki.example.com/review)
+comment.header: ""
+`,
+			want: danger.Messages{
+				"comment.footer": "Questions? Ask in #dev-help.\n\n[Review guide](https://wiki.example.com/review)\n",
+				"comment.header": "",
+			},
+		},
+		{
+			name: "folded block",
+			in: `rules.merge.behind: >-
+  This branch is behind
+  {{.base}}.
+
+  Please rebase.
+`,
+			want: danger.Messages{"rules.merge.behind": "This branch is behind {{.base}}.\nPlease rebase."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := danger.ParseMessages(strings.NewReader(tt.in))
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseMessagesInvalid(t *testing.T) {
+	for _, in := range []string{"no separator\n", "key:\n  nested: value\n", "key: a\nkey: b\n", `key: "unterminated\"` + "\n"} {
+		_, err := danger.ParseMessages(strings.NewReader(in))
+		require.NotNil(t, err, in)
+	}
+}
+
+func TestLoadMessages(t *testing.T) {
+	dir := t.TempDir()
+	m, err := danger.LoadMessages(filepath.Join(dir, "missing.yaml"))
+	require.Nil(t, err)
+	require.Empty(t, m)
+
+	path := filepath.Join(dir, "messages.yaml")
+	require.Nil(t, os.WriteFile(path, []byte("comment.footer: Thanks!\n"), 0o644))
+	m, err = danger.LoadMessages(path)
+	require.Nil(t, err)
+	require.Equal(t, danger.Messages{"comment.footer": "Thanks!"}, m)
+}
+
+func TestMsg(t *testing.T) {
+	danger.DefineMessages(danger.Messages{"test.greeting": "Hello {{.name}}"})
+
+	var logs bytes.Buffer
+	d := danger.New(danger.WithLogger(log.New(&logs, "", 0)))
+	require.Equal(t, "Hello Ada", d.Msg("test.greeting", map[string]any{"name": "Ada"}))
+	require.Equal(t, "test.unknown", d.Msg("test.unknown", nil))
+
+	d.SetMessages(danger.Messages{"test.greeting": "Hi {{.name}}, see the [guide](https://wiki.example.com)"})
+	require.Equal(t, "Hi Ada, see the [guide](https://wiki.example.com)", d.Msg("test.greeting", map[string]any{"name": "Ada"}))
+
+	// an override referring to missing data falls back to the default
+	d.SetMessages(danger.Messages{"test.greeting": "Hi {{.nickname}}"})
+	require.Equal(t, "Hello Ada", d.Msg("test.greeting", map[string]any{"name": "Ada"}))
+	require.Contains(t, logs.String(), "rendering message test.greeting")
+}
+
+func TestAddCommentFrame(t *testing.T) {
+	d := danger.New(danger.WithMessages(danger.Messages{
+		danger.MsgCommentHeader: "Checked by the platform team.",
+		danger.MsgCommentFooter: "Questions? Ask in #dev-help.",
+	}))
+	d.Markdown("table", "", 0)
+	d.AddCommentFrame()
+	require.Equal(t, []danger.Violation{
+		{Message: "Checked by the platform team."},
+		{Message: "table"},
+		{Message: "Questions? Ask in #dev-help."},
+	}, d.Snapshot().Markdowns)
+
+	d = danger.New()
+	d.AddCommentFrame()
+	require.Empty(t, d.Snapshot().Markdowns)
+}
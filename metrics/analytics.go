@@ -0,0 +1,243 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	danger "github.com/danger/golang"
+)
+
+// Outcome is the analytics record of a run: what fired and whether the PR
+// was blocked, for org-wide reporting on which policies fire most.
+type Outcome struct {
+	Time   time.Time `json:"time"`
+	Repo   string    `json:"repo"`
+	PR     string    `json:"pr"`
+	Result string    `json:"result"` // "failed" when the run has fails, "passed" otherwise
+	// PRState is the state of the PR when the run ended, "open", "closed"
+	// or "merged", to relate the policies which fired to the merge result.
+	PRState  string `json:"pr_state"`
+	Fails    int    `json:"fails"`
+	Warnings int    `json:"warnings"`
+	Messages int    `json:"messages"`
+	// DurationSeconds is the time taken by the dangerfile.
+	DurationSeconds float64    `json:"duration_seconds"`
+	Rules           []RuleHits `json:"rules"`
+}
+
+// RuleHits counts the violations of one kind a rule reported. Violations
+// added outside of a rule are counted under "none".
+type RuleHits struct {
+	Rule  string `json:"rule"`
+	Kind  string `json:"kind"`
+	Count int    `json:"count"`
+}
+
+// Outcome summarizes the run for analytics.
+func (r Run) Outcome() Outcome {
+	o := Outcome{
+		Time:            r.Time.UTC(),
+		Repo:            r.Repo,
+		PR:              r.PR,
+		Result:          "passed",
+		PRState:         r.State,
+		Fails:           len(r.Results.Fails),
+		Warnings:        len(r.Results.Warnings),
+		Messages:        len(r.Results.Messages),
+		DurationSeconds: r.Duration.Seconds(),
+		Rules:           []RuleHits{},
+	}
+	if o.Fails > 0 {
+		o.Result = "failed"
+	}
+	counts := map[RuleHits]int{}
+	for kind, vv := range map[string][]danger.Violation{
+		"fail":    r.Results.Fails,
+		"warning": r.Results.Warnings,
+		"message": r.Results.Messages,
+	} {
+		for _, v := range vv {
+			rule := v.Rule
+			if rule == "" {
+				rule = "none"
+			}
+			counts[RuleHits{Rule: rule, Kind: kind}]++
+		}
+	}
+	for k, n := range counts {
+		k.Count = n
+		o.Rules = append(o.Rules, k)
+	}
+	sort.Slice(o.Rules, func(i, j int) bool {
+		if o.Rules[i].Rule != o.Rules[j].Rule {
+			return o.Rules[i].Rule < o.Rules[j].Rule
+		}
+		return o.Rules[i].Kind < o.Rules[j].Kind
+	})
+	return o
+}
+
+// Store persists outcomes.
+type Store interface {
+	Append(ctx context.Context, o Outcome) error
+}
+
+// Analytics is an Exporter appending the outcome of each run to a Store.
+type Analytics struct {
+	Store Store
+}
+
+// Export appends the outcome of the run.
+func (a Analytics) Export(ctx context.Context, r Run) error {
+	if err := a.Store.Append(ctx, r.Outcome()); err != nil {
+		return fmt.Errorf("storing analytics: %w", err)
+	}
+	return nil
+}
+
+// JSONLStore appends outcomes to a newline-delimited JSON file, which
+// BigQuery loads with `bq load --source_format=NEWLINE_DELIMITED_JSON`.
+type JSONLStore struct {
+	Path string
+}
+
+// Append writes the outcome as one line.
+func (s JSONLStore) Append(_ context.Context, o Outcome) error {
+	line, err := json.Marshal(o)
+	if err != nil {
+		return fmt.Errorf("encoding outcome: %w", err)
+	}
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", s.Path, err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing %s: %w", s.Path, err)
+	}
+	return f.Close()
+}
+
+// Dialect is the SQL flavour of a SQLStore.
+type Dialect int
+
+const (
+	// SQLite uses ? placeholders.
+	SQLite Dialect = iota
+	// Postgres uses $1 style placeholders.
+	Postgres
+)
+
+// SQLStore appends outcomes to the danger_runs and danger_rule_hits tables
+// of a database opened with a driver registered by the caller, e.g.
+// modernc.org/sqlite or github.com/jackc/pgx/v5/stdlib.
+type SQLStore struct {
+	DB      *sql.DB
+	Dialect Dialect
+}
+
+// Migrate creates the tables when they don't exist.
+func (s SQLStore) Migrate(ctx context.Context) error {
+	for _, stmt := range []string{
+		`CREATE TABLE IF NOT EXISTS danger_runs (
+	run_at TIMESTAMP NOT NULL,
+	repo TEXT NOT NULL,
+	pr TEXT NOT NULL,
+	result TEXT NOT NULL,
+	pr_state TEXT NOT NULL,
+	fails INTEGER NOT NULL,
+	warnings INTEGER NOT NULL,
+	messages INTEGER NOT NULL,
+	duration_seconds REAL NOT NULL
+)`,
+		`CREATE TABLE IF NOT EXISTS danger_rule_hits (
+	run_at TIMESTAMP NOT NULL,
+	repo TEXT NOT NULL,
+	pr TEXT NOT NULL,
+	rule TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	hits INTEGER NOT NULL
+)`,
+	} {
+		if _, err := s.DB.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("creating analytics tables: %w", err)
+		}
+	}
+	return nil
+}
+
+// Append inserts the outcome in a transaction.
+func (s SQLStore) Append(ctx context.Context, o Outcome) (err error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tx.Rollback())
+		}
+	}()
+
+	if _, err := tx.ExecContext(ctx, s.insert("danger_runs", "run_at", "repo", "pr", "result", "pr_state", "fails", "warnings", "messages", "duration_seconds"),
+		o.Time, o.Repo, o.PR, o.Result, o.PRState, o.Fails, o.Warnings, o.Messages, o.DurationSeconds); err != nil {
+		return fmt.Errorf("inserting run: %w", err)
+	}
+	for _, h := range o.Rules {
+		if _, err := tx.ExecContext(ctx, s.insert("danger_rule_hits", "run_at", "repo", "pr", "rule", "kind", "hits"),
+			o.Time, o.Repo, o.PR, h.Rule, h.Kind, h.Count); err != nil {
+			return fmt.Errorf("inserting rule hits: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing analytics: %w", err)
+	}
+	return nil
+}
+
+// dsnStore appends outcomes to the database of DSN, opened for each outcome
+// so the driver only needs to be registered once the dangerfile is loaded.
+// The tables are created when missing.
+type dsnStore struct {
+	Driver, DSN string
+}
+
+func (s dsnStore) Append(ctx context.Context, o Outcome) error {
+	db, err := sql.Open(s.Driver, s.DSN)
+	if err != nil {
+		return fmt.Errorf("opening analytics database: %w", err)
+	}
+	defer db.Close()
+	store := SQLStore{DB: db, Dialect: SQLite}
+	switch s.Driver {
+	case "postgres", "pgx", "pq":
+		store.Dialect = Postgres
+	}
+	if err := store.Migrate(ctx); err != nil {
+		return err
+	}
+	return store.Append(ctx, o)
+}
+
+func (s SQLStore) insert(table string, columns ...string) string {
+	q := "INSERT INTO " + table + " ("
+	values := ""
+	for i, c := range columns {
+		if i > 0 {
+			q += ", "
+			values += ", "
+		}
+		q += c
+		if s.Dialect == Postgres {
+			values += fmt.Sprintf("$%d", i+1)
+		} else {
+			values += "?"
+		}
+	}
+	return q + ") VALUES (" + values + ")"
+}
@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutcome(t *testing.T) {
+	require.Equal(t, Outcome{
+		Time:            time.Unix(1700000000, 0).UTC(),
+		Repo:            "danger/golang",
+		PR:              "42",
+		Result:          "failed",
+		PRState:         "merged",
+		Fails:           2,
+		Warnings:        1,
+		DurationSeconds: 1.5,
+		Rules: []RuleHits{
+			{Rule: "changelog", Kind: "fail", Count: 2},
+			{Rule: "none", Kind: "warning", Count: 1},
+		},
+	}, testRun().Outcome())
+}
+
+func TestJSONLStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runs.jsonl")
+	a := Analytics{Store: JSONLStore{Path: path}}
+	require.Nil(t, a.Export(context.Background(), testRun()))
+	require.Nil(t, a.Export(context.Background(), Run{Repo: "other"}))
+
+	data, err := os.ReadFile(path)
+	require.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	var o Outcome
+	require.Nil(t, json.Unmarshal([]byte(lines[1]), &o))
+	require.Equal(t, "other", o.Repo)
+	require.Equal(t, "passed", o.Result)
+}
+
+// recordingDriver is a database/sql driver recording the executed
+// statements.
+type recordingDriver struct {
+	mu    sync.Mutex
+	execs []string
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) { return recordingConn{d}, nil }
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return recordingStmt{c.d, query}, nil
+}
+func (c recordingConn) Close() error              { return nil }
+func (c recordingConn) Begin() (driver.Tx, error) { return recordingTx{c.d}, nil }
+
+type recordingTx struct{ d *recordingDriver }
+
+func (tx recordingTx) Commit() error   { tx.d.record("COMMIT"); return nil }
+func (tx recordingTx) Rollback() error { tx.d.record("ROLLBACK"); return nil }
+
+type recordingStmt struct {
+	d     *recordingDriver
+	query string
+}
+
+func (s recordingStmt) Close() error  { return nil }
+func (s recordingStmt) NumInput() int { return -1 }
+func (s recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.record(s.query)
+	return driver.RowsAffected(1), nil
+}
+func (s recordingStmt) Query([]driver.Value) (driver.Rows, error) { return nil, driver.ErrSkip }
+
+func (d *recordingDriver) record(s string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.execs = append(d.execs, s)
+}
+
+func TestSQLStore(t *testing.T) {
+	d := &recordingDriver{}
+	sql.Register("recording", d)
+	db, err := sql.Open("recording", "")
+	require.Nil(t, err)
+	defer db.Close()
+
+	s := SQLStore{DB: db, Dialect: Postgres}
+	require.Nil(t, Analytics{Store: s}.Export(context.Background(), testRun()))
+	require.Equal(t, []string{
+		"INSERT INTO danger_runs (run_at, repo, pr, result, pr_state, fails, warnings, messages, duration_seconds) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
+		"INSERT INTO danger_rule_hits (run_at, repo, pr, rule, kind, hits) VALUES ($1, $2, $3, $4, $5, $6)",
+		"INSERT INTO danger_rule_hits (run_at, repo, pr, rule, kind, hits) VALUES ($1, $2, $3, $4, $5, $6)",
+		"COMMIT",
+	}, d.execs)
+
+	require.Equal(t, "INSERT INTO t (a, b) VALUES (?, ?)", SQLStore{}.insert("t", "a", "b"))
+}
+
+func TestDSNStore(t *testing.T) {
+	d := &recordingDriver{}
+	sql.Register("recording-dsn", d)
+
+	require.Nil(t, Analytics{Store: dsnStore{Driver: "recording-dsn", DSN: "file:runs.db"}}.Export(context.Background(), testRun()))
+	require.Len(t, d.execs, 6)
+	require.True(t, strings.HasPrefix(d.execs[0], "CREATE TABLE IF NOT EXISTS danger_runs"))
+	require.True(t, strings.HasPrefix(d.execs[1], "CREATE TABLE IF NOT EXISTS danger_rule_hits"))
+	require.Equal(t, "INSERT INTO danger_runs (run_at, repo, pr, result, pr_state, fails, warnings, messages, duration_seconds) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)", d.execs[2])
+
+	err := Analytics{Store: dsnStore{Driver: "unregistered", DSN: "x"}}.Export(context.Background(), testRun())
+	require.ErrorContains(t, err, "opening analytics database")
+}
@@ -0,0 +1,197 @@
+// Package metrics exports the outcome of a danger run, such as violations by
+// rule, run duration and PR size, to a Prometheus Pushgateway or an
+// OpenTelemetry (OTLP/HTTP) collector.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	danger "github.com/danger/golang"
+	"github.com/danger/golang/internal/otlp"
+)
+
+// Run describes one danger run.
+type Run struct {
+	Repo         string
+	PR           string
+	Results      danger.Results
+	Duration     time.Duration
+	Additions    int
+	Deletions    int
+	ChangedFiles int
+	// State is the state of the PR: "open", "closed" or "merged".
+	State string
+	Time  time.Time
+}
+
+// NewRun collects the metrics of a run from the results recorded on d and the
+// PR described by the DSL.
+func NewRun(d *danger.T, pr danger.DSL, duration time.Duration) Run {
+	r := Run{
+		Results:  d.Snapshot(),
+		Duration: duration,
+		Time:     time.Now(),
+	}
+	if pr.GitHub != nil && pr.GitHub.PR().Number != 0 {
+		ghPR := pr.GitHub.PR()
+		r.Repo = ghPR.Base.Repo.FullName
+		r.PR = fmt.Sprint(ghPR.Number)
+		r.Additions = ghPR.Additions
+		r.Deletions = ghPR.Deletions
+		r.ChangedFiles = ghPR.ChangedFiles
+		r.State = ghPR.State
+		if ghPR.Merged {
+			r.State = "merged"
+		}
+		return r
+	}
+	if pr.GitLab != nil {
+		r.Repo = pr.GitLab.Metadata().RepoSlug
+		r.PR = pr.GitLab.Metadata().PullRequestID
+		switch state := pr.GitLab.MR().State; state {
+		case "opened", "locked":
+			r.State = "open"
+		default:
+			r.State = state
+		}
+	}
+	if pr.Git != nil {
+		r.ChangedFiles = len(pr.Git.CreatedFiles()) + len(pr.Git.ModifiedFiles()) + len(pr.Git.DeletedFiles())
+	}
+	return r
+}
+
+// Sample is a single metric value.
+type Sample struct {
+	Name   string
+	Help   string
+	Labels map[string]string
+	Value  float64
+}
+
+// Samples flattens the run into metric samples. Violations are counted by
+// kind and rule, with "none" used for violations added outside of a rule.
+func (r Run) Samples() []Sample {
+	type key struct{ kind, rule string }
+	counts := map[key]int{}
+	for kind, vv := range map[string][]danger.Violation{
+		"fail":     r.Results.Fails,
+		"warning":  r.Results.Warnings,
+		"message":  r.Results.Messages,
+		"markdown": r.Results.Markdowns,
+	} {
+		for _, v := range vv {
+			rule := v.Rule
+			if rule == "" {
+				rule = "none"
+			}
+			counts[key{kind, rule}]++
+		}
+	}
+	keys := make([]key, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].kind != keys[j].kind {
+			return keys[i].kind < keys[j].kind
+		}
+		return keys[i].rule < keys[j].rule
+	})
+
+	var samples []Sample
+	for _, k := range keys {
+		samples = append(samples, Sample{
+			Name:   "danger_violations",
+			Help:   "Number of violations reported by the run.",
+			Labels: map[string]string{"kind": k.kind, "rule": k.rule},
+			Value:  float64(counts[k]),
+		})
+	}
+	return append(samples,
+		Sample{Name: "danger_run_duration_seconds", Help: "Duration of the danger run.", Value: r.Duration.Seconds()},
+		Sample{Name: "danger_pr_additions", Help: "Lines added by the PR.", Value: float64(r.Additions)},
+		Sample{Name: "danger_pr_deletions", Help: "Lines deleted by the PR.", Value: float64(r.Deletions)},
+		Sample{Name: "danger_pr_changed_files", Help: "Files changed by the PR.", Value: float64(r.ChangedFiles)},
+	)
+}
+
+// Exporter sends the metrics of a run somewhere.
+type Exporter interface {
+	Export(ctx context.Context, r Run) error
+}
+
+// Export sends the run to every exporter, returning the joined errors.
+func Export(ctx context.Context, r Run, exporters ...Exporter) error {
+	var errs []error
+	for _, e := range exporters {
+		if err := e.Export(ctx, r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FromEnv returns the exporters configured through the environment:
+//
+//   - DANGER_METRICS_PUSHGATEWAY_URL enables the Pushgateway exporter, with
+//     DANGER_METRICS_JOB as the job name (default "danger").
+//   - OTEL_EXPORTER_OTLP_METRICS_ENDPOINT or OTEL_EXPORTER_OTLP_ENDPOINT
+//     enables the OTLP exporter, with OTEL_EXPORTER_OTLP_HEADERS and
+//     OTEL_SERVICE_NAME honored.
+//   - DANGER_ANALYTICS_FILE appends the outcome of the run to a
+//     newline-delimited JSON file, see Analytics.
+//   - DANGER_ANALYTICS_DRIVER and DANGER_ANALYTICS_DSN append it to the
+//     database opened with the database/sql driver of that name, which the
+//     dangerfile registers by importing it, see SQLStore.
+func FromEnv() []Exporter {
+	var exporters []Exporter
+	if u := os.Getenv("DANGER_METRICS_PUSHGATEWAY_URL"); u != "" {
+		job := os.Getenv("DANGER_METRICS_JOB")
+		if job == "" {
+			job = "danger"
+		}
+		exporters = append(exporters, &PushGateway{URL: u, Job: job})
+	}
+
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT")
+	if endpoint == "" {
+		if base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); base != "" {
+			endpoint = strings.TrimSuffix(base, "/") + "/v1/metrics"
+		}
+	}
+	if endpoint != "" {
+		exporters = append(exporters, &OTLP{
+			Endpoint:    endpoint,
+			Headers:     ParseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")),
+			ServiceName: os.Getenv("OTEL_SERVICE_NAME"),
+		})
+	}
+	if path := os.Getenv("DANGER_ANALYTICS_FILE"); path != "" {
+		exporters = append(exporters, Analytics{Store: JSONLStore{Path: path}})
+	}
+	if driver, dsn := os.Getenv("DANGER_ANALYTICS_DRIVER"), os.Getenv("DANGER_ANALYTICS_DSN"); driver != "" && dsn != "" {
+		exporters = append(exporters, Analytics{Store: dsnStore{Driver: driver, DSN: dsn}})
+	}
+	return exporters
+}
+
+// ParseHeaders parses the OTEL_EXPORTER_OTLP_HEADERS format of comma separated
+// key=value pairs.
+func ParseHeaders(s string) map[string]string {
+	return otlp.ParseHeaders(s)
+}
+
+func httpClient(c *http.Client) *http.Client {
+	if c != nil {
+		return c
+	}
+	return http.DefaultClient
+}
@@ -0,0 +1,140 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+func testRun() Run {
+	return Run{
+		Repo: "danger/golang",
+		PR:   "42",
+		Results: danger.Results{
+			Fails:    []danger.Violation{{Message: "a", Rule: "changelog"}, {Message: "b", Rule: "changelog"}},
+			Warnings: []danger.Violation{{Message: "c"}},
+		},
+		Duration:     1500 * time.Millisecond,
+		Additions:    10,
+		Deletions:    2,
+		ChangedFiles: 3,
+		State:        "merged",
+		Time:         time.Unix(1700000000, 0),
+	}
+}
+
+func TestTextFormat(t *testing.T) {
+	want := `# HELP danger_violations Number of violations reported by the run.
+# TYPE danger_violations gauge
+danger_violations{kind="fail",rule="changelog"} 2
+danger_violations{kind="warning",rule="none"} 1
+# HELP danger_run_duration_seconds Duration of the danger run.
+# TYPE danger_run_duration_seconds gauge
+danger_run_duration_seconds 1.5
+# HELP danger_pr_additions Lines added by the PR.
+# TYPE danger_pr_additions gauge
+danger_pr_additions 10
+# HELP danger_pr_deletions Lines deleted by the PR.
+# TYPE danger_pr_deletions gauge
+danger_pr_deletions 2
+# HELP danger_pr_changed_files Files changed by the PR.
+# TYPE danger_pr_changed_files gauge
+danger_pr_changed_files 3
+`
+	require.Equal(t, want, string(TextFormat(testRun().Samples())))
+}
+
+func TestPushGateway(t *testing.T) {
+	var gotPath, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		gotPath = r.URL.Path
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+	}))
+	defer srv.Close()
+
+	p := &PushGateway{URL: srv.URL, Job: "danger"}
+	require.Nil(t, p.Export(context.Background(), testRun()))
+	require.Equal(t, "/metrics/job/danger/repo@base64/ZGFuZ2VyL2dvbGFuZw/pr/42", gotPath)
+	require.Contains(t, gotBody, `danger_violations{kind="fail",rule="changelog"} 2`)
+}
+
+func TestOTLP(t *testing.T) {
+	var payload struct {
+		ResourceMetrics []struct {
+			ScopeMetrics []struct {
+				Metrics []struct {
+					Name  string `json:"name"`
+					Gauge struct {
+						DataPoints []struct {
+							AsDouble float64 `json:"asDouble"`
+						} `json:"dataPoints"`
+					} `json:"gauge"`
+				} `json:"metrics"`
+			} `json:"scopeMetrics"`
+		} `json:"resourceMetrics"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "secret", r.Header.Get("Api-Key"))
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&payload))
+	}))
+	defer srv.Close()
+
+	o := &OTLP{Endpoint: srv.URL + "/v1/metrics", Headers: map[string]string{"Api-Key": "secret"}}
+	require.Nil(t, o.Export(context.Background(), testRun()))
+
+	metrics := payload.ResourceMetrics[0].ScopeMetrics[0].Metrics
+	require.Equal(t, "danger.violations", metrics[0].Name)
+	require.Len(t, metrics[0].Gauge.DataPoints, 2)
+	require.Equal(t, "danger.run.duration.seconds", metrics[1].Name)
+	require.Equal(t, 1.5, metrics[1].Gauge.DataPoints[0].AsDouble)
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("DANGER_METRICS_PUSHGATEWAY_URL", "http://gateway:9091")
+	t.Setenv("DANGER_METRICS_JOB", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318/")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "a=1, b=2")
+	t.Setenv("OTEL_SERVICE_NAME", "")
+	t.Setenv("DANGER_ANALYTICS_FILE", "")
+	t.Setenv("DANGER_ANALYTICS_DRIVER", "pgx")
+	t.Setenv("DANGER_ANALYTICS_DSN", "postgres://danger@db/analytics")
+
+	exporters := FromEnv()
+	require.Equal(t, []Exporter{
+		&PushGateway{URL: "http://gateway:9091", Job: "danger"},
+		&OTLP{Endpoint: "http://collector:4318/v1/metrics", Headers: map[string]string{"a": "1", "b": "2"}},
+		Analytics{Store: dsnStore{Driver: "pgx", DSN: "postgres://danger@db/analytics"}},
+	}, exporters)
+}
+
+func TestNewRunState(t *testing.T) {
+	opened := dangerJs.GitLabMR{}
+	opened.State = "opened"
+	tests := []struct {
+		name string
+		pr   danger.DSL
+		want string
+	}{
+		{"GitHub open", danger.DSL{GitHub: fakedsl.GitHub{PRData: dangerJs.GitHubPR{Number: 1, State: "open"}}}, "open"},
+		{"GitHub merged", danger.DSL{GitHub: fakedsl.GitHub{PRData: dangerJs.GitHubPR{Number: 1, State: "closed", Merged: true}}}, "merged"},
+		{"GitLab opened", danger.DSL{GitHub: fakedsl.GitHub{}, GitLab: fakedsl.GitLab{MRData: opened}}, "open"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, NewRun(danger.New(), tt.pr, 0).State)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/danger/golang/internal/otlp"
+)
+
+// OTLP sends metrics to an OpenTelemetry collector using OTLP over HTTP with
+// JSON encoding.
+type OTLP struct {
+	// Endpoint is the full metrics URL, e.g. http://collector:4318/v1/metrics
+	Endpoint    string
+	Headers     map[string]string
+	ServiceName string
+	Client      *http.Client
+}
+
+// Export sends the run's samples as gauges.
+func (o *OTLP) Export(ctx context.Context, r Run) error {
+	return otlp.Post(ctx, o.Client, o.Endpoint, o.Headers, "metrics", o.payload(r))
+}
+
+func (o *OTLP) payload(r Run) map[string]any {
+	service := o.ServiceName
+	if service == "" {
+		service = "danger-go"
+	}
+	resource := []otlp.Attribute{otlp.String("service.name", service)}
+	if r.Repo != "" {
+		resource = append(resource, otlp.String("danger.repo", r.Repo))
+	}
+	if r.PR != "" {
+		resource = append(resource, otlp.String("danger.pr", r.PR))
+	}
+
+	ts := strconv.FormatInt(r.Time.UnixNano(), 10)
+	var names []string
+	points := map[string][]map[string]any{}
+	for _, s := range r.Samples() {
+		// OTel metric names use dots rather than underscores
+		name := strings.ReplaceAll(s.Name, "_", ".")
+		if _, ok := points[name]; !ok {
+			names = append(names, name)
+		}
+		points[name] = append(points[name], map[string]any{
+			"timeUnixNano": ts,
+			"asDouble":     s.Value,
+			"attributes":   otlp.Attributes(s.Labels),
+		})
+	}
+
+	metrics := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		metrics = append(metrics, map[string]any{
+			"name":  name,
+			"gauge": map[string]any{"dataPoints": points[name]},
+		})
+	}
+	return map[string]any{
+		"resourceMetrics": []map[string]any{{
+			"resource": map[string]any{"attributes": resource},
+			"scopeMetrics": []map[string]any{{
+				"scope":   map[string]any{"name": "github.com/danger/golang/metrics"},
+				"metrics": metrics,
+			}},
+		}},
+	}
+}
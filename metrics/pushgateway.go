@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PushGateway pushes metrics to a Prometheus Pushgateway. The repo and PR
+// are added to the grouping key so runs for different PRs don't overwrite
+// each other.
+type PushGateway struct {
+	URL    string
+	Job    string
+	Client *http.Client
+}
+
+// Export replaces the metrics of the run's group on the gateway.
+func (p *PushGateway) Export(ctx context.Context, r Run) error {
+	u := strings.TrimSuffix(p.URL, "/") + "/metrics/job" + groupingValue(p.Job)
+	if r.Repo != "" {
+		u += "/repo" + groupingValue(r.Repo)
+	}
+	if r.PR != "" {
+		u += "/pr" + groupingValue(r.PR)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u, bytes.NewReader(TextFormat(r.Samples())))
+	if err != nil {
+		return fmt.Errorf("creating pushgateway request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := httpClient(p.Client).Do(req)
+	if err != nil {
+		return fmt.Errorf("pushing metrics: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("pushing metrics: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// groupingSuffix encodes a grouping label value as a path suffix, using the
+// base64 form so values may contain slashes.
+func groupingSuffix(v string) string {
+	return "@base64/" + base64.RawURLEncoding.EncodeToString([]byte(v))
+}
+
+// groupingValue encodes a grouping label value as a path suffix, only using
+// base64 when needed so the common case stays readable.
+func groupingValue(v string) string {
+	if strings.Contains(v, "/") {
+		return groupingSuffix(v)
+	}
+	return "/" + v
+}
+
+// TextFormat renders samples in the Prometheus text exposition format.
+func TextFormat(samples []Sample) []byte {
+	var buf bytes.Buffer
+	seen := map[string]bool{}
+	for _, s := range samples {
+		if !seen[s.Name] {
+			seen[s.Name] = true
+			fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s gauge\n", s.Name, s.Help, s.Name)
+		}
+		buf.WriteString(s.Name)
+		if len(s.Labels) > 0 {
+			names := make([]string, 0, len(s.Labels))
+			for k := range s.Labels {
+				names = append(names, k)
+			}
+			sort.Strings(names)
+			pairs := make([]string, 0, len(names))
+			for _, k := range names {
+				pairs = append(pairs, fmt.Sprintf("%s=%s", k, strconv.Quote(s.Labels[k])))
+			}
+			buf.WriteString("{" + strings.Join(pairs, ",") + "}")
+		}
+		fmt.Fprintf(&buf, " %s\n", strconv.FormatFloat(s.Value, 'g', -1, 64))
+	}
+	return buf.Bytes()
+}
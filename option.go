@@ -0,0 +1,110 @@
+package danger
+
+import (
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"time"
+)
+
+// Option configures T, see New.
+type Option func(*T)
+
+// Kind is the kind of a violation.
+type Kind string
+
+const (
+	KindFail     Kind = "fail"
+	KindWarning  Kind = "warning"
+	KindMessage  Kind = "message"
+	KindMarkdown Kind = "markdown"
+)
+
+// Reporter is notified of every violation as it is added, e.g. to log the
+// results as the dangerfile runs.
+type Reporter interface {
+	Report(kind Kind, v Violation)
+}
+
+// ReporterFunc adapts a function to Reporter.
+type ReporterFunc func(kind Kind, v Violation)
+
+// Report calls f.
+func (f ReporterFunc) Report(kind Kind, v Violation) {
+	f(kind, v)
+}
+
+// WithReporter adds r to the reporters notified of violations.
+func WithReporter(r Reporter) Option {
+	return func(t *T) {
+		t.reporters = append(t.reporters, r)
+	}
+}
+
+// WithLogger sets the logger returned by T.Logger, log.Default() otherwise.
+func WithLogger(l *log.Logger) Option {
+	return func(t *T) {
+		t.logger = l
+	}
+}
+
+// WithFailThreshold fails the build when more than n warnings were added.
+// Zero fails on any warning.
+func WithFailThreshold(n int) Option {
+	return func(t *T) {
+		t.failThreshold = n
+	}
+}
+
+// WithClock sets the clock returned by T.Now, e.g. a fixed time in tests.
+func WithClock(now func() time.Time) Option {
+	return func(t *T) {
+		t.now = now
+	}
+}
+
+// WithRandSource sets the source of T.Rand, e.g. a seeded one in tests.
+func WithRandSource(src rand.Source) Option {
+	return func(t *T) {
+		t.rand = rand.New(src)
+	}
+}
+
+// WithCommentLimit configures how the results are shortened to fit in the
+// Danger comment, see SetCommentLimit.
+func WithCommentLimit(l CommentLimit) Option {
+	return func(t *T) {
+		t.commentLimit = l
+	}
+}
+
+// Logger returns the logger dangerfiles and rules should log to.
+func (s *T) Logger() *log.Logger {
+	return s.logger
+}
+
+// Now returns the current time according to the clock of s.
+func (s *T) Now() time.Time {
+	return s.now()
+}
+
+// Rand returns the random number generator of s, e.g. to pick reviewers.
+func (s *T) Rand() *rand.Rand {
+	return s.rand
+}
+
+func (s *T) report(kind Kind, v Violation) {
+	for _, r := range s.reporters {
+		r.Report(kind, v)
+	}
+}
+
+// thresholdFail returns the failure added when the warnings exceed the fail
+// threshold.
+func (s *T) thresholdFail() (Violation, bool) {
+	if s.failThreshold < 0 || len(s.results.Warnings) <= s.failThreshold {
+		return Violation{}, false
+	}
+	return Violation{Message: fmt.Sprintf("%d warnings exceed the threshold of %d.",
+		len(s.results.Warnings), s.failThreshold)}, true
+}
@@ -0,0 +1,62 @@
+package danger_test
+
+import (
+	"bytes"
+	"log"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+)
+
+func TestWithReporter(t *testing.T) {
+	var kinds []danger.Kind
+	var got []danger.Violation
+	d := danger.New(danger.WithReporter(danger.ReporterFunc(func(kind danger.Kind, v danger.Violation) {
+		kinds = append(kinds, kind)
+		got = append(got, v)
+	})))
+
+	d.Warn("w", "a.go", 1)
+	d.Fail("f", "", 0)
+	d.Message("m", "", 0)
+	d.Markdown("md", "", 0)
+	require.Equal(t, []danger.Kind{danger.KindWarning, danger.KindFail, danger.KindMessage, danger.KindMarkdown}, kinds)
+	require.Equal(t, danger.Violation{Message: "w", File: "a.go", Line: 1}, got[0])
+}
+
+func TestWithFailThreshold(t *testing.T) {
+	d := danger.New(danger.WithFailThreshold(1))
+	d.Warn("one", "", 0)
+	require.Empty(t, d.Snapshot().Fails)
+
+	d.Warn("two", "", 0)
+	require.Equal(t, []danger.Violation{{Message: "2 warnings exceed the threshold of 1."}}, d.Snapshot().Fails)
+	r, err := d.Results()
+	require.Nil(t, err)
+	require.Contains(t, r, `"fails":[{"message":"2 warnings exceed the threshold of 1."}]`)
+
+	// no threshold by default
+	d = danger.New()
+	d.Warn("one", "", 0)
+	require.Empty(t, d.Snapshot().Fails)
+}
+
+func TestWithClockAndRand(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	a := danger.New(danger.WithClock(func() time.Time { return now }), danger.WithRandSource(rand.NewPCG(1, 2)))
+	b := danger.New(danger.WithRandSource(rand.NewPCG(1, 2)))
+	require.Equal(t, now, a.Now())
+	require.Equal(t, a.Rand().IntN(1000), b.Rand().IntN(1000))
+}
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	d := danger.New(danger.WithLogger(log.New(&buf, "", 0)))
+	d.Logger().Print("hello")
+	require.Equal(t, "hello\n", buf.String())
+	require.Equal(t, log.Default(), danger.New().Logger())
+}
@@ -0,0 +1,59 @@
+package danger
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// WithSortedResults sorts the violations of each group by file and line,
+// keeping insertion order between violations of the same line. Violations
+// without a file come first. By default violations keep insertion order.
+func WithSortedResults() Option {
+	return func(t *T) {
+		t.sorted = true
+	}
+}
+
+// Sorted returns a copy of r with the violations of each group sorted by file
+// and line, see WithSortedResults.
+func (r Results) Sorted() Results {
+	sorted := r
+	sorted.Fails = sortViolations(r.Fails)
+	sorted.Warnings = sortViolations(r.Warnings)
+	sorted.Messages = sortViolations(r.Messages)
+	sorted.Markdowns = sortViolations(r.Markdowns)
+	return sorted
+}
+
+func sortViolations(vs []Violation) []Violation {
+	if vs == nil {
+		return nil
+	}
+	sorted := slices.Clone(vs)
+	slices.SortStableFunc(sorted, func(a, b Violation) int {
+		return cmp.Or(cmp.Compare(a.File, b.File), cmp.Compare(a.Line, b.Line))
+	})
+	return sorted
+}
+
+// CanonicalJSON encodes r in a stable form suited to golden files: indented,
+// without HTML escaping, with empty groups as [] rather than null, and ending
+// with a newline.
+func (r Results) CanonicalJSON() ([]byte, error) {
+	for _, group := range []*[]Violation{&r.Fails, &r.Warnings, &r.Messages, &r.Markdowns} {
+		if *group == nil {
+			*group = []Violation{}
+		}
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return nil, fmt.Errorf("encoding results: %w", err)
+	}
+	return buf.Bytes(), nil
+}
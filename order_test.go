@@ -0,0 +1,49 @@
+package danger_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+)
+
+func TestSortedResults(t *testing.T) {
+	d := danger.New(danger.WithSortedResults())
+	d.Warn("b:3", "b.go", 3)
+	d.Warn("a:10", "a.go", 10)
+	d.Warn("general", "", 0)
+	d.Warn("a:2", "a.go", 2)
+	d.Warn("a:2 again", "a.go", 2)
+
+	var got []string
+	for _, v := range d.Snapshot().Warnings {
+		got = append(got, v.Message)
+	}
+	require.Equal(t, []string{"general", "a:2", "a:2 again", "a:10", "b:3"}, got)
+
+	// insertion order by default
+	d = danger.New()
+	d.Warn("b", "b.go", 1)
+	d.Warn("a", "a.go", 1)
+	require.Equal(t, "b", d.Snapshot().Warnings[0].Message)
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	r := danger.Results{Warnings: []danger.Violation{{Message: "use <details>", File: "a.go", Line: 1}}}
+	got, err := r.CanonicalJSON()
+	require.Nil(t, err)
+	require.Equal(t, `{
+  "fails": [],
+  "warnings": [
+    {
+      "message": "use <details>",
+      "file": "a.go",
+      "line": 1
+    }
+  ],
+  "messages": [],
+  "markdowns": []
+}
+`, string(got))
+}
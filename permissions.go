@@ -0,0 +1,187 @@
+package danger
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/githubclient"
+	"github.com/danger/golang/gitlabclient"
+)
+
+// Permission is a write access to the repository a rule needs, named after
+// the GitHub App permissions.
+type Permission string
+
+const (
+	// PullRequestsWrite allows commenting on, editing and requesting reviews
+	// of the PR.
+	PullRequestsWrite Permission = "pull_requests:write"
+	// IssuesWrite allows labeling the PR and creating issues.
+	IssuesWrite Permission = "issues:write"
+	// ContentsWrite allows pushing, e.g. updating the PR branch.
+	ContentsWrite Permission = "contents:write"
+	// StatusesWrite allows setting commit statuses.
+	StatusesWrite Permission = "statuses:write"
+)
+
+// tokenAccess is what the API token of the DSL may do, as far as the API
+// tells.
+type tokenAccess struct {
+	gitLab bool
+	// scopes are the scopes of a classic GitHub token or a GitLab token.
+	// scopesKnown is false for tokens without scopes.
+	scopes      []string
+	scopesKnown bool
+	// public repositories accept the public_repo scope.
+	public bool
+	// canPush is the push permission of a fine-grained token on the
+	// repository, nil when the API doesn't report it as for app tokens.
+	canPush *bool
+}
+
+// missing returns the permissions of perms the token lacks, with how to
+// grant them.
+func (a tokenAccess) missing(perms []Permission) ([]Permission, string) {
+	var lacks func(Permission) bool
+	var hint string
+	switch {
+	case a.gitLab:
+		hint = "create the GitLab token with the `api` scope"
+		lacks = func(Permission) bool { return a.scopesKnown && !slices.Contains(a.scopes, "api") }
+	case a.scopesKnown:
+		hint = "add the `repo` scope to the GitHub token"
+		lacks = func(p Permission) bool {
+			switch {
+			case slices.Contains(a.scopes, "repo"), a.public && slices.Contains(a.scopes, "public_repo"):
+				return false
+			case p == StatusesWrite:
+				return !slices.Contains(a.scopes, "repo:status")
+			}
+			return true
+		}
+	default:
+		hint = "grant the GitHub token write access to the repository"
+		lacks = func(Permission) bool { return a.canPush != nil && !*a.canPush }
+	}
+	var out []Permission
+	for _, p := range perms {
+		if lacks(p) {
+			out = append(out, p)
+		}
+	}
+	return out, hint
+}
+
+// fetchTokenAccess asks the GitHub or GitLab API what the token of pr may do.
+var fetchTokenAccess = func(ctx context.Context, pr DSL) (tokenAccess, error) {
+	if pr.GitHub != nil && pr.GitHub.PR().Number != 0 {
+		client, err := githubclient.NewFromSettings(pr.Settings)
+		if err != nil {
+			return tokenAccess{}, err
+		}
+		a := tokenAccess{public: !pr.GitHub.PR().Base.Repo.IsPrivate}
+		if a.scopes, a.scopesKnown, err = client.TokenScopes(ctx); err != nil || a.scopesKnown {
+			return a, err
+		}
+		this := pr.GitHub.ThisPR()
+		var repo struct {
+			Permissions *struct {
+				Push bool `json:"push"`
+			} `json:"permissions"`
+		}
+		path := fmt.Sprintf("repos/%s/%s", url.PathEscape(this.Owner), url.PathEscape(this.Repo))
+		if _, err := client.Do(ctx, http.MethodGet, path, nil, &repo); err != nil {
+			return a, err
+		}
+		if repo.Permissions != nil {
+			a.canPush = &repo.Permissions.Push
+		}
+		return a, nil
+	}
+	if pr.GitLab != nil && pr.GitLab.MR().IID != 0 {
+		client, err := gitlabclient.NewFromSettings(pr.Settings)
+		if err != nil {
+			return tokenAccess{}, err
+		}
+		a := tokenAccess{gitLab: true}
+		if client.TokenType() != gitlabclient.PersonalToken {
+			return a, nil
+		}
+		scopes, err := client.TokenScopes(ctx)
+		if err != nil {
+			// older instances and other token types can't list scopes
+			return a, nil
+		}
+		a.scopes, a.scopesKnown = scopes, true
+		return a, nil
+	}
+	return tokenAccess{}, fmt.Errorf("checking permissions requires a GitHub or GitLab PR")
+}
+
+// accessCache holds the access of each token, so the API is asked once per
+// run however many rules require permissions.
+var accessCache sync.Map
+
+func tokenAccessOf(pr DSL) (tokenAccess, error) {
+	key := ""
+	if pr.Settings != nil {
+		key = pr.Settings.GitHubAccessToken() + "\x00" + dangerJs.GitLabAccessToken(pr.Settings)
+	}
+	switch {
+	case pr.GitHub != nil:
+		key += "\x00" + pr.GitHub.ThisPR().Owner + "/" + pr.GitHub.ThisPR().Repo
+	case pr.GitLab != nil:
+		key += "\x00" + pr.GitLab.Metadata().RepoSlug
+	}
+	if a, ok := accessCache.Load(key); ok {
+		return a.(tokenAccess), nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	a, err := fetchTokenAccess(ctx, pr)
+	if err != nil {
+		return tokenAccess{}, err
+	}
+	accessCache.Store(key, a)
+	return a, nil
+}
+
+// MissingPermissions returns the permissions of perms the API token of pr
+// lacks, with an actionable hint on granting them. Tokens whose permissions
+// the API doesn't report, such as GitHub App and Actions tokens, are assumed
+// to have them; their 403 errors name the permission needed instead.
+func MissingPermissions(pr DSL, perms ...Permission) ([]Permission, string, error) {
+	a, err := tokenAccessOf(pr)
+	if err != nil {
+		return nil, "", err
+	}
+	missing, hint := a.missing(perms)
+	return missing, hint, nil
+}
+
+// RequirePermissions skips the rule, listing the missing permissions and how
+// to grant them, when the API token lacks any of perms, rather than failing
+// with a 403 while it runs. The rule runs when the permissions can't be
+// checked, and in fork-PR safe mode, where rules skip writes themselves.
+func RequirePermissions(perms ...Permission) Condition {
+	return func(pr DSL) (bool, string) {
+		if SafeMode() {
+			return true, ""
+		}
+		missing, hint, err := MissingPermissions(pr, perms...)
+		if err != nil || len(missing) == 0 {
+			return true, ""
+		}
+		names := make([]string, len(missing))
+		for i, p := range missing {
+			names[i] = string(p)
+		}
+		return false, fmt.Sprintf("the API token lacks %s, %s", quoteAll(names, ", "), hint)
+	}
+}
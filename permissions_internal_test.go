@@ -0,0 +1,111 @@
+package danger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+func TestTokenAccessMissing(t *testing.T) {
+	no, yes := false, true
+	all := []Permission{PullRequestsWrite, StatusesWrite}
+	tests := []struct {
+		name   string
+		access tokenAccess
+		want   []Permission
+		hint   string
+	}{
+		{name: "repo scope", access: tokenAccess{scopes: []string{"repo"}, scopesKnown: true}},
+		{name: "public_repo on public repository", access: tokenAccess{scopes: []string{"public_repo"}, scopesKnown: true, public: true}},
+		{
+			name:   "public_repo on private repository",
+			access: tokenAccess{scopes: []string{"public_repo"}, scopesKnown: true},
+			want:   all,
+			hint:   "add the `repo` scope to the GitHub token",
+		},
+		{
+			name:   "status scope only",
+			access: tokenAccess{scopes: []string{"repo:status"}, scopesKnown: true},
+			want:   []Permission{PullRequestsWrite},
+			hint:   "add the `repo` scope to the GitHub token",
+		},
+		{
+			name:   "read-only fine-grained token",
+			access: tokenAccess{canPush: &no},
+			want:   all,
+			hint:   "grant the GitHub token write access to the repository",
+		},
+		{name: "fine-grained token", access: tokenAccess{canPush: &yes}, hint: "grant the GitHub token write access to the repository"},
+		{name: "app token", access: tokenAccess{}, hint: "grant the GitHub token write access to the repository"},
+		{
+			name:   "GitLab read_api token",
+			access: tokenAccess{gitLab: true, scopes: []string{"read_api"}, scopesKnown: true},
+			want:   all,
+			hint:   "create the GitLab token with the `api` scope",
+		},
+		{name: "GitLab api token", access: tokenAccess{gitLab: true, scopes: []string{"api"}, scopesKnown: true}, hint: "create the GitLab token with the `api` scope"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, hint := tt.access.missing(all)
+			require.Equal(t, tt.want, got)
+			if tt.hint != "" {
+				require.Equal(t, tt.hint, hint)
+			}
+		})
+	}
+}
+
+func TestRequirePermissions(t *testing.T) {
+	t.Setenv(dangerJs.SafeModeEnv, "false")
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		require.Equal(t, "/api/v3/rate_limit", r.URL.Path)
+		w.Header().Set("X-OAuth-Scopes", "read:org, public_repo")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	data, err := dangerJs.DecodeDSL([]byte(`{"git": {}, "github": {
+		"pr": {"number": 1, "base": {"repo": {"private": true}}},
+		"thisPR": {"owner": "o", "repo": "r", "number": 1}
+	}, "settings": {"github": {"accessToken": "permissions-test", "baseURL": "` + srv.URL + `"}}}`))
+	require.Nil(t, err)
+	pr := data.ToInterface()
+
+	ran := false
+	d := New()
+	d.RunRules(pr,
+		Rule{Name: "labeler", When: []Condition{RequirePermissions(IssuesWrite)}, Run: func(*T, DSL) { ran = true }},
+		Rule{Name: "assigner", When: []Condition{RequirePermissions(PullRequestsWrite)}, Run: func(*T, DSL) { ran = true }},
+	)
+	require.False(t, ran)
+	require.Equal(t, 1, calls)
+	require.Equal(t, []RuleRun{
+		{Name: "labeler", Status: RuleSkipped, Reason: "the API token lacks `issues:write`, add the `repo` scope to the GitHub token"},
+		{Name: "assigner", Status: RuleSkipped, Reason: "the API token lacks `pull_requests:write`, add the `repo` scope to the GitHub token"},
+	}, d.RuleRuns())
+
+	t.Setenv(dangerJs.SafeModeEnv, "true")
+	run, _ := RequirePermissions(IssuesWrite)(pr)
+	require.True(t, run)
+}
+
+func TestRequirePermissionsUnchecked(t *testing.T) {
+	t.Setenv(dangerJs.SafeModeEnv, "false")
+	fetch := fetchTokenAccess
+	t.Cleanup(func() { fetchTokenAccess = fetch })
+	fetchTokenAccess = func(context.Context, DSL) (tokenAccess, error) {
+		return tokenAccess{}, context.DeadlineExceeded
+	}
+
+	run, reason := RequirePermissions(PullRequestsWrite)(DSL{})
+	require.True(t, run)
+	require.Empty(t, reason)
+}
@@ -0,0 +1,192 @@
+// Package aireview reviews the changed files of a PR with a language model
+// and posts its findings as clearly labeled, non-blocking suggestions.
+package aireview
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/review"
+)
+
+// Label prefixes every suggestion so readers can tell them from the findings
+// of deterministic rules.
+const Label = "🤖 AI suggestion:"
+
+// systemPrompt asks for findings in a format parseFindings reads.
+const systemPrompt = `You are reviewing one file of a pull request. Point out bugs, security issues and ` +
+	`clear maintainability problems in the changed lines only; do not comment on style or praise the code. ` +
+	`Reply with a JSON array of findings, each an object with "line", the line number in the new version of ` +
+	`the file, and "message", one or two sentences. Reply with [] when there is nothing worth raising.`
+
+// secretPatterns match credentials which must not be sent to the provider.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`\b(?:ghp|gho|ghu|ghs|ghr|github_pat)_[0-9A-Za-z_]{20,}\b`),
+	regexp.MustCompile(`\bglpat-[0-9A-Za-z_-]{20,}\b`),
+	regexp.MustCompile(`\bxox[abprs]-[0-9A-Za-z-]{10,}\b`),
+	regexp.MustCompile(`\bsk-[0-9A-Za-z_-]{20,}\b`),
+	regexp.MustCompile(`(?i)((?:api[_-]?key|secret|token|passw(?:or)?d)["']?\s*[:=]\s*["']?)[^\s"']{6,}`),
+}
+
+// Redact replaces credentials in s, e.g. cloud keys, API tokens and private
+// keys, with "[REDACTED]". Each line of a multi-line credential is replaced,
+// so s keeps its line count.
+func Redact(s string) string {
+	for i, p := range secretPatterns {
+		if i == len(secretPatterns)-1 {
+			// keep the key name of assignments
+			s = p.ReplaceAllString(s, "${1}[REDACTED]")
+			continue
+		}
+		s = p.ReplaceAllStringFunc(s, func(m string) string {
+			return strings.Repeat("[REDACTED]\n", strings.Count(m, "\n")) + "[REDACTED]"
+		})
+	}
+	return s
+}
+
+// redactLines redacts the contents of consecutive diff lines together, so
+// credentials spanning lines such as private keys are found.
+func redactLines(ls []dangerJs.DiffLine) []dangerJs.DiffLine {
+	out := make([]dangerJs.DiffLine, len(ls))
+	copy(out, ls)
+	for start := 0; start < len(out); {
+		end := start + 1
+		for end < len(out) && out[end].Line == out[end-1].Line+1 {
+			end++
+		}
+		contents := make([]string, end-start)
+		for i := range contents {
+			contents[i] = out[start+i].Content
+		}
+		for i, c := range strings.Split(Redact(strings.Join(contents, "\n")), "\n") {
+			out[start+i].Content = c
+		}
+		start = end
+	}
+	return out
+}
+
+// redactContext redacts every piece of content of c.
+func redactContext(c review.FileContext) review.FileContext {
+	c.Added, c.Removed = redactLines(c.Added), redactLines(c.Removed)
+	excerpts := make([]review.Excerpt, len(c.Excerpts))
+	for i, e := range c.Excerpts {
+		e.Lines = strings.Split(Redact(strings.Join(e.Lines, "\n")), "\n")
+		excerpts[i] = e
+	}
+	c.Excerpts = excerpts
+	related := make([]review.RelatedFile, len(c.Related))
+	for i, r := range c.Related {
+		r.Content = Redact(r.Content)
+		related[i] = r
+	}
+	c.Related = related
+	return c
+}
+
+// parseFindings reads the JSON array of findings from the reply of the
+// model, ignoring any text around it.
+func parseFindings(text string) ([]review.Finding, error) {
+	start, end := strings.Index(text, "["), strings.LastIndex(text, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("reply has no JSON array of findings")
+	}
+	var raw []struct {
+		Line    int    `json:"line"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(text[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("decoding findings: %w", err)
+	}
+	var findings []review.Finding
+	for _, r := range raw {
+		if msg := strings.TrimSpace(r.Message); msg != "" {
+			findings = append(findings, review.Finding{Line: r.Line, Severity: review.SeverityMessage, Message: Label + " " + msg})
+		}
+	}
+	return findings, nil
+}
+
+// Config configures the review.
+type Config struct {
+	Provider Provider
+	// Files are the patterns of the files reviewed, see dangerJs.MatchPath.
+	// All created and modified files are reviewed when empty.
+	Files []string
+	// MaxFiles caps the number of files reviewed, unlimited when zero.
+	MaxFiles int
+	// ContextTokens is the token budget of the context of each file.
+	ContextTokens int
+	// MaxOutputTokens caps the reply for each file.
+	MaxOutputTokens int
+	// MaxTotalTokens caps the tokens used by the whole run, as reported by
+	// the provider, bounding its cost. Unlimited when zero.
+	MaxTotalTokens int
+	// NoRedact sends the context as is. By default credentials are removed
+	// from it before it is sent.
+	NoRedact bool
+}
+
+// NewConfig returns a Config reviewing at most 20 files with 4000 tokens of
+// context each, 100000 tokens in total.
+func NewConfig(p Provider) Config {
+	return Config{
+		Provider:        p,
+		MaxFiles:        20,
+		ContextTokens:   4000,
+		MaxOutputTokens: 1024,
+		MaxTotalTokens:  100000,
+	}
+}
+
+// Run reviews the changed files and adds the findings as messages.
+func (c Config) Run(d *danger.T, pr danger.DSL) {
+	r := &reviewer{config: c}
+	review.Config{
+		Reviewer: r,
+		Builder:  review.ContextBuilder{TokenBudget: c.ContextTokens},
+		Files:    c.Files,
+		MaxFiles: c.MaxFiles,
+	}.Run(d, pr)
+	switch {
+	case r.skipped == 1:
+		d.Message(fmt.Sprintf("AI review stopped after using %d tokens, 1 file was not reviewed.", r.used), "", 0)
+	case r.skipped > 1:
+		d.Message(fmt.Sprintf("AI review stopped after using %d tokens, %d files were not reviewed.", r.used, r.skipped), "", 0)
+	}
+}
+
+// reviewer implements review.Reviewer, keeping track of the tokens used.
+type reviewer struct {
+	config  Config
+	used    int
+	skipped int
+}
+
+func (r *reviewer) Review(ctx context.Context, fc review.FileContext) ([]review.Finding, error) {
+	if r.config.MaxTotalTokens > 0 && r.used >= r.config.MaxTotalTokens {
+		r.skipped++
+		return nil, nil
+	}
+	if !r.config.NoRedact {
+		fc = redactContext(fc)
+	}
+	resp, err := r.config.Provider.Complete(ctx, Request{
+		System:    systemPrompt,
+		Prompt:    fc.String(),
+		MaxTokens: r.config.MaxOutputTokens,
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.used += resp.InputTokens + resp.OutputTokens
+	return parseFindings(resp.Text)
+}
@@ -0,0 +1,191 @@
+package aireview
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Request is a completion request.
+type Request struct {
+	System    string
+	Prompt    string
+	MaxTokens int
+}
+
+// Response is the completion of a Request with the tokens it used.
+type Response struct {
+	Text         string
+	InputTokens  int
+	OutputTokens int
+}
+
+// Provider completes prompts with a language model.
+type Provider interface {
+	Complete(ctx context.Context, r Request) (Response, error)
+}
+
+// OpenAI is a provider for the OpenAI chat completions API and the many
+// services and local servers compatible with it, e.g. vLLM or Ollama.
+type OpenAI struct {
+	// BaseURL is the API root, e.g. "https://api.openai.com/v1" or
+	// "http://localhost:11434/v1".
+	BaseURL string
+	// APIKey is sent as a bearer token when set.
+	APIKey     string
+	Model      string
+	HTTPClient *http.Client
+}
+
+// NewOpenAI creates an OpenAI provider for model authenticated with
+// OPENAI_API_KEY, using OPENAI_BASE_URL when set.
+func NewOpenAI(model string) OpenAI {
+	base := os.Getenv("OPENAI_BASE_URL")
+	if base == "" {
+		base = "https://api.openai.com/v1"
+	}
+	return OpenAI{BaseURL: base, APIKey: os.Getenv("OPENAI_API_KEY"), Model: model}
+}
+
+// NewLocal creates a provider for a local OpenAI-compatible server, so code
+// never leaves the CI machine.
+func NewLocal(baseURL, model string) OpenAI {
+	return OpenAI{BaseURL: baseURL, Model: model}
+}
+
+// Complete sends r to the chat completions endpoint.
+func (o OpenAI) Complete(ctx context.Context, r Request) (Response, error) {
+	body := map[string]any{
+		"model": o.Model,
+		"messages": []map[string]string{
+			{"role": "system", "content": r.System},
+			{"role": "user", "content": r.Prompt},
+		},
+	}
+	if r.MaxTokens > 0 {
+		body["max_tokens"] = r.MaxTokens
+	}
+	headers := map[string]string{}
+	if o.APIKey != "" {
+		headers["Authorization"] = "Bearer " + o.APIKey
+	}
+	var out struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	if err := post(ctx, o.HTTPClient, strings.TrimRight(o.BaseURL, "/")+"/chat/completions", headers, body, &out); err != nil {
+		return Response{}, err
+	}
+	if len(out.Choices) == 0 {
+		return Response{}, fmt.Errorf("completion has no choices")
+	}
+	return Response{
+		Text:         out.Choices[0].Message.Content,
+		InputTokens:  out.Usage.PromptTokens,
+		OutputTokens: out.Usage.CompletionTokens,
+	}, nil
+}
+
+// Anthropic is a provider for the Anthropic Messages API.
+type Anthropic struct {
+	// BaseURL is the API root, "https://api.anthropic.com" when empty.
+	BaseURL    string
+	APIKey     string
+	Model      string
+	HTTPClient *http.Client
+}
+
+// NewAnthropic creates an Anthropic provider for model authenticated with
+// ANTHROPIC_API_KEY.
+func NewAnthropic(model string) Anthropic {
+	return Anthropic{APIKey: os.Getenv("ANTHROPIC_API_KEY"), Model: model}
+}
+
+// anthropicVersion is the version of the Messages API requested.
+const anthropicVersion = "2023-06-01"
+
+// Complete sends r to the messages endpoint.
+func (a Anthropic) Complete(ctx context.Context, r Request) (Response, error) {
+	base := a.BaseURL
+	if base == "" {
+		base = "https://api.anthropic.com"
+	}
+	maxTokens := r.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = 1024 // required by the API
+	}
+	body := map[string]any{
+		"model":      a.Model,
+		"max_tokens": maxTokens,
+		"system":     r.System,
+		"messages":   []map[string]string{{"role": "user", "content": r.Prompt}},
+	}
+	headers := map[string]string{"x-api-key": a.APIKey, "anthropic-version": anthropicVersion}
+	var out struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		Usage struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	if err := post(ctx, a.HTTPClient, strings.TrimRight(base, "/")+"/v1/messages", headers, body, &out); err != nil {
+		return Response{}, err
+	}
+	var text strings.Builder
+	for _, c := range out.Content {
+		if c.Type == "text" {
+			text.WriteString(c.Text)
+		}
+	}
+	return Response{Text: text.String(), InputTokens: out.Usage.InputTokens, OutputTokens: out.Usage.OutputTokens}, nil
+}
+
+// post sends body as JSON to url and decodes the JSON response into out.
+func post(ctx context.Context, client *http.Client, url string, headers map[string]string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("encoding request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("calling %s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s returned %s: %s", req.URL.Host, resp.Status, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
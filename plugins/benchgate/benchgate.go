@@ -0,0 +1,342 @@
+// Package benchgate compares Go benchmark results of the base and head of a
+// PR, in the format printed by `go test -bench` and read by benchstat, and
+// flags statistically significant regressions.
+package benchgate
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	danger "github.com/danger/golang"
+)
+
+// Results are the measurements of a benchmark run, by benchmark and unit.
+type Results map[string]map[string][]float64
+
+// procsSuffix is the GOMAXPROCS suffix go test adds to benchmark names.
+var procsSuffix = regexp.MustCompile(`-\d+$`)
+
+// Parse reads benchmark results. Repeated runs of a benchmark, e.g. with
+// -count, are kept as samples. Benchmarks of different packages are told
+// apart by the "pkg:" lines go test prints.
+func Parse(r io.Reader) (Results, error) {
+	res := Results{}
+	pkg := ""
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if p, ok := strings.CutPrefix(line, "pkg:"); ok {
+			pkg = strings.TrimSpace(p)
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		if _, err := strconv.Atoi(fields[1]); err != nil {
+			continue
+		}
+		name := procsSuffix.ReplaceAllString(fields[0], "")
+		if pkg != "" {
+			name = pkg + "." + name
+		}
+		for i := 2; i+1 < len(fields); i += 2 {
+			v, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				break
+			}
+			if res[name] == nil {
+				res[name] = map[string][]float64{}
+			}
+			res[name][fields[i+1]] = append(res[name][fields[i+1]], v)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("reading benchmark results: %w", err)
+	}
+	return res, nil
+}
+
+// ParseFile reads benchmark results from the file at path.
+func ParseFile(path string) (Results, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening benchmark results: %w", err)
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Delta is the change of one unit of one benchmark.
+type Delta struct {
+	Benchmark string
+	Unit      string
+	Base      float64
+	Head      float64
+	// Change is the relative change of the median, e.g. 0.1 for +10%.
+	Change float64
+	// P is the p-value of the Mann-Whitney U test of the samples.
+	P float64
+	// Significant is set when P is below the significance level.
+	Significant bool
+	// Regression is set when the change is significant and for the worse.
+	Regression bool
+}
+
+// higherIsBetter reports whether larger values of unit are improvements,
+// e.g. throughput in MB/s.
+func higherIsBetter(unit string) bool {
+	return strings.HasSuffix(unit, "/s")
+}
+
+// Compare computes the deltas of the benchmarks and units found in both base
+// and head, sorted by benchmark and unit.
+func Compare(base, head Results, alpha float64) []Delta {
+	var deltas []Delta
+	for name, units := range head {
+		for unit, h := range units {
+			b := base[name][unit]
+			if len(b) == 0 {
+				continue
+			}
+			d := Delta{Benchmark: name, Unit: unit, Base: median(b), Head: median(h)}
+			if d.Base != 0 {
+				d.Change = (d.Head - d.Base) / d.Base
+			}
+			d.P = mannWhitneyU(b, h)
+			d.Significant = d.P < alpha && d.Change != 0
+			worse := d.Change > 0
+			if higherIsBetter(unit) {
+				worse = d.Change < 0
+			}
+			d.Regression = d.Significant && worse
+			deltas = append(deltas, d)
+		}
+	}
+	slices.SortFunc(deltas, func(a, b Delta) int {
+		if c := strings.Compare(a.Benchmark, b.Benchmark); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Unit, b.Unit)
+	})
+	return deltas
+}
+
+func median(xs []float64) float64 {
+	s := slices.Clone(xs)
+	slices.Sort(s)
+	n := len(s)
+	if n%2 == 1 {
+		return s[n/2]
+	}
+	return (s[n/2-1] + s[n/2]) / 2
+}
+
+// mannWhitneyU returns the two-sided p-value of the Mann-Whitney U test of
+// the samples, exact without ties and with the normal approximation
+// otherwise.
+func mannWhitneyU(a, b []float64) float64 {
+	n1, n2 := len(a), len(b)
+	type obs struct {
+		v     float64
+		first bool
+	}
+	all := make([]obs, 0, n1+n2)
+	for _, v := range a {
+		all = append(all, obs{v, true})
+	}
+	for _, v := range b {
+		all = append(all, obs{v, false})
+	}
+	slices.SortFunc(all, func(x, y obs) int {
+		switch {
+		case x.v < y.v:
+			return -1
+		case x.v > y.v:
+			return 1
+		}
+		return 0
+	})
+
+	// rank with ties sharing their average rank
+	var r1, tieTerm float64
+	ties := false
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].first {
+				r1 += rank
+			}
+		}
+		if t := float64(j - i); t > 1 {
+			ties = true
+			tieTerm += t*t*t - t
+		}
+		i = j
+	}
+	u := r1 - float64(n1*(n1+1))/2
+
+	if !ties && n1*n2 <= 400 {
+		return exactP(n1, n2, u)
+	}
+	n := float64(n1 + n2)
+	mean := float64(n1*n2) / 2
+	variance := float64(n1*n2) / 12 * ((n + 1) - tieTerm/(n*(n-1)))
+	if variance <= 0 {
+		return 1
+	}
+	z := (math.Abs(u-mean) - 0.5) / math.Sqrt(variance)
+	return math.Min(1, math.Erfc(math.Max(z, 0)/math.Sqrt2))
+}
+
+// exactP returns the two-sided p-value of U for samples of sizes n1 and n2
+// from the exact distribution of U.
+func exactP(n1, n2 int, u float64) float64 {
+	// counts[j][k] is the number of arrangements of j and n2 values with U=k,
+	// built up one sample size at a time.
+	maxU := n1 * n2
+	prev := make([][]float64, n2+1)
+	for m := range prev {
+		prev[m] = make([]float64, maxU+1)
+		prev[m][0] = 1 // no values of the first sample
+	}
+	for i := 1; i <= n1; i++ {
+		cur := make([][]float64, n2+1)
+		cur[0] = make([]float64, maxU+1)
+		cur[0][0] = 1
+		for m := 1; m <= n2; m++ {
+			cur[m] = make([]float64, maxU+1)
+			for k := 0; k <= i*m; k++ {
+				// the largest value is from the first sample, above m values
+				if k >= m {
+					cur[m][k] += prev[m][k-m]
+				}
+				cur[m][k] += cur[m-1][k]
+			}
+		}
+		prev = cur
+	}
+	counts := prev[n2]
+	total := 0.0
+	for _, c := range counts {
+		total += c
+	}
+	lo, hi := 0.0, 0.0
+	for k, c := range counts {
+		if float64(k) <= u {
+			lo += c
+		}
+		if float64(k) >= u {
+			hi += c
+		}
+	}
+	return math.Min(1, 2*math.Min(lo, hi)/total)
+}
+
+// Config configures the regression gate.
+type Config struct {
+	// BasePath and HeadPath are the files holding the benchmark output of
+	// the base and head of the PR.
+	BasePath string
+	HeadPath string
+	// WarnThreshold and FailThreshold are the relative regressions, e.g.
+	// 0.05 for 5%, beyond which significant regressions are warned about or
+	// fail the build. Zero disables the check.
+	WarnThreshold float64
+	FailThreshold float64
+	// Alpha is the significance level, 0.05 when zero.
+	Alpha float64
+	// Units limits the comparison to these units, e.g. "ns/op" and
+	// "allocs/op". All units are compared when empty.
+	Units []string
+}
+
+// NewConfig returns a Config warning about regressions beyond 5% and failing
+// beyond 20%.
+func NewConfig(basePath, headPath string) Config {
+	return Config{BasePath: basePath, HeadPath: headPath, WarnThreshold: 0.05, FailThreshold: 0.2}
+}
+
+// Run compares the benchmark results, reports the regressions and adds a
+// comparison table.
+func (c Config) Run(d *danger.T, _ danger.DSL) {
+	base, err := ParseFile(c.BasePath)
+	if err != nil {
+		d.Warn(fmt.Sprintf("Could not compare benchmarks: %s", err), "", 0)
+		return
+	}
+	head, err := ParseFile(c.HeadPath)
+	if err != nil {
+		d.Warn(fmt.Sprintf("Could not compare benchmarks: %s", err), "", 0)
+		return
+	}
+	alpha := c.Alpha
+	if alpha == 0 {
+		alpha = 0.05
+	}
+
+	var deltas []Delta
+	for _, delta := range Compare(base, head, alpha) {
+		if len(c.Units) > 0 && !slices.Contains(c.Units, delta.Unit) {
+			continue
+		}
+		deltas = append(deltas, delta)
+	}
+	if len(deltas) == 0 {
+		return
+	}
+
+	for _, delta := range deltas {
+		if !delta.Regression {
+			continue
+		}
+		msg := fmt.Sprintf("`%s` regressed by %s in %s (p=%.3f).", delta.Benchmark, formatChange(delta.Change), delta.Unit, delta.P)
+		change := math.Abs(delta.Change)
+		switch {
+		case c.FailThreshold > 0 && change > c.FailThreshold:
+			d.Fail(msg, "", 0)
+		case c.WarnThreshold > 0 && change > c.WarnThreshold:
+			d.Warn(msg, "", 0)
+		}
+	}
+	d.Markdown(Markdown(deltas), "", 0)
+}
+
+func formatChange(change float64) string {
+	return fmt.Sprintf("%+.2f%%", change*100)
+}
+
+// Markdown renders deltas as a table. Changes which are not significant are
+// shown as "~", like benchstat does.
+func Markdown(deltas []Delta) string {
+	var sb strings.Builder
+	sb.WriteString("### Benchmarks\n\n| Benchmark | Unit | Base | Head | Change | p |\n| --- | --- | ---: | ---: | ---: | ---: |\n")
+	for _, delta := range deltas {
+		change := "~"
+		if delta.Significant {
+			change = formatChange(delta.Change)
+			if delta.Regression {
+				change += " ⚠️"
+			}
+		}
+		fmt.Fprintf(&sb, "| `%s` | %s | %s | %s | %s | %.3f |\n", delta.Benchmark, delta.Unit,
+			formatValue(delta.Base), formatValue(delta.Head), change, delta.P)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
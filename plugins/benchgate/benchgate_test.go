@@ -0,0 +1,99 @@
+package benchgate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+)
+
+const baseOutput = `goos: linux
+goarch: amd64
+pkg: example.com/m/parser
+cpu: AMD EPYC
+BenchmarkParse-8   	  1000	      1000 ns/op	     512 B/op	       4 allocs/op
+BenchmarkParse-8   	  1000	      1010 ns/op	     512 B/op	       4 allocs/op
+BenchmarkParse-8   	  1000	       990 ns/op	     512 B/op	       4 allocs/op
+BenchmarkParse-8   	  1000	      1005 ns/op	     512 B/op	       4 allocs/op
+BenchmarkParse-8   	  1000	       995 ns/op	     512 B/op	       4 allocs/op
+BenchmarkCopy-8    	   100	     100.0 MB/s
+PASS
+ok  	example.com/m/parser	5.1s
+`
+
+const headOutput = `pkg: example.com/m/parser
+BenchmarkParse-8   	  1000	      1300 ns/op	     512 B/op	       4 allocs/op
+BenchmarkParse-8   	  1000	      1310 ns/op	     512 B/op	       4 allocs/op
+BenchmarkParse-8   	  1000	      1290 ns/op	     512 B/op	       4 allocs/op
+BenchmarkParse-8   	  1000	      1305 ns/op	     512 B/op	       4 allocs/op
+BenchmarkParse-8   	  1000	      1295 ns/op	     512 B/op	       4 allocs/op
+BenchmarkCopy-8    	   100	     120.0 MB/s
+BenchmarkNew-8     	   100	     10 ns/op
+`
+
+func TestParse(t *testing.T) {
+	res, err := Parse(strings.NewReader(baseOutput))
+	require.Nil(t, err)
+	require.Equal(t, []float64{1000, 1010, 990, 1005, 995}, res["example.com/m/parser.BenchmarkParse"]["ns/op"])
+	require.Equal(t, []float64{4, 4, 4, 4, 4}, res["example.com/m/parser.BenchmarkParse"]["allocs/op"])
+	require.Equal(t, []float64{100}, res["example.com/m/parser.BenchmarkCopy"]["MB/s"])
+}
+
+func TestMannWhitneyU(t *testing.T) {
+	// fully separated samples of 5: 2 of the 252 arrangements are as extreme
+	require.InDelta(t, 2.0/252, mannWhitneyU([]float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10}), 1e-9)
+	require.InDelta(t, 0.7, mannWhitneyU([]float64{1, 3, 5}, []float64{2, 4, 6}), 1e-9)
+	require.Equal(t, 1.0, mannWhitneyU([]float64{4, 4, 4}, []float64{4, 4, 4}))
+}
+
+func TestCompare(t *testing.T) {
+	base, err := Parse(strings.NewReader(baseOutput))
+	require.Nil(t, err)
+	head, err := Parse(strings.NewReader(headOutput))
+	require.Nil(t, err)
+
+	deltas := Compare(base, head, 0.05)
+	require.Len(t, deltas, 4)
+
+	copyDelta := deltas[0]
+	require.Equal(t, "MB/s", copyDelta.Unit)
+	require.False(t, copyDelta.Significant, "a single sample is never significant")
+
+	parse := deltas[3]
+	require.Equal(t, "ns/op", parse.Unit)
+	require.Equal(t, 1000.0, parse.Base)
+	require.Equal(t, 1300.0, parse.Head)
+	require.InDelta(t, 0.3, parse.Change, 1e-9)
+	require.True(t, parse.Regression)
+	require.False(t, deltas[1].Significant, "identical allocs/op")
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	basePath, headPath := filepath.Join(dir, "base.txt"), filepath.Join(dir, "head.txt")
+	require.Nil(t, os.WriteFile(basePath, []byte(baseOutput), 0o600))
+	require.Nil(t, os.WriteFile(headPath, []byte(headOutput), 0o600))
+
+	d := danger.New()
+	c := NewConfig(basePath, headPath)
+	c.Units = []string{"ns/op"}
+	c.Run(d, danger.DSL{})
+	r := d.Snapshot()
+	require.Equal(t, []danger.Violation{{Message: "`example.com/m/parser.BenchmarkParse` regressed by +30.00% in ns/op (p=0.008)."}}, r.Fails)
+	require.Equal(t, "### Benchmarks\n\n| Benchmark | Unit | Base | Head | Change | p |\n| --- | --- | ---: | ---: | ---: | ---: |\n"+
+		"| `example.com/m/parser.BenchmarkParse` | ns/op | 1000 | 1300 | +30.00% ⚠️ | 0.008 |", r.Markdowns[0].Message)
+
+	c.FailThreshold = 0
+	d = danger.New()
+	c.Run(d, danger.DSL{})
+	require.Empty(t, d.Snapshot().Fails)
+	require.Len(t, d.Snapshot().Warnings, 1)
+
+	d = danger.New()
+	Config{BasePath: filepath.Join(dir, "missing.txt"), HeadPath: headPath}.Run(d, danger.DSL{})
+	require.Len(t, d.Snapshot().Warnings, 1)
+}
@@ -0,0 +1,272 @@
+// Package buildtime compares the duration of CI jobs of the PR with their
+// previous runs on the base branch, warning when the PR makes builds
+// significantly slower.
+package buildtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	danger "github.com/danger/golang"
+	"github.com/danger/golang/githubclient"
+)
+
+// Timings are job durations by job name.
+type Timings struct {
+	// Current holds the durations of the run of the PR.
+	Current map[string]time.Duration
+	// Previous holds the durations of earlier runs, oldest first.
+	Previous map[string][]time.Duration
+}
+
+// Source provides the timings to compare.
+type Source interface {
+	Timings(ctx context.Context) (Timings, error)
+}
+
+// FileSource reads timings from a JSON file holding durations in seconds:
+//
+//	{"current": {"build": 212.4}, "previous": {"build": [180, 190.5]}}
+type FileSource string
+
+// Timings reads the file.
+func (f FileSource) Timings(context.Context) (Timings, error) {
+	data, err := os.ReadFile(string(f))
+	if err != nil {
+		return Timings{}, fmt.Errorf("reading timings: %w", err)
+	}
+	var raw struct {
+		Current  map[string]float64   `json:"current"`
+		Previous map[string][]float64 `json:"previous"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Timings{}, fmt.Errorf("decoding timings %s: %w", string(f), err)
+	}
+	t := Timings{Current: map[string]time.Duration{}, Previous: map[string][]time.Duration{}}
+	for job, s := range raw.Current {
+		t.Current[job] = seconds(s)
+	}
+	for job, ss := range raw.Previous {
+		for _, s := range ss {
+			t.Previous[job] = append(t.Previous[job], seconds(s))
+		}
+	}
+	return t, nil
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// ActionsSource reads timings from the GitHub Actions API: the completed
+// jobs of the current workflow run, and the same jobs of the last successful
+// runs of the workflow on the base branch.
+type ActionsSource struct {
+	Client *githubclient.Client
+	Owner  string
+	Repo   string
+	// RunID is the current workflow run, GITHUB_RUN_ID on Actions.
+	RunID int64
+	// Branch is the branch whose runs are the baseline.
+	Branch string
+	// Runs is the number of previous runs compared with, 5 when zero.
+	Runs int
+}
+
+// NewActionsSource creates an ActionsSource for the workflow run danger is
+// running in, comparing with the base branch of the PR.
+func NewActionsSource(pr danger.DSL) (ActionsSource, error) {
+	if pr.GitHub == nil || pr.GitHub.ThisPR().Number == 0 {
+		return ActionsSource{}, fmt.Errorf("not running on a GitHub PR")
+	}
+	runID, err := strconv.ParseInt(os.Getenv("GITHUB_RUN_ID"), 10, 64)
+	if err != nil {
+		return ActionsSource{}, fmt.Errorf("not running on GitHub Actions: GITHUB_RUN_ID is not set")
+	}
+	client, err := githubclient.NewFromSettings(pr.Settings)
+	if err != nil {
+		return ActionsSource{}, fmt.Errorf("creating GitHub client: %w", err)
+	}
+	this := pr.GitHub.ThisPR()
+	return ActionsSource{
+		Client: client,
+		Owner:  this.Owner,
+		Repo:   this.Repo,
+		RunID:  runID,
+		Branch: pr.GitHub.PR().Base.Ref,
+	}, nil
+}
+
+// Timings fetches the jobs of the current and previous runs.
+func (a ActionsSource) Timings(ctx context.Context) (Timings, error) {
+	run, err := a.Client.WorkflowRun(ctx, a.Owner, a.Repo, a.RunID)
+	if err != nil {
+		return Timings{}, fmt.Errorf("fetching workflow run: %w", err)
+	}
+	t := Timings{Current: map[string]time.Duration{}, Previous: map[string][]time.Duration{}}
+	jobs, err := a.Client.WorkflowRunJobs(ctx, a.Owner, a.Repo, run.ID)
+	if err != nil {
+		return Timings{}, fmt.Errorf("fetching jobs: %w", err)
+	}
+	for _, j := range jobs {
+		if d := j.Duration(); d > 0 {
+			t.Current[j.Name] = d
+		}
+	}
+
+	n := a.Runs
+	if n == 0 {
+		n = 5
+	}
+	query := url.Values{"branch": {a.Branch}, "status": {"success"}, "per_page": {strconv.Itoa(n)}}
+	runs, err := a.Client.ListWorkflowRuns(ctx, a.Owner, a.Repo, run.WorkflowID, query)
+	if err != nil {
+		return Timings{}, fmt.Errorf("listing previous runs: %w", err)
+	}
+	// runs are listed newest first
+	for i := len(runs) - 1; i >= 0; i-- {
+		jobs, err := a.Client.WorkflowRunJobs(ctx, a.Owner, a.Repo, runs[i].ID)
+		if err != nil {
+			return Timings{}, fmt.Errorf("fetching jobs of run %d: %w", runs[i].ID, err)
+		}
+		for _, j := range jobs {
+			if d := j.Duration(); d > 0 {
+				t.Previous[j.Name] = append(t.Previous[j.Name], d)
+			}
+		}
+	}
+	return t, nil
+}
+
+// Config configures the comparison.
+type Config struct {
+	// Source provides the timings. When nil an ActionsSource is created for
+	// the current workflow run.
+	Source Source
+	// Jobs are the jobs compared, all jobs with previous timings when empty.
+	Jobs []string
+	// WarnIncrease is the relative slowdown over the median of the previous
+	// runs, e.g. 0.2 for 20%, beyond which a warning is added.
+	WarnIncrease float64
+	// MinIncrease ignores slowdowns shorter than it, which are noise for
+	// short jobs.
+	MinIncrease time.Duration
+}
+
+// NewConfig returns a Config warning when a job becomes 20% and at least a
+// minute slower.
+func NewConfig() Config {
+	return Config{WarnIncrease: 0.2, MinIncrease: time.Minute}
+}
+
+// JobTiming is the comparison of one job.
+type JobTiming struct {
+	Job      string
+	Current  time.Duration
+	Median   time.Duration
+	Previous []time.Duration
+}
+
+// Change is the relative change of the job duration, e.g. 0.1 for 10%.
+func (j JobTiming) Change() float64 {
+	if j.Median == 0 {
+		return 0
+	}
+	return float64(j.Current-j.Median) / float64(j.Median)
+}
+
+// Compare returns the comparison of the jobs with both a current and
+// previous timings, sorted by job. When jobs is not empty only those jobs
+// are compared.
+func Compare(t Timings, jobs []string) []JobTiming {
+	var out []JobTiming
+	for job, current := range t.Current {
+		prev := t.Previous[job]
+		if len(prev) == 0 || (len(jobs) > 0 && !slices.Contains(jobs, job)) {
+			continue
+		}
+		out = append(out, JobTiming{Job: job, Current: current, Median: median(prev), Previous: prev})
+	}
+	slices.SortFunc(out, func(a, b JobTiming) int { return strings.Compare(a.Job, b.Job) })
+	return out
+}
+
+func median(ds []time.Duration) time.Duration {
+	s := slices.Clone(ds)
+	slices.Sort(s)
+	n := len(s)
+	if n%2 == 1 {
+		return s[n/2]
+	}
+	return (s[n/2-1] + s[n/2]) / 2
+}
+
+// Run compares the timings, warns about slowdowns and adds a trend table.
+func (c Config) Run(d *danger.T, pr danger.DSL) {
+	src := c.Source
+	if src == nil {
+		a, err := NewActionsSource(pr)
+		if err != nil {
+			d.Warn(fmt.Sprintf("Could not compare build times: %s", err), "", 0)
+			return
+		}
+		src = a
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	t, err := src.Timings(ctx)
+	if err != nil {
+		d.Warn(fmt.Sprintf("Could not compare build times: %s", err), "", 0)
+		return
+	}
+
+	timings := Compare(t, c.Jobs)
+	if len(timings) == 0 {
+		return
+	}
+	for _, j := range timings {
+		if c.WarnIncrease > 0 && j.Change() > c.WarnIncrease && j.Current-j.Median >= c.MinIncrease {
+			d.Warn(fmt.Sprintf("Job `%s` took %s, %+.0f%% over its median of %s on the base branch.",
+				j.Job, formatDuration(j.Current), j.Change()*100, formatDuration(j.Median)), "", 0)
+		}
+	}
+	d.Markdown(Markdown(timings), "", 0)
+}
+
+// Markdown renders timings as a table with a sparkline of the previous runs
+// followed by the current one.
+func Markdown(timings []JobTiming) string {
+	var sb strings.Builder
+	sb.WriteString("### Build times\n\n| Job | This PR | Median | Change | Trend |\n| --- | ---: | ---: | ---: | --- |\n")
+	for _, j := range timings {
+		fmt.Fprintf(&sb, "| %s | %s | %s | %+.0f%% | %s |\n", j.Job, formatDuration(j.Current),
+			formatDuration(j.Median), j.Change()*100, sparkline(append(slices.Clone(j.Previous), j.Current)))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+func sparkline(ds []time.Duration) string {
+	lo, hi := slices.Min(ds), slices.Max(ds)
+	var sb strings.Builder
+	for _, d := range ds {
+		i := 0
+		if hi > lo {
+			i = int(float64(d-lo) / float64(hi-lo) * float64(len(sparks)-1))
+		}
+		sb.WriteRune(sparks[i])
+	}
+	return sb.String()
+}
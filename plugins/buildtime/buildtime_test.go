@@ -0,0 +1,91 @@
+package buildtime
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	"github.com/danger/golang/githubclient"
+)
+
+func TestFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "timings.json")
+	require.Nil(t, os.WriteFile(path, []byte(`{"current":{"build":90.5},"previous":{"build":[60,70]}}`), 0o600))
+
+	got, err := FileSource(path).Timings(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, 90500*time.Millisecond, got.Current["build"])
+	require.Equal(t, []time.Duration{time.Minute, 70 * time.Second}, got.Previous["build"])
+}
+
+func TestActionsSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/repos/o/r/actions/runs/9":
+			_, _ = w.Write([]byte(`{"id":9,"workflow_id":3}`))
+		case "/api/v3/repos/o/r/actions/workflows/3/runs":
+			require.Equal(t, "main", r.URL.Query().Get("branch"))
+			require.Equal(t, "success", r.URL.Query().Get("status"))
+			require.Equal(t, "5", r.URL.Query().Get("per_page"))
+			_, _ = w.Write([]byte(`{"workflow_runs":[{"id":8},{"id":7}]}`))
+		case "/api/v3/repos/o/r/actions/runs/9/jobs":
+			_, _ = w.Write([]byte(`{"jobs":[
+				{"name":"build","started_at":"2024-01-01T00:00:00Z","completed_at":"2024-01-01T00:03:00Z"},
+				{"name":"danger","started_at":"2024-01-01T00:00:00Z","completed_at":null}
+			]}`))
+		case "/api/v3/repos/o/r/actions/runs/8/jobs":
+			_, _ = w.Write([]byte(`{"jobs":[{"name":"build","started_at":"2024-01-01T00:00:00Z","completed_at":"2024-01-01T00:02:00Z"}]}`))
+		case "/api/v3/repos/o/r/actions/runs/7/jobs":
+			_, _ = w.Write([]byte(`{"jobs":[{"name":"build","started_at":"2024-01-01T00:00:00Z","completed_at":"2024-01-01T00:01:00Z"}]}`))
+		default:
+			t.Fatalf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := githubclient.New(srv.URL, "")
+	require.Nil(t, err)
+
+	got, err := ActionsSource{Client: c, Owner: "o", Repo: "r", RunID: 9, Branch: "main"}.Timings(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, map[string]time.Duration{"build": 3 * time.Minute}, got.Current)
+	require.Equal(t, []time.Duration{time.Minute, 2 * time.Minute}, got.Previous["build"], "oldest first")
+}
+
+type fixedSource Timings
+
+func (f fixedSource) Timings(context.Context) (Timings, error) { return Timings(f), nil }
+
+func TestRun(t *testing.T) {
+	src := fixedSource{
+		Current: map[string]time.Duration{"build": 5 * time.Minute, "lint": 40 * time.Second, "new": time.Minute},
+		Previous: map[string][]time.Duration{
+			"build": {3 * time.Minute, 4 * time.Minute, 2 * time.Minute},
+			"lint":  {20 * time.Second, 20 * time.Second},
+		},
+	}
+	c := NewConfig()
+	c.Source = src
+
+	d := danger.New()
+	c.Run(d, danger.DSL{})
+	r := d.Snapshot()
+	require.Equal(t, []danger.Violation{{Message: "Job `build` took 5m0s, +67% over its median of 3m0s on the base branch."}}, r.Warnings,
+		"lint doubled but by less than a minute")
+	require.Equal(t, "### Build times\n\n| Job | This PR | Median | Change | Trend |\n| --- | ---: | ---: | ---: | --- |\n"+
+		"| build | 5m0s | 3m0s | +67% | ▃▅▁█ |\n"+
+		"| lint | 40s | 20s | +100% | ▁▁█ |", r.Markdowns[0].Message)
+}
+
+func TestRunWithoutActions(t *testing.T) {
+	d := danger.New()
+	NewConfig().Run(d, danger.DSL{})
+	require.Equal(t, []danger.Violation{{Message: "Could not compare build times: not running on a GitHub PR"}}, d.Snapshot().Warnings)
+}
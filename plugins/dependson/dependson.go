@@ -0,0 +1,228 @@
+// Package dependson links PRs which depend on PRs in other repositories, e.g.
+// "Depends on org/repo#123" in the PR body, and reports dependencies which
+// aren't merged yet.
+package dependson
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	danger "github.com/danger/golang"
+	"github.com/danger/golang/githubclient"
+	"github.com/danger/golang/gitlabclient"
+)
+
+// DefaultKeywords introduce a dependency in the PR body.
+var DefaultKeywords = []string{"depends on", "blocked by", "requires"}
+
+// Reference is a PR of another, or the same, repository. Repo is the full
+// path of the repository, "owner/repo" on GitHub or "group/sub/project" on
+// GitLab.
+type Reference struct {
+	Repo   string
+	Number int
+}
+
+func (r Reference) String() string {
+	return fmt.Sprintf("%s#%d", r.Repo, r.Number)
+}
+
+// refPattern matches references after a keyword: "#12", "org/repo#12",
+// "group/project!12" or the URL of a GitHub PR or GitLab MR.
+var refPattern = regexp.MustCompile(`^\s*:?\s*(?:` +
+	`https?://[^/\s]+/([\w.-]+(?:/[\w.-]+)+?)(?:/-)?/(?:pull|merge_requests)/(\d+)` +
+	`|([\w.-]+(?:/[\w.-]+)+)?[#!](\d+))`)
+
+// separator splits the references listed on a line.
+var separator = regexp.MustCompile(`\s*(?:,|\band\b)\s*`)
+
+// ParseReferences returns the PRs the body declares dependencies on, once
+// each and in order. Bare "#12" references resolve to repo. keywords are
+// matched case-insensitively at the start of a line, DefaultKeywords when
+// empty.
+func ParseReferences(body, repo string, keywords ...string) []Reference {
+	if len(keywords) == 0 {
+		keywords = DefaultKeywords
+	}
+	var refs []Reference
+	seen := map[Reference]bool{}
+	for _, line := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n") {
+		line = strings.TrimLeft(line, " \t-*>")
+		lower := strings.ToLower(line)
+		for _, k := range keywords {
+			if !strings.HasPrefix(lower, strings.ToLower(k)) {
+				continue
+			}
+			// a line may list several dependencies, "Depends on #1, #2"
+			for _, part := range separator.Split(line[len(k):], -1) {
+				ref, ok := parseReference(part, repo)
+				if ok && !seen[ref] {
+					seen[ref] = true
+					refs = append(refs, ref)
+				}
+			}
+			break
+		}
+	}
+	return refs
+}
+
+func parseReference(s, repo string) (Reference, bool) {
+	m := refPattern.FindStringSubmatch(s)
+	if m == nil {
+		return Reference{}, false
+	}
+	if m[1] != "" {
+		n, _ := strconv.Atoi(m[2])
+		return Reference{Repo: m[1], Number: n}, true
+	}
+	n, _ := strconv.Atoi(m[4])
+	if m[3] != "" {
+		repo = m[3]
+	}
+	return Reference{Repo: repo, Number: n}, true
+}
+
+// Status is the state of a referenced PR.
+type Status struct {
+	Reference
+	Title  string
+	URL    string
+	Open   bool
+	Merged bool
+}
+
+// Fetcher fetches the state of referenced PRs.
+type Fetcher interface {
+	Status(ctx context.Context, ref Reference) (Status, error)
+}
+
+// NewFetcher creates a Fetcher for the platform of the PR.
+func NewFetcher(pr danger.DSL) (Fetcher, error) {
+	if pr.GitHub != nil && pr.GitHub.PR().Number != 0 {
+		client, err := githubclient.NewFromSettings(pr.Settings)
+		if err != nil {
+			return nil, err
+		}
+		return gitHubFetcher{client: client}, nil
+	}
+	if pr.GitLab != nil && pr.GitLab.MR().IID != 0 {
+		client, err := gitlabclient.NewFromSettings(pr.Settings)
+		if err != nil {
+			return nil, err
+		}
+		return gitLabFetcher{client: client}, nil
+	}
+	return nil, fmt.Errorf("dependent PRs require a GitHub or GitLab PR")
+}
+
+type gitHubFetcher struct {
+	client *githubclient.Client
+}
+
+func (f gitHubFetcher) Status(ctx context.Context, ref Reference) (Status, error) {
+	owner, repo, ok := strings.Cut(ref.Repo, "/")
+	if !ok {
+		return Status{}, fmt.Errorf("invalid repository %q", ref.Repo)
+	}
+	pr, err := f.client.PullRequest(ctx, owner, repo, ref.Number)
+	if err != nil {
+		return Status{}, err
+	}
+	return Status{Reference: ref, Title: pr.Title, URL: pr.HTMLURL, Open: pr.State == "open", Merged: pr.Merged}, nil
+}
+
+type gitLabFetcher struct {
+	client *gitlabclient.Client
+}
+
+func (f gitLabFetcher) Status(ctx context.Context, ref Reference) (Status, error) {
+	mr, err := f.client.MR(ctx, ref.Repo, int64(ref.Number))
+	if err != nil {
+		return Status{}, err
+	}
+	return Status{Reference: ref, Title: mr.Title, URL: mr.WebURL, Open: mr.State == "opened", Merged: mr.State == "merged"}, nil
+}
+
+// Config configures Run.
+type Config struct {
+	// Keywords introduce a dependency, DefaultKeywords when empty.
+	Keywords []string
+	// Warn reports unmerged dependencies as warnings instead of failures.
+	Warn bool
+	// Fetcher fetches PR states, created from the environment when nil.
+	Fetcher Fetcher
+}
+
+// Run lists the dependencies of the PR and reports the unmerged ones.
+func (c Config) Run(d *danger.T, pr danger.DSL) {
+	body, repo := description(pr)
+	refs := ParseReferences(body, repo, c.Keywords...)
+	if len(refs) == 0 {
+		return
+	}
+
+	f := c.Fetcher
+	if f == nil {
+		var err error
+		if f, err = NewFetcher(pr); err != nil {
+			d.Warn(fmt.Sprintf("Dependent PRs: %s", err), "", 0)
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var sb strings.Builder
+	sb.WriteString("### Dependencies\n\n| PR | State |\n| --- | --- |\n")
+	for _, ref := range refs {
+		s, err := f.Status(ctx, ref)
+		if err != nil {
+			d.Warn(fmt.Sprintf("Could not fetch dependency %s: %s", ref, err), "", 0)
+			continue
+		}
+		state := "merged"
+		switch {
+		case s.Merged:
+		case s.Open:
+			state = "open"
+			c.report(d, fmt.Sprintf("This PR depends on %s, which is not merged yet", link(s)))
+		default:
+			state = "closed"
+			c.report(d, fmt.Sprintf("This PR depends on %s, which was closed without merging", link(s)))
+		}
+		fmt.Fprintf(&sb, "| %s | %s |\n", link(s), state)
+	}
+	d.Markdown(sb.String(), "", 0)
+}
+
+func (c Config) report(d *danger.T, message string) {
+	if c.Warn {
+		d.Warn(message, "", 0)
+		return
+	}
+	d.Fail(message, "", 0)
+}
+
+func link(s Status) string {
+	if s.URL == "" {
+		return s.Reference.String()
+	}
+	return fmt.Sprintf("[%s](%s)", s.Reference, s.URL)
+}
+
+// description returns the PR body and the full path of its repository.
+func description(pr danger.DSL) (string, string) {
+	switch {
+	case pr.GitHub != nil && pr.GitHub.PR().Number != 0:
+		p := pr.GitHub.PR()
+		return p.Body, p.Base.Repo.FullName
+	case pr.GitLab != nil && pr.GitLab.MR().IID != 0:
+		return pr.GitLab.MR().Description, pr.GitLab.Metadata().RepoSlug
+	}
+	return "", ""
+}
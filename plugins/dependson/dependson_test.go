@@ -0,0 +1,109 @@
+package dependson
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+func TestParseReferences(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     string
+		keywords []string
+		expected []Reference
+	}{
+		{
+			name: "none",
+			body: "Fixes #3",
+		},
+		{
+			name:     "cross repo",
+			body:     "Some change.\n\nDepends on org/api#123",
+			expected: []Reference{{Repo: "org/api", Number: 123}},
+		},
+		{
+			name:     "same repo",
+			body:     "- Blocked by: #7",
+			expected: []Reference{{Repo: "org/web", Number: 7}},
+		},
+		{
+			name: "several and urls",
+			body: "depends on https://github.com/org/api/pull/5, org/sdk#2 and #5\r\n" +
+				"Requires https://gitlab.com/group/sub/project/-/merge_requests/9\n" +
+				"Depends on org/api#5",
+			expected: []Reference{
+				{Repo: "org/api", Number: 5},
+				{Repo: "org/sdk", Number: 2},
+				{Repo: "org/web", Number: 5},
+				{Repo: "group/sub/project", Number: 9},
+			},
+		},
+		{
+			name:     "gitlab",
+			body:     "Depends on group/project!12",
+			expected: []Reference{{Repo: "group/project", Number: 12}},
+		},
+		{
+			name:     "custom keywords",
+			body:     "Depends on #1\nNeeds org/api#2",
+			keywords: []string{"needs"},
+			expected: []Reference{{Repo: "org/api", Number: 2}},
+		},
+		{
+			name: "keyword mid sentence",
+			body: "This depends on org/api#1",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, ParseReferences(tc.body, "org/web", tc.keywords...))
+		})
+	}
+}
+
+type fakeFetcher map[Reference]Status
+
+func (f fakeFetcher) Status(_ context.Context, ref Reference) (Status, error) {
+	s, ok := f[ref]
+	if !ok {
+		return Status{}, errors.New("not found")
+	}
+	s.Reference = ref
+	return s, nil
+}
+
+func TestRun(t *testing.T) {
+	pr := dangerJs.GitHubPR{Number: 1, Body: "Depends on org/api#1, org/api#2, org/api#3 and org/api#4"}
+	pr.Base.Repo.FullName = "org/web"
+	fetcher := fakeFetcher{
+		{Repo: "org/api", Number: 1}: {Merged: true, URL: "https://github.com/org/api/pull/1"},
+		{Repo: "org/api", Number: 2}: {Open: true},
+		{Repo: "org/api", Number: 3}: {},
+	}
+
+	d := danger.New()
+	Config{Fetcher: fetcher}.Run(d, danger.DSL{GitHub: fakedsl.GitHub{PRData: pr}})
+
+	r := d.Snapshot()
+	require.Len(t, r.Fails, 2)
+	require.Equal(t, "This PR depends on org/api#2, which is not merged yet", r.Fails[0].Message)
+	require.Equal(t, "This PR depends on org/api#3, which was closed without merging", r.Fails[1].Message)
+	require.Len(t, r.Warnings, 1)
+	require.Equal(t, "Could not fetch dependency org/api#4: not found", r.Warnings[0].Message)
+	require.Equal(t, "### Dependencies\n\n| PR | State |\n| --- | --- |\n"+
+		"| [org/api#1](https://github.com/org/api/pull/1) | merged |\n"+
+		"| org/api#2 | open |\n"+
+		"| org/api#3 | closed |\n", r.Markdowns[0].Message)
+
+	d = danger.New()
+	Config{Fetcher: fetcher, Warn: true}.Run(d, danger.DSL{GitHub: fakedsl.GitHub{PRData: pr}})
+	require.Empty(t, d.Snapshot().Fails)
+	require.Len(t, d.Snapshot().Warnings, 3)
+}
@@ -0,0 +1,291 @@
+// Package monorepo finds the projects of a monorepo a PR affects, from Go
+// workspaces, package.json workspaces or a map of project directories, and
+// summarizes them in the Danger comment, e.g. "Affected: api, billing, web".
+// Rules can be limited to PRs affecting given projects with OnlyIfAffected.
+package monorepo
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// Project is a project of the monorepo. Dir is relative to the repository
+// root, "." for the root itself.
+type Project struct {
+	Name string
+	Dir  string
+}
+
+// ErrNoWorkspace is returned by Detect when the repository has neither a
+// go.work file nor package.json workspaces.
+var ErrNoWorkspace = errors.New("no go.work or package.json workspaces found")
+
+// Detect reads the projects of the go.work file at root, or else of the
+// workspaces of its package.json.
+func Detect(root string) ([]Project, error) {
+	projects, err := GoWorkProjects(root)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return projects, err
+	}
+	projects, err = NPMWorkspaceProjects(root)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && len(projects) == 0) {
+		return nil, ErrNoWorkspace
+	}
+	return projects, err
+}
+
+// GoWorkProjects reads the modules of the go.work file at root, named after
+// their directory.
+func GoWorkProjects(root string) ([]Project, error) {
+	f, err := os.Open(filepath.Join(root, "go.work"))
+	if err != nil {
+		return nil, fmt.Errorf("reading go.work: %w", err)
+	}
+	defer f.Close()
+
+	var projects []Project
+	inUse := false
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "//")
+		line = strings.TrimSpace(line)
+		switch {
+		case inUse && line == ")":
+			inUse = false
+		case inUse && line != "":
+			projects = append(projects, dirProject(line))
+		case line == "use (":
+			inUse = true
+		case strings.HasPrefix(line, "use "):
+			projects = append(projects, dirProject(strings.TrimSpace(strings.TrimPrefix(line, "use "))))
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading go.work: %w", err)
+	}
+	return projects, nil
+}
+
+func dirProject(dir string) Project {
+	dir = path.Clean(dangerJs.NormalizePath(strings.Trim(dir, `"`)))
+	name := path.Base(dir)
+	if dir == "." {
+		name = "root"
+	}
+	return Project{Name: name, Dir: dir}
+}
+
+// NPMWorkspaceProjects reads the workspaces of the package.json at root,
+// expanding their globs, and names them after their package name.
+func NPMWorkspaceProjects(root string) ([]Project, error) {
+	var manifest struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if err := readJSON(filepath.Join(root, "package.json"), &manifest); err != nil {
+		return nil, err
+	}
+	var globs []string
+	if len(manifest.Workspaces) > 0 && json.Unmarshal(manifest.Workspaces, &globs) != nil {
+		// yarn also accepts {"packages": [...]}
+		var nested struct {
+			Packages []string `json:"packages"`
+		}
+		if err := json.Unmarshal(manifest.Workspaces, &nested); err != nil {
+			return nil, fmt.Errorf("parsing package.json workspaces: %w", err)
+		}
+		globs = nested.Packages
+	}
+
+	var dirs, excluded []string
+	for _, g := range globs {
+		if negated, ok := strings.CutPrefix(g, "!"); ok {
+			excluded = append(excluded, path.Clean(negated))
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(g)))
+		if err != nil {
+			return nil, fmt.Errorf("expanding workspace %s: %w", g, err)
+		}
+		for _, m := range matches {
+			rel, err := filepath.Rel(root, m)
+			if err != nil {
+				continue
+			}
+			dirs = append(dirs, filepath.ToSlash(rel))
+		}
+	}
+	slices.Sort(dirs)
+
+	var projects []Project
+	for _, dir := range slices.Compact(dirs) {
+		if slices.ContainsFunc(excluded, func(g string) bool { return dangerJs.MatchPath(g, dir) }) {
+			continue
+		}
+		var pkg struct {
+			Name string `json:"name"`
+		}
+		if err := readJSON(filepath.Join(root, filepath.FromSlash(dir), "package.json"), &pkg); err != nil {
+			// not a package, e.g. a file matched by the glob
+			continue
+		}
+		p := dirProject(dir)
+		if pkg.Name != "" {
+			p.Name = pkg.Name
+		}
+		projects = append(projects, p)
+	}
+	return projects, nil
+}
+
+func readJSON(file string, v any) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", filepath.Base(file), err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parsing %s: %w", filepath.Base(file), err)
+	}
+	return nil
+}
+
+// Affected returns the names of the projects containing files, sorted. A file
+// belongs to the project with the deepest directory containing it.
+func Affected(projects []Project, files []string) []string {
+	var names []string
+	for _, f := range files {
+		f = dangerJs.NormalizePath(f)
+		best := -1
+		for i, p := range projects {
+			if p.Dir != "." && !strings.HasPrefix(f, p.Dir+"/") {
+				continue
+			}
+			if best < 0 || len(p.Dir) > len(projects[best].Dir) || projects[best].Dir == "." {
+				best = i
+			}
+		}
+		if best >= 0 && !slices.Contains(names, projects[best].Name) {
+			names = append(names, projects[best].Name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Config configures how the projects of the monorepo are found.
+type Config struct {
+	// Root is the directory of the repository checkout, the working
+	// directory when empty.
+	Root string
+	// Paths maps project names to their directory, relative to the
+	// repository root. When empty the projects are detected from go.work or
+	// package.json workspaces.
+	Paths map[string]string
+	// Global are globs of files affecting every project when changed, e.g.
+	// "go.work" or ".github/workflows/**".
+	Global []string
+}
+
+// Projects returns the projects of Paths, or those detected at Root.
+func (c Config) Projects() ([]Project, error) {
+	if len(c.Paths) == 0 {
+		root := c.Root
+		if root == "" {
+			root = "."
+		}
+		return Detect(root)
+	}
+	projects := make([]Project, 0, len(c.Paths))
+	for name, dir := range c.Paths {
+		p := dirProject(dir)
+		p.Name = name
+		projects = append(projects, p)
+	}
+	slices.SortFunc(projects, func(a, b Project) int { return strings.Compare(a.Name, b.Name) })
+	return projects, nil
+}
+
+// Result are the projects a PR affects.
+type Result struct {
+	// Affected are the names of the affected projects, sorted.
+	Affected []string
+	// Total is the number of projects of the monorepo.
+	Total int
+	// Global is the first changed file affecting every project, if any.
+	Global string
+}
+
+// Affected returns the projects affected by the created, modified and deleted
+// files of the PR, e.g. to gate CI jobs.
+func (c Config) Affected(pr danger.DSL) (Result, error) {
+	projects, err := c.Projects()
+	if err != nil {
+		return Result{}, err
+	}
+	var files []string
+	for _, list := range [][]string{pr.Git.CreatedFiles(), pr.Git.ModifiedFiles(), pr.Git.DeletedFiles()} {
+		files = append(files, list...)
+	}
+	r := Result{Total: len(projects)}
+	for _, f := range files {
+		if slices.ContainsFunc(c.Global, func(g string) bool { return dangerJs.MatchPath(g, f) }) {
+			r.Global = dangerJs.NormalizePath(f)
+			for _, p := range projects {
+				r.Affected = append(r.Affected, p.Name)
+			}
+			slices.Sort(r.Affected)
+			return r, nil
+		}
+	}
+	r.Affected = Affected(projects, files)
+	return r, nil
+}
+
+// Run posts the affected projects in the Danger comment.
+func (c Config) Run(d *danger.T, pr danger.DSL) {
+	r, err := c.Affected(pr)
+	if err != nil {
+		d.Warn(fmt.Sprintf("Could not find the affected projects: %s", err), "", 0)
+		return
+	}
+	switch {
+	case len(r.Affected) == 0:
+		d.Markdown(fmt.Sprintf("**Affected:** none of the %d projects", r.Total), "", 0)
+	case r.Global != "":
+		d.Markdown(fmt.Sprintf("**Affected:** all %d projects, `%s` changed", r.Total, r.Global), "", 0)
+	default:
+		d.Markdown(fmt.Sprintf("**Affected:** %s (%d of %d projects)", strings.Join(r.Affected, ", "), len(r.Affected), r.Total), "", 0)
+	}
+}
+
+// OnlyIfAffected runs a rule only when the PR affects any of the projects,
+// e.g. a rule requiring API docs for OnlyIfAffected("api").
+func (c Config) OnlyIfAffected(projects ...string) danger.Condition {
+	return func(pr danger.DSL) (bool, string) {
+		r, err := c.Affected(pr)
+		if err != nil {
+			// run rather than silently skip when the projects are unknown
+			return true, ""
+		}
+		for _, name := range projects {
+			if slices.Contains(r.Affected, name) {
+				return true, ""
+			}
+		}
+		quoted := make([]string, len(projects))
+		for i, name := range projects {
+			quoted[i] = "`" + name + "`"
+		}
+		return false, "does not affect " + strings.Join(quoted, " or ")
+	}
+}
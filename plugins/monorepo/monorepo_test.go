@@ -0,0 +1,145 @@
+package monorepo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	"github.com/danger/golang/fakedsl"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		require.Nil(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.Nil(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+	return root
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []Project
+	}{
+		{
+			name: "go.work",
+			files: map[string]string{
+				"go.work": "go 1.22\n\nuse (\n\t./services/api // public API\n\t./services/billing\n)\nuse ./web\n",
+			},
+			want: []Project{
+				{Name: "api", Dir: "services/api"},
+				{Name: "billing", Dir: "services/billing"},
+				{Name: "web", Dir: "web"},
+			},
+		},
+		{
+			name: "package.json workspaces",
+			files: map[string]string{
+				"package.json":                 `{"private": true, "workspaces": ["packages/*", "!packages/legacy"]}`,
+				"packages/ui/package.json":     `{"name": "@acme/ui"}`,
+				"packages/utils/package.json":  `{}`,
+				"packages/legacy/package.json": `{"name": "legacy"}`,
+				"packages/README.md":           "not a package",
+			},
+			want: []Project{
+				{Name: "@acme/ui", Dir: "packages/ui"},
+				{Name: "utils", Dir: "packages/utils"},
+			},
+		},
+		{
+			name: "yarn workspaces object",
+			files: map[string]string{
+				"package.json":          `{"workspaces": {"packages": ["apps/*"]}}`,
+				"apps/web/package.json": `{"name": "web"}`,
+			},
+			want: []Project{{Name: "web", Dir: "apps/web"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Detect(writeFiles(t, tt.files))
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := Detect(t.TempDir())
+	require.ErrorIs(t, err, ErrNoWorkspace)
+}
+
+func TestAffected(t *testing.T) {
+	projects := []Project{
+		{Name: "root", Dir: "."},
+		{Name: "api", Dir: "services/api"},
+		{Name: "api-client", Dir: "services/api/client"},
+		{Name: "web", Dir: "web"},
+	}
+	tests := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{name: "deepest project", files: []string{"services/api/client/client.go", "web/index.ts"}, want: []string{"api-client", "web"}},
+		{name: "root project", files: []string{"README.md", "services/api/main.go"}, want: []string{"api", "root"}},
+		{name: "prefix is not a directory", files: []string{"webapp/x.go"}, want: []string{"root"}},
+		{name: "no files", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Affected(projects, tt.files))
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	c := Config{
+		Paths:  map[string]string{"api": "services/api", "billing": "services/billing", "web": "web"},
+		Global: []string{".github/workflows/**"},
+	}
+	tests := []struct {
+		name string
+		git  fakedsl.Git
+		want string
+	}{
+		{
+			name: "some projects",
+			git:  fakedsl.Git{CreatedFilesList: []string{"web/app.ts"}, DeletedFilesList: []string{"services/api/old.go"}},
+			want: "**Affected:** api, web (2 of 3 projects)",
+		},
+		{
+			name: "global file",
+			git:  fakedsl.Git{ModifiedFilesList: []string{".github/workflows/ci.yml"}},
+			want: "**Affected:** all 3 projects, `.github/workflows/ci.yml` changed",
+		},
+		{
+			name: "outside projects",
+			git:  fakedsl.Git{ModifiedFilesList: []string{"docs/index.md"}},
+			want: "**Affected:** none of the 3 projects",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := danger.New()
+			c.Run(d, danger.DSL{Git: tt.git})
+			require.Equal(t, []danger.Violation{{Message: tt.want}}, d.Snapshot().Markdowns)
+		})
+	}
+}
+
+func TestOnlyIfAffected(t *testing.T) {
+	c := Config{Paths: map[string]string{"api": "services/api", "web": "web"}}
+	pr := danger.DSL{Git: fakedsl.Git{ModifiedFilesList: []string{"web/app.ts"}}}
+
+	run, _ := c.OnlyIfAffected("web")(pr)
+	require.True(t, run)
+	run, reason := c.OnlyIfAffected("api")(pr)
+	require.False(t, run)
+	require.Equal(t, "does not affect `api`", reason)
+}
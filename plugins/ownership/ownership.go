@@ -0,0 +1,229 @@
+// Package ownership reports who owns the code a PR touches: the CODEOWNERS
+// owners of the changed files and the authors of the changed lines, with a
+// bus factor, so reviewers know whom to involve.
+package ownership
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// Config configures the ownership report.
+type Config struct {
+	// BaseRef and HeadRef select the refs to diff and blame, HEAD^ and HEAD
+	// by default. CODEOWNERS is read from the base ref.
+	BaseRef string
+	HeadRef string
+	// MaxRows limits the rows of each table, 10 when zero.
+	MaxRows int
+}
+
+// NewConfig returns a Config with the default limits.
+func NewConfig() Config {
+	return Config{MaxRows: 10}
+}
+
+func (c Config) refs() (string, string) {
+	base, head := c.BaseRef, c.HeadRef
+	if base == "" {
+		base = "HEAD^"
+	}
+	if head == "" {
+		head = "HEAD"
+	}
+	return base, head
+}
+
+// Share is the part of the changed code attributed to an owner or author.
+type Share struct {
+	Name  string
+	Files int
+	Lines int
+}
+
+// Report is the ownership of the code touched by a PR.
+type Report struct {
+	// Owners are the CODEOWNERS owners weighted by the lines changed in
+	// their files, most lines first.
+	Owners []Share
+	// Lines is the number of lines the PR changes.
+	Lines int
+	// Unowned is the number of changed lines in files without an owner.
+	Unowned int
+	// Authors are the authors of the lines the PR changes or removes, from
+	// blame at the base ref, most lines first.
+	Authors []Share
+	// HasCodeOwners is false when the repository has no CODEOWNERS file.
+	HasCodeOwners bool
+}
+
+// BusFactor is the smallest number of authors who wrote at least half of the
+// changed lines.
+func (r Report) BusFactor() int {
+	total := 0
+	for _, a := range r.Authors {
+		total += a.Lines
+	}
+	covered := 0
+	for i, a := range r.Authors {
+		covered += a.Lines
+		if 2*covered >= total {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// tally accumulates shares by key.
+type tally struct {
+	shares map[string]*Share
+	files  map[string]map[string]bool
+}
+
+func newTally() *tally {
+	return &tally{shares: map[string]*Share{}, files: map[string]map[string]bool{}}
+}
+
+func (t *tally) add(key, name, file string, lines int) {
+	s, ok := t.shares[key]
+	if !ok {
+		s = &Share{Name: name}
+		t.shares[key] = s
+		t.files[key] = map[string]bool{}
+	}
+	s.Lines += lines
+	if !t.files[key][file] {
+		t.files[key][file] = true
+		s.Files++
+	}
+}
+
+func (t *tally) sorted() []Share {
+	out := make([]Share, 0, len(t.shares))
+	for _, s := range t.shares {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Lines != out[j].Lines {
+			return out[i].Lines > out[j].Lines
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// Compute builds the ownership report of the PR.
+func Compute(pr danger.DSL, c Config) (Report, error) {
+	base, head := c.refs()
+	codeOwners, hasCodeOwners := dangerJs.LoadCodeOwners(pr.Git, base)
+	r := Report{HasCodeOwners: hasCodeOwners}
+
+	owners, authors := newTally(), newTally()
+	files := append(append(append([]string{}, pr.Git.ModifiedFiles()...), pr.Git.CreatedFiles()...), pr.Git.DeletedFiles()...)
+	for _, file := range files {
+		diff, err := pr.Git.DiffForFileWithRefs(file, base, head)
+		if err != nil {
+			return Report{}, err
+		}
+		changed := len(diff.AddedLines) + len(diff.RemovedLines)
+		if changed == 0 {
+			continue
+		}
+		r.Lines += changed
+		if fileOwners := codeOwners.Owners(file); len(fileOwners) > 0 {
+			for _, o := range fileOwners {
+				owners.add(strings.ToLower(o), o, file, changed)
+			}
+		} else {
+			r.Unowned += changed
+		}
+
+		if len(diff.RemovedLines) == 0 {
+			continue
+		}
+		removed := map[int]bool{}
+		for _, l := range diff.RemovedLines {
+			removed[l.Line] = true
+		}
+		blame, err := dangerJs.Blame(pr.Git, file, base)
+		if err != nil {
+			return Report{}, err
+		}
+		for _, l := range blame {
+			if removed[l.Line] {
+				authors.add(strings.ToLower(l.AuthorEmail), l.AuthorName, file, 1)
+			}
+		}
+	}
+	r.Owners = owners.sorted()
+	r.Authors = authors.sorted()
+	return r, nil
+}
+
+// heat renders the share of lines as a bar of ten blocks.
+func heat(lines, total int) string {
+	n := 0
+	if total > 0 {
+		n = (lines*10 + total - 1) / total
+	}
+	return strings.Repeat("█", n) + strings.Repeat("░", 10-n)
+}
+
+// Markdown renders the report as tables of at most maxRows rows.
+func (r Report) Markdown(maxRows int) string {
+	if maxRows <= 0 {
+		maxRows = 10
+	}
+	var sb strings.Builder
+	sb.WriteString("### Code ownership\n\n")
+
+	if r.HasCodeOwners {
+		total := r.Lines
+		sb.WriteString("| Owner | Files | Lines changed | |\n| --- | --- | --- | --- |\n")
+		for i, o := range r.Owners {
+			if i == maxRows {
+				fmt.Fprintf(&sb, "| … %d more | | | |\n", len(r.Owners)-maxRows)
+				break
+			}
+			fmt.Fprintf(&sb, "| %s | %d | %d | %s |\n", o.Name, o.Files, o.Lines, heat(o.Lines, total))
+		}
+		if r.Unowned > 0 {
+			fmt.Fprintf(&sb, "| _no owner_ | | %d | %s |\n", r.Unowned, heat(r.Unowned, total))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(r.Authors) > 0 {
+		total := 0
+		for _, a := range r.Authors {
+			total += a.Lines
+		}
+		sb.WriteString("| Author of changed lines | Files | Lines | |\n| --- | --- | --- | --- |\n")
+		for i, a := range r.Authors {
+			if i == maxRows {
+				fmt.Fprintf(&sb, "| … %d more | | | |\n", len(r.Authors)-maxRows)
+				break
+			}
+			fmt.Fprintf(&sb, "| %s | %d | %d | %s |\n", a.Name, a.Files, a.Lines, heat(a.Lines, total))
+		}
+		fmt.Fprintf(&sb, "\nBus factor: **%d**\n", r.BusFactor())
+	}
+	return sb.String()
+}
+
+// Run adds the ownership report to the Danger comment.
+func (c Config) Run(d *danger.T, pr danger.DSL) {
+	r, err := Compute(pr, c)
+	if err != nil {
+		d.Warn(fmt.Sprintf("Could not compute code ownership: %s", err), "", 0)
+		return
+	}
+	if len(r.Owners) == 0 && len(r.Authors) == 0 {
+		return
+	}
+	d.Markdown(r.Markdown(c.MaxRows), "", 0)
+}
@@ -0,0 +1,85 @@
+package ownership
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+func lines(numbers ...int) []dangerJs.DiffLine {
+	var out []dangerJs.DiffLine
+	for _, n := range numbers {
+		out = append(out, dangerJs.DiffLine{Line: n})
+	}
+	return out
+}
+
+func blame(authors ...string) []dangerJs.BlameLine {
+	var out []dangerJs.BlameLine
+	for i, a := range authors {
+		out = append(out, dangerJs.BlameLine{Line: i + 1, AuthorName: a, AuthorEmail: a + "@example.com"})
+	}
+	return out
+}
+
+func testGit() fakedsl.Git {
+	return fakedsl.Git{
+		ModifiedFilesList: []string{"api/server.go", "README.md"},
+		CreatedFilesList:  []string{"api/handler.go"},
+		Diffs: map[string]dangerJs.FileDiff{
+			"api/server.go":  {AddedLines: lines(1, 2), RemovedLines: lines(1, 2, 3)},
+			"README.md":      {RemovedLines: lines(4)},
+			"api/handler.go": {AddedLines: lines(1, 2, 3, 4, 5)},
+		},
+		Blames: map[string][]dangerJs.BlameLine{
+			"api/server.go": blame("ann", "bob", "ann", "cy"),
+			"README.md":     blame("dee", "dee", "dee", "bob"),
+		},
+		Files: map[string]string{".github/CODEOWNERS": "/api/ @org/backend @ann\n"},
+	}
+}
+
+func TestCompute(t *testing.T) {
+	r, err := Compute(danger.DSL{Git: testGit()}, NewConfig())
+	require.Nil(t, err)
+	require.True(t, r.HasCodeOwners)
+	require.Equal(t, []Share{
+		{Name: "@ann", Files: 2, Lines: 10},
+		{Name: "@org/backend", Files: 2, Lines: 10},
+	}, r.Owners)
+	require.Equal(t, 1, r.Unowned)
+	require.Equal(t, []Share{
+		{Name: "ann", Files: 1, Lines: 2},
+		{Name: "bob", Files: 2, Lines: 2},
+	}, r.Authors)
+	require.Equal(t, 1, r.BusFactor())
+}
+
+func TestBusFactor(t *testing.T) {
+	require.Equal(t, 0, Report{}.BusFactor())
+	require.Equal(t, 2, Report{Authors: []Share{{Lines: 3}, {Lines: 3}, {Lines: 2}, {Lines: 2}}}.BusFactor())
+}
+
+func TestRun(t *testing.T) {
+	d := danger.New()
+	NewConfig().Run(d, danger.DSL{Git: testGit()})
+	r := d.Snapshot()
+	require.Len(t, r.Markdowns, 1)
+	require.Equal(t, "### Code ownership\n\n"+
+		"| Owner | Files | Lines changed | |\n| --- | --- | --- | --- |\n"+
+		"| @ann | 2 | 10 | ██████████ |\n"+
+		"| @org/backend | 2 | 10 | ██████████ |\n"+
+		"| _no owner_ | | 1 | █░░░░░░░░░ |\n\n"+
+		"| Author of changed lines | Files | Lines | |\n| --- | --- | --- | --- |\n"+
+		"| ann | 1 | 2 | █████░░░░░ |\n"+
+		"| bob | 2 | 2 | █████░░░░░ |\n"+
+		"\nBus factor: **1**\n", r.Markdowns[0].Message)
+
+	d = danger.New()
+	Config{MaxRows: 1}.Run(d, danger.DSL{Git: fakedsl.Git{}})
+	require.Empty(t, d.Snapshot().Markdowns)
+}
@@ -0,0 +1,207 @@
+// Package prsummary summarizes a PR from static analysis alone: the files
+// changed by directory, the changes to the exported Go API and the
+// migrations touched. It works offline, without any AI provider.
+package prsummary
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/plugins/semver"
+)
+
+// Config configures the summary.
+type Config struct {
+	// BaseRef and HeadRef select the refs the API is compared between,
+	// HEAD^ and HEAD by default.
+	BaseRef string
+	HeadRef string
+	// Depth is the number of leading path segments files are grouped by, 1
+	// when zero.
+	Depth int
+	// MigrationPaths are the patterns of database migrations, see
+	// dangerJs.MatchPath.
+	MigrationPaths []string
+	// Section, when set, also writes the summary to the managed section of
+	// the GitHub PR description with that name, see danger.ReplaceSection.
+	Section string
+}
+
+// NewConfig returns a Config grouping files by their top two directories
+// and recognizing common migration layouts.
+func NewConfig() Config {
+	return Config{
+		Depth:          2,
+		MigrationPaths: []string{"**/migrations/**", "**/migration/**", "db/migrate/**"},
+	}
+}
+
+// DirChanges counts the files changed in a directory.
+type DirChanges struct {
+	Dir      string
+	Added    int
+	Modified int
+	Deleted  int
+}
+
+// Migration is a migration file touched by the PR.
+type Migration struct {
+	File   string
+	Change string // "added" | "modified" | "deleted"
+}
+
+// Summary is the summary of a PR.
+type Summary struct {
+	Dirs       []DirChanges
+	APIChanges []semver.APIChange
+	Migrations []Migration
+}
+
+// Files returns the number of changed files.
+func (s Summary) Files() int {
+	n := 0
+	for _, d := range s.Dirs {
+		n += d.Added + d.Modified + d.Deleted
+	}
+	return n
+}
+
+// Summarize analyzes the changes of the PR.
+func Summarize(pr danger.DSL, c Config) (Summary, error) {
+	depth := c.Depth
+	if depth == 0 {
+		depth = 1
+	}
+	var s Summary
+	dirs := map[string]*DirChanges{}
+	for _, group := range []struct {
+		files  []string
+		change string
+	}{
+		{pr.Git.CreatedFiles(), "added"},
+		{pr.Git.ModifiedFiles(), "modified"},
+		{pr.Git.DeletedFiles(), "deleted"},
+	} {
+		for _, f := range group.files {
+			f = dangerJs.NormalizePath(f)
+			dir := groupDir(f, depth)
+			dc, ok := dirs[dir]
+			if !ok {
+				dc = &DirChanges{Dir: dir}
+				dirs[dir] = dc
+			}
+			switch group.change {
+			case "added":
+				dc.Added++
+			case "modified":
+				dc.Modified++
+			default:
+				dc.Deleted++
+			}
+			for _, p := range c.MigrationPaths {
+				if dangerJs.MatchPath(p, f) {
+					s.Migrations = append(s.Migrations, Migration{File: f, Change: group.change})
+					break
+				}
+			}
+		}
+	}
+	for _, dc := range dirs {
+		s.Dirs = append(s.Dirs, *dc)
+	}
+	sort.Slice(s.Dirs, func(i, j int) bool { return s.Dirs[i].Dir < s.Dirs[j].Dir })
+	sort.Slice(s.Migrations, func(i, j int) bool { return s.Migrations[i].File < s.Migrations[j].File })
+
+	api := semver.NewConfig()
+	api.BaseRef, api.HeadRef = c.BaseRef, c.HeadRef
+	changes, err := semver.APIChanges(pr, api)
+	if err != nil {
+		return s, fmt.Errorf("comparing the Go API: %w", err)
+	}
+	s.APIChanges = changes
+	return s, nil
+}
+
+// groupDir returns the first depth directories of file, "." for files at
+// the root.
+func groupDir(file string, depth int) string {
+	dir := path.Dir(file)
+	if dir == "." {
+		return "."
+	}
+	parts := strings.Split(dir, "/")
+	if len(parts) > depth {
+		parts = parts[:depth]
+	}
+	return strings.Join(parts, "/")
+}
+
+// Markdown renders the summary.
+func (s Summary) Markdown() string {
+	var added, modified, deleted int
+	for _, d := range s.Dirs {
+		added, modified, deleted = added+d.Added, modified+d.Modified, deleted+d.Deleted
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### PR summary\n\n**%s changed** in %s: %d added, %d modified, %d deleted.\n",
+		plural(s.Files(), "file"), plural(len(s.Dirs), "directory"), added, modified, deleted)
+	if len(s.Dirs) > 0 {
+		sb.WriteString("\n| Directory | Added | Modified | Deleted |\n| --- | ---: | ---: | ---: |\n")
+		for _, d := range s.Dirs {
+			fmt.Fprintf(&sb, "| `%s` | %d | %d | %d |\n", d.Dir, d.Added, d.Modified, d.Deleted)
+		}
+	}
+	if len(s.APIChanges) > 0 {
+		sb.WriteString("\n#### API changes\n\n")
+		for _, ch := range s.APIChanges {
+			icon := "➕"
+			if !ch.Compatible {
+				icon = "⚠️"
+			}
+			fmt.Fprintf(&sb, "- %s `%s`: `%s` %s\n", icon, ch.Package, ch.Name, ch.Message)
+		}
+	}
+	if len(s.Migrations) > 0 {
+		sb.WriteString("\n#### Migrations\n\n")
+		for _, m := range s.Migrations {
+			fmt.Fprintf(&sb, "- `%s` (%s)\n", m.File, m.Change)
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	if strings.HasSuffix(noun, "y") {
+		return fmt.Sprintf("%d %sies", n, strings.TrimSuffix(noun, "y"))
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// Run adds the summary to the Danger comment and, with c.Section set, to the
+// PR description.
+func (c Config) Run(d *danger.T, pr danger.DSL) {
+	s, err := Summarize(pr, c)
+	if err != nil {
+		d.Warn(fmt.Sprintf("Could not summarize the PR: %s", err), "", 0)
+		return
+	}
+	md := s.Markdown()
+	d.Markdown(md, "", 0)
+	if c.Section == "" {
+		return
+	}
+	ops, err := danger.NewGitHubOps(pr)
+	if err == nil {
+		err = ops.UpdatePRSection(c.Section, md)
+	}
+	if err != nil {
+		d.Warn(fmt.Sprintf("Could not add the summary to the PR description: %s", err), "", 0)
+	}
+}
@@ -0,0 +1,46 @@
+package prsummary
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	"github.com/danger/golang/fakedsl"
+)
+
+func TestRun(t *testing.T) {
+	pr := danger.DSL{Git: fakedsl.Git{
+		CreatedFilesList:  []string{"db/migrations/0002_users.sql", "pkg/store/users.go"},
+		ModifiedFilesList: []string{"pkg/store/store.go", "README.md", "pkg/store/store_test.go"},
+		DeletedFilesList:  []string{"cmd/tool/legacy.go"},
+		Files: map[string]string{
+			"HEAD^:pkg/store/store.go": "package store\n\nfunc Open(path string) error { return nil }\n",
+			"HEAD:pkg/store/store.go":  "package store\n\nfunc Open(path string, ro bool) error { return nil }\n",
+			"HEAD:pkg/store/users.go":  "package store\n\nfunc Users() []string { return nil }\n",
+			"HEAD^:cmd/tool/legacy.go": "package main\n\nfunc main() {}\n",
+		},
+	}}
+
+	d := danger.New()
+	NewConfig().Run(d, pr)
+	r := d.Snapshot()
+	require.Empty(t, r.Warnings)
+	require.Equal(t, "### PR summary\n\n**6 files changed** in 4 directories: 2 added, 3 modified, 1 deleted.\n\n"+
+		"| Directory | Added | Modified | Deleted |\n| --- | ---: | ---: | ---: |\n"+
+		"| `.` | 0 | 1 | 0 |\n"+
+		"| `cmd/tool` | 0 | 0 | 1 |\n"+
+		"| `db/migrations` | 1 | 0 | 0 |\n"+
+		"| `pkg/store` | 1 | 2 | 0 |\n"+
+		"\n#### API changes\n\n"+
+		"- ⚠️ `pkg/store`: `func Open` changed from `func(path string) error` to `func(path string, ro bool) error`\n"+
+		"- ➕ `pkg/store`: `func Users` added\n"+
+		"\n#### Migrations\n\n- `db/migrations/0002_users.sql` (added)", r.Markdowns[0].Message)
+}
+
+func TestGroupDir(t *testing.T) {
+	require.Equal(t, ".", groupDir("go.mod", 2))
+	require.Equal(t, "a", groupDir("a/b.go", 2))
+	require.Equal(t, "a/b", groupDir("a/b/c/d.go", 2))
+	require.Equal(t, "a", groupDir("a/b/c/d.go", 1))
+}
@@ -0,0 +1,226 @@
+// Package releasenotes drafts the release notes entry of a PR from its title,
+// labels and changelog, so releases can be cut from the merged PRs. Titles
+// following conventional commits are grouped by type.
+package releasenotes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// OtherSection is the section of PRs whose title isn't a conventional commit.
+const OtherSection = "Other Changes"
+
+// DefaultCategories maps conventional commit types to release notes sections.
+var DefaultCategories = map[string]string{
+	"feat":     "Features",
+	"fix":      "Bug Fixes",
+	"perf":     "Performance",
+	"refactor": "Refactoring",
+	"docs":     "Documentation",
+	"revert":   "Reverts",
+}
+
+// Config configures how the draft is built and where it is written.
+type Config struct {
+	// Categories maps conventional commit types to sections. PRs with a type
+	// missing from it, e.g. chore or ci, are left out of the release notes.
+	Categories map[string]string
+	// LabelCategories maps labels to sections and takes precedence over the
+	// title's type.
+	LabelCategories map[string]string
+	// SkipLabels leave the PR out of the release notes.
+	SkipLabels []string
+	// ChangelogFile is diffed for added list items, which are used as the
+	// entries when the PR body has no release-note block.
+	ChangelogFile string
+	// FragmentDir, when set, makes Run write the draft to <dir>/<number>.md
+	// for CI to commit, instead of adding it to the Danger comment.
+	FragmentDir string
+}
+
+// NewConfig returns a Config using DefaultCategories and CHANGELOG.md.
+func NewConfig() Config {
+	return Config{
+		Categories:    DefaultCategories,
+		SkipLabels:    []string{"skip-release-notes", "no-release-notes"},
+		ChangelogFile: "CHANGELOG.md",
+	}
+}
+
+// Note is the release notes entry of a PR.
+type Note struct {
+	Section  string
+	Breaking bool
+	Entries  []string
+	Number   int
+	URL      string
+	Author   string
+}
+
+// Markdown renders the note as a release notes fragment.
+func (n Note) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### %s\n\n", n.Section)
+	ref := ""
+	if n.Number != 0 {
+		ref = fmt.Sprintf(" (#%d", n.Number)
+		if n.Author != "" {
+			ref += " by @" + n.Author
+		}
+		ref += ")"
+	}
+	for _, e := range n.Entries {
+		if n.Breaking {
+			e = "**Breaking:** " + e
+		}
+		fmt.Fprintf(&sb, "- %s%s\n", e, ref)
+	}
+	return sb.String()
+}
+
+// prInfo is the platform independent part of the PR the draft is built from.
+type prInfo struct {
+	title, body, url, author string
+	number                   int
+	labels                   []string
+}
+
+func info(pr danger.DSL) prInfo {
+	switch {
+	case pr.GitHub != nil && pr.GitHub.PR().Title != "":
+		p := pr.GitHub.PR()
+		i := prInfo{title: p.Title, body: p.Body, url: p.HTMLURL, author: p.User.Login, number: p.Number}
+		for _, l := range pr.GitHub.Issue().Labels {
+			i.labels = append(i.labels, l.Name)
+		}
+		return i
+	case pr.GitLab != nil && pr.GitLab.MR().Title != "":
+		mr := pr.GitLab.MR()
+		return prInfo{
+			title: mr.Title, body: mr.Description, url: mr.WebURL, author: mr.Author.Username,
+			number: int(mr.IID), labels: mr.Labels,
+		}
+	}
+	return prInfo{}
+}
+
+var releaseNoteBlockRe = regexp.MustCompile("(?s)```release-note\\s*\\n(.*?)```")
+
+// Draft builds the release notes entry of the PR. The second return value is
+// false when the PR is left out of the release notes.
+func Draft(pr danger.DSL, c Config) (Note, bool, error) {
+	i := info(pr)
+	for _, l := range i.labels {
+		for _, skip := range c.SkipLabels {
+			if strings.EqualFold(l, skip) {
+				return Note{}, false, nil
+			}
+		}
+	}
+
+	n := Note{Number: i.number, URL: i.url, Author: i.author}
+	description := strings.TrimSpace(i.title)
+	if cc, ok := dangerJs.ParseConventionalCommit(i.title + "\n\n" + i.body); ok {
+		section, known := c.Categories[cc.Type]
+		if !known {
+			return Note{}, false, nil
+		}
+		n.Section, n.Breaking = section, cc.Breaking
+		r := []rune(cc.Description)
+		r[0] = unicode.ToUpper(r[0])
+		description = string(r)
+	} else {
+		n.Section = OtherSection
+	}
+	for _, l := range i.labels {
+		if section, ok := c.LabelCategories[l]; ok {
+			n.Section = section
+			break
+		}
+	}
+
+	if m := releaseNoteBlockRe.FindStringSubmatch(i.body); m != nil {
+		block := strings.TrimSpace(m[1])
+		if strings.EqualFold(block, "none") {
+			return Note{}, false, nil
+		}
+		n.Entries = listItems(strings.Split(block, "\n"))
+		return n, true, nil
+	}
+
+	if c.ChangelogFile != "" && changed(pr, c.ChangelogFile) {
+		diff, err := pr.Git.DiffForFile(c.ChangelogFile)
+		if err != nil {
+			return Note{}, false, fmt.Errorf("diffing %s: %w", c.ChangelogFile, err)
+		}
+		lines := make([]string, 0, len(diff.AddedLines))
+		for _, l := range diff.AddedLines {
+			if item := strings.TrimSpace(l.Content); strings.HasPrefix(item, "- ") || strings.HasPrefix(item, "* ") {
+				lines = append(lines, item)
+			}
+		}
+		n.Entries = listItems(lines)
+	}
+	if len(n.Entries) == 0 {
+		n.Entries = []string{description}
+	}
+	return n, true, nil
+}
+
+// listItems strips list markers and drops empty lines.
+func listItems(lines []string) []string {
+	var items []string
+	for _, l := range lines {
+		l = strings.TrimSpace(l)
+		l = strings.TrimSpace(strings.TrimLeft(l, "-*"))
+		if l != "" {
+			items = append(items, l)
+		}
+	}
+	return items
+}
+
+func changed(pr danger.DSL, file string) bool {
+	for _, f := range append(pr.Git.CreatedFiles(), pr.Git.ModifiedFiles()...) {
+		if f == file {
+			return true
+		}
+	}
+	return false
+}
+
+// Run adds the draft to the Danger comment, or writes it to FragmentDir.
+func (c Config) Run(d *danger.T, pr danger.DSL) {
+	n, ok, err := Draft(pr, c)
+	if err != nil {
+		d.Warn(fmt.Sprintf("Release notes: %s", err), "", 0)
+		return
+	}
+	if !ok {
+		return
+	}
+	if c.FragmentDir == "" {
+		d.Markdown("## Release notes draft\n\n"+n.Markdown(), "", 0)
+		return
+	}
+
+	name := fmt.Sprintf("%d.md", n.Number)
+	if err := os.MkdirAll(c.FragmentDir, 0o755); err != nil {
+		d.Warn(fmt.Sprintf("Release notes: creating fragment directory: %s", err), "", 0)
+		return
+	}
+	path := filepath.Join(c.FragmentDir, name)
+	if err := os.WriteFile(path, []byte(n.Markdown()), 0o644); err != nil {
+		d.Warn(fmt.Sprintf("Release notes: writing fragment: %s", err), "", 0)
+		return
+	}
+	d.Message(fmt.Sprintf("Release notes fragment written to `%s`", filepath.ToSlash(path)), "", 0)
+}
@@ -0,0 +1,129 @@
+package releasenotes
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+func testPR(title, body string, labels ...string) danger.DSL {
+	return danger.DSL{
+		Git: fakedsl.Git{},
+		GitHub: fakedsl.GitHub{
+			PRData:    dangerJs.GitHubPR{Number: 42, Title: title, Body: body, User: dangerJs.GitHubUser{Login: "octocat"}},
+			IssueData: fakedsl.Labeled(labels...),
+		},
+	}
+}
+
+func TestDraft(t *testing.T) {
+	tests := []struct {
+		name   string
+		pr     danger.DSL
+		want   Note
+		wantOK bool
+	}{
+		{
+			name:   "conventional feature",
+			pr:     testPR("feat(ui): add dark mode", ""),
+			want:   Note{Section: "Features", Entries: []string{"Add dark mode"}, Number: 42, Author: "octocat"},
+			wantOK: true,
+		},
+		{
+			name:   "breaking fix",
+			pr:     testPR("fix!: reject empty names", ""),
+			want:   Note{Section: "Bug Fixes", Breaking: true, Entries: []string{"Reject empty names"}, Number: 42, Author: "octocat"},
+			wantOK: true,
+		},
+		{
+			name: "unlisted type is skipped",
+			pr:   testPR("chore: bump tools", ""),
+		},
+		{
+			name:   "plain title",
+			pr:     testPR("Improve logging", ""),
+			want:   Note{Section: OtherSection, Entries: []string{"Improve logging"}, Number: 42, Author: "octocat"},
+			wantOK: true,
+		},
+		{
+			name: "skip label",
+			pr:   testPR("feat: secret", "", "skip-release-notes"),
+		},
+		{
+			name:   "release-note block",
+			pr:     testPR("feat: x", "Details\n\n```release-note\n- First entry\n- Second entry\n```\n"),
+			want:   Note{Section: "Features", Entries: []string{"First entry", "Second entry"}, Number: 42, Author: "octocat"},
+			wantOK: true,
+		},
+		{
+			name: "release-note none",
+			pr:   testPR("feat: x", "```release-note\nNONE\n```"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := Draft(tt.pr, NewConfig())
+			require.NoError(t, err)
+			require.Equal(t, tt.wantOK, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDraftLabelsAndChangelog(t *testing.T) {
+	pr := testPR("Tweak retries", "", "bug")
+	pr.Git = fakedsl.Git{
+		ModifiedFilesList: []string{"CHANGELOG.md"},
+		Diffs: map[string]dangerJs.FileDiff{"CHANGELOG.md": {AddedLines: []dangerJs.DiffLine{
+			{Content: "## Unreleased"},
+			{Content: "- Retry on 502 responses"},
+			{Content: "* Back off exponentially"},
+		}}},
+	}
+	c := NewConfig()
+	c.LabelCategories = map[string]string{"bug": "Bug Fixes"}
+
+	got, ok, err := Draft(pr, c)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "Bug Fixes", got.Section)
+	require.Equal(t, []string{"Retry on 502 responses", "Back off exponentially"}, got.Entries)
+	require.Equal(t, "### Bug Fixes\n\n"+
+		"- Retry on 502 responses (#42 by @octocat)\n"+
+		"- Back off exponentially (#42 by @octocat)\n", got.Markdown())
+}
+
+func TestRun(t *testing.T) {
+	d := danger.New()
+	NewConfig().Run(d, testPR("feat!: new API", ""))
+	r := results(t, d)
+	require.Len(t, r.Markdowns, 1)
+	require.Equal(t, "## Release notes draft\n\n### Features\n\n- **Breaking:** New API (#42 by @octocat)\n", r.Markdowns[0].Message)
+
+	dir := t.TempDir()
+	c := NewConfig()
+	c.FragmentDir = filepath.Join(dir, "changelog.d")
+	d = danger.New()
+	c.Run(d, testPR("fix: typo", ""))
+
+	content, err := os.ReadFile(filepath.Join(c.FragmentDir, "42.md"))
+	require.NoError(t, err)
+	require.Equal(t, "### Bug Fixes\n\n- Typo (#42 by @octocat)\n", string(content))
+	require.Len(t, results(t, d).Messages, 1)
+}
+
+func results(t *testing.T, d *danger.T) danger.Results {
+	t.Helper()
+	s, err := d.Results()
+	require.Nil(t, err)
+	var r danger.Results
+	require.Nil(t, json.Unmarshal([]byte(s), &r))
+	return r
+}
@@ -0,0 +1,323 @@
+// Package reviewload reports how many open reviews each candidate reviewer
+// has and suggests, or requests, reviewers for the PR with an assignment
+// Strategy, so reviews are spread across the team.
+package reviewload
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/githubclient"
+	"github.com/danger/golang/gitlabclient"
+)
+
+// Source counts the open PRs awaiting a review of a reviewer.
+type Source interface {
+	OpenReviews(ctx context.Context, reviewer string) (int, error)
+}
+
+// Assigner requests reviews of the PR.
+type Assigner interface {
+	RequestReviewers(ctx context.Context, reviewers []string) error
+}
+
+// Candidate is a reviewer the PR can be assigned to.
+type Candidate struct {
+	Reviewer string
+	// Open is the number of open PRs awaiting their review.
+	Open int
+	// Owned is the number of changed files CODEOWNERS assigns to them.
+	Owned int
+}
+
+// Strategy picks up to n reviewers among the candidates, which are sorted
+// by name.
+type Strategy interface {
+	Pick(pr danger.DSL, candidates []Candidate, n int) []string
+}
+
+// RoundRobin rotates through the candidates by PR number, ignoring load.
+type RoundRobin struct{}
+
+// Pick returns the n candidates following the PR number.
+func (RoundRobin) Pick(pr danger.DSL, candidates []Candidate, n int) []string {
+	if len(candidates) == 0 {
+		return nil
+	}
+	start := prNumber(pr) % len(candidates)
+	var out []string
+	for i := 0; i < len(candidates) && len(out) < n; i++ {
+		out = append(out, candidates[(start+i)%len(candidates)].Reviewer)
+	}
+	return out
+}
+
+// LeastLoaded picks the candidates with the fewest open reviews.
+type LeastLoaded struct{}
+
+// Pick returns the n least loaded candidates.
+func (LeastLoaded) Pick(_ danger.DSL, candidates []Candidate, n int) []string {
+	return pickBy(candidates, n, func(c Candidate) float64 { return float64(c.Open) })
+}
+
+// CodeOwnerWeighted prefers candidates owning more of the changed files,
+// divided by their open reviews plus one, so busy owners yield to less
+// loaded ones.
+type CodeOwnerWeighted struct{}
+
+// Pick returns the n candidates with the most owned files per review.
+func (CodeOwnerWeighted) Pick(_ danger.DSL, candidates []Candidate, n int) []string {
+	return pickBy(candidates, n, func(c Candidate) float64 { return -float64(c.Owned) / float64(c.Open+1) })
+}
+
+// pickBy returns the names of the n candidates with the lowest cost.
+func pickBy(candidates []Candidate, n int, cost func(Candidate) float64) []string {
+	sorted := append([]Candidate{}, candidates...)
+	sort.SliceStable(sorted, func(i, j int) bool { return cost(sorted[i]) < cost(sorted[j]) })
+	var out []string
+	for i := 0; i < len(sorted) && i < n; i++ {
+		out = append(out, sorted[i].Reviewer)
+	}
+	return out
+}
+
+// Config configures the report and the assignment.
+type Config struct {
+	// Reviewers are the candidate logins. The PR author is never picked.
+	Reviewers []string
+	// Count is the number of reviewers the PR needs, including the ones
+	// already requested.
+	Count    int
+	Strategy Strategy
+	// Assign requests reviews from the picked reviewers instead of only
+	// suggesting them. It is ignored in fork-PR safe mode.
+	Assign bool
+	// Source defaults to searching the open PRs of the repository on GitHub
+	// or GitLab. Assigner defaults to the GitHub API.
+	Source   Source
+	Assigner Assigner
+}
+
+// NewConfig returns a Config picking one least loaded reviewer.
+func NewConfig(reviewers ...string) Config {
+	return Config{Reviewers: reviewers, Count: 1, Strategy: LeastLoaded{}}
+}
+
+// Candidates returns the candidates of the PR with their load, excluding
+// its author.
+func (c Config) Candidates(ctx context.Context, pr danger.DSL) ([]Candidate, error) {
+	src := c.Source
+	if src == nil {
+		var err error
+		if src, err = sourceFor(pr); err != nil {
+			return nil, err
+		}
+	}
+	owned := ownedFiles(pr)
+	author := strings.ToLower(prAuthor(pr))
+	var out []Candidate
+	for _, r := range c.Reviewers {
+		if strings.ToLower(r) == author {
+			continue
+		}
+		open, err := src.OpenReviews(ctx, r)
+		if err != nil {
+			return nil, fmt.Errorf("counting open reviews of %s: %w", r, err)
+		}
+		out = append(out, Candidate{Reviewer: r, Open: open, Owned: owned[strings.ToLower(r)]})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Reviewer < out[j].Reviewer })
+	return out, nil
+}
+
+// Run reports the review load of the candidates and suggests or requests
+// reviewers for the PR.
+func (c Config) Run(d *danger.T, pr danger.DSL) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	candidates, err := c.Candidates(ctx, pr)
+	if err != nil {
+		d.Warn(fmt.Sprintf("Review load: %s", err), "", 0)
+		return
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	requested := requestedReviewers(pr)
+	var pool []Candidate
+	for _, cand := range candidates {
+		if !slices.Contains(requested, strings.ToLower(cand.Reviewer)) {
+			pool = append(pool, cand)
+		}
+	}
+	var picked []string
+	if n := c.Count - len(requested); n > 0 {
+		strategy := c.Strategy
+		if strategy == nil {
+			strategy = LeastLoaded{}
+		}
+		picked = strategy.Pick(pr, pool, n)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("### Review load\n\n| Reviewer | Open reviews |\n| --- | --- |\n")
+	for _, cand := range candidates {
+		fmt.Fprintf(&sb, "| @%s | %d |\n", cand.Reviewer, cand.Open)
+	}
+	if len(picked) > 0 {
+		action := "Suggested reviewers"
+		if c.Assign && !dangerJs.SafeMode() {
+			if err := c.assign(ctx, pr, picked); err != nil {
+				d.Warn(fmt.Sprintf("Review load: requesting reviewers: %s", err), "", 0)
+			} else {
+				action = "Requested reviewers"
+			}
+		}
+		fmt.Fprintf(&sb, "\n%s: %s\n", action, mentions(picked))
+	}
+	d.Markdown(sb.String(), "", 0)
+}
+
+func (c Config) assign(ctx context.Context, pr danger.DSL, reviewers []string) error {
+	a := c.Assigner
+	if a == nil {
+		if pr.GitHub == nil || pr.GitHub.PR().Number == 0 {
+			return fmt.Errorf("requesting reviewers requires a GitHub PR")
+		}
+		client, err := githubclient.NewFromSettings(pr.Settings)
+		if err != nil {
+			return err
+		}
+		this := pr.GitHub.ThisPR()
+		a = gitHubAssigner{client: client, owner: this.Owner, repo: this.Repo, number: this.Number}
+	}
+	return a.RequestReviewers(ctx, reviewers)
+}
+
+func mentions(logins []string) string {
+	out := make([]string, len(logins))
+	for i, l := range logins {
+		out[i] = "@" + l
+	}
+	return strings.Join(out, ", ")
+}
+
+// ownedFiles counts the changed files owned by each CODEOWNERS user, by
+// lower-cased login.
+func ownedFiles(pr danger.DSL) map[string]int {
+	owned := map[string]int{}
+	if pr.Git == nil {
+		return owned
+	}
+	owners, ok := dangerJs.LoadCodeOwners(pr.Git, "HEAD")
+	if !ok {
+		return owned
+	}
+	files := append(append([]string{}, pr.Git.ModifiedFiles()...), pr.Git.CreatedFiles()...)
+	for _, f := range files {
+		for _, o := range owners.Owners(f) {
+			if strings.HasPrefix(o, "@") && !strings.Contains(o, "/") {
+				owned[strings.ToLower(strings.TrimPrefix(o, "@"))]++
+			}
+		}
+	}
+	return owned
+}
+
+func prNumber(pr danger.DSL) int {
+	switch {
+	case pr.GitHub != nil && pr.GitHub.PR().Number != 0:
+		return pr.GitHub.PR().Number
+	case pr.GitLab != nil:
+		return int(pr.GitLab.MR().IID)
+	}
+	return 0
+}
+
+func prAuthor(pr danger.DSL) string {
+	switch {
+	case pr.GitHub != nil && pr.GitHub.PR().User.Login != "":
+		return pr.GitHub.PR().User.Login
+	case pr.GitLab != nil:
+		return pr.GitLab.MR().Author.Username
+	}
+	return ""
+}
+
+// requestedReviewers returns the lower-cased logins whose review the PR
+// already requested.
+func requestedReviewers(pr danger.DSL) []string {
+	var out []string
+	switch {
+	case pr.GitHub != nil:
+		for _, u := range pr.GitHub.RequestedReviewers().Users {
+			out = append(out, strings.ToLower(u.Login))
+		}
+	case pr.GitLab != nil:
+		for _, u := range pr.GitLab.MR().Reviewers {
+			out = append(out, strings.ToLower(u.Username))
+		}
+	}
+	return out
+}
+
+func sourceFor(pr danger.DSL) (Source, error) {
+	if pr.GitHub != nil && pr.GitHub.PR().Number != 0 {
+		client, err := githubclient.NewFromSettings(pr.Settings)
+		if err != nil {
+			return nil, err
+		}
+		this := pr.GitHub.ThisPR()
+		return gitHubSource{client: client, scope: "repo:" + this.Owner + "/" + this.Repo}, nil
+	}
+	if pr.GitLab != nil && pr.GitLab.MR().IID != 0 {
+		client, err := gitlabclient.NewFromSettings(pr.Settings)
+		if err != nil {
+			return nil, err
+		}
+		return gitLabSource{client: client, project: pr.GitLab.Metadata().RepoSlug}, nil
+	}
+	return nil, fmt.Errorf("review load requires a GitHub or GitLab PR")
+}
+
+type gitHubSource struct {
+	client *githubclient.Client
+	scope  string
+}
+
+func (s gitHubSource) OpenReviews(ctx context.Context, reviewer string) (int, error) {
+	return s.client.SearchIssuesCount(ctx, fmt.Sprintf("is:pr is:open review-requested:%s %s", reviewer, s.scope))
+}
+
+type gitLabSource struct {
+	client  *gitlabclient.Client
+	project string
+}
+
+func (s gitLabSource) OpenReviews(ctx context.Context, reviewer string) (int, error) {
+	mrs, err := s.client.ListMRs(ctx, s.project, url.Values{
+		"state":             {"opened"},
+		"reviewer_username": {reviewer},
+		"per_page":          {"100"},
+	})
+	return len(mrs), err
+}
+
+type gitHubAssigner struct {
+	client *githubclient.Client
+	owner  string
+	repo   string
+	number int
+}
+
+func (a gitHubAssigner) RequestReviewers(ctx context.Context, reviewers []string) error {
+	return a.client.RequestReviewers(ctx, a.owner, a.repo, a.number, reviewers...)
+}
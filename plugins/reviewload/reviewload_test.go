@@ -0,0 +1,108 @@
+package reviewload
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+type fakeSource map[string]int
+
+func (s fakeSource) OpenReviews(_ context.Context, reviewer string) (int, error) {
+	return s[reviewer], nil
+}
+
+type fakeAssigner struct {
+	reviewers *[]string
+}
+
+func (a fakeAssigner) RequestReviewers(_ context.Context, reviewers []string) error {
+	*a.reviewers = append(*a.reviewers, reviewers...)
+	return nil
+}
+
+func TestStrategies(t *testing.T) {
+	candidates := []Candidate{
+		{Reviewer: "alice", Open: 4, Owned: 3},
+		{Reviewer: "bob", Open: 0, Owned: 0},
+		{Reviewer: "carol", Open: 1, Owned: 2},
+	}
+	pr := danger.DSL{GitHub: fakedsl.GitHub{PRData: dangerJs.GitHubPR{Number: 5}}}
+	tests := []struct {
+		name     string
+		strategy Strategy
+		want     []string
+	}{
+		{name: "round robin", strategy: RoundRobin{}, want: []string{"carol", "alice"}},
+		{name: "least loaded", strategy: LeastLoaded{}, want: []string{"bob", "carol"}},
+		{name: "code owner weighted", strategy: CodeOwnerWeighted{}, want: []string{"carol", "alice"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.strategy.Pick(pr, candidates, 2))
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Setenv(dangerJs.SafeModeEnv, "false")
+	git := fakedsl.Git{
+		ModifiedFilesList: []string{"api/server.go", "docs/README.md"},
+		Files:             map[string]string{".github/CODEOWNERS": "api/ @carol\ndocs/ @dave\n"},
+	}
+	src := fakeSource{"alice": 2, "bob": 5, "carol": 3, "dave": 1}
+	tests := []struct {
+		name      string
+		config    Config
+		requested []string
+		want      string
+		assigned  []string
+	}{
+		{
+			name:   "suggest",
+			config: NewConfig("alice", "bob", "carol", "erin"),
+			want: "### Review load\n\n| Reviewer | Open reviews |\n| --- | --- |\n| @alice | 2 |\n| @bob | 5 |\n| @carol | 3 |\n" +
+				"\nSuggested reviewers: @alice\n",
+		},
+		{
+			name:     "assign by ownership",
+			config:   Config{Reviewers: []string{"alice", "carol", "dave"}, Count: 2, Strategy: CodeOwnerWeighted{}, Assign: true},
+			want:     "### Review load\n\n| Reviewer | Open reviews |\n| --- | --- |\n| @alice | 2 |\n| @carol | 3 |\n| @dave | 1 |\n\nRequested reviewers: @dave, @carol\n",
+			assigned: []string{"dave", "carol"},
+		},
+		{
+			name:      "already requested",
+			config:    NewConfig("alice", "bob"),
+			requested: []string{"Bob"},
+			want:      "### Review load\n\n| Reviewer | Open reviews |\n| --- | --- |\n| @alice | 2 |\n| @bob | 5 |\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var assigned []string
+			tt.config.Source = src
+			tt.config.Assigner = fakeAssigner{reviewers: &assigned}
+			pr := danger.DSL{Git: git, GitHub: fakedsl.GitHub{
+				PRData:                 dangerJs.GitHubPR{Number: 1, User: dangerJs.GitHubUser{Login: "erin"}},
+				RequestedReviewersData: fakedsl.Reviewers(tt.requested...),
+			}}
+			d := danger.New()
+			tt.config.Run(d, pr)
+
+			s, err := d.Results()
+			require.Nil(t, err)
+			var r danger.Results
+			require.Nil(t, json.Unmarshal([]byte(s), &r))
+			require.Empty(t, r.Warnings)
+			require.Len(t, r.Markdowns, 1)
+			require.Equal(t, tt.want, r.Markdowns[0].Message)
+			require.Equal(t, tt.assigned, assigned)
+		})
+	}
+}
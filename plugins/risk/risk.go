@@ -0,0 +1,265 @@
+// Package risk computes a risk score for a PR from its churn, the criticality
+// of the files it touches, the bug history of those files and how familiar
+// the authors are with the code they changed.
+package risk
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// DefaultFixPattern matches commit messages of bug fixes.
+var DefaultFixPattern = regexp.MustCompile(`(?i)\b(fix(es|ed)?|bug|hotfix|revert)\b`)
+
+// Weights controls how much each factor contributes to the score. They are
+// normalised so only their ratios matter.
+type Weights struct {
+	Churn         float64
+	Criticality   float64
+	BugDensity    float64
+	Unfamiliarity float64
+}
+
+// DefaultWeights favours churn and criticality over history.
+var DefaultWeights = Weights{Churn: 0.3, Criticality: 0.3, BugDensity: 0.2, Unfamiliarity: 0.2}
+
+// Config configures the risk score and the policy applied to risky PRs.
+type Config struct {
+	// BaseRef and HeadRef select the refs to diff and blame, HEAD^ and HEAD
+	// by default.
+	BaseRef string
+	HeadRef string
+	// Criticality maps path patterns to a weight between 0 and 1, e.g.
+	// {"migrations/*": 1, "**/auth": 0.8}, see dangerJs.MatchPath. Files
+	// matching no pattern have a weight of 0.
+	Criticality map[string]float64
+	// ChurnCap is the number of changed lines considered maximum churn.
+	ChurnCap int
+	// FixPattern identifies bug-fix commits, DefaultFixPattern when nil.
+	FixPattern *regexp.Regexp
+	// HistoryDepth is the number of commits per file inspected for bug
+	// density, read at BaseRef so the commits of the PR don't count.
+	HistoryDepth int
+	Weights      Weights
+
+	// Threshold is the score from which RequiredApprovals applies.
+	Threshold float64
+	// RequiredApprovals is the number of approving reviews needed for PRs
+	// scoring at or above Threshold. Zero disables the requirement.
+	RequiredApprovals int
+	// FailOnMissingApprovals fails instead of warning when a risky PR does
+	// not have enough approvals.
+	FailOnMissingApprovals bool
+}
+
+// NewConfig returns a Config with default weights and limits.
+func NewConfig() Config {
+	return Config{
+		ChurnCap:     1000,
+		HistoryDepth: 100,
+		Weights:      DefaultWeights,
+		Threshold:    70,
+	}
+}
+
+// FileRisk is the contribution of one file to the score.
+type FileRisk struct {
+	File        string
+	Churn       int
+	Criticality float64
+	BugDensity  float64
+}
+
+// Score is the computed risk of a PR. Factors are between 0 and 1 and Total
+// is between 0 and 100.
+type Score struct {
+	Total         float64
+	Churn         float64
+	Criticality   float64
+	BugDensity    float64
+	Unfamiliarity float64
+	Files         []FileRisk
+}
+
+// Level returns "low", "medium" or "high".
+func (s Score) Level() string {
+	switch {
+	case s.Total >= 60:
+		return "high"
+	case s.Total >= 30:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// Badge returns a markdown badge image showing the score.
+func (s Score) Badge() string {
+	color := map[string]string{"low": "green", "medium": "yellow", "high": "red"}[s.Level()]
+	label := url.PathEscape(fmt.Sprintf("%s (%.0f)", s.Level(), s.Total))
+	return fmt.Sprintf("![PR risk: %s](https://img.shields.io/badge/risk-%s-%s)", s.Level(), label, color)
+}
+
+func (c Config) refs() (string, string) {
+	base, head := c.BaseRef, c.HeadRef
+	if base == "" {
+		base = "HEAD^"
+	}
+	if head == "" {
+		head = "HEAD"
+	}
+	return base, head
+}
+
+// criticalityOf returns the highest weight of the patterns matching the file
+// or one of its parent directories.
+func (c Config) criticalityOf(file string) float64 {
+	weight := 0.0
+	for pattern, w := range c.Criticality {
+		for p := file; p != "." && p != "/"; p = path.Dir(p) {
+			if dangerJs.MatchPath(pattern, p) {
+				weight = math.Max(weight, w)
+				break
+			}
+		}
+	}
+	return math.Min(weight, 1)
+}
+
+// Compute calculates the risk score of the PR.
+func Compute(pr danger.DSL, c Config) (Score, error) {
+	base, head := c.refs()
+	fixPattern := c.FixPattern
+	if fixPattern == nil {
+		fixPattern = DefaultFixPattern
+	}
+	churnCap := c.ChurnCap
+	if churnCap <= 0 {
+		churnCap = 1000
+	}
+
+	authors := map[string]bool{}
+	for _, commit := range pr.Git.Commits() {
+		authors[strings.ToLower(commit.Author.Email)] = true
+	}
+
+	var s Score
+	var totalChurn, ownLines, blamedLines int
+	var weightedBugs float64
+	created := map[string]bool{}
+	for _, f := range pr.Git.CreatedFiles() {
+		created[f] = true
+	}
+
+	files := append(append([]string{}, pr.Git.ModifiedFiles()...), pr.Git.CreatedFiles()...)
+	for _, file := range files {
+		diff, err := pr.Git.DiffForFileWithRefs(file, base, head)
+		if err != nil {
+			return Score{}, err
+		}
+		fr := FileRisk{
+			File:        file,
+			Churn:       len(diff.AddedLines) + len(diff.RemovedLines),
+			Criticality: c.criticalityOf(file),
+		}
+		totalChurn += fr.Churn
+		s.Criticality = math.Max(s.Criticality, fr.Criticality)
+
+		if !created[file] {
+			// the history of the base leaves out the commits of the PR
+			history, err := dangerJs.CommitsForFileAtRef(pr.Git, file, base, c.HistoryDepth)
+			if err != nil {
+				return Score{}, err
+			}
+			if len(history) > 0 {
+				fixes := 0
+				for _, commit := range history {
+					if fixPattern.MatchString(commit.Message) {
+						fixes++
+					}
+				}
+				fr.BugDensity = float64(fixes) / float64(len(history))
+			}
+
+			blame, err := dangerJs.Blame(pr.Git, file, base)
+			if err != nil {
+				return Score{}, err
+			}
+			for _, l := range blame {
+				blamedLines++
+				if authors[strings.ToLower(l.AuthorEmail)] {
+					ownLines++
+				}
+			}
+		}
+		weightedBugs += fr.BugDensity * float64(fr.Churn)
+		s.Files = append(s.Files, fr)
+	}
+
+	s.Churn = math.Min(float64(totalChurn)/float64(churnCap), 1)
+	if totalChurn > 0 {
+		s.BugDensity = weightedBugs / float64(totalChurn)
+	}
+	if blamedLines > 0 {
+		s.Unfamiliarity = 1 - float64(ownLines)/float64(blamedLines)
+	}
+
+	w := c.Weights
+	if w == (Weights{}) {
+		w = DefaultWeights
+	}
+	sum := w.Churn + w.Criticality + w.BugDensity + w.Unfamiliarity
+	s.Total = 100 * (w.Churn*s.Churn + w.Criticality*s.Criticality + w.BugDensity*s.BugDensity + w.Unfamiliarity*s.Unfamiliarity) / sum
+
+	sort.SliceStable(s.Files, func(i, j int) bool {
+		return s.Files[i].Churn > s.Files[j].Churn
+	})
+	return s, nil
+}
+
+// Run computes the score, posts the risk badge and enforces the approval
+// requirement for risky PRs.
+func (c Config) Run(d *danger.T, pr danger.DSL) {
+	s, err := Compute(pr, c)
+	if err != nil {
+		d.Warn(fmt.Sprintf("Could not compute PR risk: %s", err), "", 0)
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s\n\n", s.Badge())
+	sb.WriteString("| Churn | Criticality | Bug history | Unfamiliarity |\n| --- | --- | --- | --- |\n")
+	fmt.Fprintf(&sb, "| %.0f%% | %.0f%% | %.0f%% | %.0f%% |\n", s.Churn*100, s.Criticality*100, s.BugDensity*100, s.Unfamiliarity*100)
+	d.Markdown(sb.String(), "", 0)
+
+	if c.RequiredApprovals <= 0 || s.Total < c.Threshold {
+		return
+	}
+	var approvals int
+	switch {
+	case pr.GitHub != nil && pr.GitHub.PR().Number != 0:
+		approvals = len(pr.GitHubHelpers().ApprovedBy())
+	case pr.GitLab != nil && pr.GitLab.MR().IID != 0:
+		approvals = len(pr.GitLabHelpers().ApprovedBy())
+	default:
+		return
+	}
+	if approvals >= c.RequiredApprovals {
+		return
+	}
+	msg := fmt.Sprintf("This PR has a %s risk score of %.0f and needs %d approvals, it has %d.",
+		s.Level(), s.Total, c.RequiredApprovals, approvals)
+	if c.FailOnMissingApprovals {
+		d.Fail(msg, "", 0)
+	} else {
+		d.Warn(msg, "", 0)
+	}
+}
@@ -0,0 +1,207 @@
+package risk
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+func lines(n int) []dangerJs.DiffLine {
+	ll := make([]dangerJs.DiffLine, n)
+	for i := range ll {
+		ll[i] = dangerJs.DiffLine{Line: i + 1}
+	}
+	return ll
+}
+
+func testGit() fakedsl.Git {
+	return fakedsl.Git{
+		CreatedFilesList:  []string{"new.go"},
+		ModifiedFilesList: []string{"db/migrations/001.sql"},
+		CommitsList: []dangerJs.GitCommit{
+			{Author: dangerJs.GitCommitAuthor{Email: "ada@example.com"}},
+		},
+		Diffs: map[string]dangerJs.FileDiff{
+			"new.go":                {AddedLines: lines(100)},
+			"db/migrations/001.sql": {AddedLines: lines(50), RemovedLines: lines(50)},
+		},
+		History: map[string][]dangerJs.GitCommit{
+			"db/migrations/001.sql": {
+				{Message: "fix: broken index"},
+				{Message: "feat: add table"},
+			},
+		},
+		Blames: map[string][]dangerJs.BlameLine{
+			"db/migrations/001.sql": {
+				{AuthorEmail: "ada@example.com"},
+				{AuthorEmail: "bob@example.com"},
+				{AuthorEmail: "bob@example.com"},
+				{AuthorEmail: "bob@example.com"},
+			},
+		},
+	}
+}
+
+func testPR(reviews ...dangerJs.GitHubReview) danger.DSL {
+	return danger.DSL{
+		Git:    testGit(),
+		GitHub: fakedsl.GitHub{PRData: dangerJs.GitHubPR{Number: 1}, ReviewsList: reviews},
+	}
+}
+
+func TestCompute(t *testing.T) {
+	c := NewConfig()
+	c.Criticality = map[string]float64{"db/migrations": 1}
+
+	s, err := Compute(testPR(), c)
+	require.Nil(t, err)
+	require.InDelta(t, 0.2, s.Churn, 0.0001)
+	require.InDelta(t, 1.0, s.Criticality, 0.0001)
+	require.InDelta(t, 0.25, s.BugDensity, 0.0001)
+	require.InDelta(t, 0.75, s.Unfamiliarity, 0.0001)
+	require.InDelta(t, 100*(0.3*0.2+0.3*1+0.2*0.25+0.2*0.75), s.Total, 0.0001)
+	require.Equal(t, "medium", s.Level())
+	require.Equal(t, "db/migrations/001.sql", s.Files[0].File)
+}
+
+// refsGit records the refs the history is read at.
+type refsGit struct {
+	fakedsl.Git
+	refs *[]string
+}
+
+func (g refsGit) CommitsForFileAtRef(filePath, ref string, limit int) ([]dangerJs.GitCommit, error) {
+	*g.refs = append(*g.refs, ref)
+	return g.Git.CommitsForFileAtRef(filePath, ref, limit)
+}
+
+func TestComputeHistoryAtBase(t *testing.T) {
+	var refs []string
+	pr := testPR()
+	pr.Git = refsGit{Git: testGit(), refs: &refs}
+	c := NewConfig()
+	c.BaseRef = "main"
+
+	_, err := Compute(pr, c)
+	require.Nil(t, err)
+	require.Equal(t, []string{"main"}, refs, "the commits of the PR don't count towards the bug density")
+}
+
+func TestRunRequiresApprovals(t *testing.T) {
+	c := NewConfig()
+	c.Criticality = map[string]float64{"db/migrations": 1}
+	c.Threshold = 40
+	c.RequiredApprovals = 2
+	c.FailOnMissingApprovals = true
+
+	tests := []struct {
+		name      string
+		reviews   []dangerJs.GitHubReview
+		wantFails int
+	}{
+		{
+			name: "one approval",
+			reviews: []dangerJs.GitHubReview{
+				{User: dangerJs.GitHubUser{Login: "a"}, State: "APPROVED"},
+				{User: dangerJs.GitHubUser{Login: "a"}, State: "COMMENTED"},
+			},
+			wantFails: 1,
+		},
+		{
+			name: "approval dismissed by later changes requested",
+			reviews: []dangerJs.GitHubReview{
+				{User: dangerJs.GitHubUser{Login: "a"}, State: "APPROVED"},
+				{User: dangerJs.GitHubUser{Login: "b"}, State: "APPROVED"},
+				{User: dangerJs.GitHubUser{Login: "b"}, State: "CHANGES_REQUESTED"},
+			},
+			wantFails: 1,
+		},
+		{
+			name: "enough approvals",
+			reviews: []dangerJs.GitHubReview{
+				{User: dangerJs.GitHubUser{Login: "a"}, State: "APPROVED"},
+				{User: dangerJs.GitHubUser{Login: "b"}, State: "APPROVED"},
+			},
+			wantFails: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := danger.New()
+			c.Run(d, testPR(tt.reviews...))
+
+			s, err := d.Results()
+			require.Nil(t, err)
+			var r danger.Results
+			require.Nil(t, json.Unmarshal([]byte(s), &r))
+			require.Len(t, r.Fails, tt.wantFails)
+			require.Len(t, r.Markdowns, 1)
+			require.Contains(t, r.Markdowns[0].Message, "img.shields.io/badge/risk-medium")
+		})
+	}
+}
+
+func TestRunApprovalsByPlatform(t *testing.T) {
+	c := NewConfig()
+	c.Criticality = map[string]float64{"db/migrations": 1}
+	c.Threshold = 40
+	c.RequiredApprovals = 2
+	c.FailOnMissingApprovals = true
+
+	mr := dangerJs.GitLabMR{}
+	mr.IID = 4
+	approvedBy := func(usernames ...string) []any {
+		var out []any
+		for _, u := range usernames {
+			out = append(out, map[string]any{"user": map[string]any{"username": u}})
+		}
+		return out
+	}
+
+	tests := []struct {
+		name      string
+		pr        danger.DSL
+		wantFails int
+	}{
+		{
+			name:      "not a PR",
+			pr:        danger.DSL{Git: testGit(), GitHub: fakedsl.GitHub{}},
+			wantFails: 0,
+		},
+		{
+			name: "GitLab missing approvals",
+			pr: danger.DSL{Git: testGit(), GitHub: fakedsl.GitHub{}, GitLab: fakedsl.GitLab{
+				MRData:        mr,
+				ApprovalsData: dangerJs.GitLabApproval{ApprovedBy: approvedBy("a")},
+			}},
+			wantFails: 1,
+		},
+		{
+			name: "GitLab approved",
+			pr: danger.DSL{Git: testGit(), GitHub: fakedsl.GitHub{}, GitLab: fakedsl.GitLab{
+				MRData:        mr,
+				ApprovalsData: dangerJs.GitLabApproval{ApprovedBy: approvedBy("a", "b")},
+			}},
+			wantFails: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := danger.New()
+			c.Run(d, tt.pr)
+
+			s, err := d.Results()
+			require.Nil(t, err)
+			var r danger.Results
+			require.Nil(t, json.Unmarshal([]byte(s), &r))
+			require.Len(t, r.Fails, tt.wantFails)
+		})
+	}
+}
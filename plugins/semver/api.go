@@ -0,0 +1,137 @@
+package semver
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// APIChange is a change to the exported API of a Go package, in the spirit of
+// golang.org/x/exp/apidiff.
+type APIChange struct {
+	// Package is the directory of the package.
+	Package string
+	// Name identifies the declaration, e.g. "func New" or "field Config.Name".
+	Name string
+	// Compatible is false for removed or changed declarations.
+	Compatible bool
+	Message    string
+}
+
+// exportedAPI returns the signature of each exported declaration of a Go
+// file. It returns nil for main packages, whose API cannot be imported.
+func exportedAPI(filename, src string) (map[string]string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filename, err)
+	}
+	if f.Name.Name == "main" {
+		return nil, nil
+	}
+
+	api := map[string]string{}
+	str := func(n any) string {
+		var buf bytes.Buffer
+		_ = printer.Fprint(&buf, fset, n)
+		return buf.String()
+	}
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			if d.Recv == nil {
+				api["func "+d.Name.Name] = str(d.Type)
+				continue
+			}
+			recv := receiverName(d.Recv.List[0].Type)
+			if !ast.IsExported(recv) {
+				continue
+			}
+			api["method "+recv+"."+d.Name.Name] = str(d.Recv.List[0].Type) + " " + str(d.Type)
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if !s.Name.IsExported() {
+						continue
+					}
+					st, ok := s.Type.(*ast.StructType)
+					if !ok {
+						api["type "+s.Name.Name] = str(s.Type)
+						continue
+					}
+					// Fields are compared one by one so adding a field is a
+					// compatible change.
+					api["type "+s.Name.Name] = "struct"
+					for _, field := range st.Fields.List {
+						for _, name := range field.Names {
+							if name.IsExported() {
+								api["field "+s.Name.Name+"."+name.Name] = str(field.Type)
+							}
+						}
+						if len(field.Names) == 0 {
+							api["embedded "+s.Name.Name+"."+str(field.Type)] = str(field.Type)
+						}
+					}
+				case *ast.ValueSpec:
+					kind := strings.ToLower(d.Tok.String())
+					for _, name := range s.Names {
+						if !name.IsExported() {
+							continue
+						}
+						sig := ""
+						if s.Type != nil {
+							sig = str(s.Type)
+						}
+						api[kind+" "+name.Name] = sig
+					}
+				}
+			}
+		}
+	}
+	return api, nil
+}
+
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// diffAPI compares the exported API of a package before and after the change.
+func diffAPI(pkg string, before, after map[string]string) []APIChange {
+	var changes []APIChange
+	for name, sig := range before {
+		newSig, ok := after[name]
+		switch {
+		case !ok:
+			changes = append(changes, APIChange{Package: pkg, Name: name, Message: "removed"})
+		case newSig != sig:
+			changes = append(changes, APIChange{Package: pkg, Name: name,
+				Message: fmt.Sprintf("changed from `%s` to `%s`", sig, newSig)})
+		}
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			changes = append(changes, APIChange{Package: pkg, Name: name, Compatible: true, Message: "added"})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
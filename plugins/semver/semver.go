@@ -0,0 +1,368 @@
+// Package semver suggests the semantic version bump a PR requires from its
+// conventional commits, the changes to the exported Go API and the paths it
+// touches, and can label the PR with the suggestion.
+package semver
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/githubclient"
+	"github.com/danger/golang/gitlabclient"
+)
+
+// Impact is the version bump a PR requires.
+type Impact int
+
+const (
+	// ImpactNone is for PRs which only touch ignored paths.
+	ImpactNone Impact = iota
+	ImpactPatch
+	ImpactMinor
+	ImpactMajor
+)
+
+func (i Impact) String() string {
+	switch i {
+	case ImpactPatch:
+		return "patch"
+	case ImpactMinor:
+		return "minor"
+	case ImpactMajor:
+		return "major"
+	default:
+		return "none"
+	}
+}
+
+// Labeler replaces the version labels of the PR.
+type Labeler interface {
+	SetLabel(ctx context.Context, add string, remove []string) error
+}
+
+// Config configures the analysis and the label applied.
+type Config struct {
+	// BaseRef and HeadRef select the refs the API is compared between,
+	// HEAD^ and HEAD by default.
+	BaseRef string
+	HeadRef string
+	// IgnorePaths don't affect the version, e.g. tests and documentation.
+	// Patterns use dangerJs.MatchPath syntax.
+	IgnorePaths []string
+	// MajorPaths and MinorPaths raise the impact of PRs touching them.
+	MajorPaths []string
+	MinorPaths []string
+	// CheckAPI compares the exported API of the changed Go packages.
+	CheckAPI bool
+
+	// ApplyLabel labels the PR with LabelPrefix followed by the impact and
+	// removes labels for other impacts.
+	ApplyLabel  bool
+	LabelPrefix string
+	// Labeler applies the label. When nil a GitHub or GitLab client is
+	// created from the environment.
+	Labeler Labeler
+}
+
+// NewConfig returns a Config which ignores tests, markdown and CI files.
+func NewConfig() Config {
+	return Config{
+		IgnorePaths: []string{"**/*_test.go", "**/testdata/**", "**/*.md", "docs/**", ".github/**"},
+		CheckAPI:    true,
+		LabelPrefix: "semver/",
+	}
+}
+
+// Suggestion is the suggested bump and why.
+type Suggestion struct {
+	Impact     Impact
+	Reasons    []string
+	APIChanges []APIChange
+}
+
+func (s *Suggestion) raise(i Impact, reason string) {
+	if i > s.Impact {
+		s.Impact = i
+	}
+	for _, r := range s.Reasons {
+		if r == reason {
+			return
+		}
+	}
+	s.Reasons = append(s.Reasons, reason)
+}
+
+func (c Config) refs() (string, string) {
+	base, head := c.BaseRef, c.HeadRef
+	if base == "" {
+		base = "HEAD^"
+	}
+	if head == "" {
+		head = "HEAD"
+	}
+	return base, head
+}
+
+func matchAny(patterns []string, file string) bool {
+	for _, p := range patterns {
+		if dangerJs.MatchPath(p, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// Suggest works out the version bump the PR requires.
+func Suggest(pr danger.DSL, c Config) (Suggestion, error) {
+	var s Suggestion
+
+	relevant := relevantFiles(pr, c)
+	if len(relevant) > 0 {
+		s.raise(ImpactPatch, fmt.Sprintf("%d relevant file(s) changed", len(relevant)))
+	}
+	for _, f := range relevant {
+		switch {
+		case matchAny(c.MajorPaths, f):
+			s.raise(ImpactMajor, fmt.Sprintf("`%s` requires a major release", f))
+		case matchAny(c.MinorPaths, f):
+			s.raise(ImpactMinor, fmt.Sprintf("`%s` requires a minor release", f))
+		}
+	}
+
+	for _, msg := range messages(pr) {
+		cc, ok := dangerJs.ParseConventionalCommit(msg)
+		if !ok {
+			continue
+		}
+		subject, _, _ := strings.Cut(msg, "\n")
+		switch {
+		case cc.Breaking:
+			s.raise(ImpactMajor, fmt.Sprintf("breaking change: %s", subject))
+		case cc.Type == "feat":
+			s.raise(ImpactMinor, fmt.Sprintf("new feature: %s", subject))
+		}
+	}
+
+	if c.CheckAPI {
+		changes, err := apiChanges(pr, c, relevant)
+		if err != nil {
+			return s, err
+		}
+		s.APIChanges = changes
+		var added, broken int
+		for _, ch := range changes {
+			if ch.Compatible {
+				added++
+			} else {
+				broken++
+			}
+		}
+		if broken > 0 {
+			s.raise(ImpactMajor, fmt.Sprintf("%d incompatible API change(s)", broken))
+		}
+		if added > 0 {
+			s.raise(ImpactMinor, fmt.Sprintf("%d API addition(s)", added))
+		}
+	}
+	return s, nil
+}
+
+// relevantFiles returns the changed files not matching c.IgnorePaths.
+func relevantFiles(pr danger.DSL, c Config) []string {
+	var relevant []string
+	all := append(append(append([]string{}, pr.Git.CreatedFiles()...), pr.Git.ModifiedFiles()...), pr.Git.DeletedFiles()...)
+	for _, f := range all {
+		if !matchAny(c.IgnorePaths, f) {
+			relevant = append(relevant, f)
+		}
+	}
+	return relevant
+}
+
+// APIChanges compares the exported API of the packages with changed Go
+// files not matching c.IgnorePaths, between c.BaseRef and c.HeadRef.
+func APIChanges(pr danger.DSL, c Config) ([]APIChange, error) {
+	return apiChanges(pr, c, relevantFiles(pr, c))
+}
+
+// messages returns the PR title and body followed by the commit messages.
+func messages(pr danger.DSL) []string {
+	var msgs []string
+	switch {
+	case pr.GitHub != nil && pr.GitHub.PR().Title != "":
+		msgs = append(msgs, pr.GitHub.PR().Title+"\n\n"+pr.GitHub.PR().Body)
+	case pr.GitLab != nil && pr.GitLab.MR().Title != "":
+		msgs = append(msgs, pr.GitLab.MR().Title+"\n\n"+pr.GitLab.MR().Description)
+	}
+	for _, commit := range pr.Git.Commits() {
+		msgs = append(msgs, commit.Message)
+	}
+	return msgs
+}
+
+// apiChanges compares the exported API of each package with a changed
+// non-test Go file.
+func apiChanges(pr danger.DSL, c Config, files []string) ([]APIChange, error) {
+	base, head := c.refs()
+	created := map[string]bool{}
+	for _, f := range pr.Git.CreatedFiles() {
+		created[f] = true
+	}
+	deleted := map[string]bool{}
+	for _, f := range pr.Git.DeletedFiles() {
+		deleted[f] = true
+	}
+
+	type pkgAPI struct{ before, after map[string]string }
+	pkgs := map[string]*pkgAPI{}
+	var order []string
+	for _, f := range files {
+		if !strings.HasSuffix(f, ".go") || strings.HasSuffix(f, "_test.go") || isInternal(f) {
+			continue
+		}
+		dir := path.Dir(f)
+		p, ok := pkgs[dir]
+		if !ok {
+			p = &pkgAPI{before: map[string]string{}, after: map[string]string{}}
+			pkgs[dir] = p
+			order = append(order, dir)
+		}
+		if !created[f] {
+			if err := addAPI(pr, f, base, p.before); err != nil {
+				return nil, err
+			}
+		}
+		if !deleted[f] {
+			if err := addAPI(pr, f, head, p.after); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var changes []APIChange
+	for _, dir := range order {
+		changes = append(changes, diffAPI(dir, pkgs[dir].before, pkgs[dir].after)...)
+	}
+	return changes, nil
+}
+
+func addAPI(pr danger.DSL, file, ref string, api map[string]string) error {
+	src, err := dangerJs.FileAtRef(pr.Git, file, ref)
+	if err != nil {
+		return fmt.Errorf("reading %s at %s: %w", file, ref, err)
+	}
+	decls, err := exportedAPI(file, src)
+	if err != nil {
+		return err
+	}
+	for k, v := range decls {
+		api[k] = v
+	}
+	return nil
+}
+
+func isInternal(file string) bool {
+	return strings.HasPrefix(file, "internal/") || strings.Contains(file, "/internal/")
+}
+
+// Run posts the suggestion as a message and applies the label if enabled.
+func (c Config) Run(d *danger.T, pr danger.DSL) {
+	s, err := Suggest(pr, c)
+	if err != nil {
+		d.Warn(fmt.Sprintf("Semver: %s", err), "", 0)
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Suggested version bump: **%s**", s.Impact)
+	for _, r := range s.Reasons {
+		fmt.Fprintf(&sb, "\n- %s", r)
+	}
+	d.Message(sb.String(), "", 0)
+
+	if len(s.APIChanges) > 0 {
+		var md strings.Builder
+		md.WriteString("### API changes\n\n| Package | Declaration | Change |\n| --- | --- | --- |\n")
+		for _, ch := range s.APIChanges {
+			fmt.Fprintf(&md, "| `%s` | `%s` | %s |\n", ch.Package, ch.Name, ch.Message)
+		}
+		d.Markdown(md.String(), "", 0)
+	}
+
+	if !c.ApplyLabel {
+		return
+	}
+	if err := c.applyLabel(pr, s.Impact); err != nil {
+		d.Warn(fmt.Sprintf("Semver: applying label: %s", err), "", 0)
+	}
+}
+
+func (c Config) applyLabel(pr danger.DSL, impact Impact) error {
+	labeler := c.Labeler
+	if labeler == nil {
+		var err error
+		if labeler, err = labelerFor(pr); err != nil {
+			return err
+		}
+	}
+	add := c.LabelPrefix + impact.String()
+	var remove []string
+	for _, l := range danger.PRLabels(pr) {
+		if strings.HasPrefix(l, c.LabelPrefix) && l != add {
+			remove = append(remove, l)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return labeler.SetLabel(ctx, add, remove)
+}
+
+func labelerFor(pr danger.DSL) (Labeler, error) {
+	if pr.GitHub != nil && pr.GitHub.PR().Number != 0 {
+		client, err := githubclient.NewFromSettings(pr.Settings)
+		if err != nil {
+			return nil, err
+		}
+		this := pr.GitHub.ThisPR()
+		return gitHubLabeler{client: client, owner: this.Owner, repo: this.Repo, number: this.Number}, nil
+	}
+	if pr.GitLab != nil && pr.GitLab.MR().IID != 0 {
+		client, err := gitlabclient.NewFromSettings(pr.Settings)
+		if err != nil {
+			return nil, err
+		}
+		return gitLabLabeler{client: client, project: pr.GitLab.Metadata().RepoSlug, iid: pr.GitLab.MR().IID}, nil
+	}
+	return nil, fmt.Errorf("labels require a GitHub or GitLab PR")
+}
+
+type gitHubLabeler struct {
+	client *githubclient.Client
+	owner  string
+	repo   string
+	number int
+}
+
+func (l gitHubLabeler) SetLabel(ctx context.Context, add string, remove []string) error {
+	for _, r := range remove {
+		if err := l.client.RemoveLabel(ctx, l.owner, l.repo, l.number, r); err != nil {
+			return err
+		}
+	}
+	return l.client.AddLabels(ctx, l.owner, l.repo, l.number, add)
+}
+
+type gitLabLabeler struct {
+	client  *gitlabclient.Client
+	project string
+	iid     int64
+}
+
+func (l gitLabLabeler) SetLabel(ctx context.Context, add string, remove []string) error {
+	return l.client.UpdateMRLabels(ctx, l.project, l.iid, []string{add}, remove)
+}
@@ -0,0 +1,158 @@
+package semver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+type fakeLabeler struct {
+	add    string
+	remove []string
+}
+
+func (l *fakeLabeler) SetLabel(_ context.Context, add string, remove []string) error {
+	l.add, l.remove = add, remove
+	return nil
+}
+
+const apiBefore = `package lib
+
+type Config struct {
+	Name string
+}
+
+func New(name string) *Config { return &Config{Name: name} }
+
+func (c *Config) Validate() error { return nil }
+
+func helper() {}
+`
+
+func TestExportedAPIDiff(t *testing.T) {
+	after := `package lib
+
+type Config struct {
+	Name    string
+	Verbose bool
+}
+
+func New(name string, opts ...Option) *Config { return &Config{Name: name} }
+
+type Option func(*Config)
+
+func helper(x int) {}
+`
+	before, err := exportedAPI("lib.go", apiBefore)
+	require.NoError(t, err)
+	now, err := exportedAPI("lib.go", after)
+	require.NoError(t, err)
+
+	require.Equal(t, []APIChange{
+		{Package: "lib", Name: "field Config.Verbose", Compatible: true, Message: "added"},
+		{Package: "lib", Name: "func New", Message: "changed from `func(name string) *Config` to `func(name string, opts ...Option) *Config`"},
+		{Package: "lib", Name: "method Config.Validate", Message: "removed"},
+		{Package: "lib", Name: "type Option", Compatible: true, Message: "added"},
+	}, diffAPI("lib", before, now))
+
+	main, err := exportedAPI("main.go", "package main\n\nfunc Exported() {}\n")
+	require.NoError(t, err)
+	require.Nil(t, main)
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		name string
+		pr   danger.DSL
+		want Impact
+	}{
+		{
+			name: "docs only",
+			pr:   danger.DSL{Git: fakedsl.Git{ModifiedFilesList: []string{"README.md", "lib/lib_test.go"}}},
+			want: ImpactNone,
+		},
+		{
+			name: "fix",
+			pr: danger.DSL{Git: fakedsl.Git{
+				ModifiedFilesList: []string{"lib/lib.go"},
+				CommitsList:       []dangerJs.GitCommit{{Message: "fix: nil check"}},
+				Files:             map[string]string{"HEAD^:lib/lib.go": apiBefore, "HEAD:lib/lib.go": apiBefore},
+			}},
+			want: ImpactPatch,
+		},
+		{
+			name: "feature title",
+			pr: danger.DSL{
+				Git: fakedsl.Git{ModifiedFilesList: []string{"cmd/tool/main.go"}, Files: map[string]string{
+					"HEAD^:cmd/tool/main.go": "package main\n",
+					"HEAD:cmd/tool/main.go":  "package main\n\nfunc Exported() {}\n",
+				}},
+				GitHub: fakedsl.GitHub{PRData: dangerJs.GitHubPR{Title: "feat(cli): add --json"}},
+			},
+			want: ImpactMinor,
+		},
+		{
+			name: "breaking commit",
+			pr: danger.DSL{Git: fakedsl.Git{
+				ModifiedFilesList: []string{"app.yaml"},
+				CommitsList:       []dangerJs.GitCommit{{Message: "refactor!: rename settings"}},
+			}},
+			want: ImpactMajor,
+		},
+		{
+			name: "removed API in deleted file",
+			pr: danger.DSL{Git: fakedsl.Git{
+				DeletedFilesList: []string{"lib/lib.go"},
+				Files:            map[string]string{"HEAD^:lib/lib.go": apiBefore},
+			}},
+			want: ImpactMajor,
+		},
+		{
+			name: "internal packages are not API",
+			pr: danger.DSL{Git: fakedsl.Git{
+				DeletedFilesList: []string{"internal/lib/lib.go"},
+				Files:            map[string]string{"HEAD^:internal/lib/lib.go": apiBefore},
+			}},
+			want: ImpactPatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Suggest(tt.pr, NewConfig())
+			require.NoError(t, err)
+			require.Equal(t, tt.want, s.Impact, s.Reasons)
+		})
+	}
+}
+
+func TestRunAppliesLabel(t *testing.T) {
+	labeler := &fakeLabeler{}
+	c := NewConfig()
+	c.ApplyLabel = true
+	c.Labeler = labeler
+
+	pr := danger.DSL{
+		Git: fakedsl.Git{ModifiedFilesList: []string{"lib/lib.go"}, Files: map[string]string{
+			"HEAD^:lib/lib.go": apiBefore,
+			"HEAD:lib/lib.go":  apiBefore + "\nfunc Extra() {}\n",
+		}},
+		GitHub: fakedsl.GitHub{
+			PRData:    dangerJs.GitHubPR{Number: 1, Title: "Add Extra"},
+			IssueData: fakedsl.Labeled("semver/patch", "bug"),
+		},
+	}
+
+	d := danger.New()
+	c.Run(d, pr)
+
+	r := d.Snapshot()
+	require.Equal(t, "Suggested version bump: **minor**\n- 1 relevant file(s) changed\n- 1 API addition(s)", r.Messages[0].Message)
+	require.Contains(t, r.Markdowns[0].Message, "| `lib` | `func Extra` | added |")
+	require.Equal(t, "semver/minor", labeler.add)
+	require.Equal(t, []string{"semver/patch"}, labeler.remove)
+}
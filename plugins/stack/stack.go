@@ -0,0 +1,249 @@
+// Package stack detects stacked PRs, where a PR targets the branch of another
+// open PR rather than the trunk, links the PRs of the stack together and lets
+// rules adjust to them.
+package stack
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/githubclient"
+	"github.com/danger/golang/gitlabclient"
+)
+
+// maxDepth bounds the walk down the stack in case branches form a cycle.
+const maxDepth = 20
+
+// PR is a PR of a stack.
+type PR struct {
+	Number int
+	Title  string
+	URL    string
+	Head   string
+	Base   string
+}
+
+// Finder looks up open PRs by branch.
+type Finder interface {
+	// ByHead returns the open PRs whose source branch is branch.
+	ByHead(ctx context.Context, branch string) ([]PR, error)
+	// ByBase returns the open PRs targeting branch.
+	ByBase(ctx context.Context, branch string) ([]PR, error)
+}
+
+// Stack is the chain of PRs a PR belongs to.
+type Stack struct {
+	// Parents are the PRs this one is stacked on, bottom first.
+	Parents []PR
+	Current PR
+	// Children are the open PRs stacked on this one.
+	Children []PR
+}
+
+// IsStacked reports whether the PR targets another PR's branch.
+func (s Stack) IsStacked() bool {
+	return len(s.Parents) > 0
+}
+
+// Base returns the branch the bottom of the stack targets, e.g. main.
+func (s Stack) Base() string {
+	if len(s.Parents) > 0 {
+		return s.Parents[0].Base
+	}
+	return s.Current.Base
+}
+
+// Markdown lists the PRs of the stack, bottom first.
+func (s Stack) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("### PR stack\n\n")
+	fmt.Fprintf(&sb, "1. `%s`\n", s.Base())
+	for _, p := range s.Parents {
+		fmt.Fprintf(&sb, "1. %s\n", link(p))
+	}
+	fmt.Fprintf(&sb, "1. **%s** 👈 this PR\n", link(s.Current))
+	for _, p := range s.Children {
+		fmt.Fprintf(&sb, "1. %s\n", link(p))
+	}
+	return sb.String()
+}
+
+func link(p PR) string {
+	if p.URL == "" {
+		return fmt.Sprintf("#%d %s", p.Number, p.Title)
+	}
+	return fmt.Sprintf("[#%d %s](%s)", p.Number, p.Title, p.URL)
+}
+
+// CumulativeDiff returns the diff of file across the whole stack, from the
+// branch the bottom PR targets on remote to HEAD.
+func (s Stack) CumulativeDiff(pr danger.DSL, remote, file string) (dangerJs.FileDiff, error) {
+	return pr.Git.DiffForFileWithRefs(file, remote+"/"+s.Base(), "HEAD")
+}
+
+// SkipIfStacked wraps rules so they only add a message instead of running
+// when the PR is stacked, e.g. for checks which only make sense against the
+// trunk.
+func (s Stack) SkipIfStacked(rules ...danger.Rule) []danger.Rule {
+	if !s.IsStacked() {
+		return rules
+	}
+	wrapped := make([]danger.Rule, len(rules))
+	for i, r := range rules {
+		wrapped[i] = danger.Rule{Name: r.Name, When: r.When, Strict: r.Strict, Run: func(d *danger.T, _ danger.DSL) {
+			d.Message(fmt.Sprintf("Rule `%s` skipped for stacked PR", r.Name), "", 0)
+		}}
+	}
+	return wrapped
+}
+
+// current returns the PR being checked.
+func current(pr danger.DSL) PR {
+	switch {
+	case pr.GitHub != nil && pr.GitHub.PR().Number != 0:
+		p := pr.GitHub.PR()
+		return PR{Number: p.Number, Title: p.Title, URL: p.HTMLURL, Head: p.Head.Ref, Base: p.Base.Ref}
+	case pr.GitLab != nil && pr.GitLab.MR().IID != 0:
+		mr := pr.GitLab.MR()
+		return PR{Number: int(mr.IID), Title: mr.Title, URL: mr.WebURL, Head: mr.SourceBranch, Base: mr.TargetBranch}
+	}
+	return PR{}
+}
+
+// Detect works out the stack of the PR.
+func Detect(ctx context.Context, pr danger.DSL, f Finder) (Stack, error) {
+	s := Stack{Current: current(pr)}
+	if s.Current.Number == 0 {
+		return s, fmt.Errorf("stacked PRs require a GitHub or GitLab PR")
+	}
+
+	seen := map[int]bool{s.Current.Number: true}
+	base := s.Current.Base
+	for depth := 0; depth < maxDepth; depth++ {
+		parents, err := f.ByHead(ctx, base)
+		if err != nil {
+			return s, fmt.Errorf("finding PR for %s: %w", base, err)
+		}
+		if len(parents) == 0 || seen[parents[0].Number] {
+			break
+		}
+		parent := parents[0]
+		seen[parent.Number] = true
+		s.Parents = append([]PR{parent}, s.Parents...)
+		base = parent.Base
+	}
+
+	children, err := f.ByBase(ctx, s.Current.Head)
+	if err != nil {
+		return s, fmt.Errorf("finding PRs targeting %s: %w", s.Current.Head, err)
+	}
+	s.Children = children
+	return s, nil
+}
+
+// NewFinder creates a Finder for the platform of the PR.
+func NewFinder(pr danger.DSL) (Finder, error) {
+	if pr.GitHub != nil && pr.GitHub.PR().Number != 0 {
+		client, err := githubclient.NewFromSettings(pr.Settings)
+		if err != nil {
+			return nil, err
+		}
+		this := pr.GitHub.ThisPR()
+		return gitHubFinder{client: client, owner: this.Owner, repo: this.Repo}, nil
+	}
+	if pr.GitLab != nil && pr.GitLab.MR().IID != 0 {
+		client, err := gitlabclient.NewFromSettings(pr.Settings)
+		if err != nil {
+			return nil, err
+		}
+		return gitLabFinder{client: client, project: pr.GitLab.Metadata().RepoSlug}, nil
+	}
+	return nil, fmt.Errorf("stacked PRs require a GitHub or GitLab PR")
+}
+
+type gitHubFinder struct {
+	client *githubclient.Client
+	owner  string
+	repo   string
+}
+
+func (f gitHubFinder) list(ctx context.Context, query url.Values) ([]PR, error) {
+	query.Set("state", "open")
+	prs, err := f.client.ListPullRequests(ctx, f.owner, f.repo, query)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]PR, 0, len(prs))
+	for _, p := range prs {
+		out = append(out, PR{Number: p.Number, Title: p.Title, URL: p.HTMLURL, Head: p.Head.Ref, Base: p.Base.Ref})
+	}
+	return out, nil
+}
+
+func (f gitHubFinder) ByHead(ctx context.Context, branch string) ([]PR, error) {
+	return f.list(ctx, url.Values{"head": {f.owner + ":" + branch}})
+}
+
+func (f gitHubFinder) ByBase(ctx context.Context, branch string) ([]PR, error) {
+	return f.list(ctx, url.Values{"base": {branch}})
+}
+
+type gitLabFinder struct {
+	client  *gitlabclient.Client
+	project string
+}
+
+func (f gitLabFinder) list(ctx context.Context, query url.Values) ([]PR, error) {
+	query.Set("state", "opened")
+	mrs, err := f.client.ListMRs(ctx, f.project, query)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]PR, 0, len(mrs))
+	for _, mr := range mrs {
+		out = append(out, PR{Number: int(mr.IID), Title: mr.Title, URL: mr.WebURL, Head: mr.SourceBranch, Base: mr.TargetBranch})
+	}
+	return out, nil
+}
+
+func (f gitLabFinder) ByHead(ctx context.Context, branch string) ([]PR, error) {
+	return f.list(ctx, url.Values{"source_branch": {branch}})
+}
+
+func (f gitLabFinder) ByBase(ctx context.Context, branch string) ([]PR, error) {
+	return f.list(ctx, url.Values{"target_branch": {branch}})
+}
+
+// Config configures Run.
+type Config struct {
+	// Finder looks up PRs, created from the environment when nil.
+	Finder Finder
+}
+
+// Run adds the stack to the Danger comment when the PR is part of one.
+func (c Config) Run(d *danger.T, pr danger.DSL) {
+	f := c.Finder
+	if f == nil {
+		var err error
+		if f, err = NewFinder(pr); err != nil {
+			d.Warn(fmt.Sprintf("Stacked PRs: %s", err), "", 0)
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	s, err := Detect(ctx, pr, f)
+	if err != nil {
+		d.Warn(fmt.Sprintf("Stacked PRs: %s", err), "", 0)
+		return
+	}
+	if !s.IsStacked() && len(s.Children) == 0 {
+		return
+	}
+	d.Markdown(s.Markdown(), "", 0)
+}
@@ -0,0 +1,132 @@
+package stack
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+// fakeFinder holds open PRs.
+type fakeFinder []PR
+
+func (f fakeFinder) ByHead(_ context.Context, branch string) ([]PR, error) {
+	var out []PR
+	for _, p := range f {
+		if p.Head == branch {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (f fakeFinder) ByBase(_ context.Context, branch string) ([]PR, error) {
+	var out []PR
+	for _, p := range f {
+		if p.Base == branch {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func dsl(head, base string) danger.DSL {
+	pr := dangerJs.GitHubPR{Number: 3, Title: "Part 2", HTMLURL: "https://github.com/o/r/pull/3"}
+	pr.Head.Ref, pr.Base.Ref = head, base
+	return danger.DSL{GitHub: fakedsl.GitHub{PRData: pr}}
+}
+
+func TestDetect(t *testing.T) {
+	part1 := PR{Number: 2, Title: "Part 1", URL: "https://github.com/o/r/pull/2", Head: "part-1", Base: "main"}
+	part3 := PR{Number: 4, Title: "Part 3", URL: "https://github.com/o/r/pull/4", Head: "part-3", Base: "part-2"}
+	other := PR{Number: 9, Title: "Other", Head: "other", Base: "main"}
+
+	testCases := []struct {
+		name     string
+		pr       danger.DSL
+		finder   fakeFinder
+		stacked  bool
+		base     string
+		parents  []PR
+		children []PR
+	}{
+		{
+			name:   "not stacked",
+			pr:     dsl("part-2", "main"),
+			finder: fakeFinder{other},
+			base:   "main",
+		},
+		{
+			name:     "stacked with child",
+			pr:       dsl("part-2", "part-1"),
+			finder:   fakeFinder{part1, part3, other},
+			stacked:  true,
+			base:     "main",
+			parents:  []PR{part1},
+			children: []PR{part3},
+		},
+		{
+			name: "cycle",
+			pr:   dsl("part-2", "part-1"),
+			finder: fakeFinder{
+				{Number: 2, Head: "part-1", Base: "part-0"},
+				{Number: 1, Head: "part-0", Base: "part-1"},
+			},
+			stacked: true,
+			base:    "part-1",
+			parents: []PR{{Number: 1, Head: "part-0", Base: "part-1"}, {Number: 2, Head: "part-1", Base: "part-0"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := Detect(context.Background(), tc.pr, tc.finder)
+			require.Nil(t, err)
+			require.Equal(t, tc.stacked, s.IsStacked())
+			require.Equal(t, tc.base, s.Base())
+			require.Equal(t, tc.parents, s.Parents)
+			require.Equal(t, tc.children, s.Children)
+		})
+	}
+}
+
+func TestDetectNoPR(t *testing.T) {
+	_, err := Detect(context.Background(), danger.DSL{}, fakeFinder{})
+	require.Error(t, err)
+}
+
+func TestRun(t *testing.T) {
+	d := danger.New()
+	Config{Finder: fakeFinder{
+		{Number: 2, Title: "Part 1", URL: "https://github.com/o/r/pull/2", Head: "part-1", Base: "main"},
+	}}.Run(d, dsl("part-2", "part-1"))
+
+	require.Len(t, d.Snapshot().Markdowns, 1)
+	require.Equal(t, "### PR stack\n\n"+
+		"1. `main`\n"+
+		"1. [#2 Part 1](https://github.com/o/r/pull/2)\n"+
+		"1. **[#3 Part 2](https://github.com/o/r/pull/3)** 👈 this PR\n",
+		d.Snapshot().Markdowns[0].Message)
+
+	d = danger.New()
+	Config{Finder: fakeFinder{}}.Run(d, dsl("part-2", "main"))
+	require.Empty(t, d.Snapshot().Markdowns)
+}
+
+func TestSkipIfStacked(t *testing.T) {
+	ran := false
+	rules := []danger.Rule{{Name: "changelog", Run: func(*danger.T, danger.DSL) { ran = true }}}
+
+	d := danger.New()
+	d.RunRules(danger.DSL{}, Stack{}.SkipIfStacked(rules...)...)
+	require.True(t, ran)
+
+	ran = false
+	d = danger.New()
+	d.RunRules(danger.DSL{}, Stack{Parents: []PR{{Number: 1}}}.SkipIfStacked(rules...)...)
+	require.False(t, ran)
+	require.Equal(t, "Rule `changelog` skipped for stacked PR", d.Snapshot().Messages[0].Message)
+}
@@ -0,0 +1,205 @@
+// Package testselect maps the files changed by a PR to the Go packages whose
+// tests they can affect, so CI can run `go test` on those packages only.
+package testselect
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// All is the package pattern selected when a change affects every package,
+// e.g. an edit to go.mod.
+const All = "./..."
+
+// moduleFiles affect every package of the module when changed.
+var moduleFiles = []string{"go.mod", "go.sum", "go.work", "go.work.sum"}
+
+// Package is the part of `go list -json` output the selection uses.
+type Package struct {
+	ImportPath   string
+	Dir          string
+	Imports      []string
+	TestImports  []string
+	XTestImports []string
+}
+
+// ListPackages runs `go list` for all packages of the module in dir.
+func ListPackages(dir string) ([]Package, error) {
+	cmd := exec.Command("go", "list", "-e", "-json=ImportPath,Dir,Imports,TestImports,XTestImports", "./...")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return ParsePackages(bytes.NewReader(out))
+}
+
+// ParsePackages decodes the stream of JSON objects printed by
+// `go list -json`.
+func ParsePackages(r io.Reader) ([]Package, error) {
+	var pkgs []Package
+	dec := json.NewDecoder(r)
+	for {
+		var p Package
+		err := dec.Decode(&p)
+		if errors.Is(err, io.EOF) {
+			return pkgs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decoding go list output: %w", err)
+		}
+		pkgs = append(pkgs, p)
+	}
+}
+
+// Select returns the import paths of the packages whose tests are affected by
+// files, sorted: the packages containing a changed file, the packages
+// importing them directly or transitively, and the packages whose tests
+// import any of those. files are relative to moduleDir, which pkgs were
+// listed in. It returns []string{All} when a module file changed.
+func Select(moduleDir string, pkgs []Package, files []string) []string {
+	byDir := map[string]string{}
+	importers := map[string][]string{}
+	for _, p := range pkgs {
+		byDir[filepath.Clean(p.Dir)] = p.ImportPath
+		for _, imp := range p.Imports {
+			importers[imp] = append(importers[imp], p.ImportPath)
+		}
+	}
+
+	affected := map[string]bool{}
+	var queue []string
+	for _, f := range files {
+		f = dangerJs.NormalizePath(f)
+		if slices.Contains(moduleFiles, f) {
+			return []string{All}
+		}
+		// files outside any package, e.g. under testdata, belong to the
+		// closest enclosing package
+		for dir := path.Dir(f); ; dir = path.Dir(dir) {
+			if imp, ok := byDir[filepath.Join(moduleDir, filepath.FromSlash(dir))]; ok {
+				if !affected[imp] {
+					affected[imp] = true
+					queue = append(queue, imp)
+				}
+				break
+			}
+			if dir == "." || dir == "/" {
+				break
+			}
+		}
+	}
+	for len(queue) > 0 {
+		imp := queue[0]
+		queue = queue[1:]
+		for _, importer := range importers[imp] {
+			if !affected[importer] {
+				affected[importer] = true
+				queue = append(queue, importer)
+			}
+		}
+	}
+
+	var selected []string
+	for _, p := range pkgs {
+		if affected[p.ImportPath] || slices.ContainsFunc(p.TestImports, mapHas(affected)) ||
+			slices.ContainsFunc(p.XTestImports, mapHas(affected)) {
+			selected = append(selected, p.ImportPath)
+		}
+	}
+	slices.Sort(selected)
+	return selected
+}
+
+func mapHas(m map[string]bool) func(string) bool {
+	return func(k string) bool { return m[k] }
+}
+
+// Config configures the test selection of Run.
+type Config struct {
+	// Module is the directory of the Go module within the repository, the
+	// root when empty.
+	Module string
+	// Output is the file the package list is written to, one per line, e.g.
+	// for `go test $(cat affected.txt)`. "-" writes to stdout.
+	Output string
+}
+
+// Run selects the packages affected by the created, modified and deleted
+// files of the PR, writes them to c.Output and reports their count.
+func (c Config) Run(d *danger.T, pr danger.DSL) {
+	moduleDir := c.Module
+	if moduleDir == "" {
+		moduleDir = "."
+	}
+	pkgs, err := ListPackages(moduleDir)
+	if err != nil {
+		d.Warn(fmt.Sprintf("Could not select tests: %s", err), "", 0)
+		return
+	}
+
+	prefix := strings.Trim(dangerJs.NormalizePath(c.Module), "/")
+	var files []string
+	for _, list := range [][]string{pr.Git.CreatedFiles(), pr.Git.ModifiedFiles(), pr.Git.DeletedFiles()} {
+		for _, f := range list {
+			f = dangerJs.NormalizePath(f)
+			if prefix != "" {
+				var ok bool
+				if f, ok = strings.CutPrefix(f, prefix+"/"); !ok {
+					continue
+				}
+			}
+			files = append(files, f)
+		}
+	}
+
+	absDir, err := filepath.Abs(moduleDir)
+	if err != nil {
+		d.Warn(fmt.Sprintf("Could not select tests: %s", err), "", 0)
+		return
+	}
+	selected := Select(absDir, pkgs, files)
+	if err := c.write(selected); err != nil {
+		d.Warn(fmt.Sprintf("Could not write the selected test packages: %s", err), "", 0)
+		return
+	}
+
+	switch {
+	case len(selected) == 1 && selected[0] == All:
+		d.Message("Module files changed, all tests need to run.", "", 0)
+	case len(selected) == 0:
+		d.Message("No Go package is affected, tests can be skipped.", "", 0)
+	default:
+		d.Message(fmt.Sprintf("Tests of %d of %d packages are affected by this PR.", len(selected), len(pkgs)), "", 0)
+	}
+}
+
+func (c Config) write(selected []string) error {
+	out := strings.Join(selected, "\n")
+	if out != "" {
+		out += "\n"
+	}
+	switch c.Output {
+	case "":
+		return nil
+	case "-":
+		_, err := io.WriteString(os.Stdout, out)
+		return err
+	default:
+		return os.WriteFile(c.Output, []byte(out), 0o644)
+	}
+}
@@ -0,0 +1,66 @@
+package testselect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	"github.com/danger/golang/fakedsl"
+)
+
+func TestSelect(t *testing.T) {
+	pkgs := []Package{
+		{ImportPath: "m", Dir: "/m"},
+		{ImportPath: "m/a", Dir: "/m/a"},
+		{ImportPath: "m/b", Dir: "/m/b", Imports: []string{"m/a"}},
+		{ImportPath: "m/c", Dir: "/m/c", Imports: []string{"m/b"}},
+		{ImportPath: "m/d", Dir: "/m/d", TestImports: []string{"m/a"}},
+		{ImportPath: "m/e", Dir: "/m/e", XTestImports: []string{"m/c"}},
+		{ImportPath: "m/f", Dir: "/m/f", TestImports: []string{"m/d"}},
+	}
+	tests := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{name: "transitive importers and tests", files: []string{"a/a.go"}, want: []string{"m/a", "m/b", "m/c", "m/d", "m/e"}},
+		{name: "test only import", files: []string{"d/d_test.go"}, want: []string{"m/d", "m/f"}},
+		{name: "testdata", files: []string{`c\testdata\golden.txt`}, want: []string{"m/c", "m/e"}},
+		{name: "root package", files: []string{"doc.go"}, want: []string{"m"}},
+		{name: "non Go dirs", files: []string{"README.md"}, want: []string{"m"}},
+		{name: "module file", files: []string{"a/a.go", "go.sum"}, want: []string{All}},
+		{name: "nothing", files: nil, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Select("/m", pkgs, tt.files))
+		})
+	}
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+	require.Nil(t, os.MkdirAll(filepath.Dir(name), 0o755))
+	require.Nil(t, os.WriteFile(name, []byte(content), 0o644))
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "svc/go.mod"), "module example.com/svc\n\ngo 1.24\n")
+	writeFile(t, filepath.Join(dir, "svc/a/a.go"), "package a\n")
+	writeFile(t, filepath.Join(dir, "svc/b/b.go"), "package b\n\nimport _ \"example.com/svc/a\"\n")
+	writeFile(t, filepath.Join(dir, "svc/c/c.go"), "package c\n")
+	t.Chdir(dir)
+
+	d := danger.New()
+	pr := danger.DSL{Git: fakedsl.Git{ModifiedFilesList: []string{"svc/a/a.go", "other/x.go"}}}
+	Config{Module: "svc", Output: "affected.txt"}.Run(d, pr)
+
+	out, err := os.ReadFile("affected.txt")
+	require.Nil(t, err)
+	require.Equal(t, "example.com/svc/a\nexample.com/svc/b\n", string(out))
+	require.Equal(t, []danger.Violation{{Message: "Tests of 2 of 3 packages are affected by this PR."}}, d.Snapshot().Messages)
+}
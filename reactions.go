@@ -0,0 +1,85 @@
+package danger
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// DangerCommentMarker is the hidden marker danger-js includes in the comment
+// it posts with the results.
+const DangerCommentMarker = "DangerID: danger-id-"
+
+// Reactions lists the reactions to comment id of the repository. A non-empty
+// content, e.g. "+1", only lists those reactions.
+func (o *GitHubOps) Reactions(id int64, content string) ([]dangerJs.GitHubReaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opsTimeout)
+	defer cancel()
+	reactions, err := o.client.IssueCommentReactions(ctx, o.owner, o.repo, id, content)
+	if err != nil {
+		return nil, fmt.Errorf("listing reactions: %w", err)
+	}
+	return reactions, nil
+}
+
+// React adds a reaction with content to comment id of the repository.
+func (o *GitHubOps) React(id int64, content string) error {
+	if o.readOnly {
+		return ErrReadOnly
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), opsTimeout)
+	defer cancel()
+	if _, err := o.client.CreateIssueCommentReaction(ctx, o.owner, o.repo, id, content); err != nil {
+		return fmt.Errorf("adding reaction: %w", err)
+	}
+	return nil
+}
+
+// Acknowledgers returns the sorted logins of the users who reacted with
+// content, e.g. "+1", to the latest PR comment containing marker, keeping
+// only those allowed accepts. With a nil allowed, only users with write
+// access to the repository count, so maintainers can acknowledge a notice
+// but its author can't. It returns nil when no comment contains marker.
+func (o *GitHubOps) Acknowledgers(marker, content string, allowed func(login string) bool) ([]string, error) {
+	comment, ok, err := o.FindComment(marker)
+	if err != nil || !ok {
+		return nil, err
+	}
+	reactions, err := o.Reactions(comment.ID, content)
+	if err != nil {
+		return nil, err
+	}
+	if allowed == nil {
+		allowed = o.hasWriteAccess
+	}
+	var logins []string
+	for _, r := range reactions {
+		if !slices.Contains(logins, r.User.Login) && allowed(r.User.Login) {
+			logins = append(logins, r.User.Login)
+		}
+	}
+	slices.Sort(logins)
+	return logins, nil
+}
+
+// hasWriteAccess reports whether login can push to the repository. Errors,
+// e.g. for users who aren't collaborators, deny access.
+func (o *GitHubOps) hasWriteAccess(login string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), opsTimeout)
+	defer cancel()
+	permission, err := o.client.CollaboratorPermission(ctx, o.owner, o.repo, login)
+	return err == nil && (permission == "admin" || permission == "write")
+}
+
+// DangerCommentReactions counts the reactions to the comment danger-js
+// posted on the PR by earlier runs, e.g. to gauge whether its findings are
+// useful. It returns zero counts when there is no such comment.
+func (o *GitHubOps) DangerCommentReactions() (dangerJs.GitHubReactionRollup, error) {
+	comment, _, err := o.FindComment(DangerCommentMarker)
+	if err != nil {
+		return dangerJs.GitHubReactionRollup{}, err
+	}
+	return comment.Reactions, nil
+}
@@ -0,0 +1,74 @@
+package danger_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	"github.com/danger/golang/githubclient"
+)
+
+func TestAcknowledgers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/repos/o/r/issues/5/comments":
+			_, _ = w.Write([]byte(`[
+				{"id":10,"body":"<!-- breaking-change --> old notice"},
+				{"id":11,"body":"<!-- breaking-change --> This PR breaks the API."},
+				{"id":12,"body":"unrelated"}
+			]`))
+		case "/api/v3/repos/o/r/issues/comments/11/reactions":
+			require.Equal(t, "+1", r.URL.Query().Get("content"))
+			_, _ = w.Write([]byte(`[
+				{"id":1,"content":"+1","user":{"login":"maintainer"}},
+				{"id":2,"content":"+1","user":{"login":"author"}},
+				{"id":3,"content":"+1","user":{"login":"admin"}}
+			]`))
+		case "/api/v3/repos/o/r/collaborators/maintainer/permission":
+			_, _ = w.Write([]byte(`{"permission":"write"}`))
+		case "/api/v3/repos/o/r/collaborators/admin/permission":
+			_, _ = w.Write([]byte(`{"permission":"admin"}`))
+		case "/api/v3/repos/o/r/collaborators/author/permission":
+			_, _ = w.Write([]byte(`{"permission":"read"}`))
+		default:
+			t.Fatalf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := githubclient.New(srv.URL, "token")
+	require.Nil(t, err)
+	ops := danger.NewGitHubOpsWithClient(client, "o", "r", 5)
+
+	got, err := ops.Acknowledgers("<!-- breaking-change -->", "+1", nil)
+	require.Nil(t, err)
+	require.Equal(t, []string{"admin", "maintainer"}, got)
+
+	got, err = ops.Acknowledgers("<!-- breaking-change -->", "+1", func(login string) bool { return login == "author" })
+	require.Nil(t, err)
+	require.Equal(t, []string{"author"}, got)
+
+	got, err = ops.Acknowledgers("<!-- missing -->", "+1", nil)
+	require.Nil(t, err)
+	require.Nil(t, got)
+}
+
+func TestDangerCommentReactions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v3/repos/o/r/issues/5/comments", r.URL.Path)
+		_, _ = w.Write([]byte(`[{"id":10,"body":"results\n<!-- DangerID: danger-id-default; -->",
+			"reactions":{"total_count":3,"+1":2,"confused":1}}]`))
+	}))
+	defer srv.Close()
+
+	client, err := githubclient.New(srv.URL, "token")
+	require.Nil(t, err)
+	got, err := danger.NewGitHubOpsWithClient(client, "o", "r", 5).DangerCommentReactions()
+	require.Nil(t, err)
+	require.Equal(t, 3, got.TotalCount)
+	require.Equal(t, 2, got.PlusOne)
+	require.Equal(t, 1, got.Confused)
+}
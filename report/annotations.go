@@ -0,0 +1,44 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	danger "github.com/danger/golang"
+)
+
+// WriteAnnotations writes the violations of r as GitHub Actions workflow
+// commands, which the Actions runner turns into annotations of the run and,
+// for violations with a file, of the PR diff. Failures are errors, warnings
+// warnings and messages notices.
+func WriteAnnotations(w io.Writer, r danger.Results) error {
+	for _, g := range []struct {
+		command string
+		vs      []danger.Violation
+	}{{"error", r.Fails}, {"warning", r.Warnings}, {"notice", r.Messages}} {
+		for _, v := range g.vs {
+			props := []string{"title=Danger"}
+			if v.File != "" {
+				props = append(props, "file="+escapeProperty(v.File))
+				if v.Line > 0 {
+					props = append(props, fmt.Sprintf("line=%d", v.Line))
+				}
+			}
+			if _, err := fmt.Fprintf(w, "::%s %s::%s\n", g.command, strings.Join(props, ","), escapeData(v.Message)); err != nil {
+				return fmt.Errorf("writing annotations: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// escapeData escapes the message of a workflow command.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,284 @@
+package report
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	danger "github.com/danger/golang"
+)
+
+const (
+	// StatementType is the in-toto statement type of attestations.
+	StatementType = "https://in-toto.io/Statement/v1"
+	// PolicyPredicateType identifies the predicate recording the policies run.
+	PolicyPredicateType = "https://github.com/danger/golang/attestation/policy/v1"
+	// PayloadType is the DSSE payload type of in-toto statements.
+	PayloadType = "application/vnd.in-toto+json"
+)
+
+// Subject is the commit an attestation is about.
+type Subject struct {
+	// Repository is e.g. "owner/repo" or the GitLab project path.
+	Repository string
+	Commit     string
+	// URL links the PR.
+	URL string
+}
+
+// SubjectOf returns the subject of the PR checked: its repository, head
+// commit and URL, with the commit of the CI environment as a fallback.
+func SubjectOf(pr danger.DSL) Subject {
+	var s Subject
+	switch {
+	case pr.GitHub != nil && pr.GitHub.PR().Number != 0:
+		this := pr.GitHub.ThisPR()
+		s = Subject{Repository: this.Owner + "/" + this.Repo, Commit: pr.GitHub.PR().Head.SHA, URL: pr.GitHub.PR().HTMLURL}
+	case pr.GitLab != nil && pr.GitLab.MR().IID != 0:
+		mr := pr.GitLab.MR()
+		s = Subject{Repository: pr.GitLab.Metadata().RepoSlug, Commit: mr.DiffRefs.HeadSHA, URL: mr.WebURL}
+		if s.Commit == "" {
+			s.Commit = mr.SHA
+		}
+	}
+	for _, name := range []string{"GITHUB_SHA", "CI_COMMIT_SHA"} {
+		if s.Commit == "" {
+			s.Commit = os.Getenv(name)
+		}
+	}
+	return s
+}
+
+// Statement is an in-toto statement.
+type Statement struct {
+	Type          string          `json:"_type"`
+	Subject       []StatementItem `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     PolicyPredicate `json:"predicate"`
+}
+
+// StatementItem is an artifact a statement is about.
+type StatementItem struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// PolicyPredicate records the policies run on a commit and their outcomes.
+type PolicyPredicate struct {
+	Runner     string         `json:"runner"`
+	Repository string         `json:"repository,omitempty"`
+	Commit     string         `json:"commit"`
+	PR         string         `json:"pr,omitempty"`
+	Timestamp  time.Time      `json:"timestamp"`
+	Outcome    string         `json:"outcome"`
+	Policies   []PolicyResult `json:"policies"`
+	// Sources are the dangerfile and rule bundles the policies were loaded
+	// from, with their sha256. A PR can change the dangerfile, so verifiers
+	// should check them against the reviewed versions.
+	Sources []StatementItem `json:"sources"`
+}
+
+// PolicyResult is the outcome of one rule. Violations added outside of a
+// rule are recorded under the "dangerfile" policy.
+type PolicyResult struct {
+	Name string `json:"name"`
+	// Status is how the rule ended, see danger.RuleStatus.
+	Status danger.RuleStatus `json:"status"`
+	// Outcome is "fail" when the rule reported failures, "warn" for
+	// warnings only and "pass" otherwise.
+	Outcome  string `json:"outcome"`
+	Fails    int    `json:"fails"`
+	Warnings int    `json:"warnings"`
+	Messages int    `json:"messages"`
+	Reason   string `json:"reason,omitempty"`
+	// WaivedBy is who waived the failures of the rule, which are counted
+	// as warnings.
+	WaivedBy string `json:"waived_by,omitempty"`
+}
+
+// NewStatement returns the attestation of the results r of the rules runs on
+// the subject commit, checked at now by the policies of sources.
+func NewStatement(r danger.Results, runs []danger.RuleRun, s Subject, now time.Time, sources ...StatementItem) Statement {
+	byName := map[string]*PolicyResult{}
+	var policies []*PolicyResult
+	policy := func(name string) *PolicyResult {
+		if p, ok := byName[name]; ok {
+			return p
+		}
+		p := &PolicyResult{Name: name, Status: danger.RuleRan}
+		byName[name] = p
+		policies = append(policies, p)
+		return p
+	}
+	for _, run := range runs {
+		p := policy(run.Name)
+		p.Status, p.Reason, p.WaivedBy = run.Status, run.Reason, run.WaivedBy
+	}
+	ruleName := func(v danger.Violation) string {
+		if v.Rule == "" {
+			return "dangerfile"
+		}
+		return v.Rule
+	}
+	for _, v := range r.Fails {
+		policy(ruleName(v)).Fails++
+	}
+	for _, v := range r.Warnings {
+		policy(ruleName(v)).Warnings++
+	}
+	for _, v := range append(append([]danger.Violation{}, r.Messages...), r.Markdowns...) {
+		policy(ruleName(v)).Messages++
+	}
+
+	pred := PolicyPredicate{
+		Runner:     "danger-go",
+		Repository: s.Repository,
+		Commit:     s.Commit,
+		PR:         s.URL,
+		Timestamp:  now.UTC(),
+		Outcome:    "pass",
+		Policies:   []PolicyResult{},
+		Sources:    append([]StatementItem{}, sources...),
+	}
+	for _, p := range policies {
+		p.Outcome = outcome(p.Fails, p.Warnings)
+		pred.Policies = append(pred.Policies, *p)
+	}
+	sort.SliceStable(pred.Policies, func(i, j int) bool {
+		// keep the order rules ran in, with the dangerfile first
+		return pred.Policies[i].Name == "dangerfile" && pred.Policies[j].Name != "dangerfile"
+	})
+	pred.Outcome = outcome(len(r.Fails), len(r.Warnings))
+
+	name := s.Repository
+	if name == "" {
+		name = "repository"
+	}
+	return Statement{
+		Type:          StatementType,
+		Subject:       []StatementItem{{Name: name, Digest: map[string]string{"gitCommit": s.Commit}}},
+		PredicateType: PolicyPredicateType,
+		Predicate:     pred,
+	}
+}
+
+// FileDigest returns the file at path as an item named name, with its
+// sha256 digest.
+func FileDigest(name, path string) (StatementItem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return StatementItem{}, fmt.Errorf("hashing %s: %w", name, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return StatementItem{}, fmt.Errorf("hashing %s: %w", name, err)
+	}
+	return StatementItem{Name: name, Digest: map[string]string{"sha256": hex.EncodeToString(h.Sum(nil))}}, nil
+}
+
+func outcome(fails, warnings int) string {
+	switch {
+	case fails > 0:
+		return "fail"
+	case warnings > 0:
+		return "warn"
+	}
+	return "pass"
+}
+
+// Envelope is a DSSE envelope carrying a statement, signed or not.
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is a DSSE signature, identified by the hex SHA-256 of the public
+// key.
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// Sign wraps s in an envelope signed with key. A nil key leaves the envelope
+// unsigned.
+func Sign(s Statement, key ed25519.PrivateKey) (Envelope, error) {
+	payload, err := json.Marshal(s)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("marshalling statement: %w", err)
+	}
+	e := Envelope{PayloadType: PayloadType, Payload: base64.StdEncoding.EncodeToString(payload), Signatures: []Signature{}}
+	if key != nil {
+		sig := ed25519.Sign(key, pae(PayloadType, payload))
+		e.Signatures = append(e.Signatures, Signature{
+			KeyID: KeyID(key.Public().(ed25519.PublicKey)),
+			Sig:   base64.StdEncoding.EncodeToString(sig),
+		})
+	}
+	return e, nil
+}
+
+// Verify checks e is signed by pub and returns its statement.
+func (e Envelope) Verify(pub ed25519.PublicKey) (Statement, error) {
+	payload, err := base64.StdEncoding.DecodeString(e.Payload)
+	if err != nil {
+		return Statement{}, fmt.Errorf("decoding payload: %w", err)
+	}
+	id := KeyID(pub)
+	verified := false
+	for _, s := range e.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err == nil && s.KeyID == id && ed25519.Verify(pub, pae(e.PayloadType, payload), sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return Statement{}, errors.New("attestation is not signed by the key")
+	}
+	var s Statement
+	if err := json.Unmarshal(payload, &s); err != nil {
+		return Statement{}, fmt.Errorf("decoding statement: %w", err)
+	}
+	return s, nil
+}
+
+// pae is the DSSE pre-authentication encoding of a payload.
+func pae(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// KeyID identifies pub in signatures.
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseSigningKey parses a PEM encoded PKCS #8 Ed25519 private key, as
+// generated by `openssl genpkey -algorithm ed25519`.
+func ParseSigningKey(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("signing key is not PEM encoded")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing signing key: %w", err)
+	}
+	ed, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key is a %T, want an Ed25519 key", key)
+	}
+	return ed, nil
+}
@@ -0,0 +1,79 @@
+package report
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	danger "github.com/danger/golang"
+)
+
+// CodeQualityIssue is an issue of a GitLab Code Quality report, a subset of
+// the CodeClimate format.
+type CodeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"` // "info" | "minor" | "major" | "critical" | "blocker"
+	Location    CodeQualityLocation `json:"location"`
+}
+
+// CodeQualityLocation is the place in the code an issue refers to.
+type CodeQualityLocation struct {
+	Path  string `json:"path"`
+	Lines struct {
+		Begin int `json:"begin"`
+	} `json:"lines"`
+}
+
+// CodeQuality converts the violations of r which refer to a file into Code
+// Quality issues. Failures are major, warnings minor and messages info.
+// Fingerprints are stable across runs as long as the rule, file and message
+// are, so GitLab can tell new issues from existing ones.
+func CodeQuality(r danger.Results) []CodeQualityIssue {
+	issues := []CodeQualityIssue{}
+	seen := map[string]int{}
+	for _, g := range []struct {
+		severity string
+		vs       []danger.Violation
+	}{{"major", r.Fails}, {"minor", r.Warnings}, {"info", r.Messages}} {
+		for _, v := range g.vs {
+			if v.File == "" {
+				continue
+			}
+			check := v.Rule
+			if check == "" {
+				check = "danger"
+			}
+			key := strings.Join([]string{g.severity, check, v.File, v.Message}, "\x00")
+			// identical violations on different lines need distinct fingerprints
+			seen[key]++
+			sum := md5.Sum([]byte(fmt.Sprintf("%s\x00%d", key, seen[key])))
+
+			issue := CodeQualityIssue{
+				Description: v.Message,
+				CheckName:   check,
+				Fingerprint: hex.EncodeToString(sum[:]),
+				Severity:    g.severity,
+			}
+			issue.Location.Path = v.File
+			issue.Location.Lines.Begin = max(v.Line, 1)
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// WriteCodeQuality writes the Code Quality report of r to w, e.g. the file
+// declared as the job's artifacts:reports:codequality.
+func WriteCodeQuality(w io.Writer, r danger.Results) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(CodeQuality(r)); err != nil {
+		return fmt.Errorf("encoding code quality report: %w", err)
+	}
+	return nil
+}
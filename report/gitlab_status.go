@@ -0,0 +1,62 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	danger "github.com/danger/golang"
+	"github.com/danger/golang/gitlabclient"
+)
+
+// GitLabStatus publishes the results as a commit status of the MR head, so
+// they appear in the pipeline of the MR widget.
+type GitLabStatus struct {
+	Client *gitlabclient.Client
+	// Name of the status, "danger" when empty.
+	Name string
+	// TargetURL is linked from the status, the CI job when empty.
+	TargetURL string
+}
+
+// Publish sets the status to failed when r has failures and to success
+// otherwise.
+func (s GitLabStatus) Publish(ctx context.Context, pr danger.DSL, r danger.Results) error {
+	if pr.GitLab == nil || pr.GitLab.Metadata().RepoSlug == "" {
+		return fmt.Errorf("not running on a GitLab MR")
+	}
+	mr := pr.GitLab.MR()
+	sha := mr.DiffRefs.HeadSHA
+	if sha == "" {
+		sha = mr.SHA
+	}
+	if sha == "" {
+		return fmt.Errorf("MR !%d has no head commit", mr.IID)
+	}
+
+	status := gitlabclient.CommitStatus{
+		Name:        s.Name,
+		State:       "success",
+		Ref:         mr.SourceBranch,
+		TargetURL:   s.TargetURL,
+		Description: Summary(r),
+	}
+	if status.Name == "" {
+		status.Name = "danger"
+	}
+	if len(r.Fails) > 0 {
+		status.State = "failed"
+	}
+	if status.TargetURL == "" {
+		status.TargetURL = os.Getenv("CI_JOB_URL")
+	}
+	// attach the status to the running pipeline rather than a new external one
+	if id, err := strconv.ParseInt(os.Getenv("CI_PIPELINE_ID"), 10, 64); err == nil {
+		status.PipelineID = id
+	}
+	if _, err := s.Client.SetCommitStatus(ctx, pr.GitLab.Metadata().RepoSlug, sha, status); err != nil {
+		return fmt.Errorf("setting commit status: %w", err)
+	}
+	return nil
+}
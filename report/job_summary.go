@@ -0,0 +1,88 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	danger "github.com/danger/golang"
+)
+
+// jobSummaryLimit is the size GitHub accepts for the job summary of a step.
+const jobSummaryLimit = 1024 * 1024
+
+// JobSummary renders r as the markdown of a GitHub Actions job summary: a
+// table per kind of violation followed by the markdowns.
+func JobSummary(r danger.Results) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## Danger\n\n%s.\n", Summary(r))
+	for _, section := range []struct {
+		title string
+		icon  string
+		vs    []danger.Violation
+	}{
+		{"Failures", "🚫", r.Fails},
+		{"Warnings", "⚠️", r.Warnings},
+		{"Messages", "📖", r.Messages},
+	} {
+		if len(section.vs) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n### %s %s\n\n| Message | Location |\n| --- | --- |\n", section.icon, section.title)
+		for _, v := range section.vs {
+			location := ""
+			if v.File != "" {
+				location = "`" + v.File
+				if v.Line > 0 {
+					location += fmt.Sprintf(":%d", v.Line)
+				}
+				location += "`"
+			}
+			fmt.Fprintf(&sb, "| %s | %s |\n", tableCell(v.Message), location)
+		}
+	}
+	for _, m := range r.Markdowns {
+		sb.WriteString("\n" + m.Message + "\n")
+	}
+
+	s := sb.String()
+	if len(s) > jobSummaryLimit {
+		const note = "\n\n> [!NOTE]\n> The summary was truncated to fit the job summary size limit.\n"
+		cut := jobSummaryLimit - len(note)
+		// cut at a line boundary so markdown structures are not split midway
+		if i := strings.LastIndexByte(s[:cut], '\n'); i > 0 {
+			cut = i
+		}
+		s = s[:cut] + note
+	}
+	return s
+}
+
+// tableCell escapes s for use in a markdown table cell.
+func tableCell(s string) string {
+	s = strings.ReplaceAll(strings.TrimSpace(s), "|", `\|`)
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// WriteJobSummary appends the job summary of r to the file named by
+// GITHUB_STEP_SUMMARY. It does nothing outside of GitHub Actions.
+func WriteJobSummary(r danger.Results) error {
+	return WriteJobSummaryFile(os.Getenv("GITHUB_STEP_SUMMARY"), r)
+}
+
+// WriteJobSummaryFile appends the job summary of r to the file at path. It
+// does nothing when path is empty.
+func WriteJobSummaryFile(path string, r danger.Results) error {
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening job summary: %w", err)
+	}
+	if _, err := f.WriteString(JobSummary(r)); err != nil {
+		f.Close()
+		return fmt.Errorf("writing job summary: %w", err)
+	}
+	return f.Close()
+}
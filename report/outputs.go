@@ -0,0 +1,49 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	danger "github.com/danger/golang"
+)
+
+// Outputs are the step outputs of a run: fails_count, warnings_count,
+// messages_count and report_path, the results file when it was saved.
+func Outputs(r danger.Results, reportPath string) map[string]string {
+	return map[string]string{
+		"fails_count":    fmt.Sprint(len(r.Fails)),
+		"warnings_count": fmt.Sprint(len(r.Warnings)),
+		"messages_count": fmt.Sprint(len(r.Messages)),
+		"report_path":    reportPath,
+	}
+}
+
+// WriteOutputs appends the Outputs of r to the file named by GITHUB_OUTPUT,
+// so later workflow steps can read them. It does nothing outside of GitHub
+// Actions.
+func WriteOutputs(r danger.Results, reportPath string) error {
+	return WriteOutputsFile(os.Getenv("GITHUB_OUTPUT"), r, reportPath)
+}
+
+// WriteOutputsFile appends the Outputs of r to the step outputs file at
+// path. It does nothing when path is empty.
+func WriteOutputsFile(path string, r danger.Results, reportPath string) error {
+	if path == "" {
+		return nil
+	}
+	outputs := Outputs(r, reportPath)
+	var sb strings.Builder
+	for _, name := range []string{"fails_count", "warnings_count", "messages_count", "report_path"} {
+		fmt.Fprintf(&sb, "%s=%s\n", name, strings.ReplaceAll(outputs[name], "\n", " "))
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening step outputs: %w", err)
+	}
+	if _, err := f.WriteString(sb.String()); err != nil {
+		f.Close()
+		return fmt.Errorf("writing step outputs: %w", err)
+	}
+	return f.Close()
+}
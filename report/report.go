@@ -0,0 +1,36 @@
+// Package report publishes danger results outside the Danger comment, in the
+// formats code hosts render natively, such as GitLab commit statuses.
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	danger "github.com/danger/golang"
+)
+
+// Summary describes the counts of r in a short sentence, e.g.
+// "1 failure, 2 warnings".
+func Summary(r danger.Results) string {
+	var parts []string
+	for _, c := range []struct {
+		n         int
+		one, many string
+	}{
+		{len(r.Fails), "failure", "failures"},
+		{len(r.Warnings), "warning", "warnings"},
+		{len(r.Messages), "message", "messages"},
+	} {
+		switch c.n {
+		case 0:
+		case 1:
+			parts = append(parts, "1 "+c.one)
+		default:
+			parts = append(parts, fmt.Sprintf("%d %s", c.n, c.many))
+		}
+	}
+	if len(parts) == 0 {
+		return "No issues found"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,246 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+	"github.com/danger/golang/gitlabclient"
+)
+
+func gitLabPR() danger.DSL {
+	mr := dangerJs.GitLabMR{}
+	mr.IID = 4
+	mr.SourceBranch = "feature"
+	mr.SHA = "abc"
+	return danger.DSL{GitLab: fakedsl.GitLab{
+		MetadataData: dangerJs.RepoMetaData{RepoSlug: "group/project", PullRequestID: "4"},
+		MRData:       mr,
+	}}
+}
+
+func TestSummary(t *testing.T) {
+	require.Equal(t, "No issues found", Summary(danger.Results{}))
+	require.Equal(t, "1 failure, 2 warnings", Summary(danger.Results{
+		Fails:    []danger.Violation{{}},
+		Warnings: []danger.Violation{{}, {}},
+	}))
+}
+
+func TestGitLabStatus(t *testing.T) {
+	t.Setenv("CI_JOB_URL", "https://gitlab.example.com/job/1")
+	t.Setenv("CI_PIPELINE_ID", "77")
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "POST /api/v4/projects/group%2Fproject/statuses/abc", r.Method+" "+r.URL.EscapedPath())
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&got))
+		_, _ = w.Write([]byte(`{"id":1,"status":"failed"}`))
+	}))
+	defer srv.Close()
+
+	c, err := gitlabclient.New(srv.URL, gitlabclient.Token{Value: "t"})
+	require.Nil(t, err)
+
+	r := danger.Results{Fails: []danger.Violation{{Message: "missing changelog"}}}
+	require.Nil(t, GitLabStatus{Client: c}.Publish(context.Background(), gitLabPR(), r))
+	require.Equal(t, map[string]any{
+		"state":       "failed",
+		"name":        "danger",
+		"ref":         "feature",
+		"target_url":  "https://gitlab.example.com/job/1",
+		"description": "1 failure",
+		"pipeline_id": float64(77),
+	}, got)
+}
+
+func TestGitLabStatusNotGitLab(t *testing.T) {
+	err := GitLabStatus{}.Publish(context.Background(), danger.DSL{}, danger.Results{})
+	require.EqualError(t, err, "not running on a GitLab MR")
+}
+
+func TestCodeQuality(t *testing.T) {
+	r := danger.Results{
+		Fails:    []danger.Violation{{Message: "no tests", Rule: "tests", File: "a.go", Line: 3}},
+		Warnings: []danger.Violation{{Message: "TODO", File: "b.go"}, {Message: "TODO", File: "b.go", Line: 9}, {Message: "big PR"}},
+	}
+	issues := CodeQuality(r)
+	require.Len(t, issues, 3)
+	require.Equal(t, "tests", issues[0].CheckName)
+	require.Equal(t, "major", issues[0].Severity)
+	require.Equal(t, 3, issues[0].Location.Lines.Begin)
+	require.Equal(t, "danger", issues[1].CheckName)
+	require.Equal(t, "minor", issues[1].Severity)
+	require.Equal(t, 1, issues[1].Location.Lines.Begin)
+	require.NotEqual(t, issues[1].Fingerprint, issues[2].Fingerprint)
+
+	// fingerprints do not depend on lines, so moved code keeps its issues
+	r.Fails[0].Line = 30
+	require.Equal(t, issues[0].Fingerprint, CodeQuality(r)[0].Fingerprint)
+
+	var buf bytes.Buffer
+	require.Nil(t, WriteCodeQuality(&buf, danger.Results{}))
+	require.Equal(t, "[]\n", buf.String())
+}
+
+func TestJobSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+	require.Nil(t, os.WriteFile(path, []byte("earlier step\n"), 0o644))
+	t.Setenv("GITHUB_STEP_SUMMARY", path)
+
+	r := danger.Results{
+		Fails:     []danger.Violation{{Message: "a | b\nc", File: "main.go", Line: 3}},
+		Messages:  []danger.Violation{{Message: "thanks"}},
+		Markdowns: []danger.Violation{{Message: "### Coverage\n\n| Package | % |"}},
+	}
+	require.Nil(t, WriteJobSummary(r))
+	got, err := os.ReadFile(path)
+	require.Nil(t, err)
+	require.Equal(t, "earlier step\n## Danger\n\n1 failure, 1 message.\n"+
+		"\n### 🚫 Failures\n\n| Message | Location |\n| --- | --- |\n| a \\| b<br>c | `main.go:3` |\n"+
+		"\n### 📖 Messages\n\n| Message | Location |\n| --- | --- |\n| thanks |  |\n"+
+		"\n### Coverage\n\n| Package | % |\n", string(got))
+}
+
+func TestJobSummaryTruncated(t *testing.T) {
+	var r danger.Results
+	for range 20000 {
+		r.Warnings = append(r.Warnings, danger.Violation{Message: strings.Repeat("x", 100)})
+	}
+	got := JobSummary(r)
+	require.LessOrEqual(t, len(got), jobSummaryLimit)
+	require.True(t, strings.HasSuffix(got, "|\n\n> [!NOTE]\n> The summary was truncated to fit the job summary size limit.\n"))
+}
+
+func TestWriteAnnotations(t *testing.T) {
+	var buf bytes.Buffer
+	require.Nil(t, WriteAnnotations(&buf, danger.Results{
+		Fails:     []danger.Violation{{Message: "100% broken\nsee docs", File: "a,b.go", Line: 4}},
+		Warnings:  []danger.Violation{{Message: "big PR"}},
+		Messages:  []danger.Violation{{Message: "thanks", File: "README.md"}},
+		Markdowns: []danger.Violation{{Message: "### Table"}},
+	}))
+	require.Equal(t, "::error title=Danger,file=a%2Cb.go,line=4::100%25 broken%0Asee docs\n"+
+		"::warning title=Danger::big PR\n"+
+		"::notice title=Danger,file=README.md::thanks\n", buf.String())
+}
+
+func TestWriteOutputs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output")
+	require.Nil(t, os.WriteFile(path, []byte("earlier=1\n"), 0o644))
+	t.Setenv("GITHUB_OUTPUT", path)
+
+	r := danger.Results{
+		Fails:    []danger.Violation{{Message: "a"}, {Message: "b"}},
+		Warnings: []danger.Violation{{Message: "c"}},
+	}
+	require.Nil(t, WriteOutputs(r, "/tmp/danger-go-results.json"))
+	got, err := os.ReadFile(path)
+	require.Nil(t, err)
+	require.Equal(t, "earlier=1\nfails_count=2\nwarnings_count=1\nmessages_count=0\nreport_path=/tmp/danger-go-results.json\n", string(got))
+
+	t.Setenv("GITHUB_OUTPUT", "")
+	require.Nil(t, WriteOutputs(r, ""))
+}
+
+func TestAttestation(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	require.Nil(t, err)
+	r := danger.Results{
+		Fails:    []danger.Violation{{Message: "no changelog", Rule: "changelog"}},
+		Warnings: []danger.Violation{{Message: "big PR"}, {Message: "todo", Rule: "todos"}},
+		Messages: []danger.Violation{{Message: "thanks", Rule: "todos"}},
+	}
+	runs := []danger.RuleRun{
+		{Name: "changelog", Status: danger.RuleRan},
+		{Name: "todos", Status: danger.RuleRan},
+		{Name: "release notes", Status: danger.RuleSkipped, Reason: "not labeled `release`"},
+	}
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	dangerfile := filepath.Join(t.TempDir(), "dangerfile.go")
+	require.Nil(t, os.WriteFile(dangerfile, []byte("package main\n"), 0o600))
+	source, err := FileDigest("dangerfile.go", dangerfile)
+	require.Nil(t, err)
+	statement := NewStatement(r, runs, Subject{Repository: "danger/golang", Commit: "abc123", URL: "https://github.com/danger/golang/pull/4"}, now, source)
+
+	envelope, err := Sign(statement, key)
+	require.Nil(t, err)
+	require.Equal(t, PayloadType, envelope.PayloadType)
+	require.Len(t, envelope.Signatures, 1)
+	require.Equal(t, KeyID(key.Public().(ed25519.PublicKey)), envelope.Signatures[0].KeyID)
+
+	got, err := envelope.Verify(key.Public().(ed25519.PublicKey))
+	require.Nil(t, err)
+	require.Equal(t, StatementType, got.Type)
+	require.Equal(t, []StatementItem{{Name: "danger/golang", Digest: map[string]string{"gitCommit": "abc123"}}}, got.Subject)
+	require.Equal(t, PolicyPredicateType, got.PredicateType)
+	require.Equal(t, "fail", got.Predicate.Outcome)
+	require.Equal(t, now, got.Predicate.Timestamp)
+	require.Equal(t, []PolicyResult{
+		{Name: "dangerfile", Status: danger.RuleRan, Outcome: "warn", Warnings: 1},
+		{Name: "changelog", Status: danger.RuleRan, Outcome: "fail", Fails: 1},
+		{Name: "todos", Status: danger.RuleRan, Outcome: "warn", Warnings: 1, Messages: 1},
+		{Name: "release notes", Status: danger.RuleSkipped, Outcome: "pass", Reason: "not labeled `release`"},
+	}, got.Predicate.Policies)
+	require.Equal(t, []StatementItem{{Name: "dangerfile.go", Digest: map[string]string{
+		"sha256": "df1d036cbbf3df46e2045071e082245ece204c7f53ecf0a4e022bff9bb228f47",
+	}}}, got.Predicate.Sources)
+
+	other, _, err := ed25519.GenerateKey(nil)
+	require.Nil(t, err)
+	_, err = envelope.Verify(other)
+	require.NotNil(t, err)
+
+	envelope.Payload = base64.StdEncoding.EncodeToString([]byte(`{"predicate":{"outcome":"pass"}}`))
+	_, err = envelope.Verify(key.Public().(ed25519.PublicKey))
+	require.NotNil(t, err)
+}
+
+func TestAttestationUnsigned(t *testing.T) {
+	envelope, err := Sign(NewStatement(danger.Results{}, nil, Subject{Commit: "abc"}, time.Now()), nil)
+	require.Nil(t, err)
+	require.Empty(t, envelope.Signatures)
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	require.Nil(t, err)
+	var s Statement
+	require.Nil(t, json.Unmarshal(payload, &s))
+	require.Equal(t, "pass", s.Predicate.Outcome)
+	require.Empty(t, s.Predicate.Policies)
+}
+
+func TestParseSigningKey(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(nil)
+	require.Nil(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.Nil(t, err)
+	got, err := ParseSigningKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	require.Nil(t, err)
+	require.Equal(t, key, got)
+
+	_, err = ParseSigningKey([]byte("not a key"))
+	require.NotNil(t, err)
+}
+
+func TestSubjectOf(t *testing.T) {
+	t.Setenv("GITHUB_SHA", "")
+	t.Setenv("CI_COMMIT_SHA", "")
+	require.Equal(t, Subject{Repository: "group/project", Commit: "abc"}, SubjectOf(gitLabPR()))
+
+	t.Setenv("CI_COMMIT_SHA", "fromci")
+	require.Equal(t, Subject{Commit: "fromci"}, SubjectOf(danger.DSL{}))
+}
@@ -0,0 +1,285 @@
+// Package review assembles compact, token-budgeted context of the files a PR
+// changes for external code review services, and maps their findings to
+// Danger violations.
+package review
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"slices"
+	"strings"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// FileContext is the context of a changed file given to a Reviewer.
+type FileContext struct {
+	Path string
+	// Added and Removed are the changed lines, numbered in the head and base
+	// versions of the file respectively.
+	Added   []dangerJs.DiffLine
+	Removed []dangerJs.DiffLine
+	// Symbols are the signatures of the Go declarations containing added
+	// lines, e.g. "func (c *Client) Do(ctx context.Context) error".
+	Symbols []string
+	// Excerpts are the lines of the head version surrounding the changes.
+	Excerpts []Excerpt
+	// Related are files related to the file, e.g. its tests.
+	Related []RelatedFile
+	// Truncated is set when parts were left out to fit the token budget.
+	Truncated bool
+}
+
+// Excerpt is a run of lines of a file starting at StartLine.
+type Excerpt struct {
+	StartLine int
+	Lines     []string
+}
+
+// RelatedFile is a file related to the reviewed one.
+type RelatedFile struct {
+	Path    string
+	Content string
+}
+
+// EstimateTokens estimates the number of tokens of s for language models,
+// which average about four bytes per token for code.
+func EstimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// String renders the context as compact text.
+func (c FileContext) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "File: %s\n", c.Path)
+	if len(c.Added)+len(c.Removed) > 0 {
+		sb.WriteString("\nChanged lines:\n")
+		for _, l := range c.Removed {
+			sb.WriteString(removedLine(l))
+		}
+		for _, l := range c.Added {
+			sb.WriteString(addedLine(l))
+		}
+	}
+	if len(c.Symbols) > 0 {
+		sb.WriteString("\nChanged declarations:\n")
+		for _, s := range c.Symbols {
+			sb.WriteString(symbolLine(s))
+		}
+	}
+	for _, e := range c.Excerpts {
+		sb.WriteString(excerptText(e))
+	}
+	for _, r := range c.Related {
+		sb.WriteString(relatedText(r))
+	}
+	if c.Truncated {
+		sb.WriteString("\n(truncated)\n")
+	}
+	return sb.String()
+}
+
+func removedLine(l dangerJs.DiffLine) string {
+	return fmt.Sprintf("-%d: %s\n", l.Line, l.Content)
+}
+
+func addedLine(l dangerJs.DiffLine) string {
+	return fmt.Sprintf("+%d: %s\n", l.Line, l.Content)
+}
+
+func symbolLine(s string) string {
+	return "- " + s + "\n"
+}
+
+func excerptText(e Excerpt) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\nLines %d-%d:\n", e.StartLine, e.StartLine+len(e.Lines)-1)
+	for i, l := range e.Lines {
+		fmt.Fprintf(&sb, "%d: %s\n", e.StartLine+i, l)
+	}
+	return sb.String()
+}
+
+func relatedText(r RelatedFile) string {
+	return fmt.Sprintf("\nRelated file %s:\n%s\n", r.Path, strings.TrimRight(r.Content, "\n"))
+}
+
+// ContextBuilder assembles FileContexts.
+type ContextBuilder struct {
+	// BaseRef and HeadRef are the refs diffed, HEAD^ and HEAD when empty.
+	BaseRef string
+	HeadRef string
+	// ContextLines is the number of lines shown around changes, 5 when
+	// zero.
+	ContextLines int
+	// TokenBudget bounds the size of the rendered context, as estimated by
+	// EstimateTokens, 4000 when zero.
+	TokenBudget int
+}
+
+func (b ContextBuilder) refs() (string, string) {
+	base, head := b.BaseRef, b.HeadRef
+	if base == "" {
+		base = "HEAD^"
+	}
+	if head == "" {
+		head = "HEAD"
+	}
+	return base, head
+}
+
+// Build assembles the context of file. When it exceeds the token budget the
+// related files are dropped first, then excerpts, declarations and changed
+// lines, from the end.
+func (b ContextBuilder) Build(pr danger.DSL, file string) (FileContext, error) {
+	base, head := b.refs()
+	diff, err := pr.Git.DiffForFileWithRefs(file, base, head)
+	if err != nil {
+		return FileContext{}, fmt.Errorf("diffing %s: %w", file, err)
+	}
+	src, err := dangerJs.FileAtRef(pr.Git, file, head)
+	if err != nil {
+		return FileContext{}, fmt.Errorf("reading %s: %w", file, err)
+	}
+
+	c := FileContext{Path: dangerJs.NormalizePath(file), Added: diff.AddedLines, Removed: diff.RemovedLines}
+	changed := make([]int, 0, len(diff.AddedLines))
+	for _, l := range diff.AddedLines {
+		changed = append(changed, l.Line)
+	}
+	n := b.ContextLines
+	if n == 0 {
+		n = 5
+	}
+	c.Excerpts = excerpts(strings.Split(strings.TrimSuffix(src, "\n"), "\n"), changed, n)
+	if strings.HasSuffix(file, ".go") {
+		c.Symbols = goSymbols(file, src, changed)
+	}
+	for _, related := range relatedFiles(file) {
+		if content, err := dangerJs.FileAtRef(pr.Git, related, head); err == nil {
+			c.Related = append(c.Related, RelatedFile{Path: related, Content: content})
+		}
+	}
+
+	budget := b.TokenBudget
+	if budget == 0 {
+		budget = 4000
+	}
+	c.fit(budget)
+	return c, nil
+}
+
+// excerpts returns the lines within n lines of the changed ones, merging
+// overlapping runs.
+func excerpts(lines []string, changed []int, n int) []Excerpt {
+	slices.Sort(changed)
+	var out []Excerpt
+	end := 0 // last line of the previous excerpt
+	for _, line := range changed {
+		from, to := max(line-n, 1), min(line+n, len(lines))
+		if from > to {
+			continue
+		}
+		if len(out) > 0 && from <= end+1 {
+			last := &out[len(out)-1]
+			if to > end {
+				last.Lines = append(last.Lines, lines[end:to]...)
+				end = to
+			}
+			continue
+		}
+		out = append(out, Excerpt{StartLine: from, Lines: slices.Clone(lines[from-1 : to])})
+		end = to
+	}
+	return out
+}
+
+// goSymbols returns the signatures of the top-level declarations of src
+// containing any of the changed lines.
+func goSymbols(file, src string, changed []int) []string {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	var symbols []string
+	for _, decl := range f.Decls {
+		start, end := fset.Position(decl.Pos()).Line, fset.Position(decl.End()).Line
+		if !slices.ContainsFunc(changed, func(l int) bool { return l >= start && l <= end }) {
+			continue
+		}
+		var node any
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			node = &ast.FuncDecl{Recv: d.Recv, Name: d.Name, Type: d.Type}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					symbols = append(symbols, "type "+s.Name.Name)
+				case *ast.ValueSpec:
+					for _, name := range s.Names {
+						symbols = append(symbols, d.Tok.String()+" "+name.Name)
+					}
+				}
+			}
+			continue
+		}
+		var buf bytes.Buffer
+		if err := printer.Fprint(&buf, fset, node); err == nil {
+			symbols = append(symbols, buf.String())
+		}
+	}
+	return symbols
+}
+
+// relatedFiles returns the files conventionally related to file: the tests
+// of a Go file, or the file a Go test covers.
+func relatedFiles(file string) []string {
+	file = dangerJs.NormalizePath(file)
+	if !strings.HasSuffix(file, ".go") {
+		return nil
+	}
+	if base, ok := strings.CutSuffix(file, "_test.go"); ok {
+		return []string{base + ".go"}
+	}
+	dir, name := path.Split(file)
+	return []string{dir + strings.TrimSuffix(name, ".go") + "_test.go"}
+}
+
+// fit drops parts of c until its rendering fits budget tokens.
+func (c *FileContext) fit(budget int) {
+	tokens := EstimateTokens(c.String())
+	if tokens <= budget {
+		return
+	}
+	c.Truncated = true
+	tokens += EstimateTokens("\n(truncated)\n")
+	for tokens > budget {
+		switch {
+		case len(c.Related) > 0:
+			tokens -= EstimateTokens(relatedText(c.Related[len(c.Related)-1]))
+			c.Related = c.Related[:len(c.Related)-1]
+		case len(c.Excerpts) > 0:
+			tokens -= EstimateTokens(excerptText(c.Excerpts[len(c.Excerpts)-1]))
+			c.Excerpts = c.Excerpts[:len(c.Excerpts)-1]
+		case len(c.Symbols) > 0:
+			tokens -= EstimateTokens(symbolLine(c.Symbols[len(c.Symbols)-1]))
+			c.Symbols = c.Symbols[:len(c.Symbols)-1]
+		case len(c.Removed) > 0:
+			tokens -= EstimateTokens(removedLine(c.Removed[len(c.Removed)-1]))
+			c.Removed = c.Removed[:len(c.Removed)-1]
+		case len(c.Added) > 1:
+			tokens -= EstimateTokens(addedLine(c.Added[len(c.Added)-1]))
+			c.Added = c.Added[:len(c.Added)-1]
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+const source = `package calc
+
+import "fmt"
+
+// Add adds.
+func Add(a, b int) int {
+	return a + b
+}
+
+type Op int
+
+// Describe describes op.
+func (o Op) Describe(verbose bool) string {
+	if verbose {
+		return fmt.Sprintf("op %d", o)
+	}
+	return "op"
+}
+`
+
+func testPR() danger.DSL {
+	return danger.DSL{Git: fakedsl.Git{
+		ModifiedFilesList: []string{"calc/calc.go", "README.md"},
+		Diffs: map[string]dangerJs.FileDiff{"calc/calc.go": {
+			AddedLines:   []dangerJs.DiffLine{{Content: "\t\treturn fmt.Sprintf(\"op %d\", o)", Line: 15}},
+			RemovedLines: []dangerJs.DiffLine{{Content: "\t\treturn \"op!\"", Line: 15}},
+		}},
+		Files: map[string]string{
+			"calc/calc.go":      source,
+			"calc/calc_test.go": "package calc\n\nfunc TestDescribe(t *testing.T) {}\n",
+		},
+	}}
+}
+
+func TestBuild(t *testing.T) {
+	c, err := ContextBuilder{ContextLines: 2}.Build(testPR(), "calc/calc.go")
+	require.Nil(t, err)
+	require.Equal(t, []string{"func (o Op) Describe(verbose bool) string"}, c.Symbols)
+	require.Equal(t, []Excerpt{{StartLine: 13, Lines: []string{
+		"func (o Op) Describe(verbose bool) string {",
+		"\tif verbose {",
+		"\t\treturn fmt.Sprintf(\"op %d\", o)",
+		"\t}",
+		"\treturn \"op\"",
+	}}}, c.Excerpts)
+	require.Equal(t, []RelatedFile{{Path: "calc/calc_test.go", Content: "package calc\n\nfunc TestDescribe(t *testing.T) {}\n"}}, c.Related)
+	require.False(t, c.Truncated)
+	require.Equal(t, `File: calc/calc.go
+
+Changed lines:
+-15: 		return "op!"
++15: 		return fmt.Sprintf("op %d", o)
+
+Changed declarations:
+- func (o Op) Describe(verbose bool) string
+
+Lines 13-17:
+13: func (o Op) Describe(verbose bool) string {
+14: 	if verbose {
+15: 		return fmt.Sprintf("op %d", o)
+16: 	}
+17: 	return "op"
+
+Related file calc/calc_test.go:
+package calc
+
+func TestDescribe(t *testing.T) {}
+`, c.String())
+}
+
+func TestBuildTokenBudget(t *testing.T) {
+	c, err := ContextBuilder{ContextLines: 2, TokenBudget: 60}.Build(testPR(), "calc/calc.go")
+	require.Nil(t, err)
+	require.True(t, c.Truncated)
+	require.Empty(t, c.Related, "related files go first")
+	require.Empty(t, c.Excerpts)
+	require.Len(t, c.Added, 1)
+	require.LessOrEqual(t, EstimateTokens(c.String()), 60)
+}
+
+func TestExcerptsMerge(t *testing.T) {
+	lines := strings.Split("1 2 3 4 5 6 7 8 9 10 11 12", " ")
+	require.Equal(t, []Excerpt{
+		{StartLine: 1, Lines: []string{"1", "2", "3", "4", "5", "6"}},
+		{StartLine: 9, Lines: []string{"9", "10", "11", "12"}},
+	}, excerpts(lines, []int{4, 2, 11}, 2))
+}
+
+func TestRun(t *testing.T) {
+	var reviewed []string
+	reviewer := ReviewerFunc(func(_ context.Context, c FileContext) ([]Finding, error) {
+		reviewed = append(reviewed, c.Path)
+		return []Finding{
+			{Line: 15, Severity: SeverityFail, Message: "format verb"},
+			{Message: "consider a test"},
+			{Path: "calc/calc_test.go", Severity: SeverityMessage, Message: "nice"},
+		}, nil
+	})
+	d := danger.New()
+	Config{Reviewer: reviewer, Files: []string{"**/*.go"}}.Run(d, testPR())
+
+	require.Equal(t, []string{"calc/calc.go"}, reviewed)
+	r := d.Snapshot()
+	require.Equal(t, []danger.Violation{{Message: "format verb", File: "calc/calc.go", Line: 15}}, r.Fails)
+	require.Equal(t, []danger.Violation{{Message: "consider a test", File: "calc/calc.go"}}, r.Warnings)
+	require.Equal(t, []danger.Violation{{Message: "nice", File: "calc/calc_test.go"}}, r.Messages)
+}
+
+func TestRunErrors(t *testing.T) {
+	reviewer := ReviewerFunc(func(context.Context, FileContext) ([]Finding, error) {
+		return nil, fmt.Errorf("service unavailable")
+	})
+	d := danger.New()
+	Config{Reviewer: reviewer, MaxFiles: 1}.Run(d, testPR())
+	r := d.Snapshot()
+	require.Equal(t, []danger.Violation{{Message: "Could not review `calc/calc.go`: service unavailable"}}, r.Warnings)
+	require.Equal(t, []danger.Violation{{Message: "Only the first 1 changed files were reviewed."}}, r.Messages)
+}
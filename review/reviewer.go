@@ -0,0 +1,116 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// Severity is how a finding is reported.
+type Severity string
+
+const (
+	SeverityFail    Severity = "fail"
+	SeverityWarning Severity = "warning"
+	SeverityMessage Severity = "message"
+)
+
+// Finding is an issue a Reviewer found in a file.
+type Finding struct {
+	// Path is the file of the finding, the reviewed file when empty.
+	Path string
+	// Line is the head line of the finding, zero for the whole file.
+	Line int
+	// Severity is the kind of violation added, a warning when empty.
+	Severity Severity
+	Message  string
+}
+
+// Reviewer reviews the changes of a file, e.g. by calling a code review
+// service.
+type Reviewer interface {
+	Review(ctx context.Context, c FileContext) ([]Finding, error)
+}
+
+// ReviewerFunc adapts a function to Reviewer.
+type ReviewerFunc func(ctx context.Context, c FileContext) ([]Finding, error)
+
+// Review calls f.
+func (f ReviewerFunc) Review(ctx context.Context, c FileContext) ([]Finding, error) {
+	return f(ctx, c)
+}
+
+// Config configures a review of the changed files.
+type Config struct {
+	Reviewer Reviewer
+	Builder  ContextBuilder
+	// Files are the patterns of the files reviewed, see dangerJs.MatchPath.
+	// All created and modified files are reviewed when empty.
+	Files []string
+	// MaxFiles caps the number of files reviewed, unlimited when zero.
+	MaxFiles int
+	// Timeout bounds the review of each file, a minute when zero.
+	Timeout time.Duration
+}
+
+// Run reviews the created and modified files matching c.Files and adds the
+// findings as violations.
+func (c Config) Run(d *danger.T, pr danger.DSL) {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = time.Minute
+	}
+	reviewed := 0
+	for _, file := range append(pr.Git.CreatedFiles(), pr.Git.ModifiedFiles()...) {
+		if !c.matches(file) {
+			continue
+		}
+		if c.MaxFiles > 0 && reviewed == c.MaxFiles {
+			d.Message(fmt.Sprintf("Only the first %d changed files were reviewed.", c.MaxFiles), "", 0)
+			return
+		}
+		reviewed++
+
+		fc, err := c.Builder.Build(pr, file)
+		if err != nil {
+			d.Warn(fmt.Sprintf("Could not review `%s`: %s", file, err), "", 0)
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		findings, err := c.Reviewer.Review(ctx, fc)
+		cancel()
+		if err != nil {
+			d.Warn(fmt.Sprintf("Could not review `%s`: %s", file, err), "", 0)
+			continue
+		}
+		for _, f := range findings {
+			path := f.Path
+			if path == "" {
+				path = fc.Path
+			}
+			switch f.Severity {
+			case SeverityFail:
+				d.Fail(f.Message, path, f.Line)
+			case SeverityMessage:
+				d.Message(f.Message, path, f.Line)
+			default:
+				d.Warn(f.Message, path, f.Line)
+			}
+		}
+	}
+}
+
+func (c Config) matches(file string) bool {
+	if len(c.Files) == 0 {
+		return true
+	}
+	for _, p := range c.Files {
+		if dangerJs.MatchPath(p, file) {
+			return true
+		}
+	}
+	return false
+}
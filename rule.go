@@ -0,0 +1,120 @@
+package danger
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	"github.com/danger/golang/tracing"
+)
+
+// Rule is a named check. Violations added while a rule runs are attributed to
+// it, so reporters can group results by rule.
+type Rule struct {
+	Name string
+	Run  func(d *T, pr DSL)
+	// When are the conditions the rule runs under, e.g. SkipIfLabel. The
+	// rule runs when all of them are met.
+	When []Condition
+	// Strict keeps the failures of the rule while SetFailsAsWarnings is on,
+	// for checks which must hold for every author, e.g. secrets or licenses.
+	Strict bool
+}
+
+// RuleStatus is how a rule passed to RunRules ended.
+type RuleStatus string
+
+const (
+	RuleRan      RuleStatus = "ran"
+	RuleSkipped  RuleStatus = "skipped"
+	RulePanicked RuleStatus = "panicked"
+	// RuleNotRun rules were skipped after an earlier rule panicked.
+	RuleNotRun RuleStatus = "not_run"
+)
+
+// RuleRun records a rule passed to RunRules.
+type RuleRun struct {
+	Name   string
+	Status RuleStatus
+	// Reason is why a skipped rule didn't run.
+	Reason string
+	// WaivedBy is who waived the failures of the rule, see ApplyWaivers.
+	WaivedBy string
+}
+
+// RuleRuns returns the rules passed to RunRules so far, in order, e.g. to
+// attest which policies ran.
+func (s *T) RuleRuns() []RuleRun {
+	return append([]RuleRun{}, s.ruleRuns...)
+}
+
+// RunRules runs each rule in order against the DSL. If a rule panics the panic
+// is reported as a fail and the remaining rules are listed as skipped, so the
+// results gathered so far are still posted. Rules whose conditions aren't met
+// are skipped, and listed with the reason in a collapsed section.
+func (s *T) RunRules(pr DSL, rules ...Rule) {
+	var gatedRules []gated
+	defer func() { s.reportGated(gatedRules) }()
+	for i, r := range rules {
+		if reason, skip := gate(pr, r); skip {
+			gatedRules = append(gatedRules, gated{rule: r.Name, reason: reason})
+			s.ruleRuns = append(s.ruleRuns, RuleRun{Name: r.Name, Status: RuleSkipped, Reason: reason})
+			continue
+		}
+		if !s.runRule(pr, r) {
+			s.ruleRuns = append(s.ruleRuns, RuleRun{Name: r.Name, Status: RulePanicked})
+			for _, rest := range rules[i+1:] {
+				s.ruleRuns = append(s.ruleRuns, RuleRun{Name: rest.Name, Status: RuleNotRun})
+			}
+			s.skipped(rules[i+1:])
+			return
+		}
+		s.ruleRuns = append(s.ruleRuns, RuleRun{Name: r.Name, Status: RuleRan})
+	}
+}
+
+func (s *T) runRule(pr DSL, r Rule) bool {
+	_, span := tracing.Start(context.Background(), "rule "+r.Name)
+	defer span.End()
+
+	prev, prevStrict := s.rule, s.strict
+	s.rule, s.strict = r.Name, r.Strict
+	defer func() { s.rule, s.strict = prev, prevStrict }()
+	return s.RunSafely(pr, r.Run)
+}
+
+// RunSafely runs fn, converting a panic into a fail with the stack trace
+// rather than crashing the run. It reports whether fn completed.
+func (s *T) RunSafely(pr DSL, fn func(d *T, pr DSL)) (completed bool) {
+	defer func() {
+		if v := recover(); v != nil {
+			s.failPanic(v, debug.Stack())
+			completed = false
+		}
+	}()
+	fn(s, pr)
+	return true
+}
+
+func (s *T) failPanic(v any, stack []byte) {
+	what := "Dangerfile"
+	if s.rule != "" {
+		what = fmt.Sprintf("Rule `%s`", s.rule)
+	}
+	s.Fail(fmt.Sprintf("%s panicked: %v", what, v), "", 0)
+	s.Markdown(fmt.Sprintf("<details>\n<summary>%s panic stack trace</summary>\n\n```\n%s\n```\n</details>",
+		what, strings.TrimSpace(string(stack))), "", 0)
+}
+
+func (s *T) skipped(rules []Rule) {
+	if len(rules) == 0 {
+		return
+	}
+	var sb strings.Builder
+	sb.WriteString("The following rules were skipped after a panic:\n")
+	for _, r := range rules {
+		fmt.Fprintf(&sb, "\n- `%s`", r.Name)
+	}
+	s.Markdown(sb.String(), "", 0)
+}
@@ -0,0 +1,121 @@
+package danger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+func TestRunRules(t *testing.T) {
+	d := New()
+
+	d.RunRules(DSL{},
+		Rule{Name: "first", Run: func(d *T, _ DSL) {
+			d.Warn("from first", "a.go", 1)
+		}},
+		Rule{Name: "second", Run: func(d *T, _ DSL) {
+			d.Fail("from second", "", 0)
+		}},
+	)
+	d.Message("outside", "", 0)
+
+	require.Equal(t, []Violation{{Message: "from first", File: "a.go", Line: 1, Rule: "first"}}, d.results.Warnings)
+	require.Equal(t, []Violation{{Message: "from second", Rule: "second"}}, d.results.Fails)
+	require.Equal(t, []Violation{{Message: "outside"}}, d.results.Messages)
+}
+
+func TestSnapshot(t *testing.T) {
+	d := New()
+	d.Message("one", "", 0)
+
+	r := d.Snapshot()
+	d.Message("two", "", 0)
+
+	require.Equal(t, []Violation{{Message: "one"}}, r.Messages)
+	require.Len(t, d.Snapshot().Messages, 2)
+}
+
+func TestRunRulesPanic(t *testing.T) {
+	d := New()
+
+	d.RunRules(DSL{},
+		Rule{Name: "first", Run: func(d *T, _ DSL) {
+			d.Warn("from first", "", 0)
+		}},
+		Rule{Name: "broken", Run: func(d *T, _ DSL) {
+			d.Message("before panic", "", 0)
+			var m map[string]int
+			m["boom"]++
+		}},
+		Rule{Name: "third", Run: func(d *T, _ DSL) {
+			d.Fail("from third", "", 0)
+		}},
+		Rule{Name: "fourth", Run: func(d *T, _ DSL) {}},
+	)
+
+	require.Len(t, d.results.Warnings, 1)
+	require.Equal(t, []Violation{{Message: "before panic", Rule: "broken"}}, d.results.Messages)
+	require.Len(t, d.results.Fails, 1)
+	require.Equal(t, "Rule `broken` panicked: assignment to entry in nil map", d.results.Fails[0].Message)
+	require.Equal(t, "broken", d.results.Fails[0].Rule)
+
+	require.Len(t, d.results.Markdowns, 2)
+	require.Contains(t, d.results.Markdowns[0].Message, "<summary>Rule `broken` panic stack trace</summary>")
+	require.Contains(t, d.results.Markdowns[0].Message, "rule_internal_test.go")
+	require.Equal(t, "The following rules were skipped after a panic:\n\n- `third`\n- `fourth`", d.results.Markdowns[1].Message)
+	require.Empty(t, d.results.Markdowns[1].Rule)
+	require.Equal(t, []RuleRun{
+		{Name: "first", Status: RuleRan},
+		{Name: "broken", Status: RulePanicked},
+		{Name: "third", Status: RuleNotRun},
+		{Name: "fourth", Status: RuleNotRun},
+	}, d.RuleRuns())
+}
+
+func TestRunSafely(t *testing.T) {
+	d := New()
+
+	require.True(t, d.RunSafely(DSL{}, func(d *T, _ DSL) {
+		d.Message("ok", "", 0)
+	}))
+	require.False(t, d.RunSafely(DSL{}, func(*T, DSL) {
+		panic("nope")
+	}))
+
+	require.Equal(t, []Violation{{Message: "Dangerfile panicked: nope"}}, d.results.Fails)
+	require.Len(t, d.results.Messages, 1)
+}
+
+func TestRunRulesConditions(t *testing.T) {
+	data, err := dangerJs.DecodeDSL([]byte(`{"git": {}, "github": {
+		"pr": {"number": 1},
+		"issue": {"labels": [{"name": "Skip-Changelog"}, {"name": "bug"}]}
+	}}`))
+	require.Nil(t, err)
+	pr := data.ToInterface()
+
+	var ran []string
+	rule := func(name string, when ...Condition) Rule {
+		return Rule{Name: name, When: when, Run: func(*T, DSL) { ran = append(ran, name) }}
+	}
+	d := New()
+	d.RunRules(pr,
+		rule("changelog", SkipIfLabel("skip-changelog")),
+		rule("release notes", OnlyIfLabel("release", "hotfix")),
+		rule("bug template", OnlyIfLabel("bug"), SkipIfLabel("wontfix")),
+		rule("always"),
+	)
+
+	require.Equal(t, []string{"bug template", "always"}, ran)
+	require.Equal(t, []RuleRun{
+		{Name: "changelog", Status: RuleSkipped, Reason: "labeled `Skip-Changelog`"},
+		{Name: "release notes", Status: RuleSkipped, Reason: "not labeled `release` or `hotfix`"},
+		{Name: "bug template", Status: RuleRan},
+		{Name: "always", Status: RuleRan},
+	}, d.RuleRuns())
+	require.Equal(t, []Violation{{Message: "<details>\n<summary>Skipped rules (2)</summary>\n\n" +
+		"- `changelog`: labeled `Skip-Changelog`\n" +
+		"- `release notes`: not labeled `release` or `hotfix`\n</details>"}}, d.results.Markdowns)
+}
@@ -0,0 +1,200 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	danger "github.com/danger/golang"
+)
+
+// Binaries are the paths of a binary built from the base and the head of
+// the PR.
+type Binaries struct {
+	Base string
+	Head string
+}
+
+// BinarySize compares the size of binaries built from the base and head of
+// the PR and posts a size-delta table. Binaries are either built from Targets
+// in temporary git worktrees, or taken from Prebuilt.
+type BinarySize struct {
+	Refs
+	// Targets are the main packages to build, e.g. "./cmd/app".
+	Targets []string
+	// BuildFlags are passed to go build, e.g. "-trimpath".
+	BuildFlags []string
+	// Prebuilt maps target names to binaries built by an earlier CI step.
+	// Targets is ignored when set.
+	Prebuilt map[string]Binaries
+	// WarnIncrease and FailIncrease are the relative growths, e.g. 0.05 for
+	// 5%, beyond which a warning or failure is added. Zero disables them.
+	WarnIncrease float64
+	FailIncrease float64
+	// MaxSize fails when a head binary is larger, in bytes. Zero disables
+	// it.
+	MaxSize int64
+}
+
+// NewBinarySize returns a BinarySize rule building targets with -trimpath
+// and warning about growths beyond 5%.
+func NewBinarySize(targets ...string) BinarySize {
+	return BinarySize{Targets: targets, BuildFlags: []string{"-trimpath"}, WarnIncrease: 0.05}
+}
+
+// BinaryDelta is the size change of one binary.
+type BinaryDelta struct {
+	Target string
+	Base   int64
+	Head   int64
+}
+
+// Change is the relative growth of the binary, e.g. 0.1 for 10%.
+func (b BinaryDelta) Change() float64 {
+	if b.Base == 0 {
+		return 0
+	}
+	return float64(b.Head-b.Base) / float64(b.Base)
+}
+
+// Run builds or reads the binaries and reports their size changes.
+func (b BinarySize) Run(d *danger.T, pr danger.DSL) {
+	binaries := b.Prebuilt
+	if binaries == nil {
+		built, cleanup, err := b.build()
+		defer cleanup()
+		if err != nil {
+			d.Warn(fmt.Sprintf("Could not build binaries to compare their size: %s", err), "", 0)
+			return
+		}
+		binaries = built
+	}
+
+	var deltas []BinaryDelta
+	for target, bin := range binaries {
+		base, err := fileSize(bin.Base)
+		if err != nil {
+			d.Warn(fmt.Sprintf("Could not compare the size of `%s`: %s", target, err), "", 0)
+			continue
+		}
+		head, err := fileSize(bin.Head)
+		if err != nil {
+			d.Warn(fmt.Sprintf("Could not compare the size of `%s`: %s", target, err), "", 0)
+			continue
+		}
+		deltas = append(deltas, BinaryDelta{Target: target, Base: base, Head: head})
+	}
+	if len(deltas) == 0 {
+		return
+	}
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i].Target < deltas[j].Target })
+
+	for _, delta := range deltas {
+		change := delta.Change()
+		switch {
+		case b.MaxSize > 0 && delta.Head > b.MaxSize:
+			d.Fail(d.Msg("rules.binsize.over_limit", map[string]any{
+				"target": delta.Target, "size": formatBytes(delta.Head), "limit": formatBytes(b.MaxSize),
+			}), "", 0)
+		case b.FailIncrease > 0 && change > b.FailIncrease:
+			d.Fail(grew(d, delta, change), "", 0)
+		case b.WarnIncrease > 0 && change > b.WarnIncrease:
+			d.Warn(grew(d, delta, change), "", 0)
+		}
+	}
+	d.Markdown(binarySizeTable(deltas), "", 0)
+}
+
+func fileSize(p string) (int64, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+func binarySizeTable(deltas []BinaryDelta) string {
+	var sb strings.Builder
+	sb.WriteString("### Binary size\n\n| Target | Base | Head | Change |\n| --- | ---: | ---: | ---: |\n")
+	for _, delta := range deltas {
+		fmt.Fprintf(&sb, "| `%s` | %s | %s | %+d B (%+.1f%%) |\n", delta.Target,
+			formatBytes(delta.Base), formatBytes(delta.Head), delta.Head-delta.Base, delta.Change()*100)
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// grew renders the message of a binary which grew by change.
+func grew(d *danger.T, delta BinaryDelta, change float64) string {
+	return d.Msg("rules.binsize.grew", map[string]any{
+		"target": delta.Target, "change": fmt.Sprintf("%+.1f%%", change*100), "size": formatBytes(delta.Head),
+	})
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	v, suffix := float64(n), ""
+	for _, s := range []string{"KiB", "MiB", "GiB"} {
+		v /= unit
+		suffix = s
+		if v < unit {
+			break
+		}
+	}
+	return fmt.Sprintf("%.1f %s", v, suffix)
+}
+
+// build builds the targets from worktrees of the base and head refs. The
+// returned cleanup removes the worktrees and binaries.
+func (b BinarySize) build() (map[string]Binaries, func(), error) {
+	tmp, err := os.MkdirTemp("", "danger-binsize-")
+	if err != nil {
+		return nil, func() {}, fmt.Errorf("creating temp directory: %w", err)
+	}
+	var worktrees []string
+	cleanup := func() {
+		for _, w := range worktrees {
+			_ = exec.Command("git", "worktree", "remove", "--force", w).Run()
+		}
+		_ = os.RemoveAll(tmp)
+	}
+
+	base, head := b.refs()
+	dirs := map[string]string{}
+	for _, side := range []struct{ name, ref string }{{"base", base}, {"head", head}} {
+		if strings.HasPrefix(side.ref, "-") || strings.ContainsAny(side.ref, " \t\n") {
+			return nil, cleanup, fmt.Errorf("invalid git ref: %s", side.ref)
+		}
+		dir := filepath.Join(tmp, side.name)
+		if out, err := exec.Command("git", "worktree", "add", "--detach", dir, side.ref).CombinedOutput(); err != nil {
+			return nil, cleanup, fmt.Errorf("checking out %s: %w: %s", side.ref, err, strings.TrimSpace(string(out)))
+		}
+		worktrees = append(worktrees, dir)
+		dirs[side.name] = dir
+	}
+
+	binaries := map[string]Binaries{}
+	for _, target := range b.Targets {
+		name := path.Base(strings.TrimSuffix(target, "/"))
+		bin := Binaries{
+			Base: filepath.Join(tmp, "bin", "base", name),
+			Head: filepath.Join(tmp, "bin", "head", name),
+		}
+		for _, side := range []struct{ dir, out string }{{dirs["base"], bin.Base}, {dirs["head"], bin.Head}} {
+			args := append(append([]string{"build", "-o", side.out}, b.BuildFlags...), target)
+			cmd := exec.Command("go", args...)
+			cmd.Dir = side.dir
+			if out, err := cmd.CombinedOutput(); err != nil {
+				return nil, cleanup, fmt.Errorf("building %s: %w: %s", target, err, strings.TrimSpace(string(out)))
+			}
+		}
+		binaries[name] = bin
+	}
+	return binaries, cleanup, nil
+}
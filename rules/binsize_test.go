@@ -0,0 +1,83 @@
+package rules
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+)
+
+func writeSized(t *testing.T, name string, size int) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	require.Nil(t, os.WriteFile(p, make([]byte, size), 0o600))
+	return p
+}
+
+func TestBinarySizePrebuilt(t *testing.T) {
+	rule := BinarySize{
+		Prebuilt: map[string]Binaries{
+			"cli":   {Base: writeSized(t, "cli-base", 10240), Head: writeSized(t, "cli-head", 12288)},
+			"agent": {Base: writeSized(t, "agent-base", 2048), Head: writeSized(t, "agent-head", 2100)},
+		},
+		WarnIncrease: 0.01,
+		FailIncrease: 0.1,
+	}
+
+	d := danger.New()
+	rule.Run(d, danger.DSL{})
+
+	r := results(t, d)
+	require.Equal(t, []danger.Violation{{Message: "`cli` grew by +20.0% to 12.0 KiB."}}, r.Fails)
+	require.Equal(t, []danger.Violation{{Message: "`agent` grew by +2.5% to 2.1 KiB."}}, r.Warnings)
+	require.Equal(t, "### Binary size\n\n| Target | Base | Head | Change |\n| --- | ---: | ---: | ---: |\n"+
+		"| `agent` | 2.0 KiB | 2.1 KiB | +52 B (+2.5%) |\n"+
+		"| `cli` | 10.0 KiB | 12.0 KiB | +2048 B (+20.0%) |", r.Markdowns[0].Message)
+}
+
+func TestBinarySizeMaxSize(t *testing.T) {
+	rule := BinarySize{
+		Prebuilt: map[string]Binaries{"cli": {Base: writeSized(t, "base", 100), Head: writeSized(t, "head", 100)}},
+		MaxSize:  50,
+	}
+	d := danger.New()
+	rule.Run(d, danger.DSL{})
+	require.Equal(t, []danger.Violation{{Message: "`cli` is 100 B, over the limit of 50 B."}}, results(t, d).Fails)
+}
+
+func TestBinarySizeBuild(t *testing.T) {
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.Nil(t, err, string(out))
+	}
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.24\n"), 0o644))
+	require.Nil(t, os.MkdirAll(filepath.Join(dir, "cmd/app"), 0o755))
+	main := filepath.Join(dir, "cmd/app/main.go")
+	require.Nil(t, os.WriteFile(main, []byte("package main\n\nfunc main() {}\n"), 0o644))
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-qm", "base")
+	require.Nil(t, os.WriteFile(main, []byte("package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println(\"hi\") }\n"), 0o644))
+	git("commit", "-qam", "head")
+	t.Chdir(dir)
+
+	d := danger.New()
+	NewBinarySize("./cmd/app").Run(d, danger.DSL{})
+
+	r := results(t, d)
+	require.Len(t, r.Warnings, 1)
+	require.True(t, strings.HasPrefix(r.Warnings[0].Message, "`app` grew by +"))
+	require.Contains(t, r.Markdowns[0].Message, "| `app` |")
+
+	out, err := exec.Command("git", "worktree", "list").Output()
+	require.Nil(t, err)
+	require.Equal(t, 1, strings.Count(string(out), "\n"), "worktrees are removed")
+}
@@ -0,0 +1,171 @@
+package rules
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// BranchPolicy requires PRs from head branches matching Head to target a
+// base branch matching one of Bases. Patterns are globs where `*` matches
+// within a path segment and `**` across segments, e.g. "feature/**".
+type BranchPolicy struct {
+	Head string
+	// Bases are the allowed base branches. The first one is suggested as the
+	// branch to retarget to.
+	Bases []string
+	// Reason explains the policy in the failure, e.g. "hotfixes are released
+	// from release branches".
+	Reason string
+}
+
+// TargetBranch checks the base branch of the PR against the first policy
+// matching its head branch. PRs from branches no policy matches, and runs
+// outside of GitHub and GitLab, are not checked.
+type TargetBranch struct {
+	Policies []BranchPolicy
+	// Warn reports violations as warnings instead of failures.
+	Warn bool
+}
+
+// NewTargetBranch returns a TargetBranch rule for a git-flow style
+// workflow: features target develop, hotfixes target a release branch and
+// release branches target main.
+func NewTargetBranch() TargetBranch {
+	return TargetBranch{Policies: []BranchPolicy{
+		{Head: "feature/**", Bases: []string{"develop"}, Reason: "features are integrated on develop"},
+		{Head: "hotfix/**", Bases: []string{"release/**"}, Reason: "hotfixes are released from release branches"},
+		{Head: "release/**", Bases: []string{"main", "master"}, Reason: "releases are merged into the main branch"},
+	}}
+}
+
+// Check returns the policy applying to head and whether targeting base
+// violates it.
+func (t TargetBranch) Check(head, base string) (BranchPolicy, bool) {
+	for _, p := range t.Policies {
+		if !dangerJs.MatchPath(p.Head, head) {
+			continue
+		}
+		return p, !matchesAny(p.Bases, base)
+	}
+	return BranchPolicy{}, false
+}
+
+// Run checks the base branch of the PR.
+func (t TargetBranch) Run(d *danger.T, pr danger.DSL) {
+	head, base := pr.HeadBranch(), pr.BaseBranch()
+	if head == "" || base == "" {
+		return
+	}
+	p, violated := t.Check(head, base)
+	if !violated || len(p.Bases) == 0 {
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "`%s` targets `%s`, but branches matching `%s` must target %s", head, base, p.Head, quoteList(p.Bases))
+	if p.Reason != "" {
+		sb.WriteString(": " + p.Reason)
+	}
+	sb.WriteString(".\n\n")
+	if isGlob(p.Bases[0]) {
+		fmt.Fprintf(&sb, "Change the base branch of the PR to a branch matching `%s`.", p.Bases[0])
+	} else {
+		fmt.Fprintf(&sb, "Change the base branch of the PR to `%s`.", p.Bases[0])
+	}
+	report(d, t.Warn, sb.String())
+}
+
+// quoteList formats the patterns as "`a`", "`a` or `b`" or "`a`, `b` or `c`".
+func quoteList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, s := range items {
+		quoted[i] = "`" + s + "`"
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
+
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// BranchNaming requires the head branch name to follow a naming convention
+// such as "feature/ABC-123-short-description". The name is parsed with
+// dangerJs.ParseBranchName, also available to dangerfiles as
+// pr.HeadBranchName().
+type BranchNaming struct {
+	// Patterns are globs of which the branch must match one, e.g.
+	// "feature/*". Empty allows any name.
+	Patterns []string
+	// Types are the allowed branch types. Empty allows any type, including
+	// none.
+	Types []string
+	// RequireTicket requires a ticket such as "ABC-123" in the name.
+	RequireTicket bool
+	// Ignore are globs of branches which aren't checked, e.g. "dependabot/**".
+	Ignore []string
+	// Warn reports violations as warnings instead of failures.
+	Warn bool
+}
+
+// NewBranchNaming returns a BranchNaming rule requiring a conventional type
+// prefix, ignoring long-lived and bot branches.
+func NewBranchNaming() BranchNaming {
+	return BranchNaming{
+		Types:  []string{"feature", "fix", "hotfix", "chore", "docs", "refactor", "test", "release"},
+		Ignore: []string{"main", "master", "develop", "dependabot/**", "renovate/**"},
+	}
+}
+
+// Check returns why the branch name doesn't follow the convention, nothing
+// when it does.
+func (n BranchNaming) Check(name string) []string {
+	if matchesAny(n.Ignore, name) {
+		return nil
+	}
+	b := dangerJs.ParseBranchName(name)
+	var problems []string
+	if len(n.Patterns) > 0 && !matchesAny(n.Patterns, b.Name) {
+		problems = append(problems, "it does not match "+quoteList(n.Patterns))
+	}
+	if len(n.Types) > 0 {
+		switch {
+		case b.Type == "":
+			problems = append(problems, "it has no type prefix such as `"+n.Types[0]+"/`")
+		case !slices.Contains(n.Types, b.Type):
+			problems = append(problems, fmt.Sprintf("its type `%s` is not one of %s", b.Type, quoteList(n.Types)))
+		}
+	}
+	if n.RequireTicket && b.Ticket == "" {
+		problems = append(problems, "it does not reference a ticket such as `ABC-123`")
+	}
+	return problems
+}
+
+// Run checks the head branch of the PR.
+func (n BranchNaming) Run(d *danger.T, pr danger.DSL) {
+	name := pr.HeadBranch()
+	if name == "" {
+		return
+	}
+	problems := n.Check(name)
+	if len(problems) == 0 {
+		return
+	}
+	report(d, n.Warn, d.Msg("rules.branches.naming", map[string]any{"branch": name, "problems": strings.Join(problems, ", ")}))
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if dangerJs.MatchPath(p, name) {
+			return true
+		}
+	}
+	return false
+}
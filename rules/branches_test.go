@@ -0,0 +1,139 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+func TestTargetBranch(t *testing.T) {
+	tests := []struct {
+		name  string
+		head  string
+		base  string
+		rule  TargetBranch
+		fails []danger.Violation
+	}{
+		{name: "feature to develop", head: "feature/login", base: "develop", rule: NewTargetBranch()},
+		{name: "nested feature to develop", head: "feature/auth/login", base: "develop", rule: NewTargetBranch()},
+		{
+			name: "feature to main",
+			head: "feature/login",
+			base: "main",
+			rule: NewTargetBranch(),
+			fails: []danger.Violation{{Message: "`feature/login` targets `main`, but branches matching `feature/**` must target `develop`: " +
+				"features are integrated on develop.\n\nChange the base branch of the PR to `develop`."}},
+		},
+		{name: "hotfix to release", head: "hotfix/crash", base: "release/1.2", rule: NewTargetBranch()},
+		{
+			name: "hotfix to develop",
+			head: "hotfix/crash",
+			base: "develop",
+			rule: NewTargetBranch(),
+			fails: []danger.Violation{{Message: "`hotfix/crash` targets `develop`, but branches matching `hotfix/**` must target `release/**`: " +
+				"hotfixes are released from release branches.\n\nChange the base branch of the PR to a branch matching `release/**`."}},
+		},
+		{
+			name: "several bases",
+			head: "release/1.2",
+			base: "develop",
+			rule: NewTargetBranch(),
+			fails: []danger.Violation{{Message: "`release/1.2` targets `develop`, but branches matching `release/**` must target `main` or `master`: " +
+				"releases are merged into the main branch.\n\nChange the base branch of the PR to `main`."}},
+		},
+		{name: "no policy", head: "fix-typo", base: "main", rule: NewTargetBranch()},
+		{
+			name: "first matching policy",
+			head: "docs/readme",
+			base: "main",
+			rule: TargetBranch{Policies: []BranchPolicy{
+				{Head: "docs/*", Bases: []string{"main"}},
+				{Head: "**", Bases: []string{"develop"}},
+			}},
+		},
+		{
+			name: "without reason",
+			head: "chore/deps",
+			base: "main",
+			rule: TargetBranch{Policies: []BranchPolicy{{Head: "**", Bases: []string{"develop"}}}},
+			fails: []danger.Violation{{Message: "`chore/deps` targets `main`, but branches matching `**` must target `develop`." +
+				"\n\nChange the base branch of the PR to `develop`."}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pr dangerJs.GitHubPR
+			pr.Head.Ref, pr.Base.Ref = tt.head, tt.base
+			d := danger.New()
+			tt.rule.Run(d, danger.DSL{GitHub: fakedsl.GitHub{PRData: pr}})
+			r := results(t, d)
+			require.ElementsMatch(t, tt.fails, r.Fails)
+			require.Empty(t, r.Warnings)
+		})
+	}
+}
+
+func TestTargetBranchGitLab(t *testing.T) {
+	mr := dangerJs.GitLabMR{}
+	mr.SourceBranch, mr.TargetBranch = "feature/login", "main"
+	pr := danger.DSL{GitLab: fakedsl.GitLab{MRData: mr}}
+
+	d := danger.New()
+	rule := NewTargetBranch()
+	rule.Warn = true
+	rule.Run(d, pr)
+	r := results(t, d)
+	require.Empty(t, r.Fails)
+	require.Len(t, r.Warnings, 1)
+}
+
+func TestBranchNaming(t *testing.T) {
+	tests := []struct {
+		name   string
+		branch string
+		rule   BranchNaming
+		fails  []danger.Violation
+	}{
+		{name: "conventional", branch: "feature/ABC-123-login", rule: NewBranchNaming()},
+		{name: "ignored", branch: "dependabot/go_modules/x", rule: NewBranchNaming()},
+		{
+			name:   "no type",
+			branch: "login-form",
+			rule:   NewBranchNaming(),
+			fails:  []danger.Violation{{Message: "Branch `login-form` does not follow the naming convention: it has no type prefix such as `feature/`."}},
+		},
+		{
+			name:   "unknown type",
+			branch: "wip/login",
+			rule:   BranchNaming{Types: []string{"feature", "fix"}},
+			fails:  []danger.Violation{{Message: "Branch `wip/login` does not follow the naming convention: its type `wip` is not one of `feature` or `fix`."}},
+		},
+		{
+			name:   "patterns and ticket",
+			branch: "feature/login",
+			rule:   BranchNaming{Patterns: []string{"feature/*", "fix/*"}, RequireTicket: true},
+			fails:  []danger.Violation{{Message: "Branch `feature/login` does not follow the naming convention: it does not reference a ticket such as `ABC-123`."}},
+		},
+		{
+			name:   "several problems",
+			branch: "misc/stuff/more",
+			rule:   BranchNaming{Patterns: []string{"feature/*"}, Types: []string{"feature"}, RequireTicket: true},
+			fails: []danger.Violation{{Message: "Branch `misc/stuff/more` does not follow the naming convention: it does not match `feature/*`, " +
+				"its type `misc` is not one of `feature`, it does not reference a ticket such as `ABC-123`."}},
+		},
+		{name: "ticket prefixed", branch: "ABC-1-login", rule: BranchNaming{RequireTicket: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pr dangerJs.GitHubPR
+			pr.Head.Ref = tt.branch
+			d := danger.New()
+			tt.rule.Run(d, danger.DSL{GitHub: fakedsl.GitHub{PRData: pr}})
+			require.ElementsMatch(t, tt.fails, results(t, d).Fails)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package rules
+
+import (
+	"strings"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// commit is the platform independent view of a PR commit used by the commit
+// rules.
+type commit struct {
+	SHA     string
+	Message string
+	Author  dangerJs.GitCommitAuthor
+	Merge   bool
+	// Verification is nil when the platform doesn't report signatures.
+	Verification *dangerJs.GitHubCommitVerification
+}
+
+func (c commit) shortSHA() string {
+	if len(c.SHA) > 7 {
+		return c.SHA[:7]
+	}
+	return c.SHA
+}
+
+// prCommits returns the commits of the PR from the GitHub or GitLab API data
+// when available, falling back to the git commits.
+func prCommits(pr danger.DSL) []commit {
+	var commits []commit
+	switch {
+	case pr.GitHub != nil && len(pr.GitHub.Commits()) > 0:
+		for _, c := range pr.GitHub.Commits() {
+			commits = append(commits, commit{
+				SHA:          c.SHA,
+				Message:      c.Commit.Message,
+				Author:       c.Commit.Author,
+				Merge:        c.IsMerge(),
+				Verification: c.Commit.Verification,
+			})
+		}
+	case pr.GitLab != nil && len(pr.GitLab.Commits()) > 0:
+		for _, c := range pr.GitLab.Commits() {
+			commits = append(commits, commit{SHA: c.ID, Message: c.Message, Author: c.Author(), Merge: c.IsMerge()})
+		}
+	case pr.Git != nil:
+		for _, c := range pr.Git.Commits() {
+			commits = append(commits, commit{SHA: c.SHA, Message: c.Message, Author: c.Author, Merge: len(c.Parents) > 1})
+		}
+	}
+	return commits
+}
+
+// report adds a fail, or a warning when warn is set.
+func report(d *danger.T, warn bool, message string) {
+	if warn {
+		d.Warn(message, "", 0)
+		return
+	}
+	d.Fail(message, "", 0)
+}
+
+// SignedCommits requires every commit of the PR to carry a signature GitHub
+// verified. Signatures are only reported by GitHub, on other platforms the
+// rule warns that it cannot check them.
+type SignedCommits struct {
+	// Warn reports unsigned commits as warnings instead of failures.
+	Warn bool
+	// SkipMerges ignores merge commits, e.g. from updating the branch.
+	SkipMerges bool
+}
+
+// Run checks the signature of every commit.
+func (s SignedCommits) Run(d *danger.T, pr danger.DSL) {
+	for _, c := range prCommits(pr) {
+		if s.SkipMerges && c.Merge {
+			continue
+		}
+		if c.Verification == nil {
+			d.Warn("Signed commits: commit signatures are only available for GitHub PRs", "", 0)
+			return
+		}
+		if !c.Verification.Verified {
+			report(d, s.Warn, d.Msg("rules.commits.unverified", map[string]any{"sha": c.shortSHA(), "reason": c.Verification.Reason}))
+		}
+	}
+}
+
+// DCO requires every commit of the PR to carry a Developer Certificate of
+// Origin `Signed-off-by` trailer matching the commit author.
+type DCO struct {
+	// Warn reports offending commits as warnings instead of failures.
+	Warn bool
+	// SkipMerges ignores merge commits, e.g. from updating the branch.
+	SkipMerges bool
+}
+
+// Run checks the sign-off of every commit.
+func (c DCO) Run(d *danger.T, pr danger.DSL) {
+	for _, cm := range prCommits(pr) {
+		if c.SkipMerges && cm.Merge {
+			continue
+		}
+		signoffs := dangerJs.TrailerValues(cm.Message, "Signed-off-by")
+		if len(signoffs) == 0 {
+			report(d, c.Warn, d.Msg("rules.commits.missing_signoff", map[string]any{"sha": cm.shortSHA()}))
+			continue
+		}
+		if !signedOffByAuthor(signoffs, cm.Author) {
+			report(d, c.Warn, d.Msg("rules.commits.signoff_mismatch", map[string]any{
+				"sha": cm.shortSHA(), "name": cm.Author.Name, "email": cm.Author.Email,
+			}))
+		}
+	}
+}
+
+func signedOffByAuthor(signoffs []string, author dangerJs.GitCommitAuthor) bool {
+	for _, s := range signoffs {
+		if strings.EqualFold(dangerJs.ParseIdentity(s).Email, author.Email) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+func gitHubCommit(sha, message string, verified bool, reason string) dangerJs.GitHubCommit {
+	return dangerJs.GitHubCommit{
+		SHA: sha,
+		Commit: dangerJs.GitCommit{
+			Message:      message,
+			Author:       dangerJs.GitCommitAuthor{Name: "Alice", Email: "alice@example.com"},
+			Verification: &dangerJs.GitHubCommitVerification{Verified: verified, Reason: reason},
+		},
+	}
+}
+
+func TestSignedCommits(t *testing.T) {
+	merge := gitHubCommit("ccccccccc", "Merge main", false, "unsigned")
+	merge.Parents = []dangerJs.GitHubCommitParent{{SHA: "a"}, {SHA: "b"}}
+	pr := danger.DSL{GitHub: fakedsl.GitHub{CommitsList: []dangerJs.GitHubCommit{
+		gitHubCommit("aaaaaaaaa", "Signed", true, "valid"),
+		gitHubCommit("bbbbbbbbb", "Unsigned", false, "unsigned"),
+		merge,
+	}}}
+
+	d := danger.New()
+	SignedCommits{SkipMerges: true}.Run(d, pr)
+	require.Equal(t, []danger.Violation{
+		{Message: "Commit `bbbbbbb` does not have a verified signature (unsigned)"},
+	}, results(t, d).Fails)
+
+	d = danger.New()
+	SignedCommits{Warn: true}.Run(d, pr)
+	r := results(t, d)
+	require.Empty(t, r.Fails)
+	require.Len(t, r.Warnings, 2)
+}
+
+func TestSignedCommitsWithoutVerification(t *testing.T) {
+	pr := danger.DSL{Git: fakedsl.Git{CommitsList: []dangerJs.GitCommit{{SHA: "abc", Message: "x"}}}}
+
+	d := danger.New()
+	SignedCommits{}.Run(d, pr)
+	r := results(t, d)
+	require.Empty(t, r.Fails)
+	require.Equal(t, []danger.Violation{
+		{Message: "Signed commits: commit signatures are only available for GitHub PRs"},
+	}, r.Warnings)
+}
+
+func TestDCO(t *testing.T) {
+	author := dangerJs.GitCommitAuthor{Name: "Alice", Email: "alice@example.com"}
+	pr := danger.DSL{Git: fakedsl.Git{CommitsList: []dangerJs.GitCommit{
+		{SHA: "111111111", Author: author, Message: "Good\n\nSigned-off-by: Alice <Alice@Example.com>"},
+		{SHA: "222222222", Author: author, Message: "Missing"},
+		{SHA: "333333333", Author: author, Message: "Wrong\n\nSigned-off-by: Bob <bob@example.com>"},
+		{SHA: "444444444", Author: author, Message: "Merge", Parents: []string{"a", "b"}},
+	}}}
+
+	d := danger.New()
+	DCO{SkipMerges: true}.Run(d, pr)
+	require.Equal(t, []danger.Violation{
+		{Message: "Commit `2222222` is missing a `Signed-off-by` line"},
+		{Message: "Commit `3333333` is not signed off by its author `Alice <alice@example.com>`"},
+	}, results(t, d).Fails)
+}
@@ -0,0 +1,149 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	danger "github.com/danger/golang"
+	"github.com/danger/golang/githubclient"
+	"github.com/danger/golang/gitlabclient"
+)
+
+// ContributionCounter counts the merged PRs of an author in the repository.
+type ContributionCounter interface {
+	MergedPRs(ctx context.Context, author string) (int, error)
+}
+
+// FirstTimeContributor welcomes authors opening their first PR in the
+// repository and, with Relax, reports the fails of the rules run afterwards
+// as warnings for that PR, except for Strict rules. Authors are detected from the platform, see
+// DSL.FirstTimeContributor, or by counting their merged PRs when it doesn't
+// tell.
+type FirstTimeContributor struct {
+	// Welcome is the markdown posted for first-time contributors, where
+	// {author} is replaced by the author's login.
+	Welcome string
+	// Guidelines links the contribution guidelines from the welcome, e.g.
+	// the URL of CONTRIBUTING.md.
+	Guidelines string
+	// Relax reports the fails of the rules run afterwards as warnings when
+	// the author is a first-time contributor. Authors without merged PRs
+	// include anyone outside the project, so mark the rules which must
+	// never be relaxed as Strict.
+	Relax bool
+	// Counter defaults to the GitHub or GitLab API, authenticated with the
+	// DSL settings.
+	Counter ContributionCounter
+}
+
+// NewFirstTimeContributor returns a FirstTimeContributor rule with a short
+// welcome.
+func NewFirstTimeContributor(guidelines string) FirstTimeContributor {
+	return FirstTimeContributor{
+		Welcome:    "👋 Welcome @{author}, and thanks for your first contribution!",
+		Guidelines: guidelines,
+	}
+}
+
+// IsFirstTime reports whether the PR is the first of its author.
+func (f FirstTimeContributor) IsFirstTime(ctx context.Context, pr danger.DSL) (bool, error) {
+	if first, known := pr.FirstTimeContributor(); known {
+		return first, nil
+	}
+	author := prAuthor(pr)
+	if author == "" {
+		return false, nil
+	}
+	counter := f.Counter
+	if counter == nil {
+		var err error
+		if counter, err = contributionCounterFor(pr); err != nil {
+			return false, err
+		}
+	}
+	merged, err := counter.MergedPRs(ctx, author)
+	if err != nil {
+		return false, fmt.Errorf("counting merged PRs of %s: %w", author, err)
+	}
+	return merged == 0, nil
+}
+
+// Run welcomes first-time contributors.
+func (f FirstTimeContributor) Run(d *danger.T, pr danger.DSL) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	first, err := f.IsFirstTime(ctx, pr)
+	if err != nil {
+		d.Warn(fmt.Sprintf("First-time contributor: %s", err), "", 0)
+		return
+	}
+	if !first {
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strings.ReplaceAll(f.Welcome, "{author}", prAuthor(pr)))
+	if f.Guidelines != "" {
+		fmt.Fprintf(&sb, "\n\nPlease have a look at the [contribution guidelines](%s) if you haven't already.", f.Guidelines)
+	}
+	if f.Relax {
+		sb.WriteString("\n\nAs this is your first PR, the checks below are reported as warnings so you can address them with your reviewers.")
+		d.SetFailsAsWarnings(true)
+	}
+	d.Markdown(sb.String(), "", 0)
+}
+
+func prAuthor(pr danger.DSL) string {
+	switch {
+	case pr.GitHub != nil && pr.GitHub.PR().User.Login != "":
+		return pr.GitHub.PR().User.Login
+	case pr.GitLab != nil:
+		return pr.GitLab.MR().Author.Username
+	}
+	return ""
+}
+
+func contributionCounterFor(pr danger.DSL) (ContributionCounter, error) {
+	if pr.GitHub != nil && pr.GitHub.PR().Number != 0 {
+		client, err := githubclient.NewFromSettings(pr.Settings)
+		if err != nil {
+			return nil, err
+		}
+		this := pr.GitHub.ThisPR()
+		return gitHubCounter{client: client, repo: this.Owner + "/" + this.Repo}, nil
+	}
+	if pr.GitLab != nil && pr.GitLab.MR().IID != 0 {
+		client, err := gitlabclient.NewFromSettings(pr.Settings)
+		if err != nil {
+			return nil, err
+		}
+		return gitLabCounter{client: client, project: pr.GitLab.Metadata().RepoSlug}, nil
+	}
+	return nil, fmt.Errorf("counting contributions requires a GitHub or GitLab PR")
+}
+
+type gitHubCounter struct {
+	client *githubclient.Client
+	repo   string
+}
+
+func (c gitHubCounter) MergedPRs(ctx context.Context, author string) (int, error) {
+	return c.client.SearchIssuesCount(ctx, fmt.Sprintf("is:pr is:merged author:%s repo:%s", author, c.repo))
+}
+
+type gitLabCounter struct {
+	client  *gitlabclient.Client
+	project string
+}
+
+func (c gitLabCounter) MergedPRs(ctx context.Context, author string) (int, error) {
+	mrs, err := c.client.ListMRs(ctx, c.project, url.Values{
+		"state":           {"merged"},
+		"author_username": {author},
+		"per_page":        {"1"},
+	})
+	return len(mrs), err
+}
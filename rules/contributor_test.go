@@ -0,0 +1,96 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+type fakeCounter struct {
+	merged int
+	err    error
+}
+
+func (c fakeCounter) MergedPRs(context.Context, string) (int, error) {
+	return c.merged, c.err
+}
+
+func TestFirstTimeContributor(t *testing.T) {
+	welcome := "👋 Welcome @octo, and thanks for your first contribution!\n\n" +
+		"Please have a look at the [contribution guidelines](CONTRIBUTING.md) if you haven't already."
+	relaxed := NewFirstTimeContributor("CONTRIBUTING.md")
+	relaxed.Relax = true
+	tests := []struct {
+		name        string
+		association string
+		counter     fakeCounter
+		rule        FirstTimeContributor
+		markdowns   []danger.Violation
+		warnings    []danger.Violation
+		fails       []danger.Violation
+	}{
+		{
+			name:        "first timer",
+			association: "FIRST_TIME_CONTRIBUTOR",
+			rule:        NewFirstTimeContributor("CONTRIBUTING.md"),
+			markdowns:   []danger.Violation{{Message: welcome}},
+			fails:       []danger.Violation{{Message: "Missing tests"}},
+		},
+		{
+			name:        "relaxed first timer",
+			association: "FIRST_TIME_CONTRIBUTOR",
+			rule:        relaxed,
+			markdowns: []danger.Violation{{Message: welcome + "\n\n" +
+				"As this is your first PR, the checks below are reported as warnings so you can address them with your reviewers."}},
+			warnings: []danger.Violation{{Message: "Missing tests"}},
+		},
+		{
+			name:        "member",
+			association: "MEMBER",
+			rule:        NewFirstTimeContributor("CONTRIBUTING.md"),
+			fails:       []danger.Violation{{Message: "Missing tests"}},
+		},
+		{
+			name:        "counted",
+			association: "NONE",
+			counter:     fakeCounter{merged: 0},
+			rule:        FirstTimeContributor{Welcome: "Hi {author}"},
+			markdowns:   []danger.Violation{{Message: "Hi octo"}},
+			fails:       []danger.Violation{{Message: "Missing tests"}},
+		},
+		{
+			name:        "returning contributor",
+			association: "NONE",
+			counter:     fakeCounter{merged: 4},
+			rule:        NewFirstTimeContributor(""),
+			fails:       []danger.Violation{{Message: "Missing tests"}},
+		},
+		{
+			name:        "count fails",
+			association: "NONE",
+			counter:     fakeCounter{err: errors.New("rate limited")},
+			rule:        NewFirstTimeContributor(""),
+			warnings:    []danger.Violation{{Message: "First-time contributor: counting merged PRs of octo: rate limited"}},
+			fails:       []danger.Violation{{Message: "Missing tests"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := dangerJs.GitHubPR{Number: 1, AuthorAssociation: tt.association, User: dangerJs.GitHubUser{Login: "octo"}}
+			tt.rule.Counter = tt.counter
+			d := danger.New()
+			tt.rule.Run(d, danger.DSL{GitHub: fakedsl.GitHub{PRData: pr}})
+			d.Fail("Missing tests", "", 0)
+			r := results(t, d)
+			require.ElementsMatch(t, tt.markdowns, r.Markdowns)
+			require.ElementsMatch(t, tt.warnings, r.Warnings)
+			require.ElementsMatch(t, tt.fails, r.Fails)
+		})
+	}
+}
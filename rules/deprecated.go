@@ -0,0 +1,102 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	danger "github.com/danger/golang"
+)
+
+// Deprecation is an identifier, API or feature flag which added code must no
+// longer use. Either Symbol or Pattern is set.
+type Deprecation struct {
+	// Symbol is an identifier matched as a whole, e.g. the Go symbol
+	// "ioutil.ReadFile" or the flag "enable_legacy_checkout".
+	Symbol string
+	// Pattern is a regular expression, e.g. `\bFeatureFlags\.Legacy\w+`.
+	Pattern string
+	// Replacement tells what to use instead, e.g. "use os.ReadFile".
+	Replacement string
+	// Files are globs of the files it applies to, all files when empty.
+	Files []string
+}
+
+// name returns how the deprecation is named in messages.
+func (dep Deprecation) name() string {
+	if dep.Symbol != "" {
+		return dep.Symbol
+	}
+	return dep.Pattern
+}
+
+func (dep Deprecation) regexp() (*regexp.Regexp, error) {
+	if dep.Symbol != "" && strings.Contains(dep.Symbol, ".") {
+		// a qualified symbol, not part of a longer selector
+		return regexp.Compile(`(?:^|[^\w.])` + regexp.QuoteMeta(dep.Symbol) + `\b`)
+	}
+	if dep.Symbol != "" {
+		return regexp.Compile(`\b` + regexp.QuoteMeta(dep.Symbol) + `\b`)
+	}
+	return regexp.Compile(dep.Pattern)
+}
+
+// DeprecatedUsage fails when an added line introduces a deprecated
+// identifier or a dead feature flag, with the replacement guidance of each in
+// the message. Existing uses are left alone.
+type DeprecatedUsage struct {
+	Refs
+	Deprecations []Deprecation
+	// Warn reports violations as warnings instead of failures.
+	Warn bool
+}
+
+// Run checks the added lines of every created and modified file.
+func (u DeprecatedUsage) Run(d *danger.T, pr danger.DSL) {
+	type compiled struct {
+		Deprecation
+		re *regexp.Regexp
+	}
+	var deps []compiled
+	for _, dep := range u.Deprecations {
+		re, err := dep.regexp()
+		if err != nil {
+			d.Warn(fmt.Sprintf("Deprecated usage: invalid pattern `%s`: %s", dep.Pattern, err), "", 0)
+			continue
+		}
+		deps = append(deps, compiled{dep, re})
+	}
+	if len(deps) == 0 {
+		return
+	}
+
+	for _, file := range touchedFiles(pr) {
+		var applicable []compiled
+		for _, dep := range deps {
+			if len(dep.Files) == 0 || matchesAny(dep.Files, file) {
+				applicable = append(applicable, dep)
+			}
+		}
+		if len(applicable) == 0 {
+			continue
+		}
+		diff, err := u.diff(pr, file)
+		if err != nil {
+			d.Warn(fmt.Sprintf("Deprecated usage: could not diff `%s`: %s", file, err), file, 0)
+			continue
+		}
+		for _, l := range diff.AddedLines {
+			for _, dep := range applicable {
+				if !dep.re.MatchString(l.Content) {
+					continue
+				}
+				msg := d.Msg("rules.deprecated.usage", map[string]any{"symbol": dep.name(), "replacement": dep.Replacement})
+				if u.Warn {
+					d.Warn(msg, file, l.Line)
+				} else {
+					d.Fail(msg, file, l.Line)
+				}
+			}
+		}
+	}
+}
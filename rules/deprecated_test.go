@@ -0,0 +1,66 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+func TestDeprecationRegexp(t *testing.T) {
+	tests := []struct {
+		dep  Deprecation
+		line string
+		want bool
+	}{
+		{Deprecation{Symbol: "ioutil.ReadFile"}, `b, err := ioutil.ReadFile(path)`, true},
+		{Deprecation{Symbol: "ioutil.ReadFile"}, `b, err := ioutil.ReadFileAll(path)`, false},
+		{Deprecation{Symbol: "ioutil.ReadFile"}, `b, err := myioutil.ReadFile(path)`, false},
+		{Deprecation{Symbol: "ioutil.ReadFile"}, `x.ioutil.ReadFile(path)`, false},
+		{Deprecation{Symbol: "legacy_checkout"}, `if flags["legacy_checkout"] {`, true},
+		{Deprecation{Symbol: "legacy_checkout"}, `if flags["legacy_checkout_v2"] {`, false},
+		{Deprecation{Pattern: `Flags\.Legacy\w+`}, `if Flags.LegacyCart {`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dep.name()+" "+tt.line, func(t *testing.T) {
+			re, err := tt.dep.regexp()
+			require.Nil(t, err)
+			require.Equal(t, tt.want, re.MatchString(tt.line))
+		})
+	}
+}
+
+func TestDeprecatedUsage(t *testing.T) {
+	pr := danger.DSL{Git: fakedsl.Git{
+		ModifiedFilesList: []string{"main.go", "app.js"},
+		Diffs: map[string]dangerJs.FileDiff{
+			"main.go": {AddedLines: []dangerJs.DiffLine{
+				{Content: "\tb, _ := ioutil.ReadFile(p)", Line: 3},
+				{Content: "\tif flags.Enabled(\"legacy_checkout\") {", Line: 7},
+			}},
+			"app.js": {AddedLines: []dangerJs.DiffLine{
+				{Content: "const b = ioutil.ReadFile(p)", Line: 1},
+				{Content: "if (flags.legacy_checkout) {", Line: 2},
+			}},
+		},
+	}}
+
+	d := danger.New()
+	DeprecatedUsage{Deprecations: []Deprecation{
+		{Symbol: "ioutil.ReadFile", Replacement: "use os.ReadFile", Files: []string{"**/*.go"}},
+		{Symbol: "legacy_checkout", Replacement: "the flag was removed, the new checkout is always on"},
+		{Pattern: "("},
+	}}.Run(d, pr)
+
+	r := results(t, d)
+	require.Equal(t, []danger.Violation{
+		{Message: "`ioutil.ReadFile` is deprecated: use os.ReadFile", File: "main.go", Line: 3},
+		{Message: "`legacy_checkout` is deprecated: the flag was removed, the new checkout is always on", File: "main.go", Line: 7},
+		{Message: "`legacy_checkout` is deprecated: the flag was removed, the new checkout is always on", File: "app.js", Line: 2},
+	}, r.Fails)
+	require.Len(t, r.Warnings, 1)
+	require.Contains(t, r.Warnings[0].Message, "Deprecated usage: invalid pattern `(`")
+}
@@ -0,0 +1,333 @@
+package rules
+
+import (
+	"bufio"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// editorConfigSection is a glob section of an .editorconfig file.
+type editorConfigSection struct {
+	match *regexp.Regexp
+	props map[string]string
+}
+
+// editorConfigFile is a parsed .editorconfig file.
+type editorConfigFile struct {
+	root     bool
+	sections []editorConfigSection
+}
+
+// parseEditorConfig parses an .editorconfig file in dir, relative to the
+// repository root. Sections with invalid globs are ignored.
+func parseEditorConfig(dir, content string) editorConfigFile {
+	var f editorConfigFile
+	var current *editorConfigSection
+	sc := bufio.NewScanner(strings.NewReader(content))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+		case line[0] == '[' && line[len(line)-1] == ']':
+			current = nil
+			re, err := editorConfigGlob(dir, line[1:len(line)-1])
+			if err != nil {
+				continue
+			}
+			f.sections = append(f.sections, editorConfigSection{match: re, props: map[string]string{}})
+			current = &f.sections[len(f.sections)-1]
+		default:
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				continue
+			}
+			key = strings.ToLower(strings.TrimSpace(key))
+			value = strings.ToLower(strings.TrimSpace(value))
+			if current != nil {
+				current.props[key] = value
+			} else if key == "root" {
+				f.root = value == "true"
+			}
+		}
+	}
+	return f
+}
+
+var numRangeRe = regexp.MustCompile(`^(-?\d+)\.\.(-?\d+)$`)
+
+// editorConfigGlob compiles a section glob of an .editorconfig file in dir.
+// Globs without a slash match file names in any subdirectory.
+func editorConfigGlob(dir, glob string) (*regexp.Regexp, error) {
+	switch {
+	case strings.HasPrefix(glob, "/"):
+		glob = glob[1:]
+	case !strings.Contains(glob, "/"):
+		glob = "**/" + glob
+	}
+	var sb strings.Builder
+	sb.WriteString("^")
+	if dir != "" {
+		sb.WriteString(regexp.QuoteMeta(dir + "/"))
+	}
+	braces := 0
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			switch {
+			case strings.HasPrefix(glob[i:], "**/"):
+				sb.WriteString("(?:.*/)?")
+				i += 2
+			case strings.HasPrefix(glob[i:], "**"):
+				sb.WriteString(".*")
+				i++
+			default:
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end
+		case '{':
+			end := strings.IndexByte(glob[i:], '}')
+			if end < 0 {
+				sb.WriteString(`\{`)
+				continue
+			}
+			if m := numRangeRe.FindStringSubmatch(glob[i+1 : i+end]); m != nil {
+				sb.WriteString(numRange(m[1], m[2]))
+				i += end
+				continue
+			}
+			if !strings.Contains(glob[i:i+end], ",") {
+				sb.WriteString(`\{`)
+				continue
+			}
+			sb.WriteString("(?:")
+			braces++
+		case ',':
+			if braces > 0 {
+				sb.WriteString("|")
+			} else {
+				sb.WriteString(",")
+			}
+		case '}':
+			if braces > 0 {
+				sb.WriteString(")")
+				braces--
+			} else {
+				sb.WriteString(`\}`)
+			}
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+// numRange matches the integers from lo to hi.
+func numRange(lo, hi string) string {
+	a, _ := strconv.Atoi(lo)
+	b, _ := strconv.Atoi(hi)
+	if a > b {
+		a, b = b, a
+	}
+	nums := make([]string, 0, b-a+1)
+	for n := a; n <= b && len(nums) < 1000; n++ {
+		nums = append(nums, strconv.Itoa(n))
+	}
+	return "(?:" + strings.Join(nums, "|") + ")"
+}
+
+// EditorConfig warns about added lines which don't follow the .editorconfig
+// files of the repository: indentation with the wrong indent_style or not a
+// multiple of indent_size, a charset other than the configured one, and
+// lines longer than max_line_length. It applies to files of any language.
+type EditorConfig struct {
+	Refs
+	// MaxPerFile caps the warnings of a file, the rest being counted in one
+	// warning. Unlimited when zero.
+	MaxPerFile int
+}
+
+// NewEditorConfig returns an EditorConfig rule reporting up to 10 lines per
+// file.
+func NewEditorConfig() EditorConfig {
+	return EditorConfig{MaxPerFile: 10}
+}
+
+// properties returns the properties applying to file, reading the
+// .editorconfig files of its directory and the directories above it.
+func (e EditorConfig) properties(pr danger.DSL, file string, cache map[string]*editorConfigFile) map[string]string {
+	_, head := e.refs()
+	var chain []*editorConfigFile
+	for dir := path.Dir(file); ; dir = path.Dir(dir) {
+		if dir == "." || dir == "/" {
+			dir = ""
+		}
+		f, ok := cache[dir]
+		if !ok {
+			// a missing file reads as empty or fails alike
+			content, err := dangerJs.FileAtRef(pr.Git, path.Join(dir, ".editorconfig"), head)
+			if err == nil && content != "" {
+				parsed := parseEditorConfig(dir, content)
+				f = &parsed
+			}
+			cache[dir] = f
+		}
+		if f != nil {
+			chain = append(chain, f)
+			if f.root {
+				break
+			}
+		}
+		if dir == "" {
+			break
+		}
+	}
+	props := map[string]string{}
+	for i := len(chain) - 1; i >= 0; i-- {
+		for _, s := range chain[i].sections {
+			if !s.match.MatchString(file) {
+				continue
+			}
+			for k, v := range s.props {
+				if v == "unset" {
+					delete(props, k)
+				} else {
+					props[k] = v
+				}
+			}
+		}
+	}
+	return props
+}
+
+// Run checks the added lines of every created and modified file.
+func (e EditorConfig) Run(d *danger.T, pr danger.DSL) {
+	cache := map[string]*editorConfigFile{}
+	for _, file := range touchedFiles(pr) {
+		props := e.properties(pr, file, cache)
+		if len(props) == 0 {
+			continue
+		}
+		diff, err := e.diff(pr, file)
+		if err != nil {
+			d.Warn(fmt.Sprintf("EditorConfig: could not diff `%s`: %s", file, err), file, 0)
+			continue
+		}
+		reported, skipped := 0, 0
+		for _, l := range diff.AddedLines {
+			msg := checkEditorConfigLine(props, l.Content, l.Line)
+			if msg == "" {
+				continue
+			}
+			if e.MaxPerFile > 0 && reported >= e.MaxPerFile {
+				skipped++
+				continue
+			}
+			d.Warn(msg, file, l.Line)
+			reported++
+		}
+		if skipped > 0 {
+			d.Warn(fmt.Sprintf("EditorConfig: %d more %s of `%s` don't follow .editorconfig", skipped, plural(skipped, "line", "lines"), file), file, 0)
+		}
+	}
+}
+
+// checkEditorConfigLine returns the violation of line number n, or "".
+func checkEditorConfigLine(props map[string]string, line string, n int) string {
+	line = strings.TrimSuffix(line, "\r")
+	var problems []string
+	switch charset := props["charset"]; charset {
+	case "utf-8", "utf-8-bom":
+		bom := strings.HasPrefix(line, "\uFEFF")
+		switch {
+		case !utf8.ValidString(line):
+			problems = append(problems, fmt.Sprintf("is not valid UTF-8, .editorconfig sets charset = %s", charset))
+		case n == 1 && charset == "utf-8" && bom:
+			problems = append(problems, "starts with a byte order mark, .editorconfig sets charset = utf-8")
+		case n == 1 && charset == "utf-8-bom" && !bom:
+			problems = append(problems, "lacks a byte order mark, .editorconfig sets charset = utf-8-bom")
+		}
+		line = strings.TrimPrefix(line, "\uFEFF")
+	}
+
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	rest := line[len(indent):]
+	// blank lines and the continuation lines of block comments, e.g. " * x",
+	// are not indentation
+	if rest != "" && !strings.HasPrefix(rest, "*") {
+		size, _ := strconv.Atoi(props["indent_size"])
+		switch props["indent_style"] {
+		case "space":
+			if strings.Contains(indent, "\t") {
+				problems = append(problems, "is indented with tabs, .editorconfig sets indent_style = space")
+			} else if size > 0 && len(indent)%size != 0 {
+				problems = append(problems, fmt.Sprintf("is indented by %d spaces, not a multiple of indent_size = %d", len(indent), size))
+			}
+		case "tab":
+			if width := tabWidth(props); strings.HasPrefix(indent, strings.Repeat(" ", width)) {
+				problems = append(problems, "is indented with spaces, .editorconfig sets indent_style = tab")
+			}
+		}
+	}
+
+	if limit, err := strconv.Atoi(props["max_line_length"]); err == nil && limit > 0 {
+		if width := lineWidth(line, tabWidth(props)); width > limit {
+			problems = append(problems, fmt.Sprintf("is %d characters long, .editorconfig sets max_line_length = %d", width, limit))
+		}
+	}
+	if len(problems) == 0 {
+		return ""
+	}
+	return "EditorConfig: line " + strings.Join(problems, " and ")
+}
+
+// tabWidth returns the width of a tab: tab_width, defaulting to a numeric
+// indent_size, then to 4.
+func tabWidth(props map[string]string) int {
+	for _, key := range []string{"tab_width", "indent_size"} {
+		if n, err := strconv.Atoi(props[key]); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 4
+}
+
+// lineWidth counts the characters of line, with tabs advancing to the next
+// tab stop.
+func lineWidth(line string, tab int) int {
+	width := 0
+	for _, r := range line {
+		if r == '\t' {
+			width += tab - width%tab
+			continue
+		}
+		width++
+	}
+	return width
+}
@@ -0,0 +1,104 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+func TestEditorConfigGlob(t *testing.T) {
+	tests := []struct {
+		dir, glob, file string
+		want            bool
+	}{
+		{"", "*", "a/b/c.py", true},
+		{"", "*.py", "a/b/c.py", true},
+		{"", "*.py", "c.go", false},
+		{"", "/*.py", "a/c.py", false},
+		{"", "/*.py", "c.py", true},
+		{"", "lib/**.js", "lib/a/b.js", true},
+		{"", "lib/*.js", "lib/a/b.js", false},
+		{"", "*.{js,ts}", "src/a.ts", true},
+		{"", "*.{js,ts}", "src/a.go", false},
+		{"", "{package.json,.travis.yml}", "package.json", true},
+		{"", "file{1..3}.txt", "file2.txt", true},
+		{"", "file{1..3}.txt", "file4.txt", false},
+		{"", "[!a]*.md", "b.md", true},
+		{"", "[!a]*.md", "a.md", false},
+		{"", "?.c", "x.c", true},
+		{"docs", "*.md", "docs/guide/a.md", true},
+		{"docs", "*.md", "a.md", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.glob+" "+tt.file, func(t *testing.T) {
+			re, err := editorConfigGlob(tt.dir, tt.glob)
+			require.Nil(t, err)
+			require.Equal(t, tt.want, re.MatchString(tt.file))
+		})
+	}
+}
+
+func TestEditorConfigLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		props map[string]string
+		line  string
+		n     int
+		want  string
+	}{
+		{name: "spaces", props: map[string]string{"indent_style": "space", "indent_size": "2"}, line: "    x", n: 2},
+		{name: "tabs for spaces", props: map[string]string{"indent_style": "space"}, line: "\tx", n: 2, want: "EditorConfig: line is indented with tabs, .editorconfig sets indent_style = space"},
+		{name: "odd size", props: map[string]string{"indent_style": "space", "indent_size": "4"}, line: "   x", n: 2, want: "EditorConfig: line is indented by 3 spaces, not a multiple of indent_size = 4"},
+		{name: "block comment", props: map[string]string{"indent_style": "space", "indent_size": "4"}, line: "     * doc", n: 2},
+		{name: "spaces for tabs", props: map[string]string{"indent_style": "tab", "indent_size": "4"}, line: "    x", n: 2, want: "EditorConfig: line is indented with spaces, .editorconfig sets indent_style = tab"},
+		{name: "alignment after tab", props: map[string]string{"indent_style": "tab"}, line: "\t  x", n: 2},
+		{name: "invalid utf-8", props: map[string]string{"charset": "utf-8"}, line: "caf\xe9", n: 2, want: "EditorConfig: line is not valid UTF-8, .editorconfig sets charset = utf-8"},
+		{name: "bom", props: map[string]string{"charset": "utf-8"}, line: "\uFEFFx", n: 1, want: "EditorConfig: line starts with a byte order mark, .editorconfig sets charset = utf-8"},
+		{name: "missing bom", props: map[string]string{"charset": "utf-8-bom"}, line: "x", n: 1, want: "EditorConfig: line lacks a byte order mark, .editorconfig sets charset = utf-8-bom"},
+		{name: "latin1", props: map[string]string{"charset": "latin1"}, line: "caf\xe9", n: 2},
+		{name: "long line", props: map[string]string{"max_line_length": "10", "tab_width": "8"}, line: "\tabcdef", n: 2, want: "EditorConfig: line is 14 characters long, .editorconfig sets max_line_length = 10"},
+		{name: "max off", props: map[string]string{"max_line_length": "off"}, line: "a very long line indeed", n: 2},
+		{name: "several", props: map[string]string{"indent_style": "space", "max_line_length": "4"}, line: "\tlong", n: 2, want: "EditorConfig: line is indented with tabs, .editorconfig sets indent_style = space and is 8 characters long, .editorconfig sets max_line_length = 4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, checkEditorConfigLine(tt.props, tt.line, tt.n))
+		})
+	}
+}
+
+func TestEditorConfig(t *testing.T) {
+	pr := danger.DSL{Git: fakedsl.Git{
+		ModifiedFilesList: []string{"main.py", "web/app.js", "Makefile"},
+		Files: map[string]string{
+			".editorconfig":     "root = true\n\n[*]\nindent_style = space\nindent_size = 4\n\n[Makefile]\nindent_style = tab\n",
+			"web/.editorconfig": "[*.js]\nindent_size = 2\nmax_line_length = 20\n",
+		},
+		Diffs: map[string]dangerJs.FileDiff{
+			"main.py": {AddedLines: []dangerJs.DiffLine{
+				{Content: "def f():", Line: 1},
+				{Content: "  return 1", Line: 2},
+				{Content: "\treturn 2", Line: 3},
+			}},
+			"web/app.js": {AddedLines: []dangerJs.DiffLine{
+				{Content: "  f();", Line: 1},
+				{Content: "  const value = compute();", Line: 2},
+			}},
+			"Makefile": {AddedLines: []dangerJs.DiffLine{{Content: "\tgo test ./...", Line: 5}}},
+		},
+	}}
+
+	d := danger.New()
+	EditorConfig{MaxPerFile: 1}.Run(d, pr)
+
+	r := results(t, d)
+	require.Equal(t, []danger.Violation{
+		{Message: "EditorConfig: line is indented by 2 spaces, not a multiple of indent_size = 4", File: "main.py", Line: 2},
+		{Message: "EditorConfig: 1 more line of `main.py` don't follow .editorconfig", File: "main.py"},
+		{Message: "EditorConfig: line is 26 characters long, .editorconfig sets max_line_length = 20", File: "web/app.js", Line: 2},
+	}, r.Warnings)
+}
@@ -0,0 +1,185 @@
+package rules
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// GoFormat warns about Go code the PR adds which gofmt, or goimports, would
+// reformat. Only the formatting of changed lines is reported, so files which
+// drifted before the PR don't flood it. Changes of single lines get GitHub
+// suggestion blocks which can be committed from the review; changes spanning
+// lines show the diff.
+type GoFormat struct {
+	Refs
+	// Command runs the formatter, reading the file on stdin and writing it
+	// formatted to stdout. It defaults to gofmt; e.g. {"goimports", "-local",
+	// "example.com/mod"} also groups imports.
+	Command []string
+	// Autofix adds the formatting of the changed lines as a fix, which the
+	// runner commits when DANGER_AUTOFIX is set.
+	Autofix bool
+}
+
+// NewGoFormat returns a GoFormat rule running gofmt.
+func NewGoFormat() GoFormat {
+	return GoFormat{Command: []string{"gofmt"}}
+}
+
+func (g GoFormat) command() []string {
+	if len(g.Command) == 0 {
+		return []string{"gofmt"}
+	}
+	return g.Command
+}
+
+// format runs the formatter on the source of file.
+func (g GoFormat) format(ctx context.Context, file, src string) (string, error) {
+	args := g.command()
+	if path.Base(args[0]) == "goimports" {
+		// goimports resolves the imports of stdin relative to srcdir
+		args = append(append([]string{}, args...), "-srcdir", file)
+	}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(src)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// Run checks the created and modified Go files.
+func (g GoFormat) Run(d *danger.T, pr danger.DSL) {
+	name := path.Base(g.command()[0])
+	_, head := g.refs()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	for _, file := range touchedFiles(pr) {
+		if !strings.HasSuffix(file, ".go") {
+			continue
+		}
+		diff, err := g.diff(pr, file)
+		if err != nil {
+			d.Warn(fmt.Sprintf("Formatting: could not diff `%s`: %s", file, err), file, 0)
+			continue
+		}
+		changed := map[int]bool{}
+		for _, l := range diff.AddedLines {
+			changed[l.Line] = true
+		}
+		if len(changed) == 0 {
+			continue
+		}
+		src, err := dangerJs.FileAtRef(pr.Git, file, head)
+		if err != nil {
+			d.Warn(fmt.Sprintf("Formatting: could not read `%s`: %s", file, err), file, 0)
+			continue
+		}
+		formatted, err := g.format(ctx, file, src)
+		if err != nil {
+			var notFound *exec.Error
+			if errors.As(err, &notFound) {
+				d.Warn(fmt.Sprintf("Formatting: could not run %s: %s", name, err), "", 0)
+				return
+			}
+			d.Warn(fmt.Sprintf("Formatting: %s failed on `%s`: %s", name, file, err), file, 0)
+			continue
+		}
+		if formatted == src {
+			continue
+		}
+
+		lines, _ := splitLines(src)
+		want, _ := splitLines(formatted)
+		var kept []lineHunk
+		for _, h := range diffLines(lines, want) {
+			h = anchor(h, len(lines))
+			if !touches(h, changed) {
+				continue
+			}
+			kept = append(kept, h)
+			if h.A1-h.A0 == 1 {
+				suggestion := strings.Join(want[h.B0:h.B1], "\n")
+				d.Warn(fmt.Sprintf("`%s` formats this line differently:\n\n```suggestion\n%s\n```", name, suggestion), file, h.A1)
+				continue
+			}
+			if h.A1-h.A0 == h.B1-h.B0 {
+				for i := 0; i < h.A1-h.A0; i++ {
+					if lines[h.A0+i] != want[h.B0+i] {
+						d.Warn(fmt.Sprintf("`%s` formats this line differently:\n\n```suggestion\n%s\n```", name, want[h.B0+i]), file, h.A0+i+1)
+					}
+				}
+				continue
+			}
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "`%s` formats lines %d-%d differently:\n\n```diff\n", name, h.A0+1, h.A1)
+			for _, l := range lines[h.A0:h.A1] {
+				sb.WriteString("-" + l + "\n")
+			}
+			for _, l := range want[h.B0:h.B1] {
+				sb.WriteString("+" + l + "\n")
+			}
+			sb.WriteString("```")
+			d.Warn(sb.String(), file, h.A0+1)
+		}
+		if g.Autofix && len(kept) > 0 {
+			if patch := unifiedDiff(file, src, applyHunks(src, lines, want, kept)); patch != "" {
+				d.AddFix(fmt.Sprintf("Format `%s` with %s", file, name), patch)
+			}
+		}
+	}
+}
+
+// anchor widens a hunk which only inserts lines to the line before it, or
+// after it at the start of the file, so it can be reported on a line.
+func anchor(h lineHunk, n int) lineHunk {
+	if h.A0 < h.A1 || n == 0 {
+		return h
+	}
+	if h.A0 > 0 {
+		return lineHunk{h.A0 - 1, h.A1, h.B0 - 1, h.B1}
+	}
+	return lineHunk{h.A0, h.A1 + 1, h.B0, h.B1 + 1}
+}
+
+// touches reports whether a hunk replaces one of the changed lines.
+func touches(h lineHunk, changed map[int]bool) bool {
+	for l := h.A0 + 1; l <= h.A1; l++ {
+		if changed[l] {
+			return true
+		}
+	}
+	return false
+}
+
+// applyHunks returns src with the hunks replaced by their lines of want.
+func applyHunks(src string, lines, want []string, hunks []lineHunk) string {
+	var out []string
+	i := 0
+	for _, h := range hunks {
+		out = append(out, lines[i:h.A0]...)
+		out = append(out, want[h.B0:h.B1]...)
+		i = h.A1
+	}
+	out = append(out, lines[i:]...)
+	text := strings.Join(out, "\n")
+	if i < len(lines) && !strings.HasSuffix(src, "\n") {
+		// the last line is unchanged, without its final newline
+		return text
+	}
+	return text + "\n"
+}
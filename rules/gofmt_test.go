@@ -0,0 +1,113 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+const unformatted = `package main
+
+func old()  {}
+
+func main() {
+	x:=1
+	if x==1 {
+	println(x)
+	}
+}
+`
+
+func TestGoFormat(t *testing.T) {
+	pr := danger.DSL{Git: fakedsl.Git{
+		ModifiedFilesList: []string{"main.go", "README.md"},
+		Files:             map[string]string{"main.go": unformatted},
+		Diffs: map[string]dangerJs.FileDiff{
+			"main.go": {AddedLines: []dangerJs.DiffLine{
+				{Content: "\tx:=1", Line: 6},
+				{Content: "\tif x==1 {", Line: 7},
+				{Content: "\tprintln(x)", Line: 8},
+			}},
+			"README.md": {AddedLines: []dangerJs.DiffLine{{Content: "x:=1", Line: 1}}},
+		},
+	}}
+
+	d := danger.New()
+	GoFormat{Autofix: true}.Run(d, pr)
+
+	r := results(t, d)
+	require.Equal(t, []danger.Violation{
+		{Message: "`gofmt` formats this line differently:\n\n```suggestion\n\tx := 1\n```", File: "main.go", Line: 6},
+		{Message: "`gofmt` formats this line differently:\n\n```suggestion\n\tif x == 1 {\n```", File: "main.go", Line: 7},
+		{Message: "`gofmt` formats this line differently:\n\n```suggestion\n\t\tprintln(x)\n```", File: "main.go", Line: 8},
+	}, r.Warnings, "the unchanged line 3 is left alone")
+
+	fixes := d.Fixes()
+	require.Len(t, fixes, 1)
+	require.Equal(t, "Format `main.go` with gofmt", fixes[0].Description)
+	require.Equal(t, "--- a/main.go\n+++ b/main.go\n@@ -3,8 +3,8 @@\n func old()  {}\n \n func main() {\n-\tx:=1\n-\tif x==1 {\n-\tprintln(x)\n+\tx := 1\n+\tif x == 1 {\n+\t\tprintln(x)\n \t}\n }\n", fixes[0].Patch)
+}
+
+func TestGoFormatInsertedLines(t *testing.T) {
+	src := "package main\nimport \"fmt\"\nfunc main() { fmt.Println() }\n"
+	pr := danger.DSL{Git: fakedsl.Git{
+		CreatedFilesList: []string{"main.go"},
+		Files:            map[string]string{"main.go": src},
+		Diffs: map[string]dangerJs.FileDiff{
+			"main.go": {AddedLines: []dangerJs.DiffLine{
+				{Content: "package main", Line: 1},
+				{Content: "import \"fmt\"", Line: 2},
+				{Content: "func main() { fmt.Println() }", Line: 3},
+			}},
+		},
+	}}
+
+	d := danger.New()
+	GoFormat{}.Run(d, pr)
+
+	r := results(t, d)
+	require.Equal(t, []danger.Violation{
+		{Message: "`gofmt` formats this line differently:\n\n```suggestion\npackage main\n\n```", File: "main.go", Line: 1},
+		{Message: "`gofmt` formats this line differently:\n\n```suggestion\nimport \"fmt\"\n\n```", File: "main.go", Line: 2},
+	}, r.Warnings)
+	require.Empty(t, d.Fixes())
+}
+
+func TestGoFormatSyntaxError(t *testing.T) {
+	pr := danger.DSL{Git: fakedsl.Git{
+		CreatedFilesList: []string{"main.go"},
+		Files:            map[string]string{"main.go": "package main\nfunc {\n"},
+		Diffs: map[string]dangerJs.FileDiff{
+			"main.go": {AddedLines: []dangerJs.DiffLine{{Content: "func {", Line: 2}}},
+		},
+	}}
+
+	d := danger.New()
+	GoFormat{}.Run(d, pr)
+
+	r := results(t, d)
+	require.Len(t, r.Warnings, 1)
+	require.Contains(t, r.Warnings[0].Message, "Formatting: gofmt failed on `main.go`")
+}
+
+func TestGoFormatRemovedLines(t *testing.T) {
+	pr := danger.DSL{Git: fakedsl.Git{
+		ModifiedFilesList: []string{"main.go"},
+		Files:             map[string]string{"main.go": "package main\n\n\n\nfunc main() {}\n"},
+		Diffs: map[string]dangerJs.FileDiff{
+			"main.go": {AddedLines: []dangerJs.DiffLine{{Content: "", Line: 3}, {Content: "", Line: 4}}},
+		},
+	}}
+
+	d := danger.New()
+	GoFormat{}.Run(d, pr)
+
+	r := results(t, d)
+	require.Equal(t, []danger.Violation{
+		{Message: "`gofmt` formats lines 3-4 differently:\n\n```diff\n-\n-\n```", File: "main.go", Line: 3},
+	}, r.Warnings)
+}
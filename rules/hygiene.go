@@ -0,0 +1,108 @@
+package rules
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	danger "github.com/danger/golang"
+)
+
+// HygieneChecks selects which line hygiene checks run.
+type HygieneChecks struct {
+	CRLF               bool
+	BOM                bool
+	TrailingWhitespace bool
+	FinalNewline       bool
+}
+
+// AllHygieneChecks enables every check.
+var AllHygieneChecks = HygieneChecks{CRLF: true, BOM: true, TrailingWhitespace: true, FinalNewline: true}
+
+// LineHygiene warns about line-ending and encoding problems introduced on
+// added lines: CRLF line endings, byte order marks, trailing whitespace and
+// missing final newlines.
+type LineHygiene struct {
+	Refs
+	// Default is used for files whose extension is not in ByExtension.
+	Default HygieneChecks
+	// ByExtension overrides the checks for a file extension, e.g. ".md".
+	ByExtension map[string]HygieneChecks
+	// Summary adds a markdown table counting the issues found.
+	Summary bool
+}
+
+// NewLineHygiene returns a LineHygiene rule with every check enabled, except
+// trailing whitespace in markdown where it is a line break.
+func NewLineHygiene() LineHygiene {
+	md := AllHygieneChecks
+	md.TrailingWhitespace = false
+	return LineHygiene{
+		Default:     AllHygieneChecks,
+		ByExtension: map[string]HygieneChecks{".md": md},
+		Summary:     true,
+	}
+}
+
+func (h LineHygiene) checksFor(file string) HygieneChecks {
+	if c, ok := h.ByExtension[strings.ToLower(filepath.Ext(file))]; ok {
+		return c
+	}
+	return h.Default
+}
+
+// Run checks the added lines of every created and modified file.
+func (h LineHygiene) Run(d *danger.T, pr danger.DSL) {
+	var crlf, bom, trailing, finalNewline int
+	for _, file := range touchedFiles(pr) {
+		checks := h.checksFor(file)
+		if checks == (HygieneChecks{}) {
+			continue
+		}
+		diff, err := h.diff(pr, file)
+		if err != nil {
+			d.Warn(fmt.Sprintf("Line hygiene: could not diff `%s`: %s", file, err), file, 0)
+			continue
+		}
+		for _, l := range diff.AddedLines {
+			content := l.Content
+			if checks.CRLF && strings.HasSuffix(content, "\r") {
+				d.Warn(d.Msg("rules.hygiene.crlf", nil), file, l.Line)
+				crlf++
+			}
+			content = strings.TrimSuffix(content, "\r")
+			if checks.BOM && l.Line == 1 && strings.HasPrefix(content, "\uFEFF") {
+				d.Warn(d.Msg("rules.hygiene.bom", nil), file, l.Line)
+				bom++
+			}
+			if checks.TrailingWhitespace && content != strings.TrimRight(content, " \t") {
+				d.Warn(d.Msg("rules.hygiene.trailing_whitespace", nil), file, l.Line)
+				trailing++
+			}
+			if checks.FinalNewline && l.NoNewlineAtEOF {
+				d.Warn(d.Msg("rules.hygiene.final_newline", nil), file, l.Line)
+				finalNewline++
+			}
+		}
+	}
+
+	if !h.Summary || crlf+bom+trailing+finalNewline == 0 {
+		return
+	}
+	var sb strings.Builder
+	sb.WriteString("### Line hygiene\n\n| Issue | Lines |\n| --- | --- |\n")
+	for _, row := range []struct {
+		name  string
+		count int
+	}{
+		{"CRLF line endings", crlf},
+		{"Byte order marks", bom},
+		{"Trailing whitespace", trailing},
+		{"Missing final newline", finalNewline},
+	} {
+		if row.count > 0 {
+			fmt.Fprintf(&sb, "| %s | %d |\n", row.name, row.count)
+		}
+	}
+	d.Markdown(sb.String(), "", 0)
+}
@@ -0,0 +1,93 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+func TestLineHygiene(t *testing.T) {
+	pr := danger.DSL{Git: fakedsl.Git{
+		CreatedFilesList:  []string{"new.go"},
+		ModifiedFilesList: []string{"README.md"},
+		Diffs: map[string]dangerJs.FileDiff{
+			"new.go": {AddedLines: []dangerJs.DiffLine{
+				{Content: "\uFEFFpackage main\r", Line: 1},
+				{Content: "func main() {} ", Line: 2},
+				{Content: "// end", Line: 3, NoNewlineAtEOF: true},
+			}},
+			"README.md": {AddedLines: []dangerJs.DiffLine{
+				{Content: "line break  ", Line: 4},
+			}},
+		},
+	}}
+
+	d := danger.New()
+	NewLineHygiene().Run(d, pr)
+
+	r := results(t, d)
+	require.Equal(t, []danger.Violation{
+		{Message: "CRLF line ending introduced", File: "new.go", Line: 1},
+		{Message: "Byte order mark (BOM) added at start of file", File: "new.go", Line: 1},
+		{Message: "Trailing whitespace", File: "new.go", Line: 2},
+		{Message: "No newline at end of file", File: "new.go", Line: 3},
+	}, r.Warnings)
+	require.Len(t, r.Markdowns, 1)
+	require.Contains(t, r.Markdowns[0].Message, "| CRLF line endings | 1 |")
+	require.Contains(t, r.Markdowns[0].Message, "| Trailing whitespace | 1 |")
+}
+
+func TestLineHygieneClean(t *testing.T) {
+	pr := danger.DSL{Git: fakedsl.Git{
+		ModifiedFilesList: []string{"main.go"},
+		Diffs: map[string]dangerJs.FileDiff{
+			"main.go": {AddedLines: []dangerJs.DiffLine{{Content: "package main", Line: 1}}},
+		},
+	}}
+
+	d := danger.New()
+	NewLineHygiene().Run(d, pr)
+
+	r := results(t, d)
+	require.Empty(t, r.Warnings)
+	require.Empty(t, r.Markdowns)
+}
+
+func TestLineHygieneDisabledExtension(t *testing.T) {
+	pr := danger.DSL{Git: fakedsl.Git{
+		ModifiedFilesList: []string{"windows.bat"},
+		Diffs: map[string]dangerJs.FileDiff{
+			"windows.bat": {AddedLines: []dangerJs.DiffLine{{Content: "echo hi\r", Line: 1}}},
+		},
+	}}
+
+	h := NewLineHygiene()
+	h.ByExtension[".bat"] = HygieneChecks{}
+
+	d := danger.New()
+	h.Run(d, pr)
+	require.Empty(t, results(t, d).Warnings)
+}
+
+func TestLineHygieneMessages(t *testing.T) {
+	pr := danger.DSL{Git: fakedsl.Git{
+		ModifiedFilesList: []string{"main.go"},
+		Diffs: map[string]dangerJs.FileDiff{
+			"main.go": {AddedLines: []dangerJs.DiffLine{{Content: "x := 1 ", Line: 3}}},
+		},
+	}}
+
+	d := danger.New(danger.WithMessages(danger.Messages{
+		"rules.hygiene.trailing_whitespace": "Trailing whitespace, see https://wiki.example.com/style",
+	}))
+	NewLineHygiene().Run(d, pr)
+
+	r := results(t, d)
+	require.Equal(t, []danger.Violation{
+		{Message: "Trailing whitespace, see https://wiki.example.com/style", File: "main.go", Line: 3},
+	}, r.Warnings)
+}
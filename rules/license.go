@@ -0,0 +1,147 @@
+package rules
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// DefaultCommentPrefixes are the line comment markers of common languages,
+// by file extension.
+var DefaultCommentPrefixes = map[string]string{
+	".go": "//", ".js": "//", ".jsx": "//", ".ts": "//", ".tsx": "//", ".java": "//", ".kt": "//",
+	".swift": "//", ".c": "//", ".h": "//", ".cc": "//", ".cpp": "//", ".hpp": "//", ".rs": "//",
+	".scala": "//", ".cs": "//", ".proto": "//", ".dart": "//",
+	".py": "#", ".sh": "#", ".rb": "#", ".pl": "#", ".tf": "#", ".yaml": "#", ".yml": "#", ".toml": "#",
+	".sql": "--", ".lua": "--",
+}
+
+// LicenseHeader requires newly created source files to start with a license
+// or copyright header. The failure carries a GitHub suggestion block adding
+// the header, so it can be accepted from the review.
+type LicenseHeader struct {
+	Refs
+	// Template is the header without comment markers, one line per line,
+	// e.g. "Copyright {{year}} {{owner}}\nSPDX-License-Identifier: MIT".
+	// {{year}} accepts any year or range of years in existing headers and is
+	// the current year in suggestions.
+	Template string
+	// Owner replaces {{owner}}.
+	Owner string
+	// CommentPrefixes maps file extensions to their line comment marker,
+	// DefaultCommentPrefixes when nil. Files of other extensions aren't
+	// checked.
+	CommentPrefixes map[string]string
+	// Ignore are globs of files which aren't checked, e.g. "vendor/**".
+	Ignore []string
+	// Autofix adds the header as a fix, which the runner commits when
+	// DANGER_AUTOFIX is set.
+	Autofix bool
+	// Warn reports violations as warnings instead of failures.
+	Warn bool
+}
+
+// yearRe matches a year or a range of years in a header.
+const yearRe = `\d{4}(?:\s*[-–,]\s*\d{4})*`
+
+// render returns the header with the comment marker prefix, for the year.
+func (l LicenseHeader) render(prefix string, year int) []string {
+	text := strings.ReplaceAll(l.Template, "{{owner}}", l.Owner)
+	text = strings.ReplaceAll(text, "{{year}}", strconv.Itoa(year))
+	return commentLines(prefix, text)
+}
+
+// pattern matches the header with the comment marker prefix at the start of
+// a file.
+func (l LicenseHeader) pattern(prefix string) *regexp.Regexp {
+	text := strings.ReplaceAll(l.Template, "{{owner}}", l.Owner)
+	lines := commentLines(prefix, text)
+	for i, line := range lines {
+		parts := strings.Split(line, "{{year}}")
+		for j, p := range parts {
+			parts[j] = regexp.QuoteMeta(p)
+		}
+		// tolerate trailing whitespace
+		lines[i] = strings.Join(parts, yearRe) + `[ \t]*`
+	}
+	return regexp.MustCompile(`\A` + strings.Join(lines, `\r?\n`) + `(?:\r?\n|\z)`)
+}
+
+func commentLines(prefix, text string) []string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = prefix
+		} else {
+			lines[i] = prefix + " " + line
+		}
+	}
+	return lines
+}
+
+// Run checks the files created by the PR.
+func (l LicenseHeader) Run(d *danger.T, pr danger.DSL) {
+	if strings.TrimSpace(l.Template) == "" {
+		return
+	}
+	prefixes := l.CommentPrefixes
+	if prefixes == nil {
+		prefixes = DefaultCommentPrefixes
+	}
+	_, head := l.refs()
+	for _, file := range pr.Git.CreatedFiles() {
+		prefix, ok := prefixes[strings.ToLower(path.Ext(file))]
+		if !ok || matchesAny(l.Ignore, file) {
+			continue
+		}
+		src, err := dangerJs.FileAtRef(pr.Git, file, head)
+		if err != nil {
+			d.Warn(fmt.Sprintf("License header: could not read `%s`: %s", file, err), file, 0)
+			continue
+		}
+		if strings.TrimSpace(src) == "" || isGenerated(src) {
+			continue
+		}
+		// the header goes after a shebang line
+		line, body := 1, src
+		if strings.HasPrefix(src, "#!") {
+			_, body, _ = strings.Cut(src, "\n")
+			line = 2
+		}
+		if l.pattern(prefix).MatchString(body) {
+			continue
+		}
+
+		header := strings.Join(l.render(prefix, d.Now().Year()), "\n")
+		first, _, _ := strings.Cut(body, "\n")
+		msg := d.Msg("rules.license.missing", map[string]any{"file": file, "header": header, "first": strings.TrimSuffix(first, "\r")})
+		if l.Warn {
+			d.Warn(msg, file, line)
+		} else {
+			d.Fail(msg, file, line)
+		}
+		if l.Autofix {
+			fixed := strings.TrimSuffix(src, body) + header + "\n\n" + body
+			d.AddFix(fmt.Sprintf("Add the license header to `%s`", file), unifiedDiff(file, src, fixed))
+		}
+	}
+}
+
+// generatedRe matches the marker of generated Go files, also used by other
+// generators.
+var generatedRe = regexp.MustCompile(`(?m)^(//|#) Code generated .* DO NOT EDIT\.$`)
+
+// isGenerated reports whether the file is marked as generated in its first
+// lines.
+func isGenerated(src string) bool {
+	head := src
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	return generatedRe.MatchString(head)
+}
@@ -0,0 +1,57 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	"github.com/danger/golang/fakedsl"
+)
+
+func TestLicenseHeader(t *testing.T) {
+	pr := danger.DSL{Git: fakedsl.Git{
+		CreatedFilesList: []string{"ok.go", "range.go", "missing.go", "run.sh", "gen.go", "vendor/x/x.go", "notes.txt", "script.py"},
+		Files: map[string]string{
+			"ok.go":         "// Copyright 2026 Acme Inc.\n// SPDX-License-Identifier: MIT\n\npackage a\n",
+			"range.go":      "// Copyright 2019-2026 Acme Inc.\n// SPDX-License-Identifier: MIT\npackage a\n",
+			"missing.go":    "package a\n",
+			"run.sh":        "#!/bin/sh\necho hi\n",
+			"gen.go":        "// Code generated by stringer. DO NOT EDIT.\n\npackage a\n",
+			"vendor/x/x.go": "package x\n",
+			"notes.txt":     "notes\n",
+			"script.py":     "# Copyright 2026 Other Corp.\n# SPDX-License-Identifier: MIT\n",
+		},
+	}}
+
+	d := danger.New(danger.WithClock(func() time.Time { return time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC) }))
+	LicenseHeader{
+		Template: "Copyright {{year}} {{owner}}\nSPDX-License-Identifier: MIT",
+		Owner:    "Acme Inc.",
+		Ignore:   []string{"vendor/**"},
+		Autofix:  true,
+	}.Run(d, pr)
+
+	r := results(t, d)
+	require.Equal(t, []danger.Violation{
+		{Message: "`missing.go` lacks the license header:\n\n```suggestion\n// Copyright 2026 Acme Inc.\n// SPDX-License-Identifier: MIT\n\npackage a\n```", File: "missing.go", Line: 1},
+		{Message: "`run.sh` lacks the license header:\n\n```suggestion\n# Copyright 2026 Acme Inc.\n# SPDX-License-Identifier: MIT\n\necho hi\n```", File: "run.sh", Line: 2},
+		{Message: "`script.py` lacks the license header:\n\n```suggestion\n# Copyright 2026 Acme Inc.\n# SPDX-License-Identifier: MIT\n\n# Copyright 2026 Other Corp.\n```", File: "script.py", Line: 1},
+	}, r.Fails)
+
+	fixes := d.Fixes()
+	require.Len(t, fixes, 3)
+	require.Equal(t, "Add the license header to `run.sh`", fixes[1].Description)
+	require.Equal(t, "--- a/run.sh\n+++ b/run.sh\n@@ -1,2 +1,5 @@\n #!/bin/sh\n+# Copyright 2026 Acme Inc.\n+# SPDX-License-Identifier: MIT\n+\n echo hi\n", fixes[1].Patch)
+}
+
+func TestLicenseHeaderNoTemplate(t *testing.T) {
+	pr := danger.DSL{Git: fakedsl.Git{CreatedFilesList: []string{"a.go"}, Files: map[string]string{"a.go": "package a\n"}}}
+
+	d := danger.New()
+	LicenseHeader{}.Run(d, pr)
+
+	r := results(t, d)
+	require.Empty(t, r.Fails)
+}
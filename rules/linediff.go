@@ -0,0 +1,157 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// lineHunk replaces the lines a[A0:A1] with b[B0:B1].
+type lineHunk struct {
+	A0, A1 int
+	B0, B1 int
+}
+
+// diffLines returns the hunks turning a into b, found with the Myers
+// algorithm, which is fast for the few edits of formatters and fixers.
+func diffLines(a, b []string) []lineHunk {
+	n, m := len(a), len(b)
+	off := n + m + 1
+	v := make([]int, 2*off+1)
+	var trace [][]int
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, append([]int{}, v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// walk back through the rounds collecting the matching lines
+	type match struct{ x, y int }
+	var matches []match
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevX, prevY := 0, 0
+		if d > 0 {
+			prevK := k - 1
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				prevK = k + 1
+			}
+			prevX = v[off+prevK]
+			prevY = prevX - prevK
+		}
+		for x > prevX && y > prevY {
+			x--
+			y--
+			matches = append(matches, match{x, y})
+		}
+		x, y = prevX, prevY
+	}
+
+	var hunks []lineHunk
+	i, j := 0, 0
+	for idx := len(matches) - 1; idx >= 0; idx-- {
+		mt := matches[idx]
+		if mt.x > i || mt.y > j {
+			hunks = append(hunks, lineHunk{i, mt.x, j, mt.y})
+		}
+		i, j = mt.x+1, mt.y+1
+	}
+	if i < n || j < m {
+		hunks = append(hunks, lineHunk{i, n, j, m})
+	}
+	return hunks
+}
+
+// splitLines splits text into lines without their terminators. eol reports
+// whether the last line ends with a newline.
+func splitLines(text string) (lines []string, eol bool) {
+	if text == "" {
+		return nil, true
+	}
+	eol = strings.HasSuffix(text, "\n")
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n"), eol
+}
+
+// unifiedDiff returns the diff turning text a into b, as git diff formats it
+// with three lines of context, or "" when they are equal.
+func unifiedDiff(path, a, b string) string {
+	const context = 3
+	al, aEOL := splitLines(a)
+	bl, bEOL := splitLines(b)
+	hunks := diffLines(al, bl)
+	if aEOL != bEOL && (len(hunks) == 0 || hunks[len(hunks)-1].A1 < len(al)) {
+		// the final newline differs on a line which is otherwise equal
+		hunks = append(hunks, lineHunk{len(al) - 1, len(al), len(bl) - 1, len(bl)})
+	}
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", path, path)
+	line := func(prefix string, lines []string, i int, eol bool) {
+		sb.WriteString(prefix + lines[i] + "\n")
+		if i == len(lines)-1 && !eol {
+			sb.WriteString("\\ No newline at end of file\n")
+		}
+	}
+	for start := 0; start < len(hunks); {
+		// group hunks whose context overlaps
+		end := start + 1
+		for end < len(hunks) && hunks[end].A0-hunks[end-1].A1 <= 2*context {
+			end++
+		}
+		first, last := hunks[start], hunks[end-1]
+		a0 := max(0, first.A0-context)
+		a1 := min(len(al), last.A1+context)
+		b0 := first.B0 - (first.A0 - a0)
+		b1 := last.B1 + (a1 - last.A1)
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(a0, a1), hunkRange(b0, b1))
+		i := a0
+		for _, h := range hunks[start:end] {
+			for ; i < h.A0; i++ {
+				line(" ", al, i, aEOL)
+			}
+			for k := h.A0; k < h.A1; k++ {
+				line("-", al, k, aEOL)
+			}
+			for k := h.B0; k < h.B1; k++ {
+				line("+", bl, k, bEOL)
+			}
+			i = h.A1
+		}
+		for ; i < a1; i++ {
+			line(" ", al, i, aEOL)
+		}
+		start = end
+	}
+	return sb.String()
+}
+
+// hunkRange formats the lines [from, to) for a hunk header.
+func hunkRange(from, to int) string {
+	switch to - from {
+	case 0:
+		return fmt.Sprintf("%d,0", from)
+	case 1:
+		return fmt.Sprint(from + 1)
+	}
+	return fmt.Sprintf("%d,%d", from+1, to-from)
+}
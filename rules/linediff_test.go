@@ -0,0 +1,61 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []lineHunk
+	}{
+		{name: "equal", a: "a b c", b: "a b c"},
+		{name: "replaced", a: "a b c", b: "a x c", want: []lineHunk{{1, 2, 1, 2}}},
+		{name: "inserted", a: "a c", b: "a b c", want: []lineHunk{{1, 1, 1, 2}}},
+		{name: "deleted", a: "a b c", b: "a c", want: []lineHunk{{1, 2, 1, 1}}},
+		{name: "several", a: "a b c d e", b: "x b c e y", want: []lineHunk{{0, 1, 0, 1}, {3, 4, 3, 3}, {5, 5, 4, 5}}},
+		{name: "from empty", a: "", b: "a b", want: []lineHunk{{0, 0, 0, 2}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, diffLines(strings.Fields(tt.a), strings.Fields(tt.b)))
+		})
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{name: "equal", a: "a\nb\n", b: "a\nb\n"},
+		{
+			name: "context",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			b:    "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			want: "--- a/f.go\n+++ b/f.go\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name: "separate hunks",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			b:    "one\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			want: "--- a/f.go\n+++ b/f.go\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -7,4 +7,3 @@\n 7\n 8\n 9\n-10\n",
+		},
+		{
+			name: "final newline",
+			a:    "a\nb",
+			b:    "a\nb\n",
+			want: "--- a/f.go\n+++ b/f.go\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, unifiedDiff("f.go", tt.a, tt.b))
+		})
+	}
+}
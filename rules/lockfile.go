@@ -0,0 +1,106 @@
+package rules
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// Lockfiles summarizes the changes of go.sum, package-lock.json and yarn.lock
+// files as the packages they add, remove and update, instead of their raw
+// diffs, and warns when a lockfile changes without the manifest next to it.
+type Lockfiles struct {
+	Refs
+	// MaxRows caps the packages listed per lockfile, the rest being counted.
+	// Unlimited when zero.
+	MaxRows int
+	// AllowWithoutManifest are globs of lockfiles which may change on their
+	// own, e.g. "**/go.sum" when `go mod tidy` only adds hashes.
+	AllowWithoutManifest []string
+}
+
+// NewLockfiles returns a Lockfiles rule listing up to 50 packages per
+// lockfile.
+func NewLockfiles() Lockfiles {
+	return Lockfiles{MaxRows: 50}
+}
+
+// Run summarizes the created and modified lockfiles.
+func (l Lockfiles) Run(d *danger.T, pr danger.DSL) {
+	touched := touchedFiles(pr)
+	created := pr.Git.CreatedFiles()
+	base, head := l.refs()
+	var diffs []dangerJs.LockfileDiff
+	for _, file := range touched {
+		if !dangerJs.IsLockfile(file) {
+			continue
+		}
+		manifest := path.Join(path.Dir(file), dangerJs.LockfileManifests[path.Base(file)])
+		if !slices.Contains(touched, manifest) && !matchesAny(l.AllowWithoutManifest, file) {
+			d.Warn(d.Msg("rules.lockfiles.without_manifest", map[string]any{"file": file, "manifest": manifest}), file, 0)
+		}
+
+		var before string
+		if !slices.Contains(created, file) {
+			content, err := dangerJs.FileAtRef(pr.Git, file, base)
+			if err != nil {
+				d.Warn(fmt.Sprintf("Lockfiles: could not read `%s` at %s: %s", file, base, err), file, 0)
+				continue
+			}
+			before = content
+		}
+		after, err := dangerJs.FileAtRef(pr.Git, file, head)
+		if err != nil {
+			d.Warn(fmt.Sprintf("Lockfiles: could not read `%s`: %s", file, err), file, 0)
+			continue
+		}
+		diff, err := dangerJs.DiffLockfiles(file, before, after)
+		if err != nil {
+			d.Warn(fmt.Sprintf("Lockfiles: %s", err), file, 0)
+			continue
+		}
+		if !diff.Empty() {
+			diffs = append(diffs, diff)
+		}
+	}
+	if len(diffs) > 0 {
+		d.Markdown(l.table(diffs), "", 0)
+	}
+}
+
+// table renders a collapsed table of the package changes of each lockfile.
+func (l Lockfiles) table(diffs []dangerJs.LockfileDiff) string {
+	var sb strings.Builder
+	sb.WriteString("### Lockfile changes\n")
+	for _, diff := range diffs {
+		fmt.Fprintf(&sb, "\n<details>\n<summary><code>%s</code>: %s</summary>\n\n", diff.File, diff.Summary())
+		sb.WriteString("| Package | Change |\n| --- | --- |\n")
+		rows := 0
+		total := len(diff.Added) + len(diff.Removed) + len(diff.Updated)
+		for _, section := range []struct {
+			changes []dangerJs.DependencyChange
+			render  func(c dangerJs.DependencyChange) string
+		}{
+			{diff.Updated, func(c dangerJs.DependencyChange) string { return c.From + " → " + c.To }},
+			{diff.Added, func(c dangerJs.DependencyChange) string { return "added " + c.To }},
+			{diff.Removed, func(c dangerJs.DependencyChange) string { return "removed " + c.From }},
+		} {
+			for _, c := range section.changes {
+				if l.MaxRows > 0 && rows >= l.MaxRows {
+					break
+				}
+				fmt.Fprintf(&sb, "| `%s` | %s |\n", c.Name, section.render(c))
+				rows++
+			}
+		}
+		if rows < total {
+			fmt.Fprintf(&sb, "\n%d more %s.\n", total-rows, plural(total-rows, "package", "packages"))
+		}
+		sb.WriteString("</details>\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -0,0 +1,61 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	"github.com/danger/golang/fakedsl"
+)
+
+func TestLockfiles(t *testing.T) {
+	pr := danger.DSL{Git: fakedsl.Git{
+		CreatedFilesList:  []string{"web/yarn.lock"},
+		ModifiedFilesList: []string{"go.sum", "go.mod", "README.md"},
+		Files: map[string]string{
+			"HEAD^:go.sum":  "a.example/x v1.0.0 h1:=\nb.example/y v0.1.0 h1:=\n",
+			"HEAD:go.sum":   "a.example/x v1.2.0 h1:=\nc.example/z v0.2.0 h1:=\n",
+			"web/yarn.lock": "lodash@^4.17.21:\n  version \"4.17.21\"\n",
+		},
+	}}
+
+	d := danger.New()
+	NewLockfiles().Run(d, pr)
+
+	r := results(t, d)
+	require.Equal(t, []danger.Violation{{
+		Message: "`web/yarn.lock` changed without `web/package.json`, update dependencies through the manifest so they stay in sync.",
+		File:    "web/yarn.lock",
+	}}, r.Warnings)
+	require.Len(t, r.Markdowns, 1)
+	require.Equal(t, "### Lockfile changes\n"+
+		"\n<details>\n<summary><code>web/yarn.lock</code>: 1 added</summary>\n\n"+
+		"| Package | Change |\n| --- | --- |\n"+
+		"| `lodash` | added 4.17.21 |\n"+
+		"</details>\n"+
+		"\n<details>\n<summary><code>go.sum</code>: 1 added, 1 removed, 1 updated</summary>\n\n"+
+		"| Package | Change |\n| --- | --- |\n"+
+		"| `a.example/x` | v1.0.0 → v1.2.0 |\n"+
+		"| `c.example/z` | added v0.2.0 |\n"+
+		"| `b.example/y` | removed v0.1.0 |\n"+
+		"</details>", r.Markdowns[0].Message)
+}
+
+func TestLockfilesMaxRows(t *testing.T) {
+	pr := danger.DSL{Git: fakedsl.Git{
+		ModifiedFilesList: []string{"go.sum"},
+		Files: map[string]string{
+			"HEAD^:go.sum": "",
+			"HEAD:go.sum":  "a.example/x v1.0.0 h1:=\nb.example/y v0.1.0 h1:=\nc.example/z v0.2.0 h1:=\n",
+		},
+	}}
+
+	d := danger.New()
+	Lockfiles{MaxRows: 1, AllowWithoutManifest: []string{"**/go.sum"}}.Run(d, pr)
+
+	r := results(t, d)
+	require.Empty(t, r.Warnings)
+	require.Len(t, r.Markdowns, 1)
+	require.Contains(t, r.Markdowns[0].Message, "| `a.example/x` | added v1.0.0 |\n\n2 more packages.\n</details>")
+}
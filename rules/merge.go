@@ -0,0 +1,74 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// MergeConflicts warns when the PR conflicts with its base branch, listing
+// the conflicting paths found with `git merge-tree`, and when the platform
+// requires the branch to be rebased first. When git can't merge the refs,
+// e.g. in a shallow clone, the mergeability reported by GitHub or GitLab is
+// used instead.
+type MergeConflicts struct {
+	// BaseRef defaults to origin/<base branch> and HeadRef to HEAD.
+	Refs
+	// Fail reports conflicts as failures instead of warnings.
+	Fail bool
+}
+
+// Run checks whether the PR merges cleanly.
+func (m MergeConflicts) Run(d *danger.T, pr danger.DSL) {
+	branch := pr.BaseBranch()
+	state := pr.Mergeability()
+
+	base, head := m.BaseRef, m.HeadRef
+	if base == "" && branch != "" {
+		base = "origin/" + branch
+	}
+	if head == "" {
+		head = "HEAD"
+	}
+	if base != "" && pr.Git != nil {
+		if files, err := dangerJs.MergeConflicts(pr.Git, base, head); err == nil {
+			if len(files) > 0 {
+				var sb strings.Builder
+				fmt.Fprintf(&sb, "This PR has merge conflicts with `%s` in %d %s:\n", branchName(branch, base), len(files), plural(len(files), "file", "files"))
+				for _, f := range files {
+					fmt.Fprintf(&sb, "- `%s`\n", f)
+				}
+				sb.WriteString("\nMerge or rebase onto the base branch to resolve them before review.")
+				report(d, !m.Fail, sb.String())
+				return
+			}
+			state.Conflicting = false
+		}
+	}
+
+	switch {
+	case state.Known && state.Conflicting:
+		report(d, !m.Fail, d.Msg("rules.merge.conflicts", map[string]any{"base": branchName(branch, base)}))
+	case state.Known && state.NeedsRebase:
+		d.Warn(d.Msg("rules.merge.behind", map[string]any{"base": branchName(branch, base)}), "", 0)
+	}
+}
+
+func branchName(branch, ref string) string {
+	if branch != "" {
+		return branch
+	}
+	if ref != "" {
+		return ref
+	}
+	return "the base branch"
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,67 @@
+package rules
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+func TestMergeConflicts(t *testing.T) {
+	yes, no := true, false
+	pr := func(mergeable *bool, state string) dangerJs.GitHubPR {
+		p := dangerJs.GitHubPR{Number: 1, Mergeable: mergeable, MergeableState: state}
+		p.Base.Ref = "main"
+		return p
+	}
+	tests := []struct {
+		name     string
+		git      fakedsl.Git
+		pr       dangerJs.GitHubPR
+		fail     bool
+		warnings []danger.Violation
+		fails    []danger.Violation
+	}{
+		{
+			name: "conflicting files",
+			git:  fakedsl.Git{Conflicts: []string{"go.mod", "main.go"}},
+			pr:   pr(&no, "dirty"),
+			warnings: []danger.Violation{{Message: "This PR has merge conflicts with `main` in 2 files:\n- `go.mod`\n- `main.go`\n" +
+				"\nMerge or rebase onto the base branch to resolve them before review."}},
+		},
+		{
+			name:  "fail",
+			git:   fakedsl.Git{Conflicts: []string{"go.mod"}},
+			pr:    pr(&no, "dirty"),
+			fail:  true,
+			fails: []danger.Violation{{Message: "This PR has merge conflicts with `main` in 1 file:\n- `go.mod`\n\nMerge or rebase onto the base branch to resolve them before review."}},
+		},
+		{
+			name:     "git unavailable",
+			git:      fakedsl.Git{Errs: map[string]error{"MergeConflicts": errors.New("shallow clone")}},
+			pr:       pr(&no, "dirty"),
+			warnings: []danger.Violation{{Message: "This PR has merge conflicts with `main`, resolve them before review."}},
+		},
+		{
+			name:     "behind",
+			pr:       pr(&yes, "behind"),
+			warnings: []danger.Violation{{Message: "This branch is behind `main` and needs to be rebased or updated before merging."}},
+		},
+		{name: "clean", pr: pr(&yes, "clean")},
+		{name: "git merges cleanly", pr: pr(&no, "dirty")},
+		{name: "unknown", git: fakedsl.Git{Errs: map[string]error{"MergeConflicts": errors.New("no git")}}, pr: pr(nil, "unknown")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := danger.New()
+			MergeConflicts{Fail: tt.fail}.Run(d, danger.DSL{Git: tt.git, GitHub: fakedsl.GitHub{PRData: tt.pr}})
+			r := results(t, d)
+			require.ElementsMatch(t, tt.warnings, r.Warnings)
+			require.ElementsMatch(t, tt.fails, r.Fails)
+		})
+	}
+}
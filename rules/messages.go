@@ -0,0 +1,36 @@
+package rules
+
+import danger "github.com/danger/golang"
+
+// The default messages of the rules, which the messages section of danger.yaml
+// can override, see danger.SetMessages.
+func init() {
+	danger.DefineMessages(danger.Messages{
+		"rules.binsize.over_limit":          "`{{.target}}` is {{.size}}, over the limit of {{.limit}}.",
+		"rules.binsize.grew":                "`{{.target}}` grew by {{.change}} to {{.size}}.",
+		"rules.branches.naming":             "Branch `{{.branch}}` does not follow the naming convention: {{.problems}}.",
+		"rules.commits.unverified":          "Commit `{{.sha}}` does not have a verified signature ({{.reason}})",
+		"rules.commits.missing_signoff":     "Commit `{{.sha}}` is missing a `Signed-off-by` line",
+		"rules.commits.signoff_mismatch":    "Commit `{{.sha}}` is not signed off by its author `{{.name}} <{{.email}}>`",
+		"rules.deprecated.usage":            "`{{.symbol}}` is deprecated{{if .replacement}}: {{.replacement}}{{end}}",
+		"rules.hygiene.crlf":                "CRLF line ending introduced",
+		"rules.hygiene.bom":                 "Byte order mark (BOM) added at start of file",
+		"rules.hygiene.trailing_whitespace": "Trailing whitespace",
+		"rules.hygiene.final_newline":       "No newline at end of file",
+		"rules.license.missing":             "`{{.file}}` lacks the license header:\n\n```suggestion\n{{.header}}\n\n{{.first}}\n```",
+		"rules.lockfiles.without_manifest":  "`{{.file}}` changed without `{{.manifest}}`, update dependencies through the manifest so they stay in sync.",
+		"rules.merge.conflicts":             "This PR has merge conflicts with `{{.base}}`, resolve them before review.",
+		"rules.merge.behind":                "This branch is behind `{{.base}}` and needs to be rebased or updated before merging.",
+		"rules.packages.moved": "Package `{{.from}}` moved to `{{.to}}`, which breaks its importers. " +
+			"Leave type aliases marked `// Deprecated:` in the old package, or mention the import path change in the PR description.",
+		"rules.stale.updated": "This branch was {{.behind}} commits behind `{{.base}}` and has been updated.",
+		"rules.stale.behind": "This branch is {{.behind}} {{if eq .behind 1}}commit{{else}}commits{{end}} behind `{{.base}}`. " +
+			"Merge or rebase onto `{{.base}}` so the PR is checked against current code.",
+		"rules.title.pattern": "PR title `{{.title}}` does not match `{{.pattern}}`.",
+		"rules.title.type":    "PR title type `{{.type}}` is not one of {{.types}}.",
+		"rules.title.format": "PR title `{{.title}}` does not follow the conventional commit format `type(scope): description`, " +
+			"e.g. `feat(api): add pagination`.",
+		"rules.title.fixed":   "Changed the PR title from `{{.title}}` to `{{.fixed}}` to follow the conventional commit format.",
+		"rules.title.suggest": "PR title `{{.title}}` does not follow the conventional commit format, change it to `{{.fixed}}`.",
+	})
+}
@@ -0,0 +1,73 @@
+package rules
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// PackageMoves warns when a PR moves Go packages to a new import path, which
+// breaks their importers, unless the old package is left behind as a
+// deprecated forwarder or the PR description mentions the old import path,
+// e.g. in a release note.
+type PackageMoves struct {
+	Refs
+}
+
+// Run checks the packages moved between the refs.
+func (p PackageMoves) Run(d *danger.T, pr danger.DSL) {
+	base, head := p.refs()
+	renames, err := dangerJs.RenamedFilesWithRefs(pr.Git, base, head)
+	if err != nil {
+		d.Warn(fmt.Sprintf("Could not detect renamed files: %s", err), "", 0)
+		return
+	}
+	gomod, _ := dangerJs.FileAtRef(pr.Git, "go.mod", head)
+	moves := dangerJs.GoPackageMoves(renames, dangerJs.GoModulePath(gomod))
+
+	body := description(pr)
+	for _, m := range moves {
+		from, to := m.FromImport, m.ToImport
+		if from == "" {
+			from, to = m.FromDir, m.ToDir
+		}
+		if strings.Contains(body, from) || p.forwards(pr, m.FromDir) {
+			continue
+		}
+		d.Warn(d.Msg("rules.packages.moved", map[string]any{"from": from, "to": to}), "", 0)
+	}
+}
+
+// forwards reports whether the PR adds a deprecation notice to a Go file of
+// dir.
+func (p PackageMoves) forwards(pr danger.DSL, dir string) bool {
+	for _, file := range touchedFiles(pr) {
+		if path.Dir(dangerJs.NormalizePath(file)) != dir || !strings.HasSuffix(file, ".go") {
+			continue
+		}
+		diff, err := p.diff(pr, file)
+		if err != nil {
+			continue
+		}
+		for _, l := range diff.AddedLines {
+			if strings.Contains(l.Content, "Deprecated:") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// description returns the body of the GitHub PR or GitLab MR.
+func description(pr danger.DSL) string {
+	switch {
+	case pr.GitHub != nil && pr.GitHub.PR().Number != 0:
+		return pr.GitHub.PR().Body
+	case pr.GitLab != nil && pr.GitLab.MR().IID != 0:
+		return pr.GitLab.MR().Description
+	}
+	return ""
+}
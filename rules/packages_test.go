@@ -0,0 +1,67 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+func TestPackageMoves(t *testing.T) {
+	renames := []dangerJs.RenamedFile{
+		{From: "internal/util/strings.go", To: "pkg/text/strings.go", Similarity: 100},
+	}
+	files := map[string]string{"go.mod": "module example.com/m\n\ngo 1.22\n"}
+	warning := "Package `example.com/m/internal/util` moved to `example.com/m/pkg/text`, which breaks its importers. " +
+		"Leave type aliases marked `// Deprecated:` in the old package, or mention the import path change in the PR description."
+
+	testCases := []struct {
+		name     string
+		git      fakedsl.Git
+		body     string
+		expected []string
+	}{
+		{
+			name:     "moved",
+			git:      fakedsl.Git{Renames: renames, Files: files},
+			expected: []string{warning},
+		},
+		{
+			name: "no moves",
+			git:  fakedsl.Git{Files: files},
+		},
+		{
+			name: "mentioned in description",
+			git:  fakedsl.Git{Renames: renames, Files: files},
+			body: "`example.com/m/internal/util` is now `example.com/m/pkg/text`.",
+		},
+		{
+			name: "deprecated forwarder",
+			git: fakedsl.Git{Renames: renames, Files: files, CreatedFilesList: []string{"internal/util/alias.go"},
+				Diffs: map[string]dangerJs.FileDiff{"internal/util/alias.go": {AddedLines: []dangerJs.DiffLine{
+					{Content: "// Deprecated: use example.com/m/pkg/text.", Line: 3},
+				}}}},
+		},
+		{
+			name: "no go.mod",
+			git:  fakedsl.Git{Renames: renames},
+			expected: []string{"Package `internal/util` moved to `pkg/text`, which breaks its importers. " +
+				"Leave type aliases marked `// Deprecated:` in the old package, or mention the import path change in the PR description."},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := danger.New()
+			pr := danger.DSL{Git: tc.git, GitHub: fakedsl.GitHub{PRData: dangerJs.GitHubPR{Number: 1, Body: tc.body}}}
+			PackageMoves{}.Run(d, pr)
+			var got []string
+			for _, w := range results(t, d).Warnings {
+				got = append(got, w.Message)
+			}
+			require.Equal(t, tc.expected, got)
+		})
+	}
+}
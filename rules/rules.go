@@ -0,0 +1,46 @@
+// Package rules contains built-in checks which can be called from a
+// dangerfile. Each rule is a configurable struct with a Run method matching
+// the dangerfile signature, e.g.
+//
+//	func Run(d *danger.T, pr danger.DSL) {
+//		rules.NewLineHygiene().Run(d, pr)
+//	}
+package rules
+
+import (
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// Refs selects the git refs a rule diffs between. When both are empty the
+// rule uses the DSL defaults of HEAD^ and HEAD.
+type Refs struct {
+	BaseRef string
+	HeadRef string
+}
+
+func (r Refs) diff(pr danger.DSL, file string) (dangerJs.FileDiff, error) {
+	if r.BaseRef == "" && r.HeadRef == "" {
+		return pr.Git.DiffForFile(file)
+	}
+	base, head := r.refs()
+	return pr.Git.DiffForFileWithRefs(file, base, head)
+}
+
+// refs returns the base and head refs, defaulting to HEAD^ and HEAD.
+func (r Refs) refs() (string, string) {
+	base, head := r.BaseRef, r.HeadRef
+	if base == "" {
+		base = "HEAD^"
+	}
+	if head == "" {
+		head = "HEAD"
+	}
+	return base, head
+}
+
+// touchedFiles returns the created and modified files of the PR.
+func touchedFiles(pr danger.DSL) []string {
+	files := append([]string{}, pr.Git.CreatedFiles()...)
+	return append(files, pr.Git.ModifiedFiles()...)
+}
@@ -0,0 +1,20 @@
+package rules
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+)
+
+// results returns the violations recorded on d.
+func results(t *testing.T, d *danger.T) danger.Results {
+	t.Helper()
+	s, err := d.Results()
+	require.Nil(t, err)
+	var r danger.Results
+	require.Nil(t, json.Unmarshal([]byte(s), &r))
+	return r
+}
@@ -0,0 +1,132 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/githubclient"
+	"github.com/danger/golang/gitlabclient"
+)
+
+// BranchUpdater brings the head branch of the PR up to date with its base.
+type BranchUpdater interface {
+	UpdateBranch(ctx context.Context) error
+}
+
+// StaleBranch warns when the base branch has advanced by more than
+// MaxBehind commits since the PR branched off it, counted with
+// `git rev-list --count`, so the PR is tested against outdated code. Without
+// the history of both branches, e.g. in a shallow clone, GitHub PRs are
+// compared with the API, see DSL.CommitsBehind, and others are skipped.
+type StaleBranch struct {
+	// BaseRef defaults to origin/<base branch> and HeadRef to HEAD.
+	Refs
+	// MaxBehind is the number of commits the PR may be behind, 50 when zero.
+	// A negative value reports any commit behind.
+	MaxBehind int
+	// Update updates the branch, merging the base branch on GitHub and
+	// rebasing on GitLab, when it is behind. It is ignored in fork-PR safe
+	// mode.
+	Update bool
+	// Updater defaults to the GitHub or GitLab API, authenticated with the
+	// DSL settings.
+	Updater BranchUpdater
+	// Fail reports stale branches as failures instead of warnings.
+	Fail bool
+}
+
+// Run checks how far the PR is behind its base branch.
+func (s StaleBranch) Run(d *danger.T, pr danger.DSL) {
+	branch := pr.BaseBranch()
+	base, head := s.BaseRef, s.HeadRef
+	if base == "" && branch != "" {
+		base = "origin/" + branch
+	}
+	if head == "" {
+		head = "HEAD"
+	}
+	if base == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	behind, err := pr.CommitsBehind(ctx, base, head)
+	if err != nil {
+		return
+	}
+	limit := s.MaxBehind
+	if limit == 0 {
+		limit = 50
+	}
+	if behind == 0 || behind <= limit {
+		return
+	}
+
+	name := branchName(branch, base)
+	if s.Update && !dangerJs.SafeMode() {
+		err := s.update(pr)
+		if err == nil {
+			d.Message(d.Msg("rules.stale.updated", map[string]any{"behind": behind, "base": name}), "", 0)
+			return
+		}
+		d.Warn(fmt.Sprintf("Stale branch: updating the branch: %s", err), "", 0)
+	}
+	report(d, !s.Fail, d.Msg("rules.stale.behind", map[string]any{"behind": behind, "base": name}))
+}
+
+func (s StaleBranch) update(pr danger.DSL) error {
+	u := s.Updater
+	if u == nil {
+		var err error
+		if u, err = branchUpdaterFor(pr); err != nil {
+			return err
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return u.UpdateBranch(ctx)
+}
+
+func branchUpdaterFor(pr danger.DSL) (BranchUpdater, error) {
+	if pr.GitHub != nil && pr.GitHub.PR().Number != 0 {
+		client, err := githubclient.NewFromSettings(pr.Settings)
+		if err != nil {
+			return nil, err
+		}
+		this := pr.GitHub.ThisPR()
+		return gitHubBranchUpdater{client: client, owner: this.Owner, repo: this.Repo, number: this.Number, sha: pr.GitHub.PR().Head.SHA}, nil
+	}
+	if pr.GitLab != nil && pr.GitLab.MR().IID != 0 {
+		client, err := gitlabclient.NewFromSettings(pr.Settings)
+		if err != nil {
+			return nil, err
+		}
+		return gitLabBranchUpdater{client: client, project: pr.GitLab.Metadata().RepoSlug, iid: pr.GitLab.MR().IID}, nil
+	}
+	return nil, fmt.Errorf("updating the branch requires a GitHub or GitLab PR")
+}
+
+type gitHubBranchUpdater struct {
+	client *githubclient.Client
+	owner  string
+	repo   string
+	number int
+	sha    string
+}
+
+func (u gitHubBranchUpdater) UpdateBranch(ctx context.Context) error {
+	return u.client.UpdateBranch(ctx, u.owner, u.repo, u.number, u.sha)
+}
+
+type gitLabBranchUpdater struct {
+	client  *gitlabclient.Client
+	project string
+	iid     int64
+}
+
+func (u gitLabBranchUpdater) UpdateBranch(ctx context.Context) error {
+	return u.client.RebaseMR(ctx, u.project, u.iid)
+}
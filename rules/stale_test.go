@@ -0,0 +1,94 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+type fakeBranchUpdater struct {
+	calls *int
+	err   error
+}
+
+func (u fakeBranchUpdater) UpdateBranch(context.Context) error {
+	*u.calls++
+	return u.err
+}
+
+func TestStaleBranch(t *testing.T) {
+	tests := []struct {
+		name      string
+		git       fakedsl.Git
+		compared  *dangerJs.GitHubComparison
+		rule      StaleBranch
+		updateErr error
+		updates   int
+		warnings  []danger.Violation
+		fails     []danger.Violation
+		messages  []danger.Violation
+	}{
+		{name: "up to date", git: fakedsl.Git{Behind: 3}},
+		{
+			name:     "behind",
+			git:      fakedsl.Git{Behind: 51},
+			warnings: []danger.Violation{{Message: "This branch is 51 commits behind `main`. Merge or rebase onto `main` so the PR is checked against current code."}},
+		},
+		{
+			name:  "custom limit",
+			git:   fakedsl.Git{Behind: 1},
+			rule:  StaleBranch{MaxBehind: -1, Fail: true},
+			fails: []danger.Violation{{Message: "This branch is 1 commit behind `main`. Merge or rebase onto `main` so the PR is checked against current code."}},
+		},
+		{name: "git unavailable", git: fakedsl.Git{Errs: map[string]error{"CommitsBehind": errors.New("shallow clone")}}},
+		{name: "history unavailable", git: fakedsl.Git{Errs: map[string]error{"CommitsBehind": dangerJs.ErrRefNotFound}}},
+		{
+			name:     "compared online",
+			git:      fakedsl.Git{Errs: map[string]error{"CommitsBehind": fmt.Errorf("%w: %w", dangerJs.ErrShallowClone, dangerJs.ErrRefNotFound)}},
+			compared: &dangerJs.GitHubComparison{Status: "diverged", AheadBy: 2, BehindBy: 60},
+			warnings: []danger.Violation{{Message: "This branch is 60 commits behind `main`. Merge or rebase onto `main` so the PR is checked against current code."}},
+		},
+		{
+			name:     "updated",
+			git:      fakedsl.Git{Behind: 80},
+			rule:     StaleBranch{Update: true},
+			updates:  1,
+			messages: []danger.Violation{{Message: "This branch was 80 commits behind `main` and has been updated."}},
+		},
+		{
+			name:      "update fails",
+			git:       fakedsl.Git{Behind: 80},
+			rule:      StaleBranch{Update: true},
+			updateErr: errors.New("merge conflict between base and head"),
+			updates:   1,
+			warnings: []danger.Violation{
+				{Message: "Stale branch: updating the branch: merge conflict between base and head"},
+				{Message: "This branch is 80 commits behind `main`. Merge or rebase onto `main` so the PR is checked against current code."},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(dangerJs.SafeModeEnv, "false")
+			var updates int
+			tt.rule.Updater = fakeBranchUpdater{calls: &updates, err: tt.updateErr}
+			var pr dangerJs.GitHubPR
+			pr.Number = 1
+			pr.Base.Ref = "main"
+			d := danger.New()
+			tt.rule.Run(d, danger.DSL{Git: tt.git, GitHub: fakedsl.GitHub{PRData: pr, Comparison: tt.compared}})
+			r := results(t, d)
+			require.ElementsMatch(t, tt.warnings, r.Warnings)
+			require.ElementsMatch(t, tt.fails, r.Fails)
+			require.ElementsMatch(t, tt.messages, r.Messages)
+			require.Equal(t, tt.updates, updates)
+		})
+	}
+}
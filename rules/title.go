@@ -0,0 +1,206 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/githubclient"
+	"github.com/danger/golang/gitlabclient"
+)
+
+// TitleEditor changes the title of the PR.
+type TitleEditor interface {
+	SetTitle(ctx context.Context, title string) error
+}
+
+// PRTitle requires the PR title to follow the conventional commit format,
+// e.g. "feat(api): add pagination", or to match Pattern. Conventional titles
+// with a fixable mistake, such as "Feat - add pagination" or a trailing
+// period, are corrected in the suggestion, or on the PR with Fix.
+type PRTitle struct {
+	// Pattern replaces the conventional commit format. Titles not matching
+	// it can't be fixed.
+	Pattern *regexp.Regexp
+	// Types are the allowed conventional commit types. Empty allows any.
+	Types []string
+	// Fix sets the corrected title on the PR instead of suggesting it. It is
+	// ignored in fork-PR safe mode.
+	Fix bool
+	// Editor changes the title when fixing it. It defaults to the GitHub or
+	// GitLab API, authenticated with the DSL settings.
+	Editor TitleEditor
+	// Warn reports violations as warnings instead of failures.
+	Warn bool
+}
+
+// NewPRTitle returns a PRTitle rule requiring a conventional title with one
+// of the common types.
+func NewPRTitle() PRTitle {
+	return PRTitle{Types: []string{"build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test"}}
+}
+
+var (
+	// looseTitleRe matches a type followed by a separator, e.g. "Feat - x",
+	// "[fix] x" or "feat:x".
+	looseTitleRe = regexp.MustCompile(`^\[?([A-Za-z]+)(\([^)]*\))?(!)?\]?\s*[:\-–]?\s*(.+)$`)
+	separatorRe  = regexp.MustCompile(`^[A-Za-z]+\s*[:\-–]`)
+	spaceRe      = regexp.MustCompile(`\s+`)
+)
+
+// FixTitle returns title corrected to the conventional commit format: the
+// type and scope lower-cased and followed by ": ", the first word of the
+// description lower-cased unless it is an acronym, and the trailing period
+// and extra spaces dropped. The second return value is false when title has
+// no recognizable type.
+func (t PRTitle) FixTitle(title string) (string, bool) {
+	title = spaceRe.ReplaceAllString(strings.TrimSpace(title), " ")
+	m := looseTitleRe.FindStringSubmatch(title)
+	if m == nil {
+		return "", false
+	}
+	typ := strings.ToLower(m[1])
+	if !t.knownType(typ) {
+		return "", false
+	}
+	// Without a separator a leading type is part of the sentence, e.g.
+	// "Fix crash on start".
+	if !strings.HasPrefix(title, "[") && m[2] == "" && m[3] == "" && !separatorRe.MatchString(title) {
+		return "", false
+	}
+	desc := strings.TrimRight(m[4], ". ")
+	if desc == "" {
+		return "", false
+	}
+	return typ + strings.ToLower(m[2]) + m[3] + ": " + lowerFirstWord(desc), true
+}
+
+// knownType reports whether typ is allowed, or with no Types configured,
+// whether it is one of the NewPRTitle types.
+func (t PRTitle) knownType(typ string) bool {
+	types := t.Types
+	if len(types) == 0 {
+		types = NewPRTitle().Types
+	}
+	return slices.Contains(types, typ)
+}
+
+// lowerFirstWord lower-cases the first letter of s unless its first word is
+// an acronym such as "API".
+func lowerFirstWord(s string) string {
+	word, _, _ := strings.Cut(s, " ")
+	if len(word) > 1 && strings.ToUpper(word) == word {
+		return s
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
+
+// Run checks the title of the PR.
+func (t PRTitle) Run(d *danger.T, pr danger.DSL) {
+	title := prTitle(pr)
+	if title == "" {
+		return
+	}
+	if t.Pattern != nil {
+		if !t.Pattern.MatchString(title) {
+			report(d, t.Warn, d.Msg("rules.title.pattern", map[string]any{"title": title, "pattern": t.Pattern.String()}))
+		}
+		return
+	}
+
+	c, ok := dangerJs.ParseConventionalCommit(title)
+	fixed, fixable := t.FixTitle(title)
+	switch {
+	case ok && len(t.Types) > 0 && !slices.Contains(t.Types, c.Type):
+		report(d, t.Warn, d.Msg("rules.title.type", map[string]any{"type": c.Type, "types": quoteList(t.Types)}))
+		return
+	case ok && (!fixable || fixed == title):
+		return
+	case !fixable:
+		report(d, t.Warn, d.Msg("rules.title.format", map[string]any{"title": title}))
+		return
+	}
+
+	if t.Fix && !dangerJs.SafeMode() {
+		err := t.setTitle(pr, fixed)
+		if err == nil {
+			d.Message(d.Msg("rules.title.fixed", map[string]any{"title": title, "fixed": fixed}), "", 0)
+			return
+		}
+		d.Warn(fmt.Sprintf("PR title: changing the title: %s", err), "", 0)
+	}
+	report(d, t.Warn, d.Msg("rules.title.suggest", map[string]any{"title": title, "fixed": fixed}))
+}
+
+func (t PRTitle) setTitle(pr danger.DSL, title string) error {
+	editor := t.Editor
+	if editor == nil {
+		var err error
+		if editor, err = titleEditorFor(pr); err != nil {
+			return err
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return editor.SetTitle(ctx, title)
+}
+
+func prTitle(pr danger.DSL) string {
+	switch {
+	case pr.GitHub != nil && pr.GitHub.PR().Title != "":
+		return pr.GitHub.PR().Title
+	case pr.GitLab != nil:
+		return pr.GitLab.MR().Title
+	}
+	return ""
+}
+
+func titleEditorFor(pr danger.DSL) (TitleEditor, error) {
+	if pr.GitHub != nil && pr.GitHub.PR().Number != 0 {
+		client, err := githubclient.NewFromSettings(pr.Settings)
+		if err != nil {
+			return nil, err
+		}
+		this := pr.GitHub.ThisPR()
+		return gitHubTitleEditor{client: client, owner: this.Owner, repo: this.Repo, number: this.Number}, nil
+	}
+	if pr.GitLab != nil && pr.GitLab.MR().IID != 0 {
+		client, err := gitlabclient.NewFromSettings(pr.Settings)
+		if err != nil {
+			return nil, err
+		}
+		return gitLabTitleEditor{client: client, project: pr.GitLab.Metadata().RepoSlug, iid: pr.GitLab.MR().IID}, nil
+	}
+	return nil, fmt.Errorf("changing the title requires a GitHub or GitLab PR")
+}
+
+type gitHubTitleEditor struct {
+	client *githubclient.Client
+	owner  string
+	repo   string
+	number int
+}
+
+func (e gitHubTitleEditor) SetTitle(ctx context.Context, title string) error {
+	_, err := e.client.UpdatePullRequest(ctx, e.owner, e.repo, e.number, map[string]any{"title": title})
+	return err
+}
+
+type gitLabTitleEditor struct {
+	client  *gitlabclient.Client
+	project string
+	iid     int64
+}
+
+func (e gitLabTitleEditor) SetTitle(ctx context.Context, title string) error {
+	_, err := e.client.UpdateMR(ctx, e.project, e.iid, map[string]any{"title": title})
+	return err
+}
@@ -0,0 +1,128 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+type fakeTitleEditor struct {
+	titles *[]string
+	err    error
+}
+
+func (e fakeTitleEditor) SetTitle(_ context.Context, title string) error {
+	*e.titles = append(*e.titles, title)
+	return e.err
+}
+
+func TestFixTitle(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+		ok    bool
+	}{
+		{"feat: add login", "feat: add login", true},
+		{"Feat - Add login.", "feat: add login", true},
+		{"[FIX] Crash on start", "fix: crash on start", true},
+		{"fix(API)!:drop v1", "fix(api)!: drop v1", true},
+		{"docs:  Update   README", "docs: update README", true},
+		{"feat: API pagination", "feat: API pagination", true},
+		{"Fix crash on start", "", false},
+		{"Update README", "", false},
+		{"wip: login", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			got, ok := NewPRTitle().FixTitle(tt.title)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPRTitle(t *testing.T) {
+	tests := []struct {
+		name     string
+		title    string
+		rule     PRTitle
+		editErr  error
+		edits    []string
+		fails    []danger.Violation
+		warnings []danger.Violation
+		messages []danger.Violation
+	}{
+		{name: "conventional", title: "feat(api): add pagination", rule: NewPRTitle()},
+		{
+			name:  "suggestion",
+			title: "Feat: Add pagination.",
+			rule:  NewPRTitle(),
+			fails: []danger.Violation{{Message: "PR title `Feat: Add pagination.` does not follow the conventional commit format, change it to `feat: add pagination`."}},
+		},
+		{
+			name:     "fixed",
+			title:    "[feat] Add pagination",
+			rule:     PRTitle{Types: NewPRTitle().Types, Fix: true},
+			edits:    []string{"feat: add pagination"},
+			messages: []danger.Violation{{Message: "Changed the PR title from `[feat] Add pagination` to `feat: add pagination` to follow the conventional commit format."}},
+		},
+		{
+			name:     "fix fails",
+			title:    "feat - pagination",
+			rule:     PRTitle{Fix: true},
+			editErr:  errors.New("403 Forbidden"),
+			edits:    []string{"feat: pagination"},
+			warnings: []danger.Violation{{Message: "PR title: changing the title: 403 Forbidden"}},
+			fails:    []danger.Violation{{Message: "PR title `feat - pagination` does not follow the conventional commit format, change it to `feat: pagination`."}},
+		},
+		{
+			name:  "unfixable",
+			title: "Add pagination",
+			rule:  PRTitle{Fix: true},
+			fails: []danger.Violation{{Message: "PR title `Add pagination` does not follow the conventional commit format `type(scope): description`, e.g. `feat(api): add pagination`."}},
+		},
+		{
+			name:     "type not allowed",
+			title:    "wip: pagination",
+			rule:     PRTitle{Types: []string{"feat", "fix"}, Warn: true},
+			warnings: []danger.Violation{{Message: "PR title type `wip` is not one of `feat` or `fix`."}},
+		},
+		{
+			name:  "pattern",
+			title: "Add pagination",
+			rule:  PRTitle{Pattern: regexp.MustCompile(`^[A-Z]+-[0-9]+: `)},
+			fails: []danger.Violation{{Message: "PR title `Add pagination` does not match `^[A-Z]+-[0-9]+: `."}},
+		},
+		{name: "pattern matches", title: "ABC-1: Add pagination", rule: PRTitle{Pattern: regexp.MustCompile(`^[A-Z]+-[0-9]+: `)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(dangerJs.SafeModeEnv, "false")
+			var edits []string
+			tt.rule.Editor = fakeTitleEditor{titles: &edits, err: tt.editErr}
+			d := danger.New()
+			tt.rule.Run(d, danger.DSL{GitHub: fakedsl.GitHub{PRData: dangerJs.GitHubPR{Number: 1, Title: tt.title}}})
+			r := results(t, d)
+			require.ElementsMatch(t, tt.fails, r.Fails)
+			require.ElementsMatch(t, tt.warnings, r.Warnings)
+			require.ElementsMatch(t, tt.messages, r.Messages)
+			require.Equal(t, tt.edits, edits)
+		})
+	}
+}
+
+func TestPRTitleSafeMode(t *testing.T) {
+	t.Setenv(dangerJs.SafeModeEnv, "true")
+	var edits []string
+	d := danger.New()
+	PRTitle{Fix: true, Editor: fakeTitleEditor{titles: &edits}}.Run(d, danger.DSL{GitHub: fakedsl.GitHub{PRData: dangerJs.GitHubPR{Title: "Feat: x"}}})
+	require.Empty(t, edits)
+	require.Len(t, results(t, d).Fails, 1)
+}
@@ -0,0 +1,182 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	danger "github.com/danger/golang"
+)
+
+// Clock returns the current time. *danger.T implements it with the clock set
+// by danger.WithClock.
+type Clock interface {
+	Now() time.Time
+}
+
+// Freeze is a period during which PRs must not be merged.
+type Freeze struct {
+	Start time.Time
+	// End is exclusive.
+	End    time.Time
+	Reason string
+}
+
+// MergeWindow reports PRs checked while merging is not allowed: on weekends
+// or during a release freeze, configured in code, a JSON freeze file or an
+// API returning the same JSON, e.g.
+//
+//	{"freezes": [{"start": "2026-12-21", "end": "2027-01-03", "reason": "Holiday freeze"}]}
+//
+// Dates without a time cover whole days, both ends included, in Location.
+type MergeWindow struct {
+	// Location is the time zone of weekends and freeze dates, UTC when nil.
+	Location *time.Location
+	// Weekends disallows merging on Saturdays and Sundays.
+	Weekends bool
+	Freezes  []Freeze
+	// FreezeFile and FreezeURL add the freezes of a JSON file or endpoint.
+	FreezeFile string
+	FreezeURL  string
+	HTTPClient *http.Client
+	// Clock defaults to d.
+	Clock Clock
+	// Warn reports violations as warnings instead of failures.
+	Warn bool
+}
+
+// freezeCalendar is the JSON of FreezeFile and FreezeURL.
+type freezeCalendar struct {
+	Freezes []struct {
+		Start  string `json:"start"`
+		End    string `json:"end"`
+		Reason string `json:"reason"`
+	} `json:"freezes"`
+}
+
+// ParseFreezes decodes a JSON freeze calendar. Dates are RFC 3339 times or
+// YYYY-MM-DD days in loc.
+func ParseFreezes(r io.Reader, loc *time.Location) ([]Freeze, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	var c freezeCalendar
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return nil, fmt.Errorf("decoding freeze calendar: %w", err)
+	}
+	var out []Freeze
+	for _, f := range c.Freezes {
+		start, _, err := parseFreezeTime(f.Start, loc)
+		if err != nil {
+			return nil, err
+		}
+		end, day, err := parseFreezeTime(f.End, loc)
+		if err != nil {
+			return nil, err
+		}
+		if day {
+			end = end.AddDate(0, 0, 1)
+		}
+		out = append(out, Freeze{Start: start, End: end, Reason: f.Reason})
+	}
+	return out, nil
+}
+
+// parseFreezeTime parses an RFC 3339 time or a day, reporting which.
+func parseFreezeTime(s string, loc *time.Location) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, s, loc); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid freeze date %q, want YYYY-MM-DD or RFC 3339", s)
+	}
+	return t, false, nil
+}
+
+// LoadFreezes returns Freezes with the ones of FreezeFile and FreezeURL.
+func (w MergeWindow) LoadFreezes(ctx context.Context) ([]Freeze, error) {
+	freezes := append([]Freeze{}, w.Freezes...)
+	if w.FreezeFile != "" {
+		f, err := os.Open(w.FreezeFile)
+		if err != nil {
+			return nil, fmt.Errorf("opening freeze file: %w", err)
+		}
+		defer f.Close()
+		more, err := ParseFreezes(f, w.Location)
+		if err != nil {
+			return nil, err
+		}
+		freezes = append(freezes, more...)
+	}
+	if w.FreezeURL != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.FreezeURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		client := w.HTTPClient
+		if client == nil {
+			client = http.DefaultClient
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("fetching freezes: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetching freezes: %s", resp.Status)
+		}
+		more, err := ParseFreezes(resp.Body, w.Location)
+		if err != nil {
+			return nil, err
+		}
+		freezes = append(freezes, more...)
+	}
+	return freezes, nil
+}
+
+// Blocked returns why merging isn't allowed at now, and false when it is.
+func (w MergeWindow) Blocked(now time.Time, freezes []Freeze) (string, bool) {
+	loc := w.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	now = now.In(loc)
+	for _, f := range freezes {
+		if !now.Before(f.Start) && now.Before(f.End) {
+			reason := f.Reason
+			if reason == "" {
+				reason = "release freeze"
+			}
+			return fmt.Sprintf("Merging is frozen until %s: %s.", f.End.In(loc).Format(windowTimeFormat), reason), true
+		}
+	}
+	if wd := now.Weekday(); w.Weekends && (wd == time.Saturday || wd == time.Sunday) {
+		return fmt.Sprintf("Merging is not allowed on weekends, it is %s. Wait until Monday to merge.", now.Format(windowTimeFormat)), true
+	}
+	return "", false
+}
+
+const windowTimeFormat = "Mon 2 Jan 2006 15:04 MST"
+
+// Run reports the PR when merging isn't allowed now.
+func (w MergeWindow) Run(d *danger.T, pr danger.DSL) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	freezes, err := w.LoadFreezes(ctx)
+	if err != nil {
+		d.Warn(fmt.Sprintf("Merge window: %s", err), "", 0)
+		freezes = w.Freezes
+	}
+	var clock Clock = d
+	if w.Clock != nil {
+		clock = w.Clock
+	}
+	if msg, blocked := w.Blocked(clock.Now(), freezes); blocked {
+		report(d, w.Warn, msg)
+	}
+}
@@ -0,0 +1,96 @@
+package rules
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+)
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+func TestParseFreezes(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.Nil(t, err)
+	freezes, err := ParseFreezes(strings.NewReader(`{"freezes": [
+		{"start": "2026-12-21", "end": "2027-01-03", "reason": "Holiday freeze"},
+		{"start": "2026-11-02T18:00:00Z", "end": "2026-11-03T06:00:00Z"}
+	]}`), berlin)
+	require.Nil(t, err)
+	require.Equal(t, []Freeze{
+		{Start: time.Date(2026, 12, 21, 0, 0, 0, 0, berlin), End: time.Date(2027, 1, 4, 0, 0, 0, 0, berlin), Reason: "Holiday freeze"},
+		{Start: time.Date(2026, 11, 2, 18, 0, 0, 0, time.UTC), End: time.Date(2026, 11, 3, 6, 0, 0, 0, time.UTC)},
+	}, freezes)
+
+	_, err = ParseFreezes(strings.NewReader(`{"freezes": [{"start": "next monday", "end": "2027-01-03"}]}`), nil)
+	require.ErrorContains(t, err, `invalid freeze date "next monday"`)
+}
+
+func TestMergeWindow(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.Nil(t, err)
+	holiday := Freeze{Start: time.Date(2026, 12, 21, 0, 0, 0, 0, berlin), End: time.Date(2027, 1, 4, 0, 0, 0, 0, berlin), Reason: "Holiday freeze"}
+	tests := []struct {
+		name  string
+		now   time.Time
+		rule  MergeWindow
+		fails []danger.Violation
+	}{
+		{name: "weekday", now: time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC), rule: MergeWindow{Location: berlin, Weekends: true}},
+		{
+			name:  "weekend in time zone",
+			now:   time.Date(2026, 10, 16, 22, 30, 0, 0, time.UTC),
+			rule:  MergeWindow{Location: berlin, Weekends: true},
+			fails: []danger.Violation{{Message: "Merging is not allowed on weekends, it is Sat 17 Oct 2026 00:30 CEST. Wait until Monday to merge."}},
+		},
+		{name: "weekends allowed", now: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC), rule: MergeWindow{}},
+		{
+			name:  "freeze",
+			now:   time.Date(2026, 12, 22, 9, 0, 0, 0, time.UTC),
+			rule:  MergeWindow{Location: berlin, Freezes: []Freeze{holiday}},
+			fails: []danger.Violation{{Message: "Merging is frozen until Mon 4 Jan 2027 00:00 CET: Holiday freeze."}},
+		},
+		{
+			name:  "freeze without reason",
+			now:   time.Date(2026, 12, 22, 9, 0, 0, 0, time.UTC),
+			rule:  MergeWindow{Freezes: []Freeze{{Start: holiday.Start, End: holiday.End}}},
+			fails: []danger.Violation{{Message: "Merging is frozen until Sun 3 Jan 2027 23:00 UTC: release freeze."}},
+		},
+		{name: "after freeze", now: time.Date(2027, 1, 4, 9, 0, 0, 0, time.UTC), rule: MergeWindow{Location: berlin, Freezes: []Freeze{holiday}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := danger.New(danger.WithClock(func() time.Time { return tt.now }))
+			tt.rule.Run(d, danger.DSL{})
+			require.ElementsMatch(t, tt.fails, results(t, d).Fails)
+		})
+	}
+}
+
+func TestMergeWindowSources(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "freezes.json")
+	require.Nil(t, os.WriteFile(file, []byte(`{"freezes": [{"start": "2026-10-01", "end": "2026-10-02", "reason": "Migration"}]}`), 0o600))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"freezes": [{"start": "2026-10-16", "end": "2026-10-16", "reason": "Release 2.0"}]}`))
+	}))
+	defer srv.Close()
+
+	rule := MergeWindow{FreezeFile: file, FreezeURL: srv.URL, Clock: fixedClock(time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)), Warn: true}
+	d := danger.New()
+	rule.Run(d, danger.DSL{})
+	require.Equal(t, []danger.Violation{{Message: "Merging is frozen until Sat 17 Oct 2026 00:00 UTC: Release 2.0."}}, results(t, d).Warnings)
+
+	rule.FreezeURL = srv.URL + "/missing\x7f"
+	d = danger.New()
+	rule.Run(d, danger.DSL{})
+	require.Len(t, results(t, d).Warnings, 1)
+}
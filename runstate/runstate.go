@@ -0,0 +1,335 @@
+// Package runstate remembers the state of Danger runs per PR, such as the
+// violations of the previous run, acknowledged violations and baselines, in
+// the git repository itself: in git notes or under a refs/danger namespace.
+// The state needs no external storage and travels with the repository when
+// its refs are pushed.
+package runstate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	danger "github.com/danger/golang"
+)
+
+// State is what a run remembers for the next runs on the same PR.
+type State struct {
+	// Run is when the state was last saved.
+	Run time.Time `json:"run"`
+	// Head is the commit the last run checked.
+	Head string `json:"head,omitempty"`
+	// Results are the violations of the last run.
+	Results danger.Results `json:"results"`
+	// Acknowledged are the IDs of violations a reviewer accepted, which
+	// dangerfiles can stop reporting.
+	Acknowledged []string `json:"acknowledged,omitempty"`
+	// Baselines are values later runs compare with, e.g. a coverage
+	// percentage.
+	Baselines map[string]float64 `json:"baselines,omitempty"`
+	// Waivers are the waivers applied to the failures of the PR, kept as an
+	// audit trail.
+	Waivers []danger.Waiver `json:"waivers,omitempty"`
+}
+
+// AddWaiver records w, unless a waiver of the same rule by the same user was
+// recorded already.
+func (s *State) AddWaiver(w danger.Waiver) {
+	if !slices.ContainsFunc(s.Waivers, func(x danger.Waiver) bool { return x.Rule == w.Rule && x.By == w.By }) {
+		s.Waivers = append(s.Waivers, w)
+	}
+}
+
+// Acknowledge records id as acknowledged.
+func (s *State) Acknowledge(id string) {
+	if !slices.Contains(s.Acknowledged, id) {
+		s.Acknowledged = append(s.Acknowledged, id)
+	}
+}
+
+// IsAcknowledged reports whether id was acknowledged.
+func (s State) IsAcknowledged(id string) bool {
+	return slices.Contains(s.Acknowledged, id)
+}
+
+// SetBaseline records the value of the baseline name.
+func (s *State) SetBaseline(name string, value float64) {
+	if s.Baselines == nil {
+		s.Baselines = map[string]float64{}
+	}
+	s.Baselines[name] = value
+}
+
+// Baseline returns the value of the baseline name, if recorded.
+func (s State) Baseline(name string) (float64, bool) {
+	v, ok := s.Baselines[name]
+	return v, ok
+}
+
+// PreviousComment renders the results as the state marker of a Danger
+// comment, for danger.T.SetPreviousComment when the previous comment can't
+// be read, e.g. on GitLab.
+func (s State) PreviousComment() string {
+	marked, _ := danger.MarkChanges(s.Results, "")
+	return marked.Markdowns[len(marked.Markdowns)-1].Message
+}
+
+// Store loads and saves the state of PRs by key, see Key.
+type Store interface {
+	// Load returns the state of key. The second return value is false when
+	// no state was saved yet.
+	Load(ctx context.Context, key string) (State, bool, error)
+	Save(ctx context.Context, key string, s State) error
+}
+
+var unsafeKeyRe = regexp.MustCompile(`[^A-Za-z0-9._/-]+`)
+
+// Key identifies the PR in a Store, e.g. "github/danger/golang/42". It is
+// empty when the DSL has neither a GitHub PR nor a GitLab MR.
+func Key(pr danger.DSL) string {
+	var key string
+	switch {
+	case pr.GitHub != nil && pr.GitHub.ThisPR().Number != 0:
+		this := pr.GitHub.ThisPR()
+		key = fmt.Sprintf("github/%s/%s/%d", this.Owner, this.Repo, this.Number)
+	case pr.GitLab != nil && pr.GitLab.Metadata().RepoSlug != "":
+		meta := pr.GitLab.Metadata()
+		key = fmt.Sprintf("gitlab/%s/%s", meta.RepoSlug, meta.PullRequestID)
+	default:
+		return ""
+	}
+	return strings.Trim(unsafeKeyRe.ReplaceAllString(key, "-"), "/.")
+}
+
+// Update loads the state of key, applies update to it and saves it, keeping
+// what other writers of the state recorded.
+func Update(ctx context.Context, store Store, key string, update func(*State)) error {
+	s, _, err := store.Load(ctx, key)
+	if err != nil {
+		return err
+	}
+	update(&s)
+	return store.Save(ctx, key, s)
+}
+
+// FromEnv returns the store selected by DANGER_STATE, "notes" or "refs", in
+// the working directory, pushing to and fetching from the remote of
+// DANGER_STATE_REMOTE when set. It returns nil when DANGER_STATE is unset.
+func FromEnv() (Store, error) {
+	remote := os.Getenv("DANGER_STATE_REMOTE")
+	switch mode := os.Getenv("DANGER_STATE"); mode {
+	case "", "false":
+		return nil, nil
+	case "notes":
+		return Notes{Remote: remote}, nil
+	case "refs":
+		return Refs{Remote: remote}, nil
+	default:
+		return nil, fmt.Errorf("DANGER_STATE is %q, expected notes or refs", mode)
+	}
+}
+
+// DefaultNotesRef is the notes ref of Notes.
+const DefaultNotesRef = "refs/notes/danger"
+
+// Notes stores states as git notes. Notes annotate objects, so each PR gets
+// a blob of its key which the note is attached to.
+type Notes struct {
+	// Dir is the repository, the working directory when empty.
+	Dir string
+	// Ref is the notes ref, DefaultNotesRef when empty.
+	Ref string
+	// Remote is fetched before loading and pushed to after saving, e.g.
+	// "origin". Nothing is fetched or pushed when empty.
+	Remote string
+}
+
+func (n Notes) ref() string {
+	if n.Ref == "" {
+		return DefaultNotesRef
+	}
+	return n.Ref
+}
+
+// object writes and returns the blob the note of key is attached to.
+func (n Notes) object(ctx context.Context, key string) (string, error) {
+	return gitInput(ctx, n.Dir, "danger-go state "+key, "hash-object", "-w", "--stdin")
+}
+
+// Load fetches the notes and reads the note of key.
+func (n Notes) Load(ctx context.Context, key string) (State, bool, error) {
+	if err := fetch(ctx, n.Dir, n.Remote, n.ref()); err != nil {
+		return State{}, false, err
+	}
+	obj, err := n.object(ctx, key)
+	if err != nil {
+		return State{}, false, fmt.Errorf("loading state: %w", err)
+	}
+	note, err := git(ctx, n.Dir, "notes", "--ref", n.ref(), "show", obj)
+	if err != nil {
+		if strings.Contains(err.Error(), "no note found") {
+			return State{}, false, nil
+		}
+		return State{}, false, fmt.Errorf("loading state: %w", err)
+	}
+	return decode(note)
+}
+
+// Save replaces the note of key and pushes the notes. When the push is
+// rejected because another run pushed first, the remote notes are fetched
+// and the note is saved on top of them once more.
+func (n Notes) Save(ctx context.Context, key string, s State) error {
+	data, err := encode(s)
+	if err != nil {
+		return err
+	}
+	obj, err := n.object(ctx, key)
+	if err != nil {
+		return fmt.Errorf("saving state: %w", err)
+	}
+	for attempt := 0; ; attempt++ {
+		args := append(identity(ctx, n.Dir), "notes", "--ref", n.ref(), "add", "-f", "-F", "-", obj)
+		if _, err := gitInput(ctx, n.Dir, data, args...); err != nil {
+			return fmt.Errorf("saving state: %w", err)
+		}
+		if n.Remote == "" {
+			return nil
+		}
+		_, err := git(ctx, n.Dir, "push", n.Remote, n.ref()+":"+n.ref())
+		if err == nil {
+			return nil
+		}
+		if attempt > 0 {
+			return fmt.Errorf("pushing state: %w", err)
+		}
+		if err := fetch(ctx, n.Dir, n.Remote, n.ref()); err != nil {
+			return err
+		}
+	}
+}
+
+// DefaultRefsPrefix is the namespace of Refs.
+const DefaultRefsPrefix = "refs/danger"
+
+// Refs stores the state of each PR as a blob referenced by a ref of its own,
+// e.g. refs/danger/github/danger/golang/42. Runs on different PRs never
+// conflict.
+type Refs struct {
+	// Dir is the repository, the working directory when empty.
+	Dir string
+	// Prefix is the namespace of the refs, DefaultRefsPrefix when empty.
+	Prefix string
+	// Remote is fetched before loading and pushed to after saving, e.g.
+	// "origin". Nothing is fetched or pushed when empty.
+	Remote string
+}
+
+func (r Refs) ref(key string) string {
+	prefix := r.Prefix
+	if prefix == "" {
+		prefix = DefaultRefsPrefix
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + key
+}
+
+// Load fetches the ref of key and reads the state it references.
+func (r Refs) Load(ctx context.Context, key string) (State, bool, error) {
+	ref := r.ref(key)
+	if err := fetch(ctx, r.Dir, r.Remote, ref); err != nil {
+		return State{}, false, err
+	}
+	if _, err := git(ctx, r.Dir, "rev-parse", "--verify", "--quiet", ref); err != nil {
+		return State{}, false, nil
+	}
+	data, err := git(ctx, r.Dir, "cat-file", "blob", ref)
+	if err != nil {
+		return State{}, false, fmt.Errorf("loading state: %w", err)
+	}
+	return decode(data)
+}
+
+// Save points the ref of key to the state and pushes it.
+func (r Refs) Save(ctx context.Context, key string, s State) error {
+	data, err := encode(s)
+	if err != nil {
+		return err
+	}
+	blob, err := gitInput(ctx, r.Dir, data, "hash-object", "-w", "--stdin")
+	if err != nil {
+		return fmt.Errorf("saving state: %w", err)
+	}
+	ref := r.ref(key)
+	if _, err := git(ctx, r.Dir, "update-ref", ref, blob); err != nil {
+		return fmt.Errorf("saving state: %w", err)
+	}
+	if r.Remote == "" {
+		return nil
+	}
+	if _, err := git(ctx, r.Dir, "push", r.Remote, "+"+ref+":"+ref); err != nil {
+		return fmt.Errorf("pushing state: %w", err)
+	}
+	return nil
+}
+
+func encode(s State) (string, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", fmt.Errorf("encoding state: %w", err)
+	}
+	return string(data), nil
+}
+
+func decode(data string) (State, bool, error) {
+	var s State
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		return State{}, false, fmt.Errorf("decoding state: %w", err)
+	}
+	return s, true, nil
+}
+
+// fetch updates ref from remote, if any. A ref missing from the remote is
+// not an error: no run saved a state yet.
+func fetch(ctx context.Context, dir, remote, ref string) error {
+	if remote == "" {
+		return nil
+	}
+	_, err := git(ctx, dir, "fetch", "--quiet", remote, "+"+ref+":"+ref)
+	if err != nil && !strings.Contains(err.Error(), "couldn't find remote ref") {
+		return fmt.Errorf("fetching state: %w", err)
+	}
+	return nil
+}
+
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	return gitInput(ctx, dir, "", args...)
+}
+
+// gitInput runs git in dir with input on stdin and returns its trimmed
+// stdout.
+func gitInput(ctx context.Context, dir, input string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// identity returns the options committing notes as danger-go when git has no
+// identity configured, as on CI runners.
+func identity(ctx context.Context, dir string) []string {
+	if _, err := git(ctx, dir, "var", "GIT_COMMITTER_IDENT"); err == nil {
+		return nil
+	}
+	return []string{"-c", "user.name=danger-go", "-c", "user.email=danger-go@users.noreply.github.com"}
+}
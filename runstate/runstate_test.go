@@ -0,0 +1,106 @@
+package runstate
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+)
+
+func run(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.Nil(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+// newRepo creates a repository sharing the bare repository remote as origin.
+func newRepo(t *testing.T, remote string) string {
+	t.Helper()
+	dir := t.TempDir()
+	run(t, dir, "init", "-q")
+	run(t, dir, "remote", "add", "origin", remote)
+	return dir
+}
+
+func TestStores(t *testing.T) {
+	tests := []struct {
+		name  string
+		store func(dir string) Store
+	}{
+		{name: "notes", store: func(dir string) Store { return Notes{Dir: dir, Remote: "origin"} }},
+		{name: "refs", store: func(dir string) Store { return Refs{Dir: dir, Remote: "origin"} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			remote := t.TempDir()
+			run(t, remote, "init", "-q", "--bare")
+			first, second := tt.store(newRepo(t, remote)), tt.store(newRepo(t, remote))
+
+			_, ok, err := first.Load(ctx, "github/danger/golang/1")
+			require.Nil(t, err)
+			require.False(t, ok)
+
+			saved := State{
+				Run:     time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+				Head:    "abc123",
+				Results: danger.Results{Warnings: []danger.Violation{{Message: "Trailing whitespace", File: "a.go", Line: 3}}},
+			}
+			saved.Acknowledge("coverage-drop")
+			saved.SetBaseline("coverage", 81.5)
+			require.Nil(t, first.Save(ctx, "github/danger/golang/1", saved))
+
+			// another run, e.g. on another runner, finds the state through the
+			// remote and records a second PR concurrently
+			got, ok, err := second.Load(ctx, "github/danger/golang/1")
+			require.Nil(t, err)
+			require.True(t, ok)
+			require.Equal(t, saved, got)
+			require.Nil(t, Update(ctx, first, "github/danger/golang/2", func(s *State) { s.Head = "def456" }))
+			require.Nil(t, Update(ctx, second, "github/danger/golang/1", func(s *State) { s.Head = "fed654" }))
+
+			got, _, err = first.Load(ctx, "github/danger/golang/1")
+			require.Nil(t, err)
+			require.Equal(t, "fed654", got.Head)
+			require.True(t, got.IsAcknowledged("coverage-drop"))
+			baseline, ok := got.Baseline("coverage")
+			require.True(t, ok)
+			require.Equal(t, 81.5, baseline)
+			got, _, err = second.Load(ctx, "github/danger/golang/2")
+			require.Nil(t, err)
+			require.Equal(t, "def456", got.Head)
+		})
+	}
+}
+
+func TestPreviousComment(t *testing.T) {
+	r := danger.Results{Warnings: []danger.Violation{{Message: "Trailing whitespace", File: "a.go"}}}
+	marked, unchanged := danger.MarkChanges(r, State{Results: r}.PreviousComment())
+	require.True(t, unchanged)
+	require.Equal(t, "Trailing whitespace", marked.Warnings[0].Message)
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("DANGER_STATE", "")
+	s, err := FromEnv()
+	require.Nil(t, err)
+	require.Nil(t, s)
+
+	t.Setenv("DANGER_STATE", "refs")
+	t.Setenv("DANGER_STATE_REMOTE", "origin")
+	s, err = FromEnv()
+	require.Nil(t, err)
+	require.Equal(t, Refs{Remote: "origin"}, s)
+
+	t.Setenv("DANGER_STATE", "s3")
+	_, err = FromEnv()
+	require.NotNil(t, err)
+}
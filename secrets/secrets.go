@@ -0,0 +1,151 @@
+// Package secrets looks up the tokens danger-go needs from a secret store, so
+// deployments don't have to keep long-lived tokens in environment variables.
+// Secrets are named after the variables they replace, e.g.
+// DANGER_GITHUB_API_TOKEN.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/danger/golang/artifacts"
+)
+
+// ProviderEnv selects the Provider of FromEnv: "env", "file", "vault", "aws"
+// or "gcp".
+const ProviderEnv = "DANGER_SECRETS_PROVIDER"
+
+// ErrNotFound is returned by providers for secrets they don't hold.
+var ErrNotFound = errors.New("secret not found")
+
+// Provider looks up secrets by name.
+type Provider interface {
+	Get(ctx context.Context, name string) (string, error)
+}
+
+// Env looks up secrets in the environment.
+type Env struct{}
+
+// Get returns the variable name.
+func (Env) Get(_ context.Context, name string) (string, error) {
+	if v := os.Getenv(name); v != "" {
+		return v, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// File looks up secrets in the files of a directory, such as a mounted
+// Kubernetes secret, trimming surrounding whitespace.
+type File struct {
+	Dir string
+}
+
+// Get returns the content of the file name in Dir.
+func (f File) Get(_ context.Context, name string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(f.Dir, filepath.Base(name)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading secret %s: %w", name, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Chain looks up secrets in each provider in turn.
+type Chain []Provider
+
+// Get returns the secret of the first provider holding it.
+func (c Chain) Get(ctx context.Context, name string) (string, error) {
+	for _, p := range c {
+		v, err := p.Get(ctx, name)
+		if !errors.Is(err, ErrNotFound) {
+			return v, err
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// Load sets the variables of names which aren't set from the provider, so
+// danger-go and the danger-js process it starts read them as usual. Secrets
+// the provider doesn't hold are skipped.
+func Load(ctx context.Context, p Provider, names ...string) error {
+	for _, name := range names {
+		if os.Getenv(name) != "" {
+			continue
+		}
+		v, err := p.Get(ctx, name)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("loading %s: %w", name, err)
+		}
+		if err := os.Setenv(name, v); err != nil {
+			return fmt.Errorf("setting %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// FromEnv returns the provider selected by DANGER_SECRETS_PROVIDER, Env when
+// unset, configured from the environment:
+//
+//   - file: DANGER_SECRETS_DIR
+//   - vault: VAULT_ADDR, VAULT_TOKEN and DANGER_SECRETS_VAULT_PATH, the KV v2
+//     secret holding the tokens, with DANGER_SECRETS_VAULT_MOUNT defaulting
+//     to "secret"
+//   - aws: DANGER_SECRETS_AWS_SECRET_ID, a JSON secret holding the tokens, and
+//     the standard AWS region and credential variables
+//   - gcp: DANGER_SECRETS_GCP_PROJECT, authenticated with
+//     GOOGLE_OAUTH_ACCESS_TOKEN or the metadata server
+func FromEnv() (Provider, error) {
+	switch name := os.Getenv(ProviderEnv); name {
+	case "", "env":
+		return Env{}, nil
+	case "file":
+		dir := os.Getenv("DANGER_SECRETS_DIR")
+		if dir == "" {
+			return nil, errors.New("DANGER_SECRETS_DIR is required by the file secrets provider")
+		}
+		return File{Dir: dir}, nil
+	case "vault":
+		v := &Vault{
+			Addr:  os.Getenv("VAULT_ADDR"),
+			Token: os.Getenv("VAULT_TOKEN"),
+			Mount: os.Getenv("DANGER_SECRETS_VAULT_MOUNT"),
+			Path:  os.Getenv("DANGER_SECRETS_VAULT_PATH"),
+		}
+		if v.Addr == "" || v.Token == "" || v.Path == "" {
+			return nil, errors.New("VAULT_ADDR, VAULT_TOKEN and DANGER_SECRETS_VAULT_PATH are required by the vault secrets provider")
+		}
+		return v, nil
+	case "aws":
+		s3 := artifacts.S3FromEnv("")
+		a := &AWS{
+			Region:      s3.Region,
+			SecretID:    os.Getenv("DANGER_SECRETS_AWS_SECRET_ID"),
+			Credentials: s3.Credentials,
+		}
+		if a.Region == "" || a.SecretID == "" {
+			return nil, errors.New("AWS_REGION and DANGER_SECRETS_AWS_SECRET_ID are required by the aws secrets provider")
+		}
+		return a, nil
+	case "gcp":
+		g := GCP{
+			Project:     os.Getenv("DANGER_SECRETS_GCP_PROJECT"),
+			AccessToken: os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"),
+		}
+		if g.Project == "" {
+			return nil, errors.New("DANGER_SECRETS_GCP_PROJECT is required by the gcp secrets provider")
+		}
+		return g, nil
+	default:
+		return nil, fmt.Errorf("unknown secrets provider %q", name)
+	}
+}
@@ -0,0 +1,187 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danger/golang/artifacts"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "DANGER_GITHUB_API_TOKEN"), []byte("ghp_123\n"), 0o600))
+	f := File{Dir: dir}
+
+	v, err := f.Get(context.Background(), "DANGER_GITHUB_API_TOKEN")
+	require.Nil(t, err)
+	require.Equal(t, "ghp_123", v)
+
+	_, err = f.Get(context.Background(), "OPENAI_API_KEY")
+	require.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestChain(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "FROM_FILE"), []byte("file"), 0o600))
+	t.Setenv("FROM_ENV", "env")
+	c := Chain{Env{}, File{Dir: dir}}
+
+	tests := []struct {
+		name     string
+		want     string
+		notFound bool
+	}{
+		{name: "FROM_ENV", want: "env"},
+		{name: "FROM_FILE", want: "file"},
+		{name: "MISSING", notFound: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := c.Get(context.Background(), tt.name)
+			require.Equal(t, tt.notFound, errors.Is(err, ErrNotFound))
+			require.Equal(t, tt.want, v)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "TOKEN_A"), []byte("stored-a"), 0o600))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "TOKEN_B"), []byte("stored-b"), 0o600))
+	t.Setenv("TOKEN_A", "")
+	t.Setenv("TOKEN_B", "literal")
+	t.Setenv("TOKEN_C", "")
+
+	require.Nil(t, Load(context.Background(), File{Dir: dir}, "TOKEN_A", "TOKEN_B", "TOKEN_C"))
+	require.Equal(t, "stored-a", os.Getenv("TOKEN_A"))
+	require.Equal(t, "literal", os.Getenv("TOKEN_B"))
+	require.Equal(t, "", os.Getenv("TOKEN_C"))
+}
+
+func TestVault(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		require.Equal(t, "/v1/kv/data/ci/danger", r.URL.Path)
+		require.Equal(t, "vault-token", r.Header.Get("X-Vault-Token"))
+		_, _ = io.WriteString(w, `{"data":{"data":{"DANGER_GITHUB_API_TOKEN":"ghp_123"},"metadata":{"version":3}}}`)
+	}))
+	defer srv.Close()
+	v := &Vault{Addr: srv.URL, Token: "vault-token", Mount: "kv", Path: "ci/danger"}
+
+	got, err := v.Get(context.Background(), "DANGER_GITHUB_API_TOKEN")
+	require.Nil(t, err)
+	require.Equal(t, "ghp_123", got)
+	_, err = v.Get(context.Background(), "OPENAI_API_KEY")
+	require.True(t, errors.Is(err, ErrNotFound))
+	require.Equal(t, 1, requests)
+}
+
+func TestVaultError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+	}))
+	defer srv.Close()
+	v := &Vault{Addr: srv.URL, Token: "vault-token", Path: "ci/danger"}
+
+	_, err := v.Get(context.Background(), "DANGER_GITHUB_API_TOKEN")
+	require.ErrorContains(t, err, "reading Vault secret ci/danger: 403 Forbidden")
+	require.False(t, errors.Is(err, ErrNotFound))
+}
+
+func TestAWS(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		require.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		require.Contains(t, r.Header.Get("Authorization"), "/eu-west-1/secretsmanager/aws4_request")
+		var body map[string]string
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, "ci/danger", body["SecretId"])
+		_, _ = io.WriteString(w, `{"Name":"ci/danger","SecretString":"{\"DANGER_GITLAB_API_TOKEN\":\"glpat-123\"}"}`)
+	}))
+	defer srv.Close()
+	a := &AWS{
+		Region:      "eu-west-1",
+		SecretID:    "ci/danger",
+		Credentials: artifacts.S3Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		Endpoint:    srv.URL,
+	}
+
+	got, err := a.Get(context.Background(), "DANGER_GITLAB_API_TOKEN")
+	require.Nil(t, err)
+	require.Equal(t, "glpat-123", got)
+	_, err = a.Get(context.Background(), "DANGER_GITHUB_API_TOKEN")
+	require.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestGCP(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /token", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+		_, _ = io.WriteString(w, `{"access_token":"ya29.token","expires_in":3599,"token_type":"Bearer"}`)
+	})
+	mux.HandleFunc("GET /v1/projects/my-project/secrets/{name}/versions/latest:access", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer ya29.token", r.Header.Get("Authorization"))
+		if r.PathValue("name") != "DANGER_GITHUB_API_TOKEN" {
+			http.Error(w, `{"error":{"code":404}}`, http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"name":    "projects/my-project/secrets/DANGER_GITHUB_API_TOKEN/versions/2",
+			"payload": map[string]string{"data": base64.StdEncoding.EncodeToString([]byte("ghp_123"))},
+		})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	g := GCP{Project: "my-project", Endpoint: srv.URL, MetadataURL: srv.URL + "/token"}
+
+	got, err := g.Get(context.Background(), "DANGER_GITHUB_API_TOKEN")
+	require.Nil(t, err)
+	require.Equal(t, "ghp_123", got)
+	_, err = g.Get(context.Background(), "OPENAI_API_KEY")
+	require.True(t, errors.Is(err, ErrNotFound))
+}
+
+func TestFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    Provider
+		wantErr string
+	}{
+		{name: "default", want: Env{}},
+		{name: "file", env: map[string]string{ProviderEnv: "file", "DANGER_SECRETS_DIR": "/run/secrets"}, want: File{Dir: "/run/secrets"}},
+		{name: "file without dir", env: map[string]string{ProviderEnv: "file"}, wantErr: "DANGER_SECRETS_DIR is required"},
+		{name: "vault without token", env: map[string]string{ProviderEnv: "vault", "VAULT_ADDR": "https://vault:8200"}, wantErr: "VAULT_TOKEN"},
+		{name: "gcp", env: map[string]string{ProviderEnv: "gcp", "DANGER_SECRETS_GCP_PROJECT": "p"}, want: GCP{Project: "p"}},
+		{name: "unknown", env: map[string]string{ProviderEnv: "keychain"}, wantErr: `unknown secrets provider "keychain"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, k := range []string{ProviderEnv, "DANGER_SECRETS_DIR", "VAULT_ADDR", "VAULT_TOKEN", "DANGER_SECRETS_VAULT_PATH", "DANGER_SECRETS_GCP_PROJECT", "GOOGLE_OAUTH_ACCESS_TOKEN"} {
+				t.Setenv(k, "")
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			p, err := FromEnv()
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, p)
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danger/golang/artifacts"
+)
+
+// Vault looks up secrets in the keys of a KV version 2 secret of HashiCorp
+// Vault, read once.
+type Vault struct {
+	// Addr is the address of the Vault server, e.g. https://vault:8200.
+	Addr  string
+	Token string
+	// Mount is the mount path of the KV engine, "secret" when empty.
+	Mount string
+	// Path is the path of the secret in the engine, e.g. "ci/danger".
+	Path       string
+	HTTPClient *http.Client
+
+	once sync.Once
+	data map[string]string
+	err  error
+}
+
+// Get returns the key name of the secret.
+func (v *Vault) Get(ctx context.Context, name string) (string, error) {
+	v.once.Do(func() {
+		mount := v.Mount
+		if mount == "" {
+			mount = "secret"
+		}
+		u := strings.TrimSuffix(v.Addr, "/") + "/v1/" + strings.Trim(mount, "/") + "/data/" + strings.Trim(v.Path, "/")
+		var body struct {
+			Data struct {
+				Data map[string]string `json:"data"`
+			} `json:"data"`
+		}
+		v.err = getJSON(ctx, v.HTTPClient, u, map[string]string{"X-Vault-Token": v.Token}, &body)
+		if v.err != nil {
+			v.err = fmt.Errorf("reading Vault secret %s: %w", v.Path, v.err)
+		}
+		v.data = body.Data.Data
+	})
+	return lookup(v.data, v.err, name)
+}
+
+// AWS looks up secrets in the keys of a JSON secret of AWS Secrets Manager,
+// read once.
+type AWS struct {
+	Region string
+	// SecretID is the name or ARN of the secret.
+	SecretID    string
+	Credentials artifacts.S3Credentials
+	// Endpoint overrides the regional Secrets Manager endpoint.
+	Endpoint   string
+	HTTPClient *http.Client
+
+	once sync.Once
+	data map[string]string
+	err  error
+}
+
+// Get returns the key name of the secret.
+func (a *AWS) Get(ctx context.Context, name string) (string, error) {
+	a.once.Do(func() {
+		a.data, a.err = a.fetch(ctx)
+		if a.err != nil {
+			a.err = fmt.Errorf("reading AWS secret %s: %w", a.SecretID, a.err)
+		}
+	})
+	return lookup(a.data, a.err, name)
+}
+
+func (a *AWS) fetch(ctx context.Context) (map[string]string, error) {
+	endpoint := a.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com/", a.Region)
+	}
+	payload, err := json.Marshal(map[string]string{"SecretId": a.SecretID})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	sum := sha256.Sum256(payload)
+	artifacts.SignV4(req, hex.EncodeToString(sum[:]), a.Credentials, a.Region, "secretsmanager", time.Now())
+
+	var out struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := doJSON(client(a.HTTPClient), req, &out); err != nil {
+		return nil, err
+	}
+	var data map[string]string
+	if err := json.Unmarshal([]byte(out.SecretString), &data); err != nil {
+		return nil, fmt.Errorf("decoding secret string: %w", err)
+	}
+	return data, nil
+}
+
+// defaultMetadataURL is the token endpoint of the GCE metadata server.
+const defaultMetadataURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// GCP looks up secrets of GCP Secret Manager named after the secret, reading
+// their latest version.
+type GCP struct {
+	Project string
+	// AccessToken is an OAuth token. When empty a token of the attached
+	// service account is requested from the metadata server.
+	AccessToken string
+	// Endpoint overrides https://secretmanager.googleapis.com.
+	Endpoint string
+	// MetadataURL overrides the token endpoint of the metadata server.
+	MetadataURL string
+	HTTPClient  *http.Client
+}
+
+// Get returns the latest version of the secret name.
+func (g GCP) Get(ctx context.Context, name string) (string, error) {
+	token := g.AccessToken
+	if token == "" {
+		metadataURL := g.MetadataURL
+		if metadataURL == "" {
+			metadataURL = defaultMetadataURL
+		}
+		var t struct {
+			AccessToken string `json:"access_token"`
+		}
+		if err := getJSON(ctx, g.HTTPClient, metadataURL, map[string]string{"Metadata-Flavor": "Google"}, &t); err != nil {
+			return "", fmt.Errorf("requesting GCP access token: %w", err)
+		}
+		token = t.AccessToken
+	}
+
+	endpoint := g.Endpoint
+	if endpoint == "" {
+		endpoint = "https://secretmanager.googleapis.com"
+	}
+	u := fmt.Sprintf("%s/v1/projects/%s/secrets/%s/versions/latest:access",
+		strings.TrimSuffix(endpoint, "/"), url.PathEscape(g.Project), url.PathEscape(name))
+	var out struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	err := getJSON(ctx, g.HTTPClient, u, map[string]string{"Authorization": "Bearer " + token}, &out)
+	if err != nil {
+		var se statusError
+		if errors.As(err, &se) && se.code == http.StatusNotFound {
+			return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+		}
+		return "", fmt.Errorf("reading GCP secret %s: %w", name, err)
+	}
+	data, err := base64.StdEncoding.DecodeString(out.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("decoding GCP secret %s: %w", name, err)
+	}
+	return string(data), nil
+}
+
+func lookup(data map[string]string, err error, name string) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	if v, ok := data[name]; ok && v != "" {
+		return v, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+}
+
+// statusError is an unsuccessful response.
+type statusError struct {
+	code int
+	msg  string
+}
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.code, http.StatusText(e.code), e.msg)
+}
+
+func client(c *http.Client) *http.Client {
+	if c == nil {
+		return http.DefaultClient
+	}
+	return c
+}
+
+func getJSON(ctx context.Context, c *http.Client, u string, header map[string]string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	return doJSON(client(c), req, out)
+}
+
+func doJSON(c *http.Client, req *http.Request, out any) error {
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return statusError{code: resp.StatusCode, msg: strings.TrimSpace(string(body))}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package danger
+
+import dangerJs "github.com/danger/golang/danger-js"
+
+// The Settings interface of the DSL only has the GitHub settings. The
+// functions below return the settings of the other platforms. They use the
+// GitLabAccessToken, GitLabBaseURL, BitbucketCloud or BitbucketServer method
+// of the settings when it has one, as the settings danger-js sends and
+// fakedsl.Settings do, and fall back to the environment variables danger-js
+// reads otherwise.
+
+// BitbucketCloudCredentials are the credentials of Bitbucket Cloud.
+type BitbucketCloudCredentials = dangerJs.BitbucketCloudCredentials
+
+// BitbucketServerCredentials are the credentials and instance of Bitbucket
+// Server.
+type BitbucketServerCredentials = dangerJs.BitbucketServerCredentials
+
+// GitLabAccessToken returns the GitLab token of s, DANGER_GITLAB_API_TOKEN by
+// default.
+func GitLabAccessToken(s dangerJs.Settings) string {
+	return dangerJs.GitLabAccessToken(s)
+}
+
+// GitLabBaseURL returns the GitLab instance of s, DANGER_GITLAB_HOST or the
+// CI_SERVER_URL of GitLab CI by default. Empty means gitlab.com.
+func GitLabBaseURL(s dangerJs.Settings) string {
+	return dangerJs.GitLabBaseURL(s)
+}
+
+// BitbucketCloud returns the Bitbucket Cloud credentials of s, each taken
+// from the DANGER_BITBUCKETCLOUD_* variables by default.
+func BitbucketCloud(s dangerJs.Settings) BitbucketCloudCredentials {
+	return dangerJs.BitbucketCloud(s)
+}
+
+// BitbucketServer returns the Bitbucket Server credentials of s, each taken
+// from the DANGER_BITBUCKETSERVER_* variables by default.
+func BitbucketServer(s dangerJs.Settings) BitbucketServerCredentials {
+	return dangerJs.BitbucketServer(s)
+}
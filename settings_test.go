@@ -0,0 +1,36 @@
+package danger_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/fakedsl"
+)
+
+func TestSettings(t *testing.T) {
+	t.Setenv("DANGER_GITLAB_API_TOKEN", "env-token")
+	t.Setenv("DANGER_GITLAB_HOST", "gitlab.internal")
+	t.Setenv("DANGER_BITBUCKETCLOUD_USERNAME", "env-user")
+	t.Setenv("DANGER_BITBUCKETSERVER_TOKEN", "env-bb-token")
+
+	s := fakedsl.Settings{
+		GitLabToken:         "dsl-token",
+		GitLabURL:           "https://gitlab.example.com",
+		BitbucketCloudData:  danger.BitbucketCloudCredentials{Username: "dsl-user"},
+		BitbucketServerData: danger.BitbucketServerCredentials{Token: "dsl-bb-token"},
+	}
+	require.Equal(t, "dsl-token", danger.GitLabAccessToken(s))
+	require.Equal(t, "https://gitlab.example.com", danger.GitLabBaseURL(s))
+	require.Equal(t, "dsl-user", danger.BitbucketCloud(s).Username)
+	require.Equal(t, "dsl-bb-token", danger.BitbucketServer(s).Token)
+
+	// settings without the methods fall back to the environment
+	type bareSettings struct{ dangerJs.Settings }
+	require.Equal(t, "env-token", danger.GitLabAccessToken(bareSettings{}))
+	require.Equal(t, "gitlab.internal", danger.GitLabBaseURL(bareSettings{}))
+	require.Equal(t, "env-user", danger.BitbucketCloud(bareSettings{}).Username)
+	require.Equal(t, "env-bb-token", danger.BitbucketServer(bareSettings{}).Token)
+}
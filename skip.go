@@ -0,0 +1,81 @@
+package danger
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// SkipTokens skip the Danger run when found in the PR title or the message of
+// the latest commit, compared case insensitively.
+var SkipTokens = []string{"[skip danger]", "[danger skip]"}
+
+// SkipLabels skip the Danger run when the PR has one of them.
+var SkipLabels = []string{"skip-danger", "skip danger"}
+
+// SkipCheck decides whether a PR may skip the Danger run.
+type SkipCheck struct {
+	// Protected are the paths, in dangerJs.MatchPath syntax, which can't be
+	// changed without running Danger: skip requests of PRs touching them are
+	// ignored.
+	Protected []string
+}
+
+// SkipRequest returns where the PR asks to skip Danger, e.g. "the PR title",
+// and whether it does.
+func SkipRequest(pr DSL) (string, bool) {
+	if title := prTitle(pr); hasSkipToken(title) {
+		return "the PR title", true
+	}
+	for _, l := range PRLabels(pr) {
+		if slices.ContainsFunc(SkipLabels, func(s string) bool { return strings.EqualFold(s, l) }) {
+			return fmt.Sprintf("the `%s` label", l), true
+		}
+	}
+	// the commits are checked last, and only the latest is decoded
+	if pr.Git != nil {
+		if c, ok := dangerJs.LastCommit(pr.Git); ok && hasSkipToken(c.Message) {
+			return "the latest commit message", true
+		}
+	}
+	return "", false
+}
+
+func hasSkipToken(s string) bool {
+	s = strings.ToLower(s)
+	return slices.ContainsFunc(SkipTokens, func(t string) bool { return strings.Contains(s, t) })
+}
+
+func prTitle(pr DSL) string {
+	if pr.GitHub != nil && pr.GitHub.PR().Number != 0 {
+		return pr.GitHub.PR().Title
+	}
+	if pr.GitLab != nil {
+		return pr.GitLab.MR().Title
+	}
+	return ""
+}
+
+// Check returns whether the run is skipped and why: the reason explains the
+// skip, or why the skip request was refused because the PR changes
+// protected paths. The reason is empty when no skip was requested.
+func (c SkipCheck) Check(pr DSL) (skip bool, reason string) {
+	where, ok := SkipRequest(pr)
+	if !ok {
+		return false, ""
+	}
+	if pr.Git != nil && len(c.Protected) > 0 {
+		for _, list := range [][]string{pr.Git.CreatedFiles(), pr.Git.ModifiedFiles(), pr.Git.DeletedFiles()} {
+			for _, f := range list {
+				for _, p := range c.Protected {
+					if dangerJs.MatchPath(p, f) {
+						return false, fmt.Sprintf("Danger ran despite the skip request in %s because the PR changes the protected path `%s`.", where, f)
+					}
+				}
+			}
+		}
+	}
+	return true, fmt.Sprintf("Danger was skipped as requested in %s.", where)
+}
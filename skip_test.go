@@ -0,0 +1,87 @@
+package danger_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+func TestSkipCheck(t *testing.T) {
+	dsl := func(title, commit, label string) danger.DSL {
+		data, err := dangerJs.DecodeDSL([]byte(`{
+			"git": {"modified_files": ["deploy/prod.yaml", "README.md"], "commits": [{"message": "first"}, {"message": "` + commit + `"}]},
+			"github": {"pr": {"number": 1, "title": "` + title + `"}, "issue": {"labels": [{"name": "` + label + `"}]}}
+		}`))
+		require.Nil(t, err)
+		return data.ToInterface()
+	}
+	tests := []struct {
+		name       string
+		pr         danger.DSL
+		protected  []string
+		wantSkip   bool
+		wantReason string
+	}{
+		{name: "no request", pr: dsl("Fix typo", "fix", "docs")},
+		{
+			name: "title", pr: dsl("Fix typo [Skip Danger]", "fix", ""),
+			wantSkip: true, wantReason: "Danger was skipped as requested in the PR title.",
+		},
+		{
+			name: "latest commit", pr: dsl("Fix typo", `fix\n\n[danger skip]`, ""),
+			wantSkip: true, wantReason: "Danger was skipped as requested in the latest commit message.",
+		},
+		{
+			name: "label", pr: dsl("Fix typo", "fix", "skip-danger"),
+			wantSkip: true, wantReason: "Danger was skipped as requested in the `skip-danger` label.",
+		},
+		{
+			name: "protected path", pr: dsl("Fix typo [skip danger]", "fix", ""), protected: []string{"deploy/**"},
+			wantReason: "Danger ran despite the skip request in the PR title because the PR changes the protected path `deploy/prod.yaml`.",
+		},
+		{
+			name: "other protected path", pr: dsl("Fix typo [skip danger]", "fix", ""), protected: []string{"go.mod"},
+			wantSkip: true, wantReason: "Danger was skipped as requested in the PR title.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skip, reason := danger.SkipCheck{Protected: tt.protected}.Check(tt.pr)
+			require.Equal(t, tt.wantSkip, skip)
+			require.Equal(t, tt.wantReason, reason)
+		})
+	}
+}
+
+// commitsGit counts the calls listing the commits.
+type commitsGit struct {
+	dangerJs.Git
+	calls *int
+}
+
+func (g commitsGit) Commits() []dangerJs.GitCommit {
+	*g.calls++
+	return []dangerJs.GitCommit{{Message: "[skip danger]"}}
+}
+
+func TestSkipRequestChecksCommitsLast(t *testing.T) {
+	var calls int
+	data, err := dangerJs.DecodeDSL([]byte(`{"git": {}, "github": {"pr": {"number": 1, "title": "Fix typo"}, "issue": {"labels": [{"name": "skip danger"}]}}}`))
+	require.Nil(t, err)
+	pr := data.ToInterface()
+	pr.Git = commitsGit{Git: pr.Git, calls: &calls}
+
+	where, ok := danger.SkipRequest(pr)
+	require.True(t, ok)
+	require.Equal(t, "the `skip danger` label", where)
+	require.Zero(t, calls)
+
+	pr.GitHub = nil
+	where, ok = danger.SkipRequest(pr)
+	require.True(t, ok)
+	require.Equal(t, "the latest commit message", where)
+	require.Equal(t, 1, calls)
+}
@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/danger/golang/internal/otlp"
+)
+
+// OTLPExporter sends spans to an OpenTelemetry collector using OTLP over
+// HTTP with JSON encoding.
+type OTLPExporter struct {
+	// Endpoint is the full traces URL, e.g. http://collector:4318/v1/traces
+	Endpoint    string
+	Headers     map[string]string
+	ServiceName string
+	Client      *http.Client
+}
+
+// OTLP status codes and span kinds
+const (
+	otlpStatusError  = 2
+	otlpKindInternal = 1
+)
+
+// ExportSpans sends the spans in a single request.
+func (o *OTLPExporter) ExportSpans(ctx context.Context, spans []SpanData) error {
+	return otlp.Post(ctx, o.Client, o.Endpoint, o.Headers, "spans", o.payload(spans))
+}
+
+func (o *OTLPExporter) payload(spans []SpanData) map[string]any {
+	service := o.ServiceName
+	if service == "" {
+		service = "danger-go"
+	}
+	otlpSpans := make([]map[string]any, 0, len(spans))
+	for _, s := range spans {
+		span := map[string]any{
+			"traceId":           s.TraceID,
+			"spanId":            s.SpanID,
+			"name":              s.Name,
+			"kind":              otlpKindInternal,
+			"startTimeUnixNano": strconv.FormatInt(s.Start.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(s.End.UnixNano(), 10),
+			"attributes":        otlp.Attributes(s.Attrs),
+		}
+		if s.ParentID != "" {
+			span["parentSpanId"] = s.ParentID
+		}
+		if s.Err != nil {
+			span["status"] = map[string]any{"code": otlpStatusError, "message": s.Err.Error()}
+		}
+		otlpSpans = append(otlpSpans, span)
+	}
+	return map[string]any{
+		"resourceSpans": []map[string]any{{
+			"resource": map[string]any{"attributes": []otlp.Attribute{otlp.String("service.name", service)}},
+			"scopeSpans": []map[string]any{{
+				"scope": map[string]any{"name": "github.com/danger/golang/tracing"},
+				"spans": otlpSpans,
+			}},
+		}},
+	}
+}
@@ -0,0 +1,254 @@
+// Package tracing records OpenTelemetry-compatible spans for the phases of a
+// danger run (building the dangerfile, git commands, API calls and rules) and
+// exports them with OTLP over HTTP. Tracing is disabled until Init is called
+// with an endpoint configured, in which case spans cost almost nothing.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danger/golang/internal/otlp"
+)
+
+// Span is a timed operation.
+type Span struct {
+	tracer   *Tracer
+	name     string
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	start    time.Time
+	end      time.Time
+	attrs    map[string]string
+	err      error
+	ended    bool
+}
+
+// SetAttr records an attribute on the span.
+func (s *Span) SetAttr(key, value string) {
+	if s == nil || s.tracer == nil {
+		return
+	}
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.attrs[key] = value
+}
+
+// SetError marks the span as failed when err is not nil.
+func (s *Span) SetError(err error) {
+	if s == nil || s.tracer == nil || err == nil {
+		return
+	}
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.err = err
+}
+
+// End finishes the span. Calling End more than once has no effect.
+func (s *Span) End() {
+	if s == nil || s.tracer == nil {
+		return
+	}
+	s.tracer.finish(s)
+}
+
+// TraceID returns the hex encoded trace ID, or an empty string when tracing
+// is disabled.
+func (s *Span) TraceID() string {
+	if s == nil || s.tracer == nil {
+		return ""
+	}
+	return hex.EncodeToString(s.traceID[:])
+}
+
+// Exporter sends finished spans to a backend.
+type Exporter interface {
+	ExportSpans(ctx context.Context, spans []SpanData) error
+}
+
+// SpanData is a finished span.
+type SpanData struct {
+	Name     string
+	TraceID  string
+	SpanID   string
+	ParentID string
+	Start    time.Time
+	End      time.Time
+	Attrs    map[string]string
+	Err      error
+}
+
+// Tracer creates spans and buffers them until Flush.
+type Tracer struct {
+	exporter Exporter
+
+	mu       sync.Mutex
+	finished []SpanData
+	// fallback parents spans started without a span in their context, so
+	// call sites without a context still nest under the run.
+	fallback *Span
+	// remote is the parent propagated through TRACEPARENT, if any.
+	remoteTrace  [16]byte
+	remoteParent [8]byte
+}
+
+// NewTracer creates a tracer which exports to e.
+func NewTracer(e Exporter) *Tracer {
+	return &Tracer{exporter: e}
+}
+
+var (
+	globalMu sync.RWMutex
+	global   *Tracer
+)
+
+// SetTracer installs t as the process wide tracer. A nil tracer disables
+// tracing.
+func SetTracer(t *Tracer) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	global = t
+}
+
+func current() *Tracer {
+	globalMu.RLock()
+	defer globalMu.RUnlock()
+	return global
+}
+
+type spanKey struct{}
+
+// Start begins a span as a child of the span in ctx. When tracing is
+// disabled the returned span is a no-op.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	t := current()
+	if t == nil {
+		return ctx, nil
+	}
+	parent, _ := ctx.Value(spanKey{}).(*Span)
+	s := t.start(parent, name)
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func (t *Tracer) start(parent *Span, name string) *Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := &Span{tracer: t, name: name, start: time.Now(), attrs: map[string]string{}}
+	_, _ = rand.Read(s.spanID[:])
+	if parent == nil {
+		parent = t.fallback
+	}
+	switch {
+	case parent != nil:
+		s.traceID = parent.traceID
+		s.parentID = parent.spanID
+	case t.remoteTrace != [16]byte{}:
+		s.traceID = t.remoteTrace
+		s.parentID = t.remoteParent
+	default:
+		_, _ = rand.Read(s.traceID[:])
+	}
+	if t.fallback == nil {
+		t.fallback = s
+	}
+	return s
+}
+
+func (t *Tracer) finish(s *Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if s.ended {
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	if t.fallback == s {
+		t.fallback = nil
+	}
+	data := SpanData{
+		Name:    s.name,
+		TraceID: hex.EncodeToString(s.traceID[:]),
+		SpanID:  hex.EncodeToString(s.spanID[:]),
+		Start:   s.start,
+		End:     s.end,
+		Attrs:   s.attrs,
+		Err:     s.err,
+	}
+	if s.parentID != [8]byte{} {
+		data.ParentID = hex.EncodeToString(s.parentID[:])
+	}
+	t.finished = append(t.finished, data)
+}
+
+// Flush exports the spans finished so far.
+func (t *Tracer) Flush(ctx context.Context) error {
+	t.mu.Lock()
+	spans := t.finished
+	t.finished = nil
+	t.mu.Unlock()
+	if len(spans) == 0 || t.exporter == nil {
+		return nil
+	}
+	return t.exporter.ExportSpans(ctx, spans)
+}
+
+// Init configures tracing from the standard OpenTelemetry environment
+// variables and returns a function which flushes the recorded spans.
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT or OTEL_EXPORTER_OTLP_ENDPOINT enables
+// tracing, OTEL_TRACES_EXPORTER=none disables it and a W3C TRACEPARENT
+// variable makes the run part of an existing trace, e.g. the CI pipeline's.
+func Init() func(context.Context) error {
+	noop := func(context.Context) error { return nil }
+	if os.Getenv("OTEL_TRACES_EXPORTER") == "none" {
+		return noop
+	}
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if endpoint == "" {
+		base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+		if base == "" {
+			return noop
+		}
+		endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+	}
+
+	t := NewTracer(&OTLPExporter{
+		Endpoint:    endpoint,
+		Headers:     otlp.ParseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")),
+		ServiceName: os.Getenv("OTEL_SERVICE_NAME"),
+	})
+	t.remoteTrace, t.remoteParent = parseTraceparent(os.Getenv("TRACEPARENT"))
+	SetTracer(t)
+	return func(ctx context.Context) error {
+		defer SetTracer(nil)
+		return t.Flush(ctx)
+	}
+}
+
+// parseTraceparent parses a W3C traceparent header value,
+// e.g. 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func parseTraceparent(v string) ([16]byte, [8]byte) {
+	var trace [16]byte
+	var parent [8]byte
+	parts := strings.Split(v, "-")
+	if len(parts) != 4 {
+		return trace, parent
+	}
+	tb, err := hex.DecodeString(parts[1])
+	if err != nil || len(tb) != 16 {
+		return trace, parent
+	}
+	pb, err := hex.DecodeString(parts[2])
+	if err != nil || len(pb) != 8 {
+		return trace, parent
+	}
+	copy(trace[:], tb)
+	copy(parent[:], pb)
+	return trace, parent
+}
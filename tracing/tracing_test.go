@@ -0,0 +1,146 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	spans []SpanData
+}
+
+func (r *recorder) ExportSpans(_ context.Context, spans []SpanData) error {
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+func withTracer(t *testing.T) (*Tracer, *recorder) {
+	t.Helper()
+	rec := &recorder{}
+	tr := NewTracer(rec)
+	SetTracer(tr)
+	t.Cleanup(func() { SetTracer(nil) })
+	return tr, rec
+}
+
+func TestStartDisabled(t *testing.T) {
+	ctx, span := Start(context.Background(), "noop")
+	require.NotNil(t, ctx)
+	require.Nil(t, span)
+
+	// methods on a disabled span are no-ops
+	span.SetAttr("k", "v")
+	span.SetError(errors.New("boom"))
+	span.End()
+	require.Empty(t, span.TraceID())
+}
+
+func TestSpanHierarchy(t *testing.T) {
+	tr, rec := withTracer(t)
+
+	ctx, root := Start(context.Background(), "run")
+	_, child := Start(ctx, "build")
+	child.SetAttr("file", "dangerfile.go")
+	child.SetError(errors.New("compile failed"))
+	child.End()
+
+	// spans started without a context nest under the root span
+	_, git := Start(context.Background(), "git diff")
+	git.End()
+	root.End()
+
+	require.NoError(t, tr.Flush(context.Background()))
+	require.Len(t, rec.spans, 3)
+
+	build, gitSpan, run := rec.spans[0], rec.spans[1], rec.spans[2]
+	require.Equal(t, "run", run.Name)
+	require.Empty(t, run.ParentID)
+	require.Equal(t, run.SpanID, build.ParentID)
+	require.Equal(t, run.SpanID, gitSpan.ParentID)
+	require.Equal(t, run.TraceID, build.TraceID)
+	require.Equal(t, map[string]string{"file": "dangerfile.go"}, build.Attrs)
+	require.EqualError(t, build.Err, "compile failed")
+	require.NoError(t, run.Err)
+
+	// nothing is exported twice
+	rec.spans = nil
+	require.NoError(t, tr.Flush(context.Background()))
+	require.Empty(t, rec.spans)
+}
+
+func TestEndTwice(t *testing.T) {
+	tr, rec := withTracer(t)
+	_, span := Start(context.Background(), "once")
+	span.End()
+	span.End()
+	require.NoError(t, tr.Flush(context.Background()))
+	require.Len(t, rec.spans, 1)
+}
+
+func TestParseTraceparent(t *testing.T) {
+	trace, parent := parseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", hex.EncodeToString(trace[:]))
+	require.Equal(t, "00f067aa0ba902b7", hex.EncodeToString(parent[:]))
+
+	trace, parent = parseTraceparent("garbage")
+	require.Equal(t, [16]byte{}, trace)
+	require.Equal(t, [8]byte{}, parent)
+}
+
+func TestInit(t *testing.T) {
+	var got map[string]any
+	var headers http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/traces", r.URL.Path)
+		headers = r.Header
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &got))
+	}))
+	defer srv.Close()
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", srv.URL+"/")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "x-api-key=secret")
+	t.Setenv("OTEL_SERVICE_NAME", "")
+	t.Setenv("TRACEPARENT", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	shutdown := Init()
+	_, span := Start(context.Background(), "danger-go run")
+	span.SetError(errors.New("boom"))
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID())
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+	require.Nil(t, current())
+
+	require.Equal(t, "secret", headers.Get("x-api-key"))
+	require.Equal(t, "application/json", headers.Get("Content-Type"))
+
+	rs := got["resourceSpans"].([]any)[0].(map[string]any)
+	service := rs["resource"].(map[string]any)["attributes"].([]any)[0].(map[string]any)
+	require.Equal(t, "danger-go", service["value"].(map[string]any)["stringValue"])
+
+	spans := rs["scopeSpans"].([]any)[0].(map[string]any)["spans"].([]any)
+	require.Len(t, spans, 1)
+	s := spans[0].(map[string]any)
+	require.Equal(t, "danger-go run", s["name"])
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", s["traceId"])
+	require.Equal(t, "00f067aa0ba902b7", s["parentSpanId"])
+	require.Equal(t, map[string]any{"code": float64(2), "message": "boom"}, s["status"])
+}
+
+func TestInitDisabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318")
+	t.Setenv("OTEL_TRACES_EXPORTER", "none")
+	require.NoError(t, Init()(context.Background()))
+	require.Nil(t, current())
+}
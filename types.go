@@ -17,6 +17,8 @@ type Violation struct {
 	Line    int    `json:"line,omitempty"`
 	// Icon is an optional icon for table (Only valid for messages).
 	Icon string `json:"icon,omitempty"`
+	// Rule is the name of the rule which added the violation, if any.
+	Rule string `json:"rule,omitempty"`
 }
 
 type GitHubResults struct {
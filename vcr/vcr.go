@@ -0,0 +1,330 @@
+// Package vcr records HTTP interactions with the GitHub and GitLab APIs to a
+// cassette file once and replays them afterwards, so plugins can be tested
+// against realistic responses without network access. Credentials are
+// scrubbed before anything is written to disk.
+//
+//	rec, err := vcr.New("testdata/pr.json", vcr.WithSecrets(os.Getenv("GITHUB_TOKEN")))
+//	client, err := githubclient.New("", token, githubclient.WithHTTPClient(rec.Client()))
+//	...
+//	err = rec.Stop()
+package vcr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// ModeEnv selects the Mode of recorders whose mode isn't set: "record",
+// "replay" or "auto".
+const ModeEnv = "DANGER_VCR_MODE"
+
+// Redacted replaces scrubbed values.
+const Redacted = "[REDACTED]"
+
+// Mode is whether a Recorder records or replays.
+type Mode int
+
+const (
+	// ModeAuto replays the cassette when it exists and records it otherwise.
+	ModeAuto Mode = iota
+	// ModeRecord sends requests and records them, replacing the cassette.
+	ModeRecord
+	// ModeReplay serves requests from the cassette only.
+	ModeReplay
+)
+
+// ErrNoInteraction is returned when replaying a request that isn't in the
+// cassette.
+var ErrNoInteraction = errors.New("vcr: no recorded interaction")
+
+// sensitiveHeaders are dropped from recorded requests and responses.
+var sensitiveHeaders = []string{"Authorization", "Private-Token", "Job-Token", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+// sensitiveParams are scrubbed from recorded URLs.
+var sensitiveParams = []string{"access_token", "private_token", "token", "client_secret"}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Cassette is the file holding the interactions.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Option configures a Recorder.
+type Option func(*Recorder)
+
+// WithMode sets the mode, overriding DANGER_VCR_MODE.
+func WithMode(m Mode) Option {
+	return func(r *Recorder) {
+		r.mode = m
+		r.modeSet = true
+	}
+}
+
+// WithSecrets scrubs the values, e.g. tokens, wherever they appear in
+// recorded URLs, headers and bodies. Empty values are ignored.
+func WithSecrets(secrets ...string) Option {
+	return func(r *Recorder) {
+		for _, s := range secrets {
+			if s != "" {
+				r.secrets = append(r.secrets, s)
+			}
+		}
+	}
+}
+
+// WithTransport sets the transport real requests are sent with when
+// recording, http.DefaultTransport by default.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(r *Recorder) {
+		r.transport = rt
+	}
+}
+
+// Recorder is an http.RoundTripper recording to or replaying from a
+// cassette.
+type Recorder struct {
+	path      string
+	mode      Mode
+	modeSet   bool
+	secrets   []string
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// New creates a recorder for the cassette at path.
+func New(path string, opts ...Option) (*Recorder, error) {
+	r := &Recorder{path: path, transport: http.DefaultTransport}
+	for _, o := range opts {
+		o(r)
+	}
+	if !r.modeSet {
+		switch os.Getenv(ModeEnv) {
+		case "record":
+			r.mode = ModeRecord
+		case "replay":
+			r.mode = ModeReplay
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if r.mode == ModeReplay {
+			return nil, fmt.Errorf("vcr: cassette %s not found, record it with %s=record", path, ModeEnv)
+		}
+		r.mode = ModeRecord
+	case err != nil:
+		return nil, fmt.Errorf("reading cassette: %w", err)
+	case r.mode != ModeRecord:
+		r.mode = ModeReplay
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("decoding cassette %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+	return r, nil
+}
+
+// NewForTest creates a recorder for testdata/cassettes/<name>.json which is
+// saved when the test ends.
+func NewForTest(t testing.TB, name string, opts ...Option) *Recorder {
+	t.Helper()
+	r, err := New(filepath.Join("testdata", "cassettes", name+".json"), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := r.Stop(); err != nil {
+			t.Error(err)
+		}
+	})
+	return r
+}
+
+// Mode returns whether the recorder records or replays.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Client returns an HTTP client using the recorder.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip records or replays the request.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("vcr: reading request body: %w", err)
+		}
+		_ = req.Body.Close()
+	}
+	recorded := Request{
+		Method: req.Method,
+		URL:    r.scrubURL(req.URL),
+		Header: r.scrubHeader(req.Header),
+		Body:   r.scrub(string(body)),
+	}
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	resp, err := r.transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("vcr: reading response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     r.scrubHeader(resp.Header),
+			Body:       r.scrub(string(respBody)),
+		},
+	})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// replay returns the first unused interaction matching the method, URL and
+// body of the request.
+func (r *Recorder) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || in.Request.Method != recorded.Method || in.Request.URL != recorded.URL || in.Request.Body != recorded.Body {
+			continue
+		}
+		r.used[i] = true
+		header := in.Response.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(in.Response.Body)),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w for %s %s", ErrNoInteraction, recorded.Method, recorded.URL)
+}
+
+// Stop saves the cassette when recording.
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("encoding cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("creating cassette directory: %w", err)
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing cassette: %w", err)
+	}
+	return nil
+}
+
+func (r *Recorder) scrub(s string) string {
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, Redacted)
+	}
+	return s
+}
+
+func (r *Recorder) scrubURL(u *url.URL) string {
+	c := *u
+	c.User = nil
+	q := c.Query()
+	changed := false
+	for _, p := range sensitiveParams {
+		if q.Has(p) {
+			q.Set(p, Redacted)
+			changed = true
+		}
+	}
+	if changed {
+		c.RawQuery = q.Encode()
+	}
+	return r.scrub(c.String())
+}
+
+func (r *Recorder) scrubHeader(h http.Header) http.Header {
+	out := http.Header{}
+	for k, vs := range h {
+		if isSensitive(k) {
+			continue
+		}
+		for _, v := range vs {
+			out.Add(k, r.scrub(v))
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func isSensitive(header string) bool {
+	for _, s := range sensitiveHeaders {
+		if strings.EqualFold(s, header) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package vcr_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/danger/golang/githubclient"
+	"github.com/danger/golang/vcr"
+)
+
+const token = "ghp_secret123"
+
+func TestRecordReplay(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		require.Equal(t, "Bearer "+token, r.Header.Get("Authorization"))
+		w.Header().Set("Set-Cookie", "session=abc")
+		_, _ = w.Write([]byte(`{"number":5,"title":"Add cache","body":"token ` + token + `"}`))
+	}))
+	path := filepath.Join(t.TempDir(), "pr.json")
+
+	get := func(rec *vcr.Recorder) (string, error) {
+		client, err := githubclient.New(srv.URL, token, githubclient.WithHTTPClient(rec.Client()))
+		require.Nil(t, err)
+		pr, err := client.PullRequest(context.Background(), "o", "r", 5)
+		return pr.Title + "|" + pr.Body, err
+	}
+
+	rec, err := vcr.New(path, vcr.WithSecrets(token))
+	require.Nil(t, err)
+	require.Equal(t, vcr.ModeRecord, rec.Mode())
+	got, err := get(rec)
+	require.Nil(t, err)
+	require.Equal(t, "Add cache|token "+token, got)
+	require.Nil(t, rec.Stop())
+
+	data, err := os.ReadFile(path)
+	require.Nil(t, err)
+	require.NotContains(t, string(data), token)
+	require.NotContains(t, string(data), "session=abc")
+	require.Contains(t, string(data), "token [REDACTED]")
+
+	srv.Close()
+	rec, err = vcr.New(path, vcr.WithSecrets(token))
+	require.Nil(t, err)
+	require.Equal(t, vcr.ModeReplay, rec.Mode())
+	got, err = get(rec)
+	require.Nil(t, err)
+	require.Equal(t, "Add cache|token [REDACTED]", got)
+	require.Equal(t, 1, calls)
+
+	// each interaction is replayed once
+	_, err = get(rec)
+	require.ErrorIs(t, err, vcr.ErrNoInteraction)
+}
+
+func TestReplayWithoutCassette(t *testing.T) {
+	_, err := vcr.New(filepath.Join(t.TempDir(), "missing.json"), vcr.WithMode(vcr.ModeReplay))
+	require.Error(t, err)
+
+	t.Setenv(vcr.ModeEnv, "replay")
+	_, err = vcr.New(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
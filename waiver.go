@@ -0,0 +1,112 @@
+package danger
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	dangerJs "github.com/danger/golang/danger-js"
+)
+
+// Waiver accepts the failures of a rule on a PR, granted by a reviewer
+// commenting `/danger accept <rule> [reason]` on it.
+type Waiver struct {
+	Rule   string    `json:"rule"`
+	By     string    `json:"by"`
+	At     time.Time `json:"at"`
+	Reason string    `json:"reason,omitempty"`
+	// URL links to the comment granting the waiver.
+	URL string `json:"url,omitempty"`
+}
+
+var acceptRe = regexp.MustCompile(`(?m)^\s*/danger\s+accept\s+(\S+)[ \t]*(.*?)\s*$`)
+
+// ParseWaivers returns the waivers granted in comments by the users allowed
+// accepts, the earliest one per rule, in the order they were granted.
+func ParseWaivers(comments []dangerJs.GitHubIssueComment, allowed func(login string) bool) []Waiver {
+	comments = slices.Clone(comments)
+	slices.SortStableFunc(comments, func(a, b dangerJs.GitHubIssueComment) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	var waivers []Waiver
+	checked := map[string]bool{}
+	for _, c := range comments {
+		matches := acceptRe.FindAllStringSubmatch(c.Body, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		login := c.User.Login
+		ok, seen := checked[login]
+		if !seen {
+			ok = allowed(login)
+			checked[login] = ok
+		}
+		if !ok {
+			continue
+		}
+		for _, m := range matches {
+			rule := strings.Trim(m[1], "`")
+			if slices.ContainsFunc(waivers, func(w Waiver) bool { return w.Rule == rule }) {
+				continue
+			}
+			waivers = append(waivers, Waiver{Rule: rule, By: login, At: c.CreatedAt, Reason: m[2], URL: c.HTMLURL})
+		}
+	}
+	return waivers
+}
+
+// Waivers returns the waivers granted in the comments of the PR, see
+// ParseWaivers. With a nil allowed, only users with write access to the
+// repository can grant them.
+func (o *GitHubOps) Waivers(allowed func(login string) bool) ([]Waiver, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opsTimeout)
+	defer cancel()
+	comments, err := o.client.IssueComments(ctx, o.owner, o.repo, o.number)
+	if err != nil {
+		return nil, fmt.Errorf("listing comments: %w", err)
+	}
+	if allowed == nil {
+		allowed = o.hasWriteAccess
+	}
+	return ParseWaivers(comments, allowed), nil
+}
+
+// ApplyWaivers turns the failures of the waived rules into warnings naming
+// who waived them, and records the waivers in the rule runs. It returns the
+// waivers which applied to a failure.
+func (s *T) ApplyWaivers(waivers []Waiver) []Waiver {
+	var applied []Waiver
+	for _, w := range waivers {
+		var kept []Violation
+		for _, v := range s.results.Fails {
+			if v.Rule != w.Rule {
+				kept = append(kept, v)
+				continue
+			}
+			v.Message = s.Msg("danger.waived", map[string]any{"message": v.Message, "by": w.By, "reason": w.Reason, "url": w.URL})
+			s.results.Warnings = append(s.results.Warnings, v)
+			s.report(KindWarning, v)
+		}
+		if len(kept) == len(s.results.Fails) {
+			continue
+		}
+		s.results.Fails = kept
+		if s.results.Fails == nil {
+			s.results.Fails = []Violation{}
+		}
+		for i := range s.ruleRuns {
+			if s.ruleRuns[i].Name == w.Rule {
+				s.ruleRuns[i].WaivedBy = w.By
+			}
+		}
+		applied = append(applied, w)
+	}
+	s.waivers = append(s.waivers, applied...)
+	return applied
+}
+
+// AppliedWaivers returns the waivers applied with ApplyWaivers so far.
+func (s *T) AppliedWaivers() []Waiver {
+	return slices.Clone(s.waivers)
+}
@@ -0,0 +1,84 @@
+package danger_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	danger "github.com/danger/golang"
+	dangerJs "github.com/danger/golang/danger-js"
+	"github.com/danger/golang/githubclient"
+)
+
+func TestParseWaivers(t *testing.T) {
+	at := func(min int) time.Time { return time.Date(2026, 10, 16, 12, min, 0, 0, time.UTC) }
+	comment := func(login, body string, min int) dangerJs.GitHubIssueComment {
+		return dangerJs.GitHubIssueComment{Body: body, User: dangerJs.GitHubUser{Login: login}, CreatedAt: at(min),
+			HTMLURL: "https://github.com/o/r/pull/5#" + login}
+	}
+	comments := []dangerJs.GitHubIssueComment{
+		comment("lead", "/danger accept `binary-size` the growth is the new embedded UI", 3),
+		comment("author", "/danger accept license-header", 1),
+		comment("lead", "LGTM\n/danger accept license-header\n/danger accept binary-size again", 2),
+		comment("lead", "please don't /danger accept title", 4),
+	}
+
+	got := danger.ParseWaivers(comments, func(login string) bool { return login == "lead" })
+	require.Equal(t, []danger.Waiver{
+		{Rule: "license-header", By: "lead", At: at(2), URL: "https://github.com/o/r/pull/5#lead"},
+		{Rule: "binary-size", By: "lead", At: at(2), Reason: "again", URL: "https://github.com/o/r/pull/5#lead"},
+	}, got)
+}
+
+func TestApplyWaivers(t *testing.T) {
+	d := danger.New()
+	d.RunRules(danger.DSL{},
+		danger.Rule{Name: "binary-size", Run: func(d *danger.T, _ danger.DSL) { d.Fail("`cmd` grew by +12.0%", "", 0) }},
+		danger.Rule{Name: "title", Run: func(d *danger.T, _ danger.DSL) { d.Fail("Bad title", "", 0) }},
+	)
+
+	applied := d.ApplyWaivers([]danger.Waiver{
+		{Rule: "binary-size", By: "lead", Reason: "new embedded UI"},
+		{Rule: "license-header", By: "lead"},
+	})
+	require.Equal(t, []danger.Waiver{{Rule: "binary-size", By: "lead", Reason: "new embedded UI"}}, applied)
+	require.Equal(t, applied, d.AppliedWaivers())
+
+	r := d.Snapshot()
+	require.Equal(t, []danger.Violation{{Message: "Bad title", Rule: "title"}}, r.Fails)
+	require.Equal(t, []danger.Violation{
+		{Message: "`cmd` grew by +12.0% (waived by @lead: new embedded UI)", Rule: "binary-size"},
+	}, r.Warnings)
+	require.Equal(t, []danger.RuleRun{
+		{Name: "binary-size", Status: danger.RuleRan, WaivedBy: "lead"},
+		{Name: "title", Status: danger.RuleRan},
+	}, d.RuleRuns())
+}
+
+func TestGitHubOpsWaivers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/repos/o/r/issues/5/comments":
+			_, _ = w.Write([]byte(`[
+				{"id":10,"body":"/danger accept title","user":{"login":"outsider"}},
+				{"id":11,"body":"/danger accept title fine for a revert","user":{"login":"maintainer"}}
+			]`))
+		case "/api/v3/repos/o/r/collaborators/maintainer/permission":
+			_, _ = w.Write([]byte(`{"permission":"write"}`))
+		case "/api/v3/repos/o/r/collaborators/outsider/permission":
+			_, _ = w.Write([]byte(`{"permission":"read"}`))
+		default:
+			t.Fatalf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := githubclient.New(srv.URL, "token")
+	require.Nil(t, err)
+	got, err := danger.NewGitHubOpsWithClient(client, "o", "r", 5).Waivers(nil)
+	require.Nil(t, err)
+	require.Equal(t, []danger.Waiver{{Rule: "title", By: "maintainer", Reason: "fine for a revert"}}, got)
+}